@@ -15,7 +15,7 @@ import { navigateToWorkspace } from '../lib/navigation'
 import useOverheatIndicator from '../hooks/useOverheatIndicator'
 import { useModal } from './ModalProvider'
 import { useToast } from './ToastProvider'
-import { disposeWorkspace, getErrorMessage, openVSCode, reconnectRemoteHost } from '../lib/api'
+import { disposeWorkspace, getErrorMessage, openVSCode, reconnectRemoteHost, setMaintenance } from '../lib/api'
 
 const NAV_COLLAPSED_KEY = 'schmux-nav-collapsed';
 
@@ -43,7 +43,7 @@ export default function AppShell() {
   const { toggleTheme } = useTheme();
   const { isNotConfigured, config, getRepoName } = useConfig();
   const { versionInfo } = useVersionInfo();
-  const { workspaces, connected, linearSyncResolveConflictStates } = useSessions();
+  const { workspaces, connected, linearSyncResolveConflictStates, maintenance, attentionCount } = useSessions();
   const overheating = useOverheatIndicator();
   const navigate = useNavigate();
   const location = useLocation();
@@ -54,6 +54,26 @@ export default function AppShell() {
   const { alert, confirm } = useModal();
   const { success, error: toastError } = useToast();
 
+  const [endingMaintenance, setEndingMaintenance] = useState(false);
+  const readOnly = !!config?.access_control?.read_only;
+
+  // Badge the tab title with the sessions waiting on the user
+  useEffect(() => {
+    document.title = attentionCount > 0 ? `(${attentionCount}) schmux` : 'schmux';
+  }, [attentionCount]);
+
+  const handleEndMaintenance = async () => {
+    setEndingMaintenance(true);
+    try {
+      await setMaintenance({ enabled: false });
+      success('Maintenance mode ended');
+    } catch (err) {
+      toastError(getErrorMessage(err, 'Failed to end maintenance mode'));
+    } finally {
+      setEndingMaintenance(false);
+    }
+  };
+
   // State for reconnect modal (used by sidebar Reconnect button)
   const [reconnectModal, setReconnectModal] = useState<{
     hostId: string;
@@ -363,19 +383,21 @@ export default function AppShell() {
           </div>
 
           <div className="nav-workspaces">
-            <div className="nav-spawn-btn-container">
-              <button
-                className="btn nav-spawn-btn"
-                onClick={() => navigate('/spawn')}
-              >
-                <svg width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2.5">
-                  <line x1="12" y1="5" x2="12" y2="19"></line>
-                  <line x1="5" y1="12" x2="19" y2="12"></line>
-                </svg>
-                Add Workspace
-                <kbd className="nav-spawn-btn__kbd">{navigator.platform?.includes('Mac') ? '⌘K N' : 'Ctrl+K N'}</kbd>
-              </button>
-            </div>
+            {!readOnly && (
+              <div className="nav-spawn-btn-container">
+                <button
+                  className="btn nav-spawn-btn"
+                  onClick={() => navigate('/spawn')}
+                >
+                  <svg width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2.5">
+                    <line x1="12" y1="5" x2="12" y2="19"></line>
+                    <line x1="5" y1="12" x2="19" y2="12"></line>
+                  </svg>
+                  Add Workspace
+                  <kbd className="nav-spawn-btn__kbd">{navigator.platform?.includes('Mac') ? '⌘K N' : 'Ctrl+K N'}</kbd>
+                </button>
+              </div>
+            )}
             <div className="nav-section-header">
               <span className="nav-section-title">Workspaces</span>
             </div>
@@ -469,11 +491,15 @@ export default function AppShell() {
                   <div className="nav-workspace__sessions">
                     {workspace.sessions?.map((sess) => {
                       const isActive = sess.id === sessionId;
-                      const activityDisplay = !sess.running
-                        ? 'Stopped'
-                        : sess.last_output_at
-                          ? formatRelativeTime(sess.last_output_at)
-                          : '-';
+                      const activityDisplay = sess.status === 'provisioning'
+                        ? 'Starting...'
+                        : sess.status === 'failed'
+                          ? 'Failed'
+                          : !sess.running
+                            ? 'Stopped'
+                            : sess.last_output_at
+                              ? formatRelativeTime(sess.last_output_at)
+                              : '-';
 
                       // Check if this session's target is promptable
                       const runTarget = (config?.run_targets || []).find(t => t.name === sess.target);
@@ -617,6 +643,29 @@ export default function AppShell() {
           />
         )}
 
+        {maintenance?.enabled && (
+          <div className="banner banner--warning">
+            <p style={{ margin: 0, flex: 1 }}>
+              <strong>Maintenance mode:</strong> background git status, fetches, and base repo pruning are paused
+              {maintenance.block_spawns && ', and spawns are disabled'}
+              {maintenance.since && ` (started ${formatRelativeTime(maintenance.since)})`}.
+            </p>
+            {!readOnly && (
+              <button className="btn btn--sm" onClick={handleEndMaintenance} disabled={endingMaintenance}>
+                {endingMaintenance ? 'Ending...' : 'End maintenance'}
+              </button>
+            )}
+          </div>
+        )}
+
+        {readOnly && (
+          <div className="banner banner--info">
+            <p style={{ margin: 0, flex: 1 }}>
+              <strong>Read-only:</strong> this dashboard is in kiosk mode; changes are disabled by the server.
+            </p>
+          </div>
+        )}
+
         <Outlet />
       </main>
     </div>
@@ -22,7 +22,7 @@ const BUILTIN_DIFF_COMMANDS: ExternalDiffCommand[] = [
 
 export default function DiffDropdown({ workspace, externalDiffCommands }: DiffDropdownProps) {
   const navigate = useNavigate();
-  const { alert } = useModal();
+  const { alert, confirm } = useModal();
   const [isOpen, setIsOpen] = useState(false);
   const [executing, setExecuting] = useState<string | null>(null);
   const [menuPosition, setMenuPosition] = useState({ top: 0, left: 0 });
@@ -91,7 +91,12 @@ export default function DiffDropdown({ workspace, externalDiffCommands }: DiffDr
     setExecuting(cmd.name);
 
     try {
-      const response = await diffExternal(workspace.id, cmd.command);
+      let response = await diffExternal(workspace.id, cmd.command);
+      if (response.needs_confirm) {
+        const proceed = await confirm(`${response.message}. Open all ${response.file_count} files?`, { confirmText: 'Open all' });
+        if (!proceed) return;
+        response = await diffExternal(workspace.id, cmd.command, { confirm: true });
+      }
       const title = response.success ? 'Diff tool opened' : 'Failed to open diff tool';
       await alert(title, response.message);
     } catch (err) {
@@ -0,0 +1,54 @@
+import React from 'react';
+
+type PatchLine = {
+  kind: 'hunk' | 'context' | 'added' | 'removed';
+  text: string;
+  oldLine?: number;
+  newLine?: number;
+};
+
+const HUNK_HEADER = /^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@/;
+
+// parsePatch numbers the lines of git diff hunks. "\ No newline at end of file"
+// markers are dropped.
+function parsePatch(patch: string): PatchLine[] {
+  const lines: PatchLine[] = [];
+  let oldLine = 0;
+  let newLine = 0;
+  for (const line of patch.replace(/\n$/, '').split('\n')) {
+    const header = HUNK_HEADER.exec(line);
+    if (header) {
+      oldLine = Number(header[1]);
+      newLine = Number(header[2]);
+      lines.push({ kind: 'hunk', text: line });
+    } else if (line.startsWith('+')) {
+      lines.push({ kind: 'added', text: line.slice(1), newLine: newLine++ });
+    } else if (line.startsWith('-')) {
+      lines.push({ kind: 'removed', text: line.slice(1), oldLine: oldLine++ });
+    } else if (line.startsWith(' ')) {
+      lines.push({ kind: 'context', text: line.slice(1), oldLine: oldLine++, newLine: newLine++ });
+    }
+  }
+  return lines;
+}
+
+// GitPatchView renders hunks produced by git diff, for when the diff options
+// (ignore whitespace, algorithm) decide what counts as a change.
+export default function GitPatchView({ patch }: { patch: string }) {
+  return (
+    <table className="git-patch">
+      <tbody>
+        {parsePatch(patch).map((line, i) => (
+          <tr key={i} className={`git-patch__line git-patch__line--${line.kind}`}>
+            <td className="git-patch__number">{line.oldLine ?? ''}</td>
+            <td className="git-patch__number">{line.newLine ?? ''}</td>
+            <td className="git-patch__marker">
+              {line.kind === 'added' ? '+' : line.kind === 'removed' ? '-' : ''}
+            </td>
+            <td className="git-patch__text"><pre>{line.text}</pre></td>
+          </tr>
+        ))}
+      </tbody>
+    </table>
+  );
+}
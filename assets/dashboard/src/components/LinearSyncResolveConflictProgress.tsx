@@ -24,6 +24,14 @@ function StepIcon({ status }: { status: string }) {
       </svg>
     );
   }
+  if (status === 'needs_review') {
+    return (
+      <svg width="12" height="12" viewBox="0 0 16 16" fill="none" stroke="var(--color-warning)" strokeWidth="2.5">
+        <line x1="8" y1="3" x2="8" y2="9" />
+        <line x1="8" y1="12.5" x2="8" y2="13" />
+      </svg>
+    );
+  }
   // failed
   return (
     <svg width="12" height="12" viewBox="0 0 16 16" fill="none" stroke="var(--color-error)" strokeWidth="2.5">
@@ -94,6 +102,7 @@ export default function LinearSyncResolveConflictProgress({ workspaceId }: Linea
   const isActive = state.status === 'in_progress';
   const isDone = state.status === 'done';
   const isFailed = state.status === 'failed';
+  const needsReview = state.status === 'needs_review';
 
   const workspace = workspaces?.find(ws => ws.id === workspaceId);
   const hasMoreCommits = (workspace?.git_behind ?? 0) > 0;
@@ -121,7 +130,7 @@ export default function LinearSyncResolveConflictProgress({ workspaceId }: Linea
         <div style={{ display: 'flex', alignItems: 'center', gap: 8 }}>
           {isActive && <div className="spinner--small" style={{ width: 14, height: 14, borderWidth: 2 }} />}
           <strong>
-            {isActive ? 'Resolving conflicts...' : isDone ? 'Conflict resolution complete' : 'Conflict resolution failed'}
+            {isActive ? 'Resolving conflicts...' : isDone ? 'Conflict resolution complete' : needsReview ? 'Conflict resolution needs review' : 'Conflict resolution failed'}
           </strong>
           {state.hash && (
             <span style={{ color: 'var(--color-text-muted)', fontFamily: 'monospace', fontSize: '0.8rem' }}>
@@ -146,7 +155,7 @@ export default function LinearSyncResolveConflictProgress({ workspaceId }: Linea
           padding: '6px 10px',
           marginBottom: 6,
           borderRadius: 4,
-          background: isDone ? 'rgba(0, 180, 100, 0.08)' : 'rgba(220, 50, 50, 0.08)',
+          background: isDone ? 'rgba(0, 180, 100, 0.08)' : needsReview ? 'var(--color-warning-subtle)' : 'rgba(220, 50, 50, 0.08)',
           fontSize: '0.85rem',
         }}>
           {state.message}
@@ -272,12 +272,23 @@ export default function SessionTabs({ sessions, currentSessionId, workspace, act
       nudgePreviewElement = nudgePreview;
     }
 
-    // Show "Stopped" for stopped sessions, otherwise show last activity time
-    const activityDisplay = !sess.running
-      ? 'Stopped'
-      : sess.last_output_at
-        ? formatRelativeTime(sess.last_output_at)
-        : '-';
+    // Show remote provisioning state, "Stopped" for stopped sessions, otherwise last activity time
+    const activityDisplay = sess.status === 'provisioning'
+      ? 'Starting...'
+      : sess.status === 'failed'
+        ? 'Failed'
+        : !sess.running
+          ? 'Stopped'
+          : sess.last_output_at
+            ? formatRelativeTime(sess.last_output_at)
+            : '-';
+    const activityTooltip = sess.status === 'provisioning'
+      ? 'Waiting for the remote host'
+      : sess.status === 'failed'
+        ? 'Remote session failed to start'
+        : !sess.running
+          ? 'Session stopped'
+          : (sess.last_output_at ? formatTimestamp(sess.last_output_at) : 'Never');
 
     return (
       <div
@@ -298,24 +309,26 @@ export default function SessionTabs({ sessions, currentSessionId, workspace, act
           <span className="session-tab__name">
             {displayName}
           </span>
-          <Tooltip content={!sess.running ? 'Session stopped' : (sess.last_output_at ? formatTimestamp(sess.last_output_at) : 'Never')}>
+          <Tooltip content={activityTooltip}>
             <span className="session-tab__activity">
               {activityDisplay}
             </span>
           </Tooltip>
-          <Tooltip content="Dispose session" variant="warning">
-            <button
-              className="btn btn--sm btn--ghost btn--danger session-tab__dispose"
-              onClick={(e) => !disabled && handleDispose(sess.id, e)}
-              aria-label={`Dispose ${sess.id}`}
-              disabled={disabled}
-            >
-              <svg width="10" height="10" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="3" strokeLinecap="round">
-                <line x1="4" y1="4" x2="20" y2="20"></line>
-                <line x1="20" y1="4" x2="4" y2="20"></line>
-              </svg>
-            </button>
-          </Tooltip>
+          {!config.access_control?.read_only && (
+            <Tooltip content="Dispose session" variant="warning">
+              <button
+                className="btn btn--sm btn--ghost btn--danger session-tab__dispose"
+                onClick={(e) => !disabled && handleDispose(sess.id, e)}
+                aria-label={`Dispose ${sess.id}`}
+                disabled={disabled}
+              >
+                <svg width="10" height="10" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="3" strokeLinecap="round">
+                  <line x1="4" y1="4" x2="20" y2="20"></line>
+                  <line x1="20" y1="4" x2="4" y2="20"></line>
+                </svg>
+              </button>
+            </Tooltip>
+          )}
         </div>
         {nudgePreviewElement && (
           <div className="session-tab__row2">
@@ -1,7 +1,7 @@
 import React, { useState, useRef, useEffect } from 'react';
 import { createPortal } from 'react-dom';
 import { useNavigate } from 'react-router-dom';
-import { openVSCode, disposeWorkspace, disposeWorkspaceAll, getErrorMessage } from '../lib/api';
+import { openVSCode, disposeWorkspace, disposeWorkspaceAll, pinWorkspace, getErrorMessage } from '../lib/api';
 import { useModal } from './ModalProvider';
 import { useToast } from './ToastProvider';
 import { useSessions } from '../contexts/SessionsContext';
@@ -10,6 +10,10 @@ import { useSync } from '../hooks/useSync';
 import Tooltip from './Tooltip';
 import type { WorkspaceResponse } from '../lib/types';
 
+// Workspaces already offered for disposal after their branch was deleted on the remote,
+// so the prompt isn't repeated on every status update or revisit in this tab.
+const branchGonePrompted = new Set<string>();
+
 type WorkspaceHeaderProps = {
   workspace: WorkspaceResponse;
 };
@@ -63,8 +67,16 @@ export default function WorkspaceHeader({ workspace }: WorkspaceHeaderProps) {
     }
   };
 
-  const handleDisposeWorkspace = async () => {
-    const accepted = await confirm(`Dispose workspace ${workspace.id}?`, { danger: true });
+  const handleTogglePin = async () => {
+    try {
+      await pinWorkspace(workspace.id, !workspace.pinned);
+    } catch (err) {
+      toastError(getErrorMessage(err, 'Failed to pin workspace'));
+    }
+  };
+
+  const handleDisposeWorkspace = async (message = `Dispose workspace ${workspace.id}?`) => {
+    const accepted = await confirm(message, { danger: true });
     if (!accepted) return;
 
     try {
@@ -82,6 +94,17 @@ export default function WorkspaceHeader({ workspace }: WorkspaceHeaderProps) {
     }
   };
 
+  // With sessions.branch_gone_policy "prompt", offer to dispose a workspace once its
+  // branch is deleted on the remote
+  useEffect(() => {
+    if (!workspace.branch_gone || config.sessions?.branch_gone_policy !== 'prompt') return;
+    if (config.access_control?.read_only) return;
+    if (branchGonePrompted.has(workspace.id)) return;
+    branchGonePrompted.add(workspace.id);
+    handleDisposeWorkspace(`Branch ${workspace.branch} was deleted on the remote. Dispose workspace ${workspace.id}?`);
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [workspace.id, workspace.branch_gone, config.sessions?.branch_gone_policy]);
+
   // Calculate menu position when dropdown opens
   useEffect(() => {
     if (isDropdownOpen && gitStatusRef.current) {
@@ -190,7 +213,12 @@ export default function WorkspaceHeader({ workspace }: WorkspaceHeaderProps) {
                 {displayBranch}
               </span>
             )}
-            {isGit && (
+            {isGit && workspace.branch_gone && (
+              <Tooltip content={`origin/${workspace.branch} was deleted on the remote`} variant="warning">
+                <span className="app-header__git-status app-header__git-status--gone">branch deleted</span>
+              </Tooltip>
+            )}
+            {isGit && !workspace.branch_gone && (
               <div style={{ display: 'inline-flex' }} ref={gitStatusRef}>
                 <Tooltip content={`${behind} behind, ${ahead} ahead`}>
                   <span
@@ -207,6 +235,18 @@ export default function WorkspaceHeader({ workspace }: WorkspaceHeaderProps) {
           <span className="app-header__name">{displayName}</span>
         </div>
         <div className="app-header__actions">
+          <Tooltip content={workspace.pinned ? 'Unpin workspace' : 'Pin workspace to the top'}>
+            <button
+              className="btn btn--sm btn--ghost btn--bordered"
+              onClick={handleTogglePin}
+              aria-label={`${workspace.pinned ? 'Unpin' : 'Pin'} ${workspace.id}`}
+              aria-pressed={!!workspace.pinned}
+            >
+              <svg width="14" height="14" viewBox="0 0 24 24" fill={workspace.pinned ? 'currentColor' : 'none'} stroke="currentColor" strokeWidth="2">
+                <polygon points="12 2 15.09 8.26 22 9.27 17 14.14 18.18 21.02 12 17.77 5.82 21.02 7 14.14 2 9.27 8.91 8.26 12 2"></polygon>
+              </svg>
+            </button>
+          </Tooltip>
           <Tooltip content="Open in VS Code">
             <button
               className="btn btn--sm btn--ghost btn--bordered"
@@ -223,19 +263,21 @@ export default function WorkspaceHeader({ workspace }: WorkspaceHeaderProps) {
               )}
             </button>
           </Tooltip>
-          <Tooltip content="Dispose workspace and all sessions" variant="warning">
-            <button
-              className="btn btn--sm btn--ghost btn--danger btn--bordered"
-              onClick={handleDisposeWorkspace}
-              disabled={actionsDisabled}
-              aria-label={`Dispose ${workspace.id}`}
-            >
-              <svg width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2">
-                <polyline points="3 6 5 6 21 6"></polyline>
-                <path d="M19 6v14a2 2 0 0 1-2 2H7a2 2 0 0 1-2-2V6m3 0V4a2 2 0 0 1 2-2h4a2 2 0 0 1 2 2v2"></path>
-              </svg>
-            </button>
-          </Tooltip>
+          {!config.access_control?.read_only && (
+            <Tooltip content="Dispose workspace and all sessions" variant="warning">
+              <button
+                className="btn btn--sm btn--ghost btn--danger btn--bordered"
+                onClick={() => handleDisposeWorkspace()}
+                disabled={actionsDisabled}
+                aria-label={`Dispose ${workspace.id}`}
+              >
+                <svg width="14" height="14" viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth="2">
+                  <polyline points="3 6 5 6 21 6"></polyline>
+                  <path d="M19 6v14a2 2 0 0 1-2 2H7a2 2 0 0 1-2-2V6m3 0V4a2 2 0 0 1 2-2h4a2 2 0 0 1 2 2v2"></path>
+                </svg>
+              </button>
+            </Tooltip>
+          )}
         </div>
       </div>
 
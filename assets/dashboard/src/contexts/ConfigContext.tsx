@@ -20,24 +20,42 @@ const ConfigContext = createContext<ConfigContextValue | null>(null);
 const DEFAULT_CONFIG: ConfigResponse = {
   workspace_path: '',
   source_code_management: 'git-worktree',
+  prune_base_repos: false,
   repos: [],
   run_targets: [],
+  default_models: {},
   models: [],
   quick_launch: [],
-  nudgenik: { target: '', viewed_buffer_ms: 5000, seen_interval_ms: 2000 },
-  branch_suggest: { target: '' },
-  conflict_resolve: { target: '', timeout_ms: 120000 },
+  nudgenik: { target: '', viewed_buffer_ms: 5000, seen_interval_ms: 2000, max_concurrent: 2, timeout_ms: 15000 },
+  branch_suggest: { target: '', default_enabled: true },
+  conflict_resolve: { target: '', timeout_ms: 120000, min_confidence: 'high' },
   terminal: {
     width: 120,
     height: 40,
     seed_lines: 100,
     bootstrap_lines: 20000,
+    bootstrap_max_kb: 512,
+    output_interval_ms: 50,
+    output_max_kb: 64,
   },
   sessions: {
     dashboard_poll_interval_ms: 5000,
+    dashboard_ping_interval_ms: 25000,
     git_status_poll_interval_ms: 10000,
     git_clone_timeout_ms: 300000,
     git_status_timeout_ms: 30000,
+    git_fetch_timeout_ms: 120000,
+    git_diff_timeout_ms: 60000,
+    max_prompt_bytes: 131071,
+    max_workspaces_per_repo: 100,
+    max_per_workspace: 0,
+    unavailable_target_policy: 'fail',
+    nickname_collision: 'suffix',
+    branch_gone_policy: 'warn',
+    workspace_env_file: '.schmux.env',
+    auto_restart_max_retries: 3,
+    auto_restart_backoff_ms: 5000,
+    failed_remote_grace_ms: 3600000,
   },
   xterm: {
     mtime_poll_interval_ms: 5000,
@@ -45,9 +63,11 @@ const DEFAULT_CONFIG: ConfigResponse = {
     operation_timeout_ms: 10000,
     max_log_size_mb: 50,
     rotated_log_size_mb: 1,
+    retain_after_dispose: false,
   },
   network: {
     bind_address: '127.0.0.1',
+    bind_addresses: ['127.0.0.1'],
     port: 7337,
     public_base_url: '',
     tls: {
@@ -59,12 +79,21 @@ const DEFAULT_CONFIG: ConfigResponse = {
     enabled: false,
     provider: 'github',
     session_ttl_minutes: 1440,
+    allow_self_update: true,
+    read_only: false,
   },
   pr_review: {
     target: '',
   },
   notifications: {
     sound_disabled: false,
+    sounds: {
+      needs_input: { enabled: true, sound: 'attention' },
+      needs_testing: { enabled: false, sound: 'attention' },
+      completed: { enabled: false, sound: 'attention' },
+      error: { enabled: true, sound: 'attention' },
+      working: { enabled: false, sound: 'attention' },
+    },
   },
   needs_restart: false,
 };
@@ -2,8 +2,8 @@ import React, { createContext, useCallback, useContext, useMemo, useRef, useEffe
 import { useNavigate } from 'react-router-dom';
 import useSessionsWebSocket from '../hooks/useSessionsWebSocket';
 import { useConfig } from './ConfigContext';
-import { playAttentionSound, isAttentionState } from '../lib/notificationSound';
-import type { SessionWithWorkspace, WorkspaceResponse, LinearSyncResolveConflictStatePayload, PendingNavigation } from '../lib/types';
+import { playNotificationSound, soundForNudgeState } from '../lib/notificationSound';
+import type { SessionWithWorkspace, WorkspaceResponse, LinearSyncResolveConflictStatePayload, PendingNavigation, MaintenanceResponse } from '../lib/types';
 
 type SessionsContextValue = {
   workspaces: WorkspaceResponse[];
@@ -14,6 +14,8 @@ type SessionsContextValue = {
   sessionsById: Record<string, SessionWithWorkspace>;
   linearSyncResolveConflictStates: Record<string, LinearSyncResolveConflictStatePayload>;
   clearLinearSyncResolveConflictState: (workspaceId: string) => void;
+  maintenance: MaintenanceResponse | null;
+  attentionCount: number;
   pendingNavigation: PendingNavigation | null;
   setPendingNavigation: (nav: PendingNavigation | null) => void;
   clearPendingNavigation: () => void;
@@ -23,8 +25,8 @@ const SessionsContext = createContext<SessionsContextValue | null>(null);
 
 export function SessionsProvider({ children }: { children: React.ReactNode }) {
   const navigate = useNavigate();
-  const { config } = useConfig();
-  const { workspaces, loading, connected, linearSyncResolveConflictStates, clearLinearSyncResolveConflictState } = useSessionsWebSocket();
+  const { config, reloadConfig } = useConfig();
+  const { workspaces, loading, connected, linearSyncResolveConflictStates, clearLinearSyncResolveConflictState, maintenance, attentionCount } = useSessionsWebSocket(reloadConfig);
   const [pendingNavigation, setPendingNavigationState] = useState<PendingNavigation | null>(null);
 
   const sessionsById = useMemo(() => {
@@ -49,17 +51,21 @@ export function SessionsProvider({ children }: { children: React.ReactNode }) {
   // Detect nudge state changes and play notification sound
   useEffect(() => {
     const prevStates = prevNudgeStatesRef.current;
-    let shouldPlaySound = false;
+    const sounds = config?.notifications?.sounds;
+    let soundToPlay: string | null = null;
 
-    Object.entries(sessionsById).forEach(([sessionId, session]) => {
+    for (const [sessionId, session] of Object.entries(sessionsById)) {
       const prevState = prevStates[sessionId];
       const newState = session.nudge_state;
 
-      // Only notify if state changed TO an attention state (not if it was already that state)
-      if (newState !== prevState && isAttentionState(newState)) {
-        shouldPlaySound = true;
+      // Only notify if state changed TO a state with a sound (not if it was already that state).
+      // The attention cue wins when several sessions change at once.
+      if (newState === prevState) continue;
+      const sound = soundForNudgeState(newState, sounds);
+      if (sound && soundToPlay !== 'attention') {
+        soundToPlay = sound;
       }
-    });
+    }
 
     // Update ref with current states
     const newStates: Record<string, string | undefined> = {};
@@ -68,11 +74,11 @@ export function SessionsProvider({ children }: { children: React.ReactNode }) {
     });
     prevNudgeStatesRef.current = newStates;
 
-    // Play sound if any session transitioned to attention state (and sound is not disabled)
-    if (shouldPlaySound && !config?.notifications?.sound_disabled) {
-      playAttentionSound();
+    // Play sound if any session transitioned to a state with a sound (and sound is not disabled)
+    if (soundToPlay && !config?.notifications?.sound_disabled) {
+      playNotificationSound(soundToPlay);
     }
-  }, [sessionsById, config?.notifications?.sound_disabled]);
+  }, [sessionsById, config?.notifications?.sound_disabled, config?.notifications?.sounds]);
 
   // Keep a ref updated so waitForSession can always read current value
   const sessionsByIdRef = useRef(sessionsById);
@@ -142,10 +148,12 @@ export function SessionsProvider({ children }: { children: React.ReactNode }) {
     sessionsById,
     linearSyncResolveConflictStates,
     clearLinearSyncResolveConflictState,
+    maintenance,
+    attentionCount,
     pendingNavigation,
     setPendingNavigation,
     clearPendingNavigation,
-  }), [workspaces, loading, connected, waitForSession, sessionsById, linearSyncResolveConflictStates, clearLinearSyncResolveConflictState, pendingNavigation, setPendingNavigation, clearPendingNavigation]);
+  }), [workspaces, loading, connected, waitForSession, sessionsById, linearSyncResolveConflictStates, clearLinearSyncResolveConflictState, maintenance, attentionCount, pendingNavigation, setPendingNavigation, clearPendingNavigation]);
 
   return (
     <SessionsContext.Provider value={value}>
@@ -1,8 +1,11 @@
 import { useCallback, useEffect, useRef, useState } from 'react';
-import type { WorkspaceResponse, LinearSyncResolveConflictStatePayload } from '../lib/types';
+import type { WorkspaceResponse, LinearSyncResolveConflictStatePayload, MaintenanceResponse } from '../lib/types';
 
 const RECONNECT_DELAY_MS = 2000;
 const MAX_RECONNECT_DELAY_MS = 30000;
+// The server sends a heartbeat every interval_ms; missing two in a row (plus slack for
+// a busy daemon) means the connection died without a close, e.g. behind a proxy.
+const HEARTBEAT_SLACK_MS = 5000;
 
 type SessionsWebSocketState = {
   workspaces: WorkspaceResponse[];
@@ -10,17 +13,35 @@ type SessionsWebSocketState = {
   loading: boolean;
   linearSyncResolveConflictStates: Record<string, LinearSyncResolveConflictStatePayload>;
   clearLinearSyncResolveConflictState: (workspaceId: string) => void;
+  maintenance: MaintenanceResponse | null;
+  // Sessions whose nudge state asks for the user, as counted by the daemon
+  attentionCount: number;
 };
 
-export default function useSessionsWebSocket(): SessionsWebSocketState {
+// onConfigChanged is called when the daemon announces a config change that affects
+// clients (e.g. poll intervals), so the caller can reload config.
+export default function useSessionsWebSocket(onConfigChanged?: () => void): SessionsWebSocketState {
   const [workspaces, setWorkspaces] = useState<WorkspaceResponse[]>([]);
   const [connected, setConnected] = useState(false);
   const [loading, setLoading] = useState(true);
   const [linearSyncResolveConflictStates, setLinearSyncResolveConflictStates] = useState<Record<string, LinearSyncResolveConflictStatePayload>>({});
+  const [maintenance, setMaintenance] = useState<MaintenanceResponse | null>(null);
+  const [attentionCount, setAttentionCount] = useState(0);
   const wsRef = useRef<WebSocket | null>(null);
   const reconnectTimeoutRef = useRef<number | null>(null);
   const reconnectDelayRef = useRef(RECONNECT_DELAY_MS);
+  const heartbeatTimeoutRef = useRef<number | null>(null);
+  const heartbeatIntervalRef = useRef<number | null>(null);
   const mountedRef = useRef(true);
+  const onConfigChangedRef = useRef(onConfigChanged);
+  onConfigChangedRef.current = onConfigChanged;
+
+  const clearHeartbeatTimeout = () => {
+    if (heartbeatTimeoutRef.current) {
+      window.clearTimeout(heartbeatTimeoutRef.current);
+      heartbeatTimeoutRef.current = null;
+    }
+  };
 
   const connect = useCallback(() => {
     if (!mountedRef.current) return;
@@ -30,6 +51,8 @@ export default function useSessionsWebSocket(): SessionsWebSocketState {
       window.clearTimeout(reconnectTimeoutRef.current);
       reconnectTimeoutRef.current = null;
     }
+    clearHeartbeatTimeout();
+    heartbeatIntervalRef.current = null;
 
     // Close existing connection if any
     if (wsRef.current) {
@@ -44,6 +67,8 @@ export default function useSessionsWebSocket(): SessionsWebSocketState {
     ws.onopen = () => {
       if (!mountedRef.current) return;
       setConnected(true);
+      // The server only announces maintenance mode on connect while it is on
+      setMaintenance(null);
       // Reset reconnect delay on successful connection
       reconnectDelayRef.current = RECONNECT_DELAY_MS;
     };
@@ -52,23 +77,44 @@ export default function useSessionsWebSocket(): SessionsWebSocketState {
       if (!mountedRef.current) return;
       try {
         const data = JSON.parse(event.data);
+        if (data.type === 'heartbeat' && data.interval_ms > 0) {
+          heartbeatIntervalRef.current = data.interval_ms;
+        }
+        // Any message shows the connection is alive; closing a silent one lands in
+        // onclose, which marks us disconnected and reconnects.
+        clearHeartbeatTimeout();
+        if (heartbeatIntervalRef.current) {
+          heartbeatTimeoutRef.current = window.setTimeout(() => {
+            console.warn('[ws/dashboard] no heartbeat, reconnecting');
+            abandon();
+          }, heartbeatIntervalRef.current * 2 + HEARTBEAT_SLACK_MS);
+        }
         // Handle different message types
         if (data.type === 'sessions' && data.workspaces) {
           setWorkspaces(data.workspaces);
+          setAttentionCount(data.attention_count ?? 0);
           setLoading(false);
         } else if (data.type === 'linear_sync_resolve_conflict' && data.workspace_id) {
           setLinearSyncResolveConflictStates(prev => ({
             ...prev,
             [data.workspace_id]: data,
           }));
+        } else if (data.type === 'maintenance') {
+          setMaintenance({ enabled: data.enabled, block_spawns: data.block_spawns, since: data.since });
+        } else if (data.type === 'config') {
+          onConfigChangedRef.current?.();
+        } else if (data.type === 'reconnect') {
+          // The daemon is going away (e.g. restarting); don't wait for the socket to notice
+          abandon();
         }
       } catch (e) {
         console.error('[ws/dashboard] failed to parse message:', e);
       }
     };
 
-    ws.onclose = () => {
+    const handleClose = () => {
       if (!mountedRef.current) return;
+      clearHeartbeatTimeout();
       setConnected(false);
       wsRef.current = null;
 
@@ -81,6 +127,15 @@ export default function useSessionsWebSocket(): SessionsWebSocketState {
         connect();
       }, reconnectDelayRef.current);
     };
+    ws.onclose = handleClose;
+
+    // Gives up on this connection. A browser can take a long time to finish closing a
+    // socket whose peer is gone, so reconnect without waiting for its onclose.
+    const abandon = () => {
+      ws.onclose = null;
+      ws.close();
+      handleClose();
+    };
 
     ws.onerror = () => {
       if (!mountedRef.current) return;
@@ -97,6 +152,7 @@ export default function useSessionsWebSocket(): SessionsWebSocketState {
       if (reconnectTimeoutRef.current) {
         window.clearTimeout(reconnectTimeoutRef.current);
       }
+      clearHeartbeatTimeout();
       if (wsRef.current) {
         wsRef.current.close();
       }
@@ -112,5 +168,5 @@ export default function useSessionsWebSocket(): SessionsWebSocketState {
     });
   }, []);
 
-  return { workspaces, connected, loading, linearSyncResolveConflictStates, clearLinearSyncResolveConflictState };
+  return { workspaces, connected, loading, linearSyncResolveConflictStates, clearLinearSyncResolveConflictState, maintenance, attentionCount };
 }
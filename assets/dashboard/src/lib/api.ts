@@ -1,15 +1,25 @@
 import type {
   ApiError,
+  BranchExistence,
   BuiltinQuickLaunchCookbook,
   ConfigResponse,
   ConfigUpdateRequest,
   DetectToolsResponse,
+  DiffExternalCloseResponse,
+  DiffExternalRequest,
   DiffExternalResponse,
   DiffResponse,
+  FeatureTarget,
   GitGraphResponse,
+  HistoryEntry,
+  HistoryFilter,
   LinearSyncResponse,
   LinearSyncResolveConflictResponse,
+  MaintenanceRequest,
+  MaintenanceResponse,
   OpenVSCodeResponse,
+  OverlayFileContent,
+  OverlayFilesResponse,
   OverlaysResponse,
   PRCheckoutResponse,
   PRRefreshResponse,
@@ -21,11 +31,19 @@ import type {
   RemoteHost,
   RemoteHostConnectRequest,
   ScanResult,
+  SpawnOptionsResponse,
+  SpawnProgressResponse,
   SpawnRequest,
   SpawnResult,
   SuggestBranchRequest,
   SuggestBranchResponse,
+  TargetedFeature,
+  TargetProbeResult,
+  WorkspaceCommitsResponse,
+  WorkspaceLockStatus,
+  WorkspacePrompt,
   WorkspaceResponse,
+  WorkspaceStash,
 } from './types';
 
 // Extract error message from unknown catch value
@@ -35,6 +53,19 @@ export function getErrorMessage(err: unknown, fallback: string): string {
   return fallback;
 }
 
+// Extract the message from an error response: the "error" field of the API's JSON
+// error body, or the raw text from endpoints that still reply in plain text.
+async function readErrorMessage(response: Response, fallback: string): Promise<string> {
+  const text = await response.text();
+  try {
+    const parsed = JSON.parse(text);
+    if (parsed && typeof parsed.error === 'string' && parsed.error) return parsed.error;
+  } catch {
+    // Not JSON
+  }
+  return text.trim() || fallback;
+}
+
 export async function getSessions(): Promise<WorkspaceResponse[]> {
   const response = await fetch('/api/sessions');
   if (!response.ok) throw new Error('Failed to fetch sessions');
@@ -55,8 +86,35 @@ export async function spawnSessions(request: SpawnRequest): Promise<SpawnResult[
   });
   if (!response.ok) {
     // Get error message from response body
-    const text = await response.text();
-    throw new Error(text || 'Failed to spawn sessions');
+    throw new Error(await readErrorMessage(response, 'Failed to spawn sessions'));
+  }
+  return response.json();
+}
+
+export async function spawnDefault(repo: string, branch: string, prompt: string): Promise<SpawnResult[]> {
+  const params = new URLSearchParams({ repo, branch, prompt });
+  const response = await fetch(`/api/spawn-default?${params}`, { method: 'POST' });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to spawn default sessions'));
+  }
+  return response.json();
+}
+
+export async function cancelSpawn(spawnId: string): Promise<void> {
+  const response = await fetch(`/api/spawn/${encodeURIComponent(spawnId)}/cancel`, {
+    method: 'POST'
+  });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to cancel spawn'));
+  }
+}
+
+// Returns null once the spawn has finished (or never registered its spawn_id).
+export async function getSpawnProgress(spawnId: string): Promise<SpawnProgressResponse | null> {
+  const response = await fetch(`/api/spawn/${encodeURIComponent(spawnId)}/progress`);
+  if (response.status === 404) return null;
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to get spawn progress'));
   }
   return response.json();
 }
@@ -88,15 +146,7 @@ export async function suggestBranch(request: SuggestBranchRequest): Promise<Sugg
     body: JSON.stringify(request)
   });
   if (!response.ok) {
-    const text = await response.text();
-    let message = 'Failed to suggest branch name';
-    try {
-      const parsed = JSON.parse(text);
-      if (parsed.error) message = parsed.error;
-    } catch {
-      if (text) message = text;
-    }
-    throw new Error(message);
+    throw new Error(await readErrorMessage(response, 'Failed to suggest branch name'));
   }
   return response.json();
 }
@@ -119,8 +169,7 @@ export async function prepareBranchSpawn(repo: string, branch: string): Promise<
     body: JSON.stringify({ repo, branch })
   });
   if (!response.ok) {
-    const err = await response.text();
-    throw new Error(err || 'Failed to prepare branch spawn');
+    throw new Error(await readErrorMessage(response, 'Failed to prepare branch spawn'));
   }
   return response.json();
 }
@@ -131,7 +180,63 @@ export async function disposeSession(sessionId: string): Promise<{ status: strin
   return response.json();
 }
 
-export async function updateNickname(sessionId: string, nickname: string): Promise<{ status: string }> {
+export async function restartSession(sessionId: string): Promise<{ status: string; session_id: string }> {
+  const response = await fetch(`/api/sessions/${sessionId}/restart`, { method: 'POST' });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to restart session');
+  }
+  return response.json();
+}
+
+export async function sendSessionMessage(sessionId: string, message: string): Promise<{ status: 'sent' | 'queued' }> {
+  const response = await fetch(`/api/sessions/${sessionId}/message`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ message }),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to send message');
+  }
+  return response.json();
+}
+
+export async function adoptSession(tmuxSession: string, workspaceId: string, nickname?: string): Promise<{ status: string; session_id: string }> {
+  const response = await fetch('/api/sessions/adopt', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ tmux_session: tmuxSession, workspace_id: workspaceId, nickname }),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to adopt session');
+  }
+  return response.json();
+}
+
+export async function reconcileSessions(keepDead = false): Promise<{ dead: string[]; removed: boolean }> {
+  const response = await fetch('/api/sessions/reconcile', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ keep_dead: keepDead }),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to reconcile sessions');
+  }
+  return response.json();
+}
+
+export async function getSessionOutput(sessionId: string, ansi = false): Promise<string> {
+  const response = await fetch(`/api/sessions/${sessionId}/output${ansi ? '?ansi=true' : ''}`);
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to get session output'));
+  }
+  return response.text();
+}
+
+export async function updateNickname(sessionId: string, nickname: string): Promise<{ status: string; nickname: string }> {
   const response = await fetch(`/api/sessions-nickname/${sessionId}`, {
     method: 'PUT',
     headers: { 'Content-Type': 'application/json' },
@@ -158,6 +263,18 @@ export async function disposeWorkspace(workspaceId: string): Promise<{ status: s
   return response.json();
 }
 
+export async function pinWorkspace(workspaceId: string, pinned?: boolean): Promise<{ workspace_id: string; pinned: boolean }> {
+  const response = await fetch(`/api/workspaces/${workspaceId}/pin`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(pinned === undefined ? {} : { pinned }),
+  });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to pin workspace'));
+  }
+  return response.json();
+}
+
 export async function disposeWorkspaceAll(workspaceId: string): Promise<{ status: string; sessions_disposed: number }> {
   const response = await fetch(`/api/workspaces/${workspaceId}/dispose-all`, { method: 'POST' });
   if (!response.ok) {
@@ -167,8 +284,48 @@ export async function disposeWorkspaceAll(workspaceId: string): Promise<{ status
   return response.json();
 }
 
-export async function getDiff(workspaceId: string): Promise<DiffResponse> {
-  const response = await fetch(`/api/diff/${workspaceId}`);
+export async function forkWorkspace(
+  workspaceId: string,
+  branch: string,
+  copyUncommitted = false
+): Promise<{ workspace_id: string; branch: string; path: string }> {
+  const response = await fetch(`/api/workspaces/${workspaceId}/fork`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ branch, copy_uncommitted: copyUncommitted }),
+  });
+  if (!response.ok) {
+    const err = await response.json();
+    throw new Error(err.error || 'Failed to fork workspace');
+  }
+  return response.json();
+}
+
+export async function importWorkspace(
+  path: string,
+  applyOverlay = false
+): Promise<{ workspace_id: string; branch: string; path: string }> {
+  const response = await fetch('/api/workspaces/import', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ path, apply_overlay: applyOverlay }),
+  });
+  if (!response.ok) {
+    const err = await response.json();
+    throw new Error(err.error || 'Failed to import workspace');
+  }
+  return response.json();
+}
+
+export async function getDiff(
+  workspaceId: string,
+  options: { ignoreWhitespace?: boolean; algorithm?: string } = {}
+): Promise<DiffResponse> {
+  const params = new URLSearchParams();
+  if (options.ignoreWhitespace) params.set('ignore_whitespace', 'true');
+  if (options.algorithm) params.set('algorithm', options.algorithm);
+  const query = params.toString();
+  const response = await fetch(`/api/diff/${workspaceId}${query ? `?${query}` : ''}`);
   if (!response.ok) throw new Error('Failed to fetch diff');
   return response.json();
 }
@@ -187,6 +344,22 @@ export async function scanWorkspaces(): Promise<ScanResult> {
   return response.json();
 }
 
+export interface WorkspaceMigrateResult {
+  workspace_id: string;
+  from: string;
+  to: string;
+  status: 'moved' | 'skipped' | 'failed';
+  reason?: string;
+}
+
+export async function migrateWorkspaces(): Promise<{ results: WorkspaceMigrateResult[] }> {
+  const response = await fetch('/api/workspaces/migrate', { method: 'POST' });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to migrate workspaces'));
+  }
+  return response.json();
+}
+
 export async function updateConfig(request: ConfigUpdateRequest): Promise<{ status: string; message?: string; warning?: string; warnings?: string[] }> {
   const response = await fetch('/api/config', {
     method: 'POST',
@@ -210,6 +383,24 @@ export async function updateConfig(request: ConfigUpdateRequest): Promise<{ stat
   return response.json();
 }
 
+export async function getFeatureTarget(feature: TargetedFeature): Promise<FeatureTarget> {
+  const response = await fetch(`/api/${feature}/target`);
+  if (!response.ok) throw new Error(`Failed to fetch ${feature} target`);
+  return response.json();
+}
+
+export async function setFeatureTarget(feature: TargetedFeature, target: string): Promise<FeatureTarget> {
+  const response = await fetch(`/api/${feature}/target`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ target })
+  });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, `Failed to set ${feature} target`));
+  }
+  return response.json();
+}
+
 export async function getAuthSecretsStatus(): Promise<{ client_id_set: boolean; client_secret_set: boolean }> {
   const response = await fetch('/api/auth/secrets');
   if (!response.ok) throw new Error('Failed to fetch auth secrets');
@@ -223,8 +414,7 @@ export async function saveAuthSecrets(payload: { client_id: string; client_secre
     body: JSON.stringify(payload)
   });
   if (!response.ok) {
-    const err = await response.text();
-    throw new Error(err || 'Failed to save auth secrets');
+    throw new Error(await readErrorMessage(response, 'Failed to save auth secrets'));
   }
   return response.json();
 }
@@ -241,11 +431,15 @@ export async function openVSCode(workspaceId: string): Promise<OpenVSCodeRespons
   return response.json();
 }
 
-export async function diffExternal(workspaceId: string, command?: string): Promise<DiffExternalResponse> {
+export async function diffExternal(
+  workspaceId: string,
+  command?: string,
+  options: Omit<DiffExternalRequest, 'command'> = {}
+): Promise<DiffExternalResponse> {
   const response = await fetch(`/api/diff-external/${workspaceId}`, {
     method: 'POST',
     headers: { 'Content-Type': 'application/json' },
-    body: JSON.stringify(command ? { command } : {})
+    body: JSON.stringify(command ? { command, ...options } : options)
   });
   if (!response.ok) {
     const err = await response.json();
@@ -254,6 +448,14 @@ export async function diffExternal(workspaceId: string, command?: string): Promi
   return response.json();
 }
 
+export async function closeDiffExternal(workspaceId: string): Promise<DiffExternalCloseResponse> {
+  const response = await fetch(`/api/diff-external/${workspaceId}/close`, { method: 'POST' });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to close external diff tools'));
+  }
+  return response.json();
+}
+
 /**
  * Detects available tools on the system.
  * Returns a list of detected tools with their names, commands, and sources.
@@ -266,6 +468,18 @@ export async function detectTools(): Promise<DetectToolsResponse> {
   return response.json();
 }
 
+/**
+ * Lists every run target the spawn form can offer, with whether each is promptable,
+ * available, and configured.
+ */
+export async function getSpawnOptions(): Promise<SpawnOptionsResponse> {
+  const response = await fetch('/api/spawn-options');
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to fetch spawn options'));
+  }
+  return response.json();
+}
+
 /**
  * Configures secrets for a third-party model.
  */
@@ -276,8 +490,7 @@ export async function configureModelSecrets(modelId: string, secrets: Record<str
     body: JSON.stringify({ secrets })
   });
   if (!response.ok) {
-    const err = await response.text();
-    throw new Error(err || 'Failed to save model secrets');
+    throw new Error(await readErrorMessage(response, 'Failed to save model secrets'));
   }
   return response.json();
 }
@@ -290,8 +503,20 @@ export async function removeModelSecrets(modelId: string): Promise<{ status: str
     method: 'DELETE'
   });
   if (!response.ok) {
-    const err = await response.text();
-    throw new Error(err || 'Failed to remove model secrets');
+    throw new Error(await readErrorMessage(response, 'Failed to remove model secrets'));
+  }
+  return response.json();
+}
+
+export async function testTarget(name: string, timeoutMs?: number): Promise<TargetProbeResult> {
+  const response = await fetch(`/api/targets/${encodeURIComponent(name)}/test`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(timeoutMs ? { timeout_ms: timeoutMs } : {}),
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to test target');
   }
   return response.json();
 }
@@ -302,6 +527,42 @@ export async function getOverlays(): Promise<OverlaysResponse> {
   return response.json();
 }
 
+function overlayFileURL(repoName: string, name: string): string {
+  const encodedName = name.split('/').map(encodeURIComponent).join('/');
+  return `/api/overlays/${encodeURIComponent(repoName)}/files/${encodedName}`;
+}
+
+export async function getOverlayFiles(repoName: string): Promise<OverlayFilesResponse> {
+  const response = await fetch(`/api/overlays/${encodeURIComponent(repoName)}/files`);
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to fetch overlay files');
+  }
+  return response.json();
+}
+
+export async function getOverlayFile(repoName: string, name: string): Promise<OverlayFileContent> {
+  const response = await fetch(overlayFileURL(repoName, name));
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to fetch overlay file');
+  }
+  return response.json();
+}
+
+export async function saveOverlayFile(repoName: string, name: string, content: string): Promise<OverlayFileContent> {
+  const response = await fetch(overlayFileURL(repoName, name), {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ content })
+  });
+  if (!response.ok) {
+    const err = await response.json().catch(() => ({}));
+    throw new Error(err.error || 'Failed to save overlay file');
+  }
+  return response.json();
+}
+
 export async function refreshOverlay(workspaceId: string): Promise<{ status: string }> {
   const response = await fetch(`/api/workspaces/${workspaceId}/refresh-overlay`, {
     method: 'POST',
@@ -314,6 +575,24 @@ export async function refreshOverlay(workspaceId: string): Promise<{ status: str
   return response.json();
 }
 
+export interface OverlayRefreshResult {
+  workspace_id: string;
+  status: 'refreshed' | 'skipped' | 'failed';
+  reason?: string;
+}
+
+export async function refreshRepoOverlay(repoName: string): Promise<{ repo: string; results: OverlayRefreshResult[] }> {
+  const response = await fetch(`/api/repos/${encodeURIComponent(repoName)}/refresh-overlay`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' }
+  });
+  if (!response.ok) {
+    const err = await response.json();
+    throw new Error(err.error || 'Failed to refresh repo overlay');
+  }
+  return response.json();
+}
+
 /**
  * Fetches the list of built-in quick launch presets.
  * Returns a list of preset templates with names, targets, and prompts.
@@ -372,6 +651,22 @@ export async function dismissLinearSyncResolveConflictState(workspaceId: string)
   }
 }
 
+export async function getWorkspaceLock(workspaceId: string): Promise<WorkspaceLockStatus> {
+  const response = await fetch(`/api/workspaces/${workspaceId}/lock`);
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to get workspace lock'));
+  }
+  return response.json();
+}
+
+export async function releaseWorkspaceLock(workspaceId: string): Promise<{ released: boolean; warning?: string }> {
+  const response = await fetch(`/api/workspaces/${workspaceId}/lock`, { method: 'DELETE' });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to release workspace lock'));
+  }
+  return response.json();
+}
+
 export async function getRecentBranches(limit: number = 10): Promise<RecentBranch[]> {
   const response = await fetch(`/api/recent-branches?limit=${limit}`);
   if (!response.ok) {
@@ -380,6 +675,27 @@ export async function getRecentBranches(limit: number = 10): Promise<RecentBranc
   return response.json();
 }
 
+export async function getHistory(filter: HistoryFilter = {}): Promise<HistoryEntry[]> {
+  const params = new URLSearchParams();
+  for (const [key, value] of Object.entries(filter)) {
+    if (value !== undefined && value !== '') params.set(key, String(value));
+  }
+  const response = await fetch(`/api/history?${params.toString()}`);
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to fetch history'));
+  }
+  return response.json();
+}
+
+export async function getBranchExists(repo: string, branch: string): Promise<BranchExistence> {
+  const params = new URLSearchParams({ repo, branch });
+  const response = await fetch(`/api/branch-exists?${params.toString()}`);
+  if (!response.ok) {
+    throw new Error('Failed to check branch');
+  }
+  return response.json();
+}
+
 export async function getGitGraph(
   workspaceId: string,
   opts?: { maxCommits?: number; context?: number }
@@ -394,6 +710,76 @@ export async function getGitGraph(
   return response.json();
 }
 
+export async function getWorkspaceCommits(workspaceId: string, limit?: number): Promise<WorkspaceCommitsResponse> {
+  const qs = limit ? `?limit=${limit}` : '';
+  const response = await fetch(`/api/workspaces/${encodeURIComponent(workspaceId)}/commits${qs}`);
+  if (!response.ok) throw new Error('Failed to fetch workspace commits');
+  return response.json();
+}
+
+export async function getWorkspaceStashes(workspaceId: string): Promise<{ stashes: WorkspaceStash[] }> {
+  const response = await fetch(`/api/workspaces/${encodeURIComponent(workspaceId)}/stashes`);
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to fetch workspace stashes'));
+  }
+  return response.json();
+}
+
+export async function restoreWorkspaceStash(
+  workspaceId: string,
+  index: number,
+  action: 'pop' | 'apply'
+): Promise<{ success: boolean; message: string }> {
+  const response = await fetch(`/api/workspaces/${encodeURIComponent(workspaceId)}/stashes/${index}/${action}`, {
+    method: 'POST',
+  });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, `Failed to ${action} stash`));
+  }
+  return response.json();
+}
+
+// Newest first. enabled is false when sessions.prompt_history_size is 0; prompts
+// recorded before it was turned off are still listed.
+export async function getWorkspacePrompts(workspaceId: string): Promise<{ enabled: boolean; prompts: WorkspacePrompt[] }> {
+  const response = await fetch(`/api/workspaces/${encodeURIComponent(workspaceId)}/prompts`);
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to fetch prompt history'));
+  }
+  return response.json();
+}
+
+export async function rerunWorkspacePrompt(
+  workspaceId: string,
+  promptId: string,
+  options: { target?: string; nickname?: string; allow_protected?: boolean } = {}
+): Promise<SpawnResult[]> {
+  const response = await fetch(`/api/workspaces/${encodeURIComponent(workspaceId)}/prompts/${encodeURIComponent(promptId)}/rerun`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(options)
+  });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to re-run prompt'));
+  }
+  return response.json();
+}
+
+/**
+ * Turns maintenance mode, which pauses the daemon's background git work, on or off.
+ */
+export async function setMaintenance(request: MaintenanceRequest): Promise<MaintenanceResponse> {
+  const response = await fetch('/api/maintenance', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(request)
+  });
+  if (!response.ok) {
+    throw new Error(await readErrorMessage(response, 'Failed to set maintenance mode'));
+  }
+  return response.json();
+}
+
 export async function getPRs(): Promise<PRsResponse> {
   const response = await fetch('/api/prs');
   if (!response.ok) throw new Error('Failed to fetch PRs');
@@ -413,8 +799,7 @@ export async function checkoutPR(repoUrl: string, prNumber: number): Promise<PRC
     body: JSON.stringify({ repo_url: repoUrl, pr_number: prNumber })
   });
   if (!response.ok) {
-    const text = await response.text();
-    throw new Error(text || 'Failed to checkout PR');
+    throw new Error(await readErrorMessage(response, 'Failed to checkout PR'));
   }
   return response.json();
 }
@@ -436,8 +821,7 @@ export async function createRemoteFlavor(request: RemoteFlavorCreateRequest): Pr
     body: JSON.stringify(request)
   });
   if (!response.ok) {
-    const text = await response.text();
-    throw new Error(text || 'Failed to create remote flavor');
+    throw new Error(await readErrorMessage(response, 'Failed to create remote flavor'));
   }
   return response.json();
 }
@@ -449,8 +833,7 @@ export async function updateRemoteFlavor(id: string, request: RemoteFlavorCreate
     body: JSON.stringify(request)
   });
   if (!response.ok) {
-    const text = await response.text();
-    throw new Error(text || 'Failed to update remote flavor');
+    throw new Error(await readErrorMessage(response, 'Failed to update remote flavor'));
   }
   return response.json();
 }
@@ -460,8 +843,7 @@ export async function deleteRemoteFlavor(id: string): Promise<void> {
     method: 'DELETE'
   });
   if (!response.ok) {
-    const text = await response.text();
-    throw new Error(text || 'Failed to delete remote flavor');
+    throw new Error(await readErrorMessage(response, 'Failed to delete remote flavor'));
   }
 }
 
@@ -488,8 +870,7 @@ export async function connectRemoteHost(request: RemoteHostConnectRequest): Prom
     body: JSON.stringify(request)
   });
   if (!response.ok) {
-    const text = await response.text();
-    throw new Error(text || 'Failed to connect to remote host');
+    throw new Error(await readErrorMessage(response, 'Failed to connect to remote host'));
   }
   return response.json();
 }
@@ -499,8 +880,7 @@ export async function reconnectRemoteHost(hostId: string): Promise<RemoteHost> {
     method: 'POST'
   });
   if (!response.ok) {
-    const text = await response.text();
-    throw new Error(text || 'Failed to reconnect to remote host');
+    throw new Error(await readErrorMessage(response, 'Failed to reconnect to remote host'));
   }
   return response.json();
 }
@@ -510,7 +890,6 @@ export async function disconnectRemoteHost(hostId: string): Promise<void> {
     method: 'DELETE'
   });
   if (!response.ok) {
-    const text = await response.text();
-    throw new Error(text || 'Failed to disconnect remote host');
+    throw new Error(await readErrorMessage(response, 'Failed to disconnect remote host'));
   }
 }
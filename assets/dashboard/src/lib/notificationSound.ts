@@ -1,5 +1,7 @@
 // Notification sound utility for nudge state changes
 
+import type { NotificationSound } from './types.generated';
+
 let audioContext: AudioContext | null = null;
 
 function getAudioContext(): AudioContext {
@@ -73,3 +75,60 @@ export const ATTENTION_STATES = new Set([
 export function isAttentionState(state: string | undefined): boolean {
   return state !== undefined && ATTENTION_STATES.has(state);
 }
+
+/**
+ * Play a single soft tone, for informational states such as completion.
+ */
+export async function playChimeSound(): Promise<void> {
+  try {
+    await ensureAudioContextResumed();
+    const ctx = getAudioContext();
+    const now = ctx.currentTime;
+
+    const osc = ctx.createOscillator();
+    const gain = ctx.createGain();
+    osc.connect(gain);
+    gain.connect(ctx.destination);
+    osc.type = 'sine';
+    osc.frequency.setValueAtTime(1046.5, now); // C6
+    gain.gain.setValueAtTime(0, now);
+    gain.gain.linearRampToValueAtTime(0.15, now + 0.02);
+    gain.gain.exponentialRampToValueAtTime(0.001, now + 0.6);
+    osc.start(now);
+    osc.stop(now + 0.6);
+  } catch (e) {
+    console.warn('Failed to play notification sound:', e);
+  }
+}
+
+/**
+ * Play a sound by its config name ("attention" or "chime").
+ */
+export function playNotificationSound(name: string): Promise<void> {
+  return name === 'chime' ? playChimeSound() : playAttentionSound();
+}
+
+/**
+ * Maps nudge display states to the state keys used in notifications.sounds.
+ */
+const NUDGE_STATE_KEYS: Record<string, string> = {
+  'Needs Authorization': 'needs_input',
+  'Needs User Testing': 'needs_testing',
+  'Completed': 'completed',
+  'Error': 'error',
+  'Working': 'working',
+};
+
+/**
+ * Returns the sound to play when a session enters a nudge state, or null for none.
+ * Falls back to the attention states when the server did not send per-state sounds.
+ */
+export function soundForNudgeState(
+  state: string | undefined,
+  sounds: Record<string, NotificationSound> | undefined
+): string | null {
+  if (state === undefined) return null;
+  if (!sounds) return isAttentionState(state) ? 'attention' : null;
+  const sound = sounds[NUDGE_STATE_KEYS[state] ?? state];
+  return sound?.enabled ? sound.sound || 'attention' : null;
+}
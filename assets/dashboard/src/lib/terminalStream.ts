@@ -9,6 +9,7 @@ type TerminalStreamOptions = {
   onResume?: (showing: boolean) => void;
   terminalSize?: TerminalSize | null;
   onSelectedLinesChange?: (lines: string[]) => void;
+  readOnly?: boolean; // watch only; the server drops input from read-only connections
 };
 
 type TerminalOutputMessage = {
@@ -28,6 +29,7 @@ export default class TerminalStream {
   ws: WebSocket | null;
   connected: boolean;
   followTail: boolean;
+  readOnly: boolean;
   followCheckbox: HTMLInputElement | null;
   onStatusChange: (status: 'connected' | 'disconnected' | 'reconnecting' | 'error') => void;
   onResume: (showing: boolean) => void;
@@ -56,6 +58,7 @@ export default class TerminalStream {
     this.ws = null;
     this.connected = false;
     this.followTail = options.followTail !== false;
+    this.readOnly = options.readOnly === true;
     this.followCheckbox = options.followCheckbox || null;
     this.onStatusChange = options.onStatusChange || (() => {});
     this.onResume = options.onResume || (() => {});
@@ -111,6 +114,7 @@ export default class TerminalStream {
       cols,
       rows,
       cursorBlink: true,
+      disableStdin: this.readOnly,
       fontSize: 14,
       fontFamily: 'Menlo, Monaco, "Courier New", monospace',
       allowProposedApi: true,  // Required for registerDecoration API
@@ -283,7 +287,8 @@ export default class TerminalStream {
   connect() {
     if (!this.terminal) return;
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-    const wsUrl = `${protocol}//${window.location.host}/ws/terminal/${this.sessionId}`;
+    const query = this.readOnly ? '?readonly=true' : '';
+    const wsUrl = `${protocol}//${window.location.host}/ws/terminal/${this.sessionId}${query}`;
 
     this.ws = new WebSocket(wsUrl);
 
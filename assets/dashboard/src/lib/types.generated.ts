@@ -1,9 +1,14 @@
 // Code generated by cmd/gen-types; DO NOT EDIT.
 
+export const ATTENTION_COUNT_HEADER = 'X-Attention-Count';
+
 export interface AccessControl {
   enabled: boolean;
   provider: string;
   session_ttl_minutes: number;
+  allow_self_update: boolean;
+  command_allowlist?: string[];
+  read_only: boolean;
 }
 
 export interface AccessControlUpdate {
@@ -14,18 +19,23 @@ export interface AccessControlUpdate {
 
 export interface BranchSuggest {
   target?: string;
+  default_enabled: boolean;
 }
 
 export interface BranchSuggestUpdate {
   target?: string;
+  default_enabled?: boolean;
 }
 
 export interface ConfigResponse {
   workspace_path: string;
   source_code_management: string;
+  prune_base_repos: boolean;
   repos: RepoWithConfig[];
   run_targets: RunTarget[];
+  default_models: Record<string, string>;
   quick_launch: QuickLaunch[];
+  default_spawn?: DefaultSpawn;
   external_diff_commands?: ExternalDiffCommand[];
   external_diff_cleanup_after_ms?: number;
   models: Model[];
@@ -45,9 +55,12 @@ export interface ConfigResponse {
 export interface ConfigUpdateRequest {
   workspace_path?: string;
   source_code_management?: string;
+  prune_base_repos?: boolean;
   repos?: Repo[];
   run_targets?: RunTarget[];
+  default_models?: Record<string, string>;
   quick_launch?: QuickLaunch[];
+  default_spawn?: DefaultSpawn;
   external_diff_commands?: ExternalDiffCommand[];
   external_diff_cleanup_after_ms?: number;
   nudgenik?: NudgenikUpdate;
@@ -65,11 +78,20 @@ export interface ConfigUpdateRequest {
 export interface ConflictResolve {
   target?: string;
   timeout_ms: number;
+  min_confidence: string;
+  review_below_threshold?: boolean;
 }
 
 export interface ConflictResolveUpdate {
   target?: string;
   timeout_ms?: number;
+  min_confidence?: string;
+  review_below_threshold?: boolean;
+}
+
+export interface DefaultSpawn {
+  targets: Record<string, number>;
+  nickname?: string;
 }
 
 export interface ExternalDiffCommand {
@@ -108,6 +130,17 @@ export interface GitGraphResponse {
   dirty_state?: GitGraphDirtyState;
 }
 
+export interface MaintenanceRequest {
+  enabled: boolean;
+  block_spawns?: boolean;
+}
+
+export interface MaintenanceResponse {
+  enabled: boolean;
+  block_spawns: boolean;
+  since?: string;
+}
+
 export interface Model {
   id: string;
   display_name: string;
@@ -121,36 +154,51 @@ export interface Model {
 
 export interface Network {
   bind_address: string;
+  bind_addresses: string[];
   port: number;
   public_base_url: string;
   tls?: TLS;
+  ws_allowed_origins?: string[];
 }
 
 export interface NetworkUpdate {
   bind_address?: string;
+  bind_addresses?: string[];
   port?: number;
   public_base_url?: string;
   tls?: TLSUpdate;
+  ws_allowed_origins?: string[];
+}
+
+export interface NotificationSound {
+  enabled: boolean;
+  sound: string;
 }
 
 export interface Notifications {
   sound_disabled: boolean;
+  sounds: Record<string, NotificationSound>;
 }
 
 export interface NotificationsUpdate {
   sound_disabled?: boolean;
+  sounds?: Record<string, NotificationSound>;
 }
 
 export interface Nudgenik {
   target?: string;
   viewed_buffer_ms: number;
   seen_interval_ms: number;
+  max_concurrent: number;
+  timeout_ms: number;
 }
 
 export interface NudgenikUpdate {
   target?: string;
   viewed_buffer_ms?: number;
   seen_interval_ms?: number;
+  max_concurrent?: number;
+  timeout_ms?: number;
 }
 
 export interface PRsResponse {
@@ -193,6 +241,11 @@ export interface QuickLaunch {
 export interface Repo {
   name: string;
   url: string;
+  ssh_key_path?: string;
+  branch_suggest?: boolean;
+  branch_prefix_regex?: string;
+  pre_dispose_command?: string;
+  clone_filter?: string;
 }
 
 export interface RepoConfig {
@@ -202,6 +255,11 @@ export interface RepoConfig {
 export interface RepoWithConfig {
   name: string;
   url: string;
+  ssh_key_path?: string;
+  branch_suggest?: boolean;
+  branch_prefix_regex?: string;
+  pre_dispose_command?: string;
+  clone_filter?: string;
   default_branch?: string;
   config?: RepoConfig;
 }
@@ -211,20 +269,111 @@ export interface RunTarget {
   type: string;
   command: string;
   source?: string;
+  shell?: string;
+  tmux_options?: Record<string, string>;
+  panes?: TargetPane[];
+  layout?: string;
+  on_exit_command?: string;
 }
 
 export interface Sessions {
   dashboard_poll_interval_ms: number;
+  dashboard_ping_interval_ms: number;
   git_status_poll_interval_ms: number;
   git_clone_timeout_ms: number;
   git_status_timeout_ms: number;
+  git_fetch_timeout_ms: number;
+  git_diff_timeout_ms: number;
+  tmux_socket_name?: string;
+  max_prompt_bytes: number;
+  max_workspaces_per_repo: number;
+  max_per_workspace: number;
+  unavailable_target_policy: string;
+  git_http_proxy?: string;
+  git_author_name?: string;
+  git_author_email?: string;
+  git_sign_commits?: boolean;
+  git_signing_key?: string;
+  nice_level?: number;
+  ionice_class?: string;
+  sandbox_wrapper?: string;
+  protected_branches?: string[];
+  sync_commit_template?: string;
+  dispose_ignore_globs?: string[];
+  history_enabled?: boolean;
+  history_record_prompts?: boolean;
+  prompt_history_size?: number;
+  workspace_env_file: string;
+  offline?: boolean;
+  nickname_collision: string;
+  branch_gone_policy: string;
+  auto_restart_targets?: string[];
+  auto_restart_max_retries: number;
+  auto_restart_backoff_ms: number;
+  auto_restart_disabled?: boolean;
+  failed_remote_grace_ms: number;
 }
 
 export interface SessionsUpdate {
   dashboard_poll_interval_ms?: number;
+  dashboard_ping_interval_ms?: number;
   git_status_poll_interval_ms?: number;
   git_clone_timeout_ms?: number;
   git_status_timeout_ms?: number;
+  git_fetch_timeout_ms?: number;
+  git_diff_timeout_ms?: number;
+  tmux_socket_name?: string;
+  max_prompt_bytes?: number;
+  max_workspaces_per_repo?: number;
+  max_per_workspace?: number;
+  unavailable_target_policy?: string;
+  git_http_proxy?: string;
+  git_author_name?: string;
+  git_author_email?: string;
+  git_sign_commits?: boolean;
+  git_signing_key?: string;
+  nice_level?: number;
+  ionice_class?: string;
+  protected_branches?: string[];
+  sync_commit_template?: string;
+  dispose_ignore_globs?: string[];
+  history_enabled?: boolean;
+  history_record_prompts?: boolean;
+  prompt_history_size?: number;
+  workspace_env_file?: string;
+  offline?: boolean;
+  nickname_collision?: string;
+  branch_gone_policy?: string;
+  auto_restart_targets?: string[];
+  auto_restart_max_retries?: number;
+  auto_restart_backoff_ms?: number;
+  auto_restart_disabled?: boolean;
+  failed_remote_grace_ms?: number;
+}
+
+export interface SpawnOptionsResponse {
+  targets: SpawnTarget[];
+}
+
+export interface SpawnProgressResponse {
+  spawn_id: string;
+  stage?: string;
+  detail?: string;
+  updated_at?: string;
+}
+
+export interface SpawnTarget {
+  name: string;
+  label: string;
+  type: string;
+  source: string;
+  promptable: boolean;
+  available: boolean;
+  configured: boolean;
+  base_tool?: string;
+  required_secrets?: string[];
+  usage_url?: string;
+  models?: string[];
 }
 
 export interface TLS {
@@ -237,11 +386,20 @@ export interface TLSUpdate {
   key_path?: string;
 }
 
+export interface TargetPane {
+  command: string;
+  split?: string;
+  size?: number;
+}
+
 export interface Terminal {
   width: number;
   height: number;
   seed_lines: number;
   bootstrap_lines: number;
+  bootstrap_max_kb: number;
+  output_interval_ms: number;
+  output_max_kb: number;
 }
 
 export interface TerminalUpdate {
@@ -249,6 +407,22 @@ export interface TerminalUpdate {
   height?: number;
   seed_lines?: number;
   bootstrap_lines?: number;
+  bootstrap_max_kb?: number;
+  output_interval_ms?: number;
+  output_max_kb?: number;
+}
+
+export interface WorkspaceCommit {
+  hash: string;
+  short_hash: string;
+  author: string;
+  timestamp: string;
+  subject: string;
+}
+
+export interface WorkspaceCommitsResponse {
+  branch: string;
+  commits: WorkspaceCommit[];
 }
 
 export interface Xterm {
@@ -257,6 +431,7 @@ export interface Xterm {
   operation_timeout_ms: number;
   max_log_size_mb?: number;
   rotated_log_size_mb?: number;
+  retain_after_dispose: boolean;
 }
 
 export interface XtermUpdate {
@@ -265,5 +440,6 @@ export interface XtermUpdate {
   operation_timeout_ms?: number;
   max_log_size_mb?: number;
   rotated_log_size_mb?: number;
+  retain_after_dispose?: boolean;
 }
 
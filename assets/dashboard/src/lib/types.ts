@@ -1,3 +1,7 @@
+// Remote sessions are provisioning -> running (or failed); local sessions are running or
+// stopped, or blocked when queued because the target is unavailable.
+export type SessionStatus = 'running' | 'stopped' | 'blocked' | 'provisioning' | 'failed';
+
 export interface SessionResponse {
   id: string;
   target: string;
@@ -7,6 +11,11 @@ export interface SessionResponse {
   created_at: string;
   last_output_at?: string;
   running: boolean;
+  status: SessionStatus;
+  blocked_reason?: string;
+  fail_reason?: string;
+  restart_count?: number;
+  last_exit_code?: number;
   attach_cmd: string;
   nudge_state?: string;
   nudge_summary?: string;
@@ -36,6 +45,9 @@ export interface WorkspaceResponse {
   remote_flavor_name?: string;
   remote_flavor?: string;
   vcs?: string; // "git", "sapling", etc. Omitted defaults to "git".
+  pinned?: boolean;
+  imported?: boolean;
+  branch_gone?: boolean; // Branch was deleted on the remote (e.g. after its PR merged)
 }
 
 export interface SessionWithWorkspace extends SessionResponse {
@@ -49,6 +61,9 @@ export interface RepoResponse {
   name: string;
   url: string;
   default_branch?: string;  // Detected default branch (main, master, etc.), omitted if not yet detected
+  branch_suggest?: boolean; // Per-repo override of branch_suggest.default_enabled
+  branch_prefix_regex?: string; // New branches must start with a match
+  pre_dispose_command?: string; // Run in the workspace before it is disposed
 }
 
 export interface RunTargetResponse {
@@ -79,13 +94,20 @@ export type {
   GitGraphResponse,
   GitGraphNode,
   GitGraphBranch,
+  WorkspaceCommitsResponse,
+  WorkspaceCommit,
   Model,
   PRsResponse,
   PullRequest,
   PrReview,
   PrReviewUpdate,
   Notifications,
-  NotificationsUpdate
+  NotificationsUpdate,
+  SpawnOptionsResponse,
+  SpawnProgressResponse,
+  SpawnTarget,
+  MaintenanceResponse,
+  MaintenanceRequest
 } from './types.generated';
 
 export interface SpawnRequest {
@@ -97,8 +119,12 @@ export interface SpawnRequest {
   command?: string;                   // command-based spawn (alternative to targets)
   workspace_id?: string;
   quick_launch_name?: string;
+  variables?: Record<string, string>; // values for {{name}} placeholders in the quick launch preset
   resume?: boolean;                   // resume mode: use agent's resume command
   remote_flavor_id?: string;          // optional: spawn on remote host
+  allow_protected?: boolean;          // permit spawning on a sessions.protected_branches branch
+  spawn_id?: string;                  // client-chosen id; enables POST /api/spawn/{id}/cancel
+  base_ref?: string;                  // tag, commit, or branch a new branch starts from
 }
 
 export interface SpawnResult {
@@ -108,11 +134,14 @@ export interface SpawnResult {
   command?: string;  // for command-based spawns
   prompt?: string;
   nickname?: string;
+  status?: string;   // "blocked" when queued because the target is unavailable
   error?: string;
+  conflicted_files?: string[]; // set with workspace_id when pulling the branch hit conflicts
 }
 
 export interface SuggestBranchRequest {
   prompt: string;
+  repo?: string; // repo URL, so the repo's branch_suggest and branch_prefix_regex settings apply
 }
 
 export interface SuggestBranchResponse {
@@ -141,6 +170,16 @@ export interface OverlaysResponse {
   overlays: OverlayInfo[];
 }
 
+export interface OverlayFilesResponse {
+  repo: string;
+  files: string[];
+}
+
+export interface OverlayFileContent {
+  path: string;
+  content: string;
+}
+
 export interface FileDiff {
   old_path?: string;
   new_path?: string;
@@ -150,6 +189,7 @@ export interface FileDiff {
   lines_added: number;
   lines_removed: number;
   is_binary: boolean;
+  patch?: string;       // git's hunks for modified files, set when diff options are requested
 }
 
 export interface DiffResponse {
@@ -164,9 +204,28 @@ export interface OpenVSCodeResponse {
   message: string;
 }
 
+export interface DiffExternalRequest {
+  command?: string;
+  files?: string[];     // glob patterns selecting which changed files to open
+  max_files?: number;   // confirm before opening more files than this (server default 20)
+  confirm?: boolean;    // open every selected file even when over max_files
+}
+
 export interface DiffExternalResponse {
   success: boolean;
   message: string;
+  file_count?: number;
+  needs_confirm?: boolean; // over max_files; resend with confirm to open them anyway
+}
+
+export interface DiffExternalCloseResponse {
+  closed: number; // diff tool processes terminated
+}
+
+export type TargetedFeature = 'nudgenik' | 'branch-suggest' | 'conflict-resolve';
+
+export interface FeatureTarget {
+  target: string; // empty means the feature is off
 }
 
 export interface ScanWorkspace {
@@ -212,6 +271,7 @@ export interface ConflictResolution {
   confidence: string;
   summary: string;
   files: string[];
+  needs_review?: boolean;
 }
 
 export interface LinearSyncResolveConflictResponse {
@@ -236,7 +296,7 @@ export interface LinearSyncResolveConflictStep {
 export interface LinearSyncResolveConflictStatePayload {
   type: 'linear_sync_resolve_conflict';
   workspace_id: string;
-  status: 'in_progress' | 'done' | 'failed';
+  status: 'in_progress' | 'done' | 'failed' | 'needs_review';
   hash?: string;
   started_at: string;
   finished_at?: string;
@@ -245,6 +305,68 @@ export interface LinearSyncResolveConflictStatePayload {
   resolutions?: ConflictResolution[];
 }
 
+export interface BranchExistence {
+  exists_remote: boolean;
+  exists_local: boolean;
+}
+
+export interface WorkspaceLockStatus {
+  workspace_id: string;
+  locked: boolean;
+  holder?: string;
+  started_at?: string;
+}
+
+export interface WorkspaceStash {
+  index: number;
+  ref: string;
+  message: string;
+  timestamp: string;
+}
+
+export interface WorkspacePrompt {
+  id: string;
+  prompt: string;
+  target: string;
+  nickname?: string;
+  spawned_at: string;
+}
+
+export interface TargetProbeResult {
+  target: string;
+  success: boolean;
+  running: boolean;
+  exit_code?: number;
+  output: string;
+  error?: string;
+  duration_ms: number;
+}
+
+export interface HistoryEntry {
+  session_id: string;
+  workspace_id: string;
+  repo?: string;
+  branch?: string;
+  target: string;
+  nickname?: string;
+  prompt?: string;           // only with sessions.history_record_prompts
+  remote_host_id?: string;
+  started_at: string;
+  ended_at: string;
+  exit_status: 'killed' | 'exited' | 'never_started';
+  exit_code?: number;
+}
+
+export interface HistoryFilter {
+  workspace_id?: string;
+  repo?: string;
+  branch?: string;
+  target?: string;
+  since?: string;            // RFC 3339
+  until?: string;            // RFC 3339
+  limit?: number;
+}
+
 export interface RecentBranch {
   repo_name: string;
   repo_url: string;
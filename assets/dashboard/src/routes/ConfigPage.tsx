@@ -1,6 +1,6 @@
 import React, { useEffect, useState } from 'react';
 import { useNavigate, useSearchParams } from 'react-router-dom';
-import { getConfig, updateConfig, configureModelSecrets, removeModelSecrets, getOverlays, getBuiltinQuickLaunch, getAuthSecretsStatus, saveAuthSecrets, getErrorMessage } from '../lib/api';
+import { getConfig, updateConfig, configureModelSecrets, removeModelSecrets, getOverlays, getBuiltinQuickLaunch, getAuthSecretsStatus, saveAuthSecrets, migrateWorkspaces, getErrorMessage } from '../lib/api';
 import { useToast } from '../components/ToastProvider';
 import { useModal } from '../components/ModalProvider';
 import { useConfig } from '../contexts/ConfigContext';
@@ -56,13 +56,19 @@ type ConfigSnapshot = {
   terminalHeight: string;
   terminalSeedLines: string;
   terminalBootstrapLines: string;
+  terminalBootstrapMaxKB: string;
+  terminalOutputIntervalMs: string;
+  terminalOutputMaxKB: string;
   mtimePollInterval: number;
   dashboardPollInterval: number;
+  dashboardPingInterval: number;
   viewedBuffer: number;
   nudgenikSeenInterval: number;
   gitStatusPollInterval: number;
   gitCloneTimeout: number;
   gitStatusTimeout: number;
+  gitFetchTimeout: number;
+  gitDiffTimeout: number;
   xtermQueryTimeout: number;
   xtermOperationTimeout: number;
   maxLogSizeMB: number;
@@ -99,6 +105,8 @@ export default function ConfigPage() {
   const [saving, setSaving] = useState(false);
   const [error, setError] = useState('');
   const [warning, setWarning] = useState('');
+  const [canMigrate, setCanMigrate] = useState(false);
+  const [migrating, setMigrating] = useState(false);
   const { success, error: toastError } = useToast();
 
   // Wizard state
@@ -155,15 +163,21 @@ export default function ConfigPage() {
   const [terminalHeight, setTerminalHeight] = useState('40');
   const [terminalSeedLines, setTerminalSeedLines] = useState('100');
   const [terminalBootstrapLines, setTerminalBootstrapLines] = useState('20000');
+  const [terminalBootstrapMaxKB, setTerminalBootstrapMaxKB] = useState('512');
+  const [terminalOutputIntervalMs, setTerminalOutputIntervalMs] = useState('50');
+  const [terminalOutputMaxKB, setTerminalOutputMaxKB] = useState('64');
 
   // Advanced settings state
   const [mtimePollInterval, setMtimePollInterval] = useState(5000);
   const [dashboardPollInterval, setDashboardPollInterval] = useState(5000);
+  const [dashboardPingInterval, setDashboardPingInterval] = useState(25000);
   const [viewedBuffer, setViewedBuffer] = useState(5000);
   const [nudgenikSeenInterval, setNudgenikSeenInterval] = useState(2000);
   const [gitStatusPollInterval, setGitStatusPollInterval] = useState(10000);
   const [gitCloneTimeout, setGitCloneTimeout] = useState(300000);
   const [gitStatusTimeout, setGitStatusTimeout] = useState(30000);
+  const [gitFetchTimeout, setGitFetchTimeout] = useState(120000);
+  const [gitDiffTimeout, setGitDiffTimeout] = useState(60000);
   const [xtermQueryTimeout, setXtermQueryTimeout] = useState(5000);
   const [xtermOperationTimeout, setXtermOperationTimeout] = useState(10000);
   const [maxLogSizeMB, setMaxLogSizeMB] = useState(50);
@@ -210,13 +224,19 @@ export default function ConfigPage() {
       terminalHeight,
       terminalSeedLines,
       terminalBootstrapLines,
+      terminalBootstrapMaxKB,
+      terminalOutputIntervalMs,
+      terminalOutputMaxKB,
       mtimePollInterval,
       dashboardPollInterval,
+      dashboardPingInterval,
       viewedBuffer,
       nudgenikSeenInterval,
       gitStatusPollInterval,
       gitCloneTimeout,
       gitStatusTimeout,
+      gitFetchTimeout,
+      gitDiffTimeout,
       xtermQueryTimeout,
       xtermOperationTimeout,
       maxLogSizeMB,
@@ -253,13 +273,19 @@ export default function ConfigPage() {
       current.terminalHeight !== originalConfig.terminalHeight ||
       current.terminalSeedLines !== originalConfig.terminalSeedLines ||
       current.terminalBootstrapLines !== originalConfig.terminalBootstrapLines ||
+      current.terminalBootstrapMaxKB !== originalConfig.terminalBootstrapMaxKB ||
+      current.terminalOutputIntervalMs !== originalConfig.terminalOutputIntervalMs ||
+      current.terminalOutputMaxKB !== originalConfig.terminalOutputMaxKB ||
       current.mtimePollInterval !== originalConfig.mtimePollInterval ||
       current.dashboardPollInterval !== originalConfig.dashboardPollInterval ||
+      current.dashboardPingInterval !== originalConfig.dashboardPingInterval ||
       current.viewedBuffer !== originalConfig.viewedBuffer ||
       current.nudgenikSeenInterval !== originalConfig.nudgenikSeenInterval ||
       current.gitStatusPollInterval !== originalConfig.gitStatusPollInterval ||
       current.gitCloneTimeout !== originalConfig.gitCloneTimeout ||
       current.gitStatusTimeout !== originalConfig.gitStatusTimeout ||
+      current.gitFetchTimeout !== originalConfig.gitFetchTimeout ||
+      current.gitDiffTimeout !== originalConfig.gitDiffTimeout ||
       current.xtermQueryTimeout !== originalConfig.xtermQueryTimeout ||
       current.xtermOperationTimeout !== originalConfig.xtermOperationTimeout ||
       current.maxLogSizeMB !== originalConfig.maxLogSizeMB ||
@@ -324,6 +350,9 @@ export default function ConfigPage() {
         setTerminalHeight(String(data.terminal?.height || 40));
         setTerminalSeedLines(String(data.terminal?.seed_lines || 100));
         setTerminalBootstrapLines(String(data.terminal?.bootstrap_lines || 20000));
+        setTerminalBootstrapMaxKB(String(data.terminal?.bootstrap_max_kb || 512));
+        setTerminalOutputIntervalMs(String(data.terminal?.output_interval_ms || 50));
+        setTerminalOutputMaxKB(String(data.terminal?.output_max_kb || 64));
         setRepos(data.repos || []);
 
         const detectedItems = (data.run_targets || []).filter(t => t.source === 'detected');
@@ -352,11 +381,14 @@ export default function ConfigPage() {
 
         setMtimePollInterval(data.xterm?.mtime_poll_interval_ms || 5000);
         setDashboardPollInterval(data.sessions?.dashboard_poll_interval_ms || 5000);
+        setDashboardPingInterval(data.sessions?.dashboard_ping_interval_ms || 25000);
         setViewedBuffer(data.nudgenik?.viewed_buffer_ms || 5000);
         setNudgenikSeenInterval(data.nudgenik?.seen_interval_ms || 2000);
         setGitStatusPollInterval(data.sessions?.git_status_poll_interval_ms || 10000);
         setGitCloneTimeout(data.sessions?.git_clone_timeout_ms || 300000);
         setGitStatusTimeout(data.sessions?.git_status_timeout_ms || 30000);
+        setGitFetchTimeout(data.sessions?.git_fetch_timeout_ms || 120000);
+        setGitDiffTimeout(data.sessions?.git_diff_timeout_ms || 60000);
         setXtermQueryTimeout(data.xterm?.query_timeout_ms || 5000);
         setXtermOperationTimeout(data.xterm?.operation_timeout_ms || 10000);
         setMaxLogSizeMB(data.xterm?.max_log_size_mb || 50);
@@ -392,13 +424,19 @@ export default function ConfigPage() {
             terminalHeight: String(data.terminal?.height || 40),
             terminalSeedLines: String(data.terminal?.seed_lines || 100),
             terminalBootstrapLines: String(data.terminal?.bootstrap_lines || 20000),
+            terminalBootstrapMaxKB: String(data.terminal?.bootstrap_max_kb || 512),
+            terminalOutputIntervalMs: String(data.terminal?.output_interval_ms || 50),
+            terminalOutputMaxKB: String(data.terminal?.output_max_kb || 64),
             mtimePollInterval: data.xterm?.mtime_poll_interval_ms || 5000,
             dashboardPollInterval: data.sessions?.dashboard_poll_interval_ms || 5000,
+            dashboardPingInterval: data.sessions?.dashboard_ping_interval_ms || 25000,
             viewedBuffer: data.nudgenik?.viewed_buffer_ms || 5000,
             nudgenikSeenInterval: data.nudgenik?.seen_interval_ms || 2000,
             gitStatusPollInterval: data.sessions?.git_status_poll_interval_ms || 10000,
             gitCloneTimeout: data.sessions?.git_clone_timeout_ms || 300000,
             gitStatusTimeout: data.sessions?.git_status_timeout_ms || 30000,
+            gitFetchTimeout: data.sessions?.git_fetch_timeout_ms || 120000,
+            gitDiffTimeout: data.sessions?.git_diff_timeout_ms || 60000,
             xtermQueryTimeout: data.xterm?.query_timeout_ms || 5000,
             xtermOperationTimeout: data.xterm?.operation_timeout_ms || 10000,
             maxLogSizeMB: data.xterm?.max_log_size_mb || 50,
@@ -543,7 +581,7 @@ export default function ConfigPage() {
       const updateRequest: ConfigUpdateRequest = {
         workspace_path: workspacePath,
         source_code_management: sourceCodeManagement,
-        terminal: { width, height, seed_lines: seedLines, bootstrap_lines: parseInt(terminalBootstrapLines) },
+        terminal: { width, height, seed_lines: seedLines, bootstrap_lines: parseInt(terminalBootstrapLines), bootstrap_max_kb: parseInt(terminalBootstrapMaxKB), output_interval_ms: parseInt(terminalOutputIntervalMs), output_max_kb: parseInt(terminalOutputMaxKB) },
         repos: repos,
         run_targets: runTargets,
         quick_launch: quickLaunch,
@@ -565,9 +603,12 @@ export default function ConfigPage() {
         },
         sessions: {
           dashboard_poll_interval_ms: dashboardPollInterval,
+          dashboard_ping_interval_ms: dashboardPingInterval,
           git_status_poll_interval_ms: gitStatusPollInterval,
           git_clone_timeout_ms: gitCloneTimeout,
           git_status_timeout_ms: gitStatusTimeout,
+          git_fetch_timeout_ms: gitFetchTimeout,
+          git_diff_timeout_ms: gitDiffTimeout,
         },
         xterm: {
           mtime_poll_interval_ms: mtimePollInterval,
@@ -623,13 +664,19 @@ export default function ConfigPage() {
           terminalHeight,
           terminalSeedLines,
           terminalBootstrapLines,
+          terminalBootstrapMaxKB,
+          terminalOutputIntervalMs,
+          terminalOutputMaxKB,
           mtimePollInterval,
           dashboardPollInterval,
+          dashboardPingInterval,
           viewedBuffer,
           nudgenikSeenInterval,
           gitStatusPollInterval,
           gitCloneTimeout,
           gitStatusTimeout,
+          gitFetchTimeout,
+          gitDiffTimeout,
           xtermQueryTimeout,
           xtermOperationTimeout,
           maxLogSizeMB,
@@ -647,6 +694,7 @@ export default function ConfigPage() {
 
       if (result.warning && !isFirstRun) {
         setWarning(result.warning);
+        setCanMigrate(true);
       } else if (!isFirstRun) {
         success('Configuration saved');
       }
@@ -839,6 +887,27 @@ export default function ConfigPage() {
   const [runTargetEditModal, setRunTargetEditModal] = useState<RunTargetEditModalState>(null);
   const [quickLaunchEditModal, setQuickLaunchEditModal] = useState<QuickLaunchEditModalState>(null);
 
+  const handleMigrateWorkspaces = async () => {
+    setMigrating(true);
+    try {
+      const { results } = await migrateWorkspaces();
+      const moved = results.filter((r) => r.status === 'moved').length;
+      const notMoved = results.filter((r) => r.status !== 'moved');
+      if (notMoved.length === 0) {
+        setWarning('');
+        setCanMigrate(false);
+        success(`Moved ${moved} workspace${moved === 1 ? '' : 's'}`);
+      } else {
+        const details = notMoved.map((r) => `${r.workspace_id}: ${r.reason || r.status}`).join('; ');
+        setWarning(`Moved ${moved} workspace${moved === 1 ? '' : 's'}. Not moved: ${details}`);
+      }
+    } catch (err) {
+      toastError(getErrorMessage(err, 'Failed to move workspaces'));
+    } finally {
+      setMigrating(false);
+    }
+  };
+
   const handleEditWorkspacePath = async () => {
     const newPath = await prompt('Edit Workspace Directory', {
       defaultValue: workspacePath,
@@ -1132,6 +1201,16 @@ export default function ConfigPage() {
           <p style={{ margin: 0 }}>
             <strong>Warning:</strong> {warning}
           </p>
+          {canMigrate && (
+            <button
+              className="btn btn--sm"
+              style={{ marginTop: 'var(--spacing-sm)' }}
+              onClick={handleMigrateWorkspaces}
+              disabled={migrating}
+            >
+              {migrating ? 'Moving...' : 'Move idle workspaces to the new directory'}
+            </button>
+          )}
         </div>
       )}
 
@@ -2280,6 +2359,42 @@ export default function ConfigPage() {
                       />
                       <p className="form-group__hint">Lines to send on initial WebSocket connection (default: 20000)</p>
                     </div>
+
+                    <div className="form-group">
+                      <label className="form-group__label">Bootstrap Max (KB)</label>
+                      <input
+                        type="number"
+                        className="input"
+                        min="1"
+                        value={terminalBootstrapMaxKB}
+                        onChange={(e) => setTerminalBootstrapMaxKB(e.target.value)}
+                      />
+                      <p className="form-group__hint">Size cap for scrollback sent on connect; oldest output is dropped first (default: 512)</p>
+                    </div>
+
+                    <div className="form-group">
+                      <label className="form-group__label">Output Interval (ms)</label>
+                      <input
+                        type="number"
+                        className="input"
+                        min="1"
+                        value={terminalOutputIntervalMs}
+                        onChange={(e) => setTerminalOutputIntervalMs(e.target.value)}
+                      />
+                      <p className="form-group__hint">How often live output is flushed to the browser (default: 50)</p>
+                    </div>
+
+                    <div className="form-group">
+                      <label className="form-group__label">Output Max per Interval (KB)</label>
+                      <input
+                        type="number"
+                        className="input"
+                        min="1"
+                        value={terminalOutputMaxKB}
+                        onChange={(e) => setTerminalOutputMaxKB(e.target.value)}
+                      />
+                      <p className="form-group__hint">Rate cap for live output so a flood can't freeze the tab (default: 64)</p>
+                    </div>
                   </div>
                 </div>
               </div>
@@ -2302,6 +2417,18 @@ export default function ConfigPage() {
                       <p className="form-group__hint">How often to refresh sessions list</p>
                     </div>
 
+                    <div className="form-group">
+                      <label className="form-group__label">Dashboard Ping Interval (ms)</label>
+                      <input
+                        type="number"
+                        className="input input--compact"
+                        min="1000"
+                        value={dashboardPingInterval === 0 ? '' : dashboardPingInterval}
+                        onChange={(e) => setDashboardPingInterval(e.target.value === '' ? 0 : parseInt(e.target.value) || 25000)}
+                      />
+                      <p className="form-group__hint">How often to ping the live update connection; keep it under your proxy's idle timeout</p>
+                    </div>
+
                     <div className="form-group">
                       <label className="form-group__label">Git Status Poll Interval (ms)</label>
                       <input
@@ -2337,7 +2464,31 @@ export default function ConfigPage() {
                         value={gitStatusTimeout === 0 ? '' : gitStatusTimeout}
                         onChange={(e) => setGitStatusTimeout(e.target.value === '' ? 0 : parseInt(e.target.value) || 30000)}
                       />
-                      <p className="form-group__hint">Maximum time to wait for git status/diff operations (default: 30000ms)</p>
+                      <p className="form-group__hint">Maximum time to wait for git status operations (default: 30000ms)</p>
+                    </div>
+
+                    <div className="form-group">
+                      <label className="form-group__label">Git Fetch Timeout (ms)</label>
+                      <input
+                        type="number"
+                        className="input input--compact"
+                        min="100"
+                        value={gitFetchTimeout === 0 ? '' : gitFetchTimeout}
+                        onChange={(e) => setGitFetchTimeout(e.target.value === '' ? 0 : parseInt(e.target.value) || 120000)}
+                      />
+                      <p className="form-group__hint">Maximum time to wait for fetches and branch lookups on origin (default: 120000ms = 2 min)</p>
+                    </div>
+
+                    <div className="form-group">
+                      <label className="form-group__label">Git Diff Timeout (ms)</label>
+                      <input
+                        type="number"
+                        className="input input--compact"
+                        min="100"
+                        value={gitDiffTimeout === 0 ? '' : gitDiffTimeout}
+                        onChange={(e) => setGitDiffTimeout(e.target.value === '' ? 0 : parseInt(e.target.value) || 60000)}
+                      />
+                      <p className="form-group__hint">Maximum time to wait for diffs and commit logs (default: 60000ms = 1 min)</p>
                     </div>
                   </div>
                 </div>
@@ -8,6 +8,7 @@ import { useSessions } from '../contexts/SessionsContext';
 import { useModal } from '../components/ModalProvider';
 import useLocalStorage from '../hooks/useLocalStorage';
 import WorkspaceHeader from '../components/WorkspaceHeader';
+import GitPatchView from '../components/GitPatchView';
 import SessionTabs from '../components/SessionTabs';
 import type { DiffResponse } from '../lib/types';
 
@@ -22,6 +23,10 @@ const BUILTIN_DIFF_COMMANDS: ExternalDiffCommand[] = [
 ];
 
 const DIFF_SIDEBAR_WIDTH_KEY = 'schmux-diff-sidebar-width';
+const DIFF_IGNORE_WHITESPACE_KEY = 'schmux-diff-ignore-whitespace';
+const DIFF_ALGORITHM_KEY = 'schmux-diff-algorithm';
+// git diff --diff-algorithm values accepted by GET /api/diff/{id}; '' keeps git's default
+const DIFF_ALGORITHMS = ['', 'myers', 'minimal', 'patience', 'histogram'];
 const DEFAULT_SIDEBAR_WIDTH = 300;
 const MIN_SIDEBAR_WIDTH = 150;
 const MAX_SIDEBAR_WIDTH = 600;
@@ -40,7 +45,7 @@ export default function DiffPage() {
   const { theme } = useTheme();
   const { config } = useConfig();
   const { workspaces } = useSessions();
-  const { alert } = useModal();
+  const { alert, confirm } = useModal();
   const [diffData, setDiffData] = useState<DiffResponse | null>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState('');
@@ -48,6 +53,8 @@ export default function DiffPage() {
   const [executingDiff, setExecutingDiff] = useState<string | null>(null);
   const [sidebarWidth, setSidebarWidth] = useLocalStorage<number>(DIFF_SIDEBAR_WIDTH_KEY, DEFAULT_SIDEBAR_WIDTH);
   const [isResizing, setIsResizing] = useState(false);
+  const [ignoreWhitespace, setIgnoreWhitespace] = useLocalStorage<boolean>(DIFF_IGNORE_WHITESPACE_KEY, false);
+  const [diffAlgorithm, setDiffAlgorithm] = useLocalStorage<string>(DIFF_ALGORITHM_KEY, '');
   const containerRef = useRef<HTMLDivElement>(null);
   const contentRef = useRef<HTMLDivElement>(null);
   const prevGitStatsRef = useRef<{ files: number; added: number; removed: number } | null>(null);
@@ -99,7 +106,12 @@ export default function DiffPage() {
     if (!workspaceId) return;
     setExecutingDiff(cmd.name);
     try {
-      const response = await diffExternal(workspaceId, cmd.command);
+      let response = await diffExternal(workspaceId, cmd.command);
+      if (response.needs_confirm) {
+        const proceed = await confirm(`${response.message}. Open all ${response.file_count} files?`, { confirmText: 'Open all' });
+        if (!proceed) return;
+        response = await diffExternal(workspaceId, cmd.command, { confirm: true });
+      }
       const title = response.success ? 'Diff tool opened' : 'Failed to open diff tool';
       await alert(title, response.message);
     } catch (err) {
@@ -114,7 +126,7 @@ export default function DiffPage() {
       setLoading(true);
       setError('');
       try {
-        const data = await getDiff(workspaceId || '');
+        const data = await getDiff(workspaceId || '', { ignoreWhitespace, algorithm: diffAlgorithm });
         setDiffData(data);
 
         // Restore selected file from localStorage by file path (not index)
@@ -140,7 +152,7 @@ export default function DiffPage() {
       }
     };
     loadDiff();
-  }, [workspaceId]);
+  }, [workspaceId, ignoreWhitespace, diffAlgorithm]);
 
   // Reload diff data when workspace git stats change (file system changes)
   useEffect(() => {
@@ -166,7 +178,7 @@ export default function DiffPage() {
         setLoading(true);
         setError('');
         try {
-          const data = await getDiff(workspaceId || '');
+          const data = await getDiff(workspaceId || '', { ignoreWhitespace, algorithm: diffAlgorithm });
           setDiffData(data);
 
           // Try to restore the same file by path if it still exists
@@ -194,7 +206,7 @@ export default function DiffPage() {
     }
 
     prevGitStatsRef.current = currentStats;
-  }, [workspace, workspaceId, selectedFileIndex, diffData]);
+  }, [workspace, workspaceId, selectedFileIndex, diffData, ignoreWhitespace, diffAlgorithm]);
 
   const selectedFile = diffData?.files?.[selectedFileIndex];
 
@@ -345,6 +357,25 @@ export default function DiffPage() {
               {executingDiff === cmd.name ? <div className="spinner--small"></div> : cmd.name}
             </button>
           ))}
+          <label className="diff-actions__label" style={{ marginLeft: 'auto', display: 'flex', alignItems: 'center', gap: 'var(--spacing-xs)', cursor: 'pointer' }}>
+            <input
+              type="checkbox"
+              checked={ignoreWhitespace}
+              onChange={(e) => setIgnoreWhitespace(e.target.checked)}
+            />
+            Ignore whitespace
+          </label>
+          <select
+            className="select"
+            value={diffAlgorithm}
+            onChange={(e) => setDiffAlgorithm(e.target.value)}
+            title="git diff algorithm"
+            style={{ width: 'auto' }}
+          >
+            {DIFF_ALGORITHMS.map((algorithm) => (
+              <option key={algorithm} value={algorithm}>{algorithm || 'default algorithm'}</option>
+            ))}
+          </select>
         </div>
 
         <div className="diff-layout" ref={containerRef}>
@@ -396,6 +427,8 @@ export default function DiffPage() {
                     <div className="diff-binary-notice">
                       Binary file not shown
                     </div>
+                  ) : selectedFile.patch ? (
+                    <GitPatchView patch={selectedFile.patch} />
                   ) : (
                     <ReactDiffViewer
                       oldValue={selectedFile.old_content || ''}
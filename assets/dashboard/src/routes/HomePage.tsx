@@ -260,7 +260,7 @@ export default function HomePage() {
         )}
 
         {/* Primary Action - Spawn New Session (only when no workspaces) */}
-        {workspaces.length === 0 && (
+        {workspaces.length === 0 && !config?.access_control?.read_only && (
           <Link to="/spawn" className={styles.primaryAction}>
             <span className={styles.primaryActionIcon}><RocketIcon /></span>
             <span className={styles.primaryActionText}>
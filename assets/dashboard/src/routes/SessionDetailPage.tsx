@@ -1,5 +1,5 @@
 import React, { useCallback, useEffect, useRef, useState } from 'react';
-import { Link, useParams, useNavigate } from 'react-router-dom';
+import { Link, useParams, useNavigate, useSearchParams } from 'react-router-dom';
 import '@xterm/xterm/css/xterm.css';
 import TerminalStream from '../lib/terminalStream';
 import { updateNickname, disposeSession, reconnectRemoteHost, getErrorMessage } from '../lib/api';
@@ -21,6 +21,8 @@ export default function SessionDetailPage() {
   const { config, loading: configLoading } = useConfig();
   const { sessionsById, workspaces, loading: sessionsLoading, error: sessionsError } = useSessions();
   const navigate = useNavigate();
+  const [searchParams] = useSearchParams();
+  const readOnly = searchParams.get('readonly') === 'true';
   const [wsStatus, setWsStatus] = useState<'connecting' | 'connected' | 'disconnected' | 'reconnecting' | 'error'>('connecting');
   const [showResume, setShowResume] = useState(false);
   const [followTail, setFollowTail] = useState(true);
@@ -106,7 +108,8 @@ export default function SessionDetailPage() {
         setFollowTail(!showing);
       },
       onStatusChange: (status) => setWsStatus(status),
-      onSelectedLinesChange: (lines) => setSelectedLines(lines)
+      onSelectedLinesChange: (lines) => setSelectedLines(lines),
+      readOnly
     });
 
     terminalStreamRef.current = terminalStream;
@@ -120,7 +123,7 @@ export default function SessionDetailPage() {
     return () => {
       terminalStream.disconnect();
     };
-  }, [sessionData?.id, configLoading, config?.terminal, remoteDisconnected]);
+  }, [sessionData?.id, configLoading, config?.terminal, remoteDisconnected, readOnly]);
 
   useEffect(() => {
     if (!sessionData?.id) return;
@@ -1,6 +1,6 @@
 import { useEffect, useMemo, useRef, useState, useCallback } from 'react';
 import { useSearchParams, useNavigate, useLocation } from 'react-router-dom';
-import { getConfig, spawnSessions, getErrorMessage, suggestBranch } from '../lib/api';
+import { getSpawnOptions, spawnSessions, cancelSpawn, getSpawnProgress, getErrorMessage, suggestBranch } from '../lib/api';
 import { useToast } from '../components/ToastProvider';
 import { useRequireConfig, useConfig } from '../contexts/ConfigContext';
 import { useSessions } from '../contexts/SessionsContext';
@@ -9,7 +9,7 @@ import WorkspaceHeader from '../components/WorkspaceHeader';
 import SessionTabs from '../components/SessionTabs';
 import PromptTextarea from '../components/PromptTextarea';
 import RemoteHostSelector, { type EnvironmentSelection } from '../components/RemoteHostSelector';
-import type { Model, RepoResponse, RunTargetResponse, SpawnResult, SuggestBranchResponse, RemoteFlavor } from '../lib/types';
+import type { RepoResponse, SpawnProgressResponse, SpawnTarget, SpawnResult, SuggestBranchResponse, RemoteFlavor } from '../lib/types';
 import { WORKSPACE_EXPANDED_KEY } from '../lib/constants';
 
 
@@ -64,6 +64,15 @@ function clearSpawnDraft(workspaceId: string | null): void {
   }
 }
 
+// Button labels for the workspace steps reported by GET /api/spawn/{id}/progress
+const SPAWN_STAGE_LABELS: Record<string, string> = {
+  cloning: 'Cloning...',
+  fetching: 'Fetching...',
+  checking_out: 'Checking out...',
+  pulling: 'Pulling...',
+  copying_overlays: 'Copying overlays...',
+};
+
 // ============================================================================
 // Layer 3: Local Storage (Long-term Memory)
 // Cross-tab, never auto-cleared, updated on successful spawn
@@ -142,10 +151,8 @@ function saveLastModelSelectionMode(mode: 'single' | 'multiple' | 'advanced'): v
 
 export default function SpawnPage() {
   useRequireConfig();
-  const [repos, setRepos] = useState<RepoResponse[]>([]);
-  const [promptableTargets, setPromptableTargets] = useState<RunTargetResponse[]>([]);
-  const [commandTargets, setCommandTargets] = useState<RunTargetResponse[]>([]);
-  const [models, setModels] = useState<Model[]>([]);
+  const [promptableTargets, setPromptableTargets] = useState<SpawnTarget[]>([]);
+  const [commandTargets, setCommandTargets] = useState<SpawnTarget[]>([]);
   const [selectedCommand, setSelectedCommand] = useState('');
   const [spawnMode, setSpawnMode] = useState<'promptable' | 'command' | 'resume'>('promptable');
   const [repo, setRepo] = useState('');
@@ -166,10 +173,15 @@ export default function SpawnPage() {
   const { error: toastError } = useToast();
   const { workspaces, loading: sessionsLoading, waitForSession } = useSessions();
   const { setPendingNavigation } = usePendingNavigation();
-  const { config } = useConfig();
+  const { config, loading: configLoading } = useConfig();
 
   const location = useLocation();
 
+  const repos = useMemo<RepoResponse[]>(
+    () => [...(config?.repos || [])].sort((a, b) => a.name.localeCompare(b.name)),
+    [config]
+  );
+
   // Precompute URL -> default branch map for O(1) lookups
   const defaultBranchMap = useMemo(() => {
     const map = new Map<string, string>();
@@ -192,6 +204,8 @@ export default function SpawnPage() {
     return 'fresh';
   })();
   const initialized = useRef(false);
+  const spawnIdRef = useRef<string | null>(null);
+  const [spawnProgress, setSpawnProgress] = useState<SpawnProgressResponse | null>(null);
 
   const isMounted = useRef(true);
   const navigate = useNavigate();
@@ -208,18 +222,20 @@ export default function SpawnPage() {
     }
   }, [inExistingWorkspace, resolvedWorkspaceId, workspaceExists, sessionsLoading, navigate]);
 
-  // Get branch suggest target from config
+  // Branch suggestion needs a target, and the selected repo can turn it on or off
   const branchSuggestTarget = config?.branch_suggest?.target || '';
+  const repoBranchSuggest = config?.repos?.find((r) => r.url === repo)?.branch_suggest;
+  const branchSuggestEnabled = !!branchSuggestTarget && (repoBranchSuggest ?? config?.branch_suggest?.default_enabled ?? true);
 
   // Remote flavors without a provisioning command don't need repo/branch selection
   const isRemoteWithoutProvisioning = environment.type === 'remote' && !environment.flavor.provision_command;
 
   // Show branch input immediately when suggestion is disabled
   useEffect(() => {
-    if (mode === 'fresh' && !branchSuggestTarget && config) {
+    if (mode === 'fresh' && !branchSuggestEnabled && config) {
       setShowBranchInput(true);
     }
-  }, [mode, branchSuggestTarget, config]);
+  }, [mode, branchSuggestEnabled, config]);
 
   useEffect(() => {
     return () => {
@@ -227,7 +243,7 @@ export default function SpawnPage() {
     };
   }, []);
 
-  // Load config and data
+  // Load run targets and models (repos come from the config context)
   useEffect(() => {
     let active = true;
 
@@ -235,27 +251,15 @@ export default function SpawnPage() {
       setLoading(true);
       setConfigError('');
       try {
-        const cfg = await getConfig();
+        const { targets } = await getSpawnOptions();
         if (!active) return;
-        setRepos((cfg.repos || []).sort((a, b) => a.name.localeCompare(b.name)));
-        const modelBaseTools = new Set((cfg.models || []).map((model) => model.base_tool));
-        const promptableItems = (cfg.run_targets || []).filter(t => {
-          if (t.type !== 'promptable') {
-            return false;
-          }
-          if (t.source === 'detected' && modelBaseTools.has(t.name)) {
-            return false;
-          }
-          return true;
-        }).sort((a, b) => a.name.localeCompare(b.name));
-        const commandItems = (cfg.run_targets || []).filter(t => t.type === 'command').sort((a, b) => a.name.localeCompare(b.name));
-        setPromptableTargets(promptableItems);
-        setCommandTargets(commandItems);
-        setModels(cfg.models || []);
-
+        // Targets arrive sorted by name. A detected tool that runs models is offered
+        // through those models rather than on its own.
+        setPromptableTargets(targets.filter(t => t.type === 'promptable' && !(t.source === 'detected' && (t.models || []).length > 0)));
+        setCommandTargets(targets.filter(t => t.type === 'command'));
       } catch (err) {
         if (!active) return;
-        setConfigError(getErrorMessage(err, 'Failed to load config'));
+        setConfigError(getErrorMessage(err, 'Failed to load spawn options'));
       } finally {
         if (active) setLoading(false);
       }
@@ -274,6 +278,8 @@ export default function SpawnPage() {
   // Three-layer waterfall: Mode Logic → sessionStorage → localStorage → Default
   useEffect(() => {
     if (initialized.current) return;
+    // default_spawn comes from the config, so wait for it before applying defaults
+    if (configLoading) return;
 
     // Layer 1: Mode Logic (Entry Point)
     if (mode === 'workspace') {
@@ -305,6 +311,14 @@ export default function SpawnPage() {
     const lastTargetCounts = loadLastTargetCounts();
     const lastModelSelectionMode = loadLastModelSelectionMode();
 
+    // Config default_spawn: preferred over the last-used targets, but not over a draft
+    const defaultTargetCounts = config.default_spawn?.targets;
+    const defaultModelSelectionMode = defaultTargetCounts
+      ? Object.values(defaultTargetCounts).some((count) => count > 1)
+        ? 'advanced'
+        : Object.keys(defaultTargetCounts).length > 1 ? 'multiple' : 'single'
+      : undefined;
+
     // Apply three-layer waterfall for each field
     if (mode === 'workspace' || mode === 'prefilled') {
       // prompt: draft (workspace/prefilled already set prompt in Layer 1 for prefilled)
@@ -313,13 +327,15 @@ export default function SpawnPage() {
       }
       // spawnMode: draft → default
       setSpawnMode(draft?.spawnMode || 'promptable');
-      // modelSelectionMode: draft → localStorage → default
-      setModelSelectionMode(draft?.modelSelectionMode || lastModelSelectionMode || 'single');
+      // modelSelectionMode: draft → default_spawn → localStorage → default
+      setModelSelectionMode(draft?.modelSelectionMode || defaultModelSelectionMode || lastModelSelectionMode || 'single');
       // selectedCommand: draft → default
       if (draft?.selectedCommand) setSelectedCommand(draft.selectedCommand);
-      // targetCounts: draft → localStorage → default
+      // targetCounts: draft → default_spawn → localStorage → default
       if (draft?.targetCounts) {
         setTargetCounts(draft.targetCounts);
+      } else if (defaultTargetCounts) {
+        setTargetCounts(defaultTargetCounts);
       } else if (lastTargetCounts) {
         setTargetCounts(lastTargetCounts);
       }
@@ -328,17 +344,21 @@ export default function SpawnPage() {
       setRepo(draft?.repo || lastRepo || '');
       // newRepoName: draft → default
       if (draft?.newRepoName) setNewRepoName(draft.newRepoName);
+      // nickname: default_spawn → default
+      if (config.default_spawn?.nickname) setNickname(config.default_spawn.nickname);
       // prompt: draft → default
       if (draft?.prompt) setPrompt(draft.prompt);
       // spawnMode: draft → default
       setSpawnMode(draft?.spawnMode || 'promptable');
-      // modelSelectionMode: draft → localStorage → default
-      setModelSelectionMode(draft?.modelSelectionMode || lastModelSelectionMode || 'single');
+      // modelSelectionMode: draft → default_spawn → localStorage → default
+      setModelSelectionMode(draft?.modelSelectionMode || defaultModelSelectionMode || lastModelSelectionMode || 'single');
       // selectedCommand: draft → default
       if (draft?.selectedCommand) setSelectedCommand(draft.selectedCommand);
-      // targetCounts: draft → localStorage → default
+      // targetCounts: draft → default_spawn → localStorage → default
       if (draft?.targetCounts) {
         setTargetCounts(draft.targetCounts);
+      } else if (defaultTargetCounts) {
+        setTargetCounts(defaultTargetCounts);
       } else if (lastTargetCounts) {
         setTargetCounts(lastTargetCounts);
       }
@@ -346,7 +366,7 @@ export default function SpawnPage() {
 
     initialized.current = true;
     skipNextPersist.current = true;
-  }, [mode, sessionsLoading, workspaces, searchParams, urlWorkspaceId, location.state]);
+  }, [mode, sessionsLoading, configLoading, config, workspaces, searchParams, urlWorkspaceId, location.state]);
 
   type PromptableListItem = {
     name: string;
@@ -354,12 +374,11 @@ export default function SpawnPage() {
   };
 
   const promptableList = useMemo<PromptableListItem[]>(() => {
-    const modelLabels = new Map(models.map((model) => [model.id, model.display_name]));
     return promptableTargets.map((target) => ({
       name: target.name,
-      label: modelLabels.get(target.name) || target.name,
+      label: target.label,
     }));
-  }, [models, promptableTargets]);
+  }, [promptableTargets]);
 
   const [targetCounts, setTargetCounts] = useState<Record<string, number>>({});
   const [modelSelectionMode, setModelSelectionMode] = useState<'single' | 'multiple' | 'advanced'>('single');
@@ -466,12 +485,12 @@ export default function SpawnPage() {
     });
   };
 
-  const generateBranchName = useCallback(async (promptText: string): Promise<{ result: SuggestBranchResponse | null; error: string | null }> => {
+  const generateBranchName = useCallback(async (promptText: string, repoUrl: string): Promise<{ result: SuggestBranchResponse | null; error: string | null }> => {
     if (!promptText.trim()) {
       return { result: null, error: 'Empty prompt' };
     }
     try {
-      const result = await suggestBranch({ prompt: promptText });
+      const result = await suggestBranch({ prompt: promptText, repo: repoUrl });
       return { result, error: null };
     } catch (err) {
       console.error('Failed to suggest branch:', err);
@@ -504,7 +523,7 @@ export default function SpawnPage() {
         toastError('Please enter a repository name');
         return false;
       }
-      if (mode === 'fresh' && !branchSuggestTarget && !branch.trim()) {
+      if (mode === 'fresh' && !branchSuggestEnabled && !branch.trim()) {
         toastError('Please enter a branch name');
         return false;
       }
@@ -546,6 +565,42 @@ export default function SpawnPage() {
     setSelectedCommand('');
   };
 
+  // Poll the workspace step while spawning; a first clone of a large repo can take minutes
+  useEffect(() => {
+    if (engagePhase !== 'spawning') {
+      setSpawnProgress(null);
+      return;
+    }
+    const spawnId = spawnIdRef.current;
+    if (!spawnId) return;
+    let active = true;
+    const poll = async () => {
+      try {
+        const progress = await getSpawnProgress(spawnId);
+        if (active) setSpawnProgress(progress);
+      } catch (err) {
+        console.warn('Failed to get spawn progress:', err);
+      }
+    };
+    void poll();
+    const interval = setInterval(poll, 1000);
+    return () => {
+      active = false;
+      clearInterval(interval);
+    };
+  }, [engagePhase]);
+
+  // Handle "Cancel" button while spawning - the spawn request resolves with cancelled results
+  const handleCancelSpawn = async () => {
+    const spawnId = spawnIdRef.current;
+    if (!spawnId) return;
+    try {
+      await cancelSpawn(spawnId);
+    } catch (err) {
+      toastError(`Failed to cancel spawn: ${getErrorMessage(err, 'Unknown error')}`);
+    }
+  };
+
   const handleEngage = async () => {
     if (!validateForm()) return;
 
@@ -572,10 +627,10 @@ export default function SpawnPage() {
         // Resume mode: use default branch (no prompt to suggest from)
         actualBranch = getDefaultBranch(actualRepo);
         actualNickname = '';
-      } else if (spawnMode === 'promptable' && prompt.trim() && branchSuggestTarget) {
+      } else if (spawnMode === 'promptable' && prompt.trim() && branchSuggestEnabled) {
         // Call branch suggest API
         setEngagePhase('naming');
-        const { result, error } = await generateBranchName(prompt);
+        const { result, error } = await generateBranchName(prompt, actualRepo);
         if (!isMounted.current) return;
         if (result && result.branch.trim()) {
           actualBranch = result.branch;
@@ -600,10 +655,13 @@ export default function SpawnPage() {
     }
 
     // Spawn
+    const spawnId = `spawn-${Date.now().toString(36)}-${Math.random().toString(36).slice(2, 8)}`;
+    spawnIdRef.current = spawnId;
     setEngagePhase('spawning');
 
     try {
       const response = await spawnSessions({
+        spawn_id: spawnId,
         repo: actualRepo,
         branch: actualBranch,
         prompt: spawnMode === 'promptable' ? prompt : '',
@@ -685,7 +743,7 @@ export default function SpawnPage() {
     return () => window.removeEventListener('keydown', handleKeyDown);
   }, [handleEngage]);
 
-  if (loading) {
+  if (loading || configLoading) {
     return (
       <div className="loading-state">
         <div className="spinner"></div>
@@ -698,7 +756,7 @@ export default function SpawnPage() {
     return (
       <div className="empty-state">
         <div className="empty-state__icon">⚠️</div>
-        <h3 className="empty-state__title">Failed to load config</h3>
+        <h3 className="empty-state__title">Failed to load spawn options</h3>
         <p className="empty-state__description">{configError}</p>
       </div>
     );
@@ -1019,7 +1077,17 @@ export default function SpawnPage() {
         </div>
       )}
 
+      {engagePhase === 'spawning' && spawnProgress?.detail && (
+        <div style={{ marginTop: 'var(--spacing-lg)', textAlign: 'right', fontSize: '0.75rem', color: 'var(--color-text-muted)', fontFamily: 'monospace' }}>
+          {spawnProgress.detail}
+        </div>
+      )}
       <div style={{ marginTop: 'var(--spacing-lg)', display: 'flex', gap: 'var(--spacing-sm)', justifyContent: 'flex-end' }}>
+        {engagePhase === 'spawning' && (
+          <button className="btn" onClick={handleCancelSpawn}>
+            Cancel
+          </button>
+        )}
         {(spawnMode === 'command' || spawnMode === 'resume') && (
           <button className="btn" onClick={handlePromptMode} disabled={engagePhase !== 'idle'}>
             Prompt
@@ -1039,7 +1107,7 @@ export default function SpawnPage() {
           ) : engagePhase === 'spawning' ? (
             <>
               <span className="spinner spinner--small"></span>
-              Spawning...
+              {SPAWN_STAGE_LABELS[spawnProgress?.stage || ''] || 'Spawning...'}
             </>
           ) : engagePhase === 'waiting' ? (
             <>
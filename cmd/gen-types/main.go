@@ -23,12 +23,26 @@ type typeDef struct {
 	fields []fieldDef
 }
 
+type constDef struct {
+	name  string
+	value string
+}
+
 func main() {
 	rootTypes := []reflect.Type{
 		reflect.TypeOf(contracts.ConfigResponse{}),
 		reflect.TypeOf(contracts.ConfigUpdateRequest{}),
 		reflect.TypeOf(contracts.GitGraphResponse{}),
+		reflect.TypeOf(contracts.WorkspaceCommitsResponse{}),
 		reflect.TypeOf(contracts.PRsResponse{}),
+		reflect.TypeOf(contracts.SpawnOptionsResponse{}),
+		reflect.TypeOf(contracts.SpawnProgressResponse{}),
+		reflect.TypeOf(contracts.MaintenanceResponse{}),
+		reflect.TypeOf(contracts.MaintenanceRequest{}),
+	}
+	// String constants that are part of the API but not of any type, such as headers
+	constants := []constDef{
+		{name: "ATTENTION_COUNT_HEADER", value: contracts.AttentionCountHeader},
 	}
 
 	typeMap := collectTypes(rootTypes)
@@ -43,7 +57,7 @@ func main() {
 		defs = append(defs, buildTypeDef(typeMap[name], typeMap))
 	}
 
-	out := render(defs)
+	out := render(constants, defs)
 	outPath := filepath.Join("assets", "dashboard", "src", "lib", "types.generated.ts")
 	if err := os.WriteFile(outPath, out, 0644); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", outPath, err)
@@ -101,10 +115,16 @@ func buildTypeDef(t reflect.Type, known map[string]reflect.Type) typeDef {
 	return typeDef{name: t.Name(), fields: fields}
 }
 
-func render(defs []typeDef) []byte {
+func render(constants []constDef, defs []typeDef) []byte {
 	var buf bytes.Buffer
 	buf.WriteString("// Code generated by cmd/gen-types; DO NOT EDIT.\n")
 	buf.WriteString("\n")
+	for _, c := range constants {
+		fmt.Fprintf(&buf, "export const %s = '%s';\n", c.name, c.value)
+	}
+	if len(constants) > 0 {
+		buf.WriteString("\n")
+	}
 	for _, def := range defs {
 		buf.WriteString("export interface ")
 		buf.WriteString(def.name)
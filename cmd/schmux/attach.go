@@ -39,13 +39,14 @@ func (cmd *AttachCommand) Run(args []string) error {
 	}
 
 	// Find the session and get its tmux session name
-	var tmuxSession string
+	var tmuxSession, tmuxSocket string
 	for _, ws := range sessions {
 		for _, sess := range ws.Sessions {
 			if sess.ID == sessionID {
 				// Parse attach command to get tmux session name
 				// Attach command is: tmux attach -t "<session-name>" or tmux attach -t <session-name>
 				tmuxSession = parseTmuxSession(sess.AttachCmd)
+				tmuxSocket = parseTmuxSocket(sess.AttachCmd)
 				if tmuxSession == "" {
 					// Fallback: couldn't parse, try session ID
 					tmuxSession = sessionID
@@ -60,8 +61,12 @@ found:
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
-	// Execute tmux attach
-	tmuxCmd := exec.Command("tmux", "attach", "-t", tmuxSession)
+	// Execute tmux attach, on the daemon's dedicated socket if it uses one
+	tmuxArgs := []string{"attach", "-t", tmuxSession}
+	if tmuxSocket != "" {
+		tmuxArgs = append([]string{"-L", tmuxSocket}, tmuxArgs...)
+	}
+	tmuxCmd := exec.Command("tmux", tmuxArgs...)
 	tmuxCmd.Stdin = os.Stdin
 	tmuxCmd.Stdout = os.Stdout
 	tmuxCmd.Stderr = os.Stderr
@@ -76,14 +81,14 @@ found:
 //	tmux attach -t "my session" -> my session
 //	tmux attach -t my-session -> my-session
 func parseTmuxSession(cmd string) string {
-	// Find the "-t" flag
-	idx := strings.Index(cmd, "-t")
+	// Find the "-t" flag (as a whole word, so socket names like "my-tmux" don't match)
+	idx := strings.Index(cmd+" ", " -t ")
 	if idx == -1 {
 		return ""
 	}
 
 	// Get everything after "-t"
-	rest := strings.TrimSpace(cmd[idx+2:])
+	rest := strings.TrimSpace(cmd[idx+3:])
 	if rest == "" {
 		return ""
 	}
@@ -107,3 +112,21 @@ func parseTmuxSession(cmd string) string {
 
 	return rest
 }
+
+// parseTmuxSocket extracts the tmux socket name (-L) from an attach command.
+// Returns "" when the command targets the default tmux server.
+//
+//	tmux -L schmux attach -t "=my session" -> schmux
+//	tmux attach -t my-session -> ""
+func parseTmuxSocket(cmd string) string {
+	fields := strings.Fields(cmd)
+	for i, field := range fields {
+		if field == "-t" {
+			break
+		}
+		if field == "-L" && i+1 < len(fields) {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
@@ -51,7 +51,7 @@ func (cmd *AuthGitHubCommand) Run(args []string) error {
 
 	// Ensure config exists
 	if !config.ConfigExists() {
-		ok, err := config.EnsureExists()
+		ok, err := config.EnsureExists(config.CreatePrompt)
 		if err != nil {
 			return err
 		}
@@ -71,6 +71,7 @@ func (cmd *AuthGitHubCommand) Run(args []string) error {
 	if err != nil {
 		return err
 	}
+	config.SetSecretStore(config.NewSecretStore(cfg.GetSecretsBackend()))
 	secrets, _ := config.GetAuthSecrets()
 
 	// Initialize defaults from existing config
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/daemon"
@@ -21,44 +22,63 @@ func main() {
 
 	switch command {
 	case "start", "daemon-run":
-		// Shared setup for both start and daemon-run
-		configOk, err := config.EnsureExists()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error checking config: %v\n", err)
-			os.Exit(1)
-		}
-		if !configOk {
-			// User declined to create config
-			os.Exit(1)
-		}
-
-		if err := daemon.ValidateReadyToRun(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		createMode, args := configCreateMode(os.Args[2:])
+		if command == "start" && len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Unknown start flag: %s\n", args[0])
 			os.Exit(1)
 		}
+		ensureReadyToRun(createMode)
 
 		// Diverge here: background vs inline
 		if command == "start" {
-			if err := daemon.Start(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
-			}
-			fmt.Println("schmux daemon started")
+			startBackground()
 		} else { // daemon-run
 			background := false
-			args := os.Args[2:]
 			for _, arg := range args {
 				if arg == "--background" {
 					background = true
 					break
 				}
 			}
-			if err := daemon.Run(background); err != nil {
-				fmt.Fprintf(os.Stderr, "Daemon error: %v\n", err)
+			runForeground(background)
+		}
+
+	case "restart":
+		foreground := false
+		createMode, args := configCreateMode(os.Args[2:])
+		for _, arg := range args {
+			switch arg {
+			case "--foreground":
+				foreground = true
+			case "--background":
+				foreground = false
+			default:
+				fmt.Fprintf(os.Stderr, "Unknown restart flag: %s\n", arg)
 				os.Exit(1)
 			}
 		}
 
+		if pending, err := daemon.PendingRestart(); err == nil && pending {
+			fmt.Println("Restarting to apply config changes (network, access control, or tmux socket) saved since the daemon started")
+		}
+		wasRunning, err := daemon.StopForRestart()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if wasRunning {
+			fmt.Println("schmux daemon stopped")
+		} else {
+			fmt.Println("schmux daemon was not running")
+		}
+
+		ensureReadyToRun(createMode)
+		if foreground {
+			runForeground(false)
+		} else {
+			startBackground()
+		}
+
 	case "stop":
 		if err := daemon.Stop(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -117,6 +137,14 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "tail":
+		url := cli.GetDefaultURL()
+		cmd := NewTailCommand(cli.NewDaemonClient(url), url)
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	case "dispose":
 		client := cli.NewDaemonClient(cli.GetDefaultURL())
 		cmd := NewDisposeCommand(client)
@@ -133,6 +161,13 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "scan-ansi":
+		cmd := NewScanANSICommand()
+		if err := cmd.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	case "auth":
 		if len(os.Args) < 3 {
 			fmt.Fprintln(os.Stderr, "Usage: schmux auth github")
@@ -157,6 +192,64 @@ func main() {
 	}
 }
 
+// configCreateMode reads --yes/-y, --no-input, and SCHMUX_NONINTERACTIVE to decide
+// how a missing config is handled, and returns the remaining args. Flags win over
+// the environment; the last flag wins.
+func configCreateMode(args []string) (config.CreateMode, []string) {
+	mode := config.CreatePrompt
+	if v, err := strconv.ParseBool(os.Getenv("SCHMUX_NONINTERACTIVE")); err == nil && v {
+		mode = config.CreateAuto
+	}
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg {
+		case "--yes", "-y":
+			mode = config.CreateAuto
+		case "--no-input":
+			mode = config.CreateNever
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return mode, rest
+}
+
+// ensureReadyToRun is the shared setup for start, daemon-run, and restart.
+// It exits the process if the config is missing or the daemon can't run.
+func ensureReadyToRun(createMode config.CreateMode) {
+	configOk, err := config.EnsureExists(createMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking config: %v\n", err)
+		os.Exit(1)
+	}
+	if !configOk {
+		// User declined to create config
+		os.Exit(1)
+	}
+
+	if err := daemon.ValidateReadyToRun(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// startBackground starts the daemon as a background process.
+func startBackground() {
+	if err := daemon.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("schmux daemon started")
+}
+
+// runForeground runs the daemon in this process until it exits.
+func runForeground(background bool) {
+	if err := daemon.Run(background); err != nil {
+		fmt.Fprintf(os.Stderr, "Daemon error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func printUsage() {
 	fmt.Println("schmux - Smart Cognitive Hub on tmux")
 	fmt.Println()
@@ -166,13 +259,18 @@ func printUsage() {
 	fmt.Println("Daemon Commands:")
 	fmt.Println("  start       Start the daemon in background")
 	fmt.Println("  stop        Stop the daemon")
+	fmt.Println("  restart     Restart the daemon (--foreground to run it inline)")
 	fmt.Println("  status      Show daemon status and dashboard URL")
 	fmt.Println("  daemon-run  Run the daemon in foreground (for debugging)")
 	fmt.Println()
+	fmt.Println("  start, restart, and daemon-run accept --yes to create a default config")
+	fmt.Println("  without prompting, or --no-input to fail if there is none.")
+	fmt.Println()
 	fmt.Println("Session Commands:")
 	fmt.Println("  spawn           Spawn a new session")
 	fmt.Println("  list            List sessions")
 	fmt.Println("  attach          Attach to a session")
+	fmt.Println("  tail            Stream a session's output as plain text")
 	fmt.Println("  dispose         Dispose a session")
 	fmt.Println()
 	fmt.Println("Workspace Commands:")
@@ -180,6 +278,7 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Other:")
 	fmt.Println("  auth github  Configure GitHub auth")
+	fmt.Println("  scan-ansi   Summarize ANSI escape sequences in a terminal log")
 	fmt.Println("  version     Show version")
 	fmt.Println("  update      Update schmux to the latest version")
 	fmt.Println("  help        Show this help message")
@@ -189,6 +288,7 @@ func printUsage() {
 	fmt.Println("  schmux spawn -a claude -p \"fix bug\"  # Spawn in current workspace")
 	fmt.Println("  schmux list                         # List all sessions")
 	fmt.Println("  schmux attach <session-id>           # Attach to a session")
+	fmt.Println("  schmux tail <session-id>             # Follow a session's output")
 	fmt.Println("  schmux refresh-overlay <workspace>   # Refresh overlay files")
 	fmt.Println("  schmux auth github                   # Configure GitHub auth")
 }
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sergeknystautas/schmux/internal/ansiscan"
+)
+
+// ScanANSICommand implements the scan-ansi command.
+type ScanANSICommand struct{}
+
+// NewScanANSICommand creates a new scan-ansi command.
+func NewScanANSICommand() *ScanANSICommand {
+	return &ScanANSICommand{}
+}
+
+// Run executes the scan-ansi command.
+func (cmd *ScanANSICommand) Run(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: schmux scan-ansi <log-file>")
+	}
+
+	report, err := ansiscan.ScanFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to scan %s: %w", args[0], err)
+	}
+	report.Write(os.Stdout)
+	return nil
+}
@@ -5,21 +5,77 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/charmbracelet/huh"
 	"github.com/sergeknystautas/schmux/pkg/cli"
+	"golang.org/x/term"
 )
 
+// defaultSpawnBranch is the branch used when -b is not given and there is no picker.
+const defaultSpawnBranch = "main"
+
+// otherBranchValue is the branch picker's option for typing a branch name.
+const otherBranchValue = "\x00other"
+
 // SpawnCommand implements the spawn command.
 type SpawnCommand struct {
 	client cli.DaemonClient
+	// prompter asks for the repo and branch when their flags are omitted.
+	// nil when stdin or stdout isn't a terminal, so scripts still need the flags.
+	prompter spawnPrompter
 }
 
 // NewSpawnCommand creates a new spawn command.
 func NewSpawnCommand(client cli.DaemonClient) *SpawnCommand {
-	return &SpawnCommand{client: client}
+	cmd := &SpawnCommand{client: client}
+	if term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd())) {
+		cmd.prompter = huhPrompter{}
+	}
+	return cmd
+}
+
+// pickOption is one choice offered by a spawnPrompter.
+type pickOption struct {
+	Label string
+	Value string
+}
+
+// spawnPrompter asks the user for what the spawn flags left out.
+type spawnPrompter interface {
+	Select(title string, options []pickOption) (string, error)
+	Input(title string) (string, error)
+}
+
+// huhPrompter prompts on the terminal.
+type huhPrompter struct{}
+
+func (huhPrompter) Select(title string, options []pickOption) (string, error) {
+	opts := make([]huh.Option[string], len(options))
+	for i, o := range options {
+		opts[i] = huh.NewOption(o.Label, o.Value)
+	}
+	var value string
+	err := huh.NewSelect[string]().Title(title).Options(opts...).Value(&value).Run()
+	return value, err
+}
+
+func (huhPrompter) Input(title string) (string, error) {
+	var value string
+	err := huh.NewInput().
+		Title(title).
+		Value(&value).
+		Validate(func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return fmt.Errorf("required")
+			}
+			return nil
+		}).
+		Run()
+	return strings.TrimSpace(value), err
 }
 
 // Run executes the spawn command.
@@ -31,27 +87,37 @@ func (cmd *SpawnCommand) Run(args []string) error {
 		repoFlag      string
 		branchFlag    string
 		nicknameFlag  string
+		baseRefFlag   string
+		idemKeyFlag   string
 		jsonOutput    bool
 	)
 
 	fs := flag.NewFlagSet("spawn", flag.ExitOnError)
 	fs.StringVar(&targetFlag, "t", "", "Run target name (required)")
 	fs.StringVar(&targetFlag, "target", "", "Run target name (required)")
-	fs.StringVar(&promptFlag, "p", "", "Prompt for promptable targets")
-	fs.StringVar(&promptFlag, "prompt", "", "Prompt for promptable targets")
+	fs.StringVar(&promptFlag, "p", "", "Prompt for promptable targets (@file reads a file, - reads stdin)")
+	fs.StringVar(&promptFlag, "prompt", "", "Prompt for promptable targets (@file reads a file, - reads stdin)")
 	fs.StringVar(&workspaceFlag, "w", "", "Workspace path (e.g., . or ~/ws/myproject-001)")
 	fs.StringVar(&workspaceFlag, "workspace", "", "Workspace path (e.g., . or ~/ws/myproject-001)")
-	fs.StringVar(&repoFlag, "r", "", "Repo name from config (for new workspace)")
-	fs.StringVar(&repoFlag, "repo", "", "Repo name from config (for new workspace)")
-	fs.StringVar(&branchFlag, "b", "main", "Git branch")
-	fs.StringVar(&branchFlag, "branch", "main", "Git branch")
+	fs.StringVar(&repoFlag, "r", "", "Repo name from config (for new workspace; picked interactively if omitted)")
+	fs.StringVar(&repoFlag, "repo", "", "Repo name from config (for new workspace; picked interactively if omitted)")
+	fs.StringVar(&branchFlag, "b", defaultSpawnBranch, "Git branch (picked interactively if omitted)")
+	fs.StringVar(&branchFlag, "branch", defaultSpawnBranch, "Git branch (picked interactively if omitted)")
 	fs.StringVar(&nicknameFlag, "n", "", "Optional session nickname")
 	fs.StringVar(&nicknameFlag, "nickname", "", "Optional session nickname")
+	fs.StringVar(&baseRefFlag, "base-ref", "", "Tag, commit, or branch a new branch starts from (default: the default branch)")
+	fs.StringVar(&idemKeyFlag, "idempotency-key", "", "Key that makes a retried spawn return the first spawn's results (e.g. a CI job ID)")
 	fs.BoolVar(&jsonOutput, "json", false, "JSON output")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	branchSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "b" || f.Name == "branch" {
+			branchSet = true
+		}
+	})
 
 	// Validate required flags
 	if targetFlag == "" {
@@ -87,30 +153,57 @@ func (cmd *SpawnCommand) Run(args []string) error {
 		}
 		repoURL = repo.URL
 	} else {
-		// Try to auto-detect current directory as workspace
+		// Try to auto-detect current directory as workspace, else ask for a repo
 		workspaceID, repoURL, err = cmd.autoDetectWorkspace(cfg)
 		if err != nil {
-			return fmt.Errorf("please specify -w (--workspace) or -r (--repo): %w", err)
+			if cmd.prompter == nil {
+				return fmt.Errorf("please specify -w (--workspace) or -r (--repo): %w", err)
+			}
+			repo, pickErr := cmd.pickRepo(cfg)
+			if pickErr != nil {
+				return pickErr
+			}
+			repoURL = repo.URL
+			repoFlag = repo.Name
+		}
+	}
+
+	// A new workspace needs a branch; offer the repo's recent ones
+	if repoURL != "" && !branchSet && cmd.prompter != nil {
+		branchFlag, err = cmd.pickBranch(repoURL)
+		if err != nil {
+			return err
 		}
 	}
 
+	if baseRefFlag != "" && workspaceID != "" {
+		return fmt.Errorf("--base-ref only applies to a new workspace; use -r (--repo) instead of an existing workspace")
+	}
+
+	prompt, err := readPromptArg(promptFlag, os.Stdin)
+	if err != nil {
+		return err
+	}
+
 	if target, found := cmd.findRunTarget(targetFlag, cfg); found {
-		if target.Type == "command" && promptFlag != "" {
+		if target.Type == "command" && prompt != "" {
 			return fmt.Errorf("prompt (-p/--prompt) is not allowed for command targets")
 		}
-		if target.Type == "promptable" && promptFlag == "" {
+		if target.Type == "promptable" && prompt == "" {
 			return fmt.Errorf("prompt (-p/--prompt) is required for promptable targets")
 		}
 	}
 
 	// Build spawn request
 	req := cli.SpawnRequest{
-		Repo:        repoURL,
-		Branch:      branchFlag,
-		Prompt:      promptFlag,
-		Nickname:    nicknameFlag,
-		WorkspaceID: workspaceID,
-		Targets:     map[string]int{targetFlag: 1},
+		Repo:           repoURL,
+		Branch:         branchFlag,
+		Prompt:         prompt,
+		Nickname:       nicknameFlag,
+		WorkspaceID:    workspaceID,
+		Targets:        map[string]int{targetFlag: 1},
+		BaseRef:        baseRefFlag,
+		IdempotencyKey: idemKeyFlag,
 	}
 
 	results, err := cmd.client.Spawn(context.Background(), req)
@@ -129,6 +222,27 @@ func (cmd *SpawnCommand) Run(args []string) error {
 	return cmd.outputHuman(results, workspaceOrRepo)
 }
 
+// readPromptArg resolves the -p value: "@path" reads the prompt from a file,
+// "-" reads it from stdin, and anything else is used as-is.
+func readPromptArg(value string, stdin io.Reader) (string, error) {
+	switch {
+	case value == "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt from stdin: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case strings.HasPrefix(value, "@"):
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return "", fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return value, nil
+	}
+}
+
 // resolveWorkspace resolves a workspace path to a workspace ID.
 func (cmd *SpawnCommand) resolveWorkspace(path string, cfg *cli.Config) (string, error) {
 	// Expand ~
@@ -192,6 +306,56 @@ func (cmd *SpawnCommand) autoDetectWorkspace(cfg *cli.Config) (workspaceID, repo
 	return "", "", fmt.Errorf("not in a workspace directory")
 }
 
+// pickRepo asks which configured repo to spawn into.
+func (cmd *SpawnCommand) pickRepo(cfg *cli.Config) (*cli.Repo, error) {
+	if len(cfg.Repos) == 0 {
+		return nil, fmt.Errorf("no repos configured; add one in the dashboard or pass -w (--workspace)")
+	}
+	options := make([]pickOption, len(cfg.Repos))
+	for i, repo := range cfg.Repos {
+		options[i] = pickOption{Label: fmt.Sprintf("%s (%s)", repo.Name, repo.URL), Value: repo.Name}
+	}
+	name, err := cmd.prompter.Select("Repo", options)
+	if err != nil {
+		return nil, err
+	}
+	repo, found := cmd.findRepo(name, cfg)
+	if !found {
+		return nil, fmt.Errorf("repo not found in config: %s", name)
+	}
+	return repo, nil
+}
+
+// pickBranch asks which branch to spawn on, offering the default branch, the
+// repo's recently committed branches, and a free-form name.
+func (cmd *SpawnCommand) pickBranch(repoURL string) (string, error) {
+	options := []pickOption{{Label: defaultSpawnBranch + " (default)", Value: defaultSpawnBranch}}
+	recent, err := cmd.client.GetRecentBranches(50)
+	if err != nil {
+		// Recent branches are a convenience; the default and a typed name still work
+		fmt.Fprintf(os.Stderr, "warning: failed to get recent branches: %v\n", err)
+	}
+	for _, b := range recent {
+		if b.RepoURL != repoURL || b.Branch == defaultSpawnBranch {
+			continue
+		}
+		options = append(options, pickOption{Label: fmt.Sprintf("%s  %s", b.Branch, b.Subject), Value: b.Branch})
+		if len(options) > 10 {
+			break
+		}
+	}
+	options = append(options, pickOption{Label: "Other...", Value: otherBranchValue})
+
+	branch, err := cmd.prompter.Select("Branch", options)
+	if err != nil {
+		return "", err
+	}
+	if branch == otherBranchValue {
+		return cmd.prompter.Input("Branch name")
+	}
+	return branch, nil
+}
+
 // findRunTarget finds a run target by name in config.
 func (cmd *SpawnCommand) findRunTarget(name string, cfg *cli.Config) (*cli.RunTarget, bool) {
 	for _, target := range cfg.RunTargets {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -39,6 +40,11 @@ func TestParseTmuxSession(t *testing.T) {
 			cmd:      `tmux attach -t "session"  `,
 			expected: "session",
 		},
+		{
+			name:     "dedicated socket containing -t",
+			cmd:      `tmux -L my-tmux attach -t "=session"`,
+			expected: "=session",
+		},
 		{
 			name:     "no -t flag",
 			cmd:      `tmux attach session`,
@@ -61,6 +67,23 @@ func TestParseTmuxSession(t *testing.T) {
 	}
 }
 
+func TestParseTmuxSocket(t *testing.T) {
+	tests := []struct {
+		cmd      string
+		expected string
+	}{
+		{cmd: `tmux -L schmux attach -t "=my session"`, expected: "schmux"},
+		{cmd: `tmux attach -t "=my session"`, expected: ""},
+		{cmd: `tmux attach -t -L`, expected: ""},
+		{cmd: "", expected: ""},
+	}
+	for _, tt := range tests {
+		if got := parseTmuxSocket(tt.cmd); got != tt.expected {
+			t.Errorf("parseTmuxSocket(%q) = %q, want %q", tt.cmd, got, tt.expected)
+		}
+	}
+}
+
 func TestAutoDetectWorkspace(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -193,6 +216,39 @@ func TestFindRepo(t *testing.T) {
 	})
 }
 
+// TestReadPromptArg tests reading -p values inline, from @file, and from stdin
+func TestReadPromptArg(t *testing.T) {
+	promptFile := filepath.Join(t.TempDir(), "prompt.md")
+	if err := os.WriteFile(promptFile, []byte("first paragraph\n\nsecond \"quoted\" paragraph\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		stdin   string
+		want    string
+		wantErr bool
+	}{
+		{name: "inline", value: "do a review", want: "do a review"},
+		{name: "empty", value: "", want: ""},
+		{name: "file", value: "@" + promptFile, want: "first paragraph\n\nsecond \"quoted\" paragraph"},
+		{name: "missing file", value: "@" + filepath.Join(t.TempDir(), "nope.md"), wantErr: true},
+		{name: "stdin", value: "-", stdin: "from a pipe\n", want: "from a pipe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPromptArg(tt.value, strings.NewReader(tt.stdin))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readPromptArg() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("readPromptArg() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestSpawnCommand_Run tests the spawn command Run method
 func TestSpawnCommand_Run(t *testing.T) {
 	tests := []struct {
@@ -388,3 +444,104 @@ func TestSpawnCommand_OutputJSON(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+// fakePrompter answers spawn pickers from canned values and records what it offered.
+type fakePrompter struct {
+	selections []string
+	input      string
+	offered    map[string][]pickOption
+}
+
+func (p *fakePrompter) Select(title string, options []pickOption) (string, error) {
+	if p.offered == nil {
+		p.offered = make(map[string][]pickOption)
+	}
+	p.offered[title] = options
+	value := p.selections[0]
+	p.selections = p.selections[1:]
+	return value, nil
+}
+
+func (p *fakePrompter) Input(title string) (string, error) {
+	return p.input, nil
+}
+
+func TestSpawnCommand_Pickers(t *testing.T) {
+	config := &cli.Config{
+		RunTargets: []cli.RunTarget{{Name: "shell", Type: "command", Command: "bash"}},
+		Repos: []cli.Repo{
+			{Name: "schmux", URL: "https://github.com/user/schmux.git"},
+			{Name: "other", URL: "https://github.com/user/other.git"},
+		},
+	}
+	recent := []cli.RecentBranch{
+		{RepoURL: "https://github.com/user/other.git", Branch: "elsewhere"},
+		{RepoURL: "https://github.com/user/schmux.git", Branch: "feature/x", Subject: "Add x"},
+		{RepoURL: "https://github.com/user/schmux.git", Branch: "main"},
+	}
+	t.Chdir(t.TempDir()) // not a workspace
+
+	tests := []struct {
+		name       string
+		args       []string
+		prompter   *fakePrompter
+		wantRepo   string
+		wantBranch string
+	}{
+		{
+			name:       "repo and recent branch picked",
+			args:       []string{"-t", "shell"},
+			prompter:   &fakePrompter{selections: []string{"schmux", "feature/x"}},
+			wantRepo:   "https://github.com/user/schmux.git",
+			wantBranch: "feature/x",
+		},
+		{
+			name:       "branch typed",
+			args:       []string{"-r", "schmux", "-t", "shell"},
+			prompter:   &fakePrompter{selections: []string{otherBranchValue}, input: "new-work"},
+			wantRepo:   "https://github.com/user/schmux.git",
+			wantBranch: "new-work",
+		},
+		{
+			name:       "flags skip the pickers",
+			args:       []string{"-r", "other", "-b", "dev", "-t", "shell"},
+			prompter:   &fakePrompter{},
+			wantRepo:   "https://github.com/user/other.git",
+			wantBranch: "dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockDaemonClient{isRunning: true, config: config, recentBranches: recent}
+			cmd := &SpawnCommand{client: mock, prompter: tt.prompter}
+
+			oldStdout := os.Stdout
+			_, w, _ := os.Pipe()
+			os.Stdout = w
+			err := cmd.Run(tt.args)
+			w.Close()
+			os.Stdout = oldStdout
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mock.spawnRequest.Repo != tt.wantRepo || mock.spawnRequest.Branch != tt.wantBranch {
+				t.Errorf("spawned %s@%s, want %s@%s", mock.spawnRequest.Repo, mock.spawnRequest.Branch, tt.wantRepo, tt.wantBranch)
+			}
+		})
+	}
+
+	// The branch picker offers the default, the repo's own recent branches, and "Other..."
+	prompter := &fakePrompter{selections: []string{"main"}}
+	cmd := &SpawnCommand{client: &MockDaemonClient{recentBranches: recent}, prompter: prompter}
+	if _, err := cmd.pickBranch("https://github.com/user/schmux.git"); err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for _, o := range prompter.offered["Branch"] {
+		values = append(values, o.Value)
+	}
+	if want := []string{"main", "feature/x", otherBranchValue}; strings.Join(values, ",") != strings.Join(want, ",") {
+		t.Errorf("branch options = %q, want %q", values, want)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/gorilla/websocket"
+	"github.com/sergeknystautas/schmux/pkg/cli"
+)
+
+// TailCommand implements the tail command.
+type TailCommand struct {
+	client  cli.DaemonClient
+	baseURL string
+	out     io.Writer
+}
+
+// NewTailCommand creates a new tail command that reads from the daemon at baseURL.
+func NewTailCommand(client cli.DaemonClient, baseURL string) *TailCommand {
+	return &TailCommand{client: client, baseURL: baseURL, out: os.Stdout}
+}
+
+// tailMessage is an output message from the terminal websocket.
+type tailMessage struct {
+	Type    string `json:"type"` // "full", "append"
+	Content string `json:"content"`
+}
+
+// Run executes the tail command.
+func (cmd *TailCommand) Run(args []string) error {
+	var (
+		raw       bool
+		history   bool
+		sessionID string
+	)
+	for _, arg := range args {
+		switch arg {
+		case "-raw", "--raw":
+			raw = true
+		case "-history", "--history":
+			history = true
+		default:
+			if sessionID == "" && !strings.HasPrefix(arg, "-") {
+				sessionID = arg
+			} else {
+				return fmt.Errorf("usage: schmux tail [--raw] [--history] <session-id>")
+			}
+		}
+	}
+	if sessionID == "" {
+		return fmt.Errorf("usage: schmux tail [--raw] [--history] <session-id>")
+	}
+
+	// Check if daemon is running
+	if !cmd.client.IsRunning() {
+		return fmt.Errorf("daemon is not running. Start it with: schmux start")
+	}
+
+	wsURL, err := terminalWebSocketURL(cmd.baseURL, sessionID)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			// The CLI has no dashboard login to present
+			resp.Body.Close()
+			return fmt.Errorf("schmux tail doesn't support dashboards with auth enabled (access_control.enabled); use schmux attach %s instead", sessionID)
+		}
+		if resp != nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			resp.Body.Close()
+			return fmt.Errorf("failed to connect to session %s: %s", sessionID, strings.TrimSpace(string(body)))
+		}
+		return fmt.Errorf("failed to connect to session %s: %w", sessionID, err)
+	}
+	defer conn.Close()
+
+	// Closing the connection unblocks the read loop on Ctrl-C.
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	var stripper ansiStripper
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return fmt.Errorf("connection to session %s lost: %w", sessionID, err)
+		}
+		var msg tailMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+		switch msg.Type {
+		case "full":
+			// The snapshot is sent on connect and again whenever the server
+			// drops a backlog; only the first one is history worth printing.
+			if !history {
+				continue
+			}
+			history = false
+		case "append":
+		default:
+			continue
+		}
+		text := msg.Content
+		if !raw {
+			text = stripper.Strip(text)
+		}
+		if _, err := io.WriteString(cmd.out, text); err != nil {
+			if errors.Is(err, syscall.EPIPE) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// terminalWebSocketURL builds the read-only terminal websocket URL for a session
+// from the daemon's HTTP base URL.
+func terminalWebSocketURL(baseURL, sessionID string) (string, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid daemon URL %q: %w", baseURL, err)
+	}
+	scheme := "ws"
+	if base.Scheme == "https" {
+		scheme = "wss"
+	}
+	wsURL := url.URL{
+		Scheme:   scheme,
+		Host:     base.Host,
+		Path:     strings.TrimRight(base.Path, "/") + "/ws/terminal/" + sessionID,
+		RawQuery: "readonly=true",
+	}
+	return wsURL.String(), nil
+}
+
+// ansiEscape matches CSI, OSC, string-terminated, charset, and two-byte escape sequences.
+var ansiEscape = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[PX^_][^\x1b]*\x1b\\|[()*+][0-9A-Za-z]|[@-OQ-WYZ\\=>78c])`)
+
+// ansiEscapePrefix matches a complete escape sequence at the start of the text.
+var ansiEscapePrefix = regexp.MustCompile(`^(?:` + ansiEscape.String() + `)`)
+
+// maxPendingEscape bounds how much of an unterminated escape sequence is held
+// back waiting for the next chunk.
+const maxPendingEscape = 256
+
+// ansiStripper removes escape sequences and carriage returns from a stream of
+// output chunks, holding back a sequence split across two chunks.
+type ansiStripper struct {
+	pending string
+}
+
+// Strip returns the plain text of the next chunk.
+func (s *ansiStripper) Strip(chunk string) string {
+	text := s.pending + chunk
+	s.pending = ""
+	if i := strings.LastIndexByte(text, '\x1b'); i >= 0 && len(text)-i < maxPendingEscape {
+		if !ansiEscapePrefix.MatchString(text[i:]) {
+			s.pending = text[i:]
+			text = text[:i]
+		}
+	}
+	text = ansiEscape.ReplaceAllString(text, "")
+	return strings.ReplaceAll(text, "\r", "")
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestTailCommand_Run(t *testing.T) {
+	var gotQuery string
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ws/terminal/auth-session" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/ws/terminal/test-session" {
+			http.Error(w, "session not running", http.StatusGone)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteJSON(tailMessage{Type: "full", Content: "old screen\r\n"})
+		conn.WriteJSON(tailMessage{Type: "append", Content: "\x1b[32mok\x1b"})
+		conn.WriteJSON(tailMessage{Type: "append", Content: "[0m done\r\n"})
+		conn.WriteJSON(tailMessage{Type: "full", Content: "resent screen\r\n"})
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		args        []string
+		isRunning   bool
+		want        string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:        "requires session id",
+			args:        []string{"--raw"},
+			isRunning:   true,
+			wantErr:     true,
+			errContains: "usage:",
+		},
+		{
+			name:        "daemon not running",
+			args:        []string{"test-session"},
+			isRunning:   false,
+			wantErr:     true,
+			errContains: "daemon is not running",
+		},
+		{
+			name:        "session not running",
+			args:        []string{"other-session"},
+			isRunning:   true,
+			wantErr:     true,
+			errContains: "session not running",
+		},
+		{
+			name:        "auth enabled",
+			args:        []string{"auth-session"},
+			isRunning:   true,
+			wantErr:     true,
+			errContains: "use schmux attach auth-session",
+		},
+		{
+			name:      "strips ansi from appended output",
+			args:      []string{"test-session"},
+			isRunning: true,
+			want:      "ok done\n",
+		},
+		{
+			name:      "raw keeps escapes",
+			args:      []string{"--raw", "test-session"},
+			isRunning: true,
+			want:      "\x1b[32mok\x1b[0m done\r\n",
+		},
+		{
+			name:      "history prints the first snapshot",
+			args:      []string{"test-session", "--history"},
+			isRunning: true,
+			want:      "old screen\nok done\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := NewTailCommand(&MockDaemonClient{isRunning: tt.isRunning}, server.URL)
+			cmd.out = &out
+
+			err := cmd.Run(tt.args)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("Run() error = %v, want error containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if gotQuery != "readonly=true" {
+				t.Errorf("query = %q, want readonly=true", gotQuery)
+			}
+		})
+	}
+}
+
+func TestTerminalWebSocketURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{"http://localhost:7337", "ws://localhost:7337/ws/terminal/s1?readonly=true"},
+		{"https://schmux.example.com/", "wss://schmux.example.com/ws/terminal/s1?readonly=true"},
+	}
+	for _, tt := range tests {
+		got, err := terminalWebSocketURL(tt.base, "s1")
+		if err != nil {
+			t.Fatalf("terminalWebSocketURL(%q) error: %v", tt.base, err)
+		}
+		if got != tt.want {
+			t.Errorf("terminalWebSocketURL(%q) = %q, want %q", tt.base, got, tt.want)
+		}
+	}
+}
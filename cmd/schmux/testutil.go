@@ -15,8 +15,10 @@ type MockDaemonClient struct {
 	sessions          []cli.WorkspaceWithSessions
 	scanResult        *cli.ScanResult
 	scanErr           error
+	recentBranches    []cli.RecentBranch
 	spawnResults      []cli.SpawnResult
 	spawnErr          error
+	spawnRequest      *cli.SpawnRequest
 	disposeErr        error
 	getConfigErr      error
 	getSessionsErr    error
@@ -39,11 +41,16 @@ func (m *MockDaemonClient) GetSessions() ([]cli.WorkspaceWithSessions, error) {
 	return m.sessions, m.getSessionsErr
 }
 
+func (m *MockDaemonClient) GetRecentBranches(limit int) ([]cli.RecentBranch, error) {
+	return m.recentBranches, nil
+}
+
 func (m *MockDaemonClient) ScanWorkspaces(ctx context.Context) (*cli.ScanResult, error) {
 	return m.scanResult, m.scanErr
 }
 
 func (m *MockDaemonClient) Spawn(ctx context.Context, req cli.SpawnRequest) ([]cli.SpawnResult, error) {
+	m.spawnRequest = &req
 	if m.spawnErr != nil {
 		return nil, m.spawnErr
 	}
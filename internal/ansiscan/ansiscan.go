@@ -0,0 +1,102 @@
+// Package ansiscan analyzes the CSI escape sequences in terminal logs: how many
+// there are, which kinds, and how much data remains once repeats are deduplicated.
+package ansiscan
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// sampleSize is how many of the most recent sequences a Report keeps.
+const sampleSize = 100
+
+// cursorTerminators end CSI sequences that only move the cursor or edit the
+// screen. They are excluded from deduplication since they carry no style state.
+var cursorTerminators = []byte{'H', 'f', 'A', 'B', 'C', 'D', 'J', 'K', 's', 'u', 'E', 'G', 'L', 'M', 'P', 'Z', '@', '`'}
+
+// Report is the result of scanning terminal output for CSI sequences.
+type Report struct {
+	Size             int          // bytes scanned
+	TotalSequences   int          // CSI sequences found
+	TerminatorCounts map[byte]int // sequence count by final byte
+	UniqueSequences  int          // distinct non-cursor sequences
+	UniqueSize       int          // bytes taken by the distinct non-cursor sequences
+	Last             []string     // the most recent sequences, oldest first
+}
+
+// Scan analyzes data for CSI (ESC [) sequences.
+func Scan(data []byte) *Report {
+	report := &Report{
+		Size:             len(data),
+		TerminatorCounts: make(map[byte]int),
+	}
+	unique := make(map[string]struct{})
+
+	i := 0
+	for i < len(data)-1 {
+		if data[i] != 033 || data[i+1] != '[' {
+			i++
+			continue
+		}
+		j := i + 2
+		for j < len(data) {
+			if data[j] >= 0x40 && data[j] <= 0x7E {
+				terminator := data[j]
+				report.TerminatorCounts[terminator]++
+				report.TotalSequences++
+
+				seq := string(data[i : j+1])
+				if len(report.Last) >= sampleSize {
+					report.Last = report.Last[1:]
+				}
+				report.Last = append(report.Last, seq)
+
+				if !slices.Contains(cursorTerminators, terminator) {
+					if _, seen := unique[seq]; !seen {
+						unique[seq] = struct{}{}
+						report.UniqueSize += len(seq)
+					}
+				}
+				break
+			}
+			j++
+		}
+		i = j + 1
+	}
+
+	report.UniqueSequences = len(unique)
+	return report
+}
+
+// ScanFile reads and scans the file at path.
+func ScanFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Scan(data), nil
+}
+
+// Write prints the report in the scan-ansi text format.
+func (r *Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "Total CSI sequences: %d\n", r.TotalSequences)
+	fmt.Fprintf(w, "Unique CSI sequences (after dedupe): %d\n", r.UniqueSequences)
+	fmt.Fprintf(w, "File size: %.2f MB\n", float64(r.Size)/(1024*1024))
+	fmt.Fprintf(w, "Unique sequence data size: %.2f MB\n", float64(r.UniqueSize)/(1024*1024))
+	fmt.Fprintln(w, "\nSequence terminator counts:")
+	terminators := make([]byte, 0, len(r.TerminatorCounts))
+	for t := range r.TerminatorCounts {
+		terminators = append(terminators, t)
+	}
+	slices.Sort(terminators)
+	for _, t := range terminators {
+		fmt.Fprintf(w, "  %c (0x%02x): %d\n", t, t, r.TerminatorCounts[t])
+	}
+
+	fmt.Fprintf(w, "\nLast %d sequences (raw):\n", sampleSize)
+	for _, seq := range r.Last {
+		fmt.Fprintf(w, "%q\n", seq)
+	}
+}
@@ -0,0 +1,50 @@
+package ansiscan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	data := []byte("\x1b[31mred\x1b[0m \x1b[31magain\x1b[2J\x1b[10;5Hmoved\x1b[")
+	report := Scan(data)
+
+	if report.TotalSequences != 5 {
+		t.Errorf("TotalSequences = %d, want 5", report.TotalSequences)
+	}
+	if report.TerminatorCounts['m'] != 3 || report.TerminatorCounts['J'] != 1 || report.TerminatorCounts['H'] != 1 {
+		t.Errorf("TerminatorCounts = %v", report.TerminatorCounts)
+	}
+	// Only the two distinct SGR sequences count; cursor and erase sequences are skipped.
+	if report.UniqueSequences != 2 {
+		t.Errorf("UniqueSequences = %d, want 2", report.UniqueSequences)
+	}
+	if want := len("\x1b[31m") + len("\x1b[0m"); report.UniqueSize != want {
+		t.Errorf("UniqueSize = %d, want %d", report.UniqueSize, want)
+	}
+	if report.Size != len(data) {
+		t.Errorf("Size = %d, want %d", report.Size, len(data))
+	}
+	if got := report.Last[len(report.Last)-1]; got != "\x1b[10;5H" {
+		t.Errorf("last sequence = %q", got)
+	}
+}
+
+func TestScanKeepsLastSamples(t *testing.T) {
+	report := Scan(bytes.Repeat([]byte("\x1b[1m"), sampleSize+20))
+	if len(report.Last) != sampleSize {
+		t.Errorf("len(Last) = %d, want %d", len(report.Last), sampleSize)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	var buf bytes.Buffer
+	Scan([]byte("\x1b[1mbold\x1b[0m")).Write(&buf)
+	out := buf.String()
+	for _, want := range []string{"Total CSI sequences: 2", "Unique CSI sequences (after dedupe): 2", "m (0x6d): 2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -2,8 +2,13 @@ package contracts
 
 // Repo represents a git repository configuration.
 type Repo struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+	Name              string `json:"name"`
+	URL               string `json:"url"`
+	SSHKeyPath        string `json:"ssh_key_path,omitempty"`
+	BranchSuggest     *bool  `json:"branch_suggest,omitempty"`      // nil follows branch_suggest.default_enabled
+	BranchPrefixRegex string `json:"branch_prefix_regex,omitempty"` // must match the start of new branch names
+	PreDisposeCommand string `json:"pre_dispose_command,omitempty"` // run in the workspace before it is disposed
+	CloneFilter       string `json:"clone_filter,omitempty"`        // git --filter for new clones, e.g. "blob:none"
 }
 
 // RepoConfig represents repository-specific configuration from .schmux/config.json.
@@ -13,10 +18,15 @@ type RepoConfig struct {
 
 // RepoWithConfig represents a repository with its loaded configuration.
 type RepoWithConfig struct {
-	Name          string      `json:"name"`
-	URL           string      `json:"url"`
-	DefaultBranch string      `json:"default_branch,omitempty"` // Omitted if not detected
-	Config        *RepoConfig `json:"config,omitempty"`
+	Name              string      `json:"name"`
+	URL               string      `json:"url"`
+	SSHKeyPath        string      `json:"ssh_key_path,omitempty"`
+	BranchSuggest     *bool       `json:"branch_suggest,omitempty"`      // nil follows branch_suggest.default_enabled
+	BranchPrefixRegex string      `json:"branch_prefix_regex,omitempty"` // must match the start of new branch names
+	PreDisposeCommand string      `json:"pre_dispose_command,omitempty"` // run in the workspace before it is disposed
+	CloneFilter       string      `json:"clone_filter,omitempty"`        // git --filter for new clones, e.g. "blob:none"
+	DefaultBranch     string      `json:"default_branch,omitempty"`      // Omitted if not detected
+	Config            *RepoConfig `json:"config,omitempty"`
 }
 
 // RunTarget represents a user-supplied run target.
@@ -25,6 +35,20 @@ type RunTarget struct {
 	Type    string `json:"type"`
 	Command string `json:"command"`
 	Source  string `json:"source,omitempty"`
+	Shell   string `json:"shell,omitempty"`
+	// TmuxOptions are tmux session options applied over schmux's defaults.
+	TmuxOptions map[string]string `json:"tmux_options,omitempty"`
+	Panes       []TargetPane      `json:"panes,omitempty"`  // extra panes split off the agent's pane
+	Layout      string            `json:"layout,omitempty"` // tmux preset layout applied after the splits
+	// OnExitCommand is run locally whenever the agent of one of the target's sessions exits.
+	OnExitCommand string `json:"on_exit_command,omitempty"`
+}
+
+// TargetPane is an extra pane of a run target's sessions.
+type TargetPane struct {
+	Command string `json:"command"`
+	Split   string `json:"split,omitempty"` // "vertical" (default) or "horizontal"
+	Size    int    `json:"size,omitempty"`  // percent of the split; 0 splits evenly
 }
 
 // QuickLaunch represents a saved run preset.
@@ -36,6 +60,12 @@ type QuickLaunch struct {
 	Prompt  *string `json:"prompt,omitempty"`  // prompt for the target
 }
 
+// DefaultSpawn represents the default set of agents for the spawn form.
+type DefaultSpawn struct {
+	Targets  map[string]int `json:"targets"`
+	Nickname string         `json:"nickname,omitempty"`
+}
+
 // ExternalDiffCommand represents an external diff tool configuration.
 type ExternalDiffCommand struct {
 	Name    string `json:"name"`
@@ -56,10 +86,13 @@ type Model struct {
 
 // Terminal represents terminal dimensions.
 type Terminal struct {
-	Width          int `json:"width"`
-	Height         int `json:"height"`
-	SeedLines      int `json:"seed_lines"`
-	BootstrapLines int `json:"bootstrap_lines"`
+	Width            int `json:"width"`
+	Height           int `json:"height"`
+	SeedLines        int `json:"seed_lines"`
+	BootstrapLines   int `json:"bootstrap_lines"`
+	BootstrapMaxKB   int `json:"bootstrap_max_kb"`
+	OutputIntervalMs int `json:"output_interval_ms"`
+	OutputMaxKB      int `json:"output_max_kb"`
 }
 
 // Nudgenik represents NudgeNik configuration.
@@ -67,42 +100,81 @@ type Nudgenik struct {
 	Target         string `json:"target,omitempty"`
 	ViewedBufferMs int    `json:"viewed_buffer_ms"`
 	SeenIntervalMs int    `json:"seen_interval_ms"`
+	MaxConcurrent  int    `json:"max_concurrent"`
+	TimeoutMs      int    `json:"timeout_ms"`
 }
 
 // BranchSuggest represents branch name suggestion configuration.
 type BranchSuggest struct {
-	Target string `json:"target,omitempty"`
+	Target         string `json:"target,omitempty"`
+	DefaultEnabled bool   `json:"default_enabled"`
 }
 
 // ConflictResolve represents conflict resolution configuration.
 type ConflictResolve struct {
-	Target    string `json:"target,omitempty"`
-	TimeoutMs int    `json:"timeout_ms"`
+	Target               string `json:"target,omitempty"`
+	TimeoutMs            int    `json:"timeout_ms"`
+	MinConfidence        string `json:"min_confidence"`                   // "low", "medium", or "high"
+	ReviewBelowThreshold bool   `json:"review_below_threshold,omitempty"` // pause below min_confidence instead of aborting
 }
 
 // Sessions represents session and git-related timing configuration.
 type Sessions struct {
-	DashboardPollIntervalMs int `json:"dashboard_poll_interval_ms"`
-	GitStatusPollIntervalMs int `json:"git_status_poll_interval_ms"`
-	GitCloneTimeoutMs       int `json:"git_clone_timeout_ms"`
-	GitStatusTimeoutMs      int `json:"git_status_timeout_ms"`
+	DashboardPollIntervalMs int      `json:"dashboard_poll_interval_ms"`
+	DashboardPingIntervalMs int      `json:"dashboard_ping_interval_ms"`
+	GitStatusPollIntervalMs int      `json:"git_status_poll_interval_ms"`
+	GitCloneTimeoutMs       int      `json:"git_clone_timeout_ms"`
+	GitStatusTimeoutMs      int      `json:"git_status_timeout_ms"`
+	GitFetchTimeoutMs       int      `json:"git_fetch_timeout_ms"`
+	GitDiffTimeoutMs        int      `json:"git_diff_timeout_ms"`
+	TmuxSocketName          string   `json:"tmux_socket_name,omitempty"`
+	MaxPromptBytes          int      `json:"max_prompt_bytes"`
+	MaxWorkspacesPerRepo    int      `json:"max_workspaces_per_repo"`
+	MaxPerWorkspace         int      `json:"max_per_workspace"`
+	UnavailableTargetPolicy string   `json:"unavailable_target_policy"`
+	GitHTTPProxy            string   `json:"git_http_proxy,omitempty"`
+	GitAuthorName           string   `json:"git_author_name,omitempty"`
+	GitAuthorEmail          string   `json:"git_author_email,omitempty"`
+	GitSignCommits          bool     `json:"git_sign_commits,omitempty"`
+	GitSigningKey           string   `json:"git_signing_key,omitempty"`
+	NiceLevel               int      `json:"nice_level,omitempty"`
+	IoniceClass             string   `json:"ionice_class,omitempty"`
+	SandboxWrapper          string   `json:"sandbox_wrapper,omitempty"` // read-only; set in config.json
+	ProtectedBranches       []string `json:"protected_branches,omitempty"`
+	SyncCommitTemplate      string   `json:"sync_commit_template,omitempty"`
+	DisposeIgnoreGlobs      []string `json:"dispose_ignore_globs,omitempty"`
+	HistoryEnabled          bool     `json:"history_enabled,omitempty"`
+	HistoryRecordPrompts    bool     `json:"history_record_prompts,omitempty"`
+	PromptHistorySize       int      `json:"prompt_history_size,omitempty"`
+	WorkspaceEnvFile        string   `json:"workspace_env_file"`
+	Offline                 bool     `json:"offline,omitempty"`
+	NicknameCollision       string   `json:"nickname_collision"`
+	BranchGonePolicy        string   `json:"branch_gone_policy"`
+	AutoRestartTargets      []string `json:"auto_restart_targets,omitempty"`
+	AutoRestartMaxRetries   int      `json:"auto_restart_max_retries"`
+	AutoRestartBackoffMs    int      `json:"auto_restart_backoff_ms"`
+	AutoRestartDisabled     bool     `json:"auto_restart_disabled,omitempty"`
+	FailedRemoteGraceMs     int      `json:"failed_remote_grace_ms"`
 }
 
 // Xterm represents terminal capture, timeouts, and log rotation settings.
 type Xterm struct {
-	MtimePollIntervalMs int `json:"mtime_poll_interval_ms"`
-	QueryTimeoutMs      int `json:"query_timeout_ms"`
-	OperationTimeoutMs  int `json:"operation_timeout_ms"`
-	MaxLogSizeMB        int `json:"max_log_size_mb,omitempty"`
-	RotatedLogSizeMB    int `json:"rotated_log_size_mb,omitempty"`
+	MtimePollIntervalMs int  `json:"mtime_poll_interval_ms"`
+	QueryTimeoutMs      int  `json:"query_timeout_ms"`
+	OperationTimeoutMs  int  `json:"operation_timeout_ms"`
+	MaxLogSizeMB        int  `json:"max_log_size_mb,omitempty"`
+	RotatedLogSizeMB    int  `json:"rotated_log_size_mb,omitempty"`
+	RetainAfterDispose  bool `json:"retain_after_dispose"`
 }
 
 // Network controls server binding and TLS.
 type Network struct {
-	BindAddress   string `json:"bind_address"`
-	Port          int    `json:"port"`
-	PublicBaseURL string `json:"public_base_url"`
-	TLS           *TLS   `json:"tls,omitempty"`
+	BindAddress      string   `json:"bind_address"`
+	BindAddresses    []string `json:"bind_addresses"`
+	Port             int      `json:"port"`
+	PublicBaseURL    string   `json:"public_base_url"`
+	TLS              *TLS     `json:"tls,omitempty"`
+	WSAllowedOrigins []string `json:"ws_allowed_origins,omitempty"`
 }
 
 // TLS holds TLS cert paths.
@@ -113,18 +185,24 @@ type TLS struct {
 
 // AccessControl controls authentication.
 type AccessControl struct {
-	Enabled           bool   `json:"enabled"`
-	Provider          string `json:"provider"`
-	SessionTTLMinutes int    `json:"session_ttl_minutes"`
+	Enabled           bool     `json:"enabled"`
+	Provider          string   `json:"provider"`
+	SessionTTLMinutes int      `json:"session_ttl_minutes"`
+	AllowSelfUpdate   bool     `json:"allow_self_update"`           // read-only; set in config.json
+	CommandAllowlist  []string `json:"command_allowlist,omitempty"` // read-only; set in config.json
+	ReadOnly          bool     `json:"read_only"`                   // read-only; set in config.json
 }
 
 // ConfigResponse represents the API response for GET /api/config.
 type ConfigResponse struct {
 	WorkspacePath              string                `json:"workspace_path"`
 	SourceCodeManagement       string                `json:"source_code_management"`
+	PruneBaseRepos             bool                  `json:"prune_base_repos"`
 	Repos                      []RepoWithConfig      `json:"repos"`
 	RunTargets                 []RunTarget           `json:"run_targets"`
+	DefaultModels              map[string]string     `json:"default_models"` // detected tool -> model it runs when picked bare
 	QuickLaunch                []QuickLaunch         `json:"quick_launch"`
+	DefaultSpawn               *DefaultSpawn         `json:"default_spawn,omitempty"`
 	ExternalDiffCommands       []ExternalDiffCommand `json:"external_diff_commands,omitempty"`
 	ExternalDiffCleanupAfterMs int                   `json:"external_diff_cleanup_after_ms,omitempty"`
 	Models                     []Model               `json:"models"`
@@ -148,15 +226,25 @@ type PrReview struct {
 
 // Notifications represents dashboard notification settings.
 type Notifications struct {
-	SoundDisabled bool `json:"sound_disabled"`
+	SoundDisabled bool                         `json:"sound_disabled"`
+	Sounds        map[string]NotificationSound `json:"sounds"` // effective sound per nudge state (needs_input, completed, ...)
+}
+
+// NotificationSound represents the sound played when a session enters a nudge state.
+type NotificationSound struct {
+	Enabled bool   `json:"enabled"`
+	Sound   string `json:"sound"`
 }
 
 // TerminalUpdate represents partial terminal updates.
 type TerminalUpdate struct {
-	Width          *int `json:"width,omitempty"`
-	Height         *int `json:"height,omitempty"`
-	SeedLines      *int `json:"seed_lines,omitempty"`
-	BootstrapLines *int `json:"bootstrap_lines,omitempty"`
+	Width            *int `json:"width,omitempty"`
+	Height           *int `json:"height,omitempty"`
+	SeedLines        *int `json:"seed_lines,omitempty"`
+	BootstrapLines   *int `json:"bootstrap_lines,omitempty"`
+	BootstrapMaxKB   *int `json:"bootstrap_max_kb,omitempty"`
+	OutputIntervalMs *int `json:"output_interval_ms,omitempty"`
+	OutputMaxKB      *int `json:"output_max_kb,omitempty"`
 }
 
 // NudgenikUpdate represents partial nudgenik updates.
@@ -164,42 +252,80 @@ type NudgenikUpdate struct {
 	Target         *string `json:"target,omitempty"`
 	ViewedBufferMs *int    `json:"viewed_buffer_ms,omitempty"`
 	SeenIntervalMs *int    `json:"seen_interval_ms,omitempty"`
+	MaxConcurrent  *int    `json:"max_concurrent,omitempty"`
+	TimeoutMs      *int    `json:"timeout_ms,omitempty"`
 }
 
 // BranchSuggestUpdate represents partial branch suggest updates.
 type BranchSuggestUpdate struct {
-	Target *string `json:"target,omitempty"`
+	Target         *string `json:"target,omitempty"`
+	DefaultEnabled *bool   `json:"default_enabled,omitempty"`
 }
 
 // ConflictResolveUpdate represents partial conflict resolve updates.
 type ConflictResolveUpdate struct {
-	Target    *string `json:"target,omitempty"`
-	TimeoutMs *int    `json:"timeout_ms,omitempty"`
+	Target               *string `json:"target,omitempty"`
+	TimeoutMs            *int    `json:"timeout_ms,omitempty"`
+	MinConfidence        *string `json:"min_confidence,omitempty"`
+	ReviewBelowThreshold *bool   `json:"review_below_threshold,omitempty"`
 }
 
 // SessionsUpdate represents partial session timing updates.
 type SessionsUpdate struct {
-	DashboardPollIntervalMs *int `json:"dashboard_poll_interval_ms,omitempty"`
-	GitStatusPollIntervalMs *int `json:"git_status_poll_interval_ms,omitempty"`
-	GitCloneTimeoutMs       *int `json:"git_clone_timeout_ms,omitempty"`
-	GitStatusTimeoutMs      *int `json:"git_status_timeout_ms,omitempty"`
+	DashboardPollIntervalMs *int     `json:"dashboard_poll_interval_ms,omitempty"`
+	DashboardPingIntervalMs *int     `json:"dashboard_ping_interval_ms,omitempty"`
+	GitStatusPollIntervalMs *int     `json:"git_status_poll_interval_ms,omitempty"`
+	GitCloneTimeoutMs       *int     `json:"git_clone_timeout_ms,omitempty"`
+	GitStatusTimeoutMs      *int     `json:"git_status_timeout_ms,omitempty"`
+	GitFetchTimeoutMs       *int     `json:"git_fetch_timeout_ms,omitempty"`
+	GitDiffTimeoutMs        *int     `json:"git_diff_timeout_ms,omitempty"`
+	TmuxSocketName          *string  `json:"tmux_socket_name,omitempty"`
+	MaxPromptBytes          *int     `json:"max_prompt_bytes,omitempty"`
+	MaxWorkspacesPerRepo    *int     `json:"max_workspaces_per_repo,omitempty"`
+	MaxPerWorkspace         *int     `json:"max_per_workspace,omitempty"`
+	UnavailableTargetPolicy *string  `json:"unavailable_target_policy,omitempty"`
+	GitHTTPProxy            *string  `json:"git_http_proxy,omitempty"`
+	GitAuthorName           *string  `json:"git_author_name,omitempty"`
+	GitAuthorEmail          *string  `json:"git_author_email,omitempty"`
+	GitSignCommits          *bool    `json:"git_sign_commits,omitempty"`
+	GitSigningKey           *string  `json:"git_signing_key,omitempty"`
+	NiceLevel               *int     `json:"nice_level,omitempty"`
+	IoniceClass             *string  `json:"ionice_class,omitempty"`
+	ProtectedBranches       []string `json:"protected_branches,omitempty"` // nil leaves unchanged; [] clears
+	SyncCommitTemplate      *string  `json:"sync_commit_template,omitempty"`
+	DisposeIgnoreGlobs      []string `json:"dispose_ignore_globs,omitempty"` // nil leaves unchanged; [] clears
+	HistoryEnabled          *bool    `json:"history_enabled,omitempty"`
+	HistoryRecordPrompts    *bool    `json:"history_record_prompts,omitempty"`
+	PromptHistorySize       *int     `json:"prompt_history_size,omitempty"`
+	WorkspaceEnvFile        *string  `json:"workspace_env_file,omitempty"`
+	Offline                 *bool    `json:"offline,omitempty"`
+	NicknameCollision       *string  `json:"nickname_collision,omitempty"`
+	BranchGonePolicy        *string  `json:"branch_gone_policy,omitempty"`
+	AutoRestartTargets      []string `json:"auto_restart_targets,omitempty"` // nil leaves unchanged; [] clears
+	AutoRestartMaxRetries   *int     `json:"auto_restart_max_retries,omitempty"`
+	AutoRestartBackoffMs    *int     `json:"auto_restart_backoff_ms,omitempty"`
+	AutoRestartDisabled     *bool    `json:"auto_restart_disabled,omitempty"`
+	FailedRemoteGraceMs     *int     `json:"failed_remote_grace_ms,omitempty"`
 }
 
 // XtermUpdate represents partial xterm updates.
 type XtermUpdate struct {
-	MtimePollIntervalMs *int `json:"mtime_poll_interval_ms,omitempty"`
-	QueryTimeoutMs      *int `json:"query_timeout_ms,omitempty"`
-	OperationTimeoutMs  *int `json:"operation_timeout_ms,omitempty"`
-	MaxLogSizeMB        *int `json:"max_log_size_mb,omitempty"`
-	RotatedLogSizeMB    *int `json:"rotated_log_size_mb,omitempty"`
+	MtimePollIntervalMs *int  `json:"mtime_poll_interval_ms,omitempty"`
+	QueryTimeoutMs      *int  `json:"query_timeout_ms,omitempty"`
+	OperationTimeoutMs  *int  `json:"operation_timeout_ms,omitempty"`
+	MaxLogSizeMB        *int  `json:"max_log_size_mb,omitempty"`
+	RotatedLogSizeMB    *int  `json:"rotated_log_size_mb,omitempty"`
+	RetainAfterDispose  *bool `json:"retain_after_dispose,omitempty"`
 }
 
 // NetworkUpdate represents partial network updates.
 type NetworkUpdate struct {
-	BindAddress   *string    `json:"bind_address,omitempty"`
-	Port          *int       `json:"port,omitempty"`
-	PublicBaseURL *string    `json:"public_base_url,omitempty"`
-	TLS           *TLSUpdate `json:"tls,omitempty"`
+	BindAddress      *string    `json:"bind_address,omitempty"`
+	BindAddresses    []string   `json:"bind_addresses,omitempty"` // nil leaves unchanged; [] clears
+	Port             *int       `json:"port,omitempty"`
+	PublicBaseURL    *string    `json:"public_base_url,omitempty"`
+	TLS              *TLSUpdate `json:"tls,omitempty"`
+	WSAllowedOrigins []string   `json:"ws_allowed_origins,omitempty"` // nil leaves unchanged; [] clears
 }
 
 // TLSUpdate represents partial TLS updates.
@@ -219,9 +345,12 @@ type AccessControlUpdate struct {
 type ConfigUpdateRequest struct {
 	WorkspacePath              *string                `json:"workspace_path,omitempty"`
 	SourceCodeManagement       *string                `json:"source_code_management,omitempty"`
+	PruneBaseRepos             *bool                  `json:"prune_base_repos,omitempty"`
 	Repos                      []Repo                 `json:"repos,omitempty"`
 	RunTargets                 []RunTarget            `json:"run_targets,omitempty"`
+	DefaultModels              map[string]string      `json:"default_models,omitempty"` // nil leaves unchanged; {} clears
 	QuickLaunch                []QuickLaunch          `json:"quick_launch,omitempty"`
+	DefaultSpawn               *DefaultSpawn          `json:"default_spawn,omitempty"` // empty targets clears it
 	ExternalDiffCommands       []ExternalDiffCommand  `json:"external_diff_commands,omitempty"`
 	ExternalDiffCleanupAfterMs *int                   `json:"external_diff_cleanup_after_ms,omitempty"`
 	Nudgenik                   *NudgenikUpdate        `json:"nudgenik,omitempty"`
@@ -243,5 +372,6 @@ type PrReviewUpdate struct {
 
 // NotificationsUpdate represents partial notifications config updates.
 type NotificationsUpdate struct {
-	SoundDisabled *bool `json:"sound_disabled,omitempty"`
+	SoundDisabled *bool                        `json:"sound_disabled,omitempty"`
+	Sounds        map[string]NotificationSound `json:"sounds,omitempty"` // replaces the configured per-state sounds
 }
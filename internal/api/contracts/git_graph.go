@@ -8,6 +8,21 @@ type GitGraphResponse struct {
 	DirtyState *GitGraphDirtyState       `json:"dirty_state,omitempty"`
 }
 
+// WorkspaceCommitsResponse represents the API response for GET /api/workspaces/{workspaceId}/commits.
+type WorkspaceCommitsResponse struct {
+	Branch  string            `json:"branch"`
+	Commits []WorkspaceCommit `json:"commits"`
+}
+
+// WorkspaceCommit represents a single commit in a workspace's recent history.
+type WorkspaceCommit struct {
+	Hash      string `json:"hash"`
+	ShortHash string `json:"short_hash"`
+	Author    string `json:"author"`
+	Timestamp string `json:"timestamp"`
+	Subject   string `json:"subject"`
+}
+
 // GitGraphDirtyState represents uncommitted changes in the workspace.
 type GitGraphDirtyState struct {
 	FilesChanged int `json:"files_changed"`
@@ -0,0 +1,16 @@
+package contracts
+
+// MaintenanceResponse is the maintenance mode state, returned by /api/maintenance and
+// sent to dashboards in "maintenance" websocket messages.
+type MaintenanceResponse struct {
+	Enabled     bool   `json:"enabled"`
+	BlockSpawns bool   `json:"block_spawns"`
+	Since       string `json:"since,omitempty"` // RFC 3339, set while enabled
+}
+
+// MaintenanceRequest is the body of POST /api/maintenance.
+type MaintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+	// BlockSpawns also rejects spawns while maintenance mode is on.
+	BlockSpawns bool `json:"block_spawns,omitempty"`
+}
@@ -0,0 +1,17 @@
+package contracts
+
+// AttentionCountHeader is the GET /api/sessions response header carrying the number of
+// sessions that need attention. The response body is a bare array of workspaces, so the
+// count travels in a header there; the dashboard websocket sends it as attention_count.
+const AttentionCountHeader = "X-Attention-Count"
+
+// SessionOutputSinceResponse is the response for GET /api/sessions/{id}/output/since.
+type SessionOutputSinceResponse struct {
+	// Output is what the session printed after the request's marker.
+	Output string `json:"output"`
+	// Marker is passed as ?marker= on the next request to continue after Output.
+	Marker string `json:"marker"`
+	// Truncated is true when output after the request's marker was lost to log rotation
+	// or a daemon restart. Output then starts at the oldest output still logged.
+	Truncated bool `json:"truncated,omitempty"`
+}
@@ -0,0 +1,33 @@
+package contracts
+
+// SpawnTarget is one run target the spawn form can offer.
+type SpawnTarget struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`  // model display name, otherwise the target name
+	Type       string `json:"type"`   // "promptable" or "command"
+	Source     string `json:"source"` // "detected", "model", or "user"
+	Promptable bool   `json:"promptable"`
+	// Available is false when the tool the target runs on isn't detected.
+	Available bool `json:"available"`
+	// Configured is false when a model target is missing required secrets.
+	Configured      bool     `json:"configured"`
+	BaseTool        string   `json:"base_tool,omitempty"`        // model targets: the tool that runs the model
+	RequiredSecrets []string `json:"required_secrets,omitempty"` // model targets: secrets the model needs
+	UsageURL        string   `json:"usage_url,omitempty"`        // model targets: signup/pricing page
+	Models          []string `json:"models,omitempty"`           // detected tools: IDs of the models they run
+}
+
+// SpawnOptionsResponse is the response for GET /api/spawn-options.
+type SpawnOptionsResponse struct {
+	Targets []SpawnTarget `json:"targets"`
+}
+
+// SpawnProgressResponse is the response for GET /api/spawn/{id}/progress.
+type SpawnProgressResponse struct {
+	SpawnID string `json:"spawn_id"`
+	// Stage is the workspace step in progress: "cloning", "fetching", "checking_out",
+	// "pulling", or "copying_overlays". Empty until the spawn reaches its first step.
+	Stage     string `json:"stage,omitempty"`
+	Detail    string `json:"detail,omitempty"` // e.g. git's "Receiving objects:  45% (450/1000)"
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
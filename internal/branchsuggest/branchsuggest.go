@@ -46,6 +46,14 @@ Here is the user's prompt:
 <<<
 {{USER_PROMPT}}
 >>>
+`
+
+	// prefixRule is added to Prompt for repos with a branch_prefix_regex.
+	prefixRule = `
+This repository requires branch names to start with a match for the regular expression
+{{PREFIX_REGEX}}
+Choose a branch name that does, even if it means not using the prefixes above.
+
 `
 
 	branchSuggestTimeout = 30 * time.Second
@@ -59,12 +67,14 @@ var (
 	ErrInvalidBranch   = errors.New("invalid branch name")
 )
 
-// IsEnabled returns true if branch suggestion is enabled (has a configured target).
-func IsEnabled(cfg *config.Config) bool {
+// IsEnabled returns true if branch suggestion is enabled for the repo: a target is
+// configured and the repo hasn't opted out. An empty or unknown repoURL (e.g. a new
+// local repo) follows branch_suggest.default_enabled.
+func IsEnabled(cfg *config.Config, repoURL string) bool {
 	if cfg == nil {
 		return false
 	}
-	return cfg.GetBranchSuggestTarget() != ""
+	return cfg.GetBranchSuggestTarget() != "" && cfg.BranchSuggestEnabledForRepo(repoURL)
 }
 
 // Result is the parsed branch suggestion response.
@@ -73,8 +83,9 @@ type Result struct {
 	Nickname string `json:"nickname"`
 }
 
-// AskForPrompt generates a branch name and nickname from a user prompt.
-func AskForPrompt(ctx context.Context, cfg *config.Config, userPrompt string) (Result, error) {
+// AskForPrompt generates a branch name and nickname from a user prompt. repoURL, if
+// set, steers the branch name towards the repo's branch_prefix_regex.
+func AskForPrompt(ctx context.Context, cfg *config.Config, userPrompt, repoURL string) (Result, error) {
 	userPrompt = strings.TrimSpace(userPrompt)
 	if userPrompt == "" {
 		return Result{}, ErrNoPrompt
@@ -89,7 +100,7 @@ func AskForPrompt(ctx context.Context, cfg *config.Config, userPrompt string) (R
 		return Result{}, ErrDisabled
 	}
 
-	input := strings.ReplaceAll(Prompt, "{{USER_PROMPT}}", userPrompt)
+	input := buildPrompt(userPrompt, cfg.GetBranchPrefixRegex(repoURL))
 
 	response, err := oneshot.ExecuteTarget(ctx, cfg, targetName, input, oneshot.SchemaBranchSuggest, branchSuggestTimeout, "")
 	if err != nil {
@@ -107,6 +118,16 @@ func AskForPrompt(ctx context.Context, cfg *config.Config, userPrompt string) (R
 	return result, nil
 }
 
+// buildPrompt fills in Prompt, adding the branch prefix rule when prefixRegex is set.
+func buildPrompt(userPrompt, prefixRegex string) string {
+	prompt := Prompt
+	if prefixRegex != "" {
+		rule := strings.ReplaceAll(prefixRule, "{{PREFIX_REGEX}}", prefixRegex)
+		prompt = strings.Replace(prompt, "Here is the user's prompt:", strings.TrimPrefix(rule, "\n")+"Here is the user's prompt:", 1)
+	}
+	return strings.ReplaceAll(prompt, "{{USER_PROMPT}}", userPrompt)
+}
+
 // ParseResult extracts the first JSON object from a raw LLM response and parses it.
 func ParseResult(raw string) (Result, error) {
 	trimmed := strings.TrimSpace(raw)
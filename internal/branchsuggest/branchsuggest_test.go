@@ -1,6 +1,22 @@
 package branchsuggest
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildPrompt(t *testing.T) {
+	plain := buildPrompt("fix the login bug", "")
+	if strings.Contains(plain, "regular expression") || !strings.Contains(plain, "fix the login bug") {
+		t.Errorf("prompt without a prefix regex:\n%s", plain)
+	}
+
+	prefixed := buildPrompt("fix the login bug", "(JIRA-[0-9]+)/")
+	rule := strings.Index(prefixed, "(JIRA-[0-9]+)/")
+	if rule == -1 || rule > strings.Index(prefixed, "fix the login bug") {
+		t.Errorf("prompt with a prefix regex should state it before the user's prompt:\n%s", prefixed)
+	}
+}
 
 func TestParseResult(t *testing.T) {
 	tests := []struct {
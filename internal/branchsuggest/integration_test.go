@@ -83,7 +83,7 @@ func TestBranchSuggestion(t *testing.T) {
 			resultCounts := make(map[string]resultCount)
 
 			for run := 1; run <= passRuns; run++ {
-				result, err := AskForPrompt(context.Background(), cfg, string(prompt))
+				result, err := AskForPrompt(context.Background(), cfg, string(prompt), "")
 				if err != nil {
 					t.Fatalf("ask branch suggest (run %d/%d): %v", run, passRuns, err)
 				}
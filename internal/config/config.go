@@ -6,15 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	"github.com/sergeknystautas/schmux/internal/detect"
+	"github.com/sergeknystautas/schmux/internal/signal"
 	"github.com/sergeknystautas/schmux/internal/version"
 )
 
@@ -23,12 +29,28 @@ var (
 	ErrInvalidConfig  = errors.New("invalid config")
 )
 
+// SyncCommitTemplatePlaceholders are the placeholders allowed in sessions.sync_commit_template.
+var SyncCommitTemplatePlaceholders = []string{"{branch}", "{default_branch}", "{count}", "{subjects}"}
+
+// syncCommitPlaceholderPattern finds placeholder-like tokens in a sync commit template.
+var syncCommitPlaceholderPattern = regexp.MustCompile(`\{[A-Za-z_]+\}`)
+
+// tmuxSocketNamePattern restricts socket names to characters safe to embed unquoted in attach commands.
+var tmuxSocketNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
 const (
 	// Default terminal dimensions
 	DefaultTerminalWidth     = 120
 	DefaultTerminalHeight    = 40
 	DefaultTerminalSeedLines = 100
 	DefaultBootstrapLines    = 20000
+	// DefaultBootstrapMaxKB caps the bytes of scrollback sent on WebSocket connect,
+	// since a line cap alone can still be megabytes when lines are long.
+	DefaultBootstrapMaxKB = 512
+	// DefaultTerminalOutputIntervalMs and DefaultTerminalOutputMaxKB throttle live
+	// terminal output to 64KB per 50ms frame (about 1.25MB/s) per WebSocket.
+	DefaultTerminalOutputIntervalMs = 50
+	DefaultTerminalOutputMaxKB      = 64
 
 	// Default log rotation
 	DefaultMaxLogSizeMB     = 50 // 50MB
@@ -39,15 +61,73 @@ const (
 	DefaultGitStatusPollIntervalMs    = 10000   // 10 seconds
 	DefaultGitStatusWatchDebounceMs   = 1000    // 1 second
 	DefaultGitStatusTimeoutMs         = 30000   // 30 seconds
+	DefaultGitFetchTimeoutMs          = 120000  // 2 minutes
+	DefaultGitDiffTimeoutMs           = 60000   // 1 minute
 	DefaultXtermQueryTimeoutMs        = 5000    // 5 seconds
 	DefaultXtermOperationTimeoutMs    = 10000   // 10 seconds
 	DefaultExternalDiffCleanupAfterMs = 3600000 // 1 hour
 	DefaultConflictResolveTimeoutMs   = 300000  // 5 minutes
 
+	// Default spawn prompt size limit. Prompts are passed to agents as a single argv
+	// string, which Linux caps at 128KiB (MAX_ARG_STRLEN).
+	DefaultMaxPromptBytes = 128*1024 - 1
+
+	// Default cap on workspaces per repo, well below the 999 that workspace numbering allows
+	DefaultMaxWorkspacesPerRepo = 100
+
+	// Default env file, relative to the workspace root, loaded into spawned sessions
+	DefaultWorkspaceEnvFile = ".schmux.env"
+
+	// Default auto-restart policy: restarts per session, and the delay before the
+	// first one (doubled for each later restart)
+	DefaultAutoRestartMaxRetries = 3
+	DefaultAutoRestartBackoffMs  = 5000
+
+	// Default time a failed remote session stays listed before it is disposed
+	DefaultFailedRemoteGraceMs = 3600000 // 1 hour
+
+	// MaxPromptHistorySize caps sessions.prompt_history_size so state.json stays small
+	MaxPromptHistorySize = 100
+
 	// Default auth session TTL in minutes
 	DefaultAuthSessionTTLMinutes = 1440
 )
 
+// Unavailable target policies control what Spawn does when a target can't run yet
+// (for example, a model whose required secret is missing).
+const (
+	UnavailableTargetPolicyFail  = "fail"  // default: the spawn returns an error
+	UnavailableTargetPolicyQueue = "queue" // create a blocked session that can be restarted later
+)
+
+// Nickname collision policies control what spawn and rename do when a nickname is
+// already taken by another session.
+const (
+	NicknameCollisionSuffix = "suffix" // default: use the first free "name (1)", "name (2)", ...
+	NicknameCollisionReject = "reject" // fail the spawn or rename
+)
+
+// Branch-gone policies control what happens to a workspace once its branch has been
+// deleted on the remote, typically after its pull request merged.
+const (
+	BranchGonePolicyWarn    = "warn"    // default: flag the workspace on the dashboard
+	BranchGonePolicyPrompt  = "prompt"  // also ask the dashboard user whether to dispose it
+	BranchGonePolicyDispose = "dispose" // dispose it once it is idle and has nothing unpushed
+)
+
+// I/O scheduling classes for sessions.ionice_class.
+const (
+	IoniceClassBestEffort = "best-effort" // ionice -c 2 at the lowest priority
+	IoniceClassIdle       = "idle"        // ionice -c 3: only when the disk is otherwise idle
+)
+
+// Conflict resolution confidence levels for conflict_resolve.min_confidence, lowest first.
+const (
+	ConfidenceLow    = "low"
+	ConfidenceMedium = "medium"
+	ConfidenceHigh   = "high" // default
+)
+
 // Source code management constants
 const (
 	SourceCodeManagementGitWorktree = "git-worktree" // default: use git worktrees
@@ -60,9 +140,13 @@ type Config struct {
 	WorkspacePath              string                 `json:"workspace_path"`
 	WorktreeBasePath           string                 `json:"base_repos_path,omitempty"`        // path for bare clones (worktree base repos)
 	SourceCodeManagement       string                 `json:"source_code_management,omitempty"` // "git-worktree" (default) or "git"
+	PruneBaseRepos             bool                   `json:"prune_base_repos,omitempty"`       // remove base repos no workspace or configured repo uses
+	SecretsBackend             string                 `json:"secrets_backend,omitempty"`        // "file" (default), "keychain", or "env"; takes effect on restart
 	Repos                      []Repo                 `json:"repos"`
 	RunTargets                 []RunTarget            `json:"run_targets"`
+	DefaultModels              map[string]string      `json:"default_models,omitempty"` // detected tool name -> model ID or alias it runs when picked bare
 	QuickLaunch                []QuickLaunch          `json:"quick_launch"`
+	DefaultSpawn               *DefaultSpawnConfig    `json:"default_spawn,omitempty"`
 	ExternalDiffCommands       []ExternalDiffCommand  `json:"external_diff_commands,omitempty"`
 	ExternalDiffCleanupAfterMs int                    `json:"external_diff_cleanup_after_ms,omitempty"`
 	Terminal                   *TerminalSize          `json:"terminal,omitempty"`
@@ -81,6 +165,11 @@ type Config struct {
 	// path is the file path where this config was loaded from or should be saved to.
 	// Not serialized to JSON.
 	path string `json:"-"`
+
+	// envPort and envBindAddresses hold SCHMUX_PORT and SCHMUX_BIND, applied by Load.
+	// They take precedence over the network settings and are never saved.
+	envPort          int      `json:"-"`
+	envBindAddresses []string `json:"-"`
 }
 
 // RemoteFlavor represents a remote host flavor configuration.
@@ -181,7 +270,33 @@ type PrReviewConfig struct {
 
 // NotificationsConfig holds configuration for dashboard notifications.
 type NotificationsConfig struct {
-	SoundDisabled bool `json:"sound_disabled,omitempty"` // disable attention sounds (default: false = sounds enabled)
+	SoundDisabled bool                         `json:"sound_disabled,omitempty"` // disable attention sounds (default: false = sounds enabled)
+	Sounds        map[string]NotificationSound `json:"sounds,omitempty"`         // per nudge state (needs_input, completed, ...); overrides defaults
+}
+
+// NotificationSound configures the sound played when a session enters a nudge state.
+type NotificationSound struct {
+	Enabled bool   `json:"enabled"`
+	Sound   string `json:"sound,omitempty"` // one of NotificationSoundNames; defaults to "attention"
+}
+
+// Notification sound names understood by the dashboard.
+const (
+	NotificationSoundAttention = "attention"
+	NotificationSoundChime     = "chime"
+)
+
+// NotificationSoundNames lists the valid values for NotificationSound.Sound.
+var NotificationSoundNames = []string{NotificationSoundAttention, NotificationSoundChime}
+
+// defaultNotificationSounds are used for states not present in notifications.sounds.
+// Only states that need the user's attention play a sound by default.
+var defaultNotificationSounds = map[string]NotificationSound{
+	"needs_input":   {Enabled: true, Sound: NotificationSoundAttention},
+	"needs_testing": {Enabled: false, Sound: NotificationSoundAttention},
+	"completed":     {Enabled: false, Sound: NotificationSoundAttention},
+	"error":         {Enabled: true, Sound: NotificationSoundAttention},
+	"working":       {Enabled: false, Sound: NotificationSoundAttention},
 }
 
 // RemoteWorkspaceConfig holds configuration for remote workspace operations.
@@ -206,6 +321,11 @@ type TerminalSize struct {
 	Height         int `json:"height"`
 	SeedLines      int `json:"seed_lines"`
 	BootstrapLines int `json:"bootstrap_lines,omitempty"`
+	BootstrapMaxKB int `json:"bootstrap_max_kb,omitempty"`
+	// OutputIntervalMs and OutputMaxKB cap live output sent to each terminal WebSocket
+	// at OutputMaxKB per OutputIntervalMs, so a runaway agent can't freeze the browser.
+	OutputIntervalMs int `json:"output_interval_ms,omitempty"`
+	OutputMaxKB      int `json:"output_max_kb,omitempty"`
 }
 
 // NudgenikConfig represents configuration for the NudgeNik assistant.
@@ -213,17 +333,31 @@ type NudgenikConfig struct {
 	Target         string `json:"target,omitempty"`
 	ViewedBufferMs int    `json:"viewed_buffer_ms,omitempty"`
 	SeenIntervalMs int    `json:"seen_interval_ms,omitempty"`
+	// MaxConcurrent caps NudgeNik calls in flight at once; extra calls fail as rate limited.
+	MaxConcurrent int `json:"max_concurrent,omitempty"`
+	// TimeoutMs bounds each NudgeNik call.
+	TimeoutMs int `json:"timeout_ms,omitempty"`
 }
 
 // BranchSuggestConfig represents configuration for branch name suggestion.
 type BranchSuggestConfig struct {
 	Target string `json:"target,omitempty"`
+	// DefaultEnabled is whether repos without their own branch_suggest setting get
+	// suggestions. Defaults to true; set false to make suggestion opt-in per repo.
+	DefaultEnabled *bool `json:"default_enabled,omitempty"`
 }
 
 // ConflictResolveConfig represents configuration for conflict resolution.
 type ConflictResolveConfig struct {
 	Target    string `json:"target,omitempty"`
 	TimeoutMs int    `json:"timeout_ms,omitempty"`
+	// MinConfidence is the lowest confidence at which a resolution is committed
+	// automatically. Defaults to "high".
+	MinConfidence string `json:"min_confidence,omitempty"`
+	// ReviewBelowThreshold leaves the rebase paused at a resolution below
+	// MinConfidence, with the resolved files in place for review, instead of aborting
+	// the sync.
+	ReviewBelowThreshold bool `json:"review_below_threshold,omitempty"`
 }
 
 // SessionsConfig represents session and git-related timing configuration.
@@ -234,23 +368,112 @@ type SessionsConfig struct {
 	GitStatusTimeoutMs       int   `json:"git_status_timeout_ms"`
 	GitStatusWatchEnabled    *bool `json:"git_status_watch_enabled,omitempty"`
 	GitStatusWatchDebounceMs int   `json:"git_status_watch_debounce_ms,omitempty"`
+	// DashboardPingIntervalMs is how often the dashboard websocket is pinged, so
+	// proxies and NATs with idle timeouts don't drop it. Defaults to 25000ms.
+	DashboardPingIntervalMs int `json:"dashboard_ping_interval_ms,omitempty"`
+	// GitFetchTimeoutMs bounds fetches and other origin queries outside a spawn;
+	// GitDiffTimeoutMs bounds diffs, file contents, and commit logs for the dashboard.
+	GitFetchTimeoutMs int `json:"git_fetch_timeout_ms,omitempty"`
+	GitDiffTimeoutMs  int `json:"git_diff_timeout_ms,omitempty"`
+	// TmuxSocketName runs schmux sessions on a dedicated tmux server (tmux -L <name>).
+	// Empty uses the user's default tmux server. Takes effect on daemon restart.
+	TmuxSocketName string `json:"tmux_socket_name,omitempty"`
+	// MaxPromptBytes caps the size of spawn prompts. Defaults to DefaultMaxPromptBytes.
+	MaxPromptBytes int `json:"max_prompt_bytes,omitempty"`
+	// MaxWorkspacesPerRepo caps how many workspaces a repo may have. Defaults to DefaultMaxWorkspacesPerRepo.
+	MaxWorkspacesPerRepo int `json:"max_workspaces_per_repo,omitempty"`
+	// MaxPerWorkspace caps how many sessions a workspace may have; they all share one
+	// working tree. 0 (default) means unlimited.
+	MaxPerWorkspace int `json:"max_per_workspace,omitempty"`
+	// UnavailableTargetPolicy is "fail" (default) or "queue". With "queue", spawning a
+	// target that is missing secrets creates a blocked session instead of failing.
+	UnavailableTargetPolicy string `json:"unavailable_target_policy,omitempty"`
+	// GitHTTPProxy is set as http.proxy/https.proxy on the repos schmux clones,
+	// e.g. "http://proxy.corp:3128". Empty uses git's own configuration.
+	GitHTTPProxy string `json:"git_http_proxy,omitempty"`
+	// GitAuthorName and GitAuthorEmail are the identity for commits schmux makes on the
+	// user's behalf (local repo init, linear-sync squash and rebase). Empty uses git's
+	// own configuration, or "schmux <schmux@localhost>" for new local repos.
+	GitAuthorName  string `json:"git_author_name,omitempty"`
+	GitAuthorEmail string `json:"git_author_email,omitempty"`
+	// GitSignCommits signs those commits (git commit/rebase --gpg-sign) for repos that
+	// reject unsigned commits. GitSigningKey picks the key; empty uses user.signingkey.
+	GitSignCommits bool   `json:"git_sign_commits,omitempty"`
+	GitSigningKey  string `json:"git_signing_key,omitempty"`
+	// NiceLevel runs spawned sessions under nice at this niceness (1-19) so agents
+	// running builds don't starve interactive work. 0 leaves priority unchanged.
+	NiceLevel int `json:"nice_level,omitempty"`
+	// IoniceClass runs spawned sessions under ionice on Linux: "best-effort" or "idle".
+	// Empty leaves I/O priority unchanged.
+	IoniceClass string `json:"ionice_class,omitempty"`
+	// SandboxWrapper is a command prefix that agent and command sessions run inside,
+	// e.g. "firejail --net=none" or a bwrap/docker invocation. ${SCHMUX_WORKSPACE_PATH}
+	// expands to the workspace directory. Empty runs sessions unsandboxed.
+	SandboxWrapper string `json:"sandbox_wrapper,omitempty"`
+	// ProtectedBranches lists branch names or glob patterns (e.g. "main", "release/*")
+	// that spawns are rejected on unless the request sets allow_protected.
+	ProtectedBranches []string `json:"protected_branches,omitempty"`
+	// SyncCommitTemplate, when set, squashes the commits pushed by linear sync to the
+	// default branch into one commit with this message. See SyncCommitTemplatePlaceholders.
+	SyncCommitTemplate string `json:"sync_commit_template,omitempty"`
+	// DisposeIgnoreGlobs lists paths (e.g. ".env", "CLAUDE.md", "tmp/**") whose changes
+	// don't count against a workspace in the dispose safety check.
+	DisposeIgnoreGlobs []string `json:"dispose_ignore_globs,omitempty"`
+	// HistoryEnabled appends a record of each disposed session to history.jsonl
+	// next to state.json, served by GET /api/history.
+	HistoryEnabled bool `json:"history_enabled,omitempty"`
+	// HistoryRecordPrompts also records each session's spawn prompt in the history.
+	// Off by default because prompts may contain sensitive context.
+	HistoryRecordPrompts bool `json:"history_record_prompts,omitempty"`
+	// PromptHistorySize keeps each workspace's last N spawn prompts in state.json, for
+	// GET /api/workspaces/{id}/prompts and re-running them. 0 (the default) keeps none,
+	// since prompts may contain sensitive context. At most MaxPromptHistorySize.
+	PromptHistorySize int `json:"prompt_history_size,omitempty"`
+	// WorkspaceEnvFile is a KEY=VALUE file, relative to the workspace root, whose variables
+	// are set in every local session spawned in that workspace. Defaults to DefaultWorkspaceEnvFile.
+	WorkspaceEnvFile string `json:"workspace_env_file,omitempty"`
+	// Offline skips every network git operation (fetch, pull, push, clone). Git status
+	// and syncs work against the local origin/* refs from the last successful fetch.
+	Offline bool `json:"offline,omitempty"`
+	// NicknameCollision is "suffix" (default) or "reject": what spawn and rename do
+	// when the requested nickname is already in use.
+	NicknameCollision string `json:"nickname_collision,omitempty"`
+	// BranchGonePolicy is "warn" (default), "prompt", or "dispose": what happens to a
+	// workspace whose branch was pushed and has since been deleted on the remote.
+	BranchGonePolicy string `json:"branch_gone_policy,omitempty"`
+	// AutoRestartTargets lists targets whose sessions are restarted when their agent
+	// exits non-zero, up to AutoRestartMaxRetries times with a delay starting at
+	// AutoRestartBackoffMs and doubling each time. AutoRestartDisabled is a kill-switch
+	// that stops all auto-restarts without touching the rest of the policy.
+	AutoRestartTargets    []string `json:"auto_restart_targets,omitempty"`
+	AutoRestartMaxRetries int      `json:"auto_restart_max_retries,omitempty"`
+	AutoRestartBackoffMs  int      `json:"auto_restart_backoff_ms,omitempty"`
+	AutoRestartDisabled   bool     `json:"auto_restart_disabled,omitempty"`
+	// FailedRemoteGraceMs is how long a remote session whose creation failed stays
+	// listed, so its error can be read, before it is disposed. Defaults to DefaultFailedRemoteGraceMs.
+	FailedRemoteGraceMs int `json:"failed_remote_grace_ms,omitempty"`
 }
 
 // XtermConfig represents terminal capture, timeouts, and log rotation settings.
 type XtermConfig struct {
-	MtimePollIntervalMs int `json:"mtime_poll_interval_ms"`
-	QueryTimeoutMs      int `json:"query_timeout_ms"`
-	OperationTimeoutMs  int `json:"operation_timeout_ms"`
-	MaxLogSizeMB        int `json:"max_log_size_mb,omitempty"`     // max log size before rotation
-	RotatedLogSizeMB    int `json:"rotated_log_size_mb,omitempty"` // target size after rotation (keeps tail)
+	MtimePollIntervalMs int  `json:"mtime_poll_interval_ms"`
+	QueryTimeoutMs      int  `json:"query_timeout_ms"`
+	OperationTimeoutMs  int  `json:"operation_timeout_ms"`
+	MaxLogSizeMB        int  `json:"max_log_size_mb,omitempty"`      // max log size before rotation
+	RotatedLogSizeMB    int  `json:"rotated_log_size_mb,omitempty"`  // target size after rotation (keeps tail)
+	RetainAfterDispose  bool `json:"retain_after_dispose,omitempty"` // keep session logs after dispose (default: false = delete)
 }
 
 // NetworkConfig controls server binding and TLS.
 type NetworkConfig struct {
 	BindAddress   string     `json:"bind_address,omitempty"`
+	BindAddresses []string   `json:"bind_addresses,omitempty"` // additional addresses to listen on (e.g. a VPN interface)
 	Port          int        `json:"port,omitempty"`
 	PublicBaseURL string     `json:"public_base_url,omitempty"`
 	TLS           *TLSConfig `json:"tls,omitempty"`
+	// WSAllowedOrigins are extra origins (scheme://host[:port]) allowed to open WebSockets,
+	// e.g. a reverse proxy whose address differs from public_base_url.
+	WSAllowedOrigins []string `json:"ws_allowed_origins,omitempty"`
 }
 
 // TLSConfig holds TLS certificate paths.
@@ -264,12 +487,50 @@ type AccessControlConfig struct {
 	Enabled           bool   `json:"enabled"`
 	Provider          string `json:"provider,omitempty"`
 	SessionTTLMinutes int    `json:"session_ttl_minutes,omitempty"`
+	// AllowSelfUpdate permits POST /api/update to replace the binary. Defaults to true.
+	// Only settable in config.json, so dashboard users cannot re-enable it.
+	AllowSelfUpdate *bool `json:"allow_self_update,omitempty"`
+	// CommandAllowlist restricts the shell commands that spawns may run through
+	// command-type targets, quick launches, and raw commands. Each entry is a regular
+	// expression that must match the whole command. Empty allows any command.
+	// Only settable in config.json, so dashboard users cannot loosen it.
+	CommandAllowlist []string `json:"command_allowlist,omitempty"`
+	// ReadOnly turns the dashboard into a kiosk: every non-GET /api request is
+	// rejected with 403 and terminals accept no input. Only settable in config.json.
+	ReadOnly bool `json:"read_only,omitempty"`
 }
 
 // Repo represents a git repository configuration.
 type Repo struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
+	// SSHKeyPath is a private key used for this repo's clones and fetches instead of
+	// the SSH agent's default identity (e.g. a per-host deploy key). Supports ~.
+	SSHKeyPath string `json:"ssh_key_path,omitempty"`
+	// BranchSuggest turns branch suggestion on or off for this repo, overriding
+	// branch_suggest.default_enabled. nil follows the default.
+	BranchSuggest *bool `json:"branch_suggest,omitempty"`
+	// BranchPrefixRegex is a regular expression new branches for this repo must start
+	// with a match for, e.g. "(feature|fix|chore)/". Empty allows any branch name.
+	BranchPrefixRegex string `json:"branch_prefix_regex,omitempty"`
+	// PreDisposeCommand is a shell command run in a workspace's directory just before
+	// the workspace is disposed, e.g. to drop a database named after the branch.
+	// Failures are logged and don't block the dispose.
+	PreDisposeCommand string `json:"pre_dispose_command,omitempty"`
+	// CloneFilter makes new clones of this repo partial clones with git's --filter,
+	// e.g. "blob:none", so file contents are fetched on demand. Empty clones everything.
+	CloneFilter string `json:"clone_filter,omitempty"`
+}
+
+// ResolvedSSHKeyPath returns SSHKeyPath with a leading ~ expanded, or "" if unset.
+func (r Repo) ResolvedSSHKeyPath() string {
+	keyPath := strings.TrimSpace(r.SSHKeyPath)
+	if strings.HasPrefix(keyPath, "~") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			keyPath = filepath.Join(homeDir, strings.TrimPrefix(keyPath, "~"))
+		}
+	}
+	return keyPath
 }
 
 // RunTarget represents a user-supplied run target.
@@ -278,6 +539,29 @@ type RunTarget struct {
 	Type    string `json:"type"`    // "promptable" or "command"
 	Command string `json:"command"` // shell command to run
 	Source  string `json:"source,omitempty"`
+	// Shell optionally runs the command through a login shell ("bash", "/bin/zsh")
+	// so shell init files (nvm, pyenv) are loaded. Empty uses tmux's default shell.
+	Shell string `json:"shell,omitempty"`
+	// TmuxOptions sets tmux session options for this target's sessions, applied over
+	// schmux's defaults (e.g. {"history-limit": "100000"}). Names must be in AllowedTmuxOptions.
+	TmuxOptions map[string]string `json:"tmux_options,omitempty"`
+	// Panes are extra panes split off the agent's pane when a session starts, e.g. a
+	// log tail beside the agent. Layout, if set, is a tmux preset layout applied
+	// after the splits ("even-horizontal", "main-vertical", ...).
+	Panes  []TargetPane `json:"panes,omitempty"`
+	Layout string       `json:"layout,omitempty"`
+	// OnExitCommand is a shell command run in the workspace whenever the agent of one
+	// of this target's sessions exits, with the session's details in SCHMUX_* env vars.
+	OnExitCommand string `json:"on_exit_command,omitempty"`
+}
+
+// TargetPane is an extra pane of a run target's sessions.
+type TargetPane struct {
+	Command string `json:"command"`
+	// Split is "vertical" (the default, new pane below) or "horizontal" (beside).
+	Split string `json:"split,omitempty"`
+	// Size is the new pane's share of the split in percent; 0 splits evenly.
+	Size int `json:"size,omitempty"`
 }
 
 // QuickLaunch represents a saved run preset.
@@ -289,12 +573,24 @@ type QuickLaunch struct {
 	Prompt  *string `json:"prompt,omitempty"`  // prompt for the target
 }
 
+// DefaultSpawnConfig is the standard set of agents to spawn: it pre-populates the
+// spawn form and is what POST /api/spawn-default spawns.
+type DefaultSpawnConfig struct {
+	Targets  map[string]int `json:"targets"`            // target name -> quantity
+	Nickname string         `json:"nickname,omitempty"` // optional nickname for the spawned sessions
+}
+
 // ExternalDiffCommand represents an external diff tool configuration.
 type ExternalDiffCommand struct {
 	Name    string `json:"name"`
 	Command string `json:"command"`
 }
 
+const (
+	PaneSplitVertical   = "vertical"
+	PaneSplitHorizontal = "horizontal"
+)
+
 const (
 	RunTargetTypePromptable = "promptable"
 	RunTargetTypeCommand    = "command"
@@ -392,6 +688,95 @@ func (c *Config) validate(strict bool) ([]string, error) {
 	if err := validateRunTargetDependencies(c.RunTargets, c.QuickLaunch, c.Nudgenik); err != nil {
 		return nil, err
 	}
+	if err := validateDefaultSpawn(c.DefaultSpawn, c.RunTargets); err != nil {
+		return nil, err
+	}
+	if err := validateDefaultModels(c.DefaultModels); err != nil {
+		return nil, err
+	}
+	if err := c.validateBindAddresses(); err != nil {
+		return nil, err
+	}
+	if err := c.validateWSAllowedOrigins(); err != nil {
+		return nil, err
+	}
+	if name := c.GetTmuxSocketName(); name != "" && !tmuxSocketNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: sessions.tmux_socket_name %q may only contain letters, digits, '.', '_' and '-'", ErrInvalidConfig, name)
+	}
+	if email := c.GetGitAuthorEmail(); email != "" && (!strings.Contains(email, "@") || strings.ContainsAny(email, "<>\n")) {
+		return nil, fmt.Errorf("%w: sessions.git_author_email must be an email address, got %q", ErrInvalidConfig, email)
+	}
+	if strings.ContainsAny(c.GetGitAuthorName(), "<>\n") {
+		return nil, fmt.Errorf("%w: sessions.git_author_name may not contain '<', '>' or newlines", ErrInvalidConfig)
+	}
+	if strings.ContainsAny(c.GetGitSigningKey(), "\n") {
+		return nil, fmt.Errorf("%w: sessions.git_signing_key may not contain newlines", ErrInvalidConfig)
+	}
+	if level := c.GetNiceLevel(); level < 0 || level > 19 {
+		return nil, fmt.Errorf("%w: sessions.nice_level must be between 0 and 19, got %d", ErrInvalidConfig, level)
+	}
+	if size := c.GetPromptHistorySize(); size < 0 || size > MaxPromptHistorySize {
+		return nil, fmt.Errorf("%w: sessions.prompt_history_size must be between 0 and %d, got %d", ErrInvalidConfig, MaxPromptHistorySize, size)
+	}
+	if confidence := c.GetConflictResolveMinConfidence(); confidenceRank(confidence) < 0 {
+		return nil, fmt.Errorf("%w: conflict_resolve.min_confidence must be %q, %q, or %q, got %q", ErrInvalidConfig, ConfidenceLow, ConfidenceMedium, ConfidenceHigh, confidence)
+	}
+	if class := c.GetIoniceClass(); class != "" && class != IoniceClassBestEffort && class != IoniceClassIdle {
+		return nil, fmt.Errorf("%w: sessions.ionice_class must be %q or %q, got %q", ErrInvalidConfig, IoniceClassBestEffort, IoniceClassIdle, class)
+	}
+	if backend := c.GetSecretsBackend(); backend != SecretsBackendFile && backend != SecretsBackendKeychain && backend != SecretsBackendEnv {
+		return nil, fmt.Errorf("%w: secrets_backend must be %q, %q, or %q, got %q", ErrInvalidConfig, SecretsBackendFile, SecretsBackendKeychain, SecretsBackendEnv, backend)
+	}
+	if proxy := c.GetGitHTTPProxy(); proxy != "" {
+		if u, err := url.Parse(proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("%w: sessions.git_http_proxy must be a URL like http://proxy:3128, got %q", ErrInvalidConfig, proxy)
+		}
+	}
+	for _, repo := range c.Repos {
+		if pattern := strings.TrimSpace(repo.BranchPrefixRegex); pattern != "" {
+			if _, err := branchPrefixRegexp(pattern); err != nil {
+				return nil, fmt.Errorf("%w: repo %s branch_prefix_regex %q is not a valid regular expression: %v", ErrInvalidConfig, repo.Name, pattern, err)
+			}
+		}
+		if filter := strings.TrimSpace(repo.CloneFilter); filter != "" && !ValidCloneFilter(filter) {
+			return nil, fmt.Errorf("%w: repo %s clone_filter must be \"blob:none\", \"blob:limit=<size>\", or \"tree:<depth>\", got %q", ErrInvalidConfig, repo.Name, filter)
+		}
+	}
+	for _, pattern := range c.GetProtectedBranches() {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: sessions.protected_branches has invalid pattern %q", ErrInvalidConfig, pattern)
+		}
+	}
+	for _, pattern := range c.GetDisposeIgnoreGlobs() {
+		if err := ValidatePathGlob(pattern); err != nil {
+			return nil, fmt.Errorf("%w: sessions.dispose_ignore_globs has invalid pattern %q", ErrInvalidConfig, pattern)
+		}
+	}
+	if err := validateSyncCommitTemplate(c.GetSyncCommitTemplate()); err != nil {
+		return nil, err
+	}
+	if envFile := c.GetWorkspaceEnvFile(); !filepath.IsLocal(envFile) {
+		return nil, fmt.Errorf("%w: sessions.workspace_env_file must be a path inside the workspace, got %q", ErrInvalidConfig, envFile)
+	}
+	if policy := c.GetUnavailableTargetPolicy(); policy != UnavailableTargetPolicyFail && policy != UnavailableTargetPolicyQueue {
+		return nil, fmt.Errorf("%w: sessions.unavailable_target_policy must be %q or %q, got %q", ErrInvalidConfig, UnavailableTargetPolicyFail, UnavailableTargetPolicyQueue, policy)
+	}
+	if policy := c.GetNicknameCollision(); policy != NicknameCollisionSuffix && policy != NicknameCollisionReject {
+		return nil, fmt.Errorf("%w: sessions.nickname_collision must be %q or %q, got %q", ErrInvalidConfig, NicknameCollisionSuffix, NicknameCollisionReject, policy)
+	}
+	switch c.GetBranchGonePolicy() {
+	case BranchGonePolicyWarn, BranchGonePolicyPrompt, BranchGonePolicyDispose:
+	default:
+		return nil, fmt.Errorf("%w: sessions.branch_gone_policy must be %q, %q, or %q, got %q", ErrInvalidConfig, BranchGonePolicyWarn, BranchGonePolicyPrompt, BranchGonePolicyDispose, c.GetBranchGonePolicy())
+	}
+	if c.Notifications != nil {
+		if err := validateNotificationSounds(c.Notifications.Sounds); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validateCommandAllowlist(); err != nil {
+		return nil, err
+	}
 	warnings, err := c.validateAccessControl(strict)
 	if err != nil {
 		return nil, err
@@ -448,6 +833,20 @@ func (c *Config) GetSourceCodeManagement() string {
 	return c.SourceCodeManagement
 }
 
+// GetPruneBaseRepos returns whether the daemon removes base repos that no workspace
+// or configured repo uses.
+func (c *Config) GetPruneBaseRepos() bool {
+	return c != nil && c.PruneBaseRepos
+}
+
+// GetSecretsBackend returns where model and auth secrets are stored. Defaults to "file".
+func (c *Config) GetSecretsBackend() string {
+	if c == nil || c.SecretsBackend == "" {
+		return SecretsBackendFile
+	}
+	return c.SecretsBackend
+}
+
 // UseWorktrees returns true if the source code management mode is git-worktree.
 func (c *Config) UseWorktrees() bool {
 	return c.GetSourceCodeManagement() == SourceCodeManagementGitWorktree
@@ -468,6 +867,14 @@ func (c *Config) GetQuickLaunch() []QuickLaunch {
 	return c.QuickLaunch
 }
 
+// GetDefaultSpawn returns the default spawn set, or nil when none is configured.
+func (c *Config) GetDefaultSpawn() *DefaultSpawnConfig {
+	if c.DefaultSpawn == nil || len(c.DefaultSpawn.Targets) == 0 {
+		return nil
+	}
+	return c.DefaultSpawn
+}
+
 // GetExternalDiffCommands returns the list of external diff commands.
 func (c *Config) GetExternalDiffCommands() []ExternalDiffCommand {
 	return c.ExternalDiffCommands
@@ -497,6 +904,98 @@ func (c *Config) GetBranchSuggestTarget() string {
 	return strings.TrimSpace(c.BranchSuggest.Target)
 }
 
+// GetBranchSuggestDefaultEnabled returns branch_suggest.default_enabled. Defaults to true.
+func (c *Config) GetBranchSuggestDefaultEnabled() bool {
+	if c == nil || c.BranchSuggest == nil || c.BranchSuggest.DefaultEnabled == nil {
+		return true
+	}
+	return *c.BranchSuggest.DefaultEnabled
+}
+
+// BranchSuggestEnabledForRepo reports whether branch suggestion applies to the repo
+// with the given URL: its own branch_suggest setting if it has one, otherwise
+// branch_suggest.default_enabled. It doesn't check that a target is configured.
+func (c *Config) BranchSuggestEnabledForRepo(repoURL string) bool {
+	if c != nil && repoURL != "" {
+		for _, repo := range c.Repos {
+			if repo.URL == repoURL && repo.BranchSuggest != nil {
+				return *repo.BranchSuggest
+			}
+		}
+	}
+	return c.GetBranchSuggestDefaultEnabled()
+}
+
+// GetBranchPrefixRegex returns the branch_prefix_regex of the repo with the given URL,
+// or "" if it has none.
+func (c *Config) GetBranchPrefixRegex(repoURL string) string {
+	if c == nil || repoURL == "" {
+		return ""
+	}
+	for _, repo := range c.Repos {
+		if repo.URL == repoURL {
+			return strings.TrimSpace(repo.BranchPrefixRegex)
+		}
+	}
+	return ""
+}
+
+// GetPreDisposeCommand returns the pre_dispose_command of the repo with the given URL,
+// or "" if it has none.
+func (c *Config) GetPreDisposeCommand(repoURL string) string {
+	if c == nil || repoURL == "" {
+		return ""
+	}
+	for _, repo := range c.Repos {
+		if repo.URL == repoURL {
+			return strings.TrimSpace(repo.PreDisposeCommand)
+		}
+	}
+	return ""
+}
+
+// GetRepoCloneFilter returns the clone_filter of the repo with the given URL, or "" if
+// its clones aren't partial.
+func (c *Config) GetRepoCloneFilter(repoURL string) string {
+	if c == nil || repoURL == "" {
+		return ""
+	}
+	for _, repo := range c.Repos {
+		if repo.URL == repoURL {
+			return strings.TrimSpace(repo.CloneFilter)
+		}
+	}
+	return ""
+}
+
+// cloneFilterPattern matches the git --filter specs clone_filter accepts.
+var cloneFilterPattern = regexp.MustCompile(`^(blob:none|blob:limit=[0-9]+[kmg]?|tree:[0-9]+)$`)
+
+// ValidCloneFilter reports whether filter is a clone_filter value schmux accepts:
+// "blob:none", "blob:limit=<size>", or "tree:<depth>".
+func ValidCloneFilter(filter string) bool {
+	return cloneFilterPattern.MatchString(filter)
+}
+
+// BranchMatchesPrefix reports whether branch starts with a match for the
+// branch_prefix_regex of the repo with the given URL. Repos without one accept any branch.
+func (c *Config) BranchMatchesPrefix(repoURL, branch string) bool {
+	pattern := c.GetBranchPrefixRegex(repoURL)
+	if pattern == "" {
+		return true
+	}
+	re, err := branchPrefixRegexp(pattern)
+	if err != nil {
+		return true // rejected when the config is validated
+	}
+	return re.MatchString(branch)
+}
+
+// branchPrefixRegexp compiles a branch_prefix_regex anchored to the start of the branch name.
+func branchPrefixRegexp(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)`)
+}
+
 // GetConflictResolveTarget returns the configured conflict resolution target name, if any.
 func (c *Config) GetConflictResolveTarget() string {
 	if c == nil || c.ConflictResolve == nil {
@@ -514,6 +1013,34 @@ func (c *Config) GetConflictResolveTimeoutMs() int {
 	return c.ConflictResolve.TimeoutMs
 }
 
+// GetConflictResolveMinConfidence returns the lowest confidence at which a conflict
+// resolution is committed automatically. Defaults to ConfidenceHigh.
+func (c *Config) GetConflictResolveMinConfidence() string {
+	if c == nil || c.ConflictResolve == nil || c.ConflictResolve.MinConfidence == "" {
+		return ConfidenceHigh
+	}
+	return c.ConflictResolve.MinConfidence
+}
+
+// GetConflictResolveReviewBelowThreshold reports whether a resolution below
+// min_confidence pauses the rebase for review instead of aborting the sync.
+func (c *Config) GetConflictResolveReviewBelowThreshold() bool {
+	return c != nil && c.ConflictResolve != nil && c.ConflictResolve.ReviewBelowThreshold
+}
+
+// MeetsConflictResolveConfidence reports whether a resolution of the given confidence
+// may be committed automatically. Unknown confidence values never meet the threshold.
+func (c *Config) MeetsConflictResolveConfidence(confidence string) bool {
+	rank := confidenceRank(confidence)
+	return rank >= 0 && rank >= confidenceRank(c.GetConflictResolveMinConfidence())
+}
+
+// confidenceRank orders confidence levels from low (0) to high. Returns -1 for
+// anything else.
+func confidenceRank(confidence string) int {
+	return slices.Index([]string{ConfidenceLow, ConfidenceMedium, ConfidenceHigh}, confidence)
+}
+
 // GetPrReviewTarget returns the configured target for PR review sessions.
 func (c *Config) GetPrReviewTarget() string {
 	if c == nil || c.PrReview == nil {
@@ -531,6 +1058,37 @@ func (c *Config) GetNotificationSoundEnabled() bool {
 	return !c.Notifications.SoundDisabled
 }
 
+// GetNotificationSounds returns the effective per-state notification sounds:
+// the defaults for every nudge state, overridden by notifications.sounds.
+func (c *Config) GetNotificationSounds() map[string]NotificationSound {
+	sounds := make(map[string]NotificationSound, len(defaultNotificationSounds))
+	for state, sound := range defaultNotificationSounds {
+		sounds[state] = sound
+	}
+	if c == nil || c.Notifications == nil {
+		return sounds
+	}
+	for state, sound := range c.Notifications.Sounds {
+		if sound.Sound == "" {
+			sound.Sound = NotificationSoundAttention
+		}
+		sounds[state] = sound
+	}
+	return sounds
+}
+
+func validateNotificationSounds(sounds map[string]NotificationSound) error {
+	for state, sound := range sounds {
+		if !signal.IsValidState(state) {
+			return fmt.Errorf("%w: notifications.sounds: unknown state %q", ErrInvalidConfig, state)
+		}
+		if sound.Sound != "" && !slices.Contains(NotificationSoundNames, sound.Sound) {
+			return fmt.Errorf("%w: notifications.sounds.%s: unknown sound %q (valid: %s)", ErrInvalidConfig, state, sound.Sound, strings.Join(NotificationSoundNames, ", "))
+		}
+	}
+	return nil
+}
+
 // GetDetectedRunTarget finds a detected run target by name.
 func (c *Config) GetDetectedRunTarget(name string) (RunTarget, bool) {
 	for _, target := range c.RunTargets {
@@ -562,6 +1120,23 @@ func (c *Config) FindRepo(name string) (Repo, bool) {
 	return Repo{}, false
 }
 
+// GetRepoSSHCommand returns the ssh command (for core.sshCommand / GIT_SSH_COMMAND)
+// that uses the repo's configured ssh_key_path, or "" if the repo has none.
+func (c *Config) GetRepoSSHCommand(repoURL string) string {
+	for _, repo := range c.Repos {
+		if repo.URL != repoURL {
+			continue
+		}
+		keyPath := repo.ResolvedSSHKeyPath()
+		if keyPath == "" {
+			return ""
+		}
+		// git runs the command through a shell, so quote the path.
+		return "ssh -i '" + strings.ReplaceAll(keyPath, "'", `'\''`) + "' -o IdentitiesOnly=yes"
+	}
+	return ""
+}
+
 // GetRunTarget finds a run target by name.
 func (c *Config) GetRunTarget(name string) (RunTarget, bool) {
 	for _, target := range c.RunTargets {
@@ -572,6 +1147,15 @@ func (c *Config) GetRunTarget(name string) (RunTarget, bool) {
 	return RunTarget{}, false
 }
 
+// GetOnExitCommand returns the on_exit_command of the named run target, if any.
+func (c *Config) GetOnExitCommand(target string) string {
+	runTarget, found := c.GetRunTarget(target)
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(runTarget.OnExitCommand)
+}
+
 // GetTerminalSize returns the terminal size. Returns 0,0 if not configured.
 func (c *Config) GetTerminalSize() (width, height int) {
 	if c.Terminal != nil && c.Terminal.Width > 0 && c.Terminal.Height > 0 {
@@ -597,6 +1181,39 @@ func (c *Config) GetTerminalBootstrapLines() int {
 	return c.Terminal.BootstrapLines
 }
 
+// GetTerminalBootstrapMaxBytes returns the maximum bytes of scrollback to send on WebSocket connect.
+// Defaults to DefaultBootstrapMaxKB if not set.
+func (c *Config) GetTerminalBootstrapMaxBytes() int {
+	if c.Terminal == nil || c.Terminal.BootstrapMaxKB <= 0 {
+		return DefaultBootstrapMaxKB * 1024
+	}
+	return c.Terminal.BootstrapMaxKB * 1024
+}
+
+// GetTerminalOutputInterval returns how often buffered terminal output is flushed to
+// WebSocket clients. Defaults to DefaultTerminalOutputIntervalMs if not set.
+func (c *Config) GetTerminalOutputInterval() time.Duration {
+	if c.Terminal == nil || c.Terminal.OutputIntervalMs <= 0 {
+		return DefaultTerminalOutputIntervalMs * time.Millisecond
+	}
+	return time.Duration(c.Terminal.OutputIntervalMs) * time.Millisecond
+}
+
+// GetTerminalOutputMaxBytes returns the most terminal output sent to a WebSocket client
+// per output interval. Defaults to DefaultTerminalOutputMaxKB if not set.
+func (c *Config) GetTerminalOutputMaxBytes() int {
+	if c.Terminal == nil || c.Terminal.OutputMaxKB <= 0 {
+		return DefaultTerminalOutputMaxKB * 1024
+	}
+	return c.Terminal.OutputMaxKB * 1024
+}
+
+// Path returns the file the config was loaded from and is saved to. Empty for a
+// config built in memory.
+func (c *Config) Path() string {
+	return c.path
+}
+
 // Reload reloads the configuration from disk and replaces this Config struct.
 func (c *Config) Reload() error {
 	if c.path == "" {
@@ -627,6 +1244,10 @@ func (c *Config) Reload() error {
 		newCfg.WorktreeBasePath = filepath.Join(homeDir, newCfg.WorktreeBasePath[1:])
 	}
 	newCfg.expandNetworkPaths(homeDir)
+	// The env overrides live outside the file, so re-read them for the new config
+	if err := newCfg.applyEnvOverrides(); err != nil {
+		return err
+	}
 
 	// Preserve the existing path
 	existingPath := c.path
@@ -708,6 +1329,9 @@ func Load(configPath string) (*Config, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	if err := cfg.applyEnvOverrides(); err != nil {
+		return nil, err
+	}
 
 	// Expand workspace path (handle ~) - allow empty during wizard setup
 	if cfg.WorkspacePath != "" && cfg.WorkspacePath[0] == '~' {
@@ -798,13 +1422,25 @@ func ConfigExists() bool {
 	return err == nil
 }
 
-// EnsureExists checks if config exists, and offers to create one interactively if not.
+// CreateMode controls what EnsureExists does when there is no config file.
+type CreateMode int
+
+const (
+	// CreatePrompt asks on stdin before creating the default config.
+	CreatePrompt CreateMode = iota
+	// CreateAuto creates the default config without asking (--yes, SCHMUX_NONINTERACTIVE).
+	CreateAuto
+	// CreateNever fails with ErrConfigNotFound instead of asking (--no-input).
+	CreateNever
+)
+
+// EnsureExists checks if config exists, and creates one if not according to mode.
 // Returns true if config exists or was created, false if user declined or error occurred.
 //
 // Note: There is a TOCTOU race between ConfigExists() and Save(). If another process
 // creates the config file between the check and save, this will overwrite it.
-// This is acceptable for an interactive first-run flow where racing is unlikely.
-func EnsureExists() (bool, error) {
+// This is acceptable for a first-run flow where racing is unlikely.
+func EnsureExists(mode CreateMode) (bool, error) {
 	if ConfigExists() {
 		return true, nil
 	}
@@ -814,22 +1450,32 @@ func EnsureExists() (bool, error) {
 		return false, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	fmt.Println("Welcome to schmux!")
-	fmt.Println()
-	fmt.Println("No config file found at ~/.schmux/config.json")
-	fmt.Println()
-	fmt.Print("Would you like to create one now? [Y/n] ")
-
-	reader := bufio.NewReader(os.Stdin)
-	response, err := reader.ReadString('\n')
-	if err != nil {
-		return false, fmt.Errorf("failed to read response: %w", err)
-	}
+	switch mode {
+	case CreateNever:
+		return false, fmt.Errorf("%w: ~/.schmux/config.json (rerun with --yes to create the default config)", ErrConfigNotFound)
+	case CreatePrompt:
+		fmt.Println("Welcome to schmux!")
+		fmt.Println()
+		fmt.Println("No config file found at ~/.schmux/config.json")
+		fmt.Println()
+		fmt.Print("Would you like to create one now? [Y/n] ")
+
+		reader := bufio.NewReader(os.Stdin)
+		response, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) && strings.TrimSpace(response) == "" {
+			// No one to answer, e.g. under systemd or in a container.
+			fmt.Println()
+			return false, fmt.Errorf("%w: ~/.schmux/config.json and no input to confirm creating one (rerun with --yes or set SCHMUX_NONINTERACTIVE=1)", ErrConfigNotFound)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, fmt.Errorf("failed to read response: %w", err)
+		}
 
-	response = strings.TrimSpace(strings.ToLower(response))
-	if response == "n" || response == "no" {
-		fmt.Println("Config not created. Please create ~/.schmux/config.json manually to continue.")
-		return false, nil
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response == "n" || response == "no" {
+			fmt.Println("Config not created. Please create ~/.schmux/config.json manually to continue.")
+			return false, nil
+		}
 	}
 
 	// Create default config with the config path set
@@ -864,6 +1510,14 @@ func (c *Config) GetDashboardPollIntervalMs() int {
 	return c.Sessions.DashboardPollIntervalMs
 }
 
+// GetDashboardPingIntervalMs returns the dashboard websocket ping interval in ms. Defaults to 25000ms.
+func (c *Config) GetDashboardPingIntervalMs() int {
+	if c.Sessions == nil || c.Sessions.DashboardPingIntervalMs <= 0 {
+		return 25000
+	}
+	return c.Sessions.DashboardPingIntervalMs
+}
+
 // GetNudgenikViewedBufferMs returns the viewed timestamp buffer in ms. Defaults to 5000ms.
 func (c *Config) GetNudgenikViewedBufferMs() int {
 	if c.Nudgenik == nil || c.Nudgenik.ViewedBufferMs <= 0 {
@@ -872,6 +1526,22 @@ func (c *Config) GetNudgenikViewedBufferMs() int {
 	return c.Nudgenik.ViewedBufferMs
 }
 
+// GetNudgenikMaxConcurrent returns how many NudgeNik calls may run at once. Defaults to 2.
+func (c *Config) GetNudgenikMaxConcurrent() int {
+	if c.Nudgenik == nil || c.Nudgenik.MaxConcurrent <= 0 {
+		return 2
+	}
+	return c.Nudgenik.MaxConcurrent
+}
+
+// GetNudgenikTimeoutMs returns the timeout for a single NudgeNik call in ms. Defaults to 15000ms.
+func (c *Config) GetNudgenikTimeoutMs() int {
+	if c.Nudgenik == nil || c.Nudgenik.TimeoutMs <= 0 {
+		return 15000
+	}
+	return c.Nudgenik.TimeoutMs
+}
+
 // GetNudgenikSeenIntervalMs returns the interval for marking sessions as seen in ms. Defaults to 2000ms.
 func (c *Config) GetNudgenikSeenIntervalMs() int {
 	if c.Nudgenik == nil || c.Nudgenik.SeenIntervalMs <= 0 {
@@ -904,6 +1574,306 @@ func (c *Config) GetGitStatusWatchDebounceMs() int {
 	return c.Sessions.GitStatusWatchDebounceMs
 }
 
+// GetTmuxSocketName returns the tmux socket name for schmux sessions, or "" for the default server.
+func (c *Config) GetTmuxSocketName() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Sessions.TmuxSocketName)
+}
+
+// GetMaxPromptBytes returns the maximum spawn prompt size in bytes. Defaults to DefaultMaxPromptBytes.
+func (c *Config) GetMaxPromptBytes() int {
+	if c.Sessions == nil || c.Sessions.MaxPromptBytes <= 0 {
+		return DefaultMaxPromptBytes
+	}
+	return c.Sessions.MaxPromptBytes
+}
+
+// GetMaxWorkspacesPerRepo returns the maximum number of workspaces per repo. Defaults to DefaultMaxWorkspacesPerRepo.
+func (c *Config) GetMaxWorkspacesPerRepo() int {
+	if c.Sessions == nil || c.Sessions.MaxWorkspacesPerRepo <= 0 {
+		return DefaultMaxWorkspacesPerRepo
+	}
+	return c.Sessions.MaxWorkspacesPerRepo
+}
+
+// GetMaxSessionsPerWorkspace returns the maximum number of sessions per workspace, or 0 for unlimited.
+func (c *Config) GetMaxSessionsPerWorkspace() int {
+	if c.Sessions == nil || c.Sessions.MaxPerWorkspace <= 0 {
+		return 0
+	}
+	return c.Sessions.MaxPerWorkspace
+}
+
+// GetUnavailableTargetPolicy returns the spawn policy for unavailable targets. Defaults to "fail".
+func (c *Config) GetUnavailableTargetPolicy() string {
+	if c.Sessions == nil || c.Sessions.UnavailableTargetPolicy == "" {
+		return UnavailableTargetPolicyFail
+	}
+	return c.Sessions.UnavailableTargetPolicy
+}
+
+// GetBranchGonePolicy returns the policy for workspaces whose branch was deleted on
+// the remote. Defaults to "warn".
+func (c *Config) GetBranchGonePolicy() string {
+	if c.Sessions == nil || c.Sessions.BranchGonePolicy == "" {
+		return BranchGonePolicyWarn
+	}
+	return c.Sessions.BranchGonePolicy
+}
+
+// GetNicknameCollision returns the nickname collision policy. Defaults to "suffix".
+func (c *Config) GetNicknameCollision() string {
+	if c.Sessions == nil || c.Sessions.NicknameCollision == "" {
+		return NicknameCollisionSuffix
+	}
+	return c.Sessions.NicknameCollision
+}
+
+// GetAutoRestartTargets returns the targets whose sessions are auto-restarted.
+func (c *Config) GetAutoRestartTargets() []string {
+	if c.Sessions == nil {
+		return nil
+	}
+	return c.Sessions.AutoRestartTargets
+}
+
+// IsAutoRestartTarget reports whether sessions of the target are supervised for
+// auto-restart. It ignores the kill-switch, so sessions started while it is on can
+// still be restarted once it is turned off.
+func (c *Config) IsAutoRestartTarget(target string) bool {
+	return slices.Contains(c.GetAutoRestartTargets(), target)
+}
+
+// GetAutoRestartMaxRetries returns how many times a session is auto-restarted.
+// Defaults to DefaultAutoRestartMaxRetries.
+func (c *Config) GetAutoRestartMaxRetries() int {
+	if c.Sessions == nil || c.Sessions.AutoRestartMaxRetries <= 0 {
+		return DefaultAutoRestartMaxRetries
+	}
+	return c.Sessions.AutoRestartMaxRetries
+}
+
+// GetAutoRestartBackoffMs returns the delay before a session's first auto-restart.
+// Defaults to DefaultAutoRestartBackoffMs.
+func (c *Config) GetAutoRestartBackoffMs() int {
+	if c.Sessions == nil || c.Sessions.AutoRestartBackoffMs <= 0 {
+		return DefaultAutoRestartBackoffMs
+	}
+	return c.Sessions.AutoRestartBackoffMs
+}
+
+// GetAutoRestartDisabled reports whether the auto-restart kill-switch is on.
+func (c *Config) GetAutoRestartDisabled() bool {
+	return c.Sessions != nil && c.Sessions.AutoRestartDisabled
+}
+
+// GetFailedRemoteGraceMs returns how long a failed remote session is kept before it is
+// disposed. Defaults to DefaultFailedRemoteGraceMs.
+func (c *Config) GetFailedRemoteGraceMs() int {
+	if c.Sessions == nil || c.Sessions.FailedRemoteGraceMs <= 0 {
+		return DefaultFailedRemoteGraceMs
+	}
+	return c.Sessions.FailedRemoteGraceMs
+}
+
+// FailedRemoteGrace returns the failed remote session grace period as a time.Duration.
+func (c *Config) FailedRemoteGrace() time.Duration {
+	return time.Duration(c.GetFailedRemoteGraceMs()) * time.Millisecond
+}
+
+// GetProtectedBranches returns the configured protected branch patterns.
+func (c *Config) GetProtectedBranches() []string {
+	if c.Sessions == nil {
+		return nil
+	}
+	return c.Sessions.ProtectedBranches
+}
+
+// IsProtectedBranch reports whether branch matches any of sessions.protected_branches.
+// Patterns use path.Match syntax, so "*" does not cross a "/".
+func (c *Config) IsProtectedBranch(branch string) bool {
+	for _, pattern := range c.GetProtectedBranches() {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDisposeIgnoreGlobs returns the patterns excluded from the dispose safety check.
+func (c *Config) GetDisposeIgnoreGlobs() []string {
+	if c.Sessions == nil {
+		return nil
+	}
+	return c.Sessions.DisposeIgnoreGlobs
+}
+
+// GetWorkspaceEnvFile returns the workspace-relative env file loaded into spawned sessions.
+// Defaults to DefaultWorkspaceEnvFile.
+func (c *Config) GetWorkspaceEnvFile() string {
+	if c.Sessions == nil || strings.TrimSpace(c.Sessions.WorkspaceEnvFile) == "" {
+		return DefaultWorkspaceEnvFile
+	}
+	return strings.TrimSpace(c.Sessions.WorkspaceEnvFile)
+}
+
+// GetHistoryEnabled returns whether disposed sessions are recorded in history.jsonl. Defaults to false.
+func (c *Config) GetHistoryEnabled() bool {
+	if c.Sessions == nil {
+		return false
+	}
+	return c.Sessions.HistoryEnabled
+}
+
+// GetHistoryRecordPrompts returns whether spawn prompts are recorded in the history.
+// Always false when the history is disabled.
+func (c *Config) GetHistoryRecordPrompts() bool {
+	if c.Sessions == nil || !c.Sessions.HistoryEnabled {
+		return false
+	}
+	return c.Sessions.HistoryRecordPrompts
+}
+
+// GetPromptHistorySize returns how many spawn prompts each workspace keeps.
+// Defaults to 0, which turns the prompt history off.
+func (c *Config) GetPromptHistorySize() int {
+	if c.Sessions == nil {
+		return 0
+	}
+	return c.Sessions.PromptHistorySize
+}
+
+// GetOffline returns whether network git operations are skipped. Defaults to false.
+func (c *Config) GetOffline() bool {
+	if c.Sessions == nil {
+		return false
+	}
+	return c.Sessions.Offline
+}
+
+// IsDisposeIgnored reports whether a workspace-relative path matches sessions.dispose_ignore_globs.
+func (c *Config) IsDisposeIgnored(relPath string) bool {
+	for _, pattern := range c.GetDisposeIgnoreGlobs() {
+		if MatchPathGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPathGlob reports whether a workspace-relative, slash-separated path matches pattern.
+// A pattern without a "/" matches the file name at any depth, a pattern ending in "/**"
+// matches everything under that directory, and any other pattern matches the whole path.
+func MatchPathGlob(pattern, relPath string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/**"); ok {
+		if matched, _ := path.Match(dir, relPath); matched {
+			return true
+		}
+		for parent := path.Dir(relPath); parent != "."; parent = path.Dir(parent) {
+			if matched, _ := path.Match(dir, parent); matched {
+				return true
+			}
+		}
+		return false
+	}
+	target := relPath
+	if !strings.Contains(pattern, "/") {
+		target = path.Base(relPath)
+	}
+	matched, _ := path.Match(pattern, target)
+	return matched
+}
+
+// ValidatePathGlob reports a syntax error in a MatchPathGlob pattern.
+func ValidatePathGlob(pattern string) error {
+	_, err := path.Match(strings.TrimSuffix(pattern, "/**"), "")
+	return err
+}
+
+// GetSyncCommitTemplate returns the commit message template for linear sync to the
+// default branch, or "" to push the branch's commits unchanged.
+func (c *Config) GetSyncCommitTemplate() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Sessions.SyncCommitTemplate)
+}
+
+func validateSyncCommitTemplate(tmpl string) error {
+	for _, token := range syncCommitPlaceholderPattern.FindAllString(tmpl, -1) {
+		if !slices.Contains(SyncCommitTemplatePlaceholders, token) {
+			return fmt.Errorf("%w: sessions.sync_commit_template has unknown placeholder %s (allowed: %s)", ErrInvalidConfig, token, strings.Join(SyncCommitTemplatePlaceholders, ", "))
+		}
+	}
+	return nil
+}
+
+// GetNiceLevel returns sessions.nice_level, or 0 if unset.
+func (c *Config) GetNiceLevel() int {
+	if c.Sessions == nil {
+		return 0
+	}
+	return c.Sessions.NiceLevel
+}
+
+// GetSandboxWrapper returns sessions.sandbox_wrapper, or "" if unset.
+func (c *Config) GetSandboxWrapper() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Sessions.SandboxWrapper)
+}
+
+// GetIoniceClass returns sessions.ionice_class, or "" if unset.
+func (c *Config) GetIoniceClass() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return c.Sessions.IoniceClass
+}
+
+// GetGitAuthorName returns sessions.git_author_name, or "" if unset.
+func (c *Config) GetGitAuthorName() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Sessions.GitAuthorName)
+}
+
+// GetGitAuthorEmail returns sessions.git_author_email, or "" if unset.
+func (c *Config) GetGitAuthorEmail() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Sessions.GitAuthorEmail)
+}
+
+// GetGitSignCommits returns whether commits schmux makes are signed.
+func (c *Config) GetGitSignCommits() bool {
+	if c.Sessions == nil {
+		return false
+	}
+	return c.Sessions.GitSignCommits
+}
+
+// GetGitSigningKey returns sessions.git_signing_key, or "" to use git's user.signingkey.
+func (c *Config) GetGitSigningKey() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Sessions.GitSigningKey)
+}
+
+// GetGitHTTPProxy returns the proxy URL for git clone/fetch, or "" if unset.
+func (c *Config) GetGitHTTPProxy() string {
+	if c.Sessions == nil {
+		return ""
+	}
+	return strings.TrimSpace(c.Sessions.GitHTTPProxy)
+}
+
 // GitStatusWatchDebounce returns the git status watcher debounce interval as a time.Duration.
 func (c *Config) GitStatusWatchDebounce() time.Duration {
 	return time.Duration(c.GetGitStatusWatchDebounceMs()) * time.Millisecond
@@ -925,6 +1895,14 @@ func (c *Config) GetXtermRotatedLogSizeMB() int64 {
 	return int64(c.Xterm.RotatedLogSizeMB)
 }
 
+// GetXtermRetainAfterDispose returns whether session log files are kept after dispose. Defaults to false.
+func (c *Config) GetXtermRetainAfterDispose() bool {
+	if c.Xterm == nil {
+		return false
+	}
+	return c.Xterm.RetainAfterDispose
+}
+
 // GetGitCloneTimeoutMs returns the git clone timeout in ms. Defaults to 300000 (5 min).
 func (c *Config) GetGitCloneTimeoutMs() int {
 	if c.Sessions == nil || c.Sessions.GitCloneTimeoutMs <= 0 {
@@ -941,6 +1919,22 @@ func (c *Config) GetGitStatusTimeoutMs() int {
 	return c.Sessions.GitStatusTimeoutMs
 }
 
+// GetGitFetchTimeoutMs returns the git fetch timeout in ms. Defaults to 120000 (2 min).
+func (c *Config) GetGitFetchTimeoutMs() int {
+	if c.Sessions == nil || c.Sessions.GitFetchTimeoutMs <= 0 {
+		return DefaultGitFetchTimeoutMs
+	}
+	return c.Sessions.GitFetchTimeoutMs
+}
+
+// GetGitDiffTimeoutMs returns the git diff timeout in ms. Defaults to 60000 (1 min).
+func (c *Config) GetGitDiffTimeoutMs() int {
+	if c.Sessions == nil || c.Sessions.GitDiffTimeoutMs <= 0 {
+		return DefaultGitDiffTimeoutMs
+	}
+	return c.Sessions.GitDiffTimeoutMs
+}
+
 // GetXtermQueryTimeoutMs returns the xterm query timeout in ms. Defaults to 5000.
 func (c *Config) GetXtermQueryTimeoutMs() int {
 	if c.Xterm == nil || c.Xterm.QueryTimeoutMs <= 0 {
@@ -967,6 +1961,16 @@ func (c *Config) GitStatusTimeout() time.Duration {
 	return time.Duration(c.GetGitStatusTimeoutMs()) * time.Millisecond
 }
 
+// GitFetchTimeout returns the git fetch timeout as a time.Duration.
+func (c *Config) GitFetchTimeout() time.Duration {
+	return time.Duration(c.GetGitFetchTimeoutMs()) * time.Millisecond
+}
+
+// GitDiffTimeout returns the git diff timeout as a time.Duration.
+func (c *Config) GitDiffTimeout() time.Duration {
+	return time.Duration(c.GetGitDiffTimeoutMs()) * time.Millisecond
+}
+
 // XtermQueryTimeout returns the xterm query timeout as a time.Duration.
 func (c *Config) XtermQueryTimeout() time.Duration {
 	return time.Duration(c.GetXtermQueryTimeoutMs()) * time.Millisecond
@@ -977,29 +1981,160 @@ func (c *Config) XtermOperationTimeout() time.Duration {
 	return time.Duration(c.GetXtermOperationTimeoutMs()) * time.Millisecond
 }
 
-// GetBindAddress returns the address to bind the server to.
-// Defaults to "127.0.0.1" (localhost only).
+// GetBindAddress returns the primary address to bind the server to.
+// This is bind_address, else the first of bind_addresses, else "127.0.0.1" (localhost only).
 func (c *Config) GetBindAddress() string {
-	if c.Network == nil || c.Network.BindAddress == "" {
-		return "127.0.0.1"
+	if len(c.envBindAddresses) > 0 {
+		return c.envBindAddresses[0]
+	}
+	if c.Network != nil && c.Network.BindAddress != "" {
+		return c.Network.BindAddress
+	}
+	if addrs := c.GetBindAddresses(); len(addrs) > 0 {
+		return addrs[0]
+	}
+	return "127.0.0.1"
+}
+
+// GetBindAddresses returns every address the server listens on: bind_address followed by
+// bind_addresses, trimmed and de-duplicated. Defaults to ["127.0.0.1"].
+// SCHMUX_BIND replaces them all.
+func (c *Config) GetBindAddresses() []string {
+	if len(c.envBindAddresses) > 0 {
+		return c.envBindAddresses
+	}
+	if c.Network == nil {
+		return []string{"127.0.0.1"}
+	}
+	var addrs []string
+	seen := make(map[string]bool)
+	for _, addr := range append([]string{c.Network.BindAddress}, c.Network.BindAddresses...) {
+		addr = strings.TrimSpace(addr)
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		addrs = append(addrs, addr)
+	}
+	if len(addrs) == 0 {
+		return []string{"127.0.0.1"}
 	}
-	return c.Network.BindAddress
+	return addrs
+}
+
+// GetDashboardHost returns the host to use when reporting the dashboard URL.
+// Prefers "localhost" when the server listens on a loopback or wildcard address,
+// otherwise the primary bind address.
+func (c *Config) GetDashboardHost() string {
+	for _, addr := range c.GetBindAddresses() {
+		if addr == "localhost" {
+			return "localhost"
+		}
+		if ip := net.ParseIP(addr); ip != nil && (ip.IsLoopback() || ip.IsUnspecified()) {
+			return "localhost"
+		}
+	}
+	return c.GetBindAddress()
 }
 
 // GetNetworkAccess returns whether the dashboard should be accessible from the local network.
-// This is a convenience method that checks if bind_address is "0.0.0.0".
+// This is a convenience method that checks if the server binds to "0.0.0.0".
 func (c *Config) GetNetworkAccess() bool {
-	return c.GetBindAddress() == "0.0.0.0"
+	return slices.Contains(c.GetBindAddresses(), "0.0.0.0")
+}
+
+// GetWSAllowedOrigins returns the extra origins allowed to open WebSockets, trimmed and
+// without trailing slashes.
+func (c *Config) GetWSAllowedOrigins() []string {
+	if c.Network == nil {
+		return nil
+	}
+	var origins []string
+	for _, origin := range c.Network.WSAllowedOrigins {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
+}
+
+// validateWSAllowedOrigins checks that each WebSocket origin is a bare http(s) origin.
+func (c *Config) validateWSAllowedOrigins() error {
+	for _, origin := range c.GetWSAllowedOrigins() {
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+			parsed.Path != "" || parsed.RawQuery != "" || parsed.User != nil {
+			return fmt.Errorf("%w: network.ws_allowed_origins entry %q must be an origin like https://host[:port]", ErrInvalidConfig, origin)
+		}
+	}
+	return nil
+}
+
+// validateBindAddresses checks that bind addresses are IPs (or "localhost") and that a
+// wildcard address is not combined with others, since they would conflict on the same port.
+// A lone bind_address is left unchecked, as it always has been.
+func (c *Config) validateBindAddresses() error {
+	if c.Network == nil || len(c.Network.BindAddresses) == 0 {
+		return nil
+	}
+	return validateBindAddressList(c.GetBindAddresses())
+}
+
+func validateBindAddressList(addrs []string) error {
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if addr != "localhost" && ip == nil {
+			return fmt.Errorf("%w: network bind address %q must be an IP address or \"localhost\"", ErrInvalidConfig, addr)
+		}
+		if ip != nil && ip.IsUnspecified() && len(addrs) > 1 {
+			return fmt.Errorf("%w: network bind address %q already listens on all interfaces and cannot be combined with other addresses", ErrInvalidConfig, addr)
+		}
+	}
+	return nil
 }
 
-// GetPort returns the dashboard port. Defaults to 7337.
+// GetPort returns the dashboard port: SCHMUX_PORT, else network.port. Defaults to 7337.
 func (c *Config) GetPort() int {
+	if c.envPort > 0 {
+		return c.envPort
+	}
 	if c.Network == nil || c.Network.Port <= 0 {
 		return 7337
 	}
 	return c.Network.Port
 }
 
+// Environment variables that override network settings for ephemeral setups (containers,
+// CI, test harnesses) without editing config.json.
+const (
+	EnvPort = "SCHMUX_PORT" // dashboard port
+	EnvBind = "SCHMUX_BIND" // comma-separated bind addresses
+)
+
+// applyEnvOverrides reads SCHMUX_PORT and SCHMUX_BIND. Empty variables are ignored.
+func (c *Config) applyEnvOverrides() error {
+	if value := strings.TrimSpace(os.Getenv(EnvPort)); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("%w: %s must be a port number between 1 and 65535, got %q", ErrInvalidConfig, EnvPort, value)
+		}
+		c.envPort = port
+	}
+	if value := os.Getenv(EnvBind); strings.TrimSpace(value) != "" {
+		var addrs []string
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" && !slices.Contains(addrs, addr) {
+				addrs = append(addrs, addr)
+			}
+		}
+		if err := validateBindAddressList(addrs); err != nil {
+			return fmt.Errorf("%s: %w", EnvBind, err)
+		}
+		c.envBindAddresses = addrs
+	}
+	return nil
+}
+
 // GetPublicBaseURL returns the public base URL for the dashboard.
 func (c *Config) GetPublicBaseURL() string {
 	if c.Network == nil {
@@ -1048,6 +2183,19 @@ func (c *Config) GetAuthProvider() string {
 	return c.AccessControl.Provider
 }
 
+// GetAllowSelfUpdate returns whether the dashboard may trigger a self-update. Defaults to true.
+func (c *Config) GetAllowSelfUpdate() bool {
+	if c.AccessControl == nil || c.AccessControl.AllowSelfUpdate == nil {
+		return true
+	}
+	return *c.AccessControl.AllowSelfUpdate
+}
+
+// GetReadOnly returns whether the dashboard is in read-only (kiosk) mode.
+func (c *Config) GetReadOnly() bool {
+	return c.AccessControl != nil && c.AccessControl.ReadOnly
+}
+
 // GetAuthSessionTTLMinutes returns the session TTL in minutes.
 func (c *Config) GetAuthSessionTTLMinutes() int {
 	if c.AccessControl == nil || c.AccessControl.SessionTTLMinutes <= 0 {
@@ -1056,6 +2204,47 @@ func (c *Config) GetAuthSessionTTLMinutes() int {
 	return c.AccessControl.SessionTTLMinutes
 }
 
+// GetCommandAllowlist returns the access_control.command_allowlist patterns.
+func (c *Config) GetCommandAllowlist() []string {
+	if c.AccessControl == nil {
+		return nil
+	}
+	return c.AccessControl.CommandAllowlist
+}
+
+// IsCommandAllowed reports whether access_control.command_allowlist permits running
+// command. Every command is allowed when the list is empty.
+func (c *Config) IsCommandAllowed(command string) bool {
+	allowlist := c.GetCommandAllowlist()
+	if len(allowlist) == 0 {
+		return true
+	}
+	command = strings.TrimSpace(command)
+	for _, pattern := range allowlist {
+		if re, err := commandAllowlistRegexp(pattern); err == nil && re.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandAllowlistRegexp compiles an allowlist pattern anchored to the whole command.
+func commandAllowlistRegexp(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+func (c *Config) validateCommandAllowlist() error {
+	for _, pattern := range c.GetCommandAllowlist() {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("%w: access_control.command_allowlist entries must not be empty", ErrInvalidConfig)
+		}
+		if _, err := commandAllowlistRegexp(pattern); err != nil {
+			return fmt.Errorf("%w: access_control.command_allowlist entry %q is not a valid regular expression: %v", ErrInvalidConfig, pattern, err)
+		}
+	}
+	return nil
+}
+
 func (c *Config) validateAccessControl(strict bool) ([]string, error) {
 	if c.AccessControl == nil || !c.AccessControl.Enabled {
 		return nil, nil
@@ -2,10 +2,13 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sergeknystautas/schmux/internal/version"
 )
@@ -103,6 +106,409 @@ func TestGetRunTargets(t *testing.T) {
 	}
 }
 
+func TestValidateRunTargetShell(t *testing.T) {
+	valid := []RunTarget{{Name: "tool", Type: RunTargetTypeCommand, Command: "tool", Shell: "/bin/zsh"}}
+	if err := validateRunTargets(valid); err != nil {
+		t.Errorf("expected shell path to be valid, got %v", err)
+	}
+	invalid := []RunTarget{{Name: "tool", Type: RunTargetTypeCommand, Command: "tool", Shell: "bash -lc"}}
+	if err := validateRunTargets(invalid); err == nil {
+		t.Error("expected error for shell with arguments")
+	}
+}
+
+func TestValidateRunTargetTmuxOptions(t *testing.T) {
+	target := func(options map[string]string) []RunTarget {
+		return []RunTarget{{Name: "tool", Type: RunTargetTypeCommand, Command: "tool", TmuxOptions: options}}
+	}
+	if err := validateRunTargets(target(map[string]string{"history-limit": "100000", "mouse": "on", "status-left": "agent "})); err != nil {
+		t.Errorf("expected allowed options to validate, got %v", err)
+	}
+	for _, options := range []map[string]string{
+		{"default-command": "rm -rf ~"},
+		{"history-limit": "lots"},
+		{"status-left": "a\nb"},
+	} {
+		if err := validateRunTargets(target(options)); err == nil {
+			t.Errorf("expected %v to be rejected", options)
+		}
+	}
+}
+
+func TestValidateRunTargetPanes(t *testing.T) {
+	target := func(layout string, panes ...TargetPane) []RunTarget {
+		return []RunTarget{{Name: "tool", Type: RunTargetTypeCommand, Command: "tool", Panes: panes, Layout: layout}}
+	}
+	if err := validateRunTargets(target("main-vertical", TargetPane{Command: "tail -f log"}, TargetPane{Command: "htop", Split: PaneSplitHorizontal, Size: 30})); err != nil {
+		t.Errorf("expected valid panes to validate, got %v", err)
+	}
+	for name, targets := range map[string][]RunTarget{
+		"empty command":   target("", TargetPane{Command: " "}),
+		"bad split":       target("", TargetPane{Command: "top", Split: "diagonal"}),
+		"bad size":        target("", TargetPane{Command: "top", Size: 95}),
+		"bad layout":      target("spiral", TargetPane{Command: "top"}),
+		"layout no panes": target("tiled"),
+	} {
+		if err := validateRunTargets(targets); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("%s: expected ErrInvalidConfig, got %v", name, err)
+		}
+	}
+}
+
+func TestCommandAllowlist(t *testing.T) {
+	cfg := &Config{}
+	if !cfg.IsCommandAllowed("anything goes") {
+		t.Error("expected every command to be allowed without an allowlist")
+	}
+
+	cfg.AccessControl = &AccessControlConfig{CommandAllowlist: []string{"make (test|lint)", "npm run [a-z]+"}}
+	for command, want := range map[string]bool{
+		"make test":             true,
+		"  make lint ":          true,
+		"npm run build":         true,
+		"make test && rm -rf ~": false,
+		"echo hi; make test":    false,
+		"npm run build; curl x": false,
+		"make deploy":           false,
+	} {
+		if got := cfg.IsCommandAllowed(command); got != want {
+			t.Errorf("IsCommandAllowed(%q) = %v, want %v", command, got, want)
+		}
+	}
+
+	for _, allowlist := range [][]string{{"make ("}, {" "}} {
+		cfg.AccessControl.CommandAllowlist = allowlist
+		if err := cfg.validateCommandAllowlist(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("allowlist %q: expected ErrInvalidConfig, got %v", allowlist, err)
+		}
+	}
+}
+
+func TestValidateDefaultSpawn(t *testing.T) {
+	targets := []RunTarget{
+		{Name: "reviewer", Type: RunTargetTypePromptable, Command: "reviewer"},
+		{Name: "lint", Type: RunTargetTypeCommand, Command: "make lint"},
+	}
+	if err := validateDefaultSpawn(&DefaultSpawnConfig{Targets: map[string]int{"claude": 2, "reviewer": 1}}, targets); err != nil {
+		t.Errorf("expected valid default_spawn, got %v", err)
+	}
+	for _, spawn := range []map[string]int{
+		{"claude": 0},
+		{"missing": 1},
+		{"lint": 1},
+	} {
+		if err := validateDefaultSpawn(&DefaultSpawnConfig{Targets: spawn}, targets); err == nil {
+			t.Errorf("expected %v to be rejected", spawn)
+		}
+	}
+
+	cfg := &Config{DefaultSpawn: &DefaultSpawnConfig{Targets: map[string]int{}}}
+	if cfg.GetDefaultSpawn() != nil {
+		t.Error("expected empty targets to mean no default spawn")
+	}
+}
+
+func TestValidateDefaultModels(t *testing.T) {
+	if err := validateDefaultModels(map[string]string{"claude": "opus", "codex": "gpt-5.2-codex"}); err != nil {
+		t.Errorf("expected valid default_models, got %v", err)
+	}
+	for _, models := range []map[string]string{
+		{"claude": "missing"},
+		{"claude": "gpt-5.2-codex"},
+		{"reviewer": "opus"},
+	} {
+		if err := validateDefaultModels(models); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected %v to be rejected, got %v", models, err)
+		}
+	}
+}
+
+func TestMeetsConflictResolveConfidence(t *testing.T) {
+	cfg := &Config{}
+	if !cfg.MeetsConflictResolveConfidence(ConfidenceHigh) || cfg.MeetsConflictResolveConfidence(ConfidenceMedium) {
+		t.Error("default threshold should only accept high confidence")
+	}
+
+	cfg.ConflictResolve = &ConflictResolveConfig{MinConfidence: ConfidenceMedium}
+	for confidence, want := range map[string]bool{"high": true, "medium": true, "low": false, "": false, "unsure": false} {
+		if got := cfg.MeetsConflictResolveConfidence(confidence); got != want {
+			t.Errorf("MeetsConflictResolveConfidence(%q) = %v, want %v", confidence, got, want)
+		}
+	}
+
+	full := CreateDefault("")
+	full.ConflictResolve = &ConflictResolveConfig{MinConfidence: "certain"}
+	if err := full.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("min_confidence \"certain\": got %v, want ErrInvalidConfig", err)
+	}
+}
+
+func TestBranchSuggestEnabledForRepo(t *testing.T) {
+	on, off := true, false
+	cfg := &Config{Repos: []Repo{
+		{Name: "strict", URL: "git@example.com:me/strict.git", BranchSuggest: &on},
+		{Name: "scratch", URL: "git@example.com:me/scratch.git", BranchSuggest: &off},
+		{Name: "plain", URL: "git@example.com:me/plain.git"},
+	}}
+	check := func(repoURL string, want bool) {
+		t.Helper()
+		if got := cfg.BranchSuggestEnabledForRepo(repoURL); got != want {
+			t.Errorf("BranchSuggestEnabledForRepo(%q) = %v, want %v", repoURL, got, want)
+		}
+	}
+
+	check("git@example.com:me/strict.git", true)
+	check("git@example.com:me/scratch.git", false)
+	check("git@example.com:me/plain.git", true)
+	check("local:new", true)
+
+	cfg.BranchSuggest = &BranchSuggestConfig{Target: "claude", DefaultEnabled: &off}
+	check("git@example.com:me/strict.git", true)
+	check("git@example.com:me/plain.git", false)
+	check("", false)
+}
+
+func TestBranchMatchesPrefix(t *testing.T) {
+	cfg := &Config{
+		Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Repos: []Repo{
+			{Name: "strict", URL: "git@example.com:me/strict.git", BranchPrefixRegex: "(feature|fix|chore)/"},
+			{Name: "plain", URL: "git@example.com:me/plain.git"},
+		},
+	}
+	for _, tc := range []struct {
+		repoURL, branch string
+		want            bool
+	}{
+		{"git@example.com:me/strict.git", "feature/dark-mode", true},
+		{"git@example.com:me/strict.git", "chore/deps", true},
+		{"git@example.com:me/strict.git", "dark-mode", false},
+		{"git@example.com:me/strict.git", "my-feature/dark-mode", false}, // anchored at the start
+		{"git@example.com:me/plain.git", "anything", true},
+		{"local:new", "anything", true},
+	} {
+		if got := cfg.BranchMatchesPrefix(tc.repoURL, tc.branch); got != tc.want {
+			t.Errorf("BranchMatchesPrefix(%q, %q) = %v, want %v", tc.repoURL, tc.branch, got, tc.want)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	cfg.Repos[0].BranchPrefixRegex = "(feature"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), "branch_prefix_regex") {
+		t.Errorf("Validate() with an invalid branch_prefix_regex = %v", err)
+	}
+}
+
+func TestValidateCloneFilter(t *testing.T) {
+	cfg := &Config{
+		Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Repos:    []Repo{{Name: "mono", URL: "git@example.com:me/mono.git"}},
+	}
+	for _, filter := range []string{"", "blob:none", "blob:limit=1m", "blob:limit=500", "tree:0"} {
+		cfg.Repos[0].CloneFilter = filter
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("clone_filter %q: %v", filter, err)
+		}
+	}
+	for _, filter := range []string{"blobs", "blob:limit=", "tree:deep", "--upload-pack=x"} {
+		cfg.Repos[0].CloneFilter = filter
+		if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("clone_filter %q: got %v, want ErrInvalidConfig", filter, err)
+		}
+	}
+	cfg.Repos[0].CloneFilter = " blob:none "
+	if got := cfg.GetRepoCloneFilter("git@example.com:me/mono.git"); got != "blob:none" {
+		t.Errorf("GetRepoCloneFilter() = %q", got)
+	}
+}
+
+func TestValidateWSAllowedOrigins(t *testing.T) {
+	cfg := &Config{
+		WorkspacePath: t.TempDir(),
+		Terminal:      &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Network:       &NetworkConfig{WSAllowedOrigins: []string{" https://proxy.example.com/ ", "http://10.0.0.5:8080"}},
+	}
+	if _, err := cfg.validate(true); err != nil {
+		t.Fatalf("expected valid origins, got %v", err)
+	}
+	if got := cfg.GetWSAllowedOrigins(); len(got) != 2 || got[0] != "https://proxy.example.com" {
+		t.Errorf("GetWSAllowedOrigins() = %v", got)
+	}
+	for _, origin := range []string{"proxy.example.com", "ftp://proxy.example.com", "https://proxy.example.com/app"} {
+		cfg.Network.WSAllowedOrigins = []string{origin}
+		if _, err := cfg.validate(true); err == nil {
+			t.Errorf("expected %q to be rejected", origin)
+		}
+	}
+}
+
+func TestGetWorkspaceEnvFile(t *testing.T) {
+	cfg := &Config{Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100}}
+	if got := cfg.GetWorkspaceEnvFile(); got != DefaultWorkspaceEnvFile {
+		t.Errorf("default = %q, want %q", got, DefaultWorkspaceEnvFile)
+	}
+	cfg.Sessions = &SessionsConfig{WorkspaceEnvFile: " config/dev.env "}
+	if got := cfg.GetWorkspaceEnvFile(); got != "config/dev.env" {
+		t.Errorf("GetWorkspaceEnvFile() = %q", got)
+	}
+	if _, err := cfg.validate(false); err != nil {
+		t.Errorf("expected relative path to be valid, got %v", err)
+	}
+	for _, envFile := range []string{"/etc/environment", "../shared.env"} {
+		cfg.Sessions.WorkspaceEnvFile = envFile
+		if _, err := cfg.validate(false); err == nil || !strings.Contains(err.Error(), "workspace_env_file") {
+			t.Errorf("expected %q to be rejected", envFile)
+		}
+	}
+}
+
+func TestGetUnavailableTargetPolicy(t *testing.T) {
+	if got := (&Config{}).GetUnavailableTargetPolicy(); got != UnavailableTargetPolicyFail {
+		t.Errorf("default policy = %q, want %q", got, UnavailableTargetPolicyFail)
+	}
+	cfg := &Config{Sessions: &SessionsConfig{UnavailableTargetPolicy: UnavailableTargetPolicyQueue}}
+	if got := cfg.GetUnavailableTargetPolicy(); got != UnavailableTargetPolicyQueue {
+		t.Errorf("policy = %q, want %q", got, UnavailableTargetPolicyQueue)
+	}
+	cfg.Terminal = &TerminalSize{Width: 120, Height: 40, SeedLines: 100}
+	if _, err := cfg.validate(false); err != nil {
+		t.Errorf("expected queue policy to be valid, got %v", err)
+	}
+	cfg.Sessions.UnavailableTargetPolicy = "wait"
+	if _, err := cfg.validate(false); err == nil || !strings.Contains(err.Error(), "unavailable_target_policy") {
+		t.Error("expected error for invalid unavailable_target_policy")
+	}
+}
+
+func TestIsProtectedBranch(t *testing.T) {
+	cfg := &Config{
+		Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Sessions: &SessionsConfig{ProtectedBranches: []string{"main", "release/*"}},
+	}
+	for branch, want := range map[string]bool{
+		"main":          true,
+		"release/1.0":   true,
+		"release/1/hot": false,
+		"feature/main":  false,
+		"mainline":      false,
+	} {
+		if got := cfg.IsProtectedBranch(branch); got != want {
+			t.Errorf("IsProtectedBranch(%q) = %v, want %v", branch, got, want)
+		}
+	}
+	if (&Config{}).IsProtectedBranch("main") {
+		t.Error("expected no protected branches by default")
+	}
+	cfg.Sessions.ProtectedBranches = []string{"release/["}
+	if _, err := cfg.validate(false); err == nil || !strings.Contains(err.Error(), "protected_branches") {
+		t.Error("expected error for malformed protected branch pattern")
+	}
+}
+
+func TestIsDisposeIgnored(t *testing.T) {
+	cfg := &Config{
+		Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Sessions: &SessionsConfig{DisposeIgnoreGlobs: []string{".env", "*.log", "docs/*.md", "tmp/**"}},
+	}
+	for relPath, want := range map[string]bool{
+		".env":             true,
+		"sub/.env":         true,
+		"build/out.log":    true,
+		"docs/notes.md":    true,
+		"docs/sub/deep.md": false,
+		"tmp/a/b/c.txt":    true,
+		"tmpfile":          false,
+		"main.go":          false,
+	} {
+		if got := cfg.IsDisposeIgnored(relPath); got != want {
+			t.Errorf("IsDisposeIgnored(%q) = %v, want %v", relPath, got, want)
+		}
+	}
+	if (&Config{}).IsDisposeIgnored(".env") {
+		t.Error("expected no ignored paths by default")
+	}
+	cfg.Sessions.DisposeIgnoreGlobs = []string{"[abc"}
+	if _, err := cfg.validate(false); err == nil || !strings.Contains(err.Error(), "dispose_ignore_globs") {
+		t.Error("expected error for malformed dispose ignore pattern")
+	}
+}
+
+func TestGitAuthorValidation(t *testing.T) {
+	cfg := &Config{
+		Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Sessions: &SessionsConfig{GitAuthorName: " Jane Doe ", GitAuthorEmail: "jane@example.com"},
+	}
+	if _, err := cfg.validate(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.GetGitAuthorName(); got != "Jane Doe" {
+		t.Errorf("GetGitAuthorName() = %q, want trimmed name", got)
+	}
+	cfg.Sessions.GitAuthorEmail = "not-an-email"
+	if _, err := cfg.validate(false); err == nil || !strings.Contains(err.Error(), "git_author_email") {
+		t.Errorf("expected git_author_email error, got %v", err)
+	}
+	cfg.Sessions.GitAuthorEmail = ""
+	cfg.Sessions.GitAuthorName = "Jane <jane@example.com>"
+	if _, err := cfg.validate(false); err == nil || !strings.Contains(err.Error(), "git_author_name") {
+		t.Errorf("expected git_author_name error, got %v", err)
+	}
+}
+
+func TestResourceLimitValidation(t *testing.T) {
+	for _, tt := range []struct {
+		sessions SessionsConfig
+		wantErr  string
+	}{
+		{sessions: SessionsConfig{NiceLevel: 10, IoniceClass: IoniceClassIdle}},
+		{sessions: SessionsConfig{NiceLevel: 20}, wantErr: "nice_level"},
+		{sessions: SessionsConfig{NiceLevel: -5}, wantErr: "nice_level"},
+		{sessions: SessionsConfig{IoniceClass: "realtime"}, wantErr: "ionice_class"},
+	} {
+		cfg := &Config{
+			Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+			Sessions: &tt.sessions,
+		}
+		_, err := cfg.validate(false)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%+v: unexpected error: %v", tt.sessions, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%+v: expected %s error, got %v", tt.sessions, tt.wantErr, err)
+		}
+	}
+}
+
+func TestValidateSyncCommitTemplate(t *testing.T) {
+	cfg := &Config{
+		Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Sessions: &SessionsConfig{SyncCommitTemplate: "feat({branch}): {count} commits to {default_branch}\n\n{subjects}"},
+	}
+	if _, err := cfg.validate(false); err != nil {
+		t.Errorf("expected valid template, got %v", err)
+	}
+	cfg.Sessions.SyncCommitTemplate = "feat({ticket}): sync"
+	if _, err := cfg.validate(false); err == nil || !strings.Contains(err.Error(), "{ticket}") {
+		t.Errorf("expected unknown placeholder error, got %v", err)
+	}
+}
+
+func TestValidateGitHTTPProxy(t *testing.T) {
+	cfg := &Config{
+		Terminal: &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Sessions: &SessionsConfig{GitHTTPProxy: "http://proxy.corp:3128"},
+	}
+	if _, err := cfg.validate(false); err != nil {
+		t.Errorf("expected valid proxy, got %v", err)
+	}
+	cfg.Sessions.GitHTTPProxy = "proxy.corp:3128"
+	if _, err := cfg.validate(false); err == nil {
+		t.Error("expected error for proxy without scheme")
+	}
+}
+
 func TestGetTerminalSize(t *testing.T) {
 	t.Run("returns configured size", func(t *testing.T) {
 		cfg := &Config{
@@ -151,6 +557,34 @@ func TestGetTerminalSeedLines(t *testing.T) {
 	})
 }
 
+func TestGetTerminalBootstrapMaxBytes(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GetTerminalBootstrapMaxBytes(); got != DefaultBootstrapMaxKB*1024 {
+		t.Errorf("default: got %d, want %d", got, DefaultBootstrapMaxKB*1024)
+	}
+	cfg.Terminal = &TerminalSize{BootstrapMaxKB: 64}
+	if got := cfg.GetTerminalBootstrapMaxBytes(); got != 64*1024 {
+		t.Errorf("configured: got %d, want %d", got, 64*1024)
+	}
+}
+
+func TestGitOperationTimeouts(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.GitFetchTimeout(); got != DefaultGitFetchTimeoutMs*time.Millisecond {
+		t.Errorf("default fetch timeout = %v", got)
+	}
+	if got := cfg.GitDiffTimeout(); got != DefaultGitDiffTimeoutMs*time.Millisecond {
+		t.Errorf("default diff timeout = %v", got)
+	}
+	cfg.Sessions = &SessionsConfig{GitFetchTimeoutMs: 5000, GitDiffTimeoutMs: 600000}
+	if got := cfg.GitFetchTimeout(); got != 5*time.Second {
+		t.Errorf("configured fetch timeout = %v, want 5s", got)
+	}
+	if got := cfg.GitDiffTimeout(); got != 10*time.Minute {
+		t.Errorf("configured diff timeout = %v, want 10m", got)
+	}
+}
+
 func TestCreateDefault(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "test-config.json")
@@ -253,6 +687,244 @@ func TestGetXtermMtimePollIntervalMs(t *testing.T) {
 	})
 }
 
+func TestGetNotificationSounds(t *testing.T) {
+	t.Run("defaults play only attention states", func(t *testing.T) {
+		sounds := (&Config{}).GetNotificationSounds()
+		if !sounds["needs_input"].Enabled || !sounds["error"].Enabled {
+			t.Errorf("expected needs_input and error enabled by default, got %+v", sounds)
+		}
+		if sounds["working"].Enabled || sounds["completed"].Enabled {
+			t.Errorf("expected working and completed disabled by default, got %+v", sounds)
+		}
+	})
+
+	t.Run("configured states override defaults", func(t *testing.T) {
+		cfg := &Config{Notifications: &NotificationsConfig{Sounds: map[string]NotificationSound{
+			"completed":   {Enabled: true, Sound: NotificationSoundChime},
+			"needs_input": {Enabled: false},
+		}}}
+		sounds := cfg.GetNotificationSounds()
+		if got := sounds["completed"]; !got.Enabled || got.Sound != NotificationSoundChime {
+			t.Errorf("completed = %+v, want enabled chime", got)
+		}
+		if got := sounds["needs_input"]; got.Enabled || got.Sound != NotificationSoundAttention {
+			t.Errorf("needs_input = %+v, want disabled attention", got)
+		}
+		if !sounds["error"].Enabled {
+			t.Error("expected unconfigured error state to keep its default")
+		}
+	})
+}
+
+func TestValidateNotificationSounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		sounds  map[string]NotificationSound
+		wantErr bool
+	}{
+		{name: "nil", sounds: nil},
+		{name: "valid", sounds: map[string]NotificationSound{"completed": {Enabled: true, Sound: "chime"}}},
+		{name: "empty sound name", sounds: map[string]NotificationSound{"error": {Enabled: true}}},
+		{name: "unknown state", sounds: map[string]NotificationSound{"Completed": {Enabled: true}}, wantErr: true},
+		{name: "unknown sound", sounds: map[string]NotificationSound{"error": {Enabled: true, Sound: "klaxon"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateNotificationSounds(tt.sounds)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateNotificationSounds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTmuxSocketNameValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		socket  string
+		wantErr bool
+	}{
+		{name: "unset", socket: ""},
+		{name: "simple", socket: "schmux"},
+		{name: "dotted", socket: "schmux.dev_2"},
+		{name: "space", socket: "my tmux", wantErr: true},
+		{name: "path", socket: "../tmux", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Terminal: &TerminalSize{Width: 80, Height: 24, SeedLines: 100},
+				Sessions: &SessionsConfig{TmuxSocketName: tt.socket},
+			}
+			_, err := cfg.validate(false)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && cfg.GetTmuxSocketName() != tt.socket {
+				t.Errorf("GetTmuxSocketName() = %q, want %q", cfg.GetTmuxSocketName(), tt.socket)
+			}
+		})
+	}
+}
+
+func TestBindAddresses(t *testing.T) {
+	tests := []struct {
+		name        string
+		network     *NetworkConfig
+		wantAddrs   []string
+		wantPrimary string
+		wantHost    string
+		wantErr     bool
+	}{
+		{name: "default", network: nil, wantAddrs: []string{"127.0.0.1"}, wantPrimary: "127.0.0.1", wantHost: "localhost"},
+		{name: "single bind_address", network: &NetworkConfig{BindAddress: "0.0.0.0"}, wantAddrs: []string{"0.0.0.0"}, wantPrimary: "0.0.0.0", wantHost: "localhost"},
+		{
+			name:        "list with loopback",
+			network:     &NetworkConfig{BindAddresses: []string{"10.8.0.2", " 127.0.0.1 ", "10.8.0.2"}},
+			wantAddrs:   []string{"10.8.0.2", "127.0.0.1"},
+			wantPrimary: "10.8.0.2",
+			wantHost:    "localhost",
+		},
+		{
+			name:        "bind_address first",
+			network:     &NetworkConfig{BindAddress: "10.8.0.2", BindAddresses: []string{"::1"}},
+			wantAddrs:   []string{"10.8.0.2", "::1"},
+			wantPrimary: "10.8.0.2",
+			wantHost:    "localhost",
+		},
+		{
+			name:        "no loopback",
+			network:     &NetworkConfig{BindAddresses: []string{"10.8.0.2"}},
+			wantAddrs:   []string{"10.8.0.2"},
+			wantPrimary: "10.8.0.2",
+			wantHost:    "10.8.0.2",
+		},
+		{name: "hostname in list", network: &NetworkConfig{BindAddresses: []string{"vpn.example.com"}}, wantErr: true},
+		{name: "wildcard combined", network: &NetworkConfig{BindAddress: "0.0.0.0", BindAddresses: []string{"127.0.0.1"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Terminal: &TerminalSize{Width: 80, Height: 24, SeedLines: 100},
+				Network:  tt.network,
+			}
+			_, err := cfg.validate(false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := cfg.GetBindAddresses(); !slices.Equal(got, tt.wantAddrs) {
+				t.Errorf("GetBindAddresses() = %v, want %v", got, tt.wantAddrs)
+			}
+			if got := cfg.GetBindAddress(); got != tt.wantPrimary {
+				t.Errorf("GetBindAddress() = %q, want %q", got, tt.wantPrimary)
+			}
+			if got := cfg.GetDashboardHost(); got != tt.wantHost {
+				t.Errorf("GetDashboardHost() = %q, want %q", got, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	fileConfig := Config{
+		WorkspacePath: tmpDir,
+		Terminal:      &TerminalSize{Width: 120, Height: 40, SeedLines: 100},
+		Network:       &NetworkConfig{Port: 8000, BindAddress: "127.0.0.1"},
+	}
+	data, err := json.Marshal(fileConfig)
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	t.Run("unset uses config file", func(t *testing.T) {
+		t.Setenv(EnvPort, "")
+		t.Setenv(EnvBind, "")
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.GetPort() != 8000 {
+			t.Errorf("GetPort() = %d, want 8000", cfg.GetPort())
+		}
+		if got := cfg.GetBindAddresses(); !slices.Equal(got, []string{"127.0.0.1"}) {
+			t.Errorf("GetBindAddresses() = %v, want [127.0.0.1]", got)
+		}
+	})
+
+	t.Run("env takes precedence and is not saved", func(t *testing.T) {
+		t.Setenv(EnvPort, "9001")
+		t.Setenv(EnvBind, "10.8.0.2, 127.0.0.1")
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if cfg.GetPort() != 9001 {
+			t.Errorf("GetPort() = %d, want 9001", cfg.GetPort())
+		}
+		if got := cfg.GetBindAddresses(); !slices.Equal(got, []string{"10.8.0.2", "127.0.0.1"}) {
+			t.Errorf("GetBindAddresses() = %v, want [10.8.0.2 127.0.0.1]", got)
+		}
+		if cfg.GetBindAddress() != "10.8.0.2" {
+			t.Errorf("GetBindAddress() = %q, want 10.8.0.2", cfg.GetBindAddress())
+		}
+		if err := cfg.Save(); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+		var saved Config
+		data, _ := os.ReadFile(configPath)
+		if err := json.Unmarshal(data, &saved); err != nil {
+			t.Fatalf("failed to read saved config: %v", err)
+		}
+		if saved.Network == nil || saved.Network.Port != 8000 || saved.Network.BindAddress != "127.0.0.1" {
+			t.Errorf("saved network = %+v, want the file values", saved.Network)
+		}
+	})
+
+	t.Run("env survives reload", func(t *testing.T) {
+		t.Setenv(EnvPort, "9001")
+		t.Setenv(EnvBind, "10.8.0.2")
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if err := cfg.Reload(); err != nil {
+			t.Fatalf("Reload() failed: %v", err)
+		}
+		if cfg.GetPort() != 9001 {
+			t.Errorf("GetPort() after Reload = %d, want 9001", cfg.GetPort())
+		}
+		if got := cfg.GetBindAddresses(); !slices.Equal(got, []string{"10.8.0.2"}) {
+			t.Errorf("GetBindAddresses() after Reload = %v, want [10.8.0.2]", got)
+		}
+	})
+
+	invalid := []struct {
+		name, port, bind string
+	}{
+		{name: "non-numeric port", port: "http"},
+		{name: "port out of range", port: "70000"},
+		{name: "hostname bind", bind: "vpn.example.com"},
+		{name: "wildcard combined", bind: "0.0.0.0,127.0.0.1"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvPort, tt.port)
+			t.Setenv(EnvBind, tt.bind)
+			if _, err := Load(configPath); !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Load() error = %v, want ErrInvalidConfig", err)
+			}
+		})
+	}
+}
+
 func TestGetDashboardPollIntervalMs(t *testing.T) {
 	t.Run("returns configured value", func(t *testing.T) {
 		cfg := &Config{
@@ -1350,3 +2022,29 @@ func TestValidateCommandTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureExistsNonInteractive(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".schmux", "config.json")
+
+	ok, err := EnsureExists(CreateNever)
+	if ok || !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("CreateNever: EnsureExists() = %v, %v; want false, ErrConfigNotFound", ok, err)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatalf("CreateNever wrote a config: %v", err)
+	}
+
+	ok, err = EnsureExists(CreateAuto)
+	if !ok || err != nil {
+		t.Fatalf("CreateAuto: EnsureExists() = %v, %v; want true, nil", ok, err)
+	}
+	if _, err := Load(configPath); err != nil {
+		t.Fatalf("CreateAuto wrote a config that doesn't load: %v", err)
+	}
+
+	if ok, err := EnsureExists(CreateNever); !ok || err != nil {
+		t.Errorf("existing config: EnsureExists(CreateNever) = %v, %v; want true, nil", ok, err)
+	}
+}
@@ -2,11 +2,85 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/sergeknystautas/schmux/internal/detect"
 )
 
+// AllowedTmuxOptions lists the tmux session options a run target may set via tmux_options.
+// Options that run commands or change how schmux talks to tmux are deliberately excluded.
+var AllowedTmuxOptions = map[string]bool{
+	"history-limit":                true,
+	"mouse":                        true,
+	"status":                       true,
+	"status-interval":              true,
+	"status-justify":               true,
+	"status-left":                  true,
+	"status-left-length":           true,
+	"status-left-style":            true,
+	"status-position":              true,
+	"status-right":                 true,
+	"status-right-length":          true,
+	"status-right-style":           true,
+	"status-style":                 true,
+	"window-status-format":         true,
+	"window-status-current-format": true,
+	"set-titles":                   true,
+	"set-titles-string":            true,
+	"visual-activity":              true,
+	"visual-bell":                  true,
+}
+
+func validateTmuxOptions(name string, options map[string]string) error {
+	for option, value := range options {
+		if !AllowedTmuxOptions[option] {
+			return fmt.Errorf("%w: run target %s tmux_options: %q is not an allowed tmux option", ErrInvalidConfig, name, option)
+		}
+		if strings.ContainsAny(value, "\n\r") {
+			return fmt.Errorf("%w: run target %s tmux_options: %s must be a single line", ErrInvalidConfig, name, option)
+		}
+		if option == "history-limit" {
+			if n, err := strconv.Atoi(value); err != nil || n < 0 {
+				return fmt.Errorf("%w: run target %s tmux_options: history-limit must be a non-negative integer, got %q", ErrInvalidConfig, name, value)
+			}
+		}
+	}
+	return nil
+}
+
+// paneLayouts are the tmux preset layouts a run target may use.
+var paneLayouts = map[string]bool{
+	"even-horizontal": true,
+	"even-vertical":   true,
+	"main-horizontal": true,
+	"main-vertical":   true,
+	"tiled":           true,
+}
+
+func validateTargetPanes(name string, target RunTarget) error {
+	for i, pane := range target.Panes {
+		if strings.TrimSpace(pane.Command) == "" {
+			return fmt.Errorf("%w: run target %s panes[%d]: command is required", ErrInvalidConfig, name, i)
+		}
+		if pane.Split != "" && pane.Split != PaneSplitVertical && pane.Split != PaneSplitHorizontal {
+			return fmt.Errorf("%w: run target %s panes[%d]: split must be %q or %q, got %q", ErrInvalidConfig, name, i, PaneSplitVertical, PaneSplitHorizontal, pane.Split)
+		}
+		if pane.Size < 0 || pane.Size > 90 {
+			return fmt.Errorf("%w: run target %s panes[%d]: size must be a percentage between 1 and 90", ErrInvalidConfig, name, i)
+		}
+	}
+	if target.Layout != "" {
+		if len(target.Panes) == 0 {
+			return fmt.Errorf("%w: run target %s layout requires panes", ErrInvalidConfig, name)
+		}
+		if !paneLayouts[target.Layout] {
+			return fmt.Errorf("%w: run target %s has invalid layout %q", ErrInvalidConfig, name, target.Layout)
+		}
+	}
+	return nil
+}
+
 func validateRunTargets(targets []RunTarget) error {
 	seen := make(map[string]struct{})
 	for _, target := range targets {
@@ -20,6 +94,15 @@ func validateRunTargets(targets []RunTarget) error {
 		if target.Type != RunTargetTypePromptable && target.Type != RunTargetTypeCommand {
 			return fmt.Errorf("%w: run target %s has invalid type %q", ErrInvalidConfig, name, target.Type)
 		}
+		if strings.ContainsAny(target.Shell, " \t\n") {
+			return fmt.Errorf("%w: run target %s shell must be a program name or path, got %q", ErrInvalidConfig, name, target.Shell)
+		}
+		if err := validateTmuxOptions(name, target.TmuxOptions); err != nil {
+			return err
+		}
+		if err := validateTargetPanes(name, target); err != nil {
+			return err
+		}
 		source := target.Source
 		if source == "" {
 			source = RunTargetSourceUser
@@ -119,6 +202,50 @@ func validateQuickLaunch(presets []QuickLaunch, targets []RunTarget) error {
 	return nil
 }
 
+func validateDefaultSpawn(defaultSpawn *DefaultSpawnConfig, targets []RunTarget) error {
+	if defaultSpawn == nil {
+		return nil
+	}
+	for targetName, count := range defaultSpawn.Targets {
+		if count <= 0 {
+			return fmt.Errorf("%w: default_spawn quantity for %s must be > 0", ErrInvalidConfig, targetName)
+		}
+		promptable, ok := quickLaunchTargetPromptable(targetName, targets)
+		if !ok {
+			return fmt.Errorf("%w: default_spawn target not found: %s", ErrInvalidConfig, targetName)
+		}
+		if !promptable {
+			return fmt.Errorf("%w: default_spawn target %s must be promptable", ErrInvalidConfig, targetName)
+		}
+	}
+	return nil
+}
+
+func validateDefaultModels(defaultModels map[string]string) error {
+	for tool, modelID := range defaultModels {
+		if !detect.IsBuiltinToolName(tool) {
+			return fmt.Errorf("%w: default_models: %s is not a detected tool", ErrInvalidConfig, tool)
+		}
+		model, ok := detect.FindModel(modelID)
+		if !ok {
+			return fmt.Errorf("%w: default_models: model not found for %s: %s", ErrInvalidConfig, tool, modelID)
+		}
+		if model.BaseTool != tool {
+			return fmt.Errorf("%w: default_models: model %s runs on %s, not %s", ErrInvalidConfig, model.ID, model.BaseTool, tool)
+		}
+	}
+	return nil
+}
+
+// GetDefaultModel returns the model a bare detected tool target runs, or "" to run
+// the tool with its own default.
+func (c *Config) GetDefaultModel(tool string) string {
+	if c == nil {
+		return ""
+	}
+	return c.DefaultModels[tool]
+}
+
 func validateNudgenikConfig(nudgenik *NudgenikConfig, targets []RunTarget) error {
 	if nudgenik == nil {
 		return nil
@@ -162,6 +289,13 @@ func validateRunTargetDependencies(targets []RunTarget, quickLaunch []QuickLaunc
 	return nil
 }
 
+// IsPromptableTarget reports whether name is a detected tool, a model, or a
+// promptable run target.
+func (c *Config) IsPromptableTarget(name string) bool {
+	promptable, ok := quickLaunchTargetPromptable(name, c.RunTargets)
+	return ok && promptable
+}
+
 func quickLaunchTargetPromptable(targetName string, targets []RunTarget) (bool, bool) {
 	if detect.IsModelID(targetName) {
 		return true, true
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/sergeknystautas/schmux/internal/detect"
+)
+
+// Secret storage backends for secrets_backend.
+const (
+	SecretsBackendFile     = "file"     // default: ~/.schmux/secrets.json
+	SecretsBackendKeychain = "keychain" // macOS Keychain or the Linux Secret Service
+	SecretsBackendEnv      = "env"      // read-only, from the daemon's environment
+)
+
+// ErrSecretsReadOnly is returned when saving secrets to a backend that can't store them.
+var ErrSecretsReadOnly = errors.New("secrets are read from the environment and can't be saved; set them as environment variables instead")
+
+// SecretStore loads and saves the secrets document that model and auth secrets are
+// read from and written to.
+type SecretStore interface {
+	Load() (*SecretsFile, error)
+	Save(secrets *SecretsFile) error
+}
+
+var (
+	secretStoreMu sync.RWMutex
+	secretStore   SecretStore = fileSecretStore{}
+)
+
+// SetSecretStore routes every secret read and write in the process through store.
+// The daemon and CLI set it from secrets_backend right after loading the config.
+func SetSecretStore(store SecretStore) {
+	secretStoreMu.Lock()
+	defer secretStoreMu.Unlock()
+	secretStore = store
+}
+
+func currentSecretStore() SecretStore {
+	secretStoreMu.RLock()
+	defer secretStoreMu.RUnlock()
+	return secretStore
+}
+
+// NewSecretStore returns the store for a secrets_backend value. Empty or unknown
+// values get the file store.
+func NewSecretStore(backend string) SecretStore {
+	switch backend {
+	case SecretsBackendKeychain:
+		return keychainSecretStore{}
+	case SecretsBackendEnv:
+		return envSecretStore{}
+	default:
+		return fileSecretStore{}
+	}
+}
+
+const (
+	keychainService = "schmux"
+	keychainAccount = "secrets"
+)
+
+// keychainSecretStore keeps the secrets document in one OS keychain item: the login
+// keychain via security(1) on macOS, the Secret Service via secret-tool(1) on Linux.
+// The item holds the document base64-encoded, so neither tool has to cope with
+// quotes or newlines in it.
+type keychainSecretStore struct{}
+
+func (keychainSecretStore) Load() (*SecretsFile, error) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount, "-w")
+	case "linux":
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService, "account", keychainAccount)
+	default:
+		return nil, fmt.Errorf("keychain secrets are not supported on %s", runtime.GOOS)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// A missing item is the only failure that means "no secrets yet". Anything
+		// else, like a locked keychain, must not read as empty: the next save would
+		// overwrite the real secrets.
+		if keychainItemNotFound(err, stderr.String()) {
+			return &SecretsFile{Models: ModelSecrets{}}, nil
+		}
+		return nil, fmt.Errorf("failed to read secrets from keychain: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	encoded := strings.TrimSpace(string(out))
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode secrets from keychain: %w", err)
+	}
+	var secrets SecretsFile
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets from keychain: %w", err)
+	}
+	if secrets.Models == nil {
+		secrets.Models = ModelSecrets{}
+	}
+	return &secrets, nil
+}
+
+// keychainItemNotFound reports whether a failed lookup failed only because the item
+// doesn't exist: security exits 44, and secret-tool exits 1 without saying anything.
+func keychainItemNotFound(err error, stderr string) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return exitErr.ExitCode() == 44
+	case "linux":
+		return exitErr.ExitCode() == 1 && strings.TrimSpace(stderr) == ""
+	}
+	return false
+}
+
+func (keychainSecretStore) Save(secrets *SecretsFile) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	// The secret goes over stdin, never on a command line where ps could show it
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", keychainService, keychainAccount, encoded))
+	case "linux":
+		cmd = exec.Command("secret-tool", "store", "--label=schmux secrets", "service", keychainService, "account", keychainAccount)
+		cmd.Stdin = strings.NewReader(encoded)
+	default:
+		return fmt.Errorf("keychain secrets are not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to write secrets to keychain: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Environment variables the env store reads auth secrets from.
+const (
+	EnvGitHubClientID     = "SCHMUX_GITHUB_CLIENT_ID"
+	EnvGitHubClientSecret = "SCHMUX_GITHUB_CLIENT_SECRET"
+	EnvSessionSecret      = "SCHMUX_SESSION_SECRET"
+)
+
+var envNameUnsafeChars = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// ModelSecretEnvVar returns the environment variable the env store reads a model's
+// secret from: SCHMUX_SECRET_<MODEL>_<NAME>, with the model ID upper-cased and
+// anything but letters and digits turned into '_'. For example kimi-thinking's
+// ANTHROPIC_AUTH_TOKEN is SCHMUX_SECRET_KIMI_THINKING_ANTHROPIC_AUTH_TOKEN.
+func ModelSecretEnvVar(modelID, name string) string {
+	model := envNameUnsafeChars.ReplaceAllString(strings.ToUpper(modelID), "_")
+	return "SCHMUX_SECRET_" + strings.Trim(model, "_") + "_" + name
+}
+
+// envSecretStore reads secrets from the daemon's environment and can't save them.
+type envSecretStore struct{}
+
+func (envSecretStore) Load() (*SecretsFile, error) {
+	secrets := &SecretsFile{Models: ModelSecrets{}}
+	for _, model := range detect.GetBuiltinModels() {
+		for _, name := range model.RequiredSecrets {
+			value, ok := os.LookupEnv(ModelSecretEnvVar(model.ID, name))
+			if !ok {
+				continue
+			}
+			if secrets.Models[model.ID] == nil {
+				secrets.Models[model.ID] = map[string]string{}
+			}
+			secrets.Models[model.ID][name] = value
+		}
+	}
+
+	clientID, clientSecret := os.Getenv(EnvGitHubClientID), os.Getenv(EnvGitHubClientSecret)
+	if clientID != "" || clientSecret != "" {
+		secrets.Auth.GitHub = &GitHubSecrets{ClientID: clientID, ClientSecret: clientSecret}
+	}
+	secrets.Auth.SessionSecret = os.Getenv(EnvSessionSecret)
+	return secrets, nil
+}
+
+func (envSecretStore) Save(*SecretsFile) error {
+	return ErrSecretsReadOnly
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/detect"
+)
+
+func TestModelSecretEnvVar(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"kimi-thinking", "SCHMUX_SECRET_KIMI_THINKING_ANTHROPIC_AUTH_TOKEN"},
+		{"glm-4.7", "SCHMUX_SECRET_GLM_4_7_ANTHROPIC_AUTH_TOKEN"},
+		{"minimax", "SCHMUX_SECRET_MINIMAX_ANTHROPIC_AUTH_TOKEN"},
+	}
+	for _, tt := range tests {
+		if got := ModelSecretEnvVar(tt.model, "ANTHROPIC_AUTH_TOKEN"); got != tt.want {
+			t.Errorf("ModelSecretEnvVar(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestEnvSecretStore(t *testing.T) {
+	SetSecretStore(NewSecretStore(SecretsBackendEnv))
+	defer SetSecretStore(fileSecretStore{})
+
+	t.Setenv(ModelSecretEnvVar("kimi-thinking", "ANTHROPIC_AUTH_TOKEN"), "sk-kimi")
+	t.Setenv(EnvGitHubClientID, "client-id")
+	t.Setenv(EnvGitHubClientSecret, "client-secret")
+	t.Setenv(EnvSessionSecret, "session-secret")
+
+	model, ok := detect.FindModel("kimi-thinking")
+	if !ok {
+		t.Fatal("kimi-thinking model not found")
+	}
+	secrets, err := GetEffectiveModelSecrets(model)
+	if err != nil {
+		t.Fatalf("GetEffectiveModelSecrets: %v", err)
+	}
+	if secrets["ANTHROPIC_AUTH_TOKEN"] != "sk-kimi" {
+		t.Errorf("model secrets = %v", secrets)
+	}
+
+	auth, err := GetAuthSecrets()
+	if err != nil {
+		t.Fatalf("GetAuthSecrets: %v", err)
+	}
+	if auth.GitHub == nil || auth.GitHub.ClientID != "client-id" || auth.GitHub.ClientSecret != "client-secret" {
+		t.Errorf("github secrets = %+v", auth.GitHub)
+	}
+	if secret, err := EnsureSessionSecret(); err != nil || secret != "session-secret" {
+		t.Errorf("EnsureSessionSecret() = %q, %v", secret, err)
+	}
+
+	if err := SaveModelSecrets("kimi-thinking", map[string]string{"ANTHROPIC_AUTH_TOKEN": "x"}); !errors.Is(err, ErrSecretsReadOnly) {
+		t.Errorf("SaveModelSecrets error = %v, want ErrSecretsReadOnly", err)
+	}
+}
+
+func TestFileSecretStore(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	SetSecretStore(NewSecretStore(""))
+	defer SetSecretStore(fileSecretStore{})
+
+	if err := SaveGitHubAuthSecrets("client-id", "client-secret"); err != nil {
+		t.Fatalf("SaveGitHubAuthSecrets: %v", err)
+	}
+	auth, err := GetAuthSecrets()
+	if err != nil {
+		t.Fatalf("GetAuthSecrets: %v", err)
+	}
+	if auth.GitHub == nil || auth.GitHub.ClientID != "client-id" {
+		t.Errorf("github secrets = %+v", auth.GitHub)
+	}
+}
+
+func TestValidateSecretsBackend(t *testing.T) {
+	cfg := CreateDefault("")
+	for _, backend := range []string{"", SecretsBackendFile, SecretsBackendKeychain, SecretsBackendEnv} {
+		cfg.SecretsBackend = backend
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("secrets_backend %q: %v", backend, err)
+		}
+	}
+	cfg.SecretsBackend = "vault"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("secrets_backend \"vault\": got %v, want ErrInvalidConfig", err)
+	}
+}
@@ -37,8 +37,27 @@ func secretsPath() (string, error) {
 	return filepath.Join(homeDir, ".schmux", "secrets.json"), nil
 }
 
-// LoadSecretsFile loads the secrets file or returns an empty structure if it doesn't exist.
+// LoadSecretsFile loads the secrets from the current secret store, or returns an
+// empty structure if there are none yet.
 func LoadSecretsFile() (*SecretsFile, error) {
+	return currentSecretStore().Load()
+}
+
+// SaveSecretsFile saves the secrets to the current secret store.
+func SaveSecretsFile(secrets *SecretsFile) error {
+	if secrets == nil {
+		secrets = &SecretsFile{}
+	}
+	if secrets.Models == nil {
+		secrets.Models = ModelSecrets{}
+	}
+	return currentSecretStore().Save(secrets)
+}
+
+// fileSecretStore keeps secrets in plaintext in ~/.schmux/secrets.json (mode 0600).
+type fileSecretStore struct{}
+
+func (fileSecretStore) Load() (*SecretsFile, error) {
 	path, err := secretsPath()
 	if err != nil {
 		return nil, err
@@ -77,7 +96,7 @@ func LoadSecretsFile() (*SecretsFile, error) {
 			}
 			secrets.Variants = nil // Clear deprecated field
 			// Best-effort save to persist migration
-			_ = SaveSecretsFile(&secrets)
+			_ = fileSecretStore{}.Save(&secrets)
 		}
 		return &secrets, nil
 	}
@@ -97,7 +116,7 @@ func LoadSecretsFile() (*SecretsFile, error) {
 			}
 			secrets.Variants = nil // Clear deprecated field
 			// Best-effort save to persist migration
-			_ = SaveSecretsFile(&secrets)
+			_ = fileSecretStore{}.Save(&secrets)
 		}
 		return &secrets, nil
 	}
@@ -112,19 +131,12 @@ func LoadSecretsFile() (*SecretsFile, error) {
 	return &SecretsFile{Models: legacy}, nil
 }
 
-func SaveSecretsFile(secrets *SecretsFile) error {
+func (fileSecretStore) Save(secrets *SecretsFile) error {
 	path, err := secretsPath()
 	if err != nil {
 		return err
 	}
 
-	if secrets == nil {
-		secrets = &SecretsFile{}
-	}
-	if secrets.Models == nil {
-		secrets.Models = ModelSecrets{}
-	}
-
 	data, err := json.MarshalIndent(secrets, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal secrets: %w", err)
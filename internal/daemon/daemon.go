@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -197,6 +198,60 @@ func Stop() error {
 	return fmt.Errorf("timeout waiting for daemon to stop")
 }
 
+// StopForRestart stops the daemon if it is running and waits until its dashboard
+// port stops accepting connections, so a new daemon can bind it. It reports
+// whether a daemon was running.
+func StopForRestart() (bool, error) {
+	running, _, _, err := Status()
+	if err != nil {
+		return false, err
+	}
+	if !running {
+		return false, nil
+	}
+	if err := Stop(); err != nil {
+		return true, err
+	}
+	return true, waitForPortFree(5 * time.Second)
+}
+
+// waitForPortFree polls the configured dashboard address until nothing is listening on it.
+func waitForPortFree(timeout time.Duration) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+	addr := net.JoinHostPort("localhost", strconv.Itoa(dashboardPort))
+	if cfg, err := config.Load(filepath.Join(homeDir, ".schmux", "config.json")); err == nil {
+		addr = net.JoinHostPort(cfg.GetDashboardHost(), strconv.Itoa(cfg.GetPort()))
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", addr, 200*time.Millisecond)
+		if err != nil {
+			return nil
+		}
+		conn.Close()
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for %s to be released", addr)
+}
+
+// PendingRestart reports whether config changes saved through the dashboard
+// (network, access control, or tmux socket) are waiting for a daemon restart.
+func PendingRestart() (bool, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return false, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	st, err := state.Load(filepath.Join(homeDir, ".schmux", "state.json"))
+	if err != nil {
+		return false, err
+	}
+	return st.GetNeedsRestart(), nil
+}
+
 // Status returns the status of the daemon.
 func Status() (running bool, url string, startedAt string, err error) {
 	homeDir, err := os.UserHomeDir()
@@ -234,6 +289,8 @@ func Status() (running bool, url string, startedAt string, err error) {
 	if cfg, err := config.Load(filepath.Join(homeDir, ".schmux", "config.json")); err == nil {
 		if cfg.GetAuthEnabled() && cfg.GetPublicBaseURL() != "" {
 			url = cfg.GetPublicBaseURL()
+		} else {
+			url = fmt.Sprintf("http://%s", net.JoinHostPort(cfg.GetDashboardHost(), strconv.Itoa(cfg.GetPort())))
 		}
 	}
 	if startedData, err := os.ReadFile(startedFile); err == nil {
@@ -282,12 +339,19 @@ func Run(background bool) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	config.SetSecretStore(config.NewSecretStore(cfg.GetSecretsBackend()))
 	if cfg.GetAuthEnabled() {
 		if _, err := config.EnsureSessionSecret(); err != nil {
 			return fmt.Errorf("failed to initialize auth session secret: %w", err)
 		}
 	}
 
+	// Route all tmux commands to the configured server before touching any sessions
+	if name := cfg.GetTmuxSocketName(); name != "" {
+		tmux.SetSocketName(name)
+		fmt.Printf("[tmux] using dedicated socket: %s\n", name)
+	}
+
 	// Compute state path
 	statePath := filepath.Join(schmuxDir, "state.json")
 
@@ -333,6 +397,13 @@ func Run(background bool) error {
 		}
 	}
 
+	// Remove logs left behind by sessions that are no longer in state
+	if removed, err := sm.PruneLogFiles(); err != nil {
+		fmt.Printf("[session] warning: failed to prune log files: %v\n", err)
+	} else if removed > 0 {
+		fmt.Printf("[session] pruned %d orphaned log file(s)\n", removed)
+	}
+
 	// Start output trackers for running sessions restored from state.
 	for _, sess := range st.GetSessions() {
 		timeoutCtx, cancel := context.WithTimeout(shutdownCtx, cfg.XtermQueryTimeout())
@@ -392,6 +463,55 @@ func Run(background bool) error {
 		}
 	}()
 
+	// Start background goroutine to remove base repos nothing uses anymore, if enabled
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !cfg.GetPruneBaseRepos() || wm.InMaintenance() {
+					continue
+				}
+				if _, err := wm.PruneBaseRepos(shutdownCtx); err != nil {
+					fmt.Printf("[daemon] failed to prune base repos: %v\n", err)
+				}
+			case <-shutdownCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// Start background goroutine to keep session output logs under xterm.max_log_size_mb
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sm.RotateOutputLogs()
+			case <-shutdownCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// Start background goroutine to dispose remote sessions that failed to start
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				if sm.DisposeFailedRemoteSessions(shutdownCtx, now) > 0 {
+					server.BroadcastSessions()
+				}
+			case <-shutdownCtx.Done():
+				return
+			}
+		}
+	}()
+
 	// Create and start git watcher for filesystem-based change detection.
 	// Started after server creation so broadcasts reach WebSocket clients.
 	gitWatcher := workspace.NewGitWatcher(cfg, wm, server.BroadcastSessions)
@@ -432,6 +552,10 @@ func Run(background bool) error {
 		for {
 			select {
 			case <-ticker.C:
+				// Leave repos alone while they're being worked on by hand
+				if wm.InMaintenance() {
+					continue
+				}
 				ctx, cancel := context.WithTimeout(shutdownCtx, cfg.GitStatusTimeout())
 				// Ensure origin query repos exist (in case new repos were added)
 				if err := wm.EnsureOriginQueries(ctx); err != nil {
@@ -607,6 +731,8 @@ func askNudgeNikForSession(ctx context.Context, cfg *config.Config, sess state.S
 			fmt.Printf("[nudgenik] target not found in config\n")
 		case errors.Is(err, nudgenik.ErrTargetNoSecrets):
 			fmt.Printf("[nudgenik] target missing required secrets\n")
+		case errors.Is(err, nudgenik.ErrRateLimited):
+			fmt.Printf("[nudgenik] %s - rate limited, will retry on the next check\n", sess.ID)
 		default:
 			fmt.Printf("[nudgenik] %s - failed to ask: %v\n", sess.ID, err)
 		}
@@ -633,7 +759,7 @@ func validateSessionAccess(st *state.State) error {
 	currentUID := os.Getuid()
 
 	// Check if we have a tmux server running (socket exists)
-	ourSocket := fmt.Sprintf("/tmp/tmux-%d/default", currentUID)
+	ourSocket := filepath.Join(fmt.Sprintf("/tmp/tmux-%d", currentUID), tmuxSocketFileName())
 	if _, err := os.Stat(ourSocket); err == nil {
 		// We have a tmux server, we can access sessions
 		return nil
@@ -661,6 +787,14 @@ func validateSessionAccess(st *state.State) error {
 	return errors.New(msg.String())
 }
 
+// tmuxSocketFileName returns the socket file name tmux uses under /tmp/tmux-<uid>.
+func tmuxSocketFileName() string {
+	if name := tmux.SocketName(); name != "" {
+		return name
+	}
+	return "default"
+}
+
 // findOtherTmuxServerOwners finds tmux servers owned by users other than currentUID.
 // Only returns users whose tmux server socket actually exists.
 func findOtherTmuxServerOwners(currentUID int) []string {
@@ -688,7 +822,7 @@ func findOtherTmuxServerOwners(currentUID int) []string {
 		}
 
 		// Check if the socket actually exists (server is running)
-		socketPath := filepath.Join(entry, "default")
+		socketPath := filepath.Join(entry, tmuxSocketFileName())
 		if _, err := os.Stat(socketPath); err != nil {
 			continue
 		}
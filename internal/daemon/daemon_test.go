@@ -51,6 +51,28 @@ func TestPidFileParsing(t *testing.T) {
 	}
 }
 
+func TestPendingRestart(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	pending, err := PendingRestart()
+	if err != nil || pending {
+		t.Fatalf("PendingRestart() with no state = %v, %v; want false, nil", pending, err)
+	}
+
+	st := state.New(filepath.Join(home, ".schmux", "state.json"))
+	if err := os.MkdirAll(filepath.Join(home, ".schmux"), 0755); err != nil {
+		t.Fatalf("failed to create schmux dir: %v", err)
+	}
+	st.SetNeedsRestart(true)
+	if err := st.Save(); err != nil {
+		t.Fatalf("failed to save state: %v", err)
+	}
+	if pending, err := PendingRestart(); err != nil || !pending {
+		t.Errorf("PendingRestart() = %v, %v; want true, nil", pending, err)
+	}
+}
+
 func TestShutdown(t *testing.T) {
 	// Just test that Shutdown doesn't panic
 	Shutdown()
@@ -7,9 +7,11 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/github"
 	"github.com/sergeknystautas/schmux/internal/session"
@@ -49,12 +51,17 @@ func TestAPIContract_Healthz(t *testing.T) {
 		t.Fatalf("expected status 200, got %d", rr.Code)
 	}
 
-	var resp map[string]string
+	var resp map[string]any
 	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
 		t.Fatalf("failed to decode response: %v", err)
 	}
 	if resp["status"] != "ok" {
-		t.Fatalf("expected status ok, got %q", resp["status"])
+		t.Fatalf("expected status ok, got %v", resp["status"])
+	}
+	for _, field := range []string{"started_at", "uptime_seconds", "pid", "config_path"} {
+		if _, ok := resp[field]; !ok {
+			t.Errorf("expected %s field in response", field)
+		}
 	}
 }
 
@@ -198,6 +205,38 @@ func TestAPIContract_ConfigUpdateValidation(t *testing.T) {
 	}
 }
 
+func TestAPIContract_ConfigUpdateRejectsInvalidRepoURL(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	body := []byte(`{"repos":[{"name":"demo","url":"https://github.com/only-owner"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/config", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.handleConfigUpdate(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "demo") {
+		t.Fatalf("expected error to name the repo, got %q", rr.Body.String())
+	}
+}
+
+func TestAPIContract_ConfigUpdateRejectsMissingSSHKey(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	body := []byte(`{"repos":[{"name":"demo","url":"git@example.com:org/demo.git","ssh_key_path":"/nonexistent/id_demo"}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/config", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	server.handleConfigUpdate(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "ssh key not found for demo") {
+		t.Fatalf("expected error to name the repo, got %q", rr.Body.String())
+	}
+}
+
 func TestAPIContract_SessionsShape(t *testing.T) {
 	server, _, st := newTestServer(t)
 
@@ -369,6 +408,60 @@ func TestAPIContract_Overlays(t *testing.T) {
 	}
 }
 
+func TestAPIContract_Maintenance(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	maintenance := func(method string, body string) contracts.MaintenanceResponse {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/maintenance", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		server.handleMaintenance(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s /api/maintenance: expected status 200, got %d: %s", method, rr.Code, rr.Body.String())
+		}
+		var resp contracts.MaintenanceResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+	spawnStatus := func() int {
+		t.Helper()
+		body, _ := json.Marshal(SpawnRequest{
+			Repo:    "https://example.com/repo.git",
+			Branch:  "main",
+			Targets: map[string]int{"command": 1},
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/spawn", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		server.handleSpawnPost(rr, req)
+		return rr.Code
+	}
+
+	if got := maintenance(http.MethodGet, ""); got.Enabled || got.Since != "" {
+		t.Fatalf("expected maintenance off by default, got %+v", got)
+	}
+
+	got := maintenance(http.MethodPost, `{"enabled":true,"block_spawns":true}`)
+	if !got.Enabled || !got.BlockSpawns || got.Since == "" {
+		t.Fatalf("expected maintenance on with spawns blocked, got %+v", got)
+	}
+	if code := spawnStatus(); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected spawn to be rejected with 503, got %d", code)
+	}
+
+	if got := maintenance(http.MethodPost, `{"enabled":true}`); !got.Enabled || got.BlockSpawns {
+		t.Fatalf("expected spawns unblocked while still in maintenance, got %+v", got)
+	}
+	if code := spawnStatus(); code == http.StatusServiceUnavailable {
+		t.Fatal("expected spawn to be allowed without block_spawns")
+	}
+
+	if got := maintenance(http.MethodPost, `{"enabled":false,"block_spawns":true}`); got.Enabled || got.BlockSpawns {
+		t.Fatalf("expected maintenance off, got %+v", got)
+	}
+}
+
 func TestAPIContract_WebSocketErrors(t *testing.T) {
 	server, _, st := newTestServer(t)
 
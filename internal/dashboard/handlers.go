@@ -2,17 +2,23 @@ package dashboard
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -25,6 +31,8 @@ import (
 	"github.com/sergeknystautas/schmux/internal/detect"
 	"github.com/sergeknystautas/schmux/internal/difftool"
 	"github.com/sergeknystautas/schmux/internal/nudgenik"
+	"github.com/sergeknystautas/schmux/internal/session"
+	"github.com/sergeknystautas/schmux/internal/signal"
 	"github.com/sergeknystautas/schmux/internal/state"
 	"github.com/sergeknystautas/schmux/internal/update"
 	"github.com/sergeknystautas/schmux/internal/vcs"
@@ -34,10 +42,52 @@ import (
 //go:embed cookbooks.json
 var cookbooksFS embed.FS
 
+// ErrorResponse is the body of every error response from the API handlers.
+type ErrorResponse struct {
+	Error string `json:"error"`
+	// Code identifies the kind of error for clients: a specific code such as
+	// "workspace_locked" where the handler knows the cause, otherwise one derived
+	// from the status, such as "not_found" or "method_not_allowed".
+	Code string `json:"code"`
+}
+
+// writeJSONError replies to the request with an ErrorResponse and the given status.
+// It takes the same arguments as http.Error.
+func writeJSONError(w http.ResponseWriter, message string, status int) {
+	writeJSONErrorCode(w, message, statusErrorCode(status), status)
+}
+
+// writeJSONErrorCode is writeJSONError with a specific error code.
+func writeJSONErrorCode(w http.ResponseWriter, message, code string, status int) {
+	w.Header().Del("Content-Length")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message, Code: code})
+}
+
+// statusErrorCode derives an error code from an HTTP status, e.g. 404 -> "not_found".
+func statusErrorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	var b strings.Builder
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	}) {
+		if b.Len() > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteString(field)
+	}
+	return b.String()
+}
+
 // handleApp serves the React application entry point for UI routes.
 func (s *Server) handleApp(w http.ResponseWriter, r *http.Request) {
 	if strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/ws/") {
-		http.NotFound(w, r)
+		writeJSONError(w, "not found", http.StatusNotFound)
 		return
 	}
 	if !s.requireAuthOrRedirect(w, r) {
@@ -75,7 +125,7 @@ func (s *Server) serveAppIndex(w http.ResponseWriter, r *http.Request) {
 
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		http.Error(w, "Dashboard assets not built. Run `npm install` and `npm run build` in assets/dashboard.", http.StatusNotFound)
+		writeJSONError(w, "Dashboard assets not built. Run `npm install` and `npm run build` in assets/dashboard.", http.StatusNotFound)
 		return
 	}
 
@@ -85,18 +135,22 @@ func (s *Server) serveAppIndex(w http.ResponseWriter, r *http.Request) {
 
 // SessionResponseItem represents a session in the API response.
 type SessionResponseItem struct {
-	ID           string `json:"id"`
-	Target       string `json:"target"`
-	Branch       string `json:"branch"`
-	BranchURL    string `json:"branch_url,omitempty"`
-	Nickname     string `json:"nickname,omitempty"`
-	CreatedAt    string `json:"created_at"`
-	LastOutputAt string `json:"last_output_at,omitempty"`
-	Running      bool   `json:"running"`
-	Status       string `json:"status,omitempty"` // "provisioning", "running", "failed" for remote sessions
-	AttachCmd    string `json:"attach_cmd"`
-	NudgeState   string `json:"nudge_state,omitempty"`
-	NudgeSummary string `json:"nudge_summary,omitempty"`
+	ID            string `json:"id"`
+	Target        string `json:"target"`
+	Branch        string `json:"branch"`
+	BranchURL     string `json:"branch_url,omitempty"`
+	Nickname      string `json:"nickname,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	LastOutputAt  string `json:"last_output_at,omitempty"`
+	Running       bool   `json:"running"`
+	Status        string `json:"status"`                   // remote: "provisioning", "running", "failed"; local: "running", "stopped", or "blocked" when queued
+	BlockedReason string `json:"blocked_reason,omitempty"` // why a blocked session's target is unavailable
+	FailReason    string `json:"fail_reason,omitempty"`    // why a failed remote session couldn't be created
+	RestartCount  int    `json:"restart_count,omitempty"`  // auto-restarts after the agent exited non-zero
+	LastExitCode  int    `json:"last_exit_code,omitempty"` // exit code that triggered the last auto-restart check
+	AttachCmd     string `json:"attach_cmd"`
+	NudgeState    string `json:"nudge_state,omitempty"`
+	NudgeSummary  string `json:"nudge_summary,omitempty"`
 	// Remote session fields
 	RemoteHostID     string `json:"remote_host_id,omitempty"`
 	RemotePaneID     string `json:"remote_pane_id,omitempty"`
@@ -124,11 +178,15 @@ type WorkspaceResponseItem struct {
 	RemoteFlavorName string                `json:"remote_flavor_name,omitempty"`
 	RemoteFlavor     string                `json:"remote_flavor,omitempty"`
 	VCS              string                `json:"vcs,omitempty"` // "git", "sapling", etc. Omitted defaults to "git".
+	Pinned           bool                  `json:"pinned,omitempty"`
+	Imported         bool                  `json:"imported,omitempty"`
+	BranchGone       bool                  `json:"branch_gone,omitempty"`
 }
 
-// buildSessionsResponse builds the sessions/workspaces response data.
-// Used by both the HTTP handler and WebSocket broadcast.
-func (s *Server) buildSessionsResponse() []WorkspaceResponseItem {
+// buildSessionsResponse builds the sessions/workspaces response data, along with the
+// number of sessions that need attention. Used by both the HTTP handler and WebSocket
+// broadcast.
+func (s *Server) buildSessionsResponse() ([]WorkspaceResponseItem, int) {
 	sessions := s.session.GetAllSessions()
 
 	workspaceMap := make(map[string]*WorkspaceResponseItem)
@@ -203,6 +261,9 @@ func (s *Server) buildSessionsResponse() []WorkspaceResponseItem {
 			RemoteFlavorName: remoteFlavorName,
 			RemoteFlavor:     remoteFlavor,
 			VCS:              vcs,
+			Pinned:           ws.Pinned,
+			Imported:         ws.Imported,
+			BranchGone:       ws.GitBranchGone,
 		}
 	}
 
@@ -221,6 +282,13 @@ func (s *Server) buildSessionsResponse() []WorkspaceResponseItem {
 		timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(s.config.GetXtermQueryTimeoutMs())*time.Millisecond)
 		running := s.session.IsRunning(timeoutCtx, sess.ID)
 		cancel()
+		status := sess.Status
+		if status == "" {
+			status = state.SessionStatusStopped
+			if running {
+				status = state.SessionStatusRunning
+			}
+		}
 		nudgeState, nudgeSummary := parseNudgeSummary(sess.Nudge)
 
 		// Get remote host info if this is a remote session
@@ -257,7 +325,11 @@ func (s *Server) buildSessionsResponse() []WorkspaceResponseItem {
 			CreatedAt:        sess.CreatedAt.Format("2006-01-02T15:04:05"),
 			LastOutputAt:     lastOutputAt,
 			Running:          running,
-			Status:           sess.Status, // Expose session status for remote sessions
+			Status:           status,
+			BlockedReason:    sess.BlockedReason,
+			FailReason:       sess.FailReason,
+			RestartCount:     sess.RestartCount,
+			LastExitCode:     sess.LastExitCode,
 			AttachCmd:        attachCmd,
 			NudgeState:       nudgeState,
 			NudgeSummary:     nudgeSummary,
@@ -269,12 +341,15 @@ func (s *Server) buildSessionsResponse() []WorkspaceResponseItem {
 		wsResp.SessionCount = len(wsResp.Sessions)
 	}
 
-	// Convert map to slice and sort workspaces by ID
+	// Convert map to slice and sort workspaces by ID, pinned workspaces first
 	response := make([]WorkspaceResponseItem, 0, len(workspaceMap))
 	for _, ws := range workspaceMap {
 		response = append(response, *ws)
 	}
 	sort.Slice(response, func(i, j int) bool {
+		if response[i].Pinned != response[j].Pinned {
+			return response[i].Pinned
+		}
 		return response[i].ID < response[j].ID
 	})
 
@@ -293,19 +368,42 @@ func (s *Server) buildSessionsResponse() []WorkspaceResponseItem {
 		})
 	}
 
-	return response
+	return response, attentionCount(response)
+}
+
+// needsAttention reports whether a session's nudge state asks for the user: it is
+// blocked on them, errored, or finished, as opposed to still working. Nudges clear
+// when the user types into the session, so a finished session stops counting once
+// they've been back to it.
+func needsAttention(nudgeState string) bool {
+	return nudgeState != "" && nudgeState != signal.MapStateToNudge("working")
+}
+
+// attentionCount returns how many sessions need attention, for tab title and favicon
+// badges.
+func attentionCount(workspaces []WorkspaceResponseItem) int {
+	count := 0
+	for _, ws := range workspaces {
+		for _, sess := range ws.Sessions {
+			if needsAttention(sess.NudgeState) {
+				count++
+			}
+		}
+	}
+	return count
 }
 
 // handleSessions returns the list of workspaces and their sessions as JSON.
 // Returns a hierarchical structure: workspaces -> sessions
 func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	response := s.buildSessionsResponse()
+	response, attention := s.buildSessionsResponse()
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(contracts.AttentionCountHeader, strconv.Itoa(attention))
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -326,13 +424,13 @@ func parseNudgeSummary(nudge string) (string, string) {
 // handleWorkspacesScan scans the workspace directory and reconciles with state.
 func (s *Server) handleWorkspacesScan(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	result, err := s.workspace.Scan()
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to scan workspaces: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to scan workspaces: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -340,19 +438,90 @@ func (s *Server) handleWorkspacesScan(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
-// handleHealthz returns a simple health check response with version info.
+// ImportWorkspaceRequest represents a request to adopt an existing local checkout.
+type ImportWorkspaceRequest struct {
+	Path         string `json:"path"`
+	ApplyOverlay bool   `json:"apply_overlay,omitempty"`
+}
+
+// handleWorkspacesImport registers an existing local checkout as a workspace in place:
+// POST /api/workspaces/import
+func (s *Server) handleWorkspacesImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ImportWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ws, err := s.workspace.Import(r.Context(), req.Path, req.ApplyOverlay)
+	if err != nil {
+		fmt.Printf("[workspace] import error: path=%s error=%v\n", req.Path, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, workspace.ErrInvalidImport) {
+			status = http.StatusBadRequest
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	go s.BroadcastSessions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ForkWorkspaceResponse{
+		WorkspaceID: ws.ID,
+		Branch:      ws.Branch,
+		Path:        ws.Path,
+	})
+}
+
+// handleWorkspacesMigrate moves existing workspaces into the current workspace_path,
+// finishing a workspace_path change: POST /api/workspaces/migrate
+// Workspaces that aren't idle and clean are skipped and reported as such.
+func (s *Server) handleWorkspacesMigrate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	type Response struct {
+		Results []workspace.MigrateResult `json:"results"`
+	}
+
+	results, err := s.workspace.MigrateWorkspaces(r.Context())
+	if err != nil {
+		fmt.Printf("[workspace] migrate error: %v\n", err)
+		writeJSONError(w, fmt.Sprintf("Failed to migrate workspaces: %v", err), http.StatusInternalServerError)
+		return
+	}
+	go s.BroadcastSessions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{Results: results})
+}
+
+// handleHealthz returns a simple health check response with version info, plus when
+// the daemon started, its PID, and the config file in use, to tell restarts apart.
 func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	v := s.GetVersionInfo()
 	response := map[string]any{
-		"status":  "ok",
-		"version": v.Current,
-	}
-	if v.Latest != "" {
+		"status":         "ok",
+		"version":        v.Current,
+		"started_at":     s.startedAt.UTC().Format(time.RFC3339),
+		"uptime_seconds": int64(time.Since(s.startedAt).Seconds()),
+		"pid":            os.Getpid(),
+		"config_path":    s.config.Path(),
+	}
+	// Hide update info when self-update is disabled so the UI offers no update affordance
+	if v.Latest != "" && s.config.GetAllowSelfUpdate() {
 		response["latest_version"] = v.Latest
 		response["update_available"] = v.UpdateAvailable
 	}
@@ -364,7 +533,13 @@ func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
 // handleUpdate triggers an update and shuts down the daemon.
 func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !s.config.GetAllowSelfUpdate() {
+		fmt.Printf("[daemon] update via web UI rejected: access_control.allow_self_update is false\n")
+		writeJSONError(w, "self-update is disabled (access_control.allow_self_update)", http.StatusForbidden)
 		return
 	}
 
@@ -372,9 +547,7 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	s.updateMu.Lock()
 	defer s.updateMu.Unlock()
 	if s.updateInProgress {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusConflict)
-		json.NewEncoder(w).Encode(map[string]string{"error": "update already in progress"})
+		writeJSONError(w, "update already in progress", http.StatusConflict)
 		return
 	}
 	s.updateInProgress = true
@@ -384,9 +557,7 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 	// Run update synchronously so we can report actual success/failure
 	if err := update.Update(); err != nil {
 		s.updateInProgress = false
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("update failed: %v", err)})
+		writeJSONError(w, fmt.Sprintf("update failed: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -405,43 +576,127 @@ func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
 
 // SpawnRequest represents a request to spawn sessions.
 type SpawnRequest struct {
-	Repo            string         `json:"repo"`
-	Branch          string         `json:"branch"`
-	Prompt          string         `json:"prompt"`
-	Nickname        string         `json:"nickname,omitempty"`     // optional human-friendly name for sessions
-	Targets         map[string]int `json:"targets"`                // target name -> quantity
-	WorkspaceID     string         `json:"workspace_id,omitempty"` // optional: spawn into specific workspace
-	Command         string         `json:"command,omitempty"`      // shell command to run directly (alternative to targets)
-	QuickLaunchName string         `json:"quick_launch_name,omitempty"`
-	Resume          bool           `json:"resume,omitempty"`           // resume mode: use agent's resume command
-	RemoteFlavorID  string         `json:"remote_flavor_id,omitempty"` // optional: spawn on remote host
+	Repo            string            `json:"repo"`
+	Branch          string            `json:"branch"`
+	Prompt          string            `json:"prompt"`
+	Nickname        string            `json:"nickname,omitempty"`     // optional human-friendly name for sessions
+	Targets         map[string]int    `json:"targets"`                // target name -> quantity
+	WorkspaceID     string            `json:"workspace_id,omitempty"` // optional: spawn into specific workspace
+	Command         string            `json:"command,omitempty"`      // shell command to run directly (alternative to targets)
+	QuickLaunchName string            `json:"quick_launch_name,omitempty"`
+	Variables       map[string]string `json:"variables,omitempty"`        // values for {{name}} placeholders in the quick launch preset
+	Resume          bool              `json:"resume,omitempty"`           // resume mode: use agent's resume command
+	RemoteFlavorID  string            `json:"remote_flavor_id,omitempty"` // optional: spawn on remote host
+	AllowProtected  bool              `json:"allow_protected,omitempty"`  // permit spawning on a sessions.protected_branches branch
+	SpawnID         string            `json:"spawn_id,omitempty"`         // optional client-chosen id for POST /api/spawn/{id}/cancel
+	BaseRef         string            `json:"base_ref,omitempty"`         // optional tag, commit, or branch a new branch starts from
 }
 
+// errSpawnCancelled is reported for sessions whose spawn was cancelled via POST /api/spawn/{id}/cancel.
+var errSpawnCancelled = errors.New("spawn cancelled")
+
+// errSpawnsBlocked is the error for spawns rejected during maintenance mode.
+const errSpawnsBlocked = "spawns are disabled while maintenance mode is on"
+
 // handleSpawnPost handles session spawning requests.
 func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
 	var req SpawnRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		s.spawn(w, req)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		writeJSONError(w, fmt.Sprintf("Idempotency-Key is longer than %d characters", maxIdempotencyKeyLen), http.StatusBadRequest)
+		return
+	}
+	// A retry of a spawn that's still running waits for it; one that failed with a
+	// server error claims the key and spawns again.
+	for {
+		entry, owner, mismatch := s.spawnIdempotency.begin(key, body)
+		if mismatch {
+			writeJSONErrorCode(w, "Idempotency-Key was already used with a different request", "idempotency_key_reused", http.StatusUnprocessableEntity)
+			return
+		}
+		if owner {
+			capture := newResponseCapture()
+			s.spawn(capture, req)
+			resp := capture.response()
+			s.spawnIdempotency.finish(key, entry, resp)
+			writeIdempotentResponse(w, resp, false)
+			return
+		}
+		resp, err := entry.wait(r.Context())
+		if err != nil {
+			return
+		}
+		if resp != nil {
+			writeIdempotentResponse(w, resp, true)
+			return
+		}
+	}
+}
+
+// handleSpawnDefault spawns the configured default_spawn set.
+// POST /api/spawn-default?repo=&branch=&prompt=
+func (s *Server) handleSpawnDefault(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	defaultSpawn := s.config.GetDefaultSpawn()
+	if defaultSpawn == nil {
+		writeJSONError(w, "no default_spawn configured", http.StatusBadRequest)
 		return
 	}
+	query := r.URL.Query()
+	req := SpawnRequest{
+		Repo:     query.Get("repo"),
+		Branch:   query.Get("branch"),
+		Prompt:   query.Get("prompt"),
+		Nickname: defaultSpawn.Nickname,
+		Targets:  make(map[string]int, len(defaultSpawn.Targets)),
+	}
+	for target, count := range defaultSpawn.Targets {
+		req.Targets[target] = count
+	}
+	s.spawn(w, req)
+}
 
+// spawn validates req and spawns its sessions, writing the SessionResults.
+func (s *Server) spawn(w http.ResponseWriter, req SpawnRequest) {
+	if s.workspace.Maintenance().BlockSpawns {
+		writeJSONError(w, errSpawnsBlocked, http.StatusServiceUnavailable)
+		return
+	}
 	if req.QuickLaunchName != "" {
 		if req.Command != "" || len(req.Targets) > 0 {
-			http.Error(w, "cannot specify quick_launch_name with command or targets", http.StatusBadRequest)
+			writeJSONError(w, "cannot specify quick_launch_name with command or targets", http.StatusBadRequest)
 			return
 		}
 		if req.WorkspaceID == "" {
-			http.Error(w, "workspace_id is required for quick_launch_name", http.StatusBadRequest)
+			writeJSONError(w, "workspace_id is required for quick_launch_name", http.StatusBadRequest)
 			return
 		}
-		resolved, err := s.resolveQuickLaunchByName(req.WorkspaceID, req.QuickLaunchName)
+		resolved, err := s.resolveQuickLaunchByName(req.WorkspaceID, req.QuickLaunchName, req.Variables)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 		if req.Nickname == "" {
@@ -469,32 +724,68 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 	if req.WorkspaceID == "" && req.RemoteFlavorID == "" {
 		// When not spawning into existing workspace and not remote, repo and branch are required
 		if req.Repo == "" {
-			http.Error(w, "repo is required (when not using --workspace or remote)", http.StatusBadRequest)
+			writeJSONError(w, "repo is required (when not using --workspace or remote)", http.StatusBadRequest)
 			return
 		}
 		if req.Branch == "" {
-			http.Error(w, "branch is required (when not using --workspace or remote)", http.StatusBadRequest)
+			writeJSONError(w, "branch is required (when not using --workspace or remote)", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.BaseRef != "" {
+		if req.WorkspaceID != "" || req.RemoteFlavorID != "" || strings.HasPrefix(req.Repo, "local:") {
+			writeJSONError(w, "base_ref only applies when spawning a new workspace from a configured repo", http.StatusBadRequest)
+			return
+		}
+		if err := workspace.ValidateBaseRef(req.BaseRef); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 	}
 	// Either command or targets must be provided
 	if req.Command == "" && len(req.Targets) == 0 {
-		http.Error(w, "either command or targets is required", http.StatusBadRequest)
+		writeJSONError(w, "either command or targets is required", http.StatusBadRequest)
 		return
 	}
 	if req.Command != "" && len(req.Targets) > 0 {
-		http.Error(w, "cannot specify both command and targets", http.StatusBadRequest)
+		writeJSONError(w, "cannot specify both command and targets", http.StatusBadRequest)
 		return
 	}
 
 	// Validate resume mode
 	if req.Resume {
 		if req.Command != "" {
-			http.Error(w, "cannot use command mode with resume", http.StatusBadRequest)
+			writeJSONError(w, "cannot use command mode with resume", http.StatusBadRequest)
 			return
 		}
 		if strings.TrimSpace(req.Prompt) != "" {
-			http.Error(w, "cannot use prompt with resume mode", http.StatusBadRequest)
+			writeJSONError(w, "cannot use prompt with resume mode", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if rejected, ok := s.spawnCommandsAllowed(req); !ok {
+		writeJSONErrorCode(w, fmt.Sprintf("command %q is not permitted by access_control.command_allowlist", rejected), "command_not_allowed", http.StatusForbidden)
+		return
+	}
+
+	// Protected branch check: an existing workspace's branch counts, not just the requested one
+	branch := req.Branch
+	if req.WorkspaceID != "" {
+		if ws, found := s.state.GetWorkspace(req.WorkspaceID); found {
+			branch = ws.Branch
+		}
+	}
+	if !req.AllowProtected && branch != "" && s.config.IsProtectedBranch(branch) {
+		writeJSONErrorCode(w, fmt.Sprintf("branch %q is protected (sessions.protected_branches); set allow_protected to spawn on it", branch), "protected_branch", http.StatusForbidden)
+		return
+	}
+
+	// Branch prefix check: only for branches the spawn would create, so existing
+	// branches that predate the repo's naming convention stay usable
+	if req.WorkspaceID == "" && req.Repo != "" && !s.config.BranchMatchesPrefix(req.Repo, req.Branch) {
+		if exists, err := s.workspace.BranchExists(context.Background(), req.Repo, req.Branch); err == nil && !exists.ExistsRemote && !exists.ExistsLocal {
+			writeJSONErrorCode(w, fmt.Sprintf("new branch %q must start with a match for the repo's branch_prefix_regex %q", req.Branch, s.config.GetBranchPrefixRegex(req.Repo)), "branch_prefix", http.StatusBadRequest)
 			return
 		}
 	}
@@ -504,12 +795,31 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 	if req.WorkspaceID == "" && s.config.UseWorktrees() {
 		for _, ws := range s.state.GetWorkspaces() {
 			if ws.Repo == req.Repo && ws.Branch == req.Branch {
-				http.Error(w, fmt.Sprintf("branch_conflict: branch %q is already in use by workspace %q", req.Branch, ws.ID), http.StatusConflict)
+				writeJSONErrorCode(w, fmt.Sprintf("branch %q is already in use by workspace %q", req.Branch, ws.ID), "branch_conflict", http.StatusConflict)
 				return
 			}
 		}
 	}
 
+	// Spawns run under a cancellable context so POST /api/spawn/{id}/cancel can abort
+	// a long clone; workspace creation cleans up after itself when cancelled.
+	spawnCtx, spawnCancel := context.WithCancel(context.Background())
+	defer spawnCancel()
+	if req.SpawnID != "" {
+		if !s.registerSpawn(req.SpawnID, spawnCancel) {
+			writeJSONError(w, fmt.Sprintf("spawn %s is already in progress", req.SpawnID), http.StatusConflict)
+			return
+		}
+		defer s.unregisterSpawn(req.SpawnID)
+		spawnID := req.SpawnID
+		spawnCtx = workspace.WithProgress(spawnCtx, func(stage, detail string) {
+			s.setSpawnProgress(spawnID, stage, detail)
+		})
+	}
+	if req.BaseRef != "" {
+		spawnCtx = workspace.WithBaseRef(spawnCtx, req.BaseRef)
+	}
+
 	// Spawn sessions
 	type SessionResult struct {
 		SessionID   string `json:"session_id"`
@@ -518,7 +828,11 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 		Command     string `json:"command,omitempty"`
 		Prompt      string `json:"prompt,omitempty"`
 		Nickname    string `json:"nickname,omitempty"`
+		Status      string `json:"status,omitempty"` // "blocked" when queued because the target is unavailable
 		Error       string `json:"error,omitempty"`
+		// ConflictedFiles lists the files that conflicted when pulling the branch into
+		// WorkspaceID; the workspace was restored and can go through conflict resolution.
+		ConflictedFiles []string `json:"conflicted_files,omitempty"`
 	}
 
 	results := make([]SessionResult, 0)
@@ -527,16 +841,19 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 	if req.Command != "" {
 		// Remote command spawns are not currently supported
 		if req.RemoteFlavorID != "" {
-			http.Error(w, "remote command spawns are not supported (only target-based spawns work on remote hosts)", http.StatusBadRequest)
+			writeJSONError(w, "remote command spawns are not supported (only target-based spawns work on remote hosts)", http.StatusBadRequest)
 			return
 		}
 
 		fmt.Printf("[session] spawn request: repo=%s branch=%s workspace_id=%s command=%q nickname=%q\n",
 			req.Repo, req.Branch, req.WorkspaceID, req.Command, req.Nickname)
 
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitCloneTimeoutMs())*time.Millisecond)
+		ctx, cancel := context.WithTimeout(spawnCtx, time.Duration(s.config.GetGitCloneTimeoutMs())*time.Millisecond)
 		sess, err := s.session.SpawnCommand(ctx, req.Repo, req.Branch, req.Command, req.Nickname, req.WorkspaceID)
 		cancel()
+		if err != nil && spawnCtx.Err() != nil {
+			err = errSpawnCancelled
+		}
 
 		if err != nil {
 			results = append(results, SessionResult{
@@ -557,12 +874,16 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(results); err != nil {
-			http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+			writeJSONError(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
 		}
 		return
 	}
 
 	// Handle target-based spawn
+	if limit := s.config.GetMaxPromptBytes(); len(req.Prompt) > limit {
+		writeJSONError(w, fmt.Sprintf("prompt is %d bytes, which exceeds the limit of %d bytes (sessions.max_prompt_bytes)", len(req.Prompt), limit), http.StatusBadRequest)
+		return
+	}
 	promptPreview := req.Prompt
 	if len(promptPreview) > 100 {
 		promptPreview = promptPreview[:100] + "..."
@@ -631,8 +952,18 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 				nickname = req.Nickname
 			}
 
+			if spawnCtx.Err() != nil {
+				results = append(results, SessionResult{
+					Target:   targetName,
+					Prompt:   req.Prompt,
+					Nickname: nickname,
+					Error:    errSpawnCancelled.Error(),
+				})
+				continue
+			}
+
 			// Session spawn needs a longer timeout for git operations
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitCloneTimeoutMs())*time.Millisecond)
+			ctx, cancel := context.WithTimeout(spawnCtx, time.Duration(s.config.GetGitCloneTimeoutMs())*time.Millisecond)
 
 			var sess *state.Session
 			var err error
@@ -647,13 +978,22 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 			}
 
 			cancel()
+			if err != nil && spawnCtx.Err() != nil {
+				err = errSpawnCancelled
+			}
 			if err != nil {
-				results = append(results, SessionResult{
+				result := SessionResult{
 					Target:   targetName,
 					Prompt:   req.Prompt,
 					Nickname: nickname,
 					Error:    err.Error(),
-				})
+				}
+				var conflictErr *workspace.PullConflictError
+				if errors.As(err, &conflictErr) {
+					result.WorkspaceID = conflictErr.WorkspaceID
+					result.ConflictedFiles = conflictErr.ConflictedFiles
+				}
+				results = append(results, result)
 			} else {
 				results = append(results, SessionResult{
 					SessionID:   sess.ID,
@@ -661,6 +1001,7 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 					Target:      targetName,
 					Prompt:      req.Prompt,
 					Nickname:    sess.Nickname, // Return actual nickname, not input
+					Status:      sess.Status,
 				})
 			}
 		}
@@ -686,12 +1027,140 @@ func (s *Server) handleSpawnPost(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(results)
 }
 
+// spawnCommandsAllowed checks the shell commands a spawn would run, the raw or
+// quick launch command, those of command-type targets, and the extra panes and
+// on_exit_command of any target, against access_control.command_allowlist. It returns the first rejected
+// command and false.
+func (s *Server) spawnCommandsAllowed(req SpawnRequest) (rejected string, ok bool) {
+	if req.Command != "" && !s.config.IsCommandAllowed(req.Command) {
+		return req.Command, false
+	}
+	for targetName := range req.Targets {
+		target, found := s.config.GetRunTarget(targetName)
+		if !found {
+			continue
+		}
+		if target.Type == config.RunTargetTypeCommand && !s.config.IsCommandAllowed(target.Command) {
+			return target.Command, false
+		}
+		for _, pane := range target.Panes {
+			if !s.config.IsCommandAllowed(pane.Command) {
+				return pane.Command, false
+			}
+		}
+		if onExit := strings.TrimSpace(target.OnExitCommand); onExit != "" && !s.config.IsCommandAllowed(onExit) {
+			return onExit, false
+		}
+	}
+	return "", true
+}
+
+// registerSpawn records the cancel func of an in-progress spawn.
+// Returns false if a spawn with the same id is already running.
+func (s *Server) registerSpawn(id string, cancel context.CancelFunc) bool {
+	s.spawnCancelsMu.Lock()
+	defer s.spawnCancelsMu.Unlock()
+	if _, exists := s.spawnCancels[id]; exists {
+		return false
+	}
+	s.spawnCancels[id] = cancel
+	s.spawnProgress[id] = contracts.SpawnProgressResponse{SpawnID: id}
+	return true
+}
+
+// unregisterSpawn forgets a finished spawn.
+func (s *Server) unregisterSpawn(id string) {
+	s.spawnCancelsMu.Lock()
+	defer s.spawnCancelsMu.Unlock()
+	delete(s.spawnCancels, id)
+	delete(s.spawnProgress, id)
+}
+
+// setSpawnProgress records the workspace step an in-progress spawn has reached.
+func (s *Server) setSpawnProgress(id, stage, detail string) {
+	s.spawnCancelsMu.Lock()
+	defer s.spawnCancelsMu.Unlock()
+	if _, found := s.spawnProgress[id]; !found {
+		return // finished while git was still printing
+	}
+	s.spawnProgress[id] = contracts.SpawnProgressResponse{
+		SpawnID:   id,
+		Stage:     stage,
+		Detail:    detail,
+		UpdatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// handleSpawnByID routes /api/spawn/{id}/... requests.
+func (s *Server) handleSpawnByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/progress") {
+		s.handleSpawnProgress(w, r)
+		return
+	}
+	s.handleSpawnCancel(w, r)
+}
+
+// handleSpawnProgress reports the workspace step an in-progress spawn has reached,
+// for the spawn page to show while a clone or fetch is running.
+// GET /api/spawn/{id}/progress
+func (s *Server) handleSpawnProgress(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/spawn/")
+	spawnID, ok := strings.CutSuffix(rest, "/progress")
+	if !ok || spawnID == "" || strings.Contains(spawnID, "/") {
+		writeJSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.spawnCancelsMu.Lock()
+	progress, found := s.spawnProgress[spawnID]
+	s.spawnCancelsMu.Unlock()
+	if !found {
+		writeJSONError(w, fmt.Sprintf("no spawn in progress with id %s", spawnID), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// handleSpawnCancel cancels an in-progress spawn started with a spawn_id. The spawn
+// request then returns with "spawn cancelled" errors for the sessions it didn't start;
+// a workspace that was still being cloned is removed.
+// POST /api/spawn/{id}/cancel
+func (s *Server) handleSpawnCancel(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/spawn/")
+	spawnID, ok := strings.CutSuffix(rest, "/cancel")
+	if !ok || spawnID == "" || strings.Contains(spawnID, "/") {
+		writeJSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.spawnCancelsMu.Lock()
+	cancel, found := s.spawnCancels[spawnID]
+	s.spawnCancelsMu.Unlock()
+	if !found {
+		writeJSONError(w, fmt.Sprintf("no spawn in progress with id %s", spawnID), http.StatusNotFound)
+		return
+	}
+	fmt.Printf("[session] cancelling spawn %s\n", spawnID)
+	cancel()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "cancelling", "spawn_id": spawnID})
+}
+
 // handleSuggestBranch handles branch name suggestion requests.
 func (s *Server) handleSuggestBranch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Method not allowed"})
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -700,19 +1169,20 @@ func (s *Server) handleSuggestBranch(w http.ResponseWriter, r *http.Request) {
 	// Parse request
 	var req struct {
 		Prompt string `json:"prompt"`
+		Repo   string `json:"repo,omitempty"` // repo URL, for the per-repo branch_suggest and branch_prefix_regex settings
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
 	// Check if branch suggestion is enabled
-	if !branchsuggest.IsEnabled(s.config) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Branch suggestion is not configured"})
+	if s.config.GetBranchSuggestTarget() == "" {
+		writeJSONError(w, "Branch suggestion is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if !branchsuggest.IsEnabled(s.config, req.Repo) {
+		writeJSONError(w, "Branch suggestion is turned off for this repo", http.StatusServiceUnavailable)
 		return
 	}
 
@@ -720,7 +1190,7 @@ func (s *Server) handleSuggestBranch(w http.ResponseWriter, r *http.Request) {
 	fmt.Printf("[workspace] asking %s for branch suggestion\n", targetName)
 
 	// Generate branch suggestion
-	result, err := branchsuggest.AskForPrompt(r.Context(), s.config, req.Prompt)
+	result, err := branchsuggest.AskForPrompt(r.Context(), s.config, req.Prompt, req.Repo)
 	if err != nil {
 		status := http.StatusInternalServerError
 		switch {
@@ -734,9 +1204,7 @@ func (s *Server) handleSuggestBranch(w http.ResponseWriter, r *http.Request) {
 			status = http.StatusBadRequest
 		}
 		fmt.Printf("[workspace] suggest-branch error: duration=%s status=%d err=%v\n", time.Since(start).Truncate(time.Millisecond), status, err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(status)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to generate branch suggestion: %v", err)})
+		writeJSONError(w, fmt.Sprintf("Failed to generate branch suggestion: %v", err), status)
 		return
 	}
 
@@ -751,9 +1219,7 @@ func (s *Server) handleSuggestBranch(w http.ResponseWriter, r *http.Request) {
 // everything needed to populate the spawn form.
 func (s *Server) handlePrepareBranchSpawn(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Method not allowed"})
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -764,15 +1230,11 @@ func (s *Server) handlePrepareBranchSpawn(w http.ResponseWriter, r *http.Request
 		Branch string `json:"branch"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 	if req.Repo == "" || req.Branch == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "repo and branch are required"})
+		writeJSONError(w, "repo and branch are required", http.StatusBadRequest)
 		return
 	}
 
@@ -808,12 +1270,12 @@ func (s *Server) handlePrepareBranchSpawn(w http.ResponseWriter, r *http.Request
 
 	// Generate nickname from commit messages if branch suggestion is enabled
 	nickname := ""
-	if branchsuggest.IsEnabled(s.config) && len(subjects) > 0 {
+	if branchsuggest.IsEnabled(s.config, req.Repo) && len(subjects) > 0 {
 		commitSummary := strings.Join(subjects, "\n")
 		suggestionPrompt := fmt.Sprintf("Branch: %s\n\nCommit messages:\n%s", req.Branch, commitSummary)
 
 		fmt.Printf("[workspace] prepare-branch-spawn: asking for nickname from %d commits\n", len(subjects))
-		result, err := branchsuggest.AskForPrompt(ctx, s.config, suggestionPrompt)
+		result, err := branchsuggest.AskForPrompt(ctx, s.config, suggestionPrompt, "")
 		if err != nil {
 			fmt.Printf("[workspace] prepare-branch-spawn: nickname suggestion failed: %v\n", err)
 			// Non-fatal: proceed without nickname
@@ -834,8 +1296,24 @@ func (s *Server) handlePrepareBranchSpawn(w http.ResponseWriter, r *http.Request
 
 // handleDispose handles session disposal requests.
 func (s *Server) handleDispose(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/output/since") {
+		s.handleSessionOutputSince(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/output") {
+		s.handleSessionOutput(w, r)
+		return
+	}
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/restart") {
+		s.handleRestartSession(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/message") {
+		s.handleSessionMessage(w, r)
 		return
 	}
 
@@ -843,7 +1321,7 @@ func (s *Server) handleDispose(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
 	sessionID := strings.TrimSuffix(path, "/dispose")
 	if sessionID == "" {
-		http.Error(w, "session ID is required", http.StatusBadRequest)
+		writeJSONError(w, "session ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -851,7 +1329,7 @@ func (s *Server) handleDispose(w http.ResponseWriter, r *http.Request) {
 	if err := s.session.Dispose(ctx, sessionID); err != nil {
 		cancel()
 		fmt.Printf("[session] dispose error: session_id=%s error=%v\n", sessionID, err)
-		http.Error(w, fmt.Sprintf("Failed to dispose session: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to dispose session: %v", err), http.StatusInternalServerError)
 		return
 	}
 	cancel()
@@ -864,49 +1342,384 @@ func (s *Server) handleDispose(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
-// handleDisposeWorkspace handles workspace disposal requests.
-func (s *Server) handleDisposeWorkspace(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// handleRestartSession starts a blocked session once its target is available.
+// POST /api/sessions/{id}/restart
+func (s *Server) handleRestartSession(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
+	sessionID := strings.TrimSuffix(path, "/restart")
+	if sessionID == "" {
+		writeJSONError(w, "session ID is required", http.StatusBadRequest)
 		return
 	}
 
-	// Extract workspace ID from URL: /api/workspaces/{id}/dispose
-	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
-	workspaceID := strings.TrimSuffix(path, "/dispose")
-	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+	sess, err := s.session.GetSession(sessionID)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("session not found: %s", sessionID), http.StatusNotFound)
+		return
+	}
+	if !sess.IsBlocked() {
+		writeJSONError(w, fmt.Sprintf("session is not blocked: %s", sessionID), http.StatusConflict)
 		return
 	}
 
-	if err := s.workspace.Dispose(workspaceID); err != nil {
-		fmt.Printf("[workspace] dispose error: workspace_id=%s error=%v\n", workspaceID, err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest) // 400 for client-side errors like dirty state
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	restarted, err := s.session.Restart(ctx, sessionID)
+	if err != nil {
+		fmt.Printf("[session] restart error: session_id=%s error=%v\n", sessionID, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, session.ErrTargetUnavailable) {
+			status = http.StatusConflict
+		}
+		writeJSONError(w, err.Error(), status)
+		go s.BroadcastSessions()
 		return
 	}
-	fmt.Printf("[workspace] dispose success: workspace_id=%s\n", workspaceID)
+	fmt.Printf("[session] restart success: session_id=%s\n", sessionID)
 
-	// Broadcast update to WebSocket clients
 	go s.BroadcastSessions()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "session_id": restarted.ID})
 }
 
-// handleDisposeWorkspaceAll handles workspace disposal requests including all sessions.
-func (s *Server) handleDisposeWorkspaceAll(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// SessionMessageRequest is the body of POST /api/sessions/{id}/message.
+type SessionMessageRequest struct {
+	Message string `json:"message"`
+}
+
+// handleSessionMessage sends a follow-up message to a session's agent, or queues it
+// if the session hasn't started yet.
+// POST /api/sessions/{id}/message
+func (s *Server) handleSessionMessage(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
+	sessionID := strings.TrimSuffix(path, "/message")
+	if sessionID == "" {
+		writeJSONError(w, "session ID is required", http.StatusBadRequest)
 		return
 	}
 
-	// Extract workspace ID from URL: /api/workspaces/{id}/dispose-all
-	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
-	workspaceID := strings.TrimSuffix(path, "/dispose-all")
-	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+	var req SessionMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeJSONError(w, "message is required", http.StatusBadRequest)
+		return
+	}
+	if limit := s.config.GetMaxPromptBytes(); len(req.Message) > limit {
+		writeJSONError(w, fmt.Sprintf("message is %d bytes, which exceeds the limit of %d bytes (sessions.max_prompt_bytes)", len(req.Message), limit), http.StatusBadRequest)
+		return
+	}
+	if _, err := s.session.GetSession(sessionID); err != nil {
+		writeJSONError(w, fmt.Sprintf("session not found: %s", sessionID), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	queued, err := s.session.SendMessage(ctx, sessionID, req.Message)
+	if err != nil {
+		fmt.Printf("[session] message error: session_id=%s error=%v\n", sessionID, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, session.ErrSessionNotRunning) {
+			status = http.StatusConflict
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+
+	status := "sent"
+	if queued {
+		status = "queued"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+// AdoptSessionRequest is the body of POST /api/sessions/adopt.
+type AdoptSessionRequest struct {
+	TmuxSession string `json:"tmux_session"`
+	WorkspaceID string `json:"workspace_id"`
+	Nickname    string `json:"nickname,omitempty"`
+}
+
+// handleAdoptSession registers an existing tmux session with schmux.
+// POST /api/sessions/adopt
+func (s *Server) handleAdoptSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AdoptSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	req.TmuxSession = strings.TrimSpace(req.TmuxSession)
+	if req.TmuxSession == "" || req.WorkspaceID == "" {
+		writeJSONError(w, "tmux_session and workspace_id are required", http.StatusBadRequest)
+		return
+	}
+	if _, found := s.state.GetWorkspace(req.WorkspaceID); !found {
+		writeJSONError(w, fmt.Sprintf("workspace not found: %s", req.WorkspaceID), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	sess, err := s.session.Adopt(ctx, req.TmuxSession, req.WorkspaceID, req.Nickname)
+	if err != nil {
+		fmt.Printf("[session] adopt error: tmux_session=%s workspace_id=%s error=%v\n", req.TmuxSession, req.WorkspaceID, err)
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, session.ErrTmuxSessionNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, session.ErrAlreadyManaged):
+			status = http.StatusConflict
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+	fmt.Printf("[session] adopt success: session_id=%s tmux_session=%s\n", sess.ID, sess.TmuxSession)
+
+	go s.BroadcastSessions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "session_id": sess.ID})
+}
+
+// ReconcileSessionsRequest is the optional body of POST /api/sessions/reconcile.
+type ReconcileSessionsRequest struct {
+	KeepDead bool `json:"keep_dead,omitempty"`
+}
+
+// handleReconcileSessions drops sessions whose process and tmux session are gone.
+// POST /api/sessions/reconcile
+// Unlike dispose, nothing is killed; state is brought in line with what's running.
+// With keep_dead the dead sessions are only reported.
+func (s *Server) handleReconcileSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ReconcileSessionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	dead, err := s.session.Reconcile(r.Context(), req.KeepDead)
+	if err != nil {
+		fmt.Printf("[session] reconcile error: %v\n", err)
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if dead == nil {
+		dead = []string{}
+	}
+	fmt.Printf("[session] reconcile: dead=%d keep_dead=%v\n", len(dead), req.KeepDead)
+
+	if len(dead) > 0 && !req.KeepDead {
+		go s.BroadcastSessions()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dead":    dead,
+		"removed": !req.KeepDead,
+	})
+}
+
+// TargetTestRequest is the optional body of POST /api/targets/{name}/test.
+type TargetTestRequest struct {
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+const (
+	defaultTargetTestTimeout = 10 * time.Second
+	maxTargetTestTimeout     = 60 * time.Second
+)
+
+// handleTargetTest launches a run target or model once in a throwaway tmux session
+// and reports whether it started, its exit code, and the first output.
+// POST /api/targets/{name}/test
+func (s *Server) handleTargetTest(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/targets/")
+	name, ok := strings.CutSuffix(rest, "/test")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		writeJSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TargetTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	timeout := defaultTargetTestTimeout
+	if req.TimeoutMs > 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+	}
+	if timeout > maxTargetTestTimeout {
+		timeout = maxTargetTestTimeout
+	}
+
+	if target, found := s.config.GetRunTarget(name); found && target.Type == config.RunTargetTypeCommand && !s.config.IsCommandAllowed(target.Command) {
+		writeJSONErrorCode(w, fmt.Sprintf("command %q is not permitted by access_control.command_allowlist", target.Command), "command_not_allowed", http.StatusForbidden)
+		return
+	}
+
+	result, err := s.session.ProbeTarget(r.Context(), name, timeout)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, session.ErrTargetNotFound) {
+			status = http.StatusNotFound
+		} else {
+			fmt.Printf("[session] target test error for %s: %v\n", name, err)
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// handleSessionOutput returns a snapshot of a session's terminal, including scrollback.
+// GET /api/sessions/{id}/output[?ansi=true]
+// With ansi=true the escape sequences for colors and attributes are kept so the
+// screen can be reproduced faithfully; otherwise the output is plain text.
+func (s *Server) handleSessionOutput(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
+	sessionID := strings.TrimSuffix(path, "/output")
+	if sessionID == "" {
+		writeJSONError(w, "session ID is required", http.StatusBadRequest)
+		return
+	}
+
+	sess, err := s.session.GetSession(sessionID)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("session not found: %s", sessionID), http.StatusNotFound)
+		return
+	}
+	if sess.IsBlocked() {
+		writeJSONError(w, fmt.Sprintf("session is blocked: %s", sessionID), http.StatusConflict)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.GetXtermQueryTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	var output string
+	if r.URL.Query().Get("ansi") == "true" {
+		output, err = s.session.GetOutputRaw(ctx, sessionID)
+	} else {
+		output, err = s.session.GetOutput(ctx, sessionID)
+	}
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Failed to capture output: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(output))
+}
+
+// handleSessionOutputSince returns the output a session logged after a marker from a
+// previous call, for clients that poll without re-fetching the whole screen.
+// GET /api/sessions/{id}/output/since?marker=<marker>[&ansi=true]
+func (s *Server) handleSessionOutputSince(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/sessions/")
+	sessionID := strings.TrimSuffix(path, "/output/since")
+	if sessionID == "" {
+		writeJSONError(w, "session ID is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := s.session.GetSession(sessionID); err != nil {
+		writeJSONError(w, fmt.Sprintf("session not found: %s", sessionID), http.StatusNotFound)
+		return
+	}
+
+	since, err := s.session.GetOutputSince(sessionID, r.URL.Query().Get("marker"), r.URL.Query().Get("ansi") == "true")
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, session.ErrNoOutputLog) {
+			status = http.StatusConflict
+		}
+		writeJSONError(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contracts.SessionOutputSinceResponse{
+		Output:    since.Output,
+		Marker:    since.Marker,
+		Truncated: since.Truncated,
+	})
+}
+
+// handleDisposeWorkspace handles workspace disposal requests.
+func (s *Server) handleDisposeWorkspace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract workspace ID from URL: /api/workspaces/{id}/dispose
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/dispose")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.workspace.Dispose(workspaceID); err != nil {
+		fmt.Printf("[workspace] dispose error: workspace_id=%s error=%v\n", workspaceID, err)
+		writeJSONError(w, err.Error(), http.StatusBadRequest) // 400 for client-side errors like dirty state
+		return
+	}
+	fmt.Printf("[workspace] dispose success: workspace_id=%s\n", workspaceID)
+
+	// Broadcast update to WebSocket clients
+	go s.BroadcastSessions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// handleDisposeWorkspaceAll handles workspace disposal requests including all sessions.
+func (s *Server) handleDisposeWorkspaceAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract workspace ID from URL: /api/workspaces/{id}/dispose-all
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/dispose-all")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -930,9 +1743,7 @@ func (s *Server) handleDisposeWorkspaceAll(w http.ResponseWriter, r *http.Reques
 	// Then dispose the workspace
 	if err := s.workspace.Dispose(workspaceID); err != nil {
 		fmt.Printf("[workspace] dispose-all error: workspace_id=%s error=%v\n", workspaceID, err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	fmt.Printf("[workspace] dispose-all success: workspace_id=%s sessions_disposed=%d\n", workspaceID, len(sessionsDisposed))
@@ -944,6 +1755,74 @@ func (s *Server) handleDisposeWorkspaceAll(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+// ForkWorkspaceRequest represents a request to fork a workspace onto a new branch.
+type ForkWorkspaceRequest struct {
+	Branch          string `json:"branch"`
+	CopyUncommitted bool   `json:"copy_uncommitted,omitempty"`
+}
+
+// ForkWorkspaceResponse describes the workspace created by a fork.
+type ForkWorkspaceResponse struct {
+	WorkspaceID string `json:"workspace_id"`
+	Branch      string `json:"branch"`
+	Path        string `json:"path"`
+}
+
+// handleForkWorkspace creates a new workspace from another workspace's current HEAD.
+// POST /api/workspaces/{id}/fork
+func (s *Server) handleForkWorkspace(w http.ResponseWriter, r *http.Request) {
+	// Extract workspace ID from URL: /api/workspaces/{id}/fork
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/fork")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req ForkWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Branch = strings.TrimSpace(req.Branch)
+	if err := workspace.ValidateBranchName(req.Branch); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	src, found := s.state.GetWorkspace(workspaceID)
+	if !found {
+		writeJSONError(w, fmt.Sprintf("workspace %s not found", workspaceID), http.StatusNotFound)
+		return
+	}
+	if src.IsRemoteWorkspace() {
+		writeJSONError(w, "fork is not supported for remote workspaces", http.StatusBadRequest)
+		return
+	}
+
+	fmt.Printf("[workspace] fork: workspace_id=%s branch=%s copy_uncommitted=%v\n", workspaceID, req.Branch, req.CopyUncommitted)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitCloneTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	ws, err := s.workspace.Fork(ctx, workspaceID, req.Branch, req.CopyUncommitted)
+	if err != nil {
+		fmt.Printf("[workspace] fork error: workspace_id=%s error=%v\n", workspaceID, err)
+		writeJSONError(w, fmt.Sprintf("Failed to fork workspace: %v", err), http.StatusInternalServerError)
+		return
+	}
+	fmt.Printf("[workspace] fork success: workspace_id=%s new_workspace_id=%s\n", workspaceID, ws.ID)
+
+	go s.BroadcastSessions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ForkWorkspaceResponse{
+		WorkspaceID: ws.ID,
+		Branch:      ws.Branch,
+		Path:        ws.Path,
+	})
+}
+
 // UpdateNicknameRequest represents a request to update a session's nickname.
 type UpdateNicknameRequest struct {
 	Nickname string `json:"nickname"`
@@ -952,20 +1831,20 @@ type UpdateNicknameRequest struct {
 // handleUpdateNickname handles session nickname update requests.
 func (s *Server) handleUpdateNickname(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Extract session ID from URL: /api/sessions-nickname/{session-id}
 	sessionID := strings.TrimPrefix(r.URL.Path, "/api/sessions-nickname/")
 	if sessionID == "" {
-		http.Error(w, "session ID is required", http.StatusBadRequest)
+		writeJSONError(w, "session ID is required", http.StatusBadRequest)
 		return
 	}
 
 	var req UpdateNicknameRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
 
@@ -975,21 +1854,25 @@ func (s *Server) handleUpdateNickname(w http.ResponseWriter, r *http.Request) {
 	cancel()
 	if err != nil {
 		// Check if this is a nickname conflict error
-		if strings.Contains(err.Error(), "already in use") {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict) // 409 Conflict
-			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		if errors.Is(err, session.ErrNicknameInUse) {
+			writeJSONErrorCode(w, err.Error(), "nickname_in_use", http.StatusConflict)
 			return
 		}
-		http.Error(w, fmt.Sprintf("Failed to rename session: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to rename session: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	// Broadcast update to WebSocket clients
 	go s.BroadcastSessions()
 
+	// With sessions.nickname_collision "suffix", the applied nickname may differ from the request
+	nickname := req.Nickname
+	if sess, found := s.state.GetSession(sessionID); found {
+		nickname = sess.Nickname
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok", "nickname": nickname})
 }
 
 // handleConfig returns the config (repos and agents) for the spawn form,
@@ -1001,7 +1884,7 @@ func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost, http.MethodPut:
 		s.handleConfigUpdate(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
@@ -1029,7 +1912,7 @@ func (s *Server) handleDetectTools(w http.ResponseWriter, r *http.Request) {
 			})
 		}
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -1044,11 +1927,35 @@ func (s *Server) handleDetectTools(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(Response{Tools: toolResp}); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
 		return
 	}
 }
 
+// contractTargetPanes converts a run target's extra panes for the config response.
+func contractTargetPanes(panes []config.TargetPane) []contracts.TargetPane {
+	if len(panes) == 0 {
+		return nil
+	}
+	result := make([]contracts.TargetPane, len(panes))
+	for i, pane := range panes {
+		result[i] = contracts.TargetPane{Command: pane.Command, Split: pane.Split, Size: pane.Size}
+	}
+	return result
+}
+
+// configTargetPanes converts the extra panes of a run target in a config update.
+func configTargetPanes(panes []contracts.TargetPane) []config.TargetPane {
+	if len(panes) == 0 {
+		return nil
+	}
+	result := make([]config.TargetPane, len(panes))
+	for i, pane := range panes {
+		result[i] = config.TargetPane{Command: strings.TrimSpace(pane.Command), Split: strings.TrimSpace(pane.Split), Size: pane.Size}
+	}
+	return result
+}
+
 // handleConfigGet returns the current config.
 func (s *Server) handleConfigGet(w http.ResponseWriter, r *http.Request) {
 	repos := s.config.GetRepos()
@@ -1057,12 +1964,15 @@ func (s *Server) handleConfigGet(w http.ResponseWriter, r *http.Request) {
 	width, height := s.config.GetTerminalSize()
 	seedLines := s.config.GetTerminalSeedLines()
 	bootstrapLines := s.config.GetTerminalBootstrapLines()
+	bootstrapMaxKB := s.config.GetTerminalBootstrapMaxBytes() / 1024
+	outputIntervalMs := int(s.config.GetTerminalOutputInterval() / time.Millisecond)
+	outputMaxKB := s.config.GetTerminalOutputMaxBytes() / 1024
 
 	// Build repo response with default branch from cache
 	ctx := r.Context()
 	repoResp := make([]contracts.RepoWithConfig, len(repos))
 	for i, repo := range repos {
-		resp := contracts.RepoWithConfig{Name: repo.Name, URL: repo.URL}
+		resp := contracts.RepoWithConfig{Name: repo.Name, URL: repo.URL, SSHKeyPath: repo.SSHKeyPath, BranchSuggest: repo.BranchSuggest, BranchPrefixRegex: repo.BranchPrefixRegex, PreDisposeCommand: repo.PreDisposeCommand, CloneFilter: repo.CloneFilter}
 		// Try to get default branch from cache (omit if not detected)
 		if defaultBranch, err := s.workspace.GetDefaultBranch(ctx, repo.URL); err == nil {
 			resp.DefaultBranch = defaultBranch
@@ -1074,10 +1984,15 @@ func (s *Server) handleConfigGet(w http.ResponseWriter, r *http.Request) {
 	seenTargets := make(map[string]struct{}, len(runTargets))
 	for _, target := range runTargets {
 		runTargetResp = append(runTargetResp, contracts.RunTarget{
-			Name:    target.Name,
-			Type:    target.Type,
-			Command: target.Command,
-			Source:  target.Source,
+			Name:          target.Name,
+			Type:          target.Type,
+			Command:       target.Command,
+			Source:        target.Source,
+			Shell:         target.Shell,
+			TmuxOptions:   target.TmuxOptions,
+			Panes:         contractTargetPanes(target.Panes),
+			Layout:        target.Layout,
+			OnExitCommand: target.OnExitCommand,
 		})
 		seenTargets[target.Name] = struct{}{}
 	}
@@ -1086,16 +2001,31 @@ func (s *Server) handleConfigGet(w http.ResponseWriter, r *http.Request) {
 		quickLaunchResp[i] = contracts.QuickLaunch{Name: preset.Name, Command: preset.Command, Target: preset.Target, Prompt: preset.Prompt}
 	}
 
+	defaultModels := make(map[string]string, len(s.config.DefaultModels))
+	for tool, model := range s.config.DefaultModels {
+		defaultModels[tool] = model
+	}
+	var defaultSpawnResp *contracts.DefaultSpawn
+	if defaultSpawn := s.config.GetDefaultSpawn(); defaultSpawn != nil {
+		defaultSpawnResp = &contracts.DefaultSpawn{Targets: defaultSpawn.Targets, Nickname: defaultSpawn.Nickname}
+	}
+
 	externalDiffCommands := s.config.GetExternalDiffCommands()
 	externalDiffCommandsResp := make([]contracts.ExternalDiffCommand, len(externalDiffCommands))
 	for i, cmd := range externalDiffCommands {
 		externalDiffCommandsResp[i] = contracts.ExternalDiffCommand{Name: cmd.Name, Command: cmd.Command}
 	}
 
+	notificationSounds := s.config.GetNotificationSounds()
+	notificationSoundsResp := make(map[string]contracts.NotificationSound, len(notificationSounds))
+	for state, sound := range notificationSounds {
+		notificationSoundsResp[state] = contracts.NotificationSound{Enabled: sound.Enabled, Sound: sound.Sound}
+	}
+
 	// Build models list with full metadata
-	models, err := buildAvailableModels(s.config)
+	models, err := s.models.get(s.config)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to read models: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to read models: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -1123,30 +2053,69 @@ func (s *Server) handleConfigGet(w http.ResponseWriter, r *http.Request) {
 	response := contracts.ConfigResponse{
 		WorkspacePath:              s.config.GetWorkspacePath(),
 		SourceCodeManagement:       s.config.GetSourceCodeManagement(),
+		PruneBaseRepos:             s.config.GetPruneBaseRepos(),
 		Repos:                      repoResp,
 		RunTargets:                 runTargetResp,
 		QuickLaunch:                quickLaunchResp,
+		DefaultModels:              defaultModels,
+		DefaultSpawn:               defaultSpawnResp,
 		ExternalDiffCommands:       externalDiffCommandsResp,
 		ExternalDiffCleanupAfterMs: s.config.GetExternalDiffCleanupAfterMs(),
 		Models:                     models,
-		Terminal:                   contracts.Terminal{Width: width, Height: height, SeedLines: seedLines, BootstrapLines: bootstrapLines},
+		Terminal:                   contracts.Terminal{Width: width, Height: height, SeedLines: seedLines, BootstrapLines: bootstrapLines, BootstrapMaxKB: bootstrapMaxKB, OutputIntervalMs: outputIntervalMs, OutputMaxKB: outputMaxKB},
 		Nudgenik: contracts.Nudgenik{
 			Target:         s.config.GetNudgenikTarget(),
 			ViewedBufferMs: s.config.GetNudgenikViewedBufferMs(),
 			SeenIntervalMs: s.config.GetNudgenikSeenIntervalMs(),
+			MaxConcurrent:  s.config.GetNudgenikMaxConcurrent(),
+			TimeoutMs:      s.config.GetNudgenikTimeoutMs(),
 		},
 		BranchSuggest: contracts.BranchSuggest{
-			Target: s.config.GetBranchSuggestTarget(),
+			Target:         s.config.GetBranchSuggestTarget(),
+			DefaultEnabled: s.config.GetBranchSuggestDefaultEnabled(),
 		},
 		ConflictResolve: contracts.ConflictResolve{
-			Target:    s.config.GetConflictResolveTarget(),
-			TimeoutMs: s.config.GetConflictResolveTimeoutMs(),
+			Target:               s.config.GetConflictResolveTarget(),
+			TimeoutMs:            s.config.GetConflictResolveTimeoutMs(),
+			MinConfidence:        s.config.GetConflictResolveMinConfidence(),
+			ReviewBelowThreshold: s.config.GetConflictResolveReviewBelowThreshold(),
 		},
 		Sessions: contracts.Sessions{
 			DashboardPollIntervalMs: s.config.GetDashboardPollIntervalMs(),
+			DashboardPingIntervalMs: s.config.GetDashboardPingIntervalMs(),
 			GitStatusPollIntervalMs: s.config.GetGitStatusPollIntervalMs(),
 			GitCloneTimeoutMs:       s.config.GetGitCloneTimeoutMs(),
 			GitStatusTimeoutMs:      s.config.GetGitStatusTimeoutMs(),
+			GitFetchTimeoutMs:       s.config.GetGitFetchTimeoutMs(),
+			GitDiffTimeoutMs:        s.config.GetGitDiffTimeoutMs(),
+			TmuxSocketName:          s.config.GetTmuxSocketName(),
+			MaxPromptBytes:          s.config.GetMaxPromptBytes(),
+			MaxWorkspacesPerRepo:    s.config.GetMaxWorkspacesPerRepo(),
+			MaxPerWorkspace:         s.config.GetMaxSessionsPerWorkspace(),
+			UnavailableTargetPolicy: s.config.GetUnavailableTargetPolicy(),
+			GitHTTPProxy:            s.config.GetGitHTTPProxy(),
+			GitAuthorName:           s.config.GetGitAuthorName(),
+			GitAuthorEmail:          s.config.GetGitAuthorEmail(),
+			GitSignCommits:          s.config.GetGitSignCommits(),
+			GitSigningKey:           s.config.GetGitSigningKey(),
+			NiceLevel:               s.config.GetNiceLevel(),
+			IoniceClass:             s.config.GetIoniceClass(),
+			SandboxWrapper:          s.config.GetSandboxWrapper(),
+			ProtectedBranches:       s.config.GetProtectedBranches(),
+			SyncCommitTemplate:      s.config.GetSyncCommitTemplate(),
+			DisposeIgnoreGlobs:      s.config.GetDisposeIgnoreGlobs(),
+			HistoryEnabled:          s.config.GetHistoryEnabled(),
+			HistoryRecordPrompts:    s.config.GetHistoryRecordPrompts(),
+			PromptHistorySize:       s.config.GetPromptHistorySize(),
+			WorkspaceEnvFile:        s.config.GetWorkspaceEnvFile(),
+			Offline:                 s.config.GetOffline(),
+			NicknameCollision:       s.config.GetNicknameCollision(),
+			BranchGonePolicy:        s.config.GetBranchGonePolicy(),
+			AutoRestartTargets:      s.config.GetAutoRestartTargets(),
+			AutoRestartMaxRetries:   s.config.GetAutoRestartMaxRetries(),
+			AutoRestartBackoffMs:    s.config.GetAutoRestartBackoffMs(),
+			AutoRestartDisabled:     s.config.GetAutoRestartDisabled(),
+			FailedRemoteGraceMs:     s.config.GetFailedRemoteGraceMs(),
 		},
 		Xterm: contracts.Xterm{
 			MtimePollIntervalMs: s.config.GetXtermMtimePollIntervalMs(),
@@ -1154,23 +2123,30 @@ func (s *Server) handleConfigGet(w http.ResponseWriter, r *http.Request) {
 			OperationTimeoutMs:  s.config.GetXtermOperationTimeoutMs(),
 			MaxLogSizeMB:        int(s.config.GetXtermMaxLogSizeMB()),
 			RotatedLogSizeMB:    int(s.config.GetXtermRotatedLogSizeMB()),
+			RetainAfterDispose:  s.config.GetXtermRetainAfterDispose(),
 		},
 		Network: contracts.Network{
-			BindAddress:   s.config.GetBindAddress(),
-			Port:          s.config.GetPort(),
-			PublicBaseURL: s.config.GetPublicBaseURL(),
-			TLS:           buildTLS(s.config),
+			BindAddress:      s.config.GetBindAddress(),
+			BindAddresses:    s.config.GetBindAddresses(),
+			Port:             s.config.GetPort(),
+			PublicBaseURL:    s.config.GetPublicBaseURL(),
+			TLS:              buildTLS(s.config),
+			WSAllowedOrigins: s.config.GetWSAllowedOrigins(),
 		},
 		AccessControl: contracts.AccessControl{
 			Enabled:           s.config.GetAuthEnabled(),
 			Provider:          s.config.GetAuthProvider(),
 			SessionTTLMinutes: s.config.GetAuthSessionTTLMinutes(),
+			AllowSelfUpdate:   s.config.GetAllowSelfUpdate(),
+			CommandAllowlist:  s.config.GetCommandAllowlist(),
+			ReadOnly:          s.config.GetReadOnly(),
 		},
 		PrReview: contracts.PrReview{
 			Target: s.config.GetPrReviewTarget(),
 		},
 		Notifications: contracts.Notifications{
 			SoundDisabled: !s.config.GetNotificationSoundEnabled(),
+			Sounds:        notificationSoundsResp,
 		},
 		NeedsRestart: s.state.GetNeedsRestart(),
 	}
@@ -1184,14 +2160,14 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 	var req contracts.ConfigUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		fmt.Printf("[config] invalid JSON payload: %v\n", err)
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
 
 	// Reload config from disk to get all current values (including tools, etc.)
 	if err := s.config.Reload(); err != nil {
 		fmt.Printf("[config] failed to reload config: %v\n", err)
-		http.Error(w, fmt.Sprintf("Failed to reload config: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to reload config: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -1199,6 +2175,8 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 	oldNetwork := cloneNetwork(cfg.Network)
 	oldAccessControl := cloneAccessControl(cfg.AccessControl)
 	oldRepos := cfg.GetRepos()
+	oldTmuxSocketName := cfg.GetTmuxSocketName()
+	oldPollIntervals := s.clientPollIntervals()
 
 	// Check for workspace path change (for warning after save)
 	sessionCount := len(s.state.GetSessions())
@@ -1221,47 +2199,81 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 	if req.SourceCodeManagement != nil {
 		scm := *req.SourceCodeManagement
 		if scm != "" && scm != config.SourceCodeManagementGit && scm != config.SourceCodeManagementGitWorktree {
-			http.Error(w, fmt.Sprintf("invalid source_code_management: %q (must be %q or %q)",
+			writeJSONError(w, fmt.Sprintf("invalid source_code_management: %q (must be %q or %q)",
 				scm, config.SourceCodeManagementGit, config.SourceCodeManagementGitWorktree), http.StatusBadRequest)
 			return
 		}
 		cfg.SourceCodeManagement = scm
 	}
 
+	if req.PruneBaseRepos != nil {
+		cfg.PruneBaseRepos = *req.PruneBaseRepos
+	}
+
 	if req.Repos != nil {
 		// Validate repos
 		for _, repo := range req.Repos {
 			if repo.Name == "" {
-				http.Error(w, "repo name is required", http.StatusBadRequest)
+				writeJSONError(w, "repo name is required", http.StatusBadRequest)
 				return
 			}
 			if repo.URL == "" {
-				http.Error(w, fmt.Sprintf("repo URL is required for %s", repo.Name), http.StatusBadRequest)
+				writeJSONError(w, fmt.Sprintf("repo URL is required for %s", repo.Name), http.StatusBadRequest)
+				return
+			}
+			if filter := strings.TrimSpace(repo.CloneFilter); filter != "" && !config.ValidCloneFilter(filter) {
+				writeJSONError(w, fmt.Sprintf("invalid clone_filter for %s: %q (use blob:none, blob:limit=<size>, or tree:<depth>)", repo.Name, filter), http.StatusBadRequest)
 				return
 			}
+			if command := strings.TrimSpace(repo.PreDisposeCommand); command != "" && !s.config.IsCommandAllowed(command) {
+				writeJSONErrorCode(w, fmt.Sprintf("pre_dispose_command %q for %s is not permitted by access_control.command_allowlist", command, repo.Name), "command_not_allowed", http.StatusForbidden)
+				return
+			}
+		}
+		// Workspaces reference repos by URL, so URLs that are already configured are kept
+		// verbatim; only new or edited URLs are normalized.
+		existingURLs := make(map[string]bool, len(oldRepos))
+		for _, repo := range oldRepos {
+			existingURLs[repo.URL] = true
 		}
 		cfg.Repos = make([]config.Repo, len(req.Repos))
 		for i, r := range req.Repos {
-			cfg.Repos[i] = config.Repo{Name: r.Name, URL: r.URL}
+			repoURL := r.URL
+			if !existingURLs[repoURL] {
+				normalized, err := workspace.NormalizeRepoURL(repoURL)
+				if err != nil {
+					writeJSONError(w, fmt.Sprintf("invalid repo URL for %s: %v", r.Name, err), http.StatusBadRequest)
+					return
+				}
+				repoURL = normalized
+			}
+			repo := config.Repo{Name: r.Name, URL: repoURL, SSHKeyPath: strings.TrimSpace(r.SSHKeyPath), BranchSuggest: r.BranchSuggest, BranchPrefixRegex: strings.TrimSpace(r.BranchPrefixRegex), PreDisposeCommand: strings.TrimSpace(r.PreDisposeCommand), CloneFilter: strings.TrimSpace(r.CloneFilter)}
+			if keyPath := repo.ResolvedSSHKeyPath(); keyPath != "" {
+				if info, err := os.Stat(keyPath); err != nil || info.IsDir() {
+					writeJSONError(w, fmt.Sprintf("ssh key not found for %s: %s", r.Name, repo.SSHKeyPath), http.StatusBadRequest)
+					return
+				}
+			}
+			cfg.Repos[i] = repo
 		}
 	}
 
 	if req.RunTargets != nil {
 		for _, target := range req.RunTargets {
 			if target.Name == "" {
-				http.Error(w, "run target name is required", http.StatusBadRequest)
+				writeJSONError(w, "run target name is required", http.StatusBadRequest)
 				return
 			}
 			if target.Command == "" {
-				http.Error(w, fmt.Sprintf("run target command is required for %s", target.Name), http.StatusBadRequest)
+				writeJSONError(w, fmt.Sprintf("run target command is required for %s", target.Name), http.StatusBadRequest)
 				return
 			}
 			if target.Source == config.RunTargetSourceDetected {
-				http.Error(w, fmt.Sprintf("run target %s cannot be marked as detected", target.Name), http.StatusBadRequest)
+				writeJSONError(w, fmt.Sprintf("run target %s cannot be marked as detected", target.Name), http.StatusBadRequest)
 				return
 			}
 			if target.Source != "" && target.Source != config.RunTargetSourceUser {
-				http.Error(w, fmt.Sprintf("run target %s has invalid source %q", target.Name, target.Source), http.StatusBadRequest)
+				writeJSONError(w, fmt.Sprintf("run target %s has invalid source %q", target.Name, target.Source), http.StatusBadRequest)
 				return
 			}
 		}
@@ -1271,7 +2283,7 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 			if source == "" {
 				source = config.RunTargetSourceUser
 			}
-			userTargets[i] = config.RunTarget{Name: t.Name, Type: t.Type, Command: t.Command, Source: source}
+			userTargets[i] = config.RunTarget{Name: t.Name, Type: t.Type, Command: t.Command, Source: source, Shell: t.Shell, TmuxOptions: t.TmuxOptions, Panes: configTargetPanes(t.Panes), Layout: strings.TrimSpace(t.Layout), OnExitCommand: strings.TrimSpace(t.OnExitCommand)}
 		}
 		detectedTools := config.DetectedToolsFromConfig(cfg)
 		cfg.RunTargets = config.MergeDetectedRunTargets(userTargets, detectedTools)
@@ -1284,6 +2296,26 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if req.DefaultModels != nil {
+		cfg.DefaultModels = nil
+		for tool, model := range req.DefaultModels {
+			if model = strings.TrimSpace(model); model != "" {
+				if cfg.DefaultModels == nil {
+					cfg.DefaultModels = make(map[string]string)
+				}
+				cfg.DefaultModels[tool] = model
+			}
+		}
+	}
+
+	if req.DefaultSpawn != nil {
+		if len(req.DefaultSpawn.Targets) == 0 {
+			cfg.DefaultSpawn = nil
+		} else {
+			cfg.DefaultSpawn = &config.DefaultSpawnConfig{Targets: req.DefaultSpawn.Targets, Nickname: strings.TrimSpace(req.DefaultSpawn.Nickname)}
+		}
+	}
+
 	if req.ExternalDiffCommands != nil {
 		cfg.ExternalDiffCommands = make([]config.ExternalDiffCommand, len(req.ExternalDiffCommands))
 		for i, c := range req.ExternalDiffCommands {
@@ -1293,7 +2325,7 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 
 	if req.ExternalDiffCleanupAfterMs != nil {
 		if *req.ExternalDiffCleanupAfterMs <= 0 {
-			http.Error(w, "external diff cleanup delay must be > 0", http.StatusBadRequest)
+			writeJSONError(w, "external diff cleanup delay must be > 0", http.StatusBadRequest)
 			return
 		}
 		cfg.ExternalDiffCleanupAfterMs = *req.ExternalDiffCleanupAfterMs
@@ -1313,7 +2345,14 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.Nudgenik.SeenIntervalMs != nil && *req.Nudgenik.SeenIntervalMs > 0 {
 			cfg.Nudgenik.SeenIntervalMs = *req.Nudgenik.SeenIntervalMs
 		}
-		if cfg.Nudgenik.Target == "" && cfg.Nudgenik.ViewedBufferMs <= 0 && cfg.Nudgenik.SeenIntervalMs <= 0 {
+		if req.Nudgenik.MaxConcurrent != nil && *req.Nudgenik.MaxConcurrent > 0 {
+			cfg.Nudgenik.MaxConcurrent = *req.Nudgenik.MaxConcurrent
+		}
+		if req.Nudgenik.TimeoutMs != nil && *req.Nudgenik.TimeoutMs > 0 {
+			cfg.Nudgenik.TimeoutMs = *req.Nudgenik.TimeoutMs
+		}
+		if cfg.Nudgenik.Target == "" && cfg.Nudgenik.ViewedBufferMs <= 0 && cfg.Nudgenik.SeenIntervalMs <= 0 &&
+			cfg.Nudgenik.MaxConcurrent <= 0 && cfg.Nudgenik.TimeoutMs <= 0 {
 			cfg.Nudgenik = nil
 		}
 	}
@@ -1325,7 +2364,13 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.BranchSuggest.Target != nil {
 			cfg.BranchSuggest.Target = strings.TrimSpace(*req.BranchSuggest.Target)
 		}
-		if cfg.BranchSuggest.Target == "" {
+		if req.BranchSuggest.DefaultEnabled != nil {
+			cfg.BranchSuggest.DefaultEnabled = nil // true is the default
+			if !*req.BranchSuggest.DefaultEnabled {
+				cfg.BranchSuggest.DefaultEnabled = req.BranchSuggest.DefaultEnabled
+			}
+		}
+		if cfg.BranchSuggest.Target == "" && cfg.BranchSuggest.DefaultEnabled == nil {
 			cfg.BranchSuggest = nil
 		}
 	}
@@ -1340,7 +2385,13 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.ConflictResolve.TimeoutMs != nil && *req.ConflictResolve.TimeoutMs > 0 {
 			cfg.ConflictResolve.TimeoutMs = *req.ConflictResolve.TimeoutMs
 		}
-		if cfg.ConflictResolve.Target == "" && cfg.ConflictResolve.TimeoutMs <= 0 {
+		if req.ConflictResolve.MinConfidence != nil {
+			cfg.ConflictResolve.MinConfidence = strings.TrimSpace(*req.ConflictResolve.MinConfidence)
+		}
+		if req.ConflictResolve.ReviewBelowThreshold != nil {
+			cfg.ConflictResolve.ReviewBelowThreshold = *req.ConflictResolve.ReviewBelowThreshold
+		}
+		if cfg.ConflictResolve.Target == "" && cfg.ConflictResolve.TimeoutMs <= 0 && cfg.ConflictResolve.MinConfidence == "" && !cfg.ConflictResolve.ReviewBelowThreshold {
 			cfg.ConflictResolve = nil
 		}
 	}
@@ -1361,6 +2412,15 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.Terminal.BootstrapLines != nil && *req.Terminal.BootstrapLines > 0 {
 			cfg.Terminal.BootstrapLines = *req.Terminal.BootstrapLines
 		}
+		if req.Terminal.BootstrapMaxKB != nil && *req.Terminal.BootstrapMaxKB > 0 {
+			cfg.Terminal.BootstrapMaxKB = *req.Terminal.BootstrapMaxKB
+		}
+		if req.Terminal.OutputIntervalMs != nil && *req.Terminal.OutputIntervalMs > 0 {
+			cfg.Terminal.OutputIntervalMs = *req.Terminal.OutputIntervalMs
+		}
+		if req.Terminal.OutputMaxKB != nil && *req.Terminal.OutputMaxKB > 0 {
+			cfg.Terminal.OutputMaxKB = *req.Terminal.OutputMaxKB
+		}
 	}
 
 	if req.Sessions != nil {
@@ -1370,6 +2430,9 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.Sessions.DashboardPollIntervalMs != nil && *req.Sessions.DashboardPollIntervalMs > 0 {
 			cfg.Sessions.DashboardPollIntervalMs = *req.Sessions.DashboardPollIntervalMs
 		}
+		if req.Sessions.DashboardPingIntervalMs != nil && *req.Sessions.DashboardPingIntervalMs > 0 {
+			cfg.Sessions.DashboardPingIntervalMs = *req.Sessions.DashboardPingIntervalMs
+		}
 		if req.Sessions.GitStatusPollIntervalMs != nil && *req.Sessions.GitStatusPollIntervalMs > 0 {
 			cfg.Sessions.GitStatusPollIntervalMs = *req.Sessions.GitStatusPollIntervalMs
 		}
@@ -1379,6 +2442,108 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.Sessions.GitStatusTimeoutMs != nil && *req.Sessions.GitStatusTimeoutMs > 0 {
 			cfg.Sessions.GitStatusTimeoutMs = *req.Sessions.GitStatusTimeoutMs
 		}
+		if req.Sessions.GitFetchTimeoutMs != nil && *req.Sessions.GitFetchTimeoutMs > 0 {
+			cfg.Sessions.GitFetchTimeoutMs = *req.Sessions.GitFetchTimeoutMs
+		}
+		if req.Sessions.GitDiffTimeoutMs != nil && *req.Sessions.GitDiffTimeoutMs > 0 {
+			cfg.Sessions.GitDiffTimeoutMs = *req.Sessions.GitDiffTimeoutMs
+		}
+		if req.Sessions.MaxPromptBytes != nil && *req.Sessions.MaxPromptBytes > 0 {
+			cfg.Sessions.MaxPromptBytes = *req.Sessions.MaxPromptBytes
+		}
+		if req.Sessions.MaxWorkspacesPerRepo != nil && *req.Sessions.MaxWorkspacesPerRepo > 0 {
+			cfg.Sessions.MaxWorkspacesPerRepo = *req.Sessions.MaxWorkspacesPerRepo
+		}
+		if req.Sessions.MaxPerWorkspace != nil && *req.Sessions.MaxPerWorkspace >= 0 {
+			cfg.Sessions.MaxPerWorkspace = *req.Sessions.MaxPerWorkspace
+		}
+		if req.Sessions.TmuxSocketName != nil {
+			cfg.Sessions.TmuxSocketName = strings.TrimSpace(*req.Sessions.TmuxSocketName)
+		}
+		if req.Sessions.UnavailableTargetPolicy != nil {
+			cfg.Sessions.UnavailableTargetPolicy = strings.TrimSpace(*req.Sessions.UnavailableTargetPolicy)
+		}
+		if req.Sessions.GitHTTPProxy != nil {
+			cfg.Sessions.GitHTTPProxy = strings.TrimSpace(*req.Sessions.GitHTTPProxy)
+		}
+		if req.Sessions.GitAuthorName != nil {
+			cfg.Sessions.GitAuthorName = strings.TrimSpace(*req.Sessions.GitAuthorName)
+		}
+		if req.Sessions.GitAuthorEmail != nil {
+			cfg.Sessions.GitAuthorEmail = strings.TrimSpace(*req.Sessions.GitAuthorEmail)
+		}
+		if req.Sessions.GitSignCommits != nil {
+			cfg.Sessions.GitSignCommits = *req.Sessions.GitSignCommits
+		}
+		if req.Sessions.GitSigningKey != nil {
+			cfg.Sessions.GitSigningKey = strings.TrimSpace(*req.Sessions.GitSigningKey)
+		}
+		if req.Sessions.NiceLevel != nil {
+			cfg.Sessions.NiceLevel = *req.Sessions.NiceLevel
+		}
+		if req.Sessions.IoniceClass != nil {
+			cfg.Sessions.IoniceClass = strings.TrimSpace(*req.Sessions.IoniceClass)
+		}
+		if req.Sessions.SyncCommitTemplate != nil {
+			cfg.Sessions.SyncCommitTemplate = strings.TrimSpace(*req.Sessions.SyncCommitTemplate)
+		}
+		if req.Sessions.WorkspaceEnvFile != nil {
+			cfg.Sessions.WorkspaceEnvFile = strings.TrimSpace(*req.Sessions.WorkspaceEnvFile)
+		}
+		if req.Sessions.HistoryEnabled != nil {
+			cfg.Sessions.HistoryEnabled = *req.Sessions.HistoryEnabled
+		}
+		if req.Sessions.HistoryRecordPrompts != nil {
+			cfg.Sessions.HistoryRecordPrompts = *req.Sessions.HistoryRecordPrompts
+		}
+		if req.Sessions.PromptHistorySize != nil {
+			cfg.Sessions.PromptHistorySize = *req.Sessions.PromptHistorySize
+		}
+		if req.Sessions.Offline != nil {
+			cfg.Sessions.Offline = *req.Sessions.Offline
+		}
+		if req.Sessions.NicknameCollision != nil {
+			cfg.Sessions.NicknameCollision = strings.TrimSpace(*req.Sessions.NicknameCollision)
+		}
+		if req.Sessions.BranchGonePolicy != nil {
+			cfg.Sessions.BranchGonePolicy = strings.TrimSpace(*req.Sessions.BranchGonePolicy)
+		}
+		if req.Sessions.DisposeIgnoreGlobs != nil {
+			cfg.Sessions.DisposeIgnoreGlobs = nil
+			for _, pattern := range req.Sessions.DisposeIgnoreGlobs {
+				if pattern = strings.TrimSpace(pattern); pattern != "" {
+					cfg.Sessions.DisposeIgnoreGlobs = append(cfg.Sessions.DisposeIgnoreGlobs, pattern)
+				}
+			}
+		}
+		if req.Sessions.ProtectedBranches != nil {
+			cfg.Sessions.ProtectedBranches = nil
+			for _, pattern := range req.Sessions.ProtectedBranches {
+				if pattern = strings.TrimSpace(pattern); pattern != "" {
+					cfg.Sessions.ProtectedBranches = append(cfg.Sessions.ProtectedBranches, pattern)
+				}
+			}
+		}
+		if req.Sessions.AutoRestartTargets != nil {
+			cfg.Sessions.AutoRestartTargets = nil
+			for _, target := range req.Sessions.AutoRestartTargets {
+				if target = strings.TrimSpace(target); target != "" {
+					cfg.Sessions.AutoRestartTargets = append(cfg.Sessions.AutoRestartTargets, target)
+				}
+			}
+		}
+		if req.Sessions.AutoRestartMaxRetries != nil && *req.Sessions.AutoRestartMaxRetries > 0 {
+			cfg.Sessions.AutoRestartMaxRetries = *req.Sessions.AutoRestartMaxRetries
+		}
+		if req.Sessions.AutoRestartBackoffMs != nil && *req.Sessions.AutoRestartBackoffMs > 0 {
+			cfg.Sessions.AutoRestartBackoffMs = *req.Sessions.AutoRestartBackoffMs
+		}
+		if req.Sessions.AutoRestartDisabled != nil {
+			cfg.Sessions.AutoRestartDisabled = *req.Sessions.AutoRestartDisabled
+		}
+		if req.Sessions.FailedRemoteGraceMs != nil && *req.Sessions.FailedRemoteGraceMs > 0 {
+			cfg.Sessions.FailedRemoteGraceMs = *req.Sessions.FailedRemoteGraceMs
+		}
 	}
 
 	if req.Xterm != nil {
@@ -1400,6 +2565,9 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.Xterm.RotatedLogSizeMB != nil && *req.Xterm.RotatedLogSizeMB > 0 {
 			cfg.Xterm.RotatedLogSizeMB = *req.Xterm.RotatedLogSizeMB
 		}
+		if req.Xterm.RetainAfterDispose != nil {
+			cfg.Xterm.RetainAfterDispose = *req.Xterm.RetainAfterDispose
+		}
 	}
 
 	if req.Network != nil {
@@ -1409,12 +2577,28 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.Network.BindAddress != nil {
 			cfg.Network.BindAddress = *req.Network.BindAddress
 		}
+		if req.Network.BindAddresses != nil {
+			cfg.Network.BindAddresses = nil
+			for _, addr := range req.Network.BindAddresses {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					cfg.Network.BindAddresses = append(cfg.Network.BindAddresses, addr)
+				}
+			}
+		}
 		if req.Network.Port != nil && *req.Network.Port > 0 {
 			cfg.Network.Port = *req.Network.Port
 		}
 		if req.Network.PublicBaseURL != nil {
 			cfg.Network.PublicBaseURL = *req.Network.PublicBaseURL
 		}
+		if req.Network.WSAllowedOrigins != nil {
+			cfg.Network.WSAllowedOrigins = nil
+			for _, origin := range req.Network.WSAllowedOrigins {
+				if origin = strings.TrimSpace(origin); origin != "" {
+					cfg.Network.WSAllowedOrigins = append(cfg.Network.WSAllowedOrigins, origin)
+				}
+			}
+		}
 		if req.Network.TLS != nil {
 			if cfg.Network.TLS == nil {
 				cfg.Network.TLS = &config.TLSConfig{}
@@ -1459,16 +2643,30 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		if req.Notifications.SoundDisabled != nil {
 			cfg.Notifications.SoundDisabled = *req.Notifications.SoundDisabled
 		}
+		if req.Notifications.Sounds != nil {
+			cfg.Notifications.Sounds = make(map[string]config.NotificationSound, len(req.Notifications.Sounds))
+			for state, sound := range req.Notifications.Sounds {
+				cfg.Notifications.Sounds[state] = config.NotificationSound{Enabled: sound.Enabled, Sound: sound.Sound}
+			}
+		}
 	}
 
 	warnings, err := cfg.ValidateForSave()
 	if err != nil {
 		fmt.Printf("[config] validation error: %v\n", err)
-		http.Error(w, fmt.Sprintf("Invalid config: %v", err), http.StatusBadRequest)
+		writeJSONError(w, fmt.Sprintf("Invalid config: %v", err), http.StatusBadRequest)
 		return
 	}
+	if cfg.GetGitSignCommits() {
+		// Repos can override the global git config, so this is a warning; the
+		// per-repo check runs before each signed commit.
+		if err := workspace.CheckCommitSigning(r.Context(), "", cfg.GetGitSigningKey()); err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
 
-	if !reflect.DeepEqual(oldNetwork, cfg.Network) || !reflect.DeepEqual(oldAccessControl, cfg.AccessControl) {
+	if networkNeedsRestart(oldNetwork, cfg.Network) || !reflect.DeepEqual(oldAccessControl, cfg.AccessControl) ||
+		oldTmuxSocketName != cfg.GetTmuxSocketName() {
 		s.state.SetNeedsRestart(true)
 		s.state.Save()
 	}
@@ -1476,9 +2674,14 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 	// Save config
 	if err := cfg.Save(); err != nil {
 		fmt.Printf("[config] failed to save config: %v\n", err)
-		http.Error(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
 		return
 	}
+	s.models.invalidate()
+
+	if s.clientPollIntervals() != oldPollIntervals {
+		go s.BroadcastConfig()
+	}
 
 	// Update PR discovery polling based on new config
 	// Pass a function so poll always uses current repos list
@@ -1503,7 +2706,7 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 			Warnings        []string `json:"warnings,omitempty"`
 		}
 		warning := WarningResponse{
-			Warning:         fmt.Sprintf("Changing workspace_path affects only NEW workspaces. %d existing sessions and %d workspaces will keep their current paths.", sessionCount, workspaceCount),
+			Warning:         fmt.Sprintf("Changing workspace_path affects only NEW workspaces. %d existing sessions and %d workspaces will keep their current paths until moved with POST /api/workspaces/migrate.", sessionCount, workspaceCount),
 			SessionCount:    sessionCount,
 			WorkspaceCount:  workspaceCount,
 			RequiresRestart: true,
@@ -1527,13 +2730,96 @@ func (s *Server) handleConfigUpdate(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// FeatureTarget is the body and response of GET/PUT /api/{feature}/target.
+type FeatureTarget struct {
+	Target string `json:"target"` // empty turns the feature off
+}
+
+// handleNudgenikTarget reads or sets nudgenik.target.
+// GET/PUT /api/nudgenik/target
+func (s *Server) handleNudgenikTarget(w http.ResponseWriter, r *http.Request) {
+	s.handleFeatureTarget(w, r, "nudgenik", s.config.GetNudgenikTarget, func(target string) {
+		if s.config.Nudgenik == nil {
+			s.config.Nudgenik = &config.NudgenikConfig{}
+		}
+		s.config.Nudgenik.Target = target
+	})
+}
+
+// handleBranchSuggestTarget reads or sets branch_suggest.target.
+// GET/PUT /api/branch-suggest/target
+func (s *Server) handleBranchSuggestTarget(w http.ResponseWriter, r *http.Request) {
+	s.handleFeatureTarget(w, r, "branch_suggest", s.config.GetBranchSuggestTarget, func(target string) {
+		if s.config.BranchSuggest == nil {
+			s.config.BranchSuggest = &config.BranchSuggestConfig{}
+		}
+		s.config.BranchSuggest.Target = target
+	})
+}
+
+// handleConflictResolveTarget reads or sets conflict_resolve.target.
+// GET/PUT /api/conflict-resolve/target
+func (s *Server) handleConflictResolveTarget(w http.ResponseWriter, r *http.Request) {
+	s.handleFeatureTarget(w, r, "conflict_resolve", s.config.GetConflictResolveTarget, func(target string) {
+		if s.config.ConflictResolve == nil {
+			s.config.ConflictResolve = &config.ConflictResolveConfig{}
+		}
+		s.config.ConflictResolve.Target = target
+	})
+}
+
+// handleFeatureTarget reads or sets the target of one LLM feature without a full
+// config round-trip. A PUT reloads the config from disk and changes only that field,
+// so it can't clobber other settings edited in the meantime.
+func (s *Server) handleFeatureTarget(w http.ResponseWriter, r *http.Request, feature string, get func() string, set func(target string)) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req FeatureTarget
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		target := strings.TrimSpace(req.Target)
+		if target != "" && !s.config.IsPromptableTarget(target) {
+			writeJSONError(w, fmt.Sprintf("%s target must be a promptable target or model: %s", feature, target), http.StatusBadRequest)
+			return
+		}
+
+		if err := s.config.Reload(); err != nil {
+			fmt.Printf("[config] failed to reload config: %v\n", err)
+			writeJSONError(w, fmt.Sprintf("Failed to reload config: %v", err), http.StatusInternalServerError)
+			return
+		}
+		set(target)
+		if _, err := s.config.ValidateForSave(); err != nil {
+			fmt.Printf("[config] validation error: %v\n", err)
+			writeJSONError(w, fmt.Sprintf("Invalid config: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := s.config.Save(); err != nil {
+			fmt.Printf("[config] failed to save config: %v\n", err)
+			writeJSONError(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+			return
+		}
+		s.models.invalidate()
+		fmt.Printf("[config] %s target set to %q\n", feature, target)
+	default:
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(FeatureTarget{Target: get()})
+}
+
 // handleAuthSecrets gets or sets GitHub auth secrets.
 func (s *Server) handleAuthSecrets(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		secrets, err := config.GetAuthSecrets()
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to read secrets: %v", err), http.StatusInternalServerError)
+			writeJSONError(w, fmt.Sprintf("Failed to read secrets: %v", err), http.StatusInternalServerError)
 			return
 		}
 		clientIDSet := false
@@ -1554,33 +2840,33 @@ func (s *Server) handleAuthSecrets(w http.ResponseWriter, r *http.Request) {
 		}
 		var req SecretsRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 			return
 		}
 		if strings.TrimSpace(req.ClientID) == "" || strings.TrimSpace(req.ClientSecret) == "" {
-			http.Error(w, "client_id and client_secret are required", http.StatusBadRequest)
+			writeJSONError(w, "client_id and client_secret are required", http.StatusBadRequest)
 			return
 		}
 		if err := config.SaveGitHubAuthSecrets(req.ClientID, req.ClientSecret); err != nil {
-			http.Error(w, fmt.Sprintf("Failed to save secrets: %v", err), http.StatusInternalServerError)
+			writeJSONError(w, fmt.Sprintf("Failed to save secrets: %v", err), secretsWriteStatus(err))
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 	}
 }
 
 // handleModels lists available models.
 func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	resp, err := buildAvailableModels(s.config)
+	resp, err := s.models.get(s.config)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to read models: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to read models: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -1610,6 +2896,90 @@ func buildAvailableModels(cfg *config.Config) ([]contracts.Model, error) {
 	return resp, nil
 }
 
+// handleSpawnOptions returns every run target the spawn form can offer, with whether
+// it's promptable, available, and configured: GET /api/spawn-options
+// The response carries an ETag so clients can revalidate cheaply with If-None-Match.
+func (s *Server) handleSpawnOptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	models, err := s.models.get(s.config)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Failed to read models: %v", err), http.StatusInternalServerError)
+		return
+	}
+	resp, err := buildSpawnOptions(s.config, models)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Failed to read models: %v", err), http.StatusInternalServerError)
+		return
+	}
+	body, err := json.Marshal(resp)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Failed to encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// buildSpawnOptions lists the configured run targets followed by the available models
+// that aren't run targets themselves, sorted by name.
+func buildSpawnOptions(cfg *config.Config, models []contracts.Model) (contracts.SpawnOptionsResponse, error) {
+	detected := cfg.GetDetectedRunTargets()
+	modelsByID := make(map[string]contracts.Model, len(models))
+	modelsByTool := make(map[string][]string)
+	for _, model := range models {
+		modelsByID[model.ID] = model
+		modelsByTool[model.BaseTool] = append(modelsByTool[model.BaseTool], model.ID)
+	}
+
+	targets := []contracts.SpawnTarget{}
+	add := func(name, targetType, source string) {
+		promptable, available := config.IsTargetPromptable(cfg, detected, name)
+		target := contracts.SpawnTarget{
+			Name:       name,
+			Label:      name,
+			Type:       targetType,
+			Source:     source,
+			Promptable: promptable,
+			Available:  available,
+			Configured: true,
+		}
+		if source == config.RunTargetSourceDetected {
+			target.Models = modelsByTool[name]
+		}
+		if model, ok := modelsByID[name]; ok {
+			target.Label = model.DisplayName
+			target.Configured = model.Configured
+			target.BaseTool = model.BaseTool
+			target.RequiredSecrets = model.RequiredSecrets
+			target.UsageURL = model.UsageURL
+			delete(modelsByID, name)
+		}
+		targets = append(targets, target)
+	}
+	for _, target := range cfg.GetRunTargets() {
+		add(target.Name, target.Type, target.Source)
+	}
+	for _, model := range models {
+		if _, ok := modelsByID[model.ID]; ok {
+			add(model.ID, config.RunTargetTypePromptable, config.RunTargetSourceModel)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return contracts.SpawnOptionsResponse{Targets: targets}, nil
+}
+
 func buildTLS(cfg *config.Config) *contracts.TLS {
 	certPath := cfg.GetTLSCertPath()
 	keyPath := cfg.GetTLSKeyPath()
@@ -1622,11 +2992,27 @@ func buildTLS(cfg *config.Config) *contracts.TLS {
 	}
 }
 
+// networkNeedsRestart reports whether a network config change only takes effect on restart.
+// WebSocket origins are checked per request, so changing them alone doesn't count.
+func networkNeedsRestart(before, after *config.NetworkConfig) bool {
+	normalize := func(n *config.NetworkConfig) *config.NetworkConfig {
+		cpy := cloneNetwork(n)
+		if cpy == nil {
+			cpy = &config.NetworkConfig{}
+		}
+		cpy.WSAllowedOrigins = nil
+		return cpy
+	}
+	return !reflect.DeepEqual(normalize(before), normalize(after))
+}
+
 func cloneNetwork(src *config.NetworkConfig) *config.NetworkConfig {
 	if src == nil {
 		return nil
 	}
 	cpy := *src
+	cpy.BindAddresses = slices.Clone(src.BindAddresses)
+	cpy.WSAllowedOrigins = slices.Clone(src.WSAllowedOrigins)
 	if src.TLS != nil {
 		tlsCopy := *src.TLS
 		cpy.TLS = &tlsCopy
@@ -1648,7 +3034,7 @@ func reposEqual(a, b []config.Repo) bool {
 		return false
 	}
 	for i := range a {
-		if a[i].Name != b[i].Name || a[i].URL != b[i].URL {
+		if a[i].Name != b[i].Name || a[i].URL != b[i].URL || a[i].SSHKeyPath != b[i].SSHKeyPath {
 			return false
 		}
 	}
@@ -1660,7 +3046,7 @@ func (s *Server) handleModel(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/models/")
 	parts := strings.Split(path, "/")
 	if len(parts) < 2 {
-		http.Error(w, "model name and action required", http.StatusBadRequest)
+		writeJSONError(w, "model name and action required", http.StatusBadRequest)
 		return
 	}
 	name := parts[0]
@@ -1668,19 +3054,19 @@ func (s *Server) handleModel(w http.ResponseWriter, r *http.Request) {
 
 	model, ok := detect.FindModel(name)
 	if !ok {
-		http.Error(w, "model not found", http.StatusNotFound)
+		writeJSONError(w, "model not found", http.StatusNotFound)
 		return
 	}
 
 	switch action {
 	case "configured":
 		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 		configured, err := modelConfigured(model)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("Failed to read secrets: %v", err), http.StatusInternalServerError)
+			writeJSONError(w, fmt.Sprintf("Failed to read secrets: %v", err), http.StatusInternalServerError)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -1693,43 +3079,45 @@ func (s *Server) handleModel(w http.ResponseWriter, r *http.Request) {
 			}
 			var req SecretsRequest
 			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-				http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+				writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 				return
 			}
 			if err := validateModelSecrets(model, req.Secrets); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeJSONError(w, err.Error(), http.StatusBadRequest)
 				return
 			}
 			if err := config.SaveModelSecrets(model.ID, req.Secrets); err != nil {
-				http.Error(w, fmt.Sprintf("Failed to save secrets: %v", err), http.StatusInternalServerError)
+				writeJSONError(w, fmt.Sprintf("Failed to save secrets: %v", err), secretsWriteStatus(err))
 				return
 			}
+			s.models.invalidate()
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 		case http.MethodDelete:
 			if targetInUseByNudgenikOrQuickLaunch(s.config, model.ID) {
-				http.Error(w, "model is in use by nudgenik or quick launch", http.StatusBadRequest)
+				writeJSONError(w, "model is in use by nudgenik or quick launch", http.StatusBadRequest)
 				return
 			}
 			if model.Provider != "" && model.Provider != "anthropic" {
 				if err := config.DeleteProviderSecrets(model.Provider); err != nil {
-					http.Error(w, fmt.Sprintf("Failed to delete secrets: %v", err), http.StatusInternalServerError)
+					writeJSONError(w, fmt.Sprintf("Failed to delete secrets: %v", err), secretsWriteStatus(err))
 					return
 				}
 			} else {
 				if err := config.DeleteModelSecrets(model.ID); err != nil {
-					http.Error(w, fmt.Sprintf("Failed to delete secrets: %v", err), http.StatusInternalServerError)
+					writeJSONError(w, fmt.Sprintf("Failed to delete secrets: %v", err), secretsWriteStatus(err))
 					return
 				}
 			}
+			s.models.invalidate()
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 	default:
-		http.Error(w, "unknown model action", http.StatusNotFound)
+		writeJSONError(w, "unknown model action", http.StatusNotFound)
 	}
 }
 
@@ -1772,6 +3160,15 @@ func modelConfigured(model detect.Model) (bool, error) {
 	return true, nil
 }
 
+// secretsWriteStatus is the status for a failed secrets write: a read-only secrets
+// backend is a conflict with the config, not a server error.
+func secretsWriteStatus(err error) int {
+	if errors.Is(err, config.ErrSecretsReadOnly) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 func validateModelSecrets(model detect.Model, secrets map[string]string) error {
 	for _, key := range model.RequiredSecrets {
 		val := strings.TrimSpace(secrets[key])
@@ -1782,24 +3179,72 @@ func validateModelSecrets(model detect.Model, secrets map[string]string) error {
 	return nil
 }
 
+// diffAlgorithms are the values accepted for git diff --diff-algorithm.
+var diffAlgorithms = []string{"myers", "minimal", "patience", "histogram"}
+
+// diffOptionArgs turns the ignore_whitespace and algorithm query parameters of
+// GET /api/diff/{id} into git diff arguments. Unset parameters keep git's defaults.
+func diffOptionArgs(query url.Values) ([]string, error) {
+	var args []string
+	if v := query.Get("ignore_whitespace"); v != "" {
+		ignore, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore_whitespace %q: must be true or false", v)
+		}
+		if ignore {
+			args = append(args, "--ignore-all-space")
+		}
+	}
+	if v := query.Get("algorithm"); v != "" {
+		if !slices.Contains(diffAlgorithms, v) {
+			return nil, fmt.Errorf("invalid algorithm %q: must be one of %s", v, strings.Join(diffAlgorithms, ", "))
+		}
+		args = append(args, "--diff-algorithm="+v)
+	}
+	return args, nil
+}
+
+// filePatch returns the hunks of git diff HEAD for one file run with diffArgs,
+// without the file header lines. It returns "" when git fails.
+func (s *Server) filePatch(wsPath, filePath string, diffArgs []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.GitDiffTimeout())
+	defer cancel()
+	args := append([]string{"-C", wsPath, "diff", "HEAD", "--no-color", "--no-ext-diff"}, diffArgs...)
+	output, err := exec.CommandContext(ctx, "git", append(args, "--", filePath)...).Output()
+	if err != nil {
+		return ""
+	}
+	patch := string(output)
+	if i := strings.Index(patch, "\n@@"); i >= 0 {
+		return patch[i+1:]
+	}
+	return ""
+}
+
 // handleDiff returns git diff for a workspace.
 func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Extract workspace ID from URL: /api/diff/{workspace-id}
 	workspaceID := strings.TrimPrefix(r.URL.Path, "/api/diff/")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	diffArgs, err := diffOptionArgs(r.URL.Query())
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Get workspace from state
 	ws, found := s.state.GetWorkspace(workspaceID)
 	if !found {
-		http.Error(w, "workspace not found", http.StatusNotFound)
+		writeJSONError(w, "workspace not found", http.StatusNotFound)
 		return
 	}
 
@@ -1830,6 +3275,7 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 		LinesAdded   int    `json:"lines_added"`
 		LinesRemoved int    `json:"lines_removed"`
 		IsBinary     bool   `json:"is_binary"`
+		Patch        string `json:"patch,omitempty"` // git's hunks, set for modified files when diff options are given
 	}
 
 	type DiffResponse struct {
@@ -1843,8 +3289,10 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 	// --numstat shows: added/deleted lines filename
 	// HEAD compares against last commit (includes both staged and unstaged)
 	// --find-renames finds renames
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitStatusTimeoutMs())*time.Millisecond)
-	cmd := exec.CommandContext(ctx, "git", "-C", ws.Path, "diff", "HEAD", "--numstat", "--find-renames", "--diff-filter=ADM")
+	// diffArgs: --ignore-all-space drops whitespace-only files and lines from the counts
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.GitDiffTimeout())
+	args := append([]string{"-C", ws.Path, "diff", "HEAD", "--numstat", "--find-renames", "--diff-filter=ADM"}, diffArgs...)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	output, err := cmd.Output()
 	cancel()
 	if err != nil {
@@ -1881,9 +3329,10 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 
 		if isBinary {
 			status := "modified"
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitStatusTimeoutMs())*time.Millisecond)
-			oldExists := s.getFileContent(ctx, ws.Path, filePath, "HEAD") != ""
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.GitDiffTimeout())
+			oldContent, oldBinary := s.getFileContent(ctx, ws.Path, filePath, "HEAD")
 			cancel()
+			oldExists := oldContent != "" || oldBinary
 			if !oldExists {
 				status = "added"
 			}
@@ -1898,13 +3347,14 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 		// Skip if file was deleted (added is "-")
 		if addedStr == "-" && deletedStr != "-" {
 			// For deleted files, get old content
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitStatusTimeoutMs())*time.Millisecond)
-			oldContent := s.getFileContent(ctx, ws.Path, filePath, "HEAD")
+			ctx, cancel := context.WithTimeout(context.Background(), s.config.GitDiffTimeout())
+			oldContent, oldBinary := s.getFileContent(ctx, ws.Path, filePath, "HEAD")
 			cancel()
 			files = append(files, FileDiff{
 				NewPath:      filePath,
 				OldContent:   oldContent,
 				Status:       "deleted",
+				IsBinary:     oldBinary,
 				LinesAdded:   linesAdded,
 				LinesRemoved: linesRemoved,
 			})
@@ -1912,16 +3362,33 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Check if file is new (deleted is "0" and file doesn't exist in HEAD)
-		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitStatusTimeoutMs())*time.Millisecond)
-		newContent := s.getFileContent(ctx, ws.Path, filePath, "worktree")
-		oldContent := s.getFileContent(ctx, ws.Path, filePath, "HEAD")
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.GitDiffTimeout())
+		newContent, newBinary := s.getFileContent(ctx, ws.Path, filePath, "worktree")
+		oldContent, oldBinary := s.getFileContent(ctx, ws.Path, filePath, "HEAD")
 		cancel()
 
 		status := "modified"
-		if oldContent == "" {
+		if oldContent == "" && !oldBinary {
 			status = "added"
 		}
 
+		// Too large or binary despite numstat: don't send a partial text diff.
+		if newBinary || oldBinary {
+			files = append(files, FileDiff{
+				NewPath:  filePath,
+				Status:   status,
+				IsBinary: true,
+			})
+			continue
+		}
+
+		// With diff options the dashboard renders git's own hunks, so what is shown
+		// matches the whitespace handling and algorithm used for the counts.
+		patch := ""
+		if len(diffArgs) > 0 && status == "modified" {
+			patch = s.filePatch(ws.Path, filePath, diffArgs)
+		}
+
 		files = append(files, FileDiff{
 			NewPath:      filePath,
 			OldContent:   oldContent,
@@ -1929,12 +3396,13 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 			Status:       status,
 			LinesAdded:   linesAdded,
 			LinesRemoved: linesRemoved,
+			Patch:        patch,
 		})
 	}
 
 	// Get untracked files
 	// ls-files --others --exclude-standard lists untracked files (respecting .gitignore)
-	ctx, cancel = context.WithTimeout(context.Background(), time.Duration(s.config.GetGitStatusTimeoutMs())*time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), s.config.GitDiffTimeout())
 	untrackedCmd := exec.CommandContext(ctx, "git", "-C", ws.Path, "ls-files", "--others", "--exclude-standard")
 	untrackedOutput, err := untrackedCmd.Output()
 	cancel()
@@ -1944,8 +3412,10 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 			if filePath == "" {
 				continue
 			}
-			// Check if file is binary using git's detection (with fast heuristic fallback)
-			if difftool.IsBinaryFile(ctx, ws.Path, filePath) {
+			// Get content of untracked file from working directory; binary and
+			// oversized files are detected before reading
+			newContent, binary := s.getFileContent(ctx, ws.Path, filePath, "worktree")
+			if binary {
 				files = append(files, FileDiff{
 					NewPath:  filePath,
 					Status:   "untracked",
@@ -1953,8 +3423,6 @@ func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
 				})
 				continue
 			}
-			// Get content of untracked file from working directory
-			newContent := s.getFileContent(ctx, ws.Path, filePath, "worktree")
 			// Count lines for untracked files (all lines are additions)
 			lineCount := 0
 			if newContent != "" {
@@ -1990,50 +3458,95 @@ type resolvedQuickLaunch struct {
 	Prompt  string
 }
 
-func (s *Server) resolveQuickLaunchByName(workspaceID, name string) (*resolvedQuickLaunch, error) {
+// resolveQuickLaunchByName looks up a quick launch preset (workspace presets first, then global)
+// and substitutes {{name}} placeholders in its command or prompt with the supplied values.
+func (s *Server) resolveQuickLaunchByName(workspaceID, name string, values map[string]string) (*resolvedQuickLaunch, error) {
 	if name == "" {
 		return nil, fmt.Errorf("quick_launch_name is required")
 	}
 	detected := s.config.GetDetectedRunTargets()
 	if wsCfg := s.workspace.GetWorkspaceConfig(workspaceID); wsCfg != nil {
-		if resolved := resolveQuickLaunchFromPresets(wsCfg.QuickLaunch, detected, s.config, name); resolved != nil {
-			return resolved, nil
+		if resolved, err := resolveQuickLaunchFromPresets(wsCfg.QuickLaunch, detected, s.config, name, values); resolved != nil || err != nil {
+			return resolved, err
 		}
 	}
-	if resolved := resolveQuickLaunchFromPresets(adaptQuickLaunch(s.config.GetQuickLaunch()), detected, s.config, name); resolved != nil {
-		return resolved, nil
+	if resolved, err := resolveQuickLaunchFromPresets(adaptQuickLaunch(s.config.GetQuickLaunch()), detected, s.config, name, values); resolved != nil || err != nil {
+		return resolved, err
 	}
 	return nil, fmt.Errorf("quick launch not found: %s", name)
 }
 
-func resolveQuickLaunchFromPresets(presets []contracts.QuickLaunch, detected []config.RunTarget, cfg *config.Config, name string) *resolvedQuickLaunch {
+// resolveQuickLaunchFromPresets returns the named preset with its variables expanded.
+// Returns nil, nil if no valid preset with that name exists.
+func resolveQuickLaunchFromPresets(presets []contracts.QuickLaunch, detected []config.RunTarget, cfg *config.Config, name string, values map[string]string) (*resolvedQuickLaunch, error) {
 	for _, preset := range presets {
 		if preset.Name != name {
 			continue
 		}
-		if strings.TrimSpace(preset.Command) != "" {
-			return &resolvedQuickLaunch{Name: preset.Name, Command: strings.TrimSpace(preset.Command)}
+		if command := strings.TrimSpace(preset.Command); command != "" {
+			expanded, err := expandQuickLaunchVariables(command, values)
+			if err != nil {
+				return nil, fmt.Errorf("quick launch %s: %w", preset.Name, err)
+			}
+			return &resolvedQuickLaunch{Name: preset.Name, Command: expanded}, nil
 		}
 		if strings.TrimSpace(preset.Target) == "" {
-			return nil
+			return nil, nil
 		}
 		promptable, found := config.IsTargetPromptable(cfg, detected, preset.Target)
 		if !found {
-			return nil
+			return nil, nil
 		}
 		prompt := ""
 		if preset.Prompt != nil {
 			prompt = strings.TrimSpace(*preset.Prompt)
 		}
 		if promptable && prompt == "" {
-			return nil
+			return nil, nil
 		}
 		if !promptable && prompt != "" {
-			return nil
+			return nil, nil
+		}
+		expanded, err := expandQuickLaunchVariables(prompt, values)
+		if err != nil {
+			return nil, fmt.Errorf("quick launch %s: %w", preset.Name, err)
 		}
-		return &resolvedQuickLaunch{Name: preset.Name, Target: preset.Target, Prompt: prompt}
+		return &resolvedQuickLaunch{Name: preset.Name, Target: preset.Target, Prompt: expanded}, nil
 	}
-	return nil
+	return nil, nil
+}
+
+// quickLaunchVariablePattern matches {{name}} placeholders in quick launch commands and prompts.
+var quickLaunchVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*\}\}`)
+
+// quickLaunchVariables returns the distinct placeholder names in text, in order of first appearance.
+func quickLaunchVariables(text string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range quickLaunchVariablePattern.FindAllStringSubmatch(text, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// expandQuickLaunchVariables substitutes {{name}} placeholders with values.
+// Every placeholder must have a non-empty value; values are inserted verbatim.
+func expandQuickLaunchVariables(text string, values map[string]string) (string, error) {
+	var missing []string
+	for _, name := range quickLaunchVariables(text) {
+		if strings.TrimSpace(values[name]) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for variables: %s", strings.Join(missing, ", "))
+	}
+	return quickLaunchVariablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		return values[quickLaunchVariablePattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
 }
 
 func adaptQuickLaunch(presets []config.QuickLaunch) []contracts.QuickLaunch {
@@ -2052,33 +3565,56 @@ func adaptQuickLaunch(presets []config.QuickLaunch) []contracts.QuickLaunch {
 	return converted
 }
 
+// maxDiffFileContentSize caps the content loaded for one side of a file in the diff view.
+// Larger files are reported as binary rather than read into memory.
+const maxDiffFileContentSize = 1024 * 1024 // 1MB
+
 // getFileContent gets file content from a specific git tree-ish.
 // For "worktree", it reads from the working directory directly.
-func (s *Server) getFileContent(ctx context.Context, workspacePath, filePath, treeish string) string {
+// Files that are binary or larger than maxDiffFileContentSize return empty content
+// with binary set, without being read in full.
+func (s *Server) getFileContent(ctx context.Context, workspacePath, filePath, treeish string) (content string, binary bool) {
+	var reader io.Reader
 	if treeish == "worktree" {
 		fullPath := filepath.Join(workspacePath, filePath)
-		content, err := os.ReadFile(fullPath)
+		info, err := os.Stat(fullPath)
+		if err != nil || !info.Mode().IsRegular() {
+			return "", false
+		}
+		if info.Size() > maxDiffFileContentSize || difftool.IsBinaryFile(ctx, workspacePath, fullPath) {
+			return "", true
+		}
+		f, err := os.Open(fullPath)
 		if err != nil {
-			return ""
+			return "", false
 		}
-		// Cap file content to prevent loading massive files into memory
-		const maxContentSize = 1024 * 1024 // 1MB
-		if len(content) > maxContentSize {
-			content = content[:maxContentSize]
+		defer f.Close()
+		reader = f
+	} else {
+		cmd := exec.CommandContext(ctx, "git", "-C", workspacePath, "show", fmt.Sprintf("%s:%s", treeish, filePath))
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return "", false
 		}
-		return string(content)
+		if err := cmd.Start(); err != nil {
+			return "", false
+		}
+		defer func() {
+			// Stop git if the blob was too large to read to the end.
+			cmd.Process.Kill()
+			cmd.Wait()
+		}()
+		reader = stdout
 	}
-	cmd := exec.CommandContext(ctx, "git", "-C", workspacePath, "show", fmt.Sprintf("%s:%s", treeish, filePath))
-	output, err := cmd.Output()
+
+	data, err := io.ReadAll(io.LimitReader(reader, maxDiffFileContentSize+1))
 	if err != nil {
-		return ""
+		return "", false
 	}
-	// Cap git show output too
-	const maxContentSize = 1024 * 1024 // 1MB
-	if len(output) > maxContentSize {
-		output = output[:maxContentSize]
+	if len(data) > maxDiffFileContentSize || difftool.IsBinaryContent(data) {
+		return "", true
 	}
-	return string(output)
+	return string(data), false
 }
 
 // handleRemoteDiff handles diff requests for remote workspaces by executing VCS
@@ -2102,13 +3638,13 @@ func (s *Server) handleRemoteDiff(w http.ResponseWriter, r *http.Request, ws sta
 	}
 
 	if s.remoteManager == nil {
-		http.Error(w, "remote manager not available", http.StatusServiceUnavailable)
+		writeJSONError(w, "remote manager not available", http.StatusServiceUnavailable)
 		return
 	}
 
 	conn := s.remoteManager.GetConnection(ws.RemoteHostID)
 	if conn == nil || !conn.IsConnected() {
-		http.Error(w, "remote host not connected", http.StatusServiceUnavailable)
+		writeJSONError(w, "remote host not connected", http.StatusServiceUnavailable)
 		return
 	}
 
@@ -2230,17 +3766,13 @@ func (s *Server) handleRemoteDiff(w http.ResponseWriter, r *http.Request, ws sta
 // handleRemoteGitGraph handles git graph requests for remote workspaces.
 func (s *Server) handleRemoteGitGraph(w http.ResponseWriter, r *http.Request, ws state.Workspace, maxCommits, contextSize int) {
 	if s.remoteManager == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(map[string]string{"error": "remote manager not available"})
+		writeJSONError(w, "remote manager not available", http.StatusServiceUnavailable)
 		return
 	}
 
 	conn := s.remoteManager.GetConnection(ws.RemoteHostID)
 	if conn == nil || !conn.IsConnected() {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(map[string]string{"error": "remote host not connected"})
+		writeJSONError(w, "remote host not connected", http.StatusServiceUnavailable)
 		return
 	}
 
@@ -2273,16 +3805,12 @@ func (s *Server) handleRemoteGitGraph(w http.ResponseWriter, r *http.Request, ws
 	// Resolve HEAD and default branch ref
 	localHeadOutput, err := conn.RunCommand(ctx, workdir, cb.ResolveRef("HEAD"))
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": "cannot resolve HEAD"})
+		writeJSONError(w, "cannot resolve HEAD", http.StatusInternalServerError)
 		return
 	}
 	localHead := strings.TrimSpace(localHeadOutput)
 	if !isValidVCSHash(localHead) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("HEAD resolved to invalid hash: %q", localHead)})
+		writeJSONError(w, fmt.Sprintf("HEAD resolved to invalid hash: %q", localHead), http.StatusInternalServerError)
 		return
 	}
 
@@ -2320,18 +3848,14 @@ func (s *Server) handleRemoteGitGraph(w http.ResponseWriter, r *http.Request, ws
 	if originMainHead == "" || localHead == originMainHead {
 		out, err := conn.RunCommand(ctx, workdir, cb.Log([]string{"HEAD"}, contextSize+1))
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("log failed: %v", err)})
+			writeJSONError(w, fmt.Sprintf("log failed: %v", err), http.StatusInternalServerError)
 			return
 		}
 		logOutput = out
 	} else if forkPoint == "" {
 		out, err := conn.RunCommand(ctx, workdir, cb.Log([]string{"HEAD", defaultBranchRef}, maxCommits))
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("log failed: %v", err)})
+			writeJSONError(w, fmt.Sprintf("log failed: %v", err), http.StatusInternalServerError)
 			return
 		}
 		logOutput = out
@@ -2342,9 +3866,7 @@ func (s *Server) handleRemoteGitGraph(w http.ResponseWriter, r *http.Request, ws
 			// Fallback to simple log
 			out, err = conn.RunCommand(ctx, workdir, cb.Log([]string{"HEAD", defaultBranchRef}, maxCommits))
 			if err != nil {
-				w.Header().Set("Content-Type", "application/json")
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("log failed: %v", err)})
+				writeJSONError(w, fmt.Sprintf("log failed: %v", err), http.StatusInternalServerError)
 				return
 			}
 		}
@@ -2383,14 +3905,14 @@ func isValidVCSHash(s string) bool {
 // handleOpenVSCode opens VS Code in a new window for the specified workspace.
 func (s *Server) handleOpenVSCode(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Extract workspace ID from URL: /api/open-vscode/{workspace-id}
 	workspaceID := strings.TrimPrefix(r.URL.Path, "/api/open-vscode/")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -2590,42 +4112,135 @@ func (s *Server) handleOpenVSCode(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// defaultDiffExternalMaxFiles is how many separate diff tool invocations
+// handleDiffExternal launches before asking the client to confirm.
+const defaultDiffExternalMaxFiles = 20
+
+// DiffExternalRequest is the body of POST /api/diff-external/{workspaceId}.
+type DiffExternalRequest struct {
+	Command  string   `json:"command"`             // a command name from config, or a raw command string
+	Files    []string `json:"files,omitempty"`     // glob patterns selecting which changed files to open; all when empty
+	MaxFiles int      `json:"max_files,omitempty"` // confirm before opening more files than this; 0 uses defaultDiffExternalMaxFiles
+	Confirm  bool     `json:"confirm,omitempty"`   // open every selected file even when over max_files
+}
+
+// DiffExternalResponse is the response of POST /api/diff-external/{workspaceId}.
+type DiffExternalResponse struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	FileCount    int    `json:"file_count,omitempty"`    // files that would be opened
+	NeedsConfirm bool   `json:"needs_confirm,omitempty"` // over max_files; resend with confirm to open them anyway
+}
+
+// diffExternalFile is a changed file considered by handleDiffExternal.
+type diffExternalFile struct {
+	path   string
+	status string // added, modified, deleted
+}
+
+// filterDiffExternalFiles keeps the files matching any of patterns (see config.MatchPathGlob).
+// An empty pattern list keeps every file.
+func filterDiffExternalFiles(files []diffExternalFile, patterns []string) []diffExternalFile {
+	if len(patterns) == 0 {
+		return files
+	}
+	var selected []diffExternalFile
+	for _, file := range files {
+		for _, pattern := range patterns {
+			if config.MatchPathGlob(pattern, file.path) {
+				selected = append(selected, file)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// needsConfirm reports whether opening count files, one invocation each, exceeds the
+// request's max_files without confirmation.
+func (req DiffExternalRequest) needsConfirm(count int) bool {
+	maxFiles := req.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultDiffExternalMaxFiles
+	}
+	return !req.Confirm && count > maxFiles
+}
+
+// isDirDiffCommand reports whether a diff command takes whole directories ({old_dir}
+// and {new_dir}) and so is run once for all files.
+func isDirDiffCommand(cmd string) bool {
+	return strings.Contains(cmd, "{old_dir}") || strings.Contains(cmd, "{new_dir}")
+}
+
+// writeHeadFile writes a file's HEAD content from the workspace's git repo to dst.
+func writeHeadFile(ctx context.Context, workspacePath, relPath, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create temp dir for file: %w", err)
+	}
+	content, err := exec.CommandContext(ctx, "git", "-C", workspacePath, "show", "HEAD:"+relPath).Output()
+	if err != nil {
+		return fmt.Errorf("failed to get old file: %w", err)
+	}
+	if err := os.WriteFile(dst, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return nil
+}
+
+// copyWorktreeFile copies a file from the workspace to dst.
+func copyWorktreeFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create temp dir for file: %w", err)
+	}
+	content, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if err := os.WriteFile(dst, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return nil
+}
+
 // handleDiffExternal handles POST requests to open an external diff tool for a workspace.
 // POST /api/diff-external/{workspaceId}
 //
-// Request body: {"command": "ksdiff"} (optional, defaults to first configured command)
+// Request body: DiffExternalRequest, e.g. {"command": "ksdiff", "files": ["*.go"]}.
+// The command defaults to the first configured command.
 //
 // The command can use placeholders:
 //
-//	{old_file} - path to the old version of the file (from HEAD)
+//	{old_file} - path to the old version of the file (from HEAD; an empty file for new files)
 //	{new_file} - path to the new version of the file (from worktree)
 //	{file}     - path to the file in worktree (for new/deleted files)
+//	{old_dir}  - directory holding the old versions of all selected files
+//	{new_dir}  - directory holding copies of the new versions of all selected files
+//
+// A command using {old_dir} or {new_dir} runs once for all selected files; otherwise
+// it runs once per file, and more than max_files files need confirmation.
+// New files, including untracked ones, are diffed against an empty file.
 //
 // Examples:
 //
 //	"code --diff {old_file} {new_file}"  - VS Code
 //	"ksdiff {old_file} {new_file}"      - Kaleidoscope
 //	"git difftool {file}"               - git difftool (configured externally)
+//	"meld {old_dir} {new_dir}"          - Meld directory comparison
 func (s *Server) handleDiffExternal(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Extract workspace ID from URL: /api/diff-external/{workspace-id}
 	workspaceID := strings.TrimPrefix(r.URL.Path, "/api/diff-external/")
-	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+	if id, ok := strings.CutSuffix(workspaceID, "/close"); ok {
+		s.handleDiffExternalClose(w, id)
 		return
 	}
-
-	type DiffExternalRequest struct {
-		Command string `json:"command"` // Can be a command name from config, or a raw command string
-	}
-
-	type DiffExternalResponse struct {
-		Success bool   `json:"success"`
-		Message string `json:"message"`
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
 	}
 
 	// Parse request body to get command name
@@ -2640,6 +4255,17 @@ func (s *Server) handleDiffExternal(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	for _, pattern := range req.Files {
+		if err := config.ValidatePathGlob(pattern); err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DiffExternalResponse{
+				Success: false,
+				Message: fmt.Sprintf("invalid file pattern %q", pattern),
+			})
+			return
+		}
+	}
 
 	// Get the external diff commands from config
 	externalDiffCommands := s.config.GetExternalDiffCommands()
@@ -2687,7 +4313,7 @@ func (s *Server) handleDiffExternal(w http.ResponseWriter, r *http.Request) {
 
 	// Delegate to remote handler if this is a remote workspace
 	if ws.RemoteHostID != "" {
-		s.handleRemoteDiffExternal(w, r, ws, selectedCommand)
+		s.handleRemoteDiffExternal(w, r, ws, selectedCommand, req)
 		return
 	}
 
@@ -2704,45 +4330,42 @@ func (s *Server) handleDiffExternal(w http.ResponseWriter, r *http.Request) {
 
 	// Get changed files using git diff --numstat
 	// HEAD compares against last commit (includes both staged and unstaged)
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetGitStatusTimeoutMs())*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.GitDiffTimeout())
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "git", "-C", ws.Path, "diff", "HEAD", "--numstat", "--find-renames", "--diff-filter=ADM")
+	cmd := exec.CommandContext(ctx, "git", "-C", ws.Path, "diff", "HEAD", "--name-status", "--diff-filter=ADM")
 	output, err := cmd.Output()
 	if err != nil {
 		output = []byte{}
 	}
 
-	type changedFile struct {
-		path   string
-		status string // added, modified, deleted, renamed
-	}
-
-	files := make([]changedFile, 0)
+	files := make([]diffExternalFile, 0)
 	lines := strings.Split(string(output), "\n")
 	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		parts := strings.Fields(line)
-		if len(parts) < 3 {
+		code, filePath, ok := strings.Cut(line, "\t")
+		if !ok || filePath == "" {
 			continue
 		}
-		added := parts[0]
-		deleted := parts[1]
-		filePath := parts[2]
 
 		status := "modified"
-		if added == "-" && deleted == "-" {
-			// Binary file or special case
-			status = "modified"
-		} else if added == "0" && deleted != "0" {
-			status = "deleted"
-		} else if added != "0" && deleted == "0" {
+		switch code {
+		case "A":
 			status = "added"
+		case "D":
+			status = "deleted"
 		}
 
-		files = append(files, changedFile{path: filePath, status: status})
+		files = append(files, diffExternalFile{path: filePath, status: status})
+	}
+
+	// Untracked files aren't part of git diff; list them as additions too.
+	untrackedCmd := exec.CommandContext(ctx, "git", "-C", ws.Path, "ls-files", "--others", "--exclude-standard")
+	if untrackedOutput, err := untrackedCmd.Output(); err == nil {
+		for _, filePath := range strings.Split(string(untrackedOutput), "\n") {
+			if filePath != "" {
+				files = append(files, diffExternalFile{path: filePath, status: "added"})
+			}
+		}
 	}
 
 	if len(files) == 0 {
@@ -2753,8 +4376,15 @@ func (s *Server) handleDiffExternal(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-
-	fmt.Printf("[session] diff-external: launching %q for %d files in workspace %s\n", selectedCommand, len(files), workspaceID)
+	files = filterDiffExternalFiles(files, req.Files)
+	if len(files) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DiffExternalResponse{
+			Success: false,
+			Message: "No changed files match the selected patterns",
+		})
+		return
+	}
 
 	// Parse the base command (before file paths)
 	if strings.TrimSpace(selectedCommand) == "" {
@@ -2766,6 +4396,20 @@ func (s *Server) handleDiffExternal(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	dirMode := isDirDiffCommand(selectedCommand)
+	if !dirMode && req.needsConfirm(len(files)) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DiffExternalResponse{
+			Success:      false,
+			Message:      fmt.Sprintf("%d files would each open in a separate diff tool window", len(files)),
+			FileCount:    len(files),
+			NeedsConfirm: true,
+		})
+		return
+	}
+
+	fmt.Printf("[session] diff-external: launching %q for %d files in workspace %s\n", selectedCommand, len(files), workspaceID)
+
 	replacePlaceholders := func(cmd, oldPath, newPath, filePath string) string {
 		cmd = strings.ReplaceAll(cmd, "{old_file}", oldPath)
 		cmd = strings.ReplaceAll(cmd, "{new_file}", newPath)
@@ -2785,143 +4429,182 @@ func (s *Server) handleDiffExternal(w http.ResponseWriter, r *http.Request) {
 	}
 	opened := 0
 
-	for _, file := range files {
-		switch file.status {
-		case "modified":
-			oldPath := fmt.Sprintf("HEAD:%s", file.path)
-			newPath := filepath.Join(ws.Path, file.path)
-			mergedPath := newPath
-
-			// Create temp file for old version
-			tmpPath := filepath.Join(tempRoot, file.path)
-			if err := os.MkdirAll(filepath.Dir(tmpPath), 0o755); err != nil {
-				fmt.Printf("[session] diff-external: failed to create temp dir for file: %v\n", err)
-				continue
-			}
-			tmpFile, err := os.Create(tmpPath)
-			if err != nil {
-				fmt.Printf("[session] diff-external: failed to create temp file: %v\n", err)
-				continue
+	if dirMode {
+		// Build old and new trees of the selected files and run the tool once on them.
+		oldDir := filepath.Join(tempRoot, "old")
+		newDir := filepath.Join(tempRoot, "new")
+		for _, dir := range []string{oldDir, newDir} {
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				fmt.Printf("[session] diff-external: failed to create temp dir: %v\n", err)
 			}
-
-			// Get old file content from git
-			showCmd := exec.CommandContext(ctx, "git", "-C", ws.Path, "show", oldPath)
-			showOutput, err := showCmd.Output()
-			if err != nil {
-				tmpFile.Close()
-				os.Remove(tmpPath)
-				fmt.Printf("[session] diff-external: failed to get old file: %v\n", err)
-				continue
+		}
+		for _, file := range files {
+			if file.status != "added" {
+				if err := writeHeadFile(ctx, ws.Path, file.path, filepath.Join(oldDir, file.path)); err != nil {
+					fmt.Printf("[session] diff-external: %v\n", err)
+					continue
+				}
 			}
-			if _, err := tmpFile.Write(showOutput); err != nil {
-				tmpFile.Close()
-				os.Remove(tmpPath)
-				fmt.Printf("[session] diff-external: failed to write temp file: %v\n", err)
-				continue
+			if file.status != "deleted" {
+				if err := copyWorktreeFile(filepath.Join(ws.Path, file.path), filepath.Join(newDir, file.path)); err != nil {
+					fmt.Printf("[session] diff-external: %v\n", err)
+					continue
+				}
 			}
-			tmpFile.Close()
-
-			cmdString := replacePlaceholders(selectedCommand, tmpPath, newPath, newPath)
+			opened++
+		}
+		if opened > 0 {
+			cmdString := strings.ReplaceAll(selectedCommand, "{old_dir}", oldDir)
+			cmdString = strings.ReplaceAll(cmdString, "{new_dir}", newDir)
 			execCmd := exec.Command("sh", "-c", cmdString)
 			execCmd.Dir = ws.Path
 			execCmd.Env = append(os.Environ(),
-				fmt.Sprintf("LOCAL=%s", tmpPath),
-				fmt.Sprintf("REMOTE=%s", newPath),
-				fmt.Sprintf("MERGED=%s", mergedPath),
-				fmt.Sprintf("BASE=%s", mergedPath),
+				fmt.Sprintf("LOCAL=%s", oldDir),
+				fmt.Sprintf("REMOTE=%s", newDir),
 			)
-			if err := execCmd.Start(); err != nil {
+			if err := s.diffTools.Start(workspaceID, tempRoot, execCmd); err != nil {
 				fmt.Printf("[session] diff-external: diff tool exited with error: %v\n", err)
-			} else {
-				opened++
+				opened = 0
 			}
+		}
+	} else {
+		for _, file := range files {
+			switch file.status {
+			case "modified":
+				newPath := filepath.Join(ws.Path, file.path)
+				mergedPath := newPath
+
+				// Create temp file for old version
+				tmpPath := filepath.Join(tempRoot, file.path)
+				if err := writeHeadFile(ctx, ws.Path, file.path, tmpPath); err != nil {
+					os.Remove(tmpPath)
+					fmt.Printf("[session] diff-external: %v\n", err)
+					continue
+				}
 
-		case "deleted":
-			oldPath := fmt.Sprintf("HEAD:%s", file.path)
-			mergedPath := filepath.Join(ws.Path, file.path)
-			tmpPath := filepath.Join(tempRoot, file.path)
-			if err := os.MkdirAll(filepath.Dir(tmpPath), 0o755); err != nil {
-				fmt.Printf("[session] diff-external: failed to create temp dir for file: %v\n", err)
-				continue
-			}
-			tmpFile, err := os.Create(tmpPath)
-			if err != nil {
-				fmt.Printf("[session] diff-external: failed to create temp file: %v\n", err)
-				continue
-			}
+				cmdString := replacePlaceholders(selectedCommand, tmpPath, newPath, newPath)
+				execCmd := exec.Command("sh", "-c", cmdString)
+				execCmd.Dir = ws.Path
+				execCmd.Env = append(os.Environ(),
+					fmt.Sprintf("LOCAL=%s", tmpPath),
+					fmt.Sprintf("REMOTE=%s", newPath),
+					fmt.Sprintf("MERGED=%s", mergedPath),
+					fmt.Sprintf("BASE=%s", mergedPath),
+				)
+				if err := s.diffTools.Start(workspaceID, tempRoot, execCmd); err != nil {
+					fmt.Printf("[session] diff-external: diff tool exited with error: %v\n", err)
+				} else {
+					opened++
+				}
 
-			showCmd := exec.CommandContext(ctx, "git", "-C", ws.Path, "show", oldPath)
-			showOutput, err := showCmd.Output()
-			if err != nil {
-				tmpFile.Close()
-				os.Remove(tmpPath)
-				fmt.Printf("[session] diff-external: failed to get old file: %v\n", err)
-				continue
-			}
-			if _, err := tmpFile.Write(showOutput); err != nil {
-				tmpFile.Close()
-				os.Remove(tmpPath)
-				fmt.Printf("[session] diff-external: failed to write temp file: %v\n", err)
-				continue
-			}
-			tmpFile.Close()
+			case "deleted":
+				mergedPath := filepath.Join(ws.Path, file.path)
+				tmpPath := filepath.Join(tempRoot, file.path)
+				if err := writeHeadFile(ctx, ws.Path, file.path, tmpPath); err != nil {
+					os.Remove(tmpPath)
+					fmt.Printf("[session] diff-external: %v\n", err)
+					continue
+				}
 
-			cmdString := replacePlaceholders(selectedCommand, tmpPath, "", mergedPath)
-			execCmd := exec.Command("sh", "-c", cmdString)
-			execCmd.Dir = ws.Path
-			execCmd.Env = append(os.Environ(),
-				fmt.Sprintf("LOCAL=%s", tmpPath),
-				fmt.Sprintf("REMOTE="),
-				fmt.Sprintf("MERGED=%s", mergedPath),
-				fmt.Sprintf("BASE=%s", mergedPath),
-			)
-			if err := execCmd.Start(); err != nil {
-				fmt.Printf("[session] diff-external: diff tool exited with error: %v\n", err)
-			} else {
-				opened++
-			}
+				cmdString := replacePlaceholders(selectedCommand, tmpPath, "", mergedPath)
+				execCmd := exec.Command("sh", "-c", cmdString)
+				execCmd.Dir = ws.Path
+				execCmd.Env = append(os.Environ(),
+					fmt.Sprintf("LOCAL=%s", tmpPath),
+					"REMOTE=",
+					fmt.Sprintf("MERGED=%s", mergedPath),
+					fmt.Sprintf("BASE=%s", mergedPath),
+				)
+				if err := s.diffTools.Start(workspaceID, tempRoot, execCmd); err != nil {
+					fmt.Printf("[session] diff-external: diff tool exited with error: %v\n", err)
+				} else {
+					opened++
+				}
 
-		case "added":
-			// Skip new/untracked files (git difftool doesn't include them)
-			continue
+			case "added":
+				// New and untracked files have no old version; diff them against an
+				// empty file so the tool shows them as additions.
+				newPath := filepath.Join(ws.Path, file.path)
+				tmpPath := filepath.Join(tempRoot, file.path)
+				if err := os.MkdirAll(filepath.Dir(tmpPath), 0o755); err != nil {
+					fmt.Printf("[session] diff-external: failed to create temp dir for file: %v\n", err)
+					continue
+				}
+				if err := os.WriteFile(tmpPath, nil, 0o644); err != nil {
+					fmt.Printf("[session] diff-external: failed to create temp file: %v\n", err)
+					continue
+				}
+
+				cmdString := replacePlaceholders(selectedCommand, tmpPath, newPath, newPath)
+				execCmd := exec.Command("sh", "-c", cmdString)
+				execCmd.Dir = ws.Path
+				execCmd.Env = append(os.Environ(),
+					fmt.Sprintf("LOCAL=%s", tmpPath),
+					fmt.Sprintf("REMOTE=%s", newPath),
+					fmt.Sprintf("MERGED=%s", newPath),
+					fmt.Sprintf("BASE=%s", newPath),
+				)
+				if err := s.diffTools.Start(workspaceID, tempRoot, execCmd); err != nil {
+					fmt.Printf("[session] diff-external: diff tool exited with error: %v\n", err)
+				} else {
+					opened++
+				}
+			}
 		}
 	}
 
 	if opened == 0 {
 		os.RemoveAll(tempRoot)
-		// No files were added (all were new/untracked)
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(DiffExternalResponse{
 			Success: false,
-			Message: "No modified or deleted files to diff",
+			Message: "No files could be opened in the diff tool",
 		})
 		return
 	}
 
 	cleanupDelay := time.Duration(s.config.GetExternalDiffCleanupAfterMs()) * time.Millisecond
 	time.AfterFunc(cleanupDelay, func() {
-		if err := os.RemoveAll(tempRoot); err != nil {
-			fmt.Printf("[session] diff-external: failed to remove temp dir: %v\n", err)
-		}
+		s.diffTools.Expire(workspaceID, tempRoot)
 	})
 
 	// Success response
+	message := fmt.Sprintf("Opened %d files in external diff tool", opened)
+	if dirMode {
+		message = fmt.Sprintf("Opened a directory diff of %d files in external diff tool", opened)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(DiffExternalResponse{
-		Success: true,
-		Message: fmt.Sprintf("Opened %d files in external diff tool", opened),
+		Success:   true,
+		Message:   message,
+		FileCount: opened,
 	})
 }
 
+// DiffExternalCloseResponse is the response of POST /api/diff-external/{workspaceId}/close.
+type DiffExternalCloseResponse struct {
+	Closed int `json:"closed"` // diff tool processes terminated
+}
+
+// handleDiffExternalClose terminates the diff tools launched for a workspace and
+// removes their temp dirs now rather than after external_diff_cleanup_after_ms.
+// POST /api/diff-external/{workspaceId}/close
+func (s *Server) handleDiffExternalClose(w http.ResponseWriter, workspaceID string) {
+	if workspaceID == "" || strings.Contains(workspaceID, "/") {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+	closed := s.diffTools.Close(workspaceID)
+	fmt.Printf("[session] diff-external: closed %d diff tools for workspace %s\n", closed, workspaceID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DiffExternalCloseResponse{Closed: closed})
+}
+
 // handleRemoteDiffExternal handles external diff tool requests for remote workspaces.
 // It fetches file contents from the remote host, writes them to local temp files,
 // and launches the diff tool with those temp files.
-func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request, ws state.Workspace, selectedCommand string) {
-	type DiffExternalResponse struct {
-		Success bool   `json:"success"`
-		Message string `json:"message"`
-	}
-
+func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request, ws state.Workspace, selectedCommand string, req DiffExternalRequest) {
 	if s.remoteManager == nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -2964,12 +4647,7 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 		numstatOutput = ""
 	}
 
-	type changedFile struct {
-		path   string
-		status string
-	}
-
-	files := make([]changedFile, 0)
+	files := make([]diffExternalFile, 0)
 	for _, line := range strings.Split(numstatOutput, "\n") {
 		line = strings.TrimSpace(line)
 		if line == "" {
@@ -2994,7 +4672,7 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 			status = "deleted"
 		}
 
-		files = append(files, changedFile{path: filePath, status: status})
+		files = append(files, diffExternalFile{path: filePath, status: status})
 	}
 
 	if len(files) == 0 {
@@ -3006,6 +4684,34 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	files = filterDiffExternalFiles(files, req.Files)
+	// Added files are not opened for remote workspaces.
+	openable := 0
+	for _, file := range files {
+		if file.status != "added" {
+			openable++
+		}
+	}
+	if openable == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DiffExternalResponse{
+			Success: false,
+			Message: "No modified or deleted files to diff",
+		})
+		return
+	}
+	dirMode := isDirDiffCommand(selectedCommand)
+	if !dirMode && req.needsConfirm(openable) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DiffExternalResponse{
+			Success:      false,
+			Message:      fmt.Sprintf("%d files would each open in a separate diff tool window", openable),
+			FileCount:    openable,
+			NeedsConfirm: true,
+		})
+		return
+	}
+
 	fmt.Printf("[session] diff-external (remote): launching %q for %d files in workspace %s\n", selectedCommand, len(files), ws.ID)
 
 	replacePlaceholders := func(cmd, oldPath, newPath, filePath string) string {
@@ -3057,6 +4763,11 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 				continue
 			}
 
+			if dirMode {
+				opened++
+				continue
+			}
+
 			cmdString := replacePlaceholders(selectedCommand, oldPath, newPath, newPath)
 			execCmd := exec.Command("sh", "-c", cmdString)
 			execCmd.Env = append(os.Environ(),
@@ -3065,7 +4776,7 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 				fmt.Sprintf("MERGED=%s", newPath),
 				fmt.Sprintf("BASE=%s", newPath),
 			)
-			if err := execCmd.Start(); err != nil {
+			if err := s.diffTools.Start(ws.ID, tempRoot, execCmd); err != nil {
 				fmt.Printf("[session] diff-external (remote): diff tool error: %v\n", err)
 			} else {
 				opened++
@@ -3085,6 +4796,11 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 				continue
 			}
 
+			if dirMode {
+				opened++
+				continue
+			}
+
 			cmdString := replacePlaceholders(selectedCommand, oldPath, "", filepath.Join(workdir, file.path))
 			execCmd := exec.Command("sh", "-c", cmdString)
 			execCmd.Env = append(os.Environ(),
@@ -3093,7 +4809,7 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 				fmt.Sprintf("MERGED=%s", filepath.Join(workdir, file.path)),
 				fmt.Sprintf("BASE=%s", filepath.Join(workdir, file.path)),
 			)
-			if err := execCmd.Start(); err != nil {
+			if err := s.diffTools.Start(ws.ID, tempRoot, execCmd); err != nil {
 				fmt.Printf("[session] diff-external (remote): diff tool error: %v\n", err)
 			} else {
 				opened++
@@ -3104,6 +4820,25 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// Directory diff tools run once over the old and new trees written above.
+	if dirMode && opened > 0 {
+		oldDir := filepath.Join(tempRoot, "old")
+		newDir := filepath.Join(tempRoot, "new")
+		os.MkdirAll(oldDir, 0o755)
+		os.MkdirAll(newDir, 0o755)
+		cmdString := strings.ReplaceAll(selectedCommand, "{old_dir}", oldDir)
+		cmdString = strings.ReplaceAll(cmdString, "{new_dir}", newDir)
+		execCmd := exec.Command("sh", "-c", cmdString)
+		execCmd.Env = append(os.Environ(),
+			fmt.Sprintf("LOCAL=%s", oldDir),
+			fmt.Sprintf("REMOTE=%s", newDir),
+		)
+		if err := s.diffTools.Start(ws.ID, tempRoot, execCmd); err != nil {
+			fmt.Printf("[session] diff-external (remote): diff tool error: %v\n", err)
+			opened = 0
+		}
+	}
+
 	if opened == 0 {
 		os.RemoveAll(tempRoot)
 		w.Header().Set("Content-Type", "application/json")
@@ -3116,15 +4851,18 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 
 	cleanupDelay := time.Duration(s.config.GetExternalDiffCleanupAfterMs()) * time.Millisecond
 	time.AfterFunc(cleanupDelay, func() {
-		if err := os.RemoveAll(tempRoot); err != nil {
-			fmt.Printf("[session] diff-external (remote): failed to remove temp dir: %v\n", err)
-		}
+		s.diffTools.Expire(ws.ID, tempRoot)
 	})
 
+	message := fmt.Sprintf("Opened %d files in external diff tool", opened)
+	if dirMode {
+		message = fmt.Sprintf("Opened a directory diff of %d files in external diff tool", opened)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(DiffExternalResponse{
-		Success: true,
-		Message: fmt.Sprintf("Opened %d files in external diff tool", opened),
+		Success:   true,
+		Message:   message,
+		FileCount: opened,
 	})
 }
 
@@ -3135,21 +4873,21 @@ func (s *Server) handleRemoteDiffExternal(w http.ResponseWriter, r *http.Request
 // The response extraction happens internally on the server side.
 func (s *Server) handleAskNudgenik(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	// Extract session ID from URL: /api/askNudgenik/{session-id}
 	sessionID := strings.TrimPrefix(r.URL.Path, "/api/askNudgenik/")
 	if sessionID == "" {
-		http.Error(w, "session ID is required", http.StatusBadRequest)
+		writeJSONError(w, "session ID is required", http.StatusBadRequest)
 		return
 	}
 
 	// Get session from state
 	sess, found := s.state.GetSession(sessionID)
 	if !found {
-		http.Error(w, "session not found", http.StatusNotFound)
+		writeJSONError(w, "session not found", http.StatusNotFound)
 		return
 	}
 
@@ -3159,19 +4897,23 @@ func (s *Server) handleAskNudgenik(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, nudgenik.ErrDisabled):
 			fmt.Printf("[nudgenik] nudgenik is disabled\n")
-			http.Error(w, "Nudgenik is disabled. Configure a target in settings.", http.StatusServiceUnavailable)
+			writeJSONError(w, "Nudgenik is disabled. Configure a target in settings.", http.StatusServiceUnavailable)
 		case errors.Is(err, nudgenik.ErrNoResponse):
 			fmt.Printf("[nudgenik] no response extracted from session %s\n", sessionID)
-			http.Error(w, "No response found in session output", http.StatusBadRequest)
+			writeJSONError(w, "No response found in session output", http.StatusBadRequest)
 		case errors.Is(err, nudgenik.ErrTargetNotFound):
 			fmt.Printf("[nudgenik] target not found in config\n")
-			http.Error(w, "Nudgenik target not found", http.StatusServiceUnavailable)
+			writeJSONError(w, "Nudgenik target not found", http.StatusServiceUnavailable)
 		case errors.Is(err, nudgenik.ErrTargetNoSecrets):
 			fmt.Printf("[nudgenik] target missing required secrets\n")
-			http.Error(w, "Nudgenik target missing required secrets", http.StatusServiceUnavailable)
+			writeJSONError(w, "Nudgenik target missing required secrets", http.StatusServiceUnavailable)
+		case errors.Is(err, nudgenik.ErrRateLimited):
+			fmt.Printf("[nudgenik] rate limited for session %s\n", sessionID)
+			w.Header().Set("Retry-After", "5")
+			writeJSONError(w, "Nudgenik is rate limited, retry shortly", http.StatusTooManyRequests)
 		default:
 			fmt.Printf("[nudgenik] failed to ask for session %s: %v\n", sessionID, err)
-			http.Error(w, fmt.Sprintf("Failed to ask nudgenik: %v", err), http.StatusInternalServerError)
+			writeJSONError(w, fmt.Sprintf("Failed to ask nudgenik: %v", err), http.StatusInternalServerError)
 		}
 		return
 	}
@@ -3184,7 +4926,7 @@ func (s *Server) handleAskNudgenik(w http.ResponseWriter, r *http.Request) {
 // Returns available: true only when a nudgenik target is configured.
 func (s *Server) handleHasNudgenik(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3196,7 +4938,7 @@ func (s *Server) handleHasNudgenik(w http.ResponseWriter, r *http.Request) {
 // handleOverlays returns overlay information for all repos.
 func (s *Server) handleOverlays(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3250,10 +4992,106 @@ func (s *Server) handleOverlays(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(Response{Overlays: overlays})
 }
 
+// maxOverlayFileBytes caps the size of overlay files written through the API.
+const maxOverlayFileBytes = 1 << 20
+
+// OverlayFileContent is the body of GET and PUT /api/overlays/{repo}/files/{name}.
+type OverlayFileContent struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// handleOverlayFiles handles the overlay file routes:
+//   - GET /api/overlays/{repo}/files lists the files in the repo's overlay directory
+//   - GET /api/overlays/{repo}/files/{name} returns one file
+//   - PUT /api/overlays/{repo}/files/{name} creates or replaces one file
+//
+// {name} is relative to the overlay root and may contain slashes.
+func (s *Server) handleOverlayFiles(w http.ResponseWriter, r *http.Request) {
+	repoName, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/overlays/"), "/")
+	if rest != "files" && !strings.HasPrefix(rest, "files/") {
+		writeJSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+	fileName := strings.TrimPrefix(strings.TrimPrefix(rest, "files"), "/")
+
+	writeError := func(status int, msg string) {
+		writeJSONError(w, msg, status)
+	}
+
+	if _, found := s.config.FindRepo(repoName); !found {
+		writeError(http.StatusNotFound, fmt.Sprintf("repo not found: %s", repoName))
+		return
+	}
+
+	if fileName == "" {
+		if r.Method != http.MethodGet {
+			writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		files, err := workspace.ListOverlayFiles(repoName)
+		if err != nil {
+			fmt.Printf("[workspace] failed to list overlay files for %s: %v\n", repoName, err)
+			writeError(http.StatusInternalServerError, fmt.Sprintf("Failed to list overlay files: %v", err))
+			return
+		}
+		for i := range files {
+			files[i] = filepath.ToSlash(files[i])
+		}
+		sort.Strings(files)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"repo": repoName, "files": files})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		content, err := workspace.ReadOverlayFile(repoName, fileName)
+		if err != nil {
+			switch {
+			case errors.Is(err, workspace.ErrInvalidOverlayPath):
+				writeError(http.StatusBadRequest, err.Error())
+			case errors.Is(err, os.ErrNotExist):
+				writeError(http.StatusNotFound, fmt.Sprintf("overlay file not found: %s", fileName))
+			default:
+				writeError(http.StatusInternalServerError, fmt.Sprintf("Failed to read overlay file: %v", err))
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OverlayFileContent{Path: fileName, Content: string(content)})
+
+	case http.MethodPut:
+		var req OverlayFileContent
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 2*maxOverlayFileBytes)).Decode(&req); err != nil {
+			writeError(http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if len(req.Content) > maxOverlayFileBytes {
+			writeError(http.StatusRequestEntityTooLarge, fmt.Sprintf("overlay file exceeds %d bytes", maxOverlayFileBytes))
+			return
+		}
+		if err := workspace.WriteOverlayFile(repoName, fileName, []byte(req.Content)); err != nil {
+			if errors.Is(err, workspace.ErrInvalidOverlayPath) {
+				writeError(http.StatusBadRequest, err.Error())
+				return
+			}
+			fmt.Printf("[workspace] failed to write overlay file: repo=%s file=%s error=%v\n", repoName, fileName, err)
+			writeError(http.StatusInternalServerError, fmt.Sprintf("Failed to write overlay file: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OverlayFileContent{Path: fileName, Content: req.Content})
+
+	default:
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // handleRefreshOverlay handles POST requests to refresh overlay files for a workspace.
 func (s *Server) handleRefreshOverlay(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3261,7 +5099,7 @@ func (s *Server) handleRefreshOverlay(w http.ResponseWriter, r *http.Request) {
 	workspaceID := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
 	workspaceID = strings.TrimSuffix(workspaceID, "/refresh-overlay")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -3270,10 +5108,8 @@ func (s *Server) handleRefreshOverlay(w http.ResponseWriter, r *http.Request) {
 
 	if err := s.workspace.RefreshOverlay(ctx, workspaceID); err != nil {
 		fmt.Printf("[workspace] refresh-overlay error: workspace_id=%s error=%v\n", workspaceID, err)
-		w.Header().Set("Content-Type", "application/json")
 		// Return 400 for client errors (active sessions, not found)
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -3283,6 +5119,114 @@ func (s *Server) handleRefreshOverlay(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
 }
 
+// handleRepoRefreshOverlay handles POST requests to refresh overlay files for every
+// workspace of a repo: POST /api/repos/{repo}/refresh-overlay
+// Workspaces with active sessions are skipped and reported as such.
+func (s *Server) handleRepoRefreshOverlay(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/repos/")
+	if !strings.HasSuffix(path, "/refresh-overlay") {
+		writeJSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	repoName := strings.TrimSuffix(path, "/refresh-overlay")
+	if repoName == "" {
+		writeJSONError(w, "repo name is required", http.StatusBadRequest)
+		return
+	}
+
+	type Response struct {
+		Repo    string                           `json:"repo"`
+		Results []workspace.OverlayRefreshResult `json:"results"`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	results, err := s.workspace.RefreshRepoOverlay(ctx, repoName)
+	if err != nil {
+		fmt.Printf("[workspace] repo refresh-overlay error: repo=%s error=%v\n", repoName, err)
+		writeJSONError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{Repo: repoName, Results: results})
+}
+
+// handleBaseReposPrune removes base repos that no workspace uses and whose repo is no
+// longer configured: POST /api/base-repos/prune
+func (s *Server) handleBaseReposPrune(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	type Response struct {
+		Results []workspace.BaseRepoPruneResult `json:"results"`
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	results, err := s.workspace.PruneBaseRepos(ctx)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if results == nil {
+		results = []workspace.BaseRepoPruneResult{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{Results: results})
+}
+
+func maintenanceResponse(m workspace.Maintenance) contracts.MaintenanceResponse {
+	resp := contracts.MaintenanceResponse{Enabled: m.Enabled, BlockSpawns: m.BlockSpawns}
+	if m.Enabled {
+		resp.Since = m.Since.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// handleMaintenance reads or sets maintenance mode, which pauses the daemon's
+// background git work while repos are worked on by hand.
+// GET/POST /api/maintenance
+func (s *Server) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req contracts.MaintenanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		wasEnabled := s.workspace.Maintenance().Enabled
+		s.workspace.SetMaintenance(req.Enabled, req.BlockSpawns)
+		s.BroadcastMaintenance()
+		if wasEnabled && !req.Enabled {
+			// Catch up on whatever changed while the loops were paused
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), s.config.GitStatusTimeout())
+				defer cancel()
+				s.workspace.UpdateAllGitStatus(ctx)
+				s.BroadcastSessions()
+			}()
+		}
+	default:
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(maintenanceResponse(s.workspace.Maintenance()))
+}
+
 // BuiltinQuickLaunchCookbook represents a built-in quick launch cookbook entry.
 // These are predefined quick-run shortcuts that ship with schmux.
 type BuiltinQuickLaunchCookbook struct {
@@ -3303,20 +5247,44 @@ func (s *Server) handleLinearSync(w http.ResponseWriter, r *http.Request) {
 		s.handleWorkspaceGitGraph(w, r)
 		return
 	}
+	if strings.HasSuffix(path, "/commits") {
+		s.handleWorkspaceCommits(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/lock") {
+		s.handleWorkspaceLock(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/stashes") {
+		s.handleWorkspaceStashes(w, r)
+		return
+	}
+	if strings.Contains(path, "/stashes/") {
+		s.handleWorkspaceStashAction(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/prompts") {
+		s.handleWorkspacePrompts(w, r)
+		return
+	}
+	if strings.Contains(path, "/prompts/") {
+		s.handleWorkspacePromptRerun(w, r)
+		return
+	}
 
 	// DELETE routes
 	if r.Method == http.MethodDelete {
 		if strings.HasSuffix(path, "/linear-sync-resolve-conflict-state") {
 			s.handleDeleteLinearSyncResolveConflictState(w, r)
 		} else {
-			http.NotFound(w, r)
+			writeJSONError(w, "not found", http.StatusNotFound)
 		}
 		return
 	}
 
 	// All other routes require POST
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3331,8 +5299,14 @@ func (s *Server) handleLinearSync(w http.ResponseWriter, r *http.Request) {
 		s.handleDisposeWorkspace(w, r)
 	} else if strings.HasSuffix(path, "/dispose-all") {
 		s.handleDisposeWorkspaceAll(w, r)
+	} else if strings.HasSuffix(path, "/fork") {
+		s.handleForkWorkspace(w, r)
+	} else if strings.HasSuffix(path, "/refresh-overlay") {
+		s.handleRefreshOverlay(w, r)
+	} else if strings.HasSuffix(path, "/pin") {
+		s.handlePinWorkspace(w, r)
 	} else {
-		http.NotFound(w, r)
+		writeJSONError(w, "not found", http.StatusNotFound)
 	}
 }
 
@@ -3346,7 +5320,7 @@ func (s *Server) handleLinearSyncFromMain(w http.ResponseWriter, r *http.Request
 	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
 	workspaceID := strings.TrimSuffix(path, "/linear-sync-from-main")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -3414,7 +5388,7 @@ func (s *Server) handleLinearSyncToMain(w http.ResponseWriter, r *http.Request)
 	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
 	workspaceID := strings.TrimSuffix(path, "/linear-sync-to-main")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -3444,8 +5418,12 @@ func (s *Server) handleLinearSyncToMain(w http.ResponseWriter, r *http.Request)
 	result, err := s.workspace.LinearSyncToMain(ctx, workspaceID)
 	if err != nil {
 		fmt.Printf("[workspace] linear-sync-to-main error: workspace_id=%s error=%v\n", workspaceID, err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, workspace.ErrOffline) {
+			status = http.StatusConflict
+		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(LinearSyncResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to sync to main: %v", err),
@@ -3480,7 +5458,7 @@ func (s *Server) handleLinearSyncResolveConflict(w http.ResponseWriter, r *http.
 	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
 	workspaceID := strings.TrimSuffix(path, "/linear-sync-resolve-conflict")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -3591,6 +5569,7 @@ func (s *Server) handleLinearSyncResolveConflict(w http.ResponseWriter, r *http.
 					Confidence:         r.Confidence,
 					Summary:            r.Summary,
 					Files:              r.Files,
+					NeedsReview:        r.NeedsReview,
 				})
 			}
 			crState.Hash = result.Hash
@@ -3605,10 +5584,15 @@ func (s *Server) handleLinearSyncResolveConflict(w http.ResponseWriter, r *http.
 					Confidence:         r.Confidence,
 					Summary:            r.Summary,
 					Files:              r.Files,
+					NeedsReview:        r.NeedsReview,
 				})
 			}
 			crState.Hash = result.Hash
-			crState.Finish("failed", result.Message, resolutions)
+			status := "failed"
+			if result.NeedsReview {
+				status = "needs_review"
+			}
+			crState.Finish(status, result.Message, resolutions)
 		}
 
 		fmt.Printf("[workspace] linear-sync-resolve-conflict done: workspace_id=%s status=%s\n", workspaceID, crState.Status)
@@ -3630,7 +5614,7 @@ func (s *Server) handleDeleteLinearSyncResolveConflictState(w http.ResponseWrite
 	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
 	workspaceID := strings.TrimSuffix(path, "/linear-sync-resolve-conflict-state")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
@@ -3653,10 +5637,67 @@ func (s *Server) handleDeleteLinearSyncResolveConflictState(w http.ResponseWrite
 	w.WriteHeader(http.StatusOK)
 }
 
+// WorkspaceLockStatus is the response of GET /api/workspaces/{id}/lock.
+type WorkspaceLockStatus struct {
+	WorkspaceID string `json:"workspace_id"`
+	Locked      bool   `json:"locked"`
+	Holder      string `json:"holder,omitempty"` // operation holding the lock, e.g. "linear_sync_resolve_conflict"
+	StartedAt   string `json:"started_at,omitempty"`
+}
+
+// handleWorkspaceLock reports or force-releases a workspace's lock.
+// GET    /api/workspaces/{id}/lock - lock state and holder
+// DELETE /api/workspaces/{id}/lock - force-release a stuck lock
+// A workspace is locked while a resolve-conflict operation is in progress; git status
+// updates are skipped until it finishes. If that operation hangs or its goroutine dies,
+// the lock is never released, so DELETE marks the operation failed to free it.
+func (s *Server) handleWorkspaceLock(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/lock")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+	if _, found := s.state.GetWorkspace(workspaceID); !found {
+		writeJSONError(w, fmt.Sprintf("workspace not found: %s", workspaceID), http.StatusNotFound)
+		return
+	}
+
+	status := WorkspaceLockStatus{WorkspaceID: workspaceID}
+	crState := s.getLinearSyncResolveConflictState(workspaceID)
+	if crState != nil {
+		status.Holder, status.StartedAt, status.Locked = crState.LockInfo()
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	case http.MethodDelete:
+		if !status.Locked {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"released": false})
+			return
+		}
+		warning := "lock force-released; the " + status.Holder + " operation may still be running and the workspace may be mid-rebase"
+		fmt.Printf("[workspace] warning: force-releasing lock on %s held by %s since %s\n", workspaceID, status.Holder, status.StartedAt)
+		crState.Finish("failed", "Lock force-released by operator", nil)
+		go s.BroadcastSessions()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"released": true,
+			"warning":  warning,
+		})
+	default:
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
 // handleBuiltinQuickLaunch returns the list of built-in quick launch cookbooks.
 func (s *Server) handleBuiltinQuickLaunch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3678,7 +5719,7 @@ func (s *Server) handleBuiltinQuickLaunch(w http.ResponseWriter, r *http.Request
 		}
 		if readErr != nil {
 			fmt.Printf("[session] builtin-quick-launch: failed to read file: %v\n", readErr)
-			http.Error(w, "Failed to load built-in quick launch cookbooks", http.StatusInternalServerError)
+			writeJSONError(w, "Failed to load built-in quick launch cookbooks", http.StatusInternalServerError)
 			return
 		}
 	}
@@ -3686,7 +5727,7 @@ func (s *Server) handleBuiltinQuickLaunch(w http.ResponseWriter, r *http.Request
 	var cookbooks []BuiltinQuickLaunchCookbook
 	if err := json.Unmarshal(data, &cookbooks); err != nil {
 		fmt.Printf("[session] builtin-quick-launch: failed to parse: %v\n", err)
-		http.Error(w, "Failed to parse built-in quick launch cookbooks", http.StatusInternalServerError)
+		writeJSONError(w, "Failed to parse built-in quick launch cookbooks", http.StatusInternalServerError)
 		return
 	}
 
@@ -3718,7 +5759,7 @@ func (s *Server) handleBuiltinQuickLaunch(w http.ResponseWriter, r *http.Request
 // Response: {"conflict": false} or {"conflict": true, "workspace_id": "repo-001"}
 func (s *Server) handleCheckBranchConflict(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3727,12 +5768,12 @@ func (s *Server) handleCheckBranchConflict(w http.ResponseWriter, r *http.Reques
 		Branch string `json:"branch"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
 		return
 	}
 
 	if req.Repo == "" || req.Branch == "" {
-		http.Error(w, "repo and branch are required", http.StatusBadRequest)
+		writeJSONError(w, "repo and branch are required", http.StatusBadRequest)
 		return
 	}
 
@@ -3765,11 +5806,47 @@ func (s *Server) handleCheckBranchConflict(w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(BranchConflictResponse{Conflict: false})
 }
 
+// handleBranchExists reports whether a branch already exists for a repo.
+// GET /api/branch-exists?repo=<url>&branch=<name>
+func (s *Server) handleBranchExists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	repo := r.URL.Query().Get("repo")
+	branch := r.URL.Query().Get("branch")
+	if repo == "" || branch == "" {
+		writeJSONError(w, "repo and branch are required", http.StatusBadRequest)
+		return
+	}
+	if err := workspace.ValidateBranchName(branch); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !slices.ContainsFunc(s.config.GetRepos(), func(r config.Repo) bool { return r.URL == repo }) {
+		writeJSONError(w, "repo not found: "+repo, http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.GitFetchTimeout())
+	defer cancel()
+
+	exists, err := s.workspace.BranchExists(ctx, repo, branch)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exists)
+}
+
 // handleRecentBranches returns recent branches from all configured repos.
 // GET /api/recent-branches?limit=10
 func (s *Server) handleRecentBranches(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3791,7 +5868,7 @@ func (s *Server) handleRecentBranches(w http.ResponseWriter, r *http.Request) {
 
 	branches, err := s.workspace.GetRecentBranches(ctx, limit)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get recent branches: %v", err), http.StatusInternalServerError)
+		writeJSONError(w, fmt.Sprintf("Failed to get recent branches: %v", err), http.StatusInternalServerError)
 		return
 	}
 
@@ -3799,10 +5876,323 @@ func (s *Server) handleRecentBranches(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(branches)
 }
 
+// handleHistory returns disposed sessions from the history log, newest first.
+// GET /api/history?workspace_id=&repo=&branch=&target=&since=&until=&limit=100
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := session.HistoryFilter{
+		WorkspaceID: query.Get("workspace_id"),
+		Repo:        query.Get("repo"),
+		Branch:      query.Get("branch"),
+		Target:      query.Get("target"),
+		Limit:       100,
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 0 {
+			writeJSONError(w, "limit must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSONError(w, fmt.Sprintf("%s must be an RFC 3339 timestamp", bound.name), http.StatusBadRequest)
+			return
+		}
+		*bound.dst = parsed
+	}
+
+	entries, err := s.session.ReadHistory(filter)
+	if err != nil {
+		writeJSONError(w, fmt.Sprintf("Failed to read history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// handleWorkspaceCommits handles GET /api/workspaces/{id}/commits.
+// Returns recent commits on the workspace branch; a lighter alternative to git-graph.
+func (s *Server) handleWorkspaceCommits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract workspace ID: /api/workspaces/{id}/commits
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/commits")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	ws, ok := s.state.GetWorkspace(workspaceID)
+	if !ok {
+		writeJSONError(w, "workspace not found: "+workspaceID, http.StatusNotFound)
+		return
+	}
+	if ws.RemoteHostID != "" {
+		writeJSONError(w, "commits are not available for remote workspaces", http.StatusBadRequest)
+		return
+	}
+
+	limit := 0 // manager default
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			writeJSONError(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.GitDiffTimeout())
+	defer cancel()
+
+	resp, err := s.workspace.GetRecentCommits(ctx, workspaceID, limit)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleWorkspaceStashes handles GET /api/workspaces/{id}/stashes.
+// Lists the workspace's git stashes, newest first.
+func (s *Server) handleWorkspaceStashes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract workspace ID: /api/workspaces/{id}/stashes
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/stashes")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	ws, ok := s.state.GetWorkspace(workspaceID)
+	if !ok {
+		writeJSONError(w, fmt.Sprintf("workspace not found: %s", workspaceID), http.StatusNotFound)
+		return
+	}
+	if ws.RemoteHostID != "" {
+		writeJSONError(w, "stashes are not available for remote workspaces", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.GetGitStatusTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	stashes, err := s.workspace.ListStashes(ctx, workspaceID)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"stashes": stashes})
+}
+
+// handleWorkspaceStashAction restores a stash into its workspace.
+// POST /api/workspaces/{id}/stashes/{index}/pop   - apply the stash and drop it
+// POST /api/workspaces/{id}/stashes/{index}/apply - apply the stash and keep it
+// Refuses with 409 if the workspace has uncommitted changes or the stash conflicts.
+func (s *Server) handleWorkspaceStashAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract workspace ID, index, and action: /api/workspaces/{id}/stashes/{index}/{action}
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID, rest, _ := strings.Cut(path, "/stashes/")
+	indexStr, action, _ := strings.Cut(rest, "/")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+	if action != "pop" && action != "apply" {
+		writeJSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+	index, err := strconv.Atoi(indexStr)
+	if err != nil || index < 0 {
+		writeJSONError(w, "stash index must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+
+	ws, ok := s.state.GetWorkspace(workspaceID)
+	if !ok {
+		writeJSONError(w, fmt.Sprintf("workspace not found: %s", workspaceID), http.StatusNotFound)
+		return
+	}
+	if ws.RemoteHostID != "" {
+		writeJSONError(w, "stashes are not available for remote workspaces", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(s.config.GetGitCloneTimeoutMs())*time.Millisecond)
+	defer cancel()
+
+	fmt.Printf("[workspace] stash %s: workspace_id=%s index=%d\n", action, workspaceID, index)
+	if err := s.workspace.ApplyStash(ctx, workspaceID, index, action == "pop"); err != nil {
+		fmt.Printf("[workspace] stash %s error: workspace_id=%s error=%v\n", action, workspaceID, err)
+		switch {
+		case errors.Is(err, workspace.ErrStashNotFound):
+			writeJSONError(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, workspace.ErrWorkspaceLocked):
+			writeJSONErrorCode(w, err.Error(), "workspace_locked", http.StatusConflict)
+		case errors.Is(err, workspace.ErrStashConflict):
+			writeJSONErrorCode(w, err.Error(), "stash_conflict", http.StatusConflict)
+		default:
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if _, err := s.workspace.UpdateGitStatus(ctx, workspaceID); err != nil && !errors.Is(err, workspace.ErrWorkspaceLocked) {
+		fmt.Printf("[workspace] stash %s warning: failed to update git status: %v\n", action, err)
+	}
+	go s.BroadcastSessions()
+
+	message := fmt.Sprintf("stash@{%d} applied", index)
+	if action == "pop" {
+		message = fmt.Sprintf("stash@{%d} popped", index)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": message,
+	})
+}
+
+// handleWorkspacePrompts returns a workspace's prompt history, newest first.
+// GET /api/workspaces/{id}/prompts
+func (s *Server) handleWorkspacePrompts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/prompts")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+	ws, ok := s.state.GetWorkspace(workspaceID)
+	if !ok {
+		writeJSONError(w, fmt.Sprintf("workspace not found: %s", workspaceID), http.StatusNotFound)
+		return
+	}
+
+	prompts := make([]state.PromptHistoryEntry, 0, len(ws.PromptHistory))
+	for i := len(ws.PromptHistory) - 1; i >= 0; i-- {
+		prompts = append(prompts, ws.PromptHistory[i])
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"enabled": s.config.GetPromptHistorySize() > 0,
+		"prompts": prompts,
+	})
+}
+
+// PromptRerunRequest is the body of POST /api/workspaces/{id}/prompts/{promptId}/rerun.
+type PromptRerunRequest struct {
+	Target         string `json:"target,omitempty"`          // defaults to the target the prompt was spawned with
+	Nickname       string `json:"nickname,omitempty"`        // defaults to the nickname the prompt was spawned with
+	AllowProtected bool   `json:"allow_protected,omitempty"` // as for POST /api/spawn
+}
+
+// handleWorkspacePromptRerun spawns a fresh session in the workspace with a prompt
+// from its history.
+// POST /api/workspaces/{id}/prompts/{promptId}/rerun
+func (s *Server) handleWorkspacePromptRerun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID, rest, _ := strings.Cut(path, "/prompts/")
+	promptID, action, _ := strings.Cut(rest, "/")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+	if action != "rerun" {
+		writeJSONError(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	var req PromptRerunRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	ws, ok := s.state.GetWorkspace(workspaceID)
+	if !ok {
+		writeJSONError(w, fmt.Sprintf("workspace not found: %s", workspaceID), http.StatusNotFound)
+		return
+	}
+	var entry *state.PromptHistoryEntry
+	for i := range ws.PromptHistory {
+		if ws.PromptHistory[i].ID == promptID {
+			entry = &ws.PromptHistory[i]
+			break
+		}
+	}
+	if entry == nil {
+		writeJSONError(w, fmt.Sprintf("prompt not found: %s", promptID), http.StatusNotFound)
+		return
+	}
+
+	target := strings.TrimSpace(req.Target)
+	if target == "" {
+		target = entry.Target
+	}
+	nickname := strings.TrimSpace(req.Nickname)
+	if nickname == "" {
+		nickname = entry.Nickname
+	}
+	fmt.Printf("[session] re-running prompt: workspace_id=%s prompt_id=%s target=%s\n", workspaceID, promptID, target)
+	s.spawn(w, SpawnRequest{
+		WorkspaceID:    workspaceID,
+		Targets:        map[string]int{target: 1},
+		Prompt:         entry.Prompt,
+		Nickname:       nickname,
+		AllowProtected: req.AllowProtected,
+	})
+}
+
 // handleWorkspaceGitGraph handles GET /api/workspaces/{id}/git-graph.
 func (s *Server) handleWorkspaceGitGraph(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		writeJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -3810,16 +6200,14 @@ func (s *Server) handleWorkspaceGitGraph(w http.ResponseWriter, r *http.Request)
 	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
 	workspaceID := strings.TrimSuffix(path, "/git-graph")
 	if workspaceID == "" {
-		http.Error(w, "workspace ID is required", http.StatusBadRequest)
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
 		return
 	}
 
 	// Verify workspace exists
 	ws, ok := s.state.GetWorkspace(workspaceID)
 	if !ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "workspace not found: " + workspaceID})
+		writeJSONError(w, "workspace not found: "+workspaceID, http.StatusNotFound)
 		return
 	}
 
@@ -3849,9 +6237,7 @@ func (s *Server) handleWorkspaceGitGraph(w http.ResponseWriter, r *http.Request)
 
 	resp, err := s.workspace.GetGitGraph(ctx, workspaceID, maxCommits, contextSize)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -3944,3 +6330,52 @@ func shellSplit(input string) ([]string, error) {
 
 	return args, nil
 }
+
+// PinWorkspaceRequest is the optional body of POST /api/workspaces/{id}/pin.
+// Without Pinned the pin is toggled.
+type PinWorkspaceRequest struct {
+	Pinned *bool `json:"pinned,omitempty"`
+}
+
+// handlePinWorkspace pins or unpins a workspace so it sorts to the top of the dashboard.
+// POST /api/workspaces/{id}/pin
+func (s *Server) handlePinWorkspace(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/workspaces/")
+	workspaceID := strings.TrimSuffix(path, "/pin")
+	if workspaceID == "" {
+		writeJSONError(w, "workspace ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req PinWorkspaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeJSONError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	ws, found := s.state.GetWorkspace(workspaceID)
+	if !found {
+		writeJSONError(w, fmt.Sprintf("workspace not found: %s", workspaceID), http.StatusNotFound)
+		return
+	}
+	if req.Pinned != nil {
+		ws.Pinned = *req.Pinned
+	} else {
+		ws.Pinned = !ws.Pinned
+	}
+	if err := s.state.UpdateWorkspace(ws); err != nil {
+		writeJSONError(w, fmt.Sprintf("Failed to update workspace: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := s.state.Save(); err != nil {
+		fmt.Printf("[workspace] failed to save state after pin: %v\n", err)
+	}
+	fmt.Printf("[workspace] %s pinned=%v\n", workspaceID, ws.Pinned)
+	go s.BroadcastSessions()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"workspace_id": workspaceID,
+		"pinned":       ws.Pinned,
+	})
+}
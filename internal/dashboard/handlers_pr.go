@@ -3,12 +3,14 @@ package dashboard
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	gh "github.com/sergeknystautas/schmux/internal/github"
+	"github.com/sergeknystautas/schmux/internal/workspace"
 )
 
 // handlePRs handles GET /api/prs - returns cached PRs.
@@ -105,6 +107,13 @@ func (s *Server) handlePRCheckout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.workspace.Maintenance().BlockSpawns {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": errSpawnsBlocked})
+		return
+	}
+
 	// Determine target for session (explicit config required)
 	target := s.config.GetPrReviewTarget()
 	if target == "" {
@@ -121,8 +130,12 @@ func (s *Server) handlePRCheckout(w http.ResponseWriter, r *http.Request) {
 	ws, err := s.workspace.CheckoutPR(ctx, pr)
 	if err != nil {
 		fmt.Printf("[pr] checkout failed: %v\n", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, workspace.ErrOffline) {
+			status = http.StatusConflict
+		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Failed to checkout PR: %v", err)})
 		return
 	}
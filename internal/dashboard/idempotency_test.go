@@ -0,0 +1,70 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+func TestSpawnIdempotencyKey(t *testing.T) {
+	server, cfg, _ := newTestServer(t)
+
+	post := func(key string, req SpawnRequest) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(req)
+		r := httptest.NewRequest(http.MethodPost, "/api/spawn", bytes.NewReader(body))
+		if key != "" {
+			r.Header.Set("Idempotency-Key", key)
+		}
+		rr := httptest.NewRecorder()
+		server.handleSpawnPost(rr, r)
+		return rr
+	}
+	missingBranch := SpawnRequest{Repo: "https://example.com/repo.git", Targets: map[string]int{"promptable": 1}}
+
+	first := post("job-1", missingBranch)
+	if first.Code != http.StatusBadRequest || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("first request: status %d, replayed %q", first.Code, first.Header().Get("Idempotent-Replayed"))
+	}
+	retry := post("job-1", missingBranch)
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %q, want %d %q", retry.Code, retry.Body.String(), first.Code, first.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("retry was not marked as replayed")
+	}
+
+	other := missingBranch
+	other.Branch = "main"
+	other.Prompt = "different"
+	if rr := post("job-1", other); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with a different body: status %d, want 422", rr.Code)
+	}
+
+	// Server errors aren't cached, so a retry spawns again
+	server.workspace.SetMaintenance(true, true)
+	if rr := post("job-2", missingBranch); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("blocked spawn: status %d, want 503", rr.Code)
+	}
+	server.workspace.SetMaintenance(false, false)
+	if rr := post("job-2", missingBranch); rr.Code != http.StatusBadRequest || rr.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retry after 503: status %d, replayed %q", rr.Code, rr.Header().Get("Idempotent-Replayed"))
+	}
+
+	// Failed spawns aren't cached either, so a retry once the target exists spawns again
+	missingTarget := SpawnRequest{Repo: "https://example.com/repo.git", Branch: "main", Targets: map[string]int{"later": 1}}
+	failed := post("job-3", missingTarget)
+	if failed.Code != http.StatusOK || !strings.Contains(failed.Body.String(), "target not found: later") {
+		t.Fatalf("failed spawn: %d %q", failed.Code, failed.Body.String())
+	}
+	cfg.RunTargets = append(cfg.RunTargets, config.RunTarget{Name: "later", Type: config.RunTargetTypeCommand, Command: "echo later", Source: config.RunTargetSourceUser})
+	rr := post("job-3", missingTarget)
+	if rr.Header().Get("Idempotent-Replayed") != "" || strings.Contains(rr.Body.String(), "target not found") {
+		t.Errorf("retry after failed spawn was replayed: %d %q", rr.Code, rr.Body.String())
+	}
+}
@@ -9,7 +9,7 @@ import (
 // LinearSyncResolveConflictStep represents a single step in the conflict resolution process.
 type LinearSyncResolveConflictStep struct {
 	Action             string   `json:"action"`
-	Status             string   `json:"status"` // "in_progress", "done", "failed"
+	Status             string   `json:"status"` // "in_progress", "done", "failed", "needs_review"
 	Message            string   `json:"message"`
 	At                 string   `json:"at"`
 	LocalCommit        string   `json:"local_commit,omitempty"`
@@ -28,6 +28,7 @@ type LinearSyncResolveConflictResolution struct {
 	Confidence         string   `json:"confidence"`
 	Summary            string   `json:"summary"`
 	Files              []string `json:"files"`
+	NeedsReview        bool     `json:"needs_review,omitempty"`
 }
 
 // LinearSyncResolveConflictState is the full operation state, broadcast over the dashboard WebSocket.
@@ -35,7 +36,7 @@ type LinearSyncResolveConflictState struct {
 	mu          sync.Mutex                            `json:"-"`
 	Type        string                                `json:"type"` // always "linear_sync_resolve_conflict"
 	WorkspaceID string                                `json:"workspace_id"`
-	Status      string                                `json:"status"` // "in_progress", "done", "failed"
+	Status      string                                `json:"status"` // "in_progress", "done", "failed", "needs_review"
 	Hash        string                                `json:"hash,omitempty"`
 	StartedAt   string                                `json:"started_at"`
 	FinishedAt  string                                `json:"finished_at,omitempty"`
@@ -93,6 +94,17 @@ func (s *LinearSyncResolveConflictState) Finish(status, message string, resoluti
 	s.Resolutions = resolutions
 }
 
+// LockInfo reports whether the operation still holds the workspace lock, and if so
+// which operation type holds it and since when.
+func (s *LinearSyncResolveConflictState) LockInfo() (holder, startedAt string, locked bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Status != "in_progress" {
+		return "", "", false
+	}
+	return s.Type, s.StartedAt, true
+}
+
 // SetHash sets the rebased hash if it hasn't been set yet.
 func (s *LinearSyncResolveConflictState) SetHash(hash string) {
 	if hash == "" {
@@ -0,0 +1,47 @@
+package dashboard
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+// modelsCacheTTL bounds how long a model list is reused. Config and secret changes
+// made through the API invalidate it straight away; the TTL only covers edits made
+// to the files by hand.
+const modelsCacheTTL = 10 * time.Second
+
+// modelsCache is a read-through cache of buildAvailableModels. Building the list
+// re-reads the secrets file once per model, and the dashboard asks for it on every
+// /api/config, /api/models, and /api/spawn-options request.
+type modelsCache struct {
+	mu      sync.Mutex
+	models  []contracts.Model
+	expires time.Time
+}
+
+// get returns the cached model list, rebuilding it from cfg once it has expired.
+// The caller gets its own copy of the slice.
+func (c *modelsCache) get(cfg *config.Config) ([]contracts.Model, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.models == nil || time.Now().After(c.expires) {
+		models, err := buildAvailableModels(cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.models = models
+		c.expires = time.Now().Add(modelsCacheTTL)
+	}
+	return slices.Clone(c.models), nil
+}
+
+// invalidate drops the cached model list so the next get rebuilds it.
+func (c *modelsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.models = nil
+}
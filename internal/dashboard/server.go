@@ -4,15 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/assets"
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/difftool"
@@ -49,6 +53,16 @@ func (w *wsConn) WriteMessage(messageType int, data []byte) error {
 	return w.conn.WriteMessage(messageType, data)
 }
 
+// WriteControl writes a control message, such as a ping, in a thread-safe manner.
+func (w *wsConn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return fmt.Errorf("websocket connection closed")
+	}
+	return w.conn.WriteControl(messageType, data, deadline)
+}
+
 // ReadMessage reads a message from the websocket connection.
 func (w *wsConn) ReadMessage() (messageType int, p []byte, err error) {
 	return w.conn.ReadMessage()
@@ -82,6 +96,7 @@ type Server struct {
 	workspace  workspace.WorkspaceManager
 	httpServer *http.Server
 	shutdown   func() // Callback to trigger daemon shutdown
+	startedAt  time.Time
 
 	// WebSocket connection registry: sessionID -> active connection (for terminal)
 	// Only one connection per session; new connections displace old ones.
@@ -121,6 +136,22 @@ type Server struct {
 	// Linear sync resolve conflict operation states (in-memory, keyed by workspace ID)
 	linearSyncResolveConflictStates   map[string]*LinearSyncResolveConflictState
 	linearSyncResolveConflictStatesMu sync.RWMutex
+
+	// Available models with their secrets status, shared by the config, models, and spawn-options endpoints
+	models modelsCache
+
+	// In-progress spawns that supplied a spawn_id, so POST /api/spawn/{id}/cancel can abort
+	// them and GET /api/spawn/{id}/progress can report their workspace step. Both maps
+	// are guarded by spawnCancelsMu.
+	spawnCancels   map[string]context.CancelFunc
+	spawnProgress  map[string]contracts.SpawnProgressResponse
+	spawnCancelsMu sync.Mutex
+
+	// Results of POST /api/spawn requests that carried an Idempotency-Key
+	spawnIdempotency *idempotencyCache
+
+	// External diff tools launched by POST /api/diff-external/{id}, closed on request or at shutdown
+	diffTools *difftool.Launches
 }
 
 // versionInfo holds version information.
@@ -141,11 +172,16 @@ func NewServer(cfg *config.Config, st state.StateStore, statePath string, sm *se
 		workspace:                       wm,
 		prDiscovery:                     prd,
 		shutdown:                        shutdown,
+		startedAt:                       time.Now(),
+		spawnIdempotency:                newIdempotencyCache(),
 		wsConns:                         make(map[string]*wsConn),
 		sessionsConns:                   make(map[*wsConn]bool),
 		rotationLocks:                   make(map[string]*sync.Mutex),
 		broadcastDone:                   make(chan struct{}),
 		linearSyncResolveConflictStates: make(map[string]*LinearSyncResolveConflictState),
+		spawnCancels:                    make(map[string]context.CancelFunc),
+		spawnProgress:                   make(map[string]contracts.SpawnProgressResponse),
+		diffTools:                       difftool.NewLaunches(),
 		connectLimiter:                  NewRateLimiter(3, 1*time.Minute), // 3 connects per minute
 	}
 	if mgr, ok := wm.(*workspace.Manager); ok {
@@ -220,24 +256,41 @@ func (s *Server) Start() error {
 	mux.HandleFunc("/api/hasNudgenik", s.withCORS(s.withAuth(s.handleHasNudgenik)))
 	mux.HandleFunc("/api/askNudgenik/", s.withCORS(s.withAuth(s.handleAskNudgenik)))
 	mux.HandleFunc("/api/workspaces/scan", s.withCORS(s.withAuth(s.handleWorkspacesScan)))
+	mux.HandleFunc("/api/workspaces/import", s.withCORS(s.withAuth(s.handleWorkspacesImport)))
+	mux.HandleFunc("/api/workspaces/migrate", s.withCORS(s.withAuth(s.handleWorkspacesMigrate)))
 	mux.HandleFunc("/api/workspaces/", s.withCORS(s.withAuth(s.handleLinearSync)))
 	mux.HandleFunc("/api/sessions", s.withCORS(s.withAuth(s.handleSessions)))
 	mux.HandleFunc("/api/sessions-nickname/", s.withCORS(s.withAuth(s.handleUpdateNickname)))
 	mux.HandleFunc("/api/spawn", s.withCORS(s.withAuth(s.handleSpawnPost)))
+	mux.HandleFunc("/api/spawn/", s.withCORS(s.withAuth(s.handleSpawnByID)))
+	mux.HandleFunc("/api/spawn-default", s.withCORS(s.withAuth(s.handleSpawnDefault)))
 	mux.HandleFunc("/api/check-branch-conflict", s.withCORS(s.withAuth(s.handleCheckBranchConflict)))
 	mux.HandleFunc("/api/recent-branches", s.withCORS(s.withAuth(s.handleRecentBranches)))
+	mux.HandleFunc("/api/history", s.withCORS(s.withAuth(s.handleHistory)))
+	mux.HandleFunc("/api/branch-exists", s.withCORS(s.withAuth(s.handleBranchExists)))
 	mux.HandleFunc("/api/suggest-branch", s.withCORS(s.withAuth(s.handleSuggestBranch)))
 	mux.HandleFunc("/api/prepare-branch-spawn", s.withCORS(s.withAuth(s.handlePrepareBranchSpawn)))
 	mux.HandleFunc("/api/sessions/", s.withCORS(s.withAuth(s.handleDispose)))
+	mux.HandleFunc("/api/sessions/adopt", s.withCORS(s.withAuth(s.handleAdoptSession)))
+	mux.HandleFunc("/api/sessions/reconcile", s.withCORS(s.withAuth(s.handleReconcileSessions)))
 	mux.HandleFunc("/api/config", s.withCORS(s.withAuth(s.handleConfig)))
+	mux.HandleFunc("/api/nudgenik/target", s.withCORS(s.withAuth(s.handleNudgenikTarget)))
+	mux.HandleFunc("/api/branch-suggest/target", s.withCORS(s.withAuth(s.handleBranchSuggestTarget)))
+	mux.HandleFunc("/api/conflict-resolve/target", s.withCORS(s.withAuth(s.handleConflictResolveTarget)))
 	mux.HandleFunc("/api/detect-tools", s.withCORS(s.withAuth(s.handleDetectTools)))
 	mux.HandleFunc("/api/models", s.withCORS(s.withAuth(s.handleModels)))
+	mux.HandleFunc("/api/spawn-options", s.withCORS(s.withAuth(s.handleSpawnOptions)))
 	mux.HandleFunc("/api/models/", s.withCORS(s.withAuth(s.handleModel)))
+	mux.HandleFunc("/api/targets/", s.withCORS(s.withAuth(s.handleTargetTest)))
 	mux.HandleFunc("/api/builtin-quick-launch", s.withCORS(s.withAuth(s.handleBuiltinQuickLaunch)))
 	mux.HandleFunc("/api/diff/", s.withCORS(s.withAuth(s.handleDiff)))
 	mux.HandleFunc("/api/diff-external/", s.withCORS(s.withAuth(s.handleDiffExternal)))
 	mux.HandleFunc("/api/open-vscode/", s.withCORS(s.withAuth(s.handleOpenVSCode)))
 	mux.HandleFunc("/api/overlays", s.withCORS(s.withAuth(s.handleOverlays)))
+	mux.HandleFunc("/api/overlays/", s.withCORS(s.withAuth(s.handleOverlayFiles)))
+	mux.HandleFunc("/api/repos/", s.withCORS(s.withAuth(s.handleRepoRefreshOverlay)))
+	mux.HandleFunc("/api/base-repos/prune", s.withCORS(s.withAuth(s.handleBaseReposPrune)))
+	mux.HandleFunc("/api/maintenance", s.withCORS(s.withAuth(s.handleMaintenance)))
 	mux.HandleFunc("/api/prs", s.withCORS(s.withAuth(s.handlePRs)))
 	mux.HandleFunc("/api/prs/refresh", s.withCORS(s.withAuth(s.handlePRRefresh)))
 	mux.HandleFunc("/api/prs/checkout", s.withCORS(s.withAuth(s.handlePRCheckout)))
@@ -260,13 +313,25 @@ func (s *Server) Start() error {
 	// WebSocket for real-time dashboard state updates
 	mux.HandleFunc("/ws/dashboard", s.handleDashboardWebSocket)
 
-	// Bind address from config
-	bindAddr := s.config.GetBindAddress()
-
+	// Bind addresses from config
+	bindAddrs := s.config.GetBindAddresses()
 	port := s.config.GetPort()
+
+	listeners := make([]net.Listener, 0, len(bindAddrs))
+	for _, addr := range bindAddrs {
+		l, err := net.Listen("tcp", net.JoinHostPort(addr, strconv.Itoa(port)))
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return fmt.Errorf("server error: %w", err)
+		}
+		listeners = append(listeners, l)
+	}
+
 	s.httpServer = &http.Server{
-		Addr:         fmt.Sprintf("%s:%d", bindAddr, port),
-		Handler:      mux,
+		Addr:         listeners[0].Addr().String(),
+		Handler:      s.withReadOnly(mux),
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 	}
@@ -277,21 +342,37 @@ func (s *Server) Start() error {
 	}
 	if s.config.GetNetworkAccess() {
 		fmt.Printf("[daemon] listening on %s://0.0.0.0:%d (accessible from local network)\n", scheme, port)
-	} else {
+	} else if len(bindAddrs) == 1 && s.config.GetDashboardHost() == "localhost" {
 		fmt.Printf("[daemon] listening on %s://localhost:%d (localhost only)\n", scheme, port)
+	} else {
+		for _, l := range listeners {
+			fmt.Printf("[daemon] listening on %s://%s\n", scheme, l.Addr().String())
+		}
 	}
 
-	if s.config.GetAuthEnabled() {
-		certPath := s.config.GetTLSCertPath()
-		keyPath := s.config.GetTLSKeyPath()
-		if err := s.httpServer.ListenAndServeTLS(certPath, keyPath); err != nil && err != http.ErrServerClosed {
-			return fmt.Errorf("server error: %w", err)
-		}
-		return nil
+	certPath := s.config.GetTLSCertPath()
+	keyPath := s.config.GetTLSKeyPath()
+	errCh := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			if s.config.GetAuthEnabled() {
+				errCh <- s.httpServer.ServeTLS(l, certPath, keyPath)
+			} else {
+				errCh <- s.httpServer.Serve(l)
+			}
+		}(l)
 	}
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("server error: %w", err)
+	// Every listener returns once the server shuts down; a failure on one stops the rest.
+	var serveErr error
+	for range listeners {
+		if err := <-errCh; err != nil && err != http.ErrServerClosed && serveErr == nil {
+			serveErr = err
+			s.httpServer.Close()
+		}
+	}
+	if serveErr != nil {
+		return fmt.Errorf("server error: %w", serveErr)
 	}
 
 	return nil
@@ -318,6 +399,12 @@ func (s *Server) Stop() error {
 		close(s.broadcastDone)
 	})
 
+	// Don't leave diff tools running against temp dirs nobody will clean up.
+	s.diffTools.CloseAll()
+
+	// Shutdown doesn't touch hijacked websocket connections.
+	s.closeDashboardConns()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -350,7 +437,9 @@ func (s *Server) withCORS(h http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-CSRF-Token")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-CSRF-Token, Idempotency-Key")
+		// Response headers that are part of the API, so cross-origin clients can read them
+		w.Header().Set("Access-Control-Expose-Headers", contracts.AttentionCountHeader+", Idempotent-Replayed")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -361,6 +450,22 @@ func (s *Server) withCORS(h http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// withReadOnly rejects every /api request that isn't a GET, HEAD, or OPTIONS while
+// access_control.read_only is set. It wraps the whole mux so no route can be missed.
+func (s *Server) withReadOnly(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config.GetReadOnly() && strings.HasPrefix(r.URL.Path, "/api/") {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				writeJSONError(w, "dashboard is read-only (access_control.read_only)", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 // isAllowedOrigin checks if a request origin should be permitted.
 // Allowed origins:
 //   - The configured public_base_url (https when auth enabled, http when disabled)
@@ -400,10 +505,42 @@ func (s *Server) isAllowedOrigin(origin string) bool {
 		return true
 	}
 
+	// Allow the specific interfaces the server listens on
+	for _, addr := range s.config.GetBindAddresses() {
+		if origin == fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(addr, strconv.Itoa(port))) {
+			return true
+		}
+	}
+
 	// Allow any origin if network access is enabled
 	return s.config.GetNetworkAccess()
 }
 
+// isAllowedWebSocketOrigin checks the Origin of a WebSocket upgrade. On top of the
+// origins isAllowedOrigin permits, it accepts network.ws_allowed_origins and, when auth
+// is enabled, same-origin requests (Origin host matches Host), which is what a browser
+// sends through a reverse proxy that preserves Host. Same-origin alone is not trusted
+// without auth: a DNS-rebound hostname pointing at localhost would pass it.
+func (s *Server) isAllowedWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if s.isAllowedOrigin(origin) {
+		return true
+	}
+	if origin == "" {
+		return false
+	}
+	if slices.Contains(s.config.GetWSAllowedOrigins(), origin) {
+		return true
+	}
+	if s.config.GetAuthEnabled() {
+		if parsed, err := url.Parse(origin); err == nil && parsed.Host != "" && strings.EqualFold(parsed.Host, r.Host) {
+			return true
+		}
+	}
+	fmt.Printf("[daemon] rejected websocket origin: %s for %s\n", origin, r.URL.Path)
+	return false
+}
+
 // normalizeOrigin extracts scheme://host from a URL string.
 func normalizeOrigin(value string) (string, error) {
 	parsed, err := url.Parse(value)
@@ -611,12 +748,13 @@ func (s *Server) broadcastLoop() {
 // doBroadcast performs the actual broadcast to all connected WebSocket clients.
 func (s *Server) doBroadcast() {
 	// Build the sessions response
-	data := s.buildSessionsResponse()
+	data, attention := s.buildSessionsResponse()
 
 	// Marshal to JSON with type field
 	payload, err := json.Marshal(map[string]interface{}{
-		"type":       "sessions",
-		"workspaces": data,
+		"type":            "sessions",
+		"workspaces":      data,
+		"attention_count": attention,
 	})
 	if err != nil {
 		fmt.Printf("[ws/dashboard] failed to marshal response: %v\n", err)
@@ -659,6 +797,142 @@ func (s *Server) doBroadcast() {
 	}
 }
 
+// clientPollIntervals are the config values that set how often dashboard clients poll.
+type clientPollIntervals struct {
+	DashboardPollIntervalMs int `json:"dashboard_poll_interval_ms"`
+	NudgenikViewedBufferMs  int `json:"nudgenik_viewed_buffer_ms"`
+	NudgenikSeenIntervalMs  int `json:"nudgenik_seen_interval_ms"`
+}
+
+func (s *Server) clientPollIntervals() clientPollIntervals {
+	return clientPollIntervals{
+		DashboardPollIntervalMs: s.config.GetDashboardPollIntervalMs(),
+		NudgenikViewedBufferMs:  s.config.GetNudgenikViewedBufferMs(),
+		NudgenikSeenIntervalMs:  s.config.GetNudgenikSeenIntervalMs(),
+	}
+}
+
+// BroadcastConfig tells connected dashboards that the config changed, so they reload it
+// and adopt new poll intervals without a page refresh. Unlike BroadcastSessions it is
+// sent immediately; config saves are rare.
+func (s *Server) BroadcastConfig() {
+	payload, err := json.Marshal(struct {
+		Type string `json:"type"` // always "config"
+		clientPollIntervals
+	}{Type: "config", clientPollIntervals: s.clientPollIntervals()})
+	if err != nil {
+		fmt.Printf("[ws/dashboard] failed to marshal config message: %v\n", err)
+		return
+	}
+
+	s.sessionsConnsMu.RLock()
+	conns := make([]*wsConn, 0, len(s.sessionsConns))
+	for conn := range s.sessionsConns {
+		conns = append(conns, conn)
+	}
+	s.sessionsConnsMu.RUnlock()
+
+	for _, conn := range conns {
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			s.UnregisterDashboardConn(conn)
+			conn.Close()
+		}
+	}
+}
+
+// maintenancePayload is the "maintenance" message telling dashboards whether
+// maintenance mode is on.
+func (s *Server) maintenancePayload() ([]byte, error) {
+	return json.Marshal(struct {
+		Type string `json:"type"` // always "maintenance"
+		contracts.MaintenanceResponse
+	}{Type: "maintenance", MaintenanceResponse: maintenanceResponse(s.workspace.Maintenance())})
+}
+
+// BroadcastMaintenance tells connected dashboards that maintenance mode changed.
+func (s *Server) BroadcastMaintenance() {
+	payload, err := s.maintenancePayload()
+	if err != nil {
+		fmt.Printf("[ws/dashboard] failed to marshal maintenance message: %v\n", err)
+		return
+	}
+
+	s.sessionsConnsMu.RLock()
+	conns := make([]*wsConn, 0, len(s.sessionsConns))
+	for conn := range s.sessionsConns {
+		conns = append(conns, conn)
+	}
+	s.sessionsConnsMu.RUnlock()
+
+	for _, conn := range conns {
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			s.UnregisterDashboardConn(conn)
+			conn.Close()
+		}
+	}
+}
+
+func (s *Server) dashboardPingInterval() time.Duration {
+	return time.Duration(s.config.GetDashboardPingIntervalMs()) * time.Millisecond
+}
+
+// dashboardHeartbeat pings a dashboard connection until done is closed or a write
+// fails. Browsers answer ping frames without telling the page, so each ping is paired
+// with a "heartbeat" message; a client that stops hearing them knows the connection
+// is dead and reconnects.
+func (s *Server) dashboardHeartbeat(conn *wsConn, done <-chan struct{}) {
+	for {
+		// The interval is re-read each time so a config change applies without reconnecting
+		interval := s.dashboardPingInterval()
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-done:
+			timer.Stop()
+			return
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"type":        "heartbeat",
+			"interval_ms": interval.Milliseconds(),
+		})
+		if err != nil {
+			fmt.Printf("[ws/dashboard] failed to marshal heartbeat: %v\n", err)
+			return
+		}
+		if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+			conn.Close()
+			return
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			conn.Close()
+			return
+		}
+	}
+}
+
+// closeDashboardConns tells connected dashboards to reconnect and closes their
+// connections, so a daemon restart shows up as a reconnect rather than a dashboard
+// that quietly stops updating.
+func (s *Server) closeDashboardConns() {
+	payload, _ := json.Marshal(map[string]string{
+		"type":    "reconnect",
+		"content": "Daemon shutting down, please reconnect",
+	})
+
+	s.sessionsConnsMu.RLock()
+	conns := make([]*wsConn, 0, len(s.sessionsConns))
+	for conn := range s.sessionsConns {
+		conns = append(conns, conn)
+	}
+	s.sessionsConnsMu.RUnlock()
+
+	for _, conn := range conns {
+		conn.WriteMessage(websocket.TextMessage, payload)
+		conn.Close()
+	}
+}
+
 // handleDashboardWebSocket handles WebSocket connections for real-time dashboard updates.
 func (s *Server) handleDashboardWebSocket(w http.ResponseWriter, r *http.Request) {
 	// Authenticate if auth is enabled
@@ -672,11 +946,10 @@ func (s *Server) handleDashboardWebSocket(w http.ResponseWriter, r *http.Request
 	// Upgrade connection
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
-			origin := r.Header.Get("Origin")
-			if origin == "" {
+			if r.Header.Get("Origin") == "" {
 				return true
 			}
-			return s.isAllowedOrigin(origin)
+			return s.isAllowedWebSocketOrigin(r)
 		},
 	}
 
@@ -695,10 +968,11 @@ func (s *Server) handleDashboardWebSocket(w http.ResponseWriter, r *http.Request
 	defer s.UnregisterDashboardConn(conn)
 
 	// Send initial full state with type field
-	data := s.buildSessionsResponse()
+	data, attention := s.buildSessionsResponse()
 	payload, err := json.Marshal(map[string]interface{}{
-		"type":       "sessions",
-		"workspaces": data,
+		"type":            "sessions",
+		"workspaces":      data,
+		"attention_count": attention,
 	})
 	if err != nil {
 		fmt.Printf("[ws/dashboard] failed to marshal initial response: %v\n", err)
@@ -719,6 +993,25 @@ func (s *Server) handleDashboardWebSocket(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// Dashboards assume maintenance mode is off until told otherwise
+	if s.workspace.Maintenance().Enabled {
+		if mPayload, err := s.maintenancePayload(); err == nil {
+			if err := conn.WriteMessage(websocket.TextMessage, mPayload); err != nil {
+				return
+			}
+		}
+	}
+
+	// Ping on an interval so proxies don't drop the connection as idle. A client
+	// that stops answering pings is dropped when the read below times out.
+	done := make(chan struct{})
+	defer close(done)
+	rawConn.SetReadDeadline(time.Now().Add(2 * s.dashboardPingInterval()))
+	rawConn.SetPongHandler(func(string) error {
+		return rawConn.SetReadDeadline(time.Now().Add(2 * s.dashboardPingInterval()))
+	})
+	go s.dashboardHeartbeat(conn, done)
+
 	// Keep connection alive - read messages (client doesn't send any, but we need to detect close)
 	for {
 		_, _, err := conn.ReadMessage()
@@ -1,8 +1,12 @@
 package dashboard
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/sergeknystautas/schmux/internal/config"
@@ -111,6 +115,23 @@ func TestIsAllowedOrigin(t *testing.T) {
 		}
 	})
 
+	t.Run("bound interface origins allowed", func(t *testing.T) {
+		cfg := &config.Config{
+			Network: &config.NetworkConfig{
+				Port:          7337,
+				BindAddresses: []string{"127.0.0.1", "10.8.0.2"},
+			},
+		}
+		s := &Server{config: cfg}
+
+		if !s.isAllowedOrigin("http://10.8.0.2:7337") {
+			t.Error("origin of a bound interface should be allowed")
+		}
+		if s.isAllowedOrigin("http://192.168.1.100:7337") {
+			t.Error("origin of an unbound interface should be rejected")
+		}
+	})
+
 	t.Run("default port used when not configured", func(t *testing.T) {
 		cfg := &config.Config{}
 		s := &Server{config: cfg}
@@ -274,3 +295,249 @@ func TestBroadcastToSession(t *testing.T) {
 		}
 	})
 }
+
+func TestBroadcastConfigOnPollIntervalChange(t *testing.T) {
+	server, _, _ := newTestServer(t)
+	ts := httptest.NewServer(http.HandlerFunc(server.handleDashboardWebSocket))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); err != nil { // initial sessions state
+		t.Fatalf("read initial state: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handleConfigUpdate(rr, httptest.NewRequest(http.MethodPost, "/api/config", strings.NewReader(`{"sessions":{"dashboard_poll_interval_ms":1234}}`)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("config update: %d %s", rr.Code, rr.Body.String())
+	}
+
+	var msg struct {
+		Type                    string `json:"type"`
+		DashboardPollIntervalMs int    `json:"dashboard_poll_interval_ms"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read config message: %v", err)
+	}
+	if msg.Type != "config" || msg.DashboardPollIntervalMs != 1234 {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
+
+func TestDashboardWebSocketHeartbeat(t *testing.T) {
+	server, cfg, _ := newTestServer(t)
+	cfg.Sessions = &config.SessionsConfig{DashboardPingIntervalMs: 50}
+	ts := httptest.NewServer(http.HandlerFunc(server.handleDashboardWebSocket))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, _, err := conn.ReadMessage(); err != nil { // initial sessions state
+		t.Fatalf("read initial state: %v", err)
+	}
+
+	var msg struct {
+		Type       string `json:"type"`
+		IntervalMs int    `json:"interval_ms"`
+		Content    string `json:"content"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read heartbeat: %v", err)
+	}
+	if msg.Type != "heartbeat" || msg.IntervalMs != 50 {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	select {
+	case <-pinged:
+	default:
+		t.Error("heartbeat was not preceded by a ping frame")
+	}
+
+	// Shutting down tells the dashboard to reconnect, then closes the connection
+	server.closeDashboardConns()
+	for msg.Type == "heartbeat" {
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read reconnect: %v", err)
+		}
+	}
+	if msg.Type != "reconnect" || msg.Content == "" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+	if _, _, err := conn.ReadMessage(); err == nil {
+		t.Error("connection still open after closeDashboardConns")
+	}
+}
+
+func TestTrimBootstrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxBytes int
+		want     string
+	}{
+		{"leading blank lines", "\n  \n\t\nhello\n\nworld\n", 0, "hello\n\nworld\n"},
+		{"under cap", "line one\nline two\n", 100, "line one\nline two\n"},
+		{"cut at line boundary", "aaaa\nbbbb\ncccc\n", 9, "cccc\n"},
+		{"single long line", "xxéyyyy", 5, "yyyy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimBootstrap(tt.content, tt.maxBytes); got != tt.want {
+				t.Errorf("trimBootstrap() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputThrottle(t *testing.T) {
+	throttle := newOutputThrottle(4)
+
+	// The first output of an interval goes out immediately, up to the budget.
+	if got := throttle.Add([]byte("ab")); string(got) != "ab" {
+		t.Errorf("first Add() = %q, want %q", got, "ab")
+	}
+	// Later output in the same interval is held for the next tick.
+	if got := throttle.Add([]byte("cd")); got != nil {
+		t.Errorf("second Add() = %q, want nil", got)
+	}
+	throttle.Add([]byte("eé"))
+	if got := throttle.Backlog(); got != 5 {
+		t.Errorf("Backlog() = %d, want 5", got)
+	}
+	// Ticks batch the queue, never splitting a UTF-8 character.
+	if got := throttle.Tick(); string(got) != "cde" {
+		t.Errorf("first Tick() = %q, want %q", got, "cde")
+	}
+	if got := throttle.Tick(); string(got) != "é" {
+		t.Errorf("second Tick() = %q, want %q", got, "é")
+	}
+	if got := throttle.Tick(); got != nil {
+		t.Errorf("empty Tick() = %q, want nil", got)
+	}
+
+	throttle.Add([]byte("123456"))
+	throttle.Reset()
+	if got := throttle.Backlog(); got != 0 {
+		t.Errorf("Backlog() after Reset = %d, want 0", got)
+	}
+}
+
+func TestTerminalReadOnly(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", false},
+		{"?readonly=true", true},
+		{"?readonly=1", true},
+		{"?readonly=false", false},
+		{"?readonly=yes", false},
+	}
+	s := &Server{config: &config.Config{}}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/ws/terminal/abc"+tt.query, nil)
+		if got := s.terminalReadOnly(r); got != tt.want {
+			t.Errorf("terminalReadOnly(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// Kiosk mode makes every terminal read-only
+	s.config.AccessControl = &config.AccessControlConfig{ReadOnly: true}
+	r := httptest.NewRequest(http.MethodGet, "/ws/terminal/abc?readonly=false", nil)
+	if !s.terminalReadOnly(r) {
+		t.Error("terminalReadOnly() = false with access_control.read_only set")
+	}
+}
+
+func TestWithReadOnly(t *testing.T) {
+	s := &Server{config: &config.Config{AccessControl: &config.AccessControlConfig{ReadOnly: true}}}
+	h := s.withReadOnly(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodGet, "/api/sessions", http.StatusOK},
+		{http.MethodHead, "/api/sessions", http.StatusOK},
+		{http.MethodOptions, "/api/spawn", http.StatusOK},
+		{http.MethodPost, "/api/spawn", http.StatusForbidden},
+		{http.MethodDelete, "/api/config/remote-flavors/x", http.StatusForbidden},
+		{http.MethodPost, "/auth/logout", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+		if rr.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rr.Code, tt.want)
+		}
+	}
+
+	s.config.AccessControl.ReadOnly = false
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/spawn", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("POST /api/spawn with read_only off = %d, want 200", rr.Code)
+	}
+}
+
+func TestIsAllowedWebSocketOrigin(t *testing.T) {
+	upgrade := func(origin, host string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/ws/terminal/abc", nil)
+		r.Host = host
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		return r
+	}
+
+	t.Run("configured origins allowed", func(t *testing.T) {
+		s := &Server{config: &config.Config{
+			Network: &config.NetworkConfig{Port: 7337, WSAllowedOrigins: []string{"https://proxy.example.com/"}},
+		}}
+		if !s.isAllowedWebSocketOrigin(upgrade("http://localhost:7337", "localhost:7337")) {
+			t.Error("localhost should still be allowed")
+		}
+		if !s.isAllowedWebSocketOrigin(upgrade("https://proxy.example.com", "127.0.0.1:7337")) {
+			t.Error("ws_allowed_origins entry should be allowed")
+		}
+		if s.isAllowedWebSocketOrigin(upgrade("https://evil.example.com", "127.0.0.1:7337")) {
+			t.Error("unlisted origin should be rejected")
+		}
+	})
+
+	t.Run("same origin only with auth", func(t *testing.T) {
+		s := &Server{config: &config.Config{Network: &config.NetworkConfig{Port: 7337}}}
+		if s.isAllowedWebSocketOrigin(upgrade("http://rebound.example.com:7337", "rebound.example.com:7337")) {
+			t.Error("same-origin should not be trusted without auth")
+		}
+		s.config.AccessControl = &config.AccessControlConfig{Enabled: true}
+		if !s.isAllowedWebSocketOrigin(upgrade("https://schmux.internal", "schmux.internal")) {
+			t.Error("same-origin should be allowed with auth")
+		}
+		if s.isAllowedWebSocketOrigin(upgrade("https://other.internal", "schmux.internal")) {
+			t.Error("cross-origin should be rejected with auth")
+		}
+		if s.isAllowedWebSocketOrigin(upgrade("", "schmux.internal")) {
+			t.Error("missing origin should be rejected")
+		}
+	})
+}
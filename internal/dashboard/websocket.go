@@ -6,8 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
 	"github.com/sergeknystautas/schmux/internal/nudgenik"
@@ -18,6 +20,93 @@ import (
 
 const bootstrapCaptureLines = 200
 
+// trimBootstrap drops blank lines at the start of captured scrollback and, when the
+// result is larger than maxBytes, keeps only the most recent output, cut at a line
+// boundary so escape sequences and UTF-8 characters aren't split.
+func trimBootstrap(content string, maxBytes int) string {
+	for {
+		nl := strings.IndexByte(content, '\n')
+		if nl < 0 || strings.TrimSpace(content[:nl]) != "" {
+			break
+		}
+		content = content[nl+1:]
+	}
+	if maxBytes <= 0 || len(content) <= maxBytes {
+		return content
+	}
+	tail := content[len(content)-maxBytes:]
+	if nl := strings.IndexByte(tail, '\n'); nl >= 0 && nl < len(tail)-1 {
+		return tail[nl+1:]
+	}
+	// A single line longer than the cap: fall back to the nearest rune boundary.
+	for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+		tail = tail[1:]
+	}
+	return tail
+}
+
+// outputThrottle coalesces live terminal output so a WebSocket client gets at most
+// maxBytes per interval. The first output of an interval goes out right away, so
+// interactive echo isn't delayed; anything more waits for the next tick and is sent
+// as one batched append.
+type outputThrottle struct {
+	maxBytes int
+	pending  []byte
+	sent     int // bytes sent in the current interval
+}
+
+func newOutputThrottle(maxBytes int) *outputThrottle {
+	return &outputThrottle{maxBytes: maxBytes}
+}
+
+// Add queues data and returns the output that may be sent immediately, if any.
+func (t *outputThrottle) Add(data []byte) []byte {
+	t.pending = append(t.pending, data...)
+	if t.sent > 0 {
+		return nil
+	}
+	return t.take()
+}
+
+// Tick starts a new interval and returns the queued output that fits its budget.
+func (t *outputThrottle) Tick() []byte {
+	t.sent = 0
+	return t.take()
+}
+
+// Backlog returns the number of bytes waiting to be sent.
+func (t *outputThrottle) Backlog() int {
+	return len(t.pending)
+}
+
+// Reset drops the queued output, e.g. after the client was resynced with a fresh snapshot.
+func (t *outputThrottle) Reset() {
+	t.pending = nil
+}
+
+// take removes up to the interval's remaining budget from the queue, cut at a rune
+// boundary so the JSON-encoded frame never carries a split UTF-8 character.
+func (t *outputThrottle) take() []byte {
+	n := min(len(t.pending), t.maxBytes-t.sent)
+	if n <= 0 {
+		return nil
+	}
+	for n < len(t.pending) && n > 0 && !utf8.RuneStart(t.pending[n]) {
+		n--
+	}
+	if n == 0 {
+		// Budget smaller than one character: send the character anyway.
+		_, n = utf8.DecodeRune(t.pending)
+	}
+	out := t.pending[:n:n]
+	t.pending = t.pending[n:]
+	if len(t.pending) == 0 {
+		t.pending = nil
+	}
+	t.sent += n
+	return out
+}
+
 // Terminal query response prefixes to filter from input.
 // These are responses from xterm.js to queries from tmux - we don't send them back.
 var inputFilterPrefixes = []string{
@@ -69,6 +158,18 @@ type WSOutputMessage struct {
 	Content string `json:"content"`
 }
 
+// terminalReadOnly reports whether a terminal websocket was opened with ?readonly=true,
+// or the dashboard is in access_control.read_only mode. Read-only clients get the same
+// output stream, but their input and resize messages are dropped, so an observer can't
+// type into or reshape the session.
+func (s *Server) terminalReadOnly(r *http.Request) bool {
+	if s.config.GetReadOnly() {
+		return true
+	}
+	readOnly, _ := strconv.ParseBool(r.URL.Query().Get("readonly"))
+	return readOnly
+}
+
 // handleTerminalWebSocket streams tmux output to websocket clients.
 // It sends a bootstrap snapshot from capture-pane and then forwards live bytes
 // from the per-session tracker PTY.
@@ -109,19 +210,16 @@ func (s *Server) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request)
 		http.Error(w, fmt.Sprintf("failed to get tracker: %v", err), http.StatusInternalServerError)
 		return
 	}
+	readOnly := s.terminalReadOnly(r)
 
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin: func(r *http.Request) bool {
-			origin := r.Header.Get("Origin")
-			if s.config.GetAuthEnabled() {
-				return s.isAllowedOrigin(origin)
-			}
-			if origin == "" {
+			if r.Header.Get("Origin") == "" && !s.config.GetAuthEnabled() {
 				return true
 			}
-			return s.isAllowedOrigin(origin)
+			return s.isAllowedWebSocketOrigin(r)
 		},
 	}
 
@@ -131,9 +229,15 @@ func (s *Server) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request)
 	}
 
 	conn := &wsConn{conn: rawConn}
-	s.RegisterWebSocket(sessionID, conn)
+	// Only the connection that can type takes over the session; read-only observers
+	// are extra subscribers that never displace it
+	if !readOnly {
+		s.RegisterWebSocket(sessionID, conn)
+	}
 	defer func() {
-		s.UnregisterWebSocket(sessionID, conn)
+		if !readOnly {
+			s.UnregisterWebSocket(sessionID, conn)
+		}
 		conn.Close()
 	}()
 
@@ -159,24 +263,23 @@ func (s *Server) handleTerminalWebSocket(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Bootstrap with recent scrollback to avoid a blank terminal on connect.
-	capCtx, capCancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
-	bootstrap, err := tmux.CaptureLastLines(capCtx, sess.TmuxSession, bootstrapCaptureLines, true)
-	capCancel()
-	if err != nil {
-		fmt.Printf("[ws %s] bootstrap capture failed: %v\n", sessionID[:8], err)
-		bootstrap = ""
+	sendBootstrap := func() error {
+		capCtx, capCancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
+		bootstrap, err := tmux.CaptureLastLines(capCtx, sess.TmuxSession, bootstrapCaptureLines, true)
+		capCancel()
+		if err != nil {
+			fmt.Printf("[ws %s] bootstrap capture failed: %v\n", sessionID[:8], err)
+			bootstrap = ""
+		}
+		return sendOutput("full", trimBootstrap(string(filterMouseMode([]byte(bootstrap))), s.config.GetTerminalBootstrapMaxBytes()))
 	}
-	filteredBootstrap := string(filterMouseMode([]byte(bootstrap)))
-	if err := sendOutput("full", filteredBootstrap); err != nil {
+	if err := sendBootstrap(); err != nil {
 		return
 	}
 
 	// Configure status bar on connect (for existing sessions or future config changes)
 	statusCtx, statusCancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
-	_ = tmux.SetOption(statusCtx, sess.TmuxSession, "status-left", "#{pane_current_command} ")
-	_ = tmux.SetOption(statusCtx, sess.TmuxSession, "window-status-format", "")
-	_ = tmux.SetOption(statusCtx, sess.TmuxSession, "window-status-current-format", "")
-	_ = tmux.SetOption(statusCtx, sess.TmuxSession, "status-right", "")
+	s.session.ApplySessionTmuxOptions(statusCtx, *sess)
 	statusCancel()
 
 	// Flush any output that arrived while bootstrap/status setup was running.
@@ -218,6 +321,13 @@ drained:
 	ticker := time.NewTicker(500 * time.Millisecond)
 	defer ticker.Stop()
 
+	// Live output is throttled so a runaway agent can't flood the browser. When more
+	// is queued than a bootstrap would carry, it's cheaper to drop it and resend the
+	// current screen than to replay it.
+	throttle := newOutputThrottle(s.config.GetTerminalOutputMaxBytes())
+	flushTicker := time.NewTicker(s.config.GetTerminalOutputInterval())
+	defer flushTicker.Stop()
+
 	for {
 		select {
 		case chunk, ok := <-outputCh:
@@ -232,7 +342,21 @@ drained:
 				s.handleAgentSignal(sessionID, sig)
 			}
 			if len(cleanData) > 0 {
-				if err := sendOutput("append", string(cleanData)); err != nil {
+				if out := throttle.Add(cleanData); len(out) > 0 {
+					if err := sendOutput("append", string(out)); err != nil {
+						return
+					}
+				}
+				if throttle.Backlog() > s.config.GetTerminalBootstrapMaxBytes() {
+					throttle.Reset()
+					if err := sendBootstrap(); err != nil {
+						return
+					}
+				}
+			}
+		case <-flushTicker.C:
+			if out := throttle.Tick(); len(out) > 0 {
+				if err := sendOutput("append", string(out)); err != nil {
 					return
 				}
 			}
@@ -249,6 +373,9 @@ drained:
 				return
 			}
 
+			if readOnly && (msg.Type == "input" || msg.Type == "resize") {
+				continue
+			}
 			switch msg.Type {
 			case "input":
 				// Skip terminal query responses - these are xterm.js responding to tmux queries
@@ -353,6 +480,7 @@ func (s *Server) handleAgentSignal(sessionID string, sig signal.Signal) {
 // handleRemoteTerminalWebSocket streams terminal output from a remote session via control mode.
 func (s *Server) handleRemoteTerminalWebSocket(w http.ResponseWriter, r *http.Request, sess *state.Session) {
 	sessionID := sess.ID
+	readOnly := s.terminalReadOnly(r)
 
 	// Check if session has been created on remote host yet
 	// Sessions are queued during provisioning and RemotePaneID is set when created
@@ -380,14 +508,10 @@ func (s *Server) handleRemoteTerminalWebSocket(w http.ResponseWriter, r *http.Re
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin: func(r *http.Request) bool {
-			origin := r.Header.Get("Origin")
-			if s.config.GetAuthEnabled() {
-				return s.isAllowedOrigin(origin)
-			}
-			if origin == "" {
+			if r.Header.Get("Origin") == "" && !s.config.GetAuthEnabled() {
 				return true
 			}
-			return s.isAllowedOrigin(origin)
+			return s.isAllowedWebSocketOrigin(r)
 		},
 	}
 	rawConn, err := upgrader.Upgrade(w, r, nil)
@@ -398,10 +522,14 @@ func (s *Server) handleRemoteTerminalWebSocket(w http.ResponseWriter, r *http.Re
 	// Wrap the connection for concurrent write safety
 	wsConn := &wsConn{conn: rawConn}
 
-	// Register this connection
-	s.RegisterWebSocket(sessionID, wsConn)
+	// Register this connection; read-only observers don't displace the active one
+	if !readOnly {
+		s.RegisterWebSocket(sessionID, wsConn)
+	}
 	defer func() {
-		s.UnregisterWebSocket(sessionID, wsConn)
+		if !readOnly {
+			s.UnregisterWebSocket(sessionID, wsConn)
+		}
 		wsConn.Close()
 	}()
 
@@ -437,26 +565,29 @@ func (s *Server) handleRemoteTerminalWebSocket(w http.ResponseWriter, r *http.Re
 	}
 
 	// Send initial pane history (for scrollback)
-	initialLines := s.config.GetTerminalBootstrapLines()
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	history, err := conn.CapturePaneLines(ctx, sess.RemotePaneID, initialLines)
-	cancel()
-	if err != nil {
-		fmt.Printf("[ws remote %s] failed to capture initial pane content: %v\n", sessionID[:8], err)
-		// Send empty full message as fallback
-		if err := sendOutput("full", ""); err != nil {
-			return
+	sendHistory := func() error {
+		initialLines := s.config.GetTerminalBootstrapLines()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		history, err := conn.CapturePaneLines(ctx, sess.RemotePaneID, initialLines)
+		cancel()
+		if err != nil {
+			fmt.Printf("[ws remote %s] failed to capture initial pane content: %v\n", sessionID[:8], err)
+			// Send empty full message as fallback
+			return sendOutput("full", "")
 		}
-	} else {
 		// Send captured history as initial full content
-		if err := sendOutput("full", history); err != nil {
-			return
-		}
+		return sendOutput("full", trimBootstrap(history, s.config.GetTerminalBootstrapMaxBytes()))
+	}
+	if err := sendHistory(); err != nil {
+		return
 	}
 
 	paused := false
 	checkTicker := time.NewTicker(5 * time.Second) // Periodic health check
 	defer checkTicker.Stop()
+	throttle := newOutputThrottle(s.config.GetTerminalOutputMaxBytes())
+	flushTicker := time.NewTicker(s.config.GetTerminalOutputInterval())
+	defer flushTicker.Stop()
 
 	for {
 		select {
@@ -469,7 +600,22 @@ func (s *Server) handleRemoteTerminalWebSocket(w http.ResponseWriter, r *http.Re
 			if !paused && outputEvent.Data != "" {
 				// Update last output time for session activity tracking
 				s.state.UpdateSessionLastOutput(sessionID, time.Now())
-				if err := sendOutput("append", outputEvent.Data); err != nil {
+				if out := throttle.Add([]byte(outputEvent.Data)); len(out) > 0 {
+					if err := sendOutput("append", string(out)); err != nil {
+						return
+					}
+				}
+				if throttle.Backlog() > s.config.GetTerminalBootstrapMaxBytes() {
+					throttle.Reset()
+					if err := sendHistory(); err != nil {
+						return
+					}
+				}
+			}
+
+		case <-flushTicker.C:
+			if out := throttle.Tick(); len(out) > 0 {
+				if err := sendOutput("append", string(out)); err != nil {
 					return
 				}
 			}
@@ -488,9 +634,13 @@ func (s *Server) handleRemoteTerminalWebSocket(w http.ResponseWriter, r *http.Re
 			switch msg.Type {
 			case "pause":
 				paused = true
+				throttle.Reset()
 			case "resume":
 				paused = false
 			case "input":
+				if readOnly {
+					continue
+				}
 				// Send keys to remote pane
 				ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.GetXtermOperationTimeoutMs())*time.Millisecond)
 				if err := conn.SendKeys(ctx, sess.RemotePaneID, msg.Data); err != nil {
@@ -576,14 +726,10 @@ func (s *Server) handleProvisionWebSocket(w http.ResponseWriter, r *http.Request
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
 		CheckOrigin: func(r *http.Request) bool {
-			origin := r.Header.Get("Origin")
-			if s.config.GetAuthEnabled() {
-				return s.isAllowedOrigin(origin)
-			}
-			if origin == "" {
+			if r.Header.Get("Origin") == "" && !s.config.GetAuthEnabled() {
 				return true
 			}
-			return s.isAllowedOrigin(origin)
+			return s.isAllowedWebSocketOrigin(r)
 		},
 	}
 
@@ -610,8 +756,14 @@ func (s *Server) handleProvisionWebSocket(w http.ResponseWriter, r *http.Request
 				if err := json.Unmarshal(msg, &wsMsg); err == nil {
 					switch wsMsg.Type {
 					case "input":
+						if s.config.GetReadOnly() {
+							continue
+						}
 						inputChan <- []byte(wsMsg.Data)
 					case "resize":
+						if s.config.GetReadOnly() {
+							continue
+						}
 						var resizeData struct {
 							Cols int `json:"cols"`
 							Rows int `json:"rows"`
@@ -625,7 +777,7 @@ func (s *Server) handleProvisionWebSocket(w http.ResponseWriter, r *http.Request
 						}
 					}
 				}
-			} else if msgType == websocket.BinaryMessage {
+			} else if msgType == websocket.BinaryMessage && !s.config.GetReadOnly() {
 				// Direct binary input (from xterm.js)
 				inputChan <- msg
 			}
@@ -0,0 +1,68 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+func TestTerminalWebSocketObserverDoesNotDisplaceDriver(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	tmux.SetSocketName(fmt.Sprintf("schmux-dashboard-test-%d", os.Getpid()))
+	t.Cleanup(func() {
+		tmux.Command(context.Background(), "kill-server").Run()
+		tmux.SetSocketName("")
+	})
+
+	server, _, st := newTestServer(t)
+	if err := tmux.CreateSession(context.Background(), "observed", t.TempDir(), "sh"); err != nil {
+		t.Fatalf("create tmux session: %v", err)
+	}
+	st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+	st.AddSession(state.Session{ID: "ws-001-0a1b2c3d", WorkspaceID: "ws-001", Target: "command", TmuxSession: "observed"})
+
+	ts := httptest.NewServer(http.HandlerFunc(server.handleTerminalWebSocket))
+	defer ts.Close()
+	dial := func(query string) *websocket.Conn {
+		t.Helper()
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/terminal/ws-001-0a1b2c3d"+query, nil)
+		if err != nil {
+			t.Fatalf("dial %q: %v", query, err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+	driver := dial("")
+	observer := dial("?readonly=true")
+
+	if err := driver.WriteJSON(WSMessage{Type: "input", Data: "echo observed-$((6*7))\r"}); err != nil {
+		t.Fatalf("send input: %v", err)
+	}
+	for name, conn := range map[string]*websocket.Conn{"driver": driver, "observer": observer} {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		for {
+			var msg WSOutputMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("%s stopped receiving output: %v", name, err)
+			}
+			if msg.Type == "displaced" {
+				t.Fatalf("%s was displaced", name)
+			}
+			if strings.Contains(msg.Content, "observed-42") {
+				break
+			}
+		}
+	}
+}
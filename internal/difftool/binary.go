@@ -1,6 +1,7 @@
 package difftool
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"os/exec"
@@ -15,14 +16,21 @@ func isBinaryHeuristic(path string) bool {
 		return false
 	}
 	defer f.Close()
-	buf := make([]byte, 8192)
+	buf := make([]byte, binarySniffSize)
 	n, _ := f.Read(buf)
-	for i := 0; i < n; i++ {
-		if buf[i] == 0 {
-			return true
-		}
+	return IsBinaryContent(buf[:n])
+}
+
+// binarySniffSize is how much of a file is checked for null bytes.
+const binarySniffSize = 8192
+
+// IsBinaryContent reports whether data looks binary, using the same null-byte check
+// as IsBinaryFile's fast path on the first 8KB.
+func IsBinaryContent(data []byte) bool {
+	if len(data) > binarySniffSize {
+		data = data[:binarySniffSize]
 	}
-	return false
+	return bytes.IndexByte(data, 0) >= 0
 }
 
 // IsBinaryFile checks if a file is binary using git's detection.
@@ -0,0 +1,118 @@
+package difftool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// Launches tracks the external diff tool processes started for each workspace, so they
+// can be closed on request, when their temp dir expires, or at shutdown instead of
+// outliving the files they were given.
+type Launches struct {
+	mu          sync.Mutex
+	byWorkspace map[string]map[*exec.Cmd]string // workspace ID -> running command -> its temp dir
+}
+
+// NewLaunches creates an empty launch tracker.
+func NewLaunches() *Launches {
+	return &Launches{byWorkspace: make(map[string]map[*exec.Cmd]string)}
+}
+
+// Start starts cmd in its own process group, so the tool and anything its shell spawned
+// can be terminated together, and tracks it under workspaceID until it exits.
+// tempRoot is the temp dir holding the files the tool was given.
+func (l *Launches) Start(workspaceID, tempRoot string, cmd *exec.Cmd) error {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	if l.byWorkspace[workspaceID] == nil {
+		l.byWorkspace[workspaceID] = make(map[*exec.Cmd]string)
+	}
+	l.byWorkspace[workspaceID][cmd] = tempRoot
+	l.mu.Unlock()
+
+	// Reap the process so an exited tool doesn't linger as a zombie.
+	go func() {
+		_ = cmd.Wait()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.byWorkspace[workspaceID], cmd)
+		if len(l.byWorkspace[workspaceID]) == 0 {
+			delete(l.byWorkspace, workspaceID)
+		}
+	}()
+	return nil
+}
+
+// Running returns how many diff tools launched for the workspace are still running.
+func (l *Launches) Running(workspaceID string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.byWorkspace[workspaceID])
+}
+
+// Close terminates the workspace's running diff tools and removes all of its diff
+// temp dirs. It returns how many tools were terminated.
+func (l *Launches) Close(workspaceID string) int {
+	l.mu.Lock()
+	cmds := l.byWorkspace[workspaceID]
+	delete(l.byWorkspace, workspaceID)
+	l.mu.Unlock()
+
+	for cmd := range cmds {
+		terminate(cmd)
+	}
+	if err := CleanupWorkspaceTempDirs(workspaceID); err != nil {
+		fmt.Printf("[difftool] failed to remove temp dirs for %s: %v\n", workspaceID, err)
+	}
+	return len(cmds)
+}
+
+// Expire terminates the workspace's diff tools that were given tempRoot, then removes
+// tempRoot. It runs when a launch's cleanup delay (external_diff_cleanup_after_ms) passes.
+func (l *Launches) Expire(workspaceID, tempRoot string) {
+	var expired []*exec.Cmd
+	l.mu.Lock()
+	for cmd, dir := range l.byWorkspace[workspaceID] {
+		if dir == tempRoot {
+			expired = append(expired, cmd)
+		}
+	}
+	l.mu.Unlock()
+
+	for _, cmd := range expired {
+		terminate(cmd)
+	}
+	if err := os.RemoveAll(tempRoot); err != nil {
+		fmt.Printf("[difftool] failed to remove temp dir: %v\n", err)
+	}
+}
+
+// CloseAll closes the diff tools of every workspace. Called on daemon shutdown.
+func (l *Launches) CloseAll() {
+	l.mu.Lock()
+	workspaceIDs := make([]string, 0, len(l.byWorkspace))
+	for workspaceID := range l.byWorkspace {
+		workspaceIDs = append(workspaceIDs, workspaceID)
+	}
+	l.mu.Unlock()
+
+	for _, workspaceID := range workspaceIDs {
+		l.Close(workspaceID)
+	}
+}
+
+// terminate sends SIGTERM to the command's process group. A group that already
+// exited is not an error.
+func terminate(cmd *exec.Cmd) {
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+		fmt.Printf("[difftool] failed to terminate diff tool %d: %v\n", cmd.Process.Pid, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sergeknystautas/schmux/internal/config"
@@ -50,8 +51,6 @@ Here is the agent’s last response:
 {{AGENT_LAST_RESPONSE}}
 >>>
 `
-
-	nudgenikTimeout = 15 * time.Second
 )
 
 var (
@@ -60,8 +59,33 @@ var (
 	ErrTargetNotFound  = errors.New("nudgenik target not found")
 	ErrTargetNoSecrets = errors.New("nudgenik target missing required secrets")
 	ErrInvalidResponse = errors.New("invalid nudgenik response")
+	ErrRateLimited     = errors.New("nudgenik rate limited (nudgenik.max_concurrent calls in flight), retry later")
 )
 
+// limiter caps NudgeNik calls in flight across every caller (dashboard and auto-nudge).
+// The cap is read from config on each call so changes apply without a restart.
+var limiter struct {
+	mu       sync.Mutex
+	inFlight int
+}
+
+// acquire reserves a call slot, returning false if max calls are already running.
+func acquire(max int) bool {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if limiter.inFlight >= max {
+		return false
+	}
+	limiter.inFlight++
+	return true
+}
+
+func release() {
+	limiter.mu.Lock()
+	limiter.inFlight--
+	limiter.mu.Unlock()
+}
+
 // IsEnabled returns true if nudgenik is enabled (has a configured target).
 func IsEnabled(cfg *config.Config) bool {
 	if cfg == nil {
@@ -82,7 +106,7 @@ type Result struct {
 // AskForSession captures the latest session output and asks NudgeNik for feedback.
 func AskForSession(ctx context.Context, cfg *config.Config, sess state.Session) (Result, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, cfg.XtermOperationTimeout())
-	content, err := tmux.CaptureLastLines(timeoutCtx, sess.TmuxSession, 100, false)
+	content, err := tmux.CaptureLastLines(timeoutCtx, sess.TmuxTarget(), 100, false)
 	cancel()
 	if err != nil {
 		return Result{}, fmt.Errorf("capture tmux session %s: %w", sess.ID, err)
@@ -116,12 +140,18 @@ func AskForExtracted(ctx context.Context, cfg *config.Config, extracted string)
 		return Result{}, ErrDisabled
 	}
 
+	if !acquire(cfg.GetNudgenikMaxConcurrent()) {
+		return Result{}, ErrRateLimited
+	}
+	defer release()
+
 	input := Prompt + extracted
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, nudgenikTimeout)
+	timeout := time.Duration(cfg.GetNudgenikTimeoutMs()) * time.Millisecond
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	response, err := oneshot.ExecuteTarget(timeoutCtx, cfg, targetName, input, oneshot.SchemaNudgeNik, nudgenikTimeout, "")
+	response, err := oneshot.ExecuteTarget(timeoutCtx, cfg, targetName, input, oneshot.SchemaNudgeNik, timeout, "")
 	if err != nil {
 		if errors.Is(err, oneshot.ErrTargetNotFound) {
 			return Result{}, ErrTargetNotFound
@@ -72,3 +72,18 @@ func TestAskForSessionDisabled(t *testing.T) {
 		t.Fatalf("expected ErrDisabled, got %v", err)
 	}
 }
+
+func TestAskForExtractedRateLimited(t *testing.T) {
+	cfg := &config.Config{
+		Nudgenik: &config.NudgenikConfig{Target: "claude", MaxConcurrent: 1},
+	}
+	if !acquire(cfg.GetNudgenikMaxConcurrent()) {
+		t.Fatal("expected first slot to be free")
+	}
+	defer release()
+
+	_, err := AskForExtracted(context.Background(), cfg, "Implementation is complete.")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+}
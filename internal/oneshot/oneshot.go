@@ -329,6 +329,13 @@ func resolveTarget(cfg *config.Config, targetName string) (resolvedTarget, error
 		return resolvedTarget{}, fmt.Errorf("%w: %s", ErrTargetNotFound, targetName)
 	}
 
+	// A bare detected tool runs its configured default model, if it has one
+	if _, detected := cfg.GetDetectedRunTarget(targetName); detected {
+		if modelID := cfg.GetDefaultModel(targetName); modelID != "" {
+			targetName = modelID
+		}
+	}
+
 	// Check if it's a model (handles aliases like "opus", "sonnet", "haiku")
 	model, ok := detect.FindModel(targetName)
 	if ok {
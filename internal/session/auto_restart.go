@@ -0,0 +1,167 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+// autoRestartResetAfter is how long a session must stay up after an auto-restart for
+// its restart count to start over, so an agent that crashes once a day isn't
+// eventually left dead.
+const autoRestartResetAfter = 10 * time.Minute
+
+// autoRestartTimeout bounds the tmux calls of one restart.
+const autoRestartTimeout = 30 * time.Second
+
+// exitStatusFile is where the command of a session whose exit is watched writes its
+// exit code.
+func exitStatusFile(sessionID string) string {
+	return filepath.Join(os.TempDir(), "schmux-exit-"+sessionID)
+}
+
+// recordExitStatus makes command write its exit code to the session's exit status file.
+// tmux's own pane_dead_status can't be relied on: tmux 3.3 sometimes loses it when the
+// pane's output closes before the process is reaped. The newline ends any trailing
+// comment in command.
+func recordExitStatus(command, sessionID string) string {
+	return command + "\necho $? > " + shellQuote(exitStatusFile(sessionID))
+}
+
+// readExitStatus returns and removes the exit code the session's command recorded.
+// ok is false if the shell running the command was killed before it could record one.
+func readExitStatus(sessionID string) (code int, ok bool) {
+	path := exitStatusFile(sessionID)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	os.Remove(path)
+	code, err = strconv.Atoi(strings.TrimSpace(string(data)))
+	return code, err == nil
+}
+
+// handleSessionExit is called by a session's tracker when the agent of an
+// auto-restart target, or of a target with an on_exit_command, exits. The
+// on_exit_command runs in the background. For auto-restart targets, a non-zero exit,
+// or one killed before its code was recorded, schedules a restart after the backoff
+// unless the kill-switch is on or the session used up its retries; otherwise the dead
+// pane is closed, as tmux would have without remain-on-exit.
+func (m *Manager) handleSessionExit(sessionID string) {
+	sess, found := m.state.GetSession(sessionID)
+	if !found {
+		return
+	}
+	exitCode, ok := readExitStatus(sessionID)
+	if !ok {
+		exitCode = -1
+	}
+	go m.runOnExitCommand(sess, exitCode)
+	if !m.config.IsAutoRestartTarget(sess.Target) {
+		m.closeDeadSession(sess.TmuxSession)
+		return
+	}
+	if exitCode == 0 {
+		fmt.Printf("[session] %s exited cleanly; not restarting\n", sessionID)
+		m.closeDeadSession(sess.TmuxSession)
+		return
+	}
+
+	if !sess.LastRestartAt.IsZero() && time.Since(sess.LastRestartAt) >= autoRestartResetAfter {
+		sess.RestartCount = 0
+	}
+	sess.LastExitCode = exitCode
+	if err := m.state.UpdateSession(sess); err == nil {
+		m.state.Save()
+	}
+
+	maxRetries := m.config.GetAutoRestartMaxRetries()
+	switch {
+	case m.config.GetAutoRestartDisabled():
+		fmt.Printf("[session] %s exited with code %d; auto-restart is disabled\n", sessionID, exitCode)
+		m.closeDeadSession(sess.TmuxSession)
+		return
+	case sess.RestartCount >= maxRetries:
+		fmt.Printf("[session] %s exited with code %d; giving up after %d restarts\n", sessionID, exitCode, sess.RestartCount)
+		m.closeDeadSession(sess.TmuxSession)
+		return
+	}
+
+	delay := time.Duration(m.config.GetAutoRestartBackoffMs()) * time.Millisecond << sess.RestartCount
+	fmt.Printf("[session] %s exited with code %d; restarting in %s (attempt %d of %d)\n", sessionID, exitCode, delay, sess.RestartCount+1, maxRetries)
+	time.AfterFunc(delay, func() {
+		if err := m.restartExited(sessionID); err != nil {
+			fmt.Printf("[session] %s auto-restart failed: %v\n", sessionID, err)
+		}
+	})
+}
+
+// restartExited reruns the agent in the dead pane of an exited session. Targets that
+// support resume mode pick up their last conversation; others rerun their original
+// command. Does nothing if the session was disposed or already restarted meanwhile.
+func (m *Manager) restartExited(sessionID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), autoRestartTimeout)
+	defer cancel()
+
+	sess, found := m.state.GetSession(sessionID)
+	if !found {
+		return nil
+	}
+	if dead, _, err := tmux.GetPaneExitStatus(ctx, sess.TmuxTarget()); err != nil || !dead {
+		return nil
+	}
+	if m.config.GetAutoRestartDisabled() {
+		m.closeDeadSession(sess.TmuxSession)
+		return nil
+	}
+	w, found := m.workspace.GetByID(sess.WorkspaceID)
+	if !found {
+		return fmt.Errorf("workspace not found: %s", sess.WorkspaceID)
+	}
+
+	command := ""
+	if resolved, err := m.ResolveTarget(ctx, sess.Target); err == nil && resolved.Promptable {
+		if resumeCommand, _, err := m.sessionCommand(w, sess.ID, resolved, "", true); err == nil {
+			command = resumeCommand
+		}
+	}
+	if command != "" {
+		command = recordExitStatus(command, sess.ID)
+	}
+	if err := tmux.RespawnPane(ctx, sess.TmuxTarget(), command); err != nil {
+		return err
+	}
+	pid, err := tmux.GetPanePID(ctx, sess.TmuxTarget())
+	if err != nil {
+		return err
+	}
+
+	// Re-read so changes made during the backoff (nudges, renames) aren't lost
+	if current, found := m.state.GetSession(sessionID); found {
+		sess = current
+	}
+	sess.Pid = pid
+	sess.RestartCount++
+	sess.LastRestartAt = time.Now()
+	if err := m.state.UpdateSession(sess); err != nil {
+		return fmt.Errorf("failed to update session in state: %w", err)
+	}
+	if err := m.state.Save(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	fmt.Printf("[session] auto-restarted %s (restart %d)\n", sessionID, sess.RestartCount)
+	return nil
+}
+
+// closeDeadSession kills a tmux session whose held pane is dead.
+func (m *Manager) closeDeadSession(tmuxSession string) {
+	if err := tmux.KillSession(context.Background(), tmuxSession); err != nil {
+		fmt.Printf("[session] warning: failed to close exited session %s: %v\n", tmuxSession, err)
+	}
+}
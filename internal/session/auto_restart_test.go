@@ -0,0 +1,119 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+func TestAutoRestart(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	tmux.SetSocketName(fmt.Sprintf("schmux-test-%d", os.Getpid()))
+	t.Cleanup(func() {
+		tmux.Command(context.Background(), "kill-server").Run()
+		tmux.SetSocketName("")
+	})
+
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		RunTargets: []config.RunTarget{
+			{Name: "crasher", Type: config.RunTargetTypeCommand, Command: "sh -c 'sleep 1; exit 3'"},
+		},
+		Sessions: &config.SessionsConfig{
+			AutoRestartTargets:    []string{"crasher"},
+			AutoRestartMaxRetries: 1,
+			AutoRestartBackoffMs:  1,
+		},
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	if err := st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "git@example.com:me/repo.git", Branch: "main", Path: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	sess, err := m.Spawn(context.Background(), "", "", "crasher", "", "", "ws-001", false)
+	if err != nil {
+		t.Fatalf("Spawn() error: %v", err)
+	}
+	t.Cleanup(func() { m.stopTracker(sess.ID) })
+
+	// It crashes, is restarted once, crashes again, and is left closed.
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) && tmux.SessionExists(context.Background(), sess.TmuxSession) {
+		time.Sleep(200 * time.Millisecond)
+	}
+	if tmux.SessionExists(context.Background(), sess.TmuxSession) {
+		t.Fatal("session still exists after using up its restarts")
+	}
+	stored, _ := st.GetSession(sess.ID)
+	if stored.RestartCount != 1 || stored.LastExitCode != 3 || stored.LastRestartAt.IsZero() {
+		t.Errorf("restart bookkeeping = count %d, exit code %d, last restart %v; want 1, 3, set", stored.RestartCount, stored.LastExitCode, stored.LastRestartAt)
+	}
+}
+
+func TestOnExitCommand(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	tmux.SetSocketName(fmt.Sprintf("schmux-test-%d", os.Getpid()))
+	t.Cleanup(func() {
+		tmux.Command(context.Background(), "kill-server").Run()
+		tmux.SetSocketName("")
+	})
+
+	outPath := filepath.Join(t.TempDir(), "out")
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		RunTargets: []config.RunTarget{{
+			Name:          "quitter",
+			Type:          config.RunTargetTypeCommand,
+			Command:       "sh -c 'sleep 1; exit 3'",
+			OnExitCommand: `echo "$SCHMUX_SESSION_NICKNAME $SCHMUX_TARGET $SCHMUX_EXIT_CODE $SCHMUX_BRANCH" > ` + outPath,
+		}},
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	if err := st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "git@example.com:me/repo.git", Branch: "main", Path: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	sess, err := m.Spawn(context.Background(), "", "", "quitter", "", "build", "ws-001", false)
+	if err != nil {
+		t.Fatalf("Spawn() error: %v", err)
+	}
+	t.Cleanup(func() { m.stopTracker(sess.ID) })
+
+	var got []byte
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		if got, err = os.ReadFile(outPath); err == nil && len(got) > 0 {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if want := "build quitter 3 main\n"; string(got) != want {
+		t.Errorf("on_exit_command output = %q, want %q", got, want)
+	}
+	// Without auto-restart the dead pane is closed
+	for time.Now().Before(deadline) && tmux.SessionExists(context.Background(), sess.TmuxSession) {
+		time.Sleep(200 * time.Millisecond)
+	}
+	if tmux.SessionExists(context.Background(), sess.TmuxSession) {
+		t.Error("session still exists after its agent exited")
+	}
+}
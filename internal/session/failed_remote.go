@@ -0,0 +1,41 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// DisposeFailedRemoteSessions disposes remote sessions whose creation failed more than
+// sessions.failed_remote_grace_ms ago, so connectivity blips don't leave dead entries
+// on the remote workspace. Sessions from before failures were timestamped count from
+// their creation. Returns how many sessions were disposed.
+func (m *Manager) DisposeFailedRemoteSessions(ctx context.Context, now time.Time) int {
+	grace := m.config.FailedRemoteGrace()
+	disposed := 0
+	for _, sess := range m.state.GetSessions() {
+		if sess.RemoteHostID == "" || sess.Status != state.SessionStatusFailed {
+			continue
+		}
+		failedAt := sess.FailedAt
+		if failedAt.IsZero() {
+			failedAt = sess.CreatedAt
+		}
+		if now.Sub(failedAt) < grace {
+			continue
+		}
+		reason := sess.FailReason
+		if reason == "" {
+			reason = "unknown"
+		}
+		fmt.Printf("[session] disposing remote session %s, failed %s ago: %s\n", sess.ID, now.Sub(failedAt).Round(time.Second), reason)
+		if err := m.Dispose(ctx, sess.ID); err != nil {
+			fmt.Printf("[session] failed to dispose remote session %s: %v\n", sess.ID, err)
+			continue
+		}
+		disposed++
+	}
+	return disposed
+}
@@ -0,0 +1,40 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+func TestDisposeFailedRemoteSessions(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces", Sessions: &config.SessionsConfig{FailedRemoteGraceMs: 60000}}
+	statePath := t.TempDir() + "/state.json"
+	st := state.New(statePath)
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	now := time.Now()
+	st.AddSession(state.Session{ID: "old-failure", RemoteHostID: "host-1", Status: state.SessionStatusFailed, FailReason: "connection lost", FailedAt: now.Add(-2 * time.Minute)})
+	st.AddSession(state.Session{ID: "new-failure", RemoteHostID: "host-1", Status: state.SessionStatusFailed, FailedAt: now.Add(-30 * time.Second)})
+	st.AddSession(state.Session{ID: "untimed-failure", RemoteHostID: "host-1", Status: state.SessionStatusFailed, CreatedAt: now.Add(-time.Hour)})
+	st.AddSession(state.Session{ID: "running", RemoteHostID: "host-1", Status: state.SessionStatusRunning, CreatedAt: now.Add(-time.Hour)})
+	st.AddSession(state.Session{ID: "provisioning", RemoteHostID: "host-1", Status: state.SessionStatusProvisioning, CreatedAt: now.Add(-time.Hour)})
+
+	if got := m.DisposeFailedRemoteSessions(context.Background(), now); got != 2 {
+		t.Errorf("DisposeFailedRemoteSessions() = %d, want 2", got)
+	}
+	for id, want := range map[string]bool{
+		"old-failure":     false,
+		"new-failure":     true,
+		"untimed-failure": false,
+		"running":         true,
+		"provisioning":    true,
+	} {
+		if _, found := st.GetSession(id); found != want {
+			t.Errorf("session %s present = %v, want %v", id, found, want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+// historyFileName is the append-only log of disposed sessions, kept next to state.json.
+const historyFileName = "history.jsonl"
+
+// Exit statuses recorded in HistoryEntry.ExitStatus.
+const (
+	HistoryExitKilled       = "killed"        // still running when disposed
+	HistoryExitExited       = "exited"        // the agent had already exited
+	HistoryExitNeverStarted = "never_started" // a blocked session that was never started
+)
+
+// HistoryEntry records a disposed session in history.jsonl.
+type HistoryEntry struct {
+	SessionID    string    `json:"session_id"`
+	WorkspaceID  string    `json:"workspace_id"`
+	Repo         string    `json:"repo,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	Target       string    `json:"target"`
+	Nickname     string    `json:"nickname,omitempty"`
+	Prompt       string    `json:"prompt,omitempty"` // only with sessions.history_record_prompts
+	RemoteHostID string    `json:"remote_host_id,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	EndedAt      time.Time `json:"ended_at"`
+	ExitStatus   string    `json:"exit_status"`
+	ExitCode     *int      `json:"exit_code,omitempty"` // set when the pane's exit code was still visible
+}
+
+// HistoryFilter selects history entries. Zero-valued fields match everything.
+type HistoryFilter struct {
+	WorkspaceID string
+	Repo        string
+	Branch      string
+	Target      string
+	Since       time.Time // entries that ended at or after Since
+	Until       time.Time // entries that ended before Until
+	Limit       int       // maximum entries returned (newest first); 0 means no limit
+}
+
+func (f HistoryFilter) matches(e HistoryEntry) bool {
+	if f.WorkspaceID != "" && e.WorkspaceID != f.WorkspaceID {
+		return false
+	}
+	if f.Repo != "" && e.Repo != f.Repo {
+		return false
+	}
+	if f.Branch != "" && e.Branch != f.Branch {
+		return false
+	}
+	if f.Target != "" && e.Target != f.Target {
+		return false
+	}
+	if !f.Since.IsZero() && e.EndedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.EndedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// localExitStatus determines how a local session ended. It must run before the
+// session's tmux session is killed.
+func localExitStatus(ctx context.Context, sess state.Session) (string, *int) {
+	if sess.IsBlocked() {
+		return HistoryExitNeverStarted, nil
+	}
+	if !tmux.SessionExists(ctx, sess.TmuxSession) {
+		return HistoryExitExited, nil
+	}
+	if dead, code, err := tmux.GetPaneExitStatus(ctx, sess.TmuxTarget()); err == nil && dead {
+		return HistoryExitExited, &code
+	}
+	return HistoryExitKilled, nil
+}
+
+// recordHistory appends a disposed session to the history log when
+// sessions.history_enabled is set. Failures are logged, never returned: the
+// history must not block a dispose.
+func (m *Manager) recordHistory(sess state.Session, exitStatus string, exitCode *int) {
+	if m.historyPath == "" || !m.config.GetHistoryEnabled() {
+		return
+	}
+	entry := HistoryEntry{
+		SessionID:    sess.ID,
+		WorkspaceID:  sess.WorkspaceID,
+		Target:       sess.Target,
+		Nickname:     sess.Nickname,
+		RemoteHostID: sess.RemoteHostID,
+		StartedAt:    sess.CreatedAt,
+		EndedAt:      time.Now(),
+		ExitStatus:   exitStatus,
+		ExitCode:     exitCode,
+	}
+	if m.config.GetHistoryRecordPrompts() {
+		entry.Prompt = sess.Prompt
+		if entry.Prompt == "" {
+			entry.Prompt = sess.PendingPrompt
+		}
+	}
+	if ws, found := m.state.GetWorkspace(sess.WorkspaceID); found {
+		entry.Repo = ws.Repo
+		entry.Branch = ws.Branch
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		fmt.Printf("[session] warning: failed to encode history entry for %s: %v\n", sess.ID, err)
+		return
+	}
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	f, err := os.OpenFile(m.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Printf("[session] warning: failed to open history: %v\n", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		fmt.Printf("[session] warning: failed to write history: %v\n", err)
+	}
+}
+
+// ReadHistory returns the disposed sessions matching filter, newest first.
+// A missing history file yields no entries; malformed lines are skipped.
+func (m *Manager) ReadHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	entries := []HistoryEntry{}
+	if m.historyPath == "" {
+		return entries, nil
+	}
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	f, err := os.Open(m.historyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entries, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	// Lines can hold whole prompts, so read them unbounded rather than with a Scanner.
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var entry HistoryEntry
+			if json.Unmarshal(line, &entry) == nil && filter.matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history: %w", err)
+		}
+	}
+
+	// The file is in dispose order; return newest first.
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
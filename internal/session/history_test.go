@@ -0,0 +1,86 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+func TestRecordAndReadHistory(t *testing.T) {
+	cfg := &config.Config{
+		WorkspacePath: "/tmp/workspaces",
+		Sessions:      &config.SessionsConfig{HistoryEnabled: true},
+	}
+	statePath := t.TempDir() + "/state.json"
+	st := state.New(statePath)
+	st.AddWorkspace(state.Workspace{ID: "ws-1", Repo: "https://example.com/repo.git", Branch: "main"})
+	wm := workspace.New(cfg, st, statePath)
+	m := New(cfg, st, statePath, wm)
+
+	started := time.Now().Add(-time.Hour)
+	m.recordHistory(state.Session{ID: "ws-1-a", WorkspaceID: "ws-1", Target: "claude", CreatedAt: started, Prompt: "secret"}, HistoryExitKilled, nil)
+	code := 2
+	m.recordHistory(state.Session{ID: "ws-2-b", WorkspaceID: "ws-2", Target: "codex", CreatedAt: started}, HistoryExitExited, &code)
+
+	entries, err := m.ReadHistory(HistoryFilter{})
+	if err != nil {
+		t.Fatalf("ReadHistory: %v", err)
+	}
+	if len(entries) != 2 || entries[0].SessionID != "ws-2-b" || entries[1].SessionID != "ws-1-a" {
+		t.Fatalf("expected newest first, got %+v", entries)
+	}
+	if entries[1].Repo != "https://example.com/repo.git" || entries[1].Branch != "main" {
+		t.Errorf("expected repo and branch from workspace, got %q %q", entries[1].Repo, entries[1].Branch)
+	}
+	if entries[1].Prompt != "" {
+		t.Errorf("prompt recorded without history_record_prompts: %q", entries[1].Prompt)
+	}
+	if entries[0].ExitCode == nil || *entries[0].ExitCode != 2 || entries[0].ExitStatus != HistoryExitExited {
+		t.Errorf("unexpected exit for ws-2-b: %s %v", entries[0].ExitStatus, entries[0].ExitCode)
+	}
+	if !entries[1].StartedAt.Equal(started) {
+		t.Errorf("StartedAt = %v, want %v", entries[1].StartedAt, started)
+	}
+
+	filtered, _ := m.ReadHistory(HistoryFilter{Target: "claude"})
+	if len(filtered) != 1 || filtered[0].SessionID != "ws-1-a" {
+		t.Errorf("target filter: got %+v", filtered)
+	}
+	filtered, _ = m.ReadHistory(HistoryFilter{Since: time.Now().Add(time.Minute)})
+	if len(filtered) != 0 {
+		t.Errorf("since filter: expected none, got %d", len(filtered))
+	}
+	filtered, _ = m.ReadHistory(HistoryFilter{Limit: 1})
+	if len(filtered) != 1 || filtered[0].SessionID != "ws-2-b" {
+		t.Errorf("limit: got %+v", filtered)
+	}
+
+	cfg.Sessions.HistoryRecordPrompts = true
+	m.recordHistory(state.Session{ID: "ws-1-c", WorkspaceID: "ws-1", Target: "claude", Prompt: "fix the bug"}, HistoryExitKilled, nil)
+	entries, _ = m.ReadHistory(HistoryFilter{Limit: 1})
+	if len(entries) != 1 || entries[0].Prompt != "fix the bug" {
+		t.Errorf("expected recorded prompt, got %+v", entries)
+	}
+
+	cfg.Sessions.HistoryEnabled = false
+	m.recordHistory(state.Session{ID: "ws-1-d", WorkspaceID: "ws-1"}, HistoryExitKilled, nil)
+	entries, _ = m.ReadHistory(HistoryFilter{})
+	if len(entries) != 3 {
+		t.Errorf("expected disabled history to skip recording, got %d entries", len(entries))
+	}
+}
+
+func TestReadHistoryMissingFile(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
+	statePath := t.TempDir() + "/state.json"
+	st := state.New(statePath)
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	entries, err := m.ReadHistory(HistoryFilter{})
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries and no error, got %v %v", entries, err)
+	}
+}
@@ -0,0 +1,75 @@
+package session
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+// commandVarPattern matches the ${NAME} references expanded in run target commands.
+// Bare $NAME is left to the shell so $1, $?, and friends keep working.
+var commandVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// interpolateCommand expands ${NAME} references in a run target command before it
+// reaches the shell. The shell can't do this itself for the session env: in
+// `FOO=x cmd ${FOO}` the reference is expanded before the assignment applies.
+//
+// Each value is escaped for where the reference sits: single-quoted outside quotes,
+// with embedded single quotes closed and reopened inside '...', and backslash-escaped
+// inside "...". References that lookup doesn't know are left for the shell.
+func interpolateCommand(command string, lookup func(name string) (string, bool)) string {
+	if !strings.Contains(command, "${") {
+		return command
+	}
+
+	var b strings.Builder
+	var quote byte // 0, '\'', or '"'
+	for i := 0; i < len(command); {
+		c := command[i]
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(command):
+			b.WriteString(command[i : i+2])
+			i += 2
+			continue
+		case c == '\'' && quote != '"':
+			quote ^= '\''
+		case c == '"' && quote != '\'':
+			quote ^= '"'
+		case c == '$':
+			if loc := commandVarPattern.FindStringSubmatchIndex(command[i:]); loc != nil && loc[0] == 0 {
+				name := command[i+loc[2] : i+loc[3]]
+				if value, ok := lookup(name); ok {
+					b.WriteString(quoteForContext(value, quote))
+					i += loc[1]
+					continue
+				}
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+// quoteForContext escapes value so it reads as one literal string at a point in a
+// shell command where quote, a single or double quote character or 0, is open.
+func quoteForContext(value string, quote byte) string {
+	switch quote {
+	case '\'':
+		return strings.ReplaceAll(value, "'", `'\''`)
+	case '"':
+		return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace(value)
+	default:
+		return shellQuote(value)
+	}
+}
+
+// sessionEnvLookup looks names up in a session's env, then in the daemon's own.
+func sessionEnvLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		if value, ok := env[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+}
@@ -0,0 +1,55 @@
+package session
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestInterpolateCommand(t *testing.T) {
+	env := map[string]string{
+		"SCHMUX_WORKSPACE_ID": "myrepo-001",
+		"TRICKY":              `it's "$HOME" \ $(rm -rf /) ` + "`x`",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"no references", "claude --verbose", "claude --verbose"},
+		{"bare", "tool --id ${SCHMUX_WORKSPACE_ID}", "tool --id 'myrepo-001'"},
+		{"unknown left for the shell", "tool ${UNSET_VAR} $1 $HOME", "tool ${UNSET_VAR} $1 $HOME"},
+		{"escaped dollar", `tool \${SCHMUX_WORKSPACE_ID}`, `tool \${SCHMUX_WORKSPACE_ID}`},
+		{"single quoted", "sh -c 'echo ${SCHMUX_WORKSPACE_ID}'", "sh -c 'echo myrepo-001'"},
+		{"double quoted", `tool "--id=${SCHMUX_WORKSPACE_ID}"`, `tool "--id=myrepo-001"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := interpolateCommand(tt.command, lookup); got != tt.want {
+				t.Errorf("interpolateCommand(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+
+	// Whatever the quoting context, the shell sees the value verbatim
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	for _, command := range []string{
+		"printf %s ${TRICKY}",
+		"printf %s '${TRICKY}'",
+		`printf %s "${TRICKY}"`,
+	} {
+		out, err := exec.Command("sh", "-c", interpolateCommand(command, lookup)).Output()
+		if err != nil {
+			t.Fatalf("%s: %v", command, err)
+		}
+		if got := string(out); got != env["TRICKY"] {
+			t.Errorf("%s printed %q, want %q", command, got, env["TRICKY"])
+		}
+	}
+}
@@ -2,10 +2,12 @@ package session
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -32,6 +34,23 @@ const (
 	processKillGracePeriod = 100 * time.Millisecond
 )
 
+// ErrTargetUnavailable is returned by ResolveTarget when a target exists but can't run yet,
+// such as a model whose required secrets are missing.
+var ErrTargetUnavailable = errors.New("target unavailable")
+
+// ErrTargetNotFound is returned by ResolveTarget when no run target or model has the name.
+var ErrTargetNotFound = errors.New("target not found")
+
+// ErrNicknameInUse is returned by spawns and renames when the nickname is taken and
+// sessions.nickname_collision is "reject".
+var ErrNicknameInUse = errors.New("already in use")
+
+// Adopt errors, so callers can tell a bad request from a conflict.
+var (
+	ErrTmuxSessionNotFound = errors.New("tmux session not found")
+	ErrAlreadyManaged      = errors.New("tmux session is already managed by schmux")
+)
+
 // Manager manages sessions.
 type Manager struct {
 	config        *config.Config
@@ -39,17 +58,28 @@ type Manager struct {
 	workspace     workspace.WorkspaceManager
 	remoteManager *remote.Manager // Optional, for remote sessions
 	trackers      map[string]*SessionTracker
+	logDir        string           // directory holding per-session <id>.log files (next to state.json)
+	logDropped    map[string]int64 // bytes rotated out of each session's log, cached from its sidecar file
+	logMu         sync.Mutex       // guards logDropped and rewrites of the log files
+	historyPath   string           // history.jsonl of disposed sessions (next to state.json)
+	historyMu     sync.Mutex
+	messageMu     sync.Mutex      // serializes changes to sessions' queued messages
+	delivering    map[string]bool // started sessions whose queued messages wait for the agent to be ready
 	mu            sync.RWMutex
 }
 
 // ResolvedTarget is a resolved run target with command and env info.
 type ResolvedTarget struct {
-	Name       string
-	Kind       string
-	Command    string
-	Promptable bool
-	Env        map[string]string
-	Model      *detect.Model
+	Name        string
+	Kind        string
+	Command     string
+	Promptable  bool
+	Env         map[string]string
+	Model       *detect.Model
+	Shell       string
+	TmuxOptions map[string]string
+	Panes       []state.SessionPane
+	PaneLayout  string
 }
 
 const (
@@ -60,11 +90,21 @@ const (
 
 // New creates a new session manager.
 func New(cfg *config.Config, st state.StateStore, statePath string, wm workspace.WorkspaceManager) *Manager {
+	logDir := ""
+	historyPath := ""
+	if statePath != "" {
+		logDir = filepath.Join(filepath.Dir(statePath), "logs")
+		historyPath = filepath.Join(filepath.Dir(statePath), historyFileName)
+	}
 	return &Manager{
 		config:        cfg,
 		state:         st,
 		workspace:     wm,
 		trackers:      make(map[string]*SessionTracker),
+		logDir:        logDir,
+		logDropped:    make(map[string]int64),
+		delivering:    make(map[string]bool),
+		historyPath:   historyPath,
 		remoteManager: nil,
 	}
 }
@@ -94,7 +134,21 @@ func (m *Manager) SpawnRemote(ctx context.Context, flavorID, targetName, prompt,
 		return nil, err
 	}
 
-	command, err := buildCommand(resolved, prompt, nil, false)
+	if err := m.checkPromptLength(prompt); err != nil {
+		return nil, err
+	}
+	// Remote hosts can't read a local prompt file, so long prompts must fit inline.
+	if len(prompt) > inlinePromptMaxBytes {
+		return nil, fmt.Errorf("prompt is %d bytes; remote sessions support prompts up to %d bytes", len(prompt), inlinePromptMaxBytes)
+	}
+
+	// Apply the nickname collision policy (auto-suffix or reject) before doing any work
+	uniqueNickname, err := m.resolveNickname(nickname, "")
+	if err != nil {
+		return nil, err
+	}
+
+	command, err := buildCommand(resolved, prompt, "", nil, false)
 	if err != nil {
 		return nil, err
 	}
@@ -111,12 +165,6 @@ func (m *Manager) SpawnRemote(ctx context.Context, flavorID, targetName, prompt,
 	// Create session ID
 	sessionID := fmt.Sprintf("remote-%s-%s", flavorID, uuid.New().String()[:8])
 
-	// Generate unique nickname if provided
-	uniqueNickname := nickname
-	if nickname != "" {
-		uniqueNickname = m.generateUniqueNickname(nickname)
-	}
-
 	// Use nickname as window name if provided, otherwise use sessionID
 	windowName := sessionID
 	if uniqueNickname != "" {
@@ -168,13 +216,14 @@ func (m *Manager) SpawnRemote(ctx context.Context, flavorID, targetName, prompt,
 			RemoteHostID: host.ID,
 			RemotePaneID: "", // Will be set when queue is drained
 			RemoteWindow: "", // Will be set when queue is drained
-			Status:       "provisioning",
+			Status:       state.SessionStatusProvisioning,
 		}
 
 		// Save immediately with provisioning status
 		if err := m.state.AddSession(sess); err != nil {
 			return nil, fmt.Errorf("failed to add session to state: %w", err)
 		}
+		m.recordWorkspacePrompt(sess, prompt)
 		if err := m.state.Save(); err != nil {
 			return nil, fmt.Errorf("failed to save state: %w", err)
 		}
@@ -183,18 +232,30 @@ func (m *Manager) SpawnRemote(ctx context.Context, flavorID, targetName, prompt,
 		go func() {
 			select {
 			case result := <-resultCh:
+				// Start from the stored session so messages queued meanwhile are kept
+				m.messageMu.Lock()
+				updated := sess
+				if current, found := m.state.GetSession(sessionID); found {
+					updated = current
+				}
 				if result.Error != nil {
 					fmt.Printf("[session] queued session %s failed: %v\n", sessionID, result.Error)
-					sess.Status = "failed"
+					updated.Status = state.SessionStatusFailed
+					updated.FailReason = result.Error.Error()
+					updated.FailedAt = time.Now()
 				} else {
 					fmt.Printf("[session] queued session %s succeeded (window=%s, pane=%s)\n",
 						sessionID, result.WindowID, result.PaneID)
-					sess.Status = "running"
-					sess.RemoteWindow = result.WindowID
-					sess.RemotePaneID = result.PaneID
+					updated.Status = state.SessionStatusRunning
+					updated.RemoteWindow = result.WindowID
+					updated.RemotePaneID = result.PaneID
 				}
-				m.state.UpdateSession(sess)
+				m.state.UpdateSession(updated)
 				m.state.Save()
+				if result.Error == nil {
+					m.startQueuedDeliveryLocked(sessionID)
+				}
+				m.messageMu.Unlock()
 			case <-ctx.Done():
 				return
 			}
@@ -221,12 +282,13 @@ func (m *Manager) SpawnRemote(ctx context.Context, flavorID, targetName, prompt,
 		RemoteHostID: host.ID,
 		RemotePaneID: paneID,
 		RemoteWindow: windowID,
-		Status:       "running",
+		Status:       state.SessionStatusRunning,
 	}
 
 	if err := m.state.AddSession(sess); err != nil {
 		return nil, fmt.Errorf("failed to add session to state: %w", err)
 	}
+	m.recordWorkspacePrompt(sess, prompt)
 	if err := m.state.Save(); err != nil {
 		return nil, fmt.Errorf("failed to save state: %w", err)
 	}
@@ -241,7 +303,27 @@ func (m *Manager) SpawnRemote(ctx context.Context, flavorID, targetName, prompt,
 // prompt is only used if the target is promptable.
 // resume enables resume mode, which uses the agent's resume command instead of a prompt.
 func (m *Manager) Spawn(ctx context.Context, repoURL, branch, targetName, prompt, nickname string, workspaceID string, resume bool) (*state.Session, error) {
+	// With the "queue" policy, an unavailable target produces a blocked session
+	// that can be started later via Restart instead of failing the spawn.
+	blockedReason := ""
 	resolved, err := m.ResolveTarget(ctx, targetName)
+	if err != nil {
+		if !errors.Is(err, ErrTargetUnavailable) || m.config.GetUnavailableTargetPolicy() != config.UnavailableTargetPolicyQueue {
+			return nil, err
+		}
+		blockedReason = err.Error()
+	}
+	if err := m.checkPromptLength(prompt); err != nil {
+		return nil, err
+	}
+	if blockedReason == "" {
+		if err := checkTargetShell(resolved); err != nil {
+			return nil, err
+		}
+	}
+
+	// Apply the nickname collision policy (auto-suffix or reject) before doing any work
+	uniqueNickname, err := m.resolveNickname(nickname, "")
 	if err != nil {
 		return nil, err
 	}
@@ -262,6 +344,9 @@ func (m *Manager) Spawn(ctx context.Context, repoURL, branch, targetName, prompt
 			return nil, fmt.Errorf("failed to get workspace: %w", err)
 		}
 	}
+	if err := m.checkSessionLimit(w.ID); err != nil {
+		return nil, err
+	}
 
 	// Provision agent instruction files with signaling instructions
 	if err := provision.EnsureAgentInstructions(w.Path, targetName); err != nil {
@@ -269,44 +354,174 @@ func (m *Manager) Spawn(ctx context.Context, repoURL, branch, targetName, prompt
 		fmt.Printf("[session] warning: failed to provision agent instructions: %v\n", err)
 	}
 
-	// Resolve model if target is a model kind
-	var model *detect.Model
-	if resolved.Kind == TargetKindModel {
-		if m, ok := detect.FindModel(resolved.Name); ok {
-			model = &m
+	// Create session ID
+	sessionID := fmt.Sprintf("%s-%s", w.ID, uuid.New().String()[:8])
+
+	// Use sanitized unique nickname for tmux session name if provided, otherwise use sessionID
+	tmuxSession := sessionID
+	if uniqueNickname != "" {
+		tmuxSession = sanitizeNickname(uniqueNickname)
+	}
+
+	sess := state.Session{
+		ID:          sessionID,
+		WorkspaceID: w.ID,
+		Target:      targetName,
+		Nickname:    uniqueNickname,
+		TmuxSession: tmuxSession,
+		CreatedAt:   time.Now(),
+		Panes:       resolved.Panes,
+		PaneLayout:  resolved.PaneLayout,
+	}
+
+	if blockedReason != "" {
+		// Keep the prompt so Restart can start the session once the target is available.
+		sess.Status = state.SessionStatusBlocked
+		sess.BlockedReason = blockedReason
+		sess.PendingPrompt = prompt
+		sess.PendingResume = resume
+		fmt.Printf("[session] spawn queued as blocked: session_id=%s target=%s reason=%s\n", sessionID, targetName, blockedReason)
+	} else {
+		if err := m.startTmuxSession(ctx, w, &sess, resolved, prompt, resume); err != nil {
+			return nil, err
+		}
+		// The prompt is only persisted when the history records it
+		if m.config.GetHistoryRecordPrompts() {
+			sess.Prompt = prompt
 		}
 	}
 
-	// Create session ID
-	sessionID := fmt.Sprintf("%s-%s", w.ID, uuid.New().String()[:8])
+	if err := m.state.AddSession(sess); err != nil {
+		return nil, fmt.Errorf("failed to add session to state: %w", err)
+	}
+	if !resume {
+		m.recordWorkspacePrompt(sess, prompt)
+	}
+	if err := m.state.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
 
-	// Inject schmux signaling environment variables
-	resolved.Env = mergeEnvMaps(resolved.Env, map[string]string{
-		"SCHMUX_ENABLED":      "1",
-		"SCHMUX_SESSION_ID":   sessionID,
-		"SCHMUX_WORKSPACE_ID": w.ID,
-	})
+	if !sess.IsBlocked() {
+		m.ensureTrackerFromSession(sess)
+	}
 
-	command, err := buildCommand(resolved, prompt, model, resume)
+	return &sess, nil
+}
+
+// Restart re-resolves the target of a blocked session and, if it is now available,
+// starts the session's tmux session with the prompt captured at spawn time.
+// If the target is still unavailable, the session stays blocked with an updated reason.
+func (m *Manager) Restart(ctx context.Context, sessionID string) (*state.Session, error) {
+	sess, found := m.state.GetSession(sessionID)
+	if !found {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	if !sess.IsBlocked() {
+		return nil, fmt.Errorf("session is not blocked: %s", sessionID)
+	}
+	w, found := m.workspace.GetByID(sess.WorkspaceID)
+	if !found {
+		return nil, fmt.Errorf("workspace not found: %s", sess.WorkspaceID)
+	}
+
+	resolved, err := m.ResolveTarget(ctx, sess.Target)
 	if err != nil {
+		if errors.Is(err, ErrTargetUnavailable) && err.Error() != sess.BlockedReason {
+			sess.BlockedReason = err.Error()
+			if updateErr := m.state.UpdateSession(sess); updateErr == nil {
+				m.state.Save()
+			}
+		}
+		return nil, err
+	}
+	if err := checkTargetShell(resolved); err != nil {
 		return nil, err
 	}
 
-	// Generate unique nickname if provided (auto-suffix if duplicate)
-	uniqueNickname := nickname
-	if nickname != "" {
-		uniqueNickname = m.generateUniqueNickname(nickname)
+	if err := m.startTmuxSession(ctx, w, &sess, resolved, sess.PendingPrompt, sess.PendingResume); err != nil {
+		return nil, err
 	}
 
-	// Use sanitized unique nickname for tmux session name if provided, otherwise use sessionID
-	tmuxSession := sessionID
-	if uniqueNickname != "" {
-		tmuxSession = sanitizeNickname(uniqueNickname)
+	sess.Status = ""
+	sess.BlockedReason = ""
+	if m.config.GetHistoryRecordPrompts() {
+		sess.Prompt = sess.PendingPrompt
+	}
+	sess.PendingPrompt = ""
+	sess.PendingResume = false
+	// Keep messages queued while the session was starting
+	m.messageMu.Lock()
+	if current, found := m.state.GetSession(sessionID); found {
+		sess.QueuedMessages = current.QueuedMessages
+	}
+	if err := m.state.UpdateSession(sess); err != nil {
+		m.messageMu.Unlock()
+		return nil, fmt.Errorf("failed to update session in state: %w", err)
+	}
+	if err := m.state.Save(); err != nil {
+		m.messageMu.Unlock()
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+	m.startQueuedDeliveryLocked(sess.ID)
+	m.messageMu.Unlock()
+
+	m.ensureTrackerFromSession(sess)
+
+	fmt.Printf("[session] restarted blocked session: session_id=%s target=%s\n", sess.ID, sess.Target)
+	return &sess, nil
+}
+
+// checkSessionLimit returns an error if the workspace already has
+// sessions.max_per_workspace sessions.
+func (m *Manager) checkSessionLimit(workspaceID string) error {
+	limit := m.config.GetMaxSessionsPerWorkspace()
+	if limit <= 0 {
+		return nil
+	}
+	count := 0
+	for _, sess := range m.state.GetSessions() {
+		if sess.WorkspaceID == workspaceID {
+			count++
+		}
+	}
+	if count >= limit {
+		return fmt.Errorf("workspace %s already has %d sessions, the limit set by sessions.max_per_workspace; dispose sessions first or use another workspace", workspaceID, count)
+	}
+	return nil
+}
+
+// checkTargetShell verifies that a target's configured shell exists.
+func checkTargetShell(resolved ResolvedTarget) error {
+	if resolved.Shell == "" {
+		return nil
+	}
+	if _, err := exec.LookPath(resolved.Shell); err != nil {
+		return fmt.Errorf("shell %q for target %s not found: %w", resolved.Shell, resolved.Name, err)
+	}
+	return nil
+}
+
+// startTmuxSession builds the target command and starts it in the session's tmux
+// session in the workspace, splitting off the session's extra panes once the agent is
+// up. Sets the session's Pid and, for multi-pane sessions, AgentPane.
+func (m *Manager) startTmuxSession(ctx context.Context, w *state.Workspace, sess *state.Session, resolved ResolvedTarget, prompt string, resume bool) error {
+	sessionID, tmuxSession := sess.ID, sess.TmuxSession
+	command, promptFile, err := m.sessionCommand(w, sessionID, resolved, prompt, resume)
+	if err != nil {
+		return err
+	}
+	supervised := m.watchesExit(resolved.Name)
+	if supervised {
+		command = recordExitStatus(command, sessionID)
 	}
 
 	// Create tmux session
-	if err := tmux.CreateSession(ctx, tmuxSession, w.Path, command); err != nil {
-		return nil, fmt.Errorf("failed to create tmux session: %w", err)
+	// Held, so an agent that exits right away leaves its output for checkStartup
+	if err := tmux.CreateHeldSession(ctx, tmuxSession, w.Path, command, paneCreationTmuxOptions(resolved.TmuxOptions)); err != nil {
+		if promptFile != "" {
+			os.Remove(promptFile)
+		}
+		return fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
 	// Force fixed window size for deterministic TUI output
@@ -318,54 +533,145 @@ func (m *Manager) Spawn(ctx context.Context, repoURL, branch, targetName, prompt
 		fmt.Printf("[session] warning: failed to resize window: %v\n", err)
 	}
 
-	// Configure status bar: process on left, time on right, clear center
-	if err := tmux.SetOption(ctx, tmuxSession, "status-left", "#{pane_current_command} "); err != nil {
-		fmt.Printf("[session] warning: failed to set status-left: %v\n", err)
+	applyTmuxOptions(ctx, tmuxSession, resolved.TmuxOptions)
+
+	// Get the PID of the agent process from tmux pane
+	pid, err := tmux.GetPanePID(ctx, tmuxSession)
+	if err != nil {
+		return fmt.Errorf("failed to get pane PID: %w", err)
 	}
-	if err := tmux.SetOption(ctx, tmuxSession, "window-status-format", ""); err != nil {
-		fmt.Printf("[session] warning: failed to set window-status-format: %v\n", err)
+
+	// An agent that exits at once (bad flags, missing credentials) is a failed spawn,
+	// not a session that shows as running until the next poll. Sessions of auto-restart
+	// targets and targets with an on_exit_command stay held so a later exit leaves its
+	// code behind.
+	if err := checkStartup(ctx, tmuxSession, false, supervised); err != nil {
+		if promptFile != "" {
+			os.Remove(promptFile)
+		}
+		if supervised {
+			os.Remove(exitStatusFile(sessionID))
+		}
+		return fmt.Errorf("%s %w", resolved.Name, err)
 	}
-	if err := tmux.SetOption(ctx, tmuxSession, "window-status-current-format", ""); err != nil {
-		fmt.Printf("[session] warning: failed to set window-status-current-format: %v\n", err)
+	sess.Pid = pid
+	sess.AgentPane = ""
+	if len(sess.Panes) > 0 {
+		m.createPanes(ctx, w.Path, sess)
 	}
-	if err := tmux.SetOption(ctx, tmuxSession, "status-right", ""); err != nil {
-		fmt.Printf("[session] warning: failed to set status-right: %v\n", err)
+	return nil
+}
+
+// sessionCommand builds the shell command that runs a target in a session: the
+// target's command with the workspace env and schmux signaling variables, wrapped in
+// the target's shell and resource limits. Long prompts are passed through a temporary
+// file, returned so callers can remove it if the command never runs.
+func (m *Manager) sessionCommand(w *state.Workspace, sessionID string, resolved ResolvedTarget, prompt string, resume bool) (command, promptFile string, err error) {
+	// Resolve model if target is a model kind
+	var model *detect.Model
+	if resolved.Kind == TargetKindModel {
+		if m, ok := detect.FindModel(resolved.Name); ok {
+			model = &m
+		}
 	}
 
-	// Get the PID of the agent process from tmux pane
-	pid, err := tmux.GetPanePID(ctx, tmuxSession)
+	// Workspace env file vars apply to every session in the workspace; the target's
+	// own env takes precedence. A broken file is reported but doesn't block the spawn.
+	workspaceEnv, err := m.workspace.LoadWorkspaceEnv(w)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pane PID: %w", err)
+		fmt.Printf("[session] warning: skipping workspace env file: %v\n", err)
 	}
+	resolved.Env = mergeEnvMaps(workspaceEnv, resolved.Env)
 
-	// Create session state with cached PID (no Prompt field)
-	sess := state.Session{
-		ID:          sessionID,
-		WorkspaceID: w.ID,
-		Target:      targetName,
-		Nickname:    uniqueNickname,
-		TmuxSession: tmuxSession,
-		CreatedAt:   time.Now(),
-		Pid:         pid,
+	// Inject schmux signaling environment variables
+	resolved.Env = mergeEnvMaps(resolved.Env, map[string]string{
+		"SCHMUX_ENABLED":      "1",
+		"SCHMUX_SESSION_ID":   sessionID,
+		"SCHMUX_WORKSPACE_ID": w.ID,
+	})
+
+	// ${NAME} in the target command resolves against that env, then the daemon's
+	resolved.Command = interpolateCommand(resolved.Command, sessionEnvLookup(resolved.Env))
+
+	if !resume && resolved.Promptable && len(prompt) > inlinePromptMaxBytes {
+		promptFile, err = writePromptFile(m.promptFileDir(w.Path), prompt)
+		if err != nil {
+			return "", "", err
+		}
 	}
 
-	if err := m.state.AddSession(sess); err != nil {
-		return nil, fmt.Errorf("failed to add session to state: %w", err)
+	command, err = buildCommand(resolved, prompt, promptFile, model, resume)
+	if err != nil {
+		if promptFile != "" {
+			os.Remove(promptFile)
+		}
+		return "", "", err
 	}
-	if err := m.state.Save(); err != nil {
-		return nil, fmt.Errorf("failed to save state: %w", err)
+	if resolved.Shell != "" {
+		command = wrapInShell(resolved.Shell, command)
 	}
+	return m.wrapSessionCommand(command, w.Path), promptFile, nil
+}
 
-	m.ensureTrackerFromSession(sess)
+// defaultTmuxOptions configure the status bar: process on left, clear center and right.
+var defaultTmuxOptions = map[string]string{
+	"status-left":                  "#{pane_current_command} ",
+	"window-status-format":         "",
+	"window-status-current-format": "",
+	"status-right":                 "",
+}
 
-	return &sess, nil
+// paneCreationTmuxOptions returns the options tmux only reads when a pane is created,
+// which must be set before the session's command starts.
+func paneCreationTmuxOptions(options map[string]string) map[string]string {
+	if value, ok := options["history-limit"]; ok {
+		return map[string]string{"history-limit": value}
+	}
+	return nil
+}
+
+// applyTmuxOptions sets defaultTmuxOptions merged with a target's tmux_options on a session.
+// Failures are logged; a misapplied option shouldn't fail the spawn.
+func applyTmuxOptions(ctx context.Context, tmuxSession string, overrides map[string]string) {
+	options := make(map[string]string, len(defaultTmuxOptions)+len(overrides))
+	for option, value := range defaultTmuxOptions {
+		options[option] = value
+	}
+	for option, value := range overrides {
+		options[option] = value
+	}
+	names := make([]string, 0, len(options))
+	for option := range options {
+		names = append(names, option)
+	}
+	sort.Strings(names)
+	for _, option := range names {
+		if err := tmux.SetOption(ctx, tmuxSession, option, options[option]); err != nil {
+			fmt.Printf("[session] warning: failed to set %s: %v\n", option, err)
+		}
+	}
+}
+
+// ApplySessionTmuxOptions re-applies the default tmux options merged with the session's
+// target tmux_options, e.g. when a terminal connects after a config change.
+func (m *Manager) ApplySessionTmuxOptions(ctx context.Context, sess state.Session) {
+	var overrides map[string]string
+	if target, found := m.config.GetRunTarget(sess.Target); found {
+		overrides = target.TmuxOptions
+	}
+	applyTmuxOptions(ctx, sess.TmuxSession, overrides)
 }
 
 // SpawnCommand spawns a session running a raw shell command.
 // Used for quick launch presets with a direct command (no target resolution).
 func (m *Manager) SpawnCommand(ctx context.Context, repoURL, branch, command, nickname, workspaceID string) (*state.Session, error) {
 	var w *state.Workspace
-	var err error
+
+	// Apply the nickname collision policy (auto-suffix or reject) before doing any work
+	uniqueNickname, err := m.resolveNickname(nickname, "")
+	if err != nil {
+		return nil, err
+	}
 
 	if workspaceID != "" {
 		// Spawn into specific workspace (Existing Directory Spawn mode - no git operations)
@@ -381,6 +687,9 @@ func (m *Manager) SpawnCommand(ctx context.Context, repoURL, branch, command, ni
 			return nil, fmt.Errorf("failed to get workspace: %w", err)
 		}
 	}
+	if err := m.checkSessionLimit(w.ID); err != nil {
+		return nil, err
+	}
 
 	// Create session ID
 	sessionID := fmt.Sprintf("%s-%s", w.ID, uuid.New().String()[:8])
@@ -391,13 +700,7 @@ func (m *Manager) SpawnCommand(ctx context.Context, repoURL, branch, command, ni
 		"SCHMUX_SESSION_ID":   sessionID,
 		"SCHMUX_WORKSPACE_ID": w.ID,
 	}
-	commandWithEnv := fmt.Sprintf("%s %s", buildEnvPrefix(schmuxEnv), command)
-
-	// Generate unique nickname if provided (auto-suffix if duplicate)
-	uniqueNickname := nickname
-	if nickname != "" {
-		uniqueNickname = m.generateUniqueNickname(nickname)
-	}
+	commandWithEnv := m.wrapSessionCommand(fmt.Sprintf("%s %s", buildEnvPrefix(schmuxEnv), command), w.Path)
 
 	// Use sanitized unique nickname for tmux session name if provided, otherwise use sessionID
 	tmuxSession := sessionID
@@ -405,8 +708,8 @@ func (m *Manager) SpawnCommand(ctx context.Context, repoURL, branch, command, ni
 		tmuxSession = sanitizeNickname(uniqueNickname)
 	}
 
-	// Create tmux session with the raw command
-	if err := tmux.CreateSession(ctx, tmuxSession, w.Path, commandWithEnv); err != nil {
+	// Create tmux session with the raw command, held so checkStartup can read its output
+	if err := tmux.CreateHeldSession(ctx, tmuxSession, w.Path, commandWithEnv, nil); err != nil {
 		return nil, fmt.Errorf("failed to create tmux session: %w", err)
 	}
 
@@ -419,29 +722,83 @@ func (m *Manager) SpawnCommand(ctx context.Context, repoURL, branch, command, ni
 		fmt.Printf("[session] warning: failed to resize window: %v\n", err)
 	}
 
-	// Configure status bar: process on left, time on right, clear center
-	if err := tmux.SetOption(ctx, tmuxSession, "status-left", "#{pane_current_command} "); err != nil {
-		fmt.Printf("[session] warning: failed to set status-left: %v\n", err)
+	applyTmuxOptions(ctx, tmuxSession, nil)
+
+	// Get the PID of the process from tmux pane
+	pid, err := tmux.GetPanePID(ctx, tmuxSession)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pane PID: %w", err)
 	}
-	if err := tmux.SetOption(ctx, tmuxSession, "window-status-format", ""); err != nil {
-		fmt.Printf("[session] warning: failed to set window-status-format: %v\n", err)
+
+	// A command that fails at once is reported; one that finishes cleanly is fine.
+	if err := checkStartup(ctx, tmuxSession, true, false); err != nil {
+		return nil, fmt.Errorf("command %w", err)
 	}
-	if err := tmux.SetOption(ctx, tmuxSession, "window-status-current-format", ""); err != nil {
-		fmt.Printf("[session] warning: failed to set window-status-current-format: %v\n", err)
+
+	// Create session state (Target uses a stable value for command-based sessions)
+	sess := state.Session{
+		ID:          sessionID,
+		WorkspaceID: w.ID,
+		Target:      "command",
+		Nickname:    uniqueNickname,
+		TmuxSession: tmuxSession,
+		CreatedAt:   time.Now(),
+		Pid:         pid,
 	}
-	if err := tmux.SetOption(ctx, tmuxSession, "status-right", ""); err != nil {
-		fmt.Printf("[session] warning: failed to set status-right: %v\n", err)
+
+	if err := m.state.AddSession(sess); err != nil {
+		return nil, fmt.Errorf("failed to add session to state: %w", err)
+	}
+	if err := m.state.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	m.ensureTrackerFromSession(sess)
+
+	return &sess, nil
+}
+
+// Adopt registers a tmux session that schmux isn't tracking (created by hand, or lost
+// from state after a crash) as a command session in the given workspace. The tmux
+// session keeps its name; only the nickname shown in the dashboard is set.
+func (m *Manager) Adopt(ctx context.Context, tmuxSession, workspaceID, nickname string) (*state.Session, error) {
+	w, found := m.workspace.GetByID(workspaceID)
+	if !found {
+		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	if w.RemoteHostID != "" {
+		return nil, fmt.Errorf("cannot adopt into remote workspace: %s", workspaceID)
+	}
+	if !tmux.SessionExists(ctx, tmuxSession) {
+		return nil, fmt.Errorf("%w: %s", ErrTmuxSessionNotFound, tmuxSession)
+	}
+	for _, existing := range m.state.GetSessions() {
+		if existing.TmuxSession == tmuxSession && existing.RemoteHostID == "" {
+			return nil, fmt.Errorf("%w: %s is session %s", ErrAlreadyManaged, tmuxSession, existing.ID)
+		}
+	}
+
+	uniqueNickname, err := m.resolveNickname(nickname, "")
+	if err != nil {
+		return nil, err
 	}
 
-	// Get the PID of the process from tmux pane
 	pid, err := tmux.GetPanePID(ctx, tmuxSession)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pane PID: %w", err)
 	}
 
-	// Create session state (Target uses a stable value for command-based sessions)
+	// Match spawned sessions so the dashboard terminal renders at the configured size
+	width, height := m.config.GetTerminalSize()
+	if err := tmux.SetWindowSizeManual(ctx, tmuxSession); err != nil {
+		fmt.Printf("[session] warning: failed to set manual window size: %v\n", err)
+	}
+	if err := tmux.ResizeWindow(ctx, tmuxSession, width, height); err != nil {
+		fmt.Printf("[session] warning: failed to resize window: %v\n", err)
+	}
+
 	sess := state.Session{
-		ID:          sessionID,
+		ID:          fmt.Sprintf("%s-%s", w.ID, uuid.New().String()[:8]),
 		WorkspaceID: w.ID,
 		Target:      "command",
 		Nickname:    uniqueNickname,
@@ -464,6 +821,13 @@ func (m *Manager) SpawnCommand(ctx context.Context, repoURL, branch, command, ni
 
 // ResolveTarget resolves a target name to a command and env.
 func (m *Manager) ResolveTarget(_ context.Context, targetName string) (ResolvedTarget, error) {
+	// A bare detected tool runs its configured default model, if it has one
+	if _, detected := m.config.GetDetectedRunTarget(targetName); detected {
+		if modelID := m.config.GetDefaultModel(targetName); modelID != "" {
+			targetName = modelID
+		}
+	}
+
 	// Check if it's a model (handles aliases like "opus", "sonnet", "haiku")
 	model, ok := detect.FindModel(targetName)
 	if ok {
@@ -485,10 +849,10 @@ func (m *Manager) ResolveTarget(_ context.Context, targetName string) (ResolvedT
 		}
 		secrets, err := config.GetEffectiveModelSecrets(model)
 		if err != nil {
-			return ResolvedTarget{}, fmt.Errorf("failed to load secrets for model %s: %w", model.ID, err)
+			return ResolvedTarget{}, fmt.Errorf("%w: failed to load secrets for model %s: %w", ErrTargetUnavailable, model.ID, err)
 		}
 		if err := ensureModelSecrets(model, secrets); err != nil {
-			return ResolvedTarget{}, err
+			return ResolvedTarget{}, fmt.Errorf("%w: %w", ErrTargetUnavailable, err)
 		}
 		env := mergeEnvMaps(model.BuildEnv(), secrets)
 		return ResolvedTarget{
@@ -507,14 +871,18 @@ func (m *Manager) ResolveTarget(_ context.Context, targetName string) (ResolvedT
 			kind = TargetKindDetected
 		}
 		return ResolvedTarget{
-			Name:       target.Name,
-			Kind:       kind,
-			Command:    target.Command,
-			Promptable: target.Type == config.RunTargetTypePromptable,
+			Name:        target.Name,
+			Kind:        kind,
+			Command:     target.Command,
+			Promptable:  target.Type == config.RunTargetTypePromptable,
+			Shell:       target.Shell,
+			TmuxOptions: target.TmuxOptions,
+			Panes:       sessionPanes(target.Panes),
+			PaneLayout:  target.Layout,
 		}, nil
 	}
 
-	return ResolvedTarget{}, fmt.Errorf("target not found: %s", targetName)
+	return ResolvedTarget{}, fmt.Errorf("%w: %s", ErrTargetNotFound, targetName)
 }
 
 // shellQuote quotes a string for safe use in shell commands using single quotes.
@@ -524,7 +892,62 @@ func shellQuote(s string) string {
 	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
 }
 
-func buildCommand(target ResolvedTarget, prompt string, model *detect.Model, resume bool) (string, error) {
+// inlinePromptMaxBytes is the largest prompt embedded directly in the tmux command.
+// tmux rejects commands that exceed its message size limit (~16KB), so longer prompts
+// are written to a file and read back by the shell when the session starts.
+const inlinePromptMaxBytes = 8 * 1024
+
+// checkPromptLength returns an error if the prompt exceeds sessions.max_prompt_bytes.
+func (m *Manager) checkPromptLength(prompt string) error {
+	if limit := m.config.GetMaxPromptBytes(); len(prompt) > limit {
+		return fmt.Errorf("prompt is %d bytes, which exceeds the limit of %d bytes (sessions.max_prompt_bytes)", len(prompt), limit)
+	}
+	return nil
+}
+
+// promptFileDir returns where a session's prompt file goes: the system temp directory,
+// or the workspace when sessions.sandbox_wrapper is set, since the file is read inside
+// the sandbox and the workspace is the directory the sandbox is told about. A sandbox
+// with a private /tmp would otherwise hand the agent an empty prompt.
+func (m *Manager) promptFileDir(workspacePath string) string {
+	if m.config.GetSandboxWrapper() != "" {
+		return workspacePath
+	}
+	return ""
+}
+
+// writePromptFile writes a long prompt to a private temp file in dir (the system temp
+// directory if empty) for the session command to read. The command deletes the file
+// once it has been read.
+func writePromptFile(dir, prompt string) (string, error) {
+	f, err := os.CreateTemp(dir, ".schmux-prompt-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create prompt file: %w", err)
+	}
+	if _, err := f.WriteString(prompt); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// promptShellArg returns the prompt as a single shell word. When promptFile is set, the
+// prompt is substituted from the file (which is then removed) instead of inlined.
+func promptShellArg(prompt, promptFile string) string {
+	if promptFile != "" {
+		return fmt.Sprintf("\"$(cat %s; rm -f %s)\"", shellQuote(promptFile), shellQuote(promptFile))
+	}
+	return shellQuote(prompt)
+}
+
+// buildCommand builds the shell command for a target. promptFile, if non-empty, holds the
+// prompt contents and is used in place of inlining the prompt.
+func buildCommand(target ResolvedTarget, prompt, promptFile string, model *detect.Model, resume bool) (string, error) {
 	trimmedPrompt := strings.TrimSpace(prompt)
 
 	// Handle resume mode
@@ -557,7 +980,7 @@ func buildCommand(target ResolvedTarget, prompt string, model *detect.Model, res
 		if trimmedPrompt == "" {
 			return "", fmt.Errorf("prompt is required for target %s", target.Name)
 		}
-		command := fmt.Sprintf("%s %s", baseCommand, shellQuote(prompt))
+		command := fmt.Sprintf("%s %s", baseCommand, promptShellArg(prompt, promptFile))
 		if len(target.Env) > 0 {
 			return fmt.Sprintf("%s %s", buildEnvPrefix(target.Env), command), nil
 		}
@@ -573,6 +996,68 @@ func buildCommand(target ResolvedTarget, prompt string, model *detect.Model, res
 	return baseCommand, nil
 }
 
+// wrapInShell runs command through the given shell as a login shell, so the
+// shell's init files are sourced before the command starts.
+func wrapInShell(shell, command string) string {
+	return fmt.Sprintf("%s -l -c %s", shellQuote(shell), shellQuote(command))
+}
+
+// wrapSessionCommand applies the sandbox wrapper and resource limits that every command
+// schmux starts in a session pane gets, whether the agent, a raw command, an extra pane,
+// or a target probe.
+func (m *Manager) wrapSessionCommand(command, workspacePath string) string {
+	return m.applyResourceLimits(m.applySandbox(command, workspacePath))
+}
+
+// applySandbox runs command inside sessions.sandbox_wrapper, if one is set. The wrapper
+// is a command prefix such as "firejail --net=none"; ${SCHMUX_WORKSPACE_PATH} in it
+// expands to the workspace directory so the sandbox can mount or whitelist it.
+func (m *Manager) applySandbox(command, workspacePath string) string {
+	return wrapSandbox(command, m.config.GetSandboxWrapper(), workspacePath)
+}
+
+func wrapSandbox(command, wrapper, workspacePath string) string {
+	if wrapper == "" {
+		return command
+	}
+	wrapper = interpolateCommand(wrapper, sessionEnvLookup(map[string]string{"SCHMUX_WORKSPACE_PATH": workspacePath}))
+	// Through sh -c, like wrapResourceLimits, because the command may start with
+	// environment assignments the wrapper can't exec directly.
+	return fmt.Sprintf("%s sh -c %s", wrapper, shellQuote(command))
+}
+
+// applyResourceLimits runs command under nice/ionice per sessions.nice_level and
+// sessions.ionice_class. The command goes through sh -c because it may start with
+// environment assignments, which nice and ionice can't exec directly.
+func (m *Manager) applyResourceLimits(command string) string {
+	return wrapResourceLimits(command, m.config.GetNiceLevel(), m.config.GetIoniceClass(), ioniceAvailable())
+}
+
+func wrapResourceLimits(command string, niceLevel int, ioniceClass string, haveIonice bool) string {
+	var prefix []string
+	if ioniceClass != "" && haveIonice {
+		switch ioniceClass {
+		case config.IoniceClassIdle:
+			prefix = append(prefix, "ionice", "-c", "3")
+		case config.IoniceClassBestEffort:
+			prefix = append(prefix, "ionice", "-c", "2", "-n", "7")
+		}
+	}
+	if niceLevel > 0 {
+		prefix = append(prefix, "nice", "-n", strconv.Itoa(niceLevel))
+	}
+	if len(prefix) == 0 {
+		return command
+	}
+	return fmt.Sprintf("%s sh -c %s", strings.Join(prefix, " "), shellQuote(command))
+}
+
+// ioniceAvailable reports whether ionice is installed (it's Linux-only).
+func ioniceAvailable() bool {
+	_, err := exec.LookPath("ionice")
+	return err == nil
+}
+
 func buildEnvPrefix(env map[string]string) string {
 	keys := make([]string, 0, len(env))
 	for k := range env {
@@ -756,9 +1241,10 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 	orphanKilled := 0
 	tmuxKilled := false
 
-	// Get the workspace for process cleanup fallback
+	// Get the workspace for process cleanup fallback.
+	// Blocked sessions never started, so there are no processes to clean up.
 	ws, found := m.workspace.GetByID(sess.WorkspaceID)
-	if found {
+	if found && !sess.IsBlocked() {
 		// Step 1: Kill the tracked process group (if we have a PID)
 		if sess.Pid > 0 {
 			if err := killProcessGroup(sess.Pid); err != nil {
@@ -791,12 +1277,21 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 		}
 	}
 
+	exitStatus, exitCode := localExitStatus(ctx, sess)
+
 	// Step 3: Kill tmux session (ignore error if already gone - that's success)
 	if err := tmux.KillSession(ctx, sess.TmuxSession); err == nil {
 		tmuxKilled = true
 	}
 
 	m.stopTracker(sessionID)
+	os.Remove(exitStatusFile(sessionID))
+
+	if !m.config.GetXtermRetainAfterDispose() {
+		if removed := m.removeSessionLogs(sessionID); removed > 0 {
+			fmt.Printf("[session] removed %d log file(s) for %s\n", removed, sessionID)
+		}
+	}
 
 	// Note: workspace is NOT cleaned up on session disposal.
 	// Workspaces persist and are only reset when reused for a new spawn.
@@ -808,6 +1303,7 @@ func (m *Manager) Dispose(ctx context.Context, sessionID string) error {
 	if err := m.state.Save(); err != nil {
 		return fmt.Errorf("failed to save state: %w", err)
 	}
+	m.recordHistory(sess, exitStatus, exitCode)
 
 	// Print summary
 	summary := fmt.Sprintf("Disposed session %s: killed %d process group", sessionID, processesKilled)
@@ -855,6 +1351,11 @@ func (m *Manager) disposeRemoteSession(ctx context.Context, sess state.Session)
 	if err := m.state.Save(); err != nil {
 		return fmt.Errorf("failed to save state: %w", err)
 	}
+	exitStatus := HistoryExitKilled
+	if sess.Status == state.SessionStatusFailed {
+		exitStatus = HistoryExitNeverStarted
+	}
+	m.recordHistory(sess, exitStatus, nil)
 
 	// Print summary
 	summary := fmt.Sprintf("Disposed remote session %s", sess.ID)
@@ -871,6 +1372,81 @@ func (m *Manager) disposeRemoteSession(ctx context.Context, sess state.Session)
 	return nil
 }
 
+// sessionLogFiles returns the log files for a session, including rotated copies (<id>.log.*).
+func (m *Manager) sessionLogFiles(sessionID string) []string {
+	if m.logDir == "" {
+		return nil
+	}
+	base := filepath.Join(m.logDir, sessionID+".log")
+	files := []string{base}
+	if rotated, err := filepath.Glob(base + ".*"); err == nil {
+		files = append(files, rotated...)
+	}
+	return files
+}
+
+// removeSessionLogs deletes the log files for a session. Returns the number of files removed.
+func (m *Manager) removeSessionLogs(sessionID string) int {
+	m.logMu.Lock()
+	delete(m.logDropped, sessionID)
+	m.logMu.Unlock()
+
+	removed := 0
+	for _, path := range m.sessionLogFiles(sessionID) {
+		if err := os.Remove(path); err == nil {
+			removed++
+		} else if !os.IsNotExist(err) {
+			fmt.Printf("[session] warning: failed to remove log %s: %v\n", path, err)
+		}
+	}
+	return removed
+}
+
+// sessionLogNamePattern matches the files schmux writes for a session in the log
+// directory: <session-id>.log, its rotated copies, and the .dropped sidecar. Session IDs
+// end in the first 8 hex digits of a UUID. The capture is the session ID.
+var sessionLogNamePattern = regexp.MustCompile(`^(.+-[0-9a-f]{8})\.log(?:\.(?:[0-9]+|dropped))?$`)
+
+// PruneLogFiles deletes log files for sessions that are no longer in state. Only files
+// named like schmux's own session logs are touched, so anything else kept in the log
+// directory survives. It is a no-op when xterm.retain_after_dispose is set. Returns the
+// number of files removed.
+func (m *Manager) PruneLogFiles() (int, error) {
+	if m.logDir == "" || m.config.GetXtermRetainAfterDispose() {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(m.logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	activeIDs := make(map[string]bool)
+	for _, sess := range m.state.GetSessions() {
+		activeIDs[sess.ID] = true
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		match := sessionLogNamePattern.FindStringSubmatch(name)
+		if match == nil || activeIDs[match[1]] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(m.logDir, name)); err != nil {
+			fmt.Printf("[session] warning: failed to remove orphaned log %s: %v\n", name, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
 // GetAttachCommand returns the tmux attach command for a session.
 func (m *Manager) GetAttachCommand(sessionID string) (string, error) {
 	sess, found := m.state.GetSession(sessionID)
@@ -881,14 +1457,56 @@ func (m *Manager) GetAttachCommand(sessionID string) (string, error) {
 	return tmux.GetAttachCommand(sess.TmuxSession), nil
 }
 
-// GetOutput returns the current terminal output for a session.
+// GetOutput returns the current terminal output for a session as plain text.
 func (m *Manager) GetOutput(ctx context.Context, sessionID string) (string, error) {
+	output, err := m.GetOutputRaw(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	return tmux.StripAnsi(output), nil
+}
+
+// GetOutputRaw returns the current terminal output for a session with ANSI
+// escape sequences (colors, attributes) preserved, as captured by tmux capture-pane -e.
+func (m *Manager) GetOutputRaw(ctx context.Context, sessionID string) (string, error) {
 	sess, found := m.state.GetSession(sessionID)
 	if !found {
 		return "", fmt.Errorf("session not found: %s", sessionID)
 	}
+	if sess.IsBlocked() {
+		return "", fmt.Errorf("session %s is blocked and has no output", sessionID)
+	}
 
-	return tmux.CaptureOutput(ctx, sess.TmuxSession)
+	return tmux.CaptureOutput(ctx, sess.TmuxTarget())
+}
+
+// Reconcile finds local sessions whose process and tmux session are gone and, unless
+// keepDead is set, drops them from state. Nothing is killed; remote and blocked
+// sessions are left alone. Returns the IDs of the dead sessions.
+func (m *Manager) Reconcile(ctx context.Context, keepDead bool) ([]string, error) {
+	var dead []string
+	for _, sess := range m.state.GetSessions() {
+		if sess.IsRemoteSession() || sess.IsBlocked() {
+			continue
+		}
+		if m.IsRunning(ctx, sess.ID) {
+			continue
+		}
+		dead = append(dead, sess.ID)
+		if keepDead {
+			continue
+		}
+		m.stopTracker(sess.ID)
+		if err := m.state.RemoveSession(sess.ID); err != nil {
+			return dead, fmt.Errorf("failed to remove session %s from state: %w", sess.ID, err)
+		}
+	}
+	if len(dead) > 0 && !keepDead {
+		if err := m.state.Save(); err != nil {
+			return dead, fmt.Errorf("failed to save state: %w", err)
+		}
+	}
+	return dead, nil
 }
 
 // GetAllSessions returns all sessions.
@@ -907,16 +1525,17 @@ func (m *Manager) GetSession(sessionID string) (*state.Session, error) {
 
 // RenameSession updates a session's nickname and renames the tmux session.
 // The nickname is sanitized before use as the tmux session name.
-// Returns an error if the new nickname conflicts with an existing session.
+// A nickname that conflicts with another session is suffixed or rejected
+// (ErrNicknameInUse) according to sessions.nickname_collision.
 func (m *Manager) RenameSession(ctx context.Context, sessionID, newNickname string) error {
 	sess, found := m.state.GetSession(sessionID)
 	if !found {
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
-	// Check if new nickname conflicts with an existing session
-	if conflictingID := m.nicknameExists(newNickname, sessionID); conflictingID != "" {
-		return fmt.Errorf("nickname %q already in use by session %s", newNickname, conflictingID)
+	newNickname, err := m.resolveNickname(newNickname, sessionID)
+	if err != nil {
+		return err
 	}
 
 	oldTmuxName := sess.TmuxSession
@@ -975,20 +1594,34 @@ func (m *Manager) nicknameExists(nickname, excludeSessionID string) string {
 	return ""
 }
 
+// resolveNickname applies sessions.nickname_collision to a requested nickname.
+// excludeSessionID is the session being renamed, if any. With "reject", a taken
+// nickname returns an error wrapping ErrNicknameInUse; otherwise it is suffixed.
+func (m *Manager) resolveNickname(nickname, excludeSessionID string) (string, error) {
+	conflictingID := m.nicknameExists(nickname, excludeSessionID)
+	if conflictingID == "" {
+		return nickname, nil
+	}
+	if m.config.GetNicknameCollision() == config.NicknameCollisionReject {
+		return "", fmt.Errorf("nickname %q %w by session %s", nickname, ErrNicknameInUse, conflictingID)
+	}
+	return m.generateUniqueNickname(nickname, excludeSessionID), nil
+}
+
 // generateUniqueNickname generates a unique nickname by trying the base name,
 // then "name (1)", "name (2)", etc. until a unique name is found.
-func (m *Manager) generateUniqueNickname(baseNickname string) string {
+func (m *Manager) generateUniqueNickname(baseNickname, excludeSessionID string) string {
 	if baseNickname == "" {
 		return ""
 	}
 	// Try base name first
-	if m.nicknameExists(baseNickname, "") == "" {
+	if m.nicknameExists(baseNickname, excludeSessionID) == "" {
 		return baseNickname
 	}
 	// Try numbered suffixes
 	for i := 1; i <= maxNicknameAttempts; i++ {
 		candidate := fmt.Sprintf("%s (%d)", baseNickname, i)
-		if m.nicknameExists(candidate, "") == "" {
+		if m.nicknameExists(candidate, excludeSessionID) == "" {
 			return candidate
 		}
 	}
@@ -1012,6 +1645,9 @@ func (m *Manager) GetTracker(sessionID string) (*SessionTracker, error) {
 	if !found {
 		return nil, fmt.Errorf("session not found: %s", sessionID)
 	}
+	if sess.IsBlocked() {
+		return nil, fmt.Errorf("session is blocked: %s", sess.BlockedReason)
+	}
 	return m.ensureTrackerFromSession(sess), nil
 }
 
@@ -1019,11 +1655,17 @@ func (m *Manager) ensureTrackerFromSession(sess state.Session) *SessionTracker {
 	m.mu.Lock()
 	if existing := m.trackers[sess.ID]; existing != nil {
 		existing.SetTmuxSession(sess.TmuxSession)
+		existing.SetAgentPane(sess.AgentPane)
 		m.mu.Unlock()
 		return existing
 	}
 
 	tracker := NewSessionTracker(sess.ID, sess.TmuxSession, m.state)
+	tracker.SetAgentPane(sess.AgentPane)
+	if !sess.IsRemoteSession() && m.watchesExit(sess.Target) {
+		sessionID := sess.ID
+		tracker.SetExitHandler(func() { m.handleSessionExit(sessionID) })
+	}
 	m.trackers[sess.ID] = tracker
 	m.mu.Unlock()
 	tracker.Start()
@@ -2,7 +2,9 @@ package session
 
 import (
 	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -185,6 +187,16 @@ func TestGetOutput(t *testing.T) {
 		if err == nil {
 			t.Error("expected error for nonexistent session")
 		}
+		if _, err := m.GetOutputRaw(context.Background(), "nonexistent"); err == nil {
+			t.Error("expected raw error for nonexistent session")
+		}
+	})
+
+	t.Run("returns error for blocked session", func(t *testing.T) {
+		st.AddSession(state.Session{ID: "blocked-1", Status: state.SessionStatusBlocked})
+		if _, err := m.GetOutputRaw(context.Background(), "blocked-1"); err == nil {
+			t.Error("expected error for blocked session")
+		}
 	})
 }
 
@@ -305,6 +317,76 @@ func TestRenameSession(t *testing.T) {
 	})
 }
 
+func TestSessionLimitPerWorkspace(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces", Sessions: &config.SessionsConfig{}}
+	st := state.New("")
+	statePath := t.TempDir() + "/state.json"
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+	st.AddWorkspace(state.Workspace{ID: "ws-1", Repo: "https://example.com/repo.git", Branch: "main", Path: t.TempDir()})
+	st.AddSession(state.Session{ID: "s1", WorkspaceID: "ws-1"})
+	st.AddSession(state.Session{ID: "s2", WorkspaceID: "ws-1"})
+	st.AddSession(state.Session{ID: "s3", WorkspaceID: "ws-2"})
+
+	if err := m.checkSessionLimit("ws-1"); err != nil {
+		t.Errorf("unlimited by default, got %v", err)
+	}
+	cfg.Sessions.MaxPerWorkspace = 3
+	if err := m.checkSessionLimit("ws-1"); err != nil {
+		t.Errorf("below the limit, got %v", err)
+	}
+	cfg.Sessions.MaxPerWorkspace = 2
+	if err := m.checkSessionLimit("ws-2"); err != nil {
+		t.Errorf("other workspaces' sessions must not count, got %v", err)
+	}
+
+	// The limit is checked before any tmux session is created.
+	_, err := m.SpawnCommand(context.Background(), "", "", "echo hi", "", "ws-1")
+	if err == nil || !strings.Contains(err.Error(), "sessions.max_per_workspace") {
+		t.Fatalf("expected max_per_workspace error, got %v", err)
+	}
+}
+
+func TestResolveNickname(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces", Sessions: &config.SessionsConfig{}}
+	st := state.New("")
+	statePath := t.TempDir() + "/state.json"
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+	st.AddSession(state.Session{ID: "s1", TmuxSession: "reviewer"})
+	st.AddSession(state.Session{ID: "s2", TmuxSession: "reviewer (1)"})
+
+	tests := []struct {
+		name, policy, nickname, exclude, want string
+		wantErr                               bool
+	}{
+		{name: "free name", policy: config.NicknameCollisionReject, nickname: "builder", want: "builder"},
+		{name: "empty", policy: config.NicknameCollisionReject, nickname: "", want: ""},
+		{name: "suffix", policy: "", nickname: "reviewer", want: "reviewer (2)"},
+		{name: "suffix rename keeps own name", policy: config.NicknameCollisionSuffix, nickname: "reviewer", exclude: "s1", want: "reviewer"},
+		{name: "reject", policy: config.NicknameCollisionReject, nickname: "reviewer", wantErr: true},
+		{name: "reject rename keeps own name", policy: config.NicknameCollisionReject, nickname: "reviewer", exclude: "s1", want: "reviewer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Sessions.NicknameCollision = tt.policy
+			got, err := m.resolveNickname(tt.nickname, tt.exclude)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNicknameInUse) {
+					t.Fatalf("expected ErrNicknameInUse, got %v", err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("resolveNickname() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	cfg.Sessions.NicknameCollision = config.NicknameCollisionReject
+	if err := m.RenameSession(context.Background(), "s2", "reviewer"); !errors.Is(err, ErrNicknameInUse) {
+		t.Errorf("RenameSession() error = %v, want ErrNicknameInUse", err)
+	}
+}
+
 func TestDispose(t *testing.T) {
 	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
 	st := state.New("")
@@ -398,6 +480,69 @@ func TestPruneLogFiles(t *testing.T) {
 	})
 }
 
+func TestManagerPruneLogFiles(t *testing.T) {
+	setup := func(t *testing.T, retain bool) (*Manager, string) {
+		cfg := &config.Config{WorkspacePath: "/tmp/workspaces", Xterm: &config.XtermConfig{RetainAfterDispose: retain}}
+		st := state.New("")
+		statePath := filepath.Join(t.TempDir(), "state.json")
+		wm := workspace.New(cfg, st, statePath)
+		m := New(cfg, st, statePath, wm)
+		st.AddSession(state.Session{ID: "ws-001-9f8e7d6c", TmuxSession: "active"})
+
+		logDir := filepath.Join(filepath.Dir(statePath), "logs")
+		if err := os.MkdirAll(logDir, 0755); err != nil {
+			t.Fatalf("failed to create log dir: %v", err)
+		}
+		for _, name := range []string{"ws-001-9f8e7d6c.log", "ws-001-0a1b2c3d.log", "ws-001-0a1b2c3d.log.1", "ws-001-0a1b2c3d.log.dropped", "daemon.log", "daemon.log.1", "notes.txt"} {
+			if err := os.WriteFile(filepath.Join(logDir, name), []byte("x"), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+		return m, logDir
+	}
+
+	t.Run("removes logs for sessions not in state", func(t *testing.T) {
+		m, logDir := setup(t, false)
+		removed, err := m.PruneLogFiles()
+		if err != nil {
+			t.Fatalf("PruneLogFiles() error: %v", err)
+		}
+		if removed != 3 {
+			t.Errorf("expected 3 files removed, got %d", removed)
+		}
+		// Files that aren't schmux session logs are left alone
+		for _, name := range []string{"ws-001-9f8e7d6c.log", "daemon.log", "daemon.log.1", "notes.txt"} {
+			if _, err := os.Stat(filepath.Join(logDir, name)); err != nil {
+				t.Errorf("expected %s to be kept: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("retain_after_dispose keeps everything", func(t *testing.T) {
+		m, logDir := setup(t, true)
+		removed, err := m.PruneLogFiles()
+		if err != nil {
+			t.Fatalf("PruneLogFiles() error: %v", err)
+		}
+		if removed != 0 {
+			t.Errorf("expected no files removed, got %d", removed)
+		}
+		if _, err := os.Stat(filepath.Join(logDir, "ws-001-0a1b2c3d.log")); err != nil {
+			t.Errorf("expected ws-001-0a1b2c3d.log to be kept: %v", err)
+		}
+	})
+
+	t.Run("removeSessionLogs removes rotated copies", func(t *testing.T) {
+		m, logDir := setup(t, false)
+		if removed := m.removeSessionLogs("ws-001-0a1b2c3d"); removed != 3 {
+			t.Errorf("expected 3 files removed, got %d", removed)
+		}
+		if _, err := os.Stat(filepath.Join(logDir, "ws-001-9f8e7d6c.log")); err != nil {
+			t.Errorf("expected ws-001-9f8e7d6c.log to be kept: %v", err)
+		}
+	})
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (len(substr) == 0 || s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || containsMiddle(s, substr)))
 }
@@ -615,7 +760,7 @@ func TestBuildCommand(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := buildCommand(tt.target, tt.prompt, tt.model, tt.resume)
+			got, err := buildCommand(tt.target, tt.prompt, "", tt.model, tt.resume)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("buildCommand() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -648,6 +793,290 @@ func TestBuildCommand(t *testing.T) {
 	}
 }
 
+func TestBuildCommandPromptFile(t *testing.T) {
+	prompt := strings.Repeat("it's a \"long\" $PROMPT `x`\n", inlinePromptMaxBytes/20) + "end"
+	promptFile, err := writePromptFile("", prompt)
+	if err != nil {
+		t.Fatalf("writePromptFile() error: %v", err)
+	}
+	defer os.Remove(promptFile)
+
+	target := ResolvedTarget{Name: "echo", Command: "printf %s", Promptable: true}
+	command, err := buildCommand(target, prompt, promptFile, nil, false)
+	if err != nil {
+		t.Fatalf("buildCommand() error: %v", err)
+	}
+	if len(command) > inlinePromptMaxBytes {
+		t.Fatalf("command is %d bytes, expected prompt to be read from file", len(command))
+	}
+
+	output, err := exec.Command("sh", "-c", command).Output()
+	if err != nil {
+		t.Fatalf("running %q failed: %v", command, err)
+	}
+	if string(output) != prompt {
+		t.Errorf("agent received %d bytes, want the %d byte prompt unchanged", len(output), len(prompt))
+	}
+	if _, err := os.Stat(promptFile); !os.IsNotExist(err) {
+		t.Errorf("expected prompt file to be removed after read, stat err = %v", err)
+	}
+}
+
+func TestWrapResourceLimits(t *testing.T) {
+	command := `GREETING='it'"'"'s' printenv GREETING`
+	tests := []struct {
+		name       string
+		niceLevel  int
+		ionice     string
+		haveIonice bool
+		wantPrefix string
+	}{
+		{name: "unset", wantPrefix: ""},
+		{name: "nice", niceLevel: 10, wantPrefix: "nice -n 10 sh -c "},
+		{name: "idle io", ionice: config.IoniceClassIdle, haveIonice: true, wantPrefix: "ionice -c 3 sh -c "},
+		{name: "both", niceLevel: 5, ionice: config.IoniceClassBestEffort, haveIonice: true, wantPrefix: "ionice -c 2 -n 7 nice -n 5 sh -c "},
+		{name: "no ionice binary", niceLevel: 5, ionice: config.IoniceClassIdle, wantPrefix: "nice -n 5 sh -c "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapResourceLimits(command, tt.niceLevel, tt.ionice, tt.haveIonice)
+			if tt.wantPrefix == "" {
+				if got != command {
+					t.Errorf("expected command unchanged, got %q", got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, tt.wantPrefix) {
+				t.Errorf("wrapResourceLimits() = %q, want prefix %q", got, tt.wantPrefix)
+			}
+		})
+	}
+
+	// The wrapped command must still run with its environment assignments intact.
+	if _, err := exec.LookPath("nice"); err != nil {
+		t.Skip("nice not available")
+	}
+	output, err := exec.Command("sh", "-c", wrapResourceLimits(command, 10, "", false)).Output()
+	if err != nil {
+		t.Fatalf("running wrapped command failed: %v", err)
+	}
+	if string(output) != "it's\n" {
+		t.Errorf("wrapped command printed %q, want %q", output, "it's\n")
+	}
+}
+
+func TestWrapSandbox(t *testing.T) {
+	command := `GREETING='it'"'"'s' printenv GREETING`
+	if got := wrapSandbox(command, "", "/ws/repo-001"); got != command {
+		t.Errorf("expected command unchanged without a wrapper, got %q", got)
+	}
+
+	got := wrapSandbox(command, "docker run -v ${SCHMUX_WORKSPACE_PATH}:/work image", "/ws/it's")
+	if want := `docker run -v '/ws/it'\''s':/work image sh -c `; !strings.HasPrefix(got, want) {
+		t.Errorf("wrapSandbox() = %q, want prefix %q", got, want)
+	}
+
+	// A pass-through wrapper must leave the command runnable with its env intact
+	output, err := exec.Command("sh", "-c", wrapSandbox(command, "env", "/ws")).Output()
+	if err != nil {
+		t.Fatalf("running wrapped command failed: %v", err)
+	}
+	if string(output) != "it's\n" {
+		t.Errorf("wrapped command printed %q, want %q", output, "it's\n")
+	}
+}
+
+func TestSandboxPromptFile(t *testing.T) {
+	if _, err := exec.LookPath("unshare"); err != nil {
+		t.Skip("unshare not available")
+	}
+	// A wrapper that gives the command a private, empty temp directory
+	tmp := os.TempDir()
+	wrapper := "unshare -rm sh -c 'mount -t tmpfs none \"$0\" && exec \"$@\"' " + shellQuote(tmp)
+	if err := exec.Command("sh", "-c", wrapper+" true").Run(); err != nil {
+		t.Skipf("cannot mount a private %s: %v", tmp, err)
+	}
+	wsDir, err := os.MkdirTemp(".", "workspace-")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error: %v", err)
+	}
+	defer os.RemoveAll(wsDir)
+	wsDir, _ = filepath.Abs(wsDir)
+	if strings.HasPrefix(wsDir, tmp) {
+		t.Skip("test workspace would be hidden by the sandbox")
+	}
+
+	m := &Manager{config: &config.Config{Sessions: &config.SessionsConfig{SandboxWrapper: wrapper}}}
+	prompt := strings.Repeat("p", inlinePromptMaxBytes+1)
+	promptFile, err := writePromptFile(m.promptFileDir(wsDir), prompt)
+	if err != nil {
+		t.Fatalf("writePromptFile() error: %v", err)
+	}
+	defer os.Remove(promptFile)
+
+	target := ResolvedTarget{Name: "echo", Command: "printf %s", Promptable: true}
+	command, err := buildCommand(target, prompt, promptFile, nil, false)
+	if err != nil {
+		t.Fatalf("buildCommand() error: %v", err)
+	}
+	output, err := exec.Command("sh", "-c", m.applySandbox(command, wsDir)).Output()
+	if err != nil {
+		t.Fatalf("running sandboxed command failed: %v", err)
+	}
+	if string(output) != prompt {
+		t.Errorf("sandboxed agent received %d bytes, want the %d byte prompt", len(output), len(prompt))
+	}
+}
+
+func TestWrapInShell(t *testing.T) {
+	target := ResolvedTarget{Name: "echo", Command: "printf %s", Promptable: true, Env: map[string]string{"GREETING": "hi"}}
+	command, err := buildCommand(target, "it's $GREETING", "", nil, false)
+	if err != nil {
+		t.Fatalf("buildCommand() error: %v", err)
+	}
+
+	output, err := exec.Command("sh", "-c", wrapInShell("sh", command)).Output()
+	if err != nil {
+		t.Fatalf("running wrapped command failed: %v", err)
+	}
+	if string(output) != "it's $GREETING" {
+		t.Errorf("wrapped command output = %q, want the prompt unchanged", output)
+	}
+}
+
+func TestSpawnRejectsMissingShell(t *testing.T) {
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		RunTargets: []config.RunTarget{
+			{Name: "tool", Type: config.RunTargetTypeCommand, Command: "true", Source: config.RunTargetSourceUser, Shell: "schmux-no-such-shell"},
+		},
+	}
+	m := New(cfg, state.New(""), "", nil)
+
+	_, err := m.Spawn(context.Background(), "", "", "tool", "", "", "missing", false)
+	if err == nil || !strings.Contains(err.Error(), "schmux-no-such-shell") {
+		t.Fatalf("expected missing shell error, got %v", err)
+	}
+}
+
+func TestResolveTargetTmuxOptions(t *testing.T) {
+	options := map[string]string{"history-limit": "100000", "mouse": "on"}
+	cfg := &config.Config{
+		RunTargets: []config.RunTarget{
+			{Name: "tool", Type: config.RunTargetTypeCommand, Command: "true", Source: config.RunTargetSourceUser, TmuxOptions: options},
+		},
+	}
+	m := New(cfg, state.New(""), "", nil)
+
+	resolved, err := m.ResolveTarget(context.Background(), "tool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.TmuxOptions["mouse"] != "on" {
+		t.Errorf("expected tmux options on resolved target, got %v", resolved.TmuxOptions)
+	}
+	// Only history-limit needs to be set before the pane exists.
+	if got := paneCreationTmuxOptions(resolved.TmuxOptions); len(got) != 1 || got["history-limit"] != "100000" {
+		t.Errorf("paneCreationTmuxOptions() = %v", got)
+	}
+	if got := paneCreationTmuxOptions(map[string]string{"mouse": "on"}); got != nil {
+		t.Errorf("expected no pane creation options, got %v", got)
+	}
+}
+
+func TestResolveTargetDefaultModel(t *testing.T) {
+	cfg := &config.Config{
+		RunTargets: []config.RunTarget{
+			{Name: "claude", Type: config.RunTargetTypePromptable, Command: "claude", Source: config.RunTargetSourceDetected},
+		},
+	}
+	m := New(cfg, state.New(""), "", nil)
+
+	resolved, err := m.ResolveTarget(context.Background(), "claude")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Name != "claude" || resolved.Command != "claude" {
+		t.Errorf("without a default model: %+v", resolved)
+	}
+
+	cfg.DefaultModels = map[string]string{"claude": "opus"}
+	resolved, err = m.ResolveTarget(context.Background(), "claude")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Name != "claude-opus" || resolved.Model == nil {
+		t.Errorf("with default model opus: %+v", resolved)
+	}
+}
+
+func TestSpawnQueuesUnavailableTarget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	newManager := func(policy string) (*Manager, *state.State) {
+		cfg := &config.Config{
+			WorkspacePath: t.TempDir(),
+			RunTargets: []config.RunTarget{
+				{Name: "claude", Type: config.RunTargetTypePromptable, Command: "claude", Source: config.RunTargetSourceDetected},
+			},
+			Sessions: &config.SessionsConfig{UnavailableTargetPolicy: policy},
+		}
+		statePath := filepath.Join(t.TempDir(), "state.json")
+		st := state.New(statePath)
+		if err := st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "git@example.com:me/repo.git", Branch: "main", Path: t.TempDir()}); err != nil {
+			t.Fatal(err)
+		}
+		return New(cfg, st, statePath, workspace.New(cfg, st, statePath)), st
+	}
+
+	// Default policy: a model without its required secret fails the spawn.
+	m, _ := newManager("")
+	if _, err := m.Spawn(context.Background(), "", "", "kimi-thinking", "do it", "", "ws-001", false); !errors.Is(err, ErrTargetUnavailable) {
+		t.Fatalf("expected ErrTargetUnavailable, got %v", err)
+	}
+
+	// Queue policy: the spawn succeeds with a blocked session holding the prompt.
+	m, st := newManager(config.UnavailableTargetPolicyQueue)
+	sess, err := m.Spawn(context.Background(), "", "", "kimi-thinking", "do it", "agent", "ws-001", false)
+	if err != nil {
+		t.Fatalf("Spawn() error: %v", err)
+	}
+	stored, found := st.GetSession(sess.ID)
+	if !found {
+		t.Fatalf("blocked session %s not saved to state", sess.ID)
+	}
+	if !stored.IsBlocked() || stored.PendingPrompt != "do it" || !strings.Contains(stored.BlockedReason, "ANTHROPIC_AUTH_TOKEN") {
+		t.Fatalf("unexpected blocked session: %+v", stored)
+	}
+	if m.IsRunning(context.Background(), sess.ID) {
+		t.Error("blocked session should not be running")
+	}
+	if _, err := m.GetTracker(sess.ID); err == nil {
+		t.Error("expected GetTracker to fail for blocked session")
+	}
+
+	// Restart re-resolves the target; it stays blocked while the secret is still missing.
+	if _, err := m.Restart(context.Background(), sess.ID); !errors.Is(err, ErrTargetUnavailable) {
+		t.Fatalf("expected ErrTargetUnavailable from Restart, got %v", err)
+	}
+	if stored, _ := st.GetSession(sess.ID); !stored.IsBlocked() {
+		t.Errorf("session should remain blocked, got status %q", stored.Status)
+	}
+}
+
+func TestCheckPromptLength(t *testing.T) {
+	cfg := &config.Config{Sessions: &config.SessionsConfig{MaxPromptBytes: 10}}
+	m := New(cfg, state.New(""), "", nil)
+
+	if err := m.checkPromptLength("0123456789"); err != nil {
+		t.Errorf("prompt at the limit should pass: %v", err)
+	}
+	err := m.checkPromptLength("0123456789a")
+	if err == nil || !strings.Contains(err.Error(), "max_prompt_bytes") {
+		t.Errorf("expected max_prompt_bytes error, got %v", err)
+	}
+}
+
 func TestGetTrackerAndEnsureTracker(t *testing.T) {
 	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
 	st := state.New("")
@@ -697,3 +1126,92 @@ func TestGetTrackerAndEnsureTracker(t *testing.T) {
 		m.stopTracker(sess.ID)
 	})
 }
+
+func TestAdoptErrors(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: t.TempDir()}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	if err := st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "git@example.com:me/repo.git", Branch: "main", Path: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	if _, err := m.Adopt(context.Background(), "some-session", "missing", ""); err == nil {
+		t.Error("expected error for missing workspace")
+	}
+	if _, err := m.Adopt(context.Background(), "schmux-no-such-session-xyz", "ws-001", ""); !errors.Is(err, ErrTmuxSessionNotFound) {
+		t.Errorf("expected ErrTmuxSessionNotFound, got %v", err)
+	}
+	if len(st.GetSessions()) != 0 {
+		t.Error("expected no sessions after failed adopts")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: t.TempDir()}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	// A PID that has exited and been reaped.
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Skipf("cannot run true: %v", err)
+	}
+	for _, sess := range []state.Session{
+		{ID: "alive", TmuxSession: "alive", Pid: os.Getpid()},
+		{ID: "dead", TmuxSession: "dead", Pid: exited.Process.Pid},
+		{ID: "blocked", TmuxSession: "blocked", Status: state.SessionStatusBlocked},
+		{ID: "remote", TmuxSession: "remote", RemoteHostID: "host-1"},
+	} {
+		if err := st.AddSession(sess); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dead, err := m.Reconcile(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Reconcile(keepDead) error: %v", err)
+	}
+	if len(dead) != 1 || dead[0] != "dead" {
+		t.Fatalf("Reconcile(keepDead) = %v, want [dead]", dead)
+	}
+	if _, found := st.GetSession("dead"); !found {
+		t.Fatal("keep_dead should leave the dead session in state")
+	}
+
+	if dead, err = m.Reconcile(context.Background(), false); err != nil || len(dead) != 1 {
+		t.Fatalf("Reconcile() = %v, %v", dead, err)
+	}
+	if _, found := st.GetSession("dead"); found {
+		t.Error("dead session should be removed from state")
+	}
+	if got := len(st.GetSessions()); got != 3 {
+		t.Errorf("expected 3 sessions to remain, got %d", got)
+	}
+}
+
+func TestParseProbeOutput(t *testing.T) {
+	tests := []struct {
+		name     string
+		captured string
+		output   string
+		code     int
+		exited   bool
+	}{
+		{"running", "\x1b[32mloading\x1b[0m...\n\n", "loading...", 0, false},
+		{"exit ok", "OK\n\n" + targetProbeMarker + " 0\n", "OK", 0, true},
+		{"exit failure", "command not found\n\n" + targetProbeMarker + " 127\n", "command not found", 127, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, code, exited := parseProbeOutput(tt.captured)
+			if output != tt.output || code != tt.code || exited != tt.exited {
+				t.Errorf("parseProbeOutput() = (%q, %d, %v), want (%q, %d, %v)", output, code, exited, tt.output, tt.code, tt.exited)
+			}
+		})
+	}
+	if output, _, _ := parseProbeOutput(strings.Repeat("x", 2*targetProbeMaxOutput)); len(output) != targetProbeMaxOutput {
+		t.Errorf("expected output capped at %d, got %d", targetProbeMaxOutput, len(output))
+	}
+}
@@ -0,0 +1,141 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+const (
+	// queuedMessageQuiet is how long a started agent's output must be quiet before its
+	// queued messages are typed, so they reach its input prompt rather than its boot screen.
+	queuedMessageQuiet = 2 * time.Second
+	// queuedMessageMaxWait bounds the wait for agents that never go quiet, and for
+	// remote sessions, whose output isn't tracked.
+	queuedMessageMaxWait = 15 * time.Second
+)
+
+// ErrSessionNotRunning is returned by SendMessage when the session's agent can't
+// receive input: it failed to start, exited, or its remote host is disconnected.
+var ErrSessionNotRunning = errors.New("session is not running")
+
+// SendMessage types a follow-up message into the session's agent and presses Enter.
+// Messages for a session that hasn't started yet (provisioning or blocked) are queued
+// in state and delivered in order once its agent is ready; so are messages sent while
+// that delivery is pending. queued reports which happened.
+func (m *Manager) SendMessage(ctx context.Context, sessionID, message string) (queued bool, err error) {
+	m.messageMu.Lock()
+	defer m.messageMu.Unlock()
+
+	sess, found := m.state.GetSession(sessionID)
+	if !found {
+		return false, fmt.Errorf("session not found: %s", sessionID)
+	}
+	queue := m.delivering[sessionID]
+	switch sess.Status {
+	case state.SessionStatusProvisioning, state.SessionStatusBlocked:
+		queue = true
+	case state.SessionStatusFailed:
+		return false, fmt.Errorf("%w: %s failed to start", ErrSessionNotRunning, sessionID)
+	}
+	if queue {
+		sess.QueuedMessages = append(sess.QueuedMessages, message)
+		if err := m.state.UpdateSession(sess); err != nil {
+			return false, fmt.Errorf("failed to update session in state: %w", err)
+		}
+		if err := m.state.Save(); err != nil {
+			return false, fmt.Errorf("failed to save state: %w", err)
+		}
+		fmt.Printf("[session] queued message: session_id=%s queued=%d\n", sessionID, len(sess.QueuedMessages))
+		return true, nil
+	}
+	return false, m.typeMessage(ctx, sess, message)
+}
+
+// typeMessage sends message followed by Enter to the session's pane.
+func (m *Manager) typeMessage(ctx context.Context, sess state.Session, message string) error {
+	if sess.RemoteHostID != "" {
+		if m.remoteManager == nil {
+			return fmt.Errorf("remote manager not configured")
+		}
+		conn := m.remoteManager.GetConnection(sess.RemoteHostID)
+		if conn == nil || !conn.IsConnected() || sess.RemotePaneID == "" {
+			return fmt.Errorf("%w: remote host for %s is not connected", ErrSessionNotRunning, sess.ID)
+		}
+		return conn.SendKeys(ctx, sess.RemotePaneID, message+"\r")
+	}
+
+	if !tmux.SessionExists(ctx, sess.TmuxSession) {
+		return fmt.Errorf("%w: tmux session %s not found", ErrSessionNotRunning, sess.TmuxSession)
+	}
+	if err := tmux.SendLiteral(ctx, sess.TmuxTarget(), message); err != nil {
+		return err
+	}
+	return tmux.SendKeys(ctx, sess.TmuxTarget(), "Enter")
+}
+
+// startQueuedDeliveryLocked delivers a just-started session's queued messages in the
+// background once its agent is ready. Callers hold messageMu.
+func (m *Manager) startQueuedDeliveryLocked(sessionID string) {
+	if sess, found := m.state.GetSession(sessionID); !found || len(sess.QueuedMessages) == 0 {
+		return
+	}
+	m.delivering[sessionID] = true
+	started := time.Now()
+	go func() {
+		m.waitForAgentReady(sessionID, started)
+		m.deliverQueuedMessages(sessionID)
+	}()
+}
+
+// waitForAgentReady waits until a session started at started has printed output and
+// then gone quiet for queuedMessageQuiet, which is when an agent's TUI has booted and
+// is waiting for input, or until queuedMessageMaxWait has passed.
+func (m *Manager) waitForAgentReady(sessionID string, started time.Time) {
+	deadline := started.Add(queuedMessageMaxWait)
+	for time.Now().Before(deadline) {
+		sess, found := m.state.GetSession(sessionID)
+		if !found {
+			return
+		}
+		if sess.LastOutputAt.After(started) && time.Since(sess.LastOutputAt) >= queuedMessageQuiet {
+			return
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// deliverQueuedMessages sends the messages queued while a session was starting.
+// Call it once the session's agent is ready. Delivery failures are logged, not
+// returned: the session itself started fine.
+func (m *Manager) deliverQueuedMessages(sessionID string) {
+	m.messageMu.Lock()
+	defer m.messageMu.Unlock()
+	delete(m.delivering, sessionID)
+
+	sess, found := m.state.GetSession(sessionID)
+	if !found || len(sess.QueuedMessages) == 0 {
+		return
+	}
+	messages := sess.QueuedMessages
+	sess.QueuedMessages = nil
+	if err := m.state.UpdateSession(sess); err != nil {
+		fmt.Printf("[session] warning: failed to clear queued messages for %s: %v\n", sessionID, err)
+		return
+	}
+	m.state.Save()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(m.config.GetXtermOperationTimeoutMs())*time.Millisecond)
+	defer cancel()
+	for i, message := range messages {
+		if err := m.typeMessage(ctx, sess, message); err != nil {
+			fmt.Printf("[session] warning: failed to deliver queued message %d/%d to %s: %v\n", i+1, len(messages), sessionID, err)
+			return
+		}
+	}
+	fmt.Printf("[session] delivered queued messages: session_id=%s count=%d\n", sessionID, len(messages))
+}
@@ -0,0 +1,106 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+func TestSendMessage_QueuesUntilStarted(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
+	statePath := t.TempDir() + "/state.json"
+	st := state.New(statePath)
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+	st.AddSession(state.Session{ID: "blocked", Status: state.SessionStatusBlocked})
+	st.AddSession(state.Session{ID: "provisioning", RemoteHostID: "host-1", Status: state.SessionStatusProvisioning})
+	st.AddSession(state.Session{ID: "failed", RemoteHostID: "host-1", Status: state.SessionStatusFailed})
+	ctx := context.Background()
+
+	for _, id := range []string{"blocked", "provisioning"} {
+		for _, message := range []string{"first", "second"} {
+			queued, err := m.SendMessage(ctx, id, message)
+			if err != nil || !queued {
+				t.Fatalf("SendMessage(%s) = %v, %v; want queued", id, queued, err)
+			}
+		}
+		sess, _ := st.GetSession(id)
+		if !slices.Equal(sess.QueuedMessages, []string{"first", "second"}) {
+			t.Errorf("%s queued messages = %v, want [first second]", id, sess.QueuedMessages)
+		}
+	}
+
+	if _, err := m.SendMessage(ctx, "failed", "hello"); !errors.Is(err, ErrSessionNotRunning) {
+		t.Errorf("SendMessage(failed) error = %v, want ErrSessionNotRunning", err)
+	}
+	if _, err := m.SendMessage(ctx, "missing", "hello"); err == nil {
+		t.Error("expected error for missing session")
+	}
+
+	// The remote host isn't connected, so delivery fails, but the queue is still drained.
+	m.deliverQueuedMessages("provisioning")
+	if sess, _ := st.GetSession("provisioning"); len(sess.QueuedMessages) != 0 {
+		t.Errorf("expected queue to be cleared, got %v", sess.QueuedMessages)
+	}
+}
+
+func TestSendMessage_QueuesBehindPendingDelivery(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
+	statePath := t.TempDir() + "/state.json"
+	st := state.New(statePath)
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+	st.AddSession(state.Session{ID: "booting", RemoteHostID: "host-1", Status: state.SessionStatusRunning, QueuedMessages: []string{"first"}})
+
+	// Until the agent is ready, later messages line up behind the queued ones
+	m.delivering["booting"] = true
+	queued, err := m.SendMessage(context.Background(), "booting", "second")
+	if err != nil || !queued {
+		t.Fatalf("SendMessage() = %v, %v; want queued", queued, err)
+	}
+	if sess, _ := st.GetSession("booting"); !slices.Equal(sess.QueuedMessages, []string{"first", "second"}) {
+		t.Errorf("queued messages = %v, want [first second]", sess.QueuedMessages)
+	}
+
+	m.deliverQueuedMessages("booting")
+	if m.delivering["booting"] {
+		t.Error("delivery still marked pending after delivering")
+	}
+}
+
+func TestWaitForAgentReady(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
+	statePath := t.TempDir() + "/state.json"
+	st := state.New(statePath)
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+	st.AddSession(state.Session{ID: "s1"})
+	st.AddSession(state.Session{ID: "s2"})
+	started := time.Now().Add(-5 * time.Second)
+
+	// Output after the start, then quiet: ready
+	st.UpdateSessionLastOutput("s1", started.Add(time.Second))
+	begin := time.Now()
+	m.waitForAgentReady("s1", started)
+	if waited := time.Since(begin); waited > time.Second {
+		t.Errorf("waited %v for an agent that had gone quiet", waited)
+	}
+
+	// Still printing: waits for the output to settle
+	st.UpdateSessionLastOutput("s1", time.Now())
+	begin = time.Now()
+	m.waitForAgentReady("s1", started)
+	if waited := time.Since(begin); waited < queuedMessageQuiet-time.Second/2 {
+		t.Errorf("waited only %v for an agent that was still printing", waited)
+	}
+
+	// Never printed: gives up at the max wait
+	begin = time.Now()
+	m.waitForAgentReady("s2", time.Now().Add(-queuedMessageMaxWait))
+	if waited := time.Since(begin); waited > time.Second {
+		t.Errorf("waited %v past the max wait", waited)
+	}
+}
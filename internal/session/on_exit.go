@@ -0,0 +1,62 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// onExitTimeout bounds a run target's on_exit_command.
+const onExitTimeout = 2 * time.Minute
+
+// watchesExit reports whether sessions of the target are held when their agent exits
+// so the tracker can report the exit: for auto-restart, or to run the target's
+// on_exit_command.
+func (m *Manager) watchesExit(target string) bool {
+	return m.config.IsAutoRestartTarget(target) || m.config.GetOnExitCommand(target) != ""
+}
+
+// runOnExitCommand runs the on_exit_command of the session's target, if any, in the
+// session's workspace. The command sees SCHMUX_SESSION_ID, SCHMUX_SESSION_NICKNAME,
+// SCHMUX_TARGET, SCHMUX_EXIT_CODE (-1 when unknown), SCHMUX_WORKSPACE_ID,
+// SCHMUX_WORKSPACE_PATH, SCHMUX_BRANCH, and SCHMUX_REPO. Failures are only logged.
+func (m *Manager) runOnExitCommand(sess state.Session, exitCode int) {
+	command := m.config.GetOnExitCommand(sess.Target)
+	if command == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), onExitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"SCHMUX_SESSION_ID="+sess.ID,
+		"SCHMUX_SESSION_NICKNAME="+sess.Nickname,
+		"SCHMUX_TARGET="+sess.Target,
+		"SCHMUX_EXIT_CODE="+strconv.Itoa(exitCode),
+		"SCHMUX_WORKSPACE_ID="+sess.WorkspaceID,
+	)
+	if w, found := m.workspace.GetByID(sess.WorkspaceID); found {
+		cmd.Dir = w.Path
+		cmd.Env = append(cmd.Env,
+			"SCHMUX_WORKSPACE_PATH="+w.Path,
+			"SCHMUX_BRANCH="+w.Branch,
+			"SCHMUX_REPO="+w.Repo,
+		)
+	}
+
+	fmt.Printf("[session] running on_exit_command: id=%s exit_code=%d\n", sess.ID, exitCode)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", onExitTimeout)
+		}
+		fmt.Printf("[session] warning: on_exit_command failed for %s: %v: %s\n", sess.ID, err, strings.TrimSpace(string(output)))
+	}
+}
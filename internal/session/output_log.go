@@ -0,0 +1,221 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+// maxOutputSinceBytes caps how much log one GetOutputSince call returns. A client that
+// fell further behind gets the rest on its next call.
+const maxOutputSinceBytes = 1 << 20
+
+// ErrNoOutputLog is returned for sessions that have no output log to read from:
+// remote and blocked sessions, or a manager without a state directory.
+var ErrNoOutputLog = errors.New("session has no output log")
+
+// OutputSince is the session output appended after a marker.
+type OutputSince struct {
+	Output string
+	// Marker is passed back to GetOutputSince to continue after this output.
+	Marker string
+	// Truncated is set when output between the two markers was lost, because the log
+	// was rotated past it or removed.
+	Truncated bool
+}
+
+func (m *Manager) sessionLogPath(sessionID string) string {
+	return filepath.Join(m.logDir, sessionID+".log")
+}
+
+// droppedLogPath is the sidecar file that records how many bytes rotation has dropped
+// from the head of a session's log, so markers stay aligned across daemon restarts.
+func (m *Manager) droppedLogPath(sessionID string) string {
+	return m.sessionLogPath(sessionID) + ".dropped"
+}
+
+// loggedDropped returns the bytes rotated out of a session's log, reading the sidecar
+// the first time. Callers hold logMu.
+func (m *Manager) loggedDropped(sessionID string) int64 {
+	if dropped, ok := m.logDropped[sessionID]; ok {
+		return dropped
+	}
+	var dropped int64
+	if data, err := os.ReadFile(m.droppedLogPath(sessionID)); err == nil {
+		dropped, _ = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	}
+	m.logDropped[sessionID] = dropped
+	return dropped
+}
+
+// startOutputLog pipes a local session's pane output into its log file. Logging is
+// started on demand by GetOutputSince rather than for every session, so panes aren't
+// written to disk unless a client asks for their output. tmux's pipe-pane -o leaves an
+// existing pipe alone, so calling it again is harmless.
+func (m *Manager) startOutputLog(sess state.Session) {
+	if m.logDir == "" || sess.IsRemoteSession() || sess.IsBlocked() {
+		return
+	}
+	if err := os.MkdirAll(m.logDir, 0700); err != nil {
+		fmt.Printf("[session] warning: failed to create log directory: %v\n", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.XtermQueryTimeout())
+	defer cancel()
+	if err := tmux.PipePane(ctx, sess.TmuxTarget(), "cat >> "+shellQuote(m.sessionLogPath(sess.ID))); err != nil {
+		fmt.Printf("[session] warning: failed to start output log for %s: %v\n", sess.ID, err)
+	}
+}
+
+// GetOutputSince returns the session output logged after marker, along with a new
+// marker to pass next time, and starts logging the session if it isn't already.
+// Markers are opaque to callers except that an empty marker starts from the current
+// end of the log and "0" from its beginning. Unless ansi is set, escape sequences are
+// stripped from the output.
+//
+// Markers are absolute offsets into everything the session has logged, so they stay
+// valid when RotateOutputLogs drops the head of the log; the dropped byte count is
+// kept in a sidecar file so they also survive a daemon restart.
+func (m *Manager) GetOutputSince(sessionID, marker string, ansi bool) (OutputSince, error) {
+	sess, found := m.state.GetSession(sessionID)
+	if !found {
+		return OutputSince{}, fmt.Errorf("session not found: %s", sessionID)
+	}
+	if m.logDir == "" || sess.IsRemoteSession() || sess.IsBlocked() {
+		return OutputSince{}, ErrNoOutputLog
+	}
+
+	var from int64 = -1
+	if marker != "" {
+		offset, err := strconv.ParseInt(marker, 10, 64)
+		if err != nil || offset < 0 {
+			return OutputSince{}, fmt.Errorf("invalid marker: %q", marker)
+		}
+		from = offset
+	}
+
+	m.startOutputLog(sess)
+
+	m.logMu.Lock()
+	defer m.logMu.Unlock()
+	dropped := m.loggedDropped(sessionID)
+
+	f, err := os.Open(m.sessionLogPath(sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Nothing logged yet
+			return OutputSince{Marker: strconv.FormatInt(dropped, 10), Truncated: from > dropped}, nil
+		}
+		return OutputSince{}, fmt.Errorf("failed to open output log: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return OutputSince{}, fmt.Errorf("failed to stat output log: %w", err)
+	}
+	end := dropped + info.Size()
+
+	var result OutputSince
+	switch {
+	case from < 0:
+		return OutputSince{Marker: strconv.FormatInt(end, 10)}, nil
+	case from < dropped, from > end:
+		// Rotated away, or ahead of a log that was removed
+		result.Truncated = true
+		from = dropped
+	}
+
+	buf := make([]byte, min(end-from, maxOutputSinceBytes))
+	n, err := f.ReadAt(buf, from-dropped)
+	if err != nil && err != io.EOF {
+		return OutputSince{}, fmt.Errorf("failed to read output log: %w", err)
+	}
+	buf = buf[:n]
+	// When capped, stop after a whole line so the next call doesn't start mid-sequence
+	if from+int64(n) < end {
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			buf = buf[:i+1]
+		}
+	}
+
+	result.Output = string(buf)
+	if !ansi {
+		result.Output = tmux.StripAnsi(result.Output)
+	}
+	result.Marker = strconv.FormatInt(from+int64(len(buf)), 10)
+	return result, nil
+}
+
+// RotateOutputLogs trims session logs that grew past xterm.max_log_size_mb down to
+// their last xterm.rotated_log_size_mb. The log is rewritten in place because tmux's
+// pipe keeps appending to the same file. Returns the number of logs trimmed.
+func (m *Manager) RotateOutputLogs() int {
+	if m.logDir == "" {
+		return 0
+	}
+	maxSize := m.config.GetXtermMaxLogSizeMB() * 1024 * 1024
+	keep := m.config.GetXtermRotatedLogSizeMB() * 1024 * 1024
+
+	rotated := 0
+	for _, sess := range m.state.GetSessions() {
+		path := m.sessionLogPath(sess.ID)
+		info, err := os.Stat(path)
+		if err != nil || info.Size() <= maxSize {
+			continue
+		}
+		if err := m.rotateOutputLog(sess.ID, path, keep); err != nil {
+			fmt.Printf("[session] warning: failed to rotate output log for %s: %v\n", sess.ID, err)
+			continue
+		}
+		rotated++
+	}
+	return rotated
+}
+
+func (m *Manager) rotateOutputLog(sessionID, path string, keep int64) error {
+	m.logMu.Lock()
+	defer m.logMu.Unlock()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	size := info.Size()
+	if size <= keep {
+		f.Close()
+		return nil
+	}
+	tail := make([]byte, keep)
+	n, err := f.ReadAt(tail, size-keep)
+	f.Close()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	tail = tail[:n]
+	// Start the kept tail on a line boundary
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 && i+1 < len(tail) {
+		tail = tail[i+1:]
+	}
+	// Output appended between the read and the rewrite is lost; at most a few
+	// writes' worth.
+	if err := os.WriteFile(path, tail, 0600); err != nil {
+		return err
+	}
+	dropped := m.loggedDropped(sessionID) + size - int64(len(tail))
+	m.logDropped[sessionID] = dropped
+	return os.WriteFile(m.droppedLogPath(sessionID), []byte(strconv.FormatInt(dropped, 10)+"\n"), 0600)
+}
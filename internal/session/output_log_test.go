@@ -0,0 +1,101 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+func TestGetOutputSince(t *testing.T) {
+	cfg := &config.Config{WorkspacePath: "/tmp/workspaces"}
+	st := state.New("")
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+	st.AddSession(state.Session{ID: "s1", TmuxSession: "s1"})
+
+	logPath := m.sessionLogPath("s1")
+	appendLog := func(text string) {
+		t.Helper()
+		f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	since := func(marker string) OutputSince {
+		t.Helper()
+		got, err := m.GetOutputSince("s1", marker, false)
+		if err != nil {
+			t.Fatalf("GetOutputSince(%q): %v", marker, err)
+		}
+		return got
+	}
+
+	// Before anything is logged, an empty marker starts at the beginning
+	start := since("")
+	if start.Output != "" || start.Marker != "0" {
+		t.Fatalf("empty log: %+v", start)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(logPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	appendLog("one\n\x1b[32mtwo\x1b[0m\n")
+	got := since(start.Marker)
+	if got.Output != "one\ntwo\n" || got.Truncated {
+		t.Errorf("first poll: %+v", got)
+	}
+	if raw, _ := m.GetOutputSince("s1", start.Marker, true); raw.Output != "one\n\x1b[32mtwo\x1b[0m\n" {
+		t.Errorf("ansi poll: %q", raw.Output)
+	}
+
+	appendLog("three\n")
+	next := since(got.Marker)
+	if next.Output != "three\n" {
+		t.Errorf("second poll: %+v", next)
+	}
+	if idle := since(next.Marker); idle.Output != "" || idle.Marker != next.Marker {
+		t.Errorf("idle poll: %+v", idle)
+	}
+
+	// Rotation drops the head of the log but keeps markers valid
+	appendLog("four\nfive\n")
+	if err := m.rotateOutputLog("s1", logPath, 6); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if got := since(next.Marker); got.Output != "five\n" || !got.Truncated {
+		t.Errorf("poll across rotation: %+v", got)
+	}
+	appendLog("six\n")
+	after := since(next.Marker)
+	if final := since(after.Marker); final.Output != "" || final.Truncated {
+		t.Errorf("poll at end after rotation: %+v", final)
+	}
+	if got := since("0"); got.Output != "five\nsix\n" || !got.Truncated {
+		t.Errorf("poll from 0 after rotation: %+v", got)
+	}
+
+	// The dropped count survives a daemon restart, so earlier markers still line up
+	restarted := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+	appendLog("seven\n")
+	if got, err := restarted.GetOutputSince("s1", after.Marker, false); err != nil || got.Output != "seven\n" || got.Truncated {
+		t.Errorf("poll after restart: %+v, %v", got, err)
+	}
+	if got, _ := restarted.GetOutputSince("s1", next.Marker, false); !got.Truncated {
+		t.Errorf("rotated-away marker after restart: %+v", got)
+	}
+
+	if _, err := m.GetOutputSince("s1", "bogus", false); err == nil {
+		t.Error("expected an error for an invalid marker")
+	}
+	if _, err := m.GetOutputSince("missing", "", false); err == nil {
+		t.Error("expected an error for an unknown session")
+	}
+}
@@ -0,0 +1,49 @@
+package session
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+// sessionPanes converts a run target's extra panes into the form kept on its sessions.
+func sessionPanes(panes []config.TargetPane) []state.SessionPane {
+	if len(panes) == 0 {
+		return nil
+	}
+	result := make([]state.SessionPane, len(panes))
+	for i, pane := range panes {
+		result[i] = state.SessionPane{
+			Command:    pane.Command,
+			Horizontal: pane.Split == config.PaneSplitHorizontal,
+			Size:       pane.Size,
+		}
+	}
+	return result
+}
+
+// createPanes records the agent's pane and splits the session's extra panes off it,
+// then applies the session's layout. The agent's pane stays active. Failures are
+// logged: the agent is already running, and a missing log pane shouldn't fail the spawn.
+// Pane commands run inside the same sandbox and resource limits as the agent.
+func (m *Manager) createPanes(ctx context.Context, dir string, sess *state.Session) {
+	agentPane, err := tmux.GetPaneID(ctx, sess.TmuxSession)
+	if err != nil {
+		fmt.Printf("[session] warning: not creating extra panes for %s: %v\n", sess.ID, err)
+		return
+	}
+	sess.AgentPane = agentPane
+	for _, pane := range sess.Panes {
+		if err := tmux.SplitWindow(ctx, agentPane, dir, m.wrapSessionCommand(pane.Command, dir), pane.Horizontal, pane.Size); err != nil {
+			fmt.Printf("[session] warning: failed to create pane %q for %s: %v\n", pane.Command, sess.ID, err)
+		}
+	}
+	if sess.PaneLayout != "" {
+		if err := tmux.SelectLayout(ctx, agentPane, sess.PaneLayout); err != nil {
+			fmt.Printf("[session] warning: failed to apply layout %s for %s: %v\n", sess.PaneLayout, sess.ID, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+func TestSpawnCreatesPanes(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+	tmux.SetSocketName(fmt.Sprintf("schmux-test-%d", os.Getpid()))
+	t.Cleanup(func() {
+		tmux.Command(context.Background(), "kill-server").Run()
+		tmux.SetSocketName("")
+	})
+
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		Sessions:      &config.SessionsConfig{SandboxWrapper: "env SCHMUX_SANDBOXED=1"},
+		RunTargets: []config.RunTarget{{
+			Name:    "editor",
+			Type:    config.RunTargetTypeCommand,
+			Command: "sleep 60",
+			Panes:   []config.TargetPane{{Command: "sleep 61", Split: config.PaneSplitHorizontal}, {Command: "sleep 62"}},
+			Layout:  "main-vertical",
+		}},
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	if err := st.AddWorkspace(state.Workspace{ID: "ws-001", Repo: "git@example.com:me/repo.git", Branch: "main", Path: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	sess, err := m.Spawn(context.Background(), "", "", "editor", "", "", "ws-001", false)
+	if err != nil {
+		t.Fatalf("Spawn() error: %v", err)
+	}
+	t.Cleanup(func() { m.stopTracker(sess.ID) })
+
+	ctx := context.Background()
+	output, err := tmux.Command(ctx, "list-panes", "-t", sess.TmuxSession, "-F", "#{pane_id} #{pane_active} #{pane_start_command}").Output()
+	if err != nil {
+		t.Fatalf("list-panes: %v", err)
+	}
+	panes := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(panes) != 3 {
+		t.Fatalf("got %d panes, want 3:\n%s", len(panes), output)
+	}
+	stored, _ := st.GetSession(sess.ID)
+	if stored.AgentPane == "" || len(stored.Panes) != 2 || stored.PaneLayout != "main-vertical" {
+		t.Fatalf("stored session = %+v", stored)
+	}
+	otherPane := ""
+	for _, line := range panes {
+		fields := strings.Fields(line)
+		// Extra panes run inside the sandbox like the agent
+		if !strings.Contains(line, "env SCHMUX_SANDBOXED=1 sh -c") {
+			t.Errorf("pane %s is not sandboxed: %s", fields[0], line)
+		}
+		if fields[0] != stored.AgentPane {
+			otherPane = fields[0]
+		} else if fields[1] != "1" {
+			t.Errorf("agent pane %s is not active: %s", stored.AgentPane, line)
+		}
+	}
+
+	// Health checks address the agent even when another pane is active
+	if err := tmux.Command(ctx, "select-pane", "-t", otherPane).Run(); err != nil {
+		t.Fatal(err)
+	}
+	pid, err := tmux.GetPanePID(ctx, stored.TmuxTarget())
+	if err != nil || pid != stored.Pid {
+		t.Errorf("GetPanePID(agent) = %d, %v; want %d", pid, err, stored.Pid)
+	}
+}
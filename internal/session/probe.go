@@ -0,0 +1,137 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sergeknystautas/schmux/internal/detect"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+const (
+	// targetProbePrompt is the prompt given to promptable targets by ProbeTarget.
+	targetProbePrompt = "Reply with OK."
+	// targetProbeMarker is printed after the target's command exits, with its status.
+	targetProbeMarker = "__schmux_probe_exit__"
+	// targetProbeMaxOutput caps the output snippet returned by ProbeTarget.
+	targetProbeMaxOutput = 4096
+)
+
+var targetProbeExitPattern = regexp.MustCompile(targetProbeMarker + ` (\d+)`)
+
+// TargetProbeResult reports how a target behaved when launched by ProbeTarget.
+type TargetProbeResult struct {
+	Target     string `json:"target"`
+	Success    bool   `json:"success"`
+	Running    bool   `json:"running"`             // still running when the probe ended (interactive agents usually are)
+	ExitCode   *int   `json:"exit_code,omitempty"` // set when the command exited during the probe
+	Output     string `json:"output"`              // first output of the command, ANSI stripped
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// ProbeTarget launches a target in a throwaway tmux session in a temp directory to
+// check that it starts: promptable targets get a trivial prompt, command targets run
+// as configured. It waits up to wait for the command to exit, captures its output,
+// then kills the session. The target succeeds if it exits 0 or is still running.
+// Targets that can't be resolved (e.g. missing secrets) are reported as failures;
+// an unknown target returns ErrTargetNotFound.
+func (m *Manager) ProbeTarget(ctx context.Context, targetName string, wait time.Duration) (*TargetProbeResult, error) {
+	result := &TargetProbeResult{Target: targetName}
+	resolved, err := m.ResolveTarget(ctx, targetName)
+	if errors.Is(err, ErrTargetNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		err = checkTargetShell(resolved)
+	}
+	var command string
+	if err == nil {
+		var model *detect.Model
+		if resolved.Kind == TargetKindModel {
+			if found, ok := detect.FindModel(resolved.Name); ok {
+				model = &found
+			}
+		}
+		prompt := ""
+		if resolved.Promptable {
+			prompt = targetProbePrompt
+		}
+		command, err = buildCommand(resolved, prompt, "", model, false)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	if resolved.Shell != "" {
+		command = wrapInShell(resolved.Shell, command)
+	}
+
+	dir, err := os.MkdirTemp("", "schmux-probe-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create probe directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	// The probe runs the target the way a session would, sandbox and limits included
+	command = m.wrapSessionCommand(command, dir)
+
+	// Keep the pane alive after the command exits so its output and status can be read.
+	script := fmt.Sprintf("%s; printf '\\n%s %%d\\n' \"$?\"; sleep 600", command, targetProbeMarker)
+	tmuxSession := "schmux-probe-" + uuid.New().String()[:8]
+	start := time.Now()
+	if err := tmux.CreateSession(ctx, tmuxSession, dir, "sh -c "+shellQuote(script)); err != nil {
+		return nil, fmt.Errorf("failed to create tmux session: %w", err)
+	}
+	defer tmux.KillSession(context.Background(), tmuxSession)
+
+	var output string
+	exited := false
+	deadline := start.Add(wait)
+	for {
+		if captured, err := tmux.CaptureOutput(ctx, tmuxSession); err == nil {
+			output = captured
+		}
+		var code int
+		if output, code, exited = parseProbeOutput(output); exited {
+			result.ExitCode = &code
+			break
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			break
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	result.Output = output
+	result.Running = !exited
+	result.Success = result.Running || *result.ExitCode == 0
+	if !result.Success {
+		result.Error = fmt.Sprintf("command exited with status %d", *result.ExitCode)
+	}
+	fmt.Printf("[session] probed target %s: success=%v running=%v duration=%dms\n", targetName, result.Success, result.Running, result.DurationMs)
+	return result, nil
+}
+
+// parseProbeOutput strips ANSI sequences from captured probe output and, if the exit
+// marker is present, removes it and returns the command's exit status.
+func parseProbeOutput(captured string) (output string, exitCode int, exited bool) {
+	output = tmux.StripAnsi(captured)
+	if loc := targetProbeExitPattern.FindStringSubmatchIndex(output); loc != nil {
+		exitCode, _ = strconv.Atoi(output[loc[2]:loc[3]])
+		output = output[:loc[0]]
+		exited = true
+	}
+	output = strings.TrimSpace(output)
+	if len(output) > targetProbeMaxOutput {
+		output = output[:targetProbeMaxOutput]
+	}
+	return output, exitCode, exited
+}
@@ -0,0 +1,44 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+	"github.com/sergeknystautas/schmux/internal/tmux"
+	"github.com/sergeknystautas/schmux/internal/workspace"
+)
+
+func TestProbeTargetUsesSandbox(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not available")
+	}
+	tmux.SetSocketName(fmt.Sprintf("schmux-probe-test-%d", os.Getpid()))
+	t.Cleanup(func() {
+		tmux.Command(context.Background(), "kill-server").Run()
+		tmux.SetSocketName("")
+	})
+
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		Sessions:      &config.SessionsConfig{SandboxWrapper: "env SCHMUX_SANDBOXED=1"},
+		RunTargets:    []config.RunTarget{{Name: "check", Type: config.RunTargetTypeCommand, Command: "printenv SCHMUX_SANDBOXED"}},
+	}
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	m := New(cfg, st, statePath, workspace.New(cfg, st, statePath))
+
+	result, err := m.ProbeTarget(context.Background(), "check", 5*time.Second)
+	if err != nil {
+		t.Fatalf("ProbeTarget() error: %v", err)
+	}
+	if !result.Success || result.Output != "1" {
+		t.Errorf("ProbeTarget() = %+v, want the command to run inside the sandbox wrapper", result)
+	}
+}
@@ -0,0 +1,28 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// recordWorkspacePrompt adds a spawn prompt to its workspace's prompt history when
+// sessions.prompt_history_size is set. The caller saves state. Failures are logged,
+// never returned: the history must not fail a spawn.
+func (m *Manager) recordWorkspacePrompt(sess state.Session, prompt string) {
+	limit := m.config.GetPromptHistorySize()
+	if limit <= 0 || prompt == "" {
+		return
+	}
+	entry := state.PromptHistoryEntry{
+		ID:        uuid.New().String()[:8],
+		Prompt:    prompt,
+		Target:    sess.Target,
+		Nickname:  sess.Nickname,
+		SpawnedAt: sess.CreatedAt,
+	}
+	if err := m.state.AddWorkspacePrompt(sess.WorkspaceID, entry, limit); err != nil {
+		fmt.Printf("[session] warning: failed to record prompt history for %s: %v\n", sess.WorkspaceID, err)
+	}
+}
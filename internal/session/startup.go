@@ -0,0 +1,89 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/tmux"
+)
+
+const (
+	// startupCheckDelay is how long a new session's process must stay up before the
+	// spawn counts as started.
+	startupCheckDelay = 500 * time.Millisecond
+
+	// startupOutputLines is how much of a dead pane's output a startup error carries.
+	startupOutputLines = 20
+)
+
+// ErrExitedOnStartup is returned by spawns whose process exited during startupCheckDelay.
+var ErrExitedOnStartup = errors.New("exited immediately after starting")
+
+// checkStartup waits startupCheckDelay, then checks the process in a session created
+// with tmux.CreateHeldSession. If the process already exited, the tmux session is
+// killed and the error carries the exit code and last output. An exit with code 0 is
+// only an error when allowCleanExit is false; quick shell commands may legitimately
+// finish that fast. A process that is still running has its session released so tmux
+// closes it normally when the process exits later, unless keepHeld is set: then its
+// pane stays behind on exit so its exit handler can read the exit code.
+func checkStartup(ctx context.Context, tmuxSession string, allowCleanExit, keepHeld bool) error {
+	select {
+	case <-time.After(startupCheckDelay):
+	case <-ctx.Done():
+		tmux.KillSession(context.Background(), tmuxSession)
+		return ctx.Err()
+	}
+
+	dead, code, err := tmux.GetPaneExitStatus(ctx, tmuxSession)
+	if err == nil && !dead && !keepHeld {
+		err = tmux.SetRemainOnExit(ctx, tmuxSession, false)
+		if err == nil {
+			// The process may have exited just before the pane was released.
+			dead, code, err = tmux.GetPaneExitStatus(ctx, tmuxSession)
+		}
+	}
+	if err != nil {
+		fmt.Printf("[session] warning: failed to check startup of %s: %v\n", tmuxSession, err)
+		return nil
+	}
+	if !dead {
+		return nil
+	}
+
+	output, captureErr := tmux.CaptureLastLines(ctx, tmuxSession, startupOutputLines, false)
+	if captureErr != nil {
+		fmt.Printf("[session] warning: failed to capture output of %s: %v\n", tmuxSession, captureErr)
+	}
+	if err := tmux.KillSession(ctx, tmuxSession); err != nil {
+		fmt.Printf("[session] warning: failed to kill exited session %s: %v\n", tmuxSession, err)
+	}
+	if code == 0 && allowCleanExit {
+		return nil
+	}
+	// tmux doesn't always know the status yet, so 0 may just mean unknown.
+	err = ErrExitedOnStartup
+	if code != 0 {
+		err = fmt.Errorf("%w (exit code %d)", err, code)
+	}
+	if output = startupOutput(output); output != "" {
+		err = fmt.Errorf("%w: %s", err, output)
+	}
+	return err
+}
+
+// startupOutput trims a dead pane's captured output, dropping blank lines and the
+// "Pane is dead" notice tmux shows in panes kept with remain-on-exit.
+func startupOutput(captured string) string {
+	var lines []string
+	for _, line := range strings.Split(captured, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "Pane is dead") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,14 @@
+package session
+
+import "testing"
+
+func TestStartupOutput(t *testing.T) {
+	captured := "error: unknown option '--bogus'\nusage: agent [options]   \n\n\n\nPane is dead (status 2, Fri Oct 16 13:19:13 2026)\n"
+	want := "error: unknown option '--bogus'\nusage: agent [options]"
+	if got := startupOutput(captured); got != want {
+		t.Errorf("startupOutput() = %q, want %q", got, want)
+	}
+	if got := startupOutput("\n\nPane is dead\n"); got != "" {
+		t.Errorf("startupOutput() of an empty pane = %q, want empty", got)
+	}
+}
@@ -19,6 +19,7 @@ import (
 const trackerRestartDelay = 500 * time.Millisecond
 const trackerActivityDebounce = 500 * time.Millisecond
 const trackerRetryLogInterval = 15 * time.Second
+const trackerExitPollInterval = 2 * time.Second
 
 var trackerIgnorePrefixes = [][]byte{
 	[]byte("\x1b[?"),
@@ -28,14 +29,16 @@ var trackerIgnorePrefixes = [][]byte{
 }
 
 // SessionTracker maintains a long-lived PTY attachment for a tmux session.
-// It tracks output activity and forwards terminal output to one active websocket client.
+// It tracks output activity and fans terminal output out to every attached websocket
+// client, so observers can watch a session without displacing whoever is typing in it.
 type SessionTracker struct {
 	sessionID   string
 	tmuxSession string
+	agentPane   string // the agent's pane in a multi-pane session; health checks target it
 	state       state.StateStore
 
 	mu        sync.RWMutex
-	clientCh  chan []byte
+	clients   map[chan []byte]struct{}
 	ptmx      *os.File
 	attachCmd *exec.Cmd
 	lastEvent time.Time
@@ -45,6 +48,9 @@ type SessionTracker struct {
 	doneCh   chan struct{}
 
 	lastRetryLog time.Time
+
+	// onExit, when set, is called each time the session's held pane dies
+	onExit func()
 }
 
 // IsAttached reports whether the tracker currently has an active PTY attachment.
@@ -60,14 +66,56 @@ func NewSessionTracker(sessionID, tmuxSession string, st state.StateStore) *Sess
 		sessionID:   sessionID,
 		tmuxSession: tmuxSession,
 		state:       st,
+		clients:     make(map[chan []byte]struct{}),
 		stopCh:      make(chan struct{}),
 		doneCh:      make(chan struct{}),
 	}
 }
 
+// SetExitHandler makes the tracker watch the session's pane, which must be held with
+// remain-on-exit, and call fn each time its process exits. Set it before Start.
+func (t *SessionTracker) SetExitHandler(fn func()) {
+	t.onExit = fn
+}
+
 // Start launches the tracker loop in a background goroutine.
 func (t *SessionTracker) Start() {
 	go t.run()
+	if t.onExit != nil {
+		go t.watchExit()
+	}
+}
+
+// watchExit polls the held pane and calls onExit once per process that exits. A pane
+// that stays dead (waiting for a restart) isn't reported again; one that was respawned
+// has a new pane PID by the time it dies again, even if no poll saw it running.
+func (t *SessionTracker) watchExit() {
+	reportedPid := 0
+	for {
+		if t.waitOrStop(trackerExitPollInterval) {
+			return
+		}
+		t.mu.RLock()
+		target := t.tmuxSession
+		if t.agentPane != "" {
+			target = t.agentPane
+		}
+		t.mu.RUnlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), trackerExitPollInterval)
+		dead, _, err := tmux.GetPaneExitStatus(ctx, target)
+		pid := 0
+		if err == nil && dead {
+			pid, err = tmux.GetPanePID(ctx, target)
+		}
+		cancel()
+		if err != nil || !dead || pid == reportedPid {
+			// Still running, already reported, or the session is gone.
+			continue
+		}
+		reportedPid = pid
+		t.onExit()
+	}
 }
 
 // Stop terminates the tracker and closes the active websocket output channel.
@@ -86,26 +134,32 @@ func (t *SessionTracker) SetTmuxSession(name string) {
 	t.tmuxSession = name
 }
 
-// AttachWebSocket registers the active websocket stream and returns its output channel.
-// If a client is already attached, it is replaced and its channel is closed.
+// SetAgentPane sets the pane the agent runs in, for sessions with extra panes.
+// Empty means the session's active pane.
+func (t *SessionTracker) SetAgentPane(paneID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.agentPane = paneID
+}
+
+// AttachWebSocket registers a websocket stream and returns its output channel. Clients
+// already attached keep receiving output.
 func (t *SessionTracker) AttachWebSocket() chan []byte {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	if t.clientCh != nil {
-		close(t.clientCh)
-	}
-	t.clientCh = make(chan []byte, 64)
-	return t.clientCh
+	ch := make(chan []byte, 64)
+	t.clients[ch] = struct{}{}
+	return ch
 }
 
-// DetachWebSocket clears the websocket stream if it matches the currently registered one.
+// DetachWebSocket unregisters a stream returned by AttachWebSocket and closes its channel.
 func (t *SessionTracker) DetachWebSocket(ch chan []byte) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	if t.clientCh == ch {
-		close(t.clientCh)
-		t.clientCh = nil
+	if _, ok := t.clients[ch]; ok {
+		delete(t.clients, ch)
+		close(ch)
 	}
 }
 
@@ -190,7 +244,7 @@ func (t *SessionTracker) attachAndRead() error {
 		width, height = 80, 24
 	}
 
-	attachCmd := exec.CommandContext(ctx, "tmux", "attach-session", "-t", "="+target)
+	attachCmd := tmux.Command(ctx, "attach-session", "-t", "="+target)
 	ptmx, err := pty.StartWithSize(attachCmd, &pty.Winsize{Cols: uint16(width), Rows: uint16(height)})
 	if err != nil {
 		return err
@@ -217,18 +271,12 @@ func (t *SessionTracker) attachAndRead() error {
 			if shouldUpdate {
 				t.lastEvent = now
 			}
-			clientCh := t.clientCh
 			t.mu.Unlock()
 
 			if shouldUpdate {
 				t.state.UpdateSessionLastOutput(t.sessionID, now)
 			}
-			if clientCh != nil {
-				select {
-				case clientCh <- chunk:
-				default:
-				}
-			}
+			t.broadcast(chunk)
 		}
 
 		if err != nil {
@@ -243,6 +291,20 @@ func (t *SessionTracker) attachAndRead() error {
 	}
 }
 
+// broadcast sends a chunk of output to every attached client. A client that has fallen
+// behind misses the chunk rather than stalling the others.
+func (t *SessionTracker) broadcast(chunk []byte) {
+	// Held while sending so DetachWebSocket can't close a channel mid-send
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for ch := range t.clients {
+		select {
+		case ch <- chunk:
+		default:
+		}
+	}
+}
+
 func (t *SessionTracker) shouldLogRetry(now time.Time) bool {
 	t.mu.Lock()
 	defer t.mu.Unlock()
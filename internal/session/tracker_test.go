@@ -11,37 +11,45 @@ func TestSessionTrackerAttachDetach(t *testing.T) {
 	st := state.New("")
 	tracker := NewSessionTracker("s1", "tmux-s1", st)
 
-	ch1 := tracker.AttachWebSocket()
-	if ch1 == nil {
-		t.Fatal("expected first channel")
+	// A driver and a read-only observer attached at the same time both get output
+	driver := tracker.AttachWebSocket()
+	observer := tracker.AttachWebSocket()
+	if driver == observer {
+		t.Fatal("expected a channel per client")
 	}
-
-	ch2 := tracker.AttachWebSocket()
-	if ch2 == nil {
-		t.Fatal("expected second channel")
-	}
-	if ch1 == ch2 {
-		t.Fatal("expected replacement channel")
+	tracker.broadcast([]byte("hello"))
+	for name, ch := range map[string]chan []byte{"driver": driver, "observer": observer} {
+		select {
+		case chunk, ok := <-ch:
+			if !ok || string(chunk) != "hello" {
+				t.Errorf("%s got %q (open=%v), want hello", name, chunk, ok)
+			}
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("%s received no output", name)
+		}
 	}
 
+	// Detaching one client closes only its channel
+	tracker.DetachWebSocket(observer)
 	select {
-	case _, ok := <-ch1:
+	case _, ok := <-observer:
 		if ok {
-			t.Fatal("expected replaced channel to be closed")
+			t.Fatal("expected detached channel to be closed")
 		}
 	case <-time.After(100 * time.Millisecond):
-		t.Fatal("expected replaced channel close signal")
+		t.Fatal("expected detached channel close signal")
 	}
-
-	tracker.DetachWebSocket(ch2)
+	tracker.broadcast([]byte("still here"))
 	select {
-	case _, ok := <-ch2:
-		if ok {
-			t.Fatal("expected detached channel to be closed")
+	case chunk, ok := <-driver:
+		if !ok || string(chunk) != "still here" {
+			t.Errorf("driver got %q (open=%v) after the observer left", chunk, ok)
 		}
 	case <-time.After(100 * time.Millisecond):
-		t.Fatal("expected detached channel close signal")
+		t.Error("driver stopped receiving output after the observer left")
 	}
+	tracker.DetachWebSocket(observer) // detaching twice is harmless
+	tracker.DetachWebSocket(driver)
 }
 
 func TestSessionTrackerInputResizeWithoutPTY(t *testing.T) {
@@ -22,12 +22,14 @@ type StateStore interface {
 	GetWorkspace(id string) (Workspace, bool)
 	AddWorkspace(ws Workspace) error
 	UpdateWorkspace(ws Workspace) error
+	AddWorkspacePrompt(workspaceID string, entry PromptHistoryEntry, limit int) error
 	RemoveWorkspace(id string) error
 
 	// Worktree base operations (for git worktrees)
 	GetWorktreeBases() []WorktreeBase
 	GetWorktreeBaseByURL(repoURL string) (WorktreeBase, bool)
 	AddWorktreeBase(wb WorktreeBase) error
+	RemoveWorktreeBase(repoURL string) error
 
 	// Remote host operations
 	GetRemoteHosts() []RemoteHost
@@ -52,21 +52,51 @@ const (
 	RemoteHostStatusReconnecting = "reconnecting"
 )
 
+// SessionStatusBlocked marks a local session whose target was unavailable at spawn time
+// (sessions.unavailable_target_policy = "queue"). It has no tmux session until restarted.
+const SessionStatusBlocked = "blocked"
+
+// Remote session statuses. A remote session is "provisioning" while it waits for its
+// host connection, then "running" once its window exists, or "failed" if creation failed.
+const (
+	SessionStatusProvisioning = "provisioning"
+	SessionStatusRunning      = "running"
+	SessionStatusFailed       = "failed"
+)
+
+// SessionStatusStopped is reported (never stored) for a local session whose process has exited.
+const SessionStatusStopped = "stopped"
+
 // Workspace represents a workspace directory state.
 // Multiple sessions can share the same workspace (multi-agent per directory).
 type Workspace struct {
-	ID              string `json:"id"`
-	Repo            string `json:"repo"`
-	Branch          string `json:"branch"`
-	Path            string `json:"path"`
-	GitDirty        bool   `json:"-"`
-	GitAhead        int    `json:"-"`
-	GitBehind       int    `json:"-"`
-	GitLinesAdded   int    `json:"-"`
-	GitLinesRemoved int    `json:"-"`
-	GitFilesChanged int    `json:"-"`
-	RemoteHostID    string `json:"remote_host_id,omitempty"` // Empty for local workspaces
-	RemotePath      string `json:"remote_path,omitempty"`    // Path on remote host
+	ID               string `json:"id"`
+	Repo             string `json:"repo"`
+	Branch           string `json:"branch"`
+	Path             string `json:"path"`
+	GitDirty         bool   `json:"-"`
+	GitAhead         int    `json:"-"`
+	GitBehind        int    `json:"-"`
+	GitLinesAdded    int    `json:"-"`
+	GitLinesRemoved  int    `json:"-"`
+	GitFilesChanged  int    `json:"-"`
+	GitBranchGone    bool   `json:"-"`                            // Branch was seen on the remote (RemoteBranchHead) and has since been deleted
+	RemoteHostID     string `json:"remote_host_id,omitempty"`     // Empty for local workspaces
+	RemotePath       string `json:"remote_path,omitempty"`        // Path on remote host
+	Pinned           bool   `json:"pinned,omitempty"`             // Sorted to the top of the dashboard
+	Imported         bool   `json:"imported,omitempty"`           // An existing checkout adopted in place; schmux never moves or deletes it
+	RemoteBranchHead string `json:"remote_branch_head,omitempty"` // Last commit seen at origin/<Branch>; tells a deleted branch from a never-pushed one
+	// PromptHistory holds recent spawn prompts, oldest first; only kept with sessions.prompt_history_size
+	PromptHistory []PromptHistoryEntry `json:"prompt_history,omitempty"`
+}
+
+// PromptHistoryEntry is a spawn prompt kept in a workspace's prompt history.
+type PromptHistoryEntry struct {
+	ID        string    `json:"id"`
+	Prompt    string    `json:"prompt"`
+	Target    string    `json:"target"`
+	Nickname  string    `json:"nickname,omitempty"`
+	SpawnedAt time.Time `json:"spawned_at"`
 }
 
 // WorktreeBase tracks a bare clone that hosts worktrees.
@@ -77,20 +107,44 @@ type WorktreeBase struct {
 
 // Session represents a run target session.
 type Session struct {
-	ID           string    `json:"id"`
-	WorkspaceID  string    `json:"workspace_id"`
-	Target       string    `json:"target"`
-	Nickname     string    `json:"nickname,omitempty"` // Optional human-friendly name
-	TmuxSession  string    `json:"tmux_session"`
-	CreatedAt    time.Time `json:"created_at"`
-	Pid          int       `json:"pid"`                      // PID of the target process from tmux pane
-	LastOutputAt time.Time `json:"-"`                        // Last time terminal had new output (in-memory only, not persisted)
-	LastSignalAt time.Time `json:"-"`                        // Last time agent sent a direct signal (in-memory only)
-	Nudge        string    `json:"nudge,omitempty"`          // NudgeNik consultation result
-	RemoteHostID string    `json:"remote_host_id,omitempty"` // Empty for local sessions
-	RemotePaneID string    `json:"remote_pane_id,omitempty"` // tmux pane ID on remote (e.g., "%5")
-	RemoteWindow string    `json:"remote_window,omitempty"`  // tmux window ID on remote (e.g., "@3")
-	Status       string    `json:"status,omitempty"`         // Status for remote sessions: "provisioning", "running", "failed"
+	ID            string    `json:"id"`
+	WorkspaceID   string    `json:"workspace_id"`
+	Target        string    `json:"target"`
+	Nickname      string    `json:"nickname,omitempty"` // Optional human-friendly name
+	TmuxSession   string    `json:"tmux_session"`
+	CreatedAt     time.Time `json:"created_at"`
+	Pid           int       `json:"pid"`                      // PID of the target process from tmux pane
+	LastOutputAt  time.Time `json:"-"`                        // Last time terminal had new output (in-memory only, not persisted)
+	LastSignalAt  time.Time `json:"-"`                        // Last time agent sent a direct signal (in-memory only)
+	Nudge         string    `json:"nudge,omitempty"`          // NudgeNik consultation result
+	RemoteHostID  string    `json:"remote_host_id,omitempty"` // Empty for local sessions
+	RemotePaneID  string    `json:"remote_pane_id,omitempty"` // tmux pane ID on remote (e.g., "%5")
+	RemoteWindow  string    `json:"remote_window,omitempty"`  // tmux window ID on remote (e.g., "@3")
+	Status        string    `json:"status,omitempty"`         // Status for remote sessions: "provisioning", "running", "failed"; "blocked" for queued local sessions
+	BlockedReason string    `json:"blocked_reason,omitempty"` // Why a blocked session's target is unavailable
+	FailReason    string    `json:"fail_reason,omitempty"`    // Why a failed remote session couldn't be created
+	FailedAt      time.Time `json:"failed_at,omitempty"`      // When a remote session failed; it is disposed after sessions.failed_remote_grace_ms
+	PendingPrompt string    `json:"pending_prompt,omitempty"` // Prompt to start a blocked session with (cleared once started)
+	PendingResume bool      `json:"pending_resume,omitempty"` // Start a blocked session in resume mode
+	Prompt        string    `json:"prompt,omitempty"`         // Spawn prompt, kept only for sessions.history_record_prompts
+	// QueuedMessages are follow-up messages sent before the session started; delivered once it runs
+	QueuedMessages []string `json:"queued_messages,omitempty"`
+	// Auto-restart bookkeeping for sessions of sessions.auto_restart_targets
+	RestartCount  int       `json:"restart_count,omitempty"`   // auto-restarts since the count last reset
+	LastRestartAt time.Time `json:"last_restart_at,omitempty"` // when the session was last auto-restarted
+	LastExitCode  int       `json:"last_exit_code,omitempty"`  // exit code of the agent's last non-zero exit
+	// Multi-pane sessions: the extra panes split off the agent's pane, kept so the
+	// layout can be recreated when the session starts again, and the agent's own pane
+	Panes      []SessionPane `json:"panes,omitempty"`
+	PaneLayout string        `json:"pane_layout,omitempty"` // tmux preset layout applied after the splits
+	AgentPane  string        `json:"agent_pane,omitempty"`  // tmux pane ID of the agent (e.g. "%3")
+}
+
+// SessionPane is an extra pane of a multi-pane session.
+type SessionPane struct {
+	Command    string `json:"command"`
+	Horizontal bool   `json:"horizontal,omitempty"` // beside the agent instead of below
+	Size       int    `json:"size,omitempty"`       // percent of the split; 0 splits evenly
 }
 
 // New creates a new empty State instance.
@@ -255,6 +309,34 @@ func (s *State) UpdateWorkspace(w Workspace) error {
 	return fmt.Errorf("workspace not found: %s", w.ID)
 }
 
+// AddWorkspacePrompt appends entry to a workspace's prompt history, keeping the newest
+// limit entries. An older entry with the same prompt is dropped, so spawning a prompt
+// again moves it to the end rather than filling the history with copies.
+// Returns an error if the workspace is not found.
+func (s *State) AddWorkspacePrompt(workspaceID string, entry PromptHistoryEntry, limit int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.Workspaces {
+		if existing.ID != workspaceID {
+			continue
+		}
+		// Build a new slice: copies handed out by GetWorkspaces share the old one
+		history := make([]PromptHistoryEntry, 0, len(existing.PromptHistory)+1)
+		for _, e := range existing.PromptHistory {
+			if e.Prompt != entry.Prompt {
+				history = append(history, e)
+			}
+		}
+		history = append(history, entry)
+		if len(history) > limit {
+			history = history[len(history)-limit:]
+		}
+		s.Workspaces[i].PromptHistory = history
+		return nil
+	}
+	return fmt.Errorf("workspace not found: %s", workspaceID)
+}
+
 // AddSession adds a session to the state.
 func (s *State) AddSession(sess Session) error {
 	s.mu.Lock()
@@ -391,6 +473,19 @@ func (s *State) GetWorktreeBaseByURL(repoURL string) (WorktreeBase, bool) {
 	return WorktreeBase{}, false
 }
 
+// RemoveWorktreeBase removes the worktree base for a repo URL, if there is one.
+func (s *State) RemoveWorktreeBase(repoURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, wb := range s.WorktreeBases {
+		if wb.RepoURL == repoURL {
+			s.WorktreeBases = append(s.WorktreeBases[:i], s.WorktreeBases[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 // SetNeedsRestart sets the needs_restart flag.
 func (s *State) SetNeedsRestart(needsRestart bool) error {
 	s.mu.Lock()
@@ -559,6 +654,21 @@ func (sess *Session) IsRemoteSession() bool {
 	return sess.RemoteHostID != ""
 }
 
+// TmuxTarget returns the tmux target addressing the session's agent: its pane in a
+// multi-pane session, where the session name would resolve to whichever pane is
+// active, or the session name otherwise.
+func (sess *Session) TmuxTarget() string {
+	if sess.AgentPane != "" {
+		return sess.AgentPane
+	}
+	return sess.TmuxSession
+}
+
+// IsBlocked returns true if the session is waiting for its target to become available.
+func (sess *Session) IsBlocked() bool {
+	return sess.Status == SessionStatusBlocked
+}
+
 // IsRemoteWorkspace returns true if the workspace is on a remote host.
 func (ws *Workspace) IsRemoteWorkspace() bool {
 	return ws.RemoteHostID != ""
@@ -142,6 +142,30 @@ func TestGetSessions(t *testing.T) {
 	}
 }
 
+func TestAddWorkspacePrompt(t *testing.T) {
+	s := New("")
+	s.AddWorkspace(Workspace{ID: "ws-001", Repo: "https://github.com/test/repo", Branch: "main", Path: "/tmp/test"})
+
+	for _, prompt := range []string{"one", "two", "one", "three"} {
+		if err := s.AddWorkspacePrompt("ws-001", PromptHistoryEntry{ID: prompt, Prompt: prompt}, 2); err != nil {
+			t.Fatalf("AddWorkspacePrompt(%q): %v", prompt, err)
+		}
+	}
+	w, _ := s.GetWorkspace("ws-001")
+	var got []string
+	for _, e := range w.PromptHistory {
+		got = append(got, e.Prompt)
+	}
+	// "one" moved to the end when repeated, then "two" fell off the front
+	if strings.Join(got, ",") != "one,three" {
+		t.Errorf("prompt history = %v, want [one three]", got)
+	}
+
+	if err := s.AddWorkspacePrompt("nonexistent", PromptHistoryEntry{Prompt: "x"}, 2); err == nil {
+		t.Error("expected error for nonexistent workspace")
+	}
+}
+
 // Error path tests
 
 func TestUpdateWorkspaceNotFound(t *testing.T) {
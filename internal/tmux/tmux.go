@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -42,6 +43,36 @@ func (c *defaultChecker) Check() error {
 	return nil
 }
 
+// socketName is the tmux server socket (tmux -L) used by every command in this package.
+// Empty means the user's default tmux server. Set once at daemon startup via SetSocketName.
+var socketName string
+
+// SetSocketName selects the tmux server socket used for all schmux sessions.
+// Call before any sessions are created; existing sessions on another server are not moved.
+func SetSocketName(name string) {
+	socketName = name
+}
+
+// SocketName returns the configured tmux socket name, or "" for the default server.
+func SocketName() string {
+	return socketName
+}
+
+// Command builds a tmux command against the configured socket.
+// Callers outside this package (e.g. the PTY attach in session tracking) must use this
+// instead of invoking "tmux" directly so they reach the same server.
+func Command(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, "tmux", socketArgs(args)...)
+}
+
+// socketArgs prefixes args with -L <socket> when a socket name is configured.
+func socketArgs(args []string) []string {
+	if socketName == "" {
+		return args
+	}
+	return append([]string{"-L", socketName}, args...)
+}
+
 // ANSI escape sequence regex for stripping terminal codes.
 // Compiled once at package initialization for efficiency.
 var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]|\x1b\][^\x07\x1b]*\x07|\x1b\][^\x07\x1b]*\x1b\\`)
@@ -57,11 +88,147 @@ func CreateSession(ctx context.Context, name, dir, command string) error {
 		command, // command to run
 	}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create tmux session: %w: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// CreateSessionWithOptions creates a session like CreateSession, but sets the given
+// session options before the command's pane exists. Options that tmux only reads when
+// a pane is created (history-limit) have no effect when set afterwards. The session
+// starts on a placeholder window that is then replaced by the command, all in one
+// tmux invocation. Values must not end in ";" (tmux's command separator).
+func CreateSessionWithOptions(ctx context.Context, name, dir, command string, options map[string]string) error {
+	if len(options) == 0 {
+		return CreateSession(ctx, name, dir, command)
+	}
+	return runCreateSession(ctx, createSessionArgs(name, dir, command, options))
+}
+
+// CreateHeldSession creates a session like CreateSessionWithOptions and, in the same
+// tmux invocation, sets remain-on-exit on the command's window. A command that exits
+// right away then leaves its pane and output behind to inspect with GetPaneExitStatus
+// and CaptureLastLines. Release it with SetRemainOnExit(ctx, name, false).
+func CreateHeldSession(ctx context.Context, name, dir, command string, options map[string]string) error {
+	args := createSessionArgs(name, dir, command, options)
+	args = append(args, ";", "set-option", "-w", "-t", name, "remain-on-exit", "on")
+	return runCreateSession(ctx, args)
+}
+
+// createSessionArgs returns the tmux arguments creating a session that runs command,
+// with options set before the command's pane is created.
+func createSessionArgs(name, dir, command string, options map[string]string) []string {
+	if len(options) == 0 {
+		return []string{"new-session", "-d", "-s", name, "-c", dir, command}
+	}
+
+	args := []string{"new-session", "-d", "-s", name, "-c", dir, "cat"}
+	keys := make([]string, 0, len(options))
+	for option := range options {
+		keys = append(keys, option)
+	}
+	sort.Strings(keys)
+	for _, option := range keys {
+		args = append(args, ";", "set-option", "-t", name, option, options[option])
+	}
+	// -k replaces the placeholder (the session's first window, whatever base-index is).
+	return append(args, ";", "new-window", "-k", "-t", name+":^", "-c", dir, command)
+}
+
+func runCreateSession(ctx context.Context, args []string) error {
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to create tmux session: %w: %s", err, string(output))
 	}
+	return nil
+}
 
+// SetRemainOnExit sets whether the session's window is kept after its process exits.
+// With it off, tmux closes the session when the process exits, except that a pane
+// that is already dead stays until the session is killed.
+func SetRemainOnExit(ctx context.Context, name string, on bool) error {
+	value := "off"
+	if on {
+		value = "on"
+	}
+	cmd := Command(ctx, "set-option", "-w", "-t", name, "remain-on-exit", value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set remain-on-exit: %w: %s", err, string(output))
+	}
+	return nil
+}
+
+// RespawnPane restarts the dead pane of a session held with remain-on-exit, running
+// command, or the pane's original command when command is empty.
+func RespawnPane(ctx context.Context, name, command string) error {
+	args := []string{"respawn-pane", "-t", name}
+	if command != "" {
+		args = append(args, command)
+	}
+	cmd := Command(ctx, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to respawn pane: %w: %s", err, string(output))
+	}
+	return nil
+}
+
+// PipePane sends everything the target pane outputs to command's stdin (pipe-pane -o).
+// Panes that are already piped are left alone, so it's safe to call again after a
+// daemon restart: tmux keeps the pipe running without us.
+func PipePane(ctx context.Context, target, command string) error {
+	cmd := Command(ctx, "pipe-pane", "-o", "-t", target, command)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to pipe pane: %w: %s", err, string(output))
+	}
+	return nil
+}
+
+// GetPaneID returns the unique ID (e.g. "%5") of the target's pane. Pane IDs survive
+// splits, layout changes, and respawns, so they address one pane for its whole life.
+func GetPaneID(ctx context.Context, target string) (string, error) {
+	cmd := Command(ctx, "display-message", "-p", "-t", target, "#{pane_id}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get pane ID: %w", err)
+	}
+	paneID := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(paneID, "%") {
+		return "", fmt.Errorf("unexpected pane ID %q", paneID)
+	}
+	return paneID, nil
+}
+
+// SplitWindow splits the target's pane and runs command in the new pane, leaving the
+// target's pane active. horizontal places the new pane beside the target instead of
+// below it; sizePercent, when > 0, is the new pane's share of the split.
+func SplitWindow(ctx context.Context, target, dir, command string, horizontal bool, sizePercent int) error {
+	args := []string{"split-window", "-d", "-t", target, "-c", dir}
+	if horizontal {
+		args = append(args, "-h")
+	} else {
+		args = append(args, "-v")
+	}
+	if sizePercent > 0 {
+		args = append(args, "-l", fmt.Sprintf("%d%%", sizePercent))
+	}
+	args = append(args, command)
+	cmd := Command(ctx, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to split window: %w: %s", err, string(output))
+	}
+	return nil
+}
+
+// SelectLayout arranges the panes of the target's window with a tmux preset layout
+// such as "main-vertical" or "tiled".
+func SelectLayout(ctx context.Context, target, layout string) error {
+	cmd := Command(ctx, "select-layout", "-t", target, layout)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to select layout: %w: %s", err, string(output))
+	}
 	return nil
 }
 
@@ -70,7 +237,7 @@ func SessionExists(ctx context.Context, name string) bool {
 	// tmux has-session -t <name> (= prefix for exact match)
 	args := []string{"has-session", "-t", "=" + name}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	err := cmd.Run()
 	return err == nil
 }
@@ -85,7 +252,7 @@ func GetPanePID(ctx context.Context, name string) (int, error) {
 		"#{pane_pid}",
 	}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
@@ -102,6 +269,29 @@ func GetPanePID(ctx context.Context, name string) (int, error) {
 	return pid, nil
 }
 
+// GetPaneExitStatus reports whether the process in the tmux session's pane has exited
+// (only visible while the pane is kept with remain-on-exit) and, if so, its exit code.
+func GetPaneExitStatus(ctx context.Context, name string) (dead bool, code int, err error) {
+	cmd := Command(ctx, "display-message", "-p", "-t", name, "#{pane_dead} #{pane_dead_status}")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		return false, 0, fmt.Errorf("failed to get pane status: %w", err)
+	}
+
+	fields := strings.Fields(stdout.String())
+	if len(fields) == 0 || fields[0] != "1" {
+		return false, 0, nil
+	}
+	if len(fields) > 1 {
+		if _, err := fmt.Sscanf(fields[1], "%d", &code); err != nil {
+			return true, 0, fmt.Errorf("failed to parse exit status: %w", err)
+		}
+	}
+	return true, code, nil
+}
+
 // CaptureOutput captures the current output of a tmux session, including full scrollback history.
 func CaptureOutput(ctx context.Context, name string) (string, error) {
 	// tmux capture-pane -e -p -S - -t <name>
@@ -116,7 +306,7 @@ func CaptureOutput(ctx context.Context, name string) (string, error) {
 		"-t", name, // target session/pane
 	}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
@@ -142,7 +332,7 @@ func CaptureLastLines(ctx context.Context, name string, lines int, includeEscape
 		"-t", name, // target session/pane
 	)
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
@@ -158,7 +348,7 @@ func KillSession(ctx context.Context, name string) error {
 	// tmux kill-session -t <name> (= prefix for exact match)
 	args := []string{"kill-session", "-t", "=" + name}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to kill tmux session: %w: %s", err, string(output))
 	}
@@ -171,7 +361,7 @@ func ListSessions(ctx context.Context) ([]string, error) {
 	// tmux list-sessions -F "#{session_name}"
 	args := []string{"list-sessions", "-F", "#{session_name}"}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 
@@ -193,7 +383,7 @@ func SendKeys(ctx context.Context, name, keys string) error {
 	// tmux send-keys -t <name> <keys> (send-keys does not support = prefix)
 	args := []string{"send-keys", "-t", name, keys}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to send keys to tmux session: %w: %s", err, strings.TrimSpace(string(output)))
 	}
@@ -206,7 +396,7 @@ func SendLiteral(ctx context.Context, name, text string) error {
 	// tmux send-keys -l -t <name> <text> (send-keys does not support = prefix)
 	args := []string{"send-keys", "-l", "-t", name, text}
 
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to send literal text to tmux session: %w: %s", err, strings.TrimSpace(string(output)))
 	}
@@ -215,7 +405,11 @@ func SendLiteral(ctx context.Context, name, text string) error {
 }
 
 // GetAttachCommand returns the command to attach to a tmux session.
+// Includes -L when a dedicated socket is configured so it works from any shell.
 func GetAttachCommand(name string) string {
+	if socketName != "" {
+		return fmt.Sprintf("tmux -L %s attach -t \"=%s\"", socketName, name)
+	}
 	return fmt.Sprintf("tmux attach -t \"=%s\"", name)
 }
 
@@ -228,7 +422,7 @@ func StripAnsi(text string) string {
 func SetWindowSizeManual(ctx context.Context, sessionName string) error {
 	// set-option does not support = prefix for session target
 	args := []string{"set-option", "-t", sessionName, "window-size", "manual"}
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to set window-size manual: %w: %s", err, string(output))
 	}
@@ -238,7 +432,7 @@ func SetWindowSizeManual(ctx context.Context, sessionName string) error {
 // SetOption sets a tmux option on a session.
 func SetOption(ctx context.Context, sessionName, option, value string) error {
 	args := []string{"set-option", "-t", sessionName, option, value}
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to set %s: %w: %s", option, err, string(output))
 	}
@@ -253,7 +447,7 @@ func GetWindowSize(ctx context.Context, sessionName string) (width, height int,
 		"-t", fmt.Sprintf("=%s:0.0", sessionName),
 		"#{window_width} #{window_height}",
 	}
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	if runErr := cmd.Run(); runErr != nil {
@@ -283,7 +477,7 @@ func ResizeWindow(ctx context.Context, sessionName string, width, height int) er
 		"-x", strconv.Itoa(width),
 		"-y", strconv.Itoa(height),
 	}
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to resize window: %w: %s", err, string(output))
 	}
@@ -294,7 +488,7 @@ func ResizeWindow(ctx context.Context, sessionName string, width, height int) er
 // This is used when updating session nicknames.
 func RenameSession(ctx context.Context, oldName, newName string) error {
 	args := []string{"rename-session", "-t", "=" + oldName, newName}
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to rename tmux session: %w: %s", err, string(output))
 	}
@@ -308,7 +502,7 @@ func GetCursorPosition(ctx context.Context, sessionName string) (x, y int, err e
 		"display-message", "-p", "-t", sessionName,
 		"#{cursor_x}", "#{cursor_y}",
 	}
-	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd := Command(ctx, args...)
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	if err := cmd.Run(); err != nil {
@@ -112,6 +112,25 @@ func TestGetAttachCommand(t *testing.T) {
 	}
 }
 
+func TestSocketName(t *testing.T) {
+	SetSocketName("schmux")
+	defer SetSocketName("")
+
+	if got, want := GetAttachCommand("my session"), `tmux -L schmux attach -t "=my session"`; got != want {
+		t.Errorf("GetAttachCommand() = %q, want %q", got, want)
+	}
+	cmd := Command(context.Background(), "has-session", "-t", "=x")
+	if got, want := strings.Join(cmd.Args, " "), "tmux -L schmux has-session -t =x"; got != want {
+		t.Errorf("Command() args = %q, want %q", got, want)
+	}
+
+	SetSocketName("")
+	cmd = Command(context.Background(), "ls")
+	if got, want := strings.Join(cmd.Args, " "), "tmux ls"; got != want {
+		t.Errorf("Command() args without socket = %q, want %q", got, want)
+	}
+}
+
 func TestCaptureLastLines_Validation(t *testing.T) {
 	ctx := context.Background()
 
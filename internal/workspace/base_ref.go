@@ -0,0 +1,53 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type baseRefKey struct{}
+
+// WithBaseRef returns a context whose GetOrCreate starts a branch that doesn't exist
+// yet, locally or on origin, from ref (a tag, commit, or branch) instead of the
+// default branch. Branches that already exist are checked out as usual. Like
+// WithProgress, this keeps the option out of every signature between the spawn and
+// the git call that uses it.
+func WithBaseRef(ctx context.Context, ref string) context.Context {
+	return context.WithValue(ctx, baseRefKey{}, ref)
+}
+
+func baseRefFromContext(ctx context.Context) string {
+	ref, _ := ctx.Value(baseRefKey{}).(string)
+	return ref
+}
+
+// ValidateBaseRef checks that a base ref is safe to hand to git: non-empty, not an
+// option, and free of whitespace and control characters. Whether it names a commit
+// is only known once the repo has been fetched.
+func ValidateBaseRef(ref string) error {
+	if ref == "" {
+		return fmt.Errorf("base ref is empty")
+	}
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("base ref %q may not start with '-'", ref)
+	}
+	if strings.IndexFunc(ref, func(r rune) bool { return r <= ' ' || r == 0x7f }) >= 0 {
+		return fmt.Errorf("base ref %q may not contain whitespace or control characters", ref)
+	}
+	return nil
+}
+
+// verifyBaseRef checks that ref names a commit in the repo at dir.
+func verifyBaseRef(ctx context.Context, dir, ref string) error {
+	if err := ValidateBaseRef(ref); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	cmd.Dir = dir
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("base ref %q not found in repo", ref)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PruneBaseRepos removes base repos (the bare clones worktrees are added from) that no
+// workspace uses and whose repo is no longer configured. A base repo whose repo is busy,
+// such as mid-clone for a new workspace, is skipped, as is one git still lists worktrees
+// for. Base repos with nothing to prune are left out of the results.
+func (m *Manager) PruneBaseRepos(ctx context.Context) ([]BaseRepoPruneResult, error) {
+	var results []BaseRepoPruneResult
+	for _, wb := range m.state.GetWorktreeBases() {
+		if _, configured := m.findRepoByURL(wb.RepoURL); configured || m.baseRepoInUse(wb.RepoURL) {
+			continue
+		}
+		result := BaseRepoPruneResult{RepoURL: wb.RepoURL, Path: wb.Path}
+
+		// Workspace creation holds the repo lock while it clones and adds worktrees
+		lock := m.repoLock(wb.RepoURL)
+		if !lock.TryLock() {
+			result.Status = BaseRepoPruneStatusSkipped
+			result.Reason = "repo is busy"
+			results = append(results, result)
+			continue
+		}
+		if m.baseRepoInUse(wb.RepoURL) {
+			lock.Unlock()
+			continue // a workspace was created while we looked
+		}
+		if worktrees, err := m.baseRepoWorktrees(ctx, wb.Path); err != nil {
+			result.Status = BaseRepoPruneStatusFailed
+			result.Reason = err.Error()
+		} else if worktrees > 0 {
+			result.Status = BaseRepoPruneStatusSkipped
+			result.Reason = fmt.Sprintf("git lists %d worktrees", worktrees)
+		} else if err := m.removeBaseRepo(wb.RepoURL, wb.Path); err != nil {
+			result.Status = BaseRepoPruneStatusFailed
+			result.Reason = err.Error()
+		} else {
+			result.Status = BaseRepoPruneStatusPruned
+		}
+		lock.Unlock()
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// baseRepoInUse reports whether any workspace was created from the repo.
+func (m *Manager) baseRepoInUse(repoURL string) bool {
+	for _, w := range m.state.GetWorkspaces() {
+		if w.Repo == repoURL {
+			return true
+		}
+	}
+	return false
+}
+
+// baseRepoWorktrees returns how many worktrees git still has registered for a bare
+// clone, after pruning the ones whose directories are gone.
+func (m *Manager) baseRepoWorktrees(ctx context.Context, path string) (int, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return 0, nil
+	}
+	pruneCmd := exec.CommandContext(ctx, "git", "worktree", "prune")
+	pruneCmd.Dir = path
+	if output, err := pruneCmd.CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("git worktree prune failed: %w: %s", err, string(output))
+	}
+	listCmd := exec.CommandContext(ctx, "git", "worktree", "list", "--porcelain")
+	listCmd.Dir = path
+	output, err := listCmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("git worktree list failed: %w", err)
+	}
+	worktrees := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "worktree ") {
+			worktrees++
+		}
+	}
+	// The first entry is the bare clone itself
+	return max(worktrees-1, 0), nil
+}
+
+// removeBaseRepo deletes a base repo from disk and forgets it in state.
+func (m *Manager) removeBaseRepo(repoURL, path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	if err := m.state.RemoveWorktreeBase(repoURL); err != nil {
+		return fmt.Errorf("failed to remove base repo from state: %w", err)
+	}
+	if err := m.state.Save(); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	fmt.Printf("[workspace] pruned base repo: url=%s path=%s\n", repoURL, path)
+	return nil
+}
@@ -0,0 +1,80 @@
+package workspace
+
+import (
+	"context"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestPruneBaseRepos(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	baseDir := t.TempDir()
+	newBase := func(name string) string {
+		path := filepath.Join(baseDir, name+".git")
+		runGit(t, baseDir, "init", "-q", "--bare", path)
+		return path
+	}
+	configured := newBase("configured")
+	used := newBase("used")
+	unused := newBase("unused")
+	busy := newBase("busy")
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{WorkspacePath: t.TempDir(), Repos: []config.Repo{{Name: "configured", URL: "git@example.com:me/configured.git"}}}
+	manager := New(cfg, st, statePath)
+	for url, path := range map[string]string{
+		"git@example.com:me/configured.git": configured,
+		"git@example.com:me/used.git":       used,
+		"git@example.com:me/unused.git":     unused,
+		"git@example.com:me/busy.git":       busy,
+	} {
+		if err := st.AddWorktreeBase(state.WorktreeBase{RepoURL: url, Path: path}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.AddWorkspace(state.Workspace{ID: "used-001", Repo: "git@example.com:me/used.git", Branch: "main", Path: t.TempDir()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A clone in progress holds the repo lock
+	lock := manager.repoLock("git@example.com:me/busy.git")
+	lock.Lock()
+	results, err := manager.PruneBaseRepos(context.Background())
+	lock.Unlock()
+	if err != nil {
+		t.Fatalf("PruneBaseRepos() error: %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, r := range results {
+		got[r.RepoURL] = r.Status
+	}
+	want := map[string]string{
+		"git@example.com:me/unused.git": BaseRepoPruneStatusPruned,
+		"git@example.com:me/busy.git":   BaseRepoPruneStatusSkipped,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("results = %+v, want statuses %v", results, want)
+	}
+
+	if _, err := os.Stat(unused); !os.IsNotExist(err) {
+		t.Errorf("unused base repo still on disk: %v", err)
+	}
+	if _, found := st.GetWorktreeBaseByURL("git@example.com:me/unused.git"); found {
+		t.Error("unused base repo still in state")
+	}
+	for _, path := range []string{configured, used, busy} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s was removed: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// gitRemoteBranchHead returns the commit at refs/remotes/origin/<branch>, or "" if
+// there is no such ref.
+func (m *Manager) gitRemoteBranchHead(ctx context.Context, dir, branch string) string {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch+"^{commit}")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// trackRemoteBranch refreshes w.RemoteBranchHead and w.GitBranchGone from origin/<w.Branch>.
+// A branch is gone once it has been seen on the remote and its ref was since pruned.
+// previousBranch is the branch recorded before this refresh; switching branches
+// forgets what was seen for the old one. Returns true if RemoteBranchHead changed.
+func (m *Manager) trackRemoteBranch(ctx context.Context, w *state.Workspace, previousBranch string) bool {
+	seen := w.RemoteBranchHead
+	if w.Branch != previousBranch {
+		w.RemoteBranchHead = ""
+	}
+	if w.Branch == "" || w.Branch == "HEAD" {
+		w.GitBranchGone = false
+		return w.RemoteBranchHead != seen
+	}
+	if head := m.gitRemoteBranchHead(ctx, w.Path, w.Branch); head != "" {
+		w.RemoteBranchHead = head
+		w.GitBranchGone = false
+	} else {
+		w.GitBranchGone = w.RemoteBranchHead != ""
+	}
+	return w.RemoteBranchHead != seen
+}
+
+// applyBranchGonePolicy acts on a workspace whose branch was deleted on the remote
+// according to sessions.branch_gone_policy. Only "dispose" does anything here; "warn"
+// and "prompt" are handled by the dashboard. A workspace is only disposed when it has
+// no sessions, no uncommitted changes, and no commits beyond what was last pushed.
+func (m *Manager) applyBranchGonePolicy(ctx context.Context, w state.Workspace) {
+	if !w.GitBranchGone || m.config.GetBranchGonePolicy() != config.BranchGonePolicyDispose {
+		return
+	}
+	if w.Imported || m.hasActiveSessions(w.ID) {
+		return
+	}
+	if w.GitDirty || w.GitFilesChanged > 0 {
+		return
+	}
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", "HEAD", w.RemoteBranchHead)
+	cmd.Dir = w.Path
+	if err := cmd.Run(); err != nil {
+		return // HEAD has commits that were never pushed
+	}
+	fmt.Printf("[workspace] disposing %s: origin/%s was deleted on the remote\n", w.ID, w.Branch)
+	if err := m.Dispose(w.ID); err != nil {
+		fmt.Printf("[workspace] failed to dispose %s: %v\n", w.ID, err)
+	}
+}
@@ -0,0 +1,70 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+func TestUpdateGitStatusDetectsBranchGone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	mgr, remoteDir, wsDir, wsID := setupWorkspaceGraphTest(t, "feature")
+	ctx := context.Background()
+
+	// Never pushed: not gone
+	w, err := mgr.UpdateGitStatus(ctx, wsID)
+	if err != nil {
+		t.Fatalf("UpdateGitStatus() error: %v", err)
+	}
+	if w.GitBranchGone || w.RemoteBranchHead != "" {
+		t.Fatalf("unpushed branch: gone=%v head=%q, want false and empty", w.GitBranchGone, w.RemoteBranchHead)
+	}
+
+	commitOnWorkspace(t, wsDir, "feature.txt", "feature work")
+	runGit(t, wsDir, "push", "-u", "origin", "feature")
+	w, err = mgr.UpdateGitStatus(ctx, wsID)
+	if err != nil {
+		t.Fatalf("UpdateGitStatus() error: %v", err)
+	}
+	if w.GitBranchGone || w.RemoteBranchHead != getHash(t, wsDir, "HEAD") {
+		t.Fatalf("pushed branch: gone=%v head=%q, want false and HEAD", w.GitBranchGone, w.RemoteBranchHead)
+	}
+
+	// Deleted on the remote: the pruning fetch drops origin/feature
+	runGit(t, remoteDir, "branch", "-D", "feature")
+	w, err = mgr.UpdateGitStatus(ctx, wsID)
+	if err != nil {
+		t.Fatalf("UpdateGitStatus() error: %v", err)
+	}
+	if !w.GitBranchGone {
+		t.Fatal("GitBranchGone = false after the remote branch was deleted")
+	}
+
+	// Warn (default) leaves the workspace alone
+	mgr.UpdateAllGitStatus(ctx)
+	if _, found := mgr.state.GetWorkspace(wsID); !found {
+		t.Fatal("workspace disposed under the warn policy")
+	}
+
+	// Dispose refuses while HEAD has commits that were never pushed
+	mgr.config.Sessions = &config.SessionsConfig{BranchGonePolicy: config.BranchGonePolicyDispose}
+	commitOnWorkspace(t, wsDir, "more.txt", "unpushed work")
+	mgr.UpdateAllGitStatus(ctx)
+	if _, found := mgr.state.GetWorkspace(wsID); !found {
+		t.Fatal("workspace with unpushed commits was disposed")
+	}
+
+	runGit(t, wsDir, "reset", "--hard", "HEAD~1")
+	mgr.UpdateAllGitStatus(ctx)
+	if _, found := mgr.state.GetWorkspace(wsID); found {
+		t.Fatal("workspace not disposed under the dispose policy")
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Errorf("workspace directory still exists: %v", err)
+	}
+}
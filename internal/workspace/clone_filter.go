@@ -0,0 +1,25 @@
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// gitCloneFilterArgs returns the "git clone --filter" option for a repo with a
+// clone_filter, which makes the clone partial. git records the filter on the clone,
+// so later fetches stay partial and missing objects are fetched from origin on demand.
+func (m *Manager) gitCloneFilterArgs(repoURL string) []string {
+	if filter := m.config.GetRepoCloneFilter(repoURL); filter != "" {
+		return []string{"--filter=" + filter}
+	}
+	return nil
+}
+
+// withPartialCloneHint explains a git failure caused by objects a partial clone hasn't
+// fetched yet and couldn't fetch now, usually because origin is unreachable.
+func withPartialCloneHint(err error, output []byte) error {
+	if !bytes.Contains(output, []byte("promisor remote")) {
+		return err
+	}
+	return fmt.Errorf("%w (the repo is a partial clone, see clone_filter, and objects it needs could not be fetched from origin; retry when origin is reachable)", err)
+}
@@ -0,0 +1,60 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// preDisposeTimeout bounds a repo's pre_dispose_command.
+const preDisposeTimeout = 2 * time.Minute
+
+// runPreDisposeCommand runs the repo's pre_dispose_command, if any, in the workspace
+// directory. The command sees the workspace env file plus SCHMUX_WORKSPACE_ID,
+// SCHMUX_WORKSPACE_PATH, SCHMUX_BRANCH, and SCHMUX_REPO. Failures are only logged:
+// a broken cleanup script must not leave the workspace undisposable. A command that
+// access_control.command_allowlist doesn't permit is skipped, which also covers one
+// written before the allowlist was tightened.
+func (m *Manager) runPreDisposeCommand(ctx context.Context, w state.Workspace) {
+	command := m.config.GetPreDisposeCommand(w.Repo)
+	if command == "" {
+		return
+	}
+	if !m.config.IsCommandAllowed(command) {
+		fmt.Printf("[workspace] warning: skipping pre_dispose_command for %s: not permitted by access_control.command_allowlist\n", w.ID)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, preDisposeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = w.Path
+	cmd.Env = os.Environ()
+	workspaceEnv, err := m.LoadWorkspaceEnv(&w)
+	if err != nil {
+		fmt.Printf("[workspace] warning: %v\n", err)
+	}
+	for key, value := range workspaceEnv {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Env = append(cmd.Env,
+		"SCHMUX_WORKSPACE_ID="+w.ID,
+		"SCHMUX_WORKSPACE_PATH="+w.Path,
+		"SCHMUX_BRANCH="+w.Branch,
+		"SCHMUX_REPO="+w.Repo,
+	)
+
+	fmt.Printf("[workspace] running pre_dispose_command: id=%s\n", w.ID)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", preDisposeTimeout)
+		}
+		fmt.Printf("[workspace] warning: pre_dispose_command failed for %s: %v: %s\n", w.ID, err, strings.TrimSpace(string(output)))
+	}
+}
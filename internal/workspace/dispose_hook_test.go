@@ -0,0 +1,78 @@
+package workspace
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+)
+
+func TestDisposeRunsPreDisposeCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	mgr, _, wsDir, wsID := setupWorkspaceGraphTest(t, "feature")
+	writeFile(t, wsDir, ".schmux.env", "DB_PREFIX=test_")
+	runGit(t, wsDir, "add", ".schmux.env")
+	runGit(t, wsDir, "commit", "-m", "add env file")
+	runGit(t, wsDir, "push", "-u", "origin", "feature")
+
+	outPath := filepath.Join(t.TempDir(), "out")
+	mgr.config.Repos[0].PreDisposeCommand = `echo "$DB_PREFIX$SCHMUX_BRANCH $SCHMUX_WORKSPACE_ID $(pwd)" > ` + outPath
+
+	resolved, _ := filepath.EvalSymlinks(wsDir)
+
+	if err := mgr.Dispose(wsID); err != nil {
+		t.Fatalf("Dispose() error: %v", err)
+	}
+	got, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("pre_dispose_command did not run: %v", err)
+	}
+	if want := "test_feature " + wsID + " " + resolved + "\n"; string(got) != want {
+		t.Errorf("pre_dispose_command output = %q, want %q", got, want)
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Errorf("workspace directory still exists: %v", err)
+	}
+}
+
+func TestDisposeIgnoresFailingPreDisposeCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	mgr, _, wsDir, wsID := setupWorkspaceGraphTest(t, "main")
+	mgr.config.Repos[0].PreDisposeCommand = "echo cleanup failed >&2; exit 3"
+
+	if err := mgr.Dispose(wsID); err != nil {
+		t.Fatalf("Dispose() error: %v", err)
+	}
+	if _, found := mgr.state.GetWorkspace(wsID); found {
+		t.Error("workspace still in state after dispose")
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Errorf("workspace directory still exists: %v", err)
+	}
+}
+
+func TestDisposeSkipsDisallowedPreDisposeCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	mgr, _, wsDir, wsID := setupWorkspaceGraphTest(t, "main")
+	outPath := filepath.Join(t.TempDir(), "out")
+	mgr.config.Repos[0].PreDisposeCommand = "touch " + outPath
+	mgr.config.AccessControl = &config.AccessControlConfig{CommandAllowlist: []string{"make drop-db"}}
+
+	if err := mgr.Dispose(wsID); err != nil {
+		t.Fatalf("Dispose() error: %v", err)
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("disallowed pre_dispose_command ran: %v", err)
+	}
+	if _, err := os.Stat(wsDir); !os.IsNotExist(err) {
+		t.Errorf("workspace directory still exists: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package workspace
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadWorkspaceEnv reads the workspace's env file (sessions.workspace_env_file) and
+// returns its variables. A missing file, or a remote workspace, yields nil.
+func (m *Manager) LoadWorkspaceEnv(w *state.Workspace) (map[string]string, error) {
+	if w.IsRemoteWorkspace() {
+		return nil, nil
+	}
+	envPath := filepath.Join(w.Path, m.config.GetWorkspaceEnvFile())
+	f, err := os.Open(envPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", envPath, err)
+	}
+	defer f.Close()
+
+	env, err := parseEnvFile(bufio.NewScanner(f))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", envPath, err)
+	}
+	return env, nil
+}
+
+// parseEnvFile parses KEY=VALUE lines. Blank lines and lines starting with '#' are
+// ignored, an optional "export " prefix is allowed, and a value wrapped in matching
+// single or double quotes is unquoted. Values are taken literally: no variable
+// expansion or escape processing.
+func parseEnvFile(scanner *bufio.Scanner) (map[string]string, error) {
+	env := make(map[string]string)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
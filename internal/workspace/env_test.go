@@ -0,0 +1,69 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestLoadWorkspaceEnv(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	cfg := &config.Config{WorkspacePath: t.TempDir()}
+	manager := New(cfg, state.New(statePath), statePath)
+	w := &state.Workspace{ID: "repo-001", Path: t.TempDir()}
+
+	// Missing file is not an error.
+	env, err := manager.LoadWorkspaceEnv(w)
+	if err != nil || env != nil {
+		t.Fatalf("missing file: got %v, %v", env, err)
+	}
+
+	content := "# shared config\n\nAPI_BASE_URL=http://localhost:8080\nexport FEATURE_X=on\nQUOTED=\"a b\"\nSINGLE='$HOME'\nEMPTY=\nWITH_EQUALS=a=b\n"
+	if err := os.WriteFile(filepath.Join(w.Path, ".schmux.env"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env, err = manager.LoadWorkspaceEnv(w)
+	if err != nil {
+		t.Fatalf("LoadWorkspaceEnv() error: %v", err)
+	}
+	want := map[string]string{
+		"API_BASE_URL": "http://localhost:8080",
+		"FEATURE_X":    "on",
+		"QUOTED":       "a b",
+		"SINGLE":       "$HOME",
+		"EMPTY":        "",
+		"WITH_EQUALS":  "a=b",
+	}
+	if len(env) != len(want) {
+		t.Errorf("got %d vars, want %d: %v", len(env), len(want), env)
+	}
+	for k, v := range want {
+		if got, ok := env[k]; !ok || got != v {
+			t.Errorf("%s = %q (present=%v), want %q", k, got, ok, v)
+		}
+	}
+
+	// A configured file name replaces the default.
+	cfg.Sessions = &config.SessionsConfig{WorkspaceEnvFile: "config/dev.env"}
+	if env, err := manager.LoadWorkspaceEnv(w); err != nil || env != nil {
+		t.Errorf("configured missing file: got %v, %v", env, err)
+	}
+
+	// Malformed lines are reported with their line number.
+	cfg.Sessions = nil
+	if err := os.WriteFile(filepath.Join(w.Path, ".schmux.env"), []byte("OK=1\nnot a var\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.LoadWorkspaceEnv(w); err == nil {
+		t.Error("expected error for malformed line")
+	}
+
+	// Remote workspaces have no local env file.
+	remote := &state.Workspace{ID: "remote-001", Path: w.Path, RemoteHostID: "host-1"}
+	if env, err := manager.LoadWorkspaceEnv(remote); err != nil || env != nil {
+		t.Errorf("remote workspace: got %v, %v", env, err)
+	}
+}
@@ -0,0 +1,208 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// Fork creates a new workspace on newBranch starting from the source workspace's
+// current HEAD. Unlike GetOrCreate, the starting point is another workspace's
+// working state (including unpushed commits), not a remote branch.
+// When copyUncommitted is true, staged, unstaged, and untracked changes are
+// carried over as well.
+func (m *Manager) Fork(ctx context.Context, sourceWorkspaceID, newBranch string, copyUncommitted bool) (*state.Workspace, error) {
+	if err := ValidateBranchName(newBranch); err != nil {
+		return nil, fmt.Errorf("failed to fork workspace: %w", err)
+	}
+
+	src, found := m.state.GetWorkspace(sourceWorkspaceID)
+	if !found {
+		return nil, fmt.Errorf("workspace not found: %s", sourceWorkspaceID)
+	}
+	if src.IsRemoteWorkspace() {
+		return nil, fmt.Errorf("cannot fork remote workspace: %s", sourceWorkspaceID)
+	}
+	if strings.HasPrefix(src.Repo, "local:") {
+		return nil, fmt.Errorf("cannot fork local repository workspace: %s", sourceWorkspaceID)
+	}
+
+	repoConfig, found := m.findRepoByURL(src.Repo)
+	if !found {
+		return nil, fmt.Errorf("repo URL not found in config: %s", src.Repo)
+	}
+
+	lock := m.repoLock(src.Repo)
+	lock.Lock()
+	defer lock.Unlock()
+
+	headCmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	headCmd.Dir = src.Path
+	headOutput, err := headCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse HEAD failed: %w", err)
+	}
+	sourceHead := strings.TrimSpace(string(headOutput))
+
+	workspaces := m.getWorkspacesForRepo(src.Repo)
+	if err := m.checkWorkspaceLimit(repoConfig.Name, workspaces); err != nil {
+		return nil, err
+	}
+	nextNum := findNextWorkspaceNumber(workspaces)
+	workspaceID := fmt.Sprintf("%s-"+workspaceNumberFormat, repoConfig.Name, nextNum)
+	workspacePath := filepath.Join(m.config.GetWorkspacePath(), workspaceID)
+
+	worktreeBasePath, err := m.ensureWorktreeBase(ctx, src.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure worktree base: %w", err)
+	}
+
+	if m.config.UseWorktrees() {
+		// Worktrees share one object store, so the source HEAD is always reachable.
+		if m.localBranchExists(ctx, worktreeBasePath, newBranch) {
+			return nil, fmt.Errorf("branch already exists: %s", newBranch)
+		}
+	}
+
+	cleanupNeeded := true
+	defer func() {
+		if cleanupNeeded {
+			fmt.Printf("[workspace] cleaning up failed fork: %s\n", workspacePath)
+			if err := m.removeWorktree(ctx, worktreeBasePath, workspacePath); err != nil {
+				os.RemoveAll(workspacePath)
+			}
+			if m.config.UseWorktrees() {
+				_ = m.deleteBranch(ctx, worktreeBasePath, newBranch)
+			}
+		}
+	}()
+
+	fmt.Printf("[workspace] forking: source=%s head=%s branch=%s path=%s\n", src.ID, sourceHead, newBranch, workspacePath)
+	if m.config.UseWorktrees() {
+		cmd := exec.CommandContext(ctx, "git", "worktree", "add", "-b", newBranch, workspacePath, sourceHead)
+		cmd.Dir = worktreeBasePath
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git worktree add failed: %w: %s", err, string(output))
+		}
+	} else {
+		if err := m.cloneRepo(ctx, src.Repo, workspacePath); err != nil {
+			return nil, fmt.Errorf("failed to clone repo: %w", err)
+		}
+		// The source HEAD may contain unpushed commits, so fetch it from the source clone.
+		fetchCmd := exec.CommandContext(ctx, "git", "fetch", src.Path, sourceHead)
+		fetchCmd.Dir = workspacePath
+		if output, err := fetchCmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git fetch from source workspace failed: %w: %s", err, string(output))
+		}
+		checkoutCmd := exec.CommandContext(ctx, "git", "checkout", "-b", newBranch, sourceHead)
+		checkoutCmd.Dir = workspacePath
+		if output, err := checkoutCmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git checkout -b %s failed: %w: %s", newBranch, err, string(output))
+		}
+	}
+
+	if copyUncommitted {
+		if err := copyUncommittedChanges(ctx, src.Path, workspacePath); err != nil {
+			return nil, fmt.Errorf("failed to copy uncommitted changes: %w", err)
+		}
+	}
+
+	if err := m.copyOverlayFiles(ctx, repoConfig.Name, workspacePath); err != nil {
+		fmt.Printf("[workspace] warning: failed to copy overlay files: %v\n", err)
+	}
+
+	w := state.Workspace{
+		ID:     workspaceID,
+		Repo:   src.Repo,
+		Branch: newBranch,
+		Path:   workspacePath,
+	}
+	if err := m.state.AddWorkspace(w); err != nil {
+		return nil, fmt.Errorf("failed to add workspace to state: %w", err)
+	}
+	if err := m.state.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+	cleanupNeeded = false
+
+	if m.gitWatcher != nil {
+		m.gitWatcher.AddWorkspace(w.ID, w.Path)
+	}
+
+	fmt.Printf("[workspace] forked: id=%s source=%s branch=%s\n", w.ID, src.ID, newBranch)
+	return &w, nil
+}
+
+// copyUncommittedChanges replays tracked changes (staged and unstaged, relative to HEAD)
+// from srcPath onto dstPath as a binary patch, then copies untracked files.
+func copyUncommittedChanges(ctx context.Context, srcPath, dstPath string) error {
+	diffCmd := exec.CommandContext(ctx, "git", "diff", "HEAD", "--binary")
+	diffCmd.Dir = srcPath
+	patch, err := diffCmd.Output()
+	if err != nil {
+		return fmt.Errorf("git diff HEAD failed: %w", err)
+	}
+	if len(patch) > 0 {
+		applyCmd := exec.CommandContext(ctx, "git", "apply", "--binary", "-")
+		applyCmd.Dir = dstPath
+		applyCmd.Stdin = strings.NewReader(string(patch))
+		if output, err := applyCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git apply failed: %w: %s", err, string(output))
+		}
+	}
+
+	untrackedCmd := exec.CommandContext(ctx, "git", "ls-files", "--others", "--exclude-standard", "-z")
+	untrackedCmd.Dir = srcPath
+	output, err := untrackedCmd.Output()
+	if err != nil {
+		return fmt.Errorf("git ls-files failed: %w", err)
+	}
+	for _, relPath := range strings.Split(string(output), "\x00") {
+		if relPath == "" {
+			continue
+		}
+		if err := copyWorkspaceFile(filepath.Join(srcPath, relPath), filepath.Join(dstPath, relPath)); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", relPath, err)
+		}
+	}
+	return nil
+}
+
+// copyWorkspaceFile copies a regular file or symlink, creating parent directories.
+func copyWorkspaceFile(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
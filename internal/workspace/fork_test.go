@@ -0,0 +1,106 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestFork_BothSCMModes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	modes := []string{config.SourceCodeManagementGitWorktree, config.SourceCodeManagementGit}
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			upstream := gitTestUpstream(t)
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			st := state.New(statePath)
+			cfg := &config.Config{
+				WorkspacePath:        t.TempDir(),
+				WorktreeBasePath:     t.TempDir(),
+				SourceCodeManagement: mode,
+				Repos:                []config.Repo{{Name: "test", URL: upstream}},
+			}
+			manager := New(cfg, st, statePath)
+			ctx := context.Background()
+
+			src, err := manager.GetOrCreate(ctx, upstream, "feature")
+			if err != nil {
+				t.Fatalf("GetOrCreate failed: %v", err)
+			}
+
+			// An unpushed commit plus tracked, staged, and untracked changes.
+			writeFile(t, src.Path, "committed.txt", "local commit")
+			runGit(t, src.Path, "add", ".")
+			runGit(t, src.Path, "commit", "-m", "unpushed")
+			writeFile(t, src.Path, "README.md", "modified")
+			writeFile(t, src.Path, "staged.txt", "staged")
+			runGit(t, src.Path, "add", "staged.txt")
+			if err := os.MkdirAll(filepath.Join(src.Path, "dir"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, src.Path, "dir/untracked.txt", "untracked")
+
+			forked, err := manager.Fork(ctx, src.ID, "feature-copy", true)
+			if err != nil {
+				t.Fatalf("Fork failed: %v", err)
+			}
+			if forked.ID == src.ID || forked.Path == src.Path {
+				t.Fatalf("fork reused source workspace: %+v", forked)
+			}
+			if forked.Branch != "feature-copy" || currentBranch(t, forked.Path) != "feature-copy" {
+				t.Fatalf("fork branch = %s (checked out %s), want feature-copy", forked.Branch, currentBranch(t, forked.Path))
+			}
+			if got, want := getHash(t, forked.Path, "HEAD"), getHash(t, src.Path, "HEAD"); got != want {
+				t.Fatalf("fork HEAD = %s, want source HEAD %s", got, want)
+			}
+			for name, want := range map[string]string{
+				"README.md":         "modified",
+				"staged.txt":        "staged",
+				"dir/untracked.txt": "untracked",
+			} {
+				data, err := os.ReadFile(filepath.Join(forked.Path, name))
+				if err != nil || string(data) != want {
+					t.Fatalf("%s = %q (err %v), want %q", name, data, err, want)
+				}
+			}
+			if _, found := st.GetWorkspace(forked.ID); !found {
+				t.Fatalf("forked workspace %s not registered in state", forked.ID)
+			}
+
+			// Without copyUncommitted, only the committed state comes across.
+			clean, err := manager.Fork(ctx, src.ID, "feature-clean", false)
+			if err != nil {
+				t.Fatalf("Fork (clean) failed: %v", err)
+			}
+			if got := gitOutput(t, clean.Path, "status", "--porcelain"); strings.TrimSpace(got) != "" {
+				t.Fatalf("expected clean fork, status: %q", got)
+			}
+		})
+	}
+}
+
+func TestFork_RejectsInvalidBranch(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	manager := New(&config.Config{WorkspacePath: t.TempDir()}, st, statePath)
+
+	if _, err := manager.Fork(context.Background(), "missing", "bad..branch", false); err == nil {
+		t.Fatal("expected error for invalid branch name")
+	}
+	if _, err := manager.Fork(context.Background(), "missing", "ok-branch", false); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
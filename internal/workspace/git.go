@@ -19,6 +19,10 @@ var branchNamePattern = regexp.MustCompile(`^[a-z0-9_]+(?:[._/-][a-z0-9_]+)*$`)
 // ErrInvalidBranchName is returned when a branch name fails validation.
 var ErrInvalidBranchName = errors.New("invalid branch name")
 
+// ErrOffline is returned by operations that cannot work without the network
+// (pushes, fetching PR refs) while sessions.offline is set.
+var ErrOffline = errors.New("schmux is in offline mode")
+
 // ValidateBranchName checks whether a branch name is acceptable for use.
 // Returns nil if valid, or an error describing the problem.
 func ValidateBranchName(branch string) error {
@@ -89,8 +93,14 @@ func resolveWorktreeBaseFromWorktree(worktreePath string) (string, error) {
 	return "", fmt.Errorf("could not parse worktree base from gitdir: %s", gitdir)
 }
 
-// gitFetch runs git fetch. For worktrees, fetches from the worktree base.
+// gitFetch runs git fetch --prune. For worktrees, fetches from the worktree base.
+// Pruning drops origin/* refs for branches deleted on the remote, which is how a
+// workspace's branch is noticed to be gone. In offline mode it does nothing,
+// leaving the existing origin/* refs in place.
 func (m *Manager) gitFetch(ctx context.Context, dir string) error {
+	if m.config.GetOffline() {
+		return nil
+	}
 	// Resolve to worktree base if this is a worktree
 	fetchDir := dir
 	if isWorktree(dir) {
@@ -99,28 +109,31 @@ func (m *Manager) gitFetch(ctx context.Context, dir string) error {
 		}
 	}
 
-	args := []string{"fetch"}
+	args := []string{"fetch", "--prune"}
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = fetchDir
 
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := runGitProgress(ctx, cmd, ProgressFetching); err != nil {
 		return fmt.Errorf("git fetch failed: %w: %s", err, string(output))
 	}
 
 	return nil
 }
 
-// gitCheckoutBranch runs git checkout -B, optionally resetting to origin/<branch>.
-func (m *Manager) gitCheckoutBranch(ctx context.Context, dir, branch string, remoteBranchExists bool) error {
+// gitCheckoutBranch runs git checkout -B, optionally resetting to origin/<branch>,
+// or else starting the branch from startPoint if one is given.
+func (m *Manager) gitCheckoutBranch(ctx context.Context, dir, branch string, remoteBranchExists bool, startPoint string) error {
 	args := []string{"checkout", "-B", branch}
 	if remoteBranchExists {
 		args = append(args, "origin/"+branch)
+	} else if startPoint != "" {
+		args = append(args, startPoint)
 	}
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = dir
 
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git checkout failed: %w: %s", err, string(output))
+		return withPartialCloneHint(fmt.Errorf("git checkout failed: %w: %s", err, string(output)), output)
 	}
 
 	return nil
@@ -130,6 +143,11 @@ func (m *Manager) gitCheckoutBranch(ctx context.Context, dir, branch string, rem
 // For cloned repos with an origin remote, this avoids relying on potentially incorrect
 // upstream config. For local repos without origin, skips the pull.
 func (m *Manager) gitPullRebase(ctx context.Context, dir, branch string) error {
+	if m.config.GetOffline() {
+		fmt.Printf("[workspace] offline, skipping pull\n")
+		return nil
+	}
+
 	// Check if origin remote exists
 	remoteCmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
 	remoteCmd.Dir = dir
@@ -151,6 +169,54 @@ func (m *Manager) gitPullRebase(ctx context.Context, dir, branch string) error {
 	return nil
 }
 
+// PullConflictError is returned when preparing a workspace hits conflicts during
+// git pull --rebase. The rebase has already been aborted, so the workspace is left
+// on its pre-pull commit with a clean rebase state.
+type PullConflictError struct {
+	WorkspaceID     string
+	Branch          string
+	ConflictedFiles []string
+}
+
+func (e *PullConflictError) Error() string {
+	if len(e.ConflictedFiles) == 0 {
+		return fmt.Sprintf("git pull --rebase of origin/%s conflicted in workspace %s; rebase aborted", e.Branch, e.WorkspaceID)
+	}
+	return fmt.Sprintf("git pull --rebase of origin/%s conflicted in workspace %s (%s); rebase aborted", e.Branch, e.WorkspaceID, strings.Join(e.ConflictedFiles, ", "))
+}
+
+// abortRebase runs git rebase --abort if a rebase is in progress.
+// Returns true if a rebase was aborted.
+func (m *Manager) abortRebase(ctx context.Context, dir string) (bool, error) {
+	if !rebaseInProgress(dir) {
+		return false, nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "rebase", "--abort")
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return false, fmt.Errorf("git rebase --abort failed: %w: %s", err, string(output))
+	}
+	return true, nil
+}
+
+// pullRebaseOrAbort runs git pull --rebase and, if it stops on conflicts, aborts the
+// rebase and returns a *PullConflictError listing the conflicted files.
+func (m *Manager) pullRebaseOrAbort(ctx context.Context, workspaceID, dir, branch string) error {
+	err := m.gitPullRebase(ctx, dir, branch)
+	if err == nil {
+		return nil
+	}
+	if !rebaseInProgress(dir) {
+		return fmt.Errorf("git pull --rebase failed: %w", err)
+	}
+	conflicted := m.getUnmergedFiles(ctx, dir)
+	if _, abortErr := m.abortRebase(ctx, dir); abortErr != nil {
+		return fmt.Errorf("git pull --rebase conflicted and %w", abortErr)
+	}
+	fmt.Printf("[workspace] pull conflict, rebase aborted: id=%s branch=%s files=%v\n", workspaceID, branch, conflicted)
+	return &PullConflictError{WorkspaceID: workspaceID, Branch: branch, ConflictedFiles: conflicted}
+}
+
 // gitHasOriginRemote checks if the repo has an origin remote configured.
 func (m *Manager) gitHasOriginRemote(ctx context.Context, dir string) bool {
 	remoteCmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
@@ -347,6 +413,20 @@ func countLinesCapped(path string, maxBytes int) (int, error) {
 	return lineCount, nil
 }
 
+// porcelainPath extracts the path from the path field of a `git status --porcelain`
+// line, taking the destination of a rename and unquoting C-style quoted paths.
+func porcelainPath(field string) string {
+	if _, dest, ok := strings.Cut(field, " -> "); ok {
+		field = dest
+	}
+	if strings.HasPrefix(field, `"`) {
+		if unquoted, err := strconv.Unquote(field); err == nil {
+			field = unquoted
+		}
+	}
+	return field
+}
+
 // checkGitSafety checks if a workspace is safe to dispose based on git state.
 // Returns detailed status about why the workspace is not safe.
 func (m *Manager) checkGitSafety(ctx context.Context, workspaceID string) (*GitSafetyStatus, error) {
@@ -357,8 +437,14 @@ func (m *Manager) checkGitSafety(ctx context.Context, workspaceID string) (*GitS
 
 	status := &GitSafetyStatus{Safe: true}
 
-	// Check for dirty state (any changes: modified, added, removed, or untracked)
-	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
+	// Check for dirty state (any changes: modified, added, removed, or untracked).
+	// With ignore globs, list untracked files individually so they can be matched.
+	ignoreGlobs := len(m.config.GetDisposeIgnoreGlobs()) > 0
+	statusArgs := []string{"status", "--porcelain"}
+	if ignoreGlobs {
+		statusArgs = append(statusArgs, "--untracked-files=all")
+	}
+	statusCmd := exec.CommandContext(ctx, "git", statusArgs...)
 	statusCmd.Dir = w.Path
 	output, err := statusCmd.CombinedOutput()
 	if err != nil {
@@ -370,8 +456,11 @@ func (m *Manager) checkGitSafety(ctx context.Context, workspaceID string) (*GitS
 
 	// Parse status output to count file types
 	// Format: XY filename where X is staged, Y is unstaged
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
 	for _, line := range lines {
+		if ignoreGlobs && len(line) > 3 && m.config.IsDisposeIgnored(porcelainPath(line[3:])) {
+			continue
+		}
 		line = strings.TrimSpace(line)
 		if line == "" {
 			continue
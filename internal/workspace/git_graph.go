@@ -14,6 +14,9 @@ import (
 const (
 	defaultMaxCommits  = 200
 	defaultContextSize = 5
+
+	defaultRecentCommits = 20
+	maxRecentCommits     = 500
 )
 
 // GetGitGraph returns the commit graph for a workspace, showing the local branch
@@ -55,6 +58,12 @@ func (m *Manager) GetGitGraph(ctx context.Context, workspaceID string, maxCommit
 		}
 	}
 
+	// The graph only changes when one of these inputs does; dirty state is overlaid by the caller.
+	cacheKey := gitGraphCacheKey(localHead, originMainHead, localBranch, maxCommits, contextSize, branchWorkspaces)
+	if cached := m.cachedGitGraph(workspaceID, cacheKey); cached != nil {
+		return cached, nil
+	}
+
 	// Find fork point
 	var forkPoint string
 	if originMainHead != "" && localHead != originMainHead {
@@ -79,7 +88,115 @@ func (m *Manager) GetGitGraph(ctx context.Context, workspaceID string, maxCommit
 		return nil, fmt.Errorf("git log failed: %w", err)
 	}
 
-	return BuildGraphResponse(rawNodes, localBranch, defaultBranch, localHead, originMainHead, forkPoint, branchWorkspaces, ws.Repo, maxCommits), nil
+	resp := BuildGraphResponse(rawNodes, localBranch, defaultBranch, localHead, originMainHead, forkPoint, branchWorkspaces, ws.Repo, maxCommits)
+	m.storeGitGraph(workspaceID, cacheKey, resp)
+	return resp, nil
+}
+
+// gitGraphCacheEntry is a computed graph and the key of the inputs it was built from.
+type gitGraphCacheEntry struct {
+	key  string
+	resp *contracts.GitGraphResponse
+}
+
+// gitGraphCacheKey identifies everything a graph depends on besides the commits themselves:
+// both branch heads, the request limits, and which workspaces sit on which branches.
+// Uncommitted changes are deliberately not part of the key: the graph is built from
+// commits only, and the dashboard handler overlays DirtyState on the returned copy from
+// the workspace's current git stats on every request, so the cache never serves a
+// stale dirty state.
+func gitGraphCacheKey(localHead, originMainHead, localBranch string, maxCommits, contextSize int, branchWorkspaces map[string][]string) string {
+	branches := make([]string, 0, len(branchWorkspaces))
+	for branch, ids := range branchWorkspaces {
+		sorted := append([]string(nil), ids...)
+		sort.Strings(sorted)
+		branches = append(branches, branch+"="+strings.Join(sorted, ","))
+	}
+	sort.Strings(branches)
+	return fmt.Sprintf("%s|%s|%s|%d|%d|%s", localHead, originMainHead, localBranch, maxCommits, contextSize, strings.Join(branches, ";"))
+}
+
+// cachedGitGraph returns a copy of the cached graph if it was built from the same inputs.
+// The copy lets callers set per-request fields (DirtyState) without touching the cache.
+func (m *Manager) cachedGitGraph(workspaceID, key string) *contracts.GitGraphResponse {
+	m.gitGraphCacheMu.Lock()
+	defer m.gitGraphCacheMu.Unlock()
+	entry, ok := m.gitGraphCache[workspaceID]
+	if !ok || entry.key != key {
+		return nil
+	}
+	resp := *entry.resp
+	return &resp
+}
+
+// storeGitGraph caches a freshly built graph. The caller keeps resp; the cache holds a copy.
+func (m *Manager) storeGitGraph(workspaceID, key string, resp *contracts.GitGraphResponse) {
+	m.gitGraphCacheMu.Lock()
+	defer m.gitGraphCacheMu.Unlock()
+	if m.gitGraphCache == nil {
+		m.gitGraphCache = make(map[string]gitGraphCacheEntry)
+	}
+	cached := *resp
+	m.gitGraphCache[workspaceID] = gitGraphCacheEntry{key: key, resp: &cached}
+}
+
+// invalidateGitGraph drops the cached graph for a workspace.
+func (m *Manager) invalidateGitGraph(workspaceID string) {
+	m.gitGraphCacheMu.Lock()
+	defer m.gitGraphCacheMu.Unlock()
+	delete(m.gitGraphCache, workspaceID)
+}
+
+// GetRecentCommits returns the most recent commits reachable from the workspace HEAD,
+// newest first. Lighter than GetGitGraph: no origin comparison or branch annotations.
+func (m *Manager) GetRecentCommits(ctx context.Context, workspaceID string, limit int) (*contracts.WorkspaceCommitsResponse, error) {
+	if limit <= 0 {
+		limit = defaultRecentCommits
+	}
+	if limit > maxRecentCommits {
+		limit = maxRecentCommits
+	}
+
+	ws, ok := m.state.GetWorkspace(workspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+
+	// Subject goes last so a "|" inside it does not shift the other fields
+	cmd := exec.CommandContext(ctx, "git", "log",
+		"--format=%H|%h|%an|%aI|%s",
+		fmt.Sprintf("--max-count=%d", limit),
+		"HEAD",
+	)
+	cmd.Dir = ws.Path
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w: %s", err, string(output))
+	}
+
+	return &contracts.WorkspaceCommitsResponse{
+		Branch:  ws.Branch,
+		Commits: parseRecentCommits(string(output)),
+	}, nil
+}
+
+// parseRecentCommits parses "%H|%h|%an|%aI|%s" git log output.
+func parseRecentCommits(output string) []contracts.WorkspaceCommit {
+	commits := []contracts.WorkspaceCommit{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "|", 5)
+		if len(parts) < 5 {
+			continue
+		}
+		commits = append(commits, contracts.WorkspaceCommit{
+			Hash:      parts[0],
+			ShortHash: parts[1],
+			Author:    parts[2],
+			Timestamp: parts[3],
+			Subject:   parts[4],
+		})
+	}
+	return commits
 }
 
 // BuildGraphResponse builds a GitGraphResponse from raw nodes and branch metadata.
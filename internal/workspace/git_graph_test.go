@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/sergeknystautas/schmux/internal/api/contracts"
 	"github.com/sergeknystautas/schmux/internal/config"
 	"github.com/sergeknystautas/schmux/internal/state"
 )
@@ -529,3 +530,93 @@ func TestGitGraph_CancelledContext(t *testing.T) {
 		t.Fatal("expected error for cancelled context")
 	}
 }
+
+func TestGitGraph_Cache(t *testing.T) {
+	mgr, remoteDir, wsDir, wsID := setupWorkspaceGraphTest(t, "feature-a")
+	ctx := context.Background()
+	commitOnWorkspace(t, wsDir, "a1.txt", "feature-a commit 1")
+
+	first, err := mgr.GetGitGraph(ctx, wsID, 200, 5)
+	if err != nil {
+		t.Fatalf("GetGitGraph: %v", err)
+	}
+	// Per-request fields set by callers must not leak into the cache.
+	first.DirtyState = &contracts.GitGraphDirtyState{FilesChanged: 1}
+
+	second, err := mgr.GetGitGraph(ctx, wsID, 200, 5)
+	if err != nil {
+		t.Fatalf("GetGitGraph: %v", err)
+	}
+	if second.DirtyState != nil {
+		t.Error("expected cached graph without caller-set dirty state")
+	}
+	if len(second.Nodes) == 0 || &second.Nodes[0] != &first.Nodes[0] {
+		t.Error("expected unchanged inputs to be served from cache")
+	}
+
+	// A new local commit changes HEAD and must rebuild the graph.
+	commitOnWorkspace(t, wsDir, "a2.txt", "feature-a commit 2")
+	third, err := mgr.GetGitGraph(ctx, wsID, 200, 5)
+	if err != nil {
+		t.Fatalf("GetGitGraph: %v", err)
+	}
+	if got, want := third.Branches["feature-a"].Head, getHash(t, wsDir, "HEAD"); got != want {
+		t.Errorf("feature-a head = %s, want %s after new commit", got, want)
+	}
+
+	// So does origin/main moving.
+	commitOnRemote(t, remoteDir, wsDir, "m1.txt", "main commit 1")
+	fourth, err := mgr.GetGitGraph(ctx, wsID, 200, 5)
+	if err != nil {
+		t.Fatalf("GetGitGraph: %v", err)
+	}
+	if got, want := fourth.Branches["main"].Head, getHash(t, wsDir, "origin/main"); got != want {
+		t.Errorf("main head = %s, want %s after fetch", got, want)
+	}
+}
+
+func TestParseRecentCommits(t *testing.T) {
+	output := "aaa111|aaa|Alice|2025-01-01T00:00:00Z|first | with pipe\n" +
+		"bbb222|bbb|Bob|2025-01-02T00:00:00Z|second\n" +
+		"malformed line\n"
+	commits := parseRecentCommits(output)
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	if commits[0].Subject != "first | with pipe" || commits[0].Author != "Alice" {
+		t.Errorf("unexpected first commit: %+v", commits[0])
+	}
+	if commits[1].Hash != "bbb222" || commits[1].ShortHash != "bbb" {
+		t.Errorf("unexpected second commit: %+v", commits[1])
+	}
+	if got := parseRecentCommits(""); got == nil || len(got) != 0 {
+		t.Errorf("expected empty non-nil slice for empty output, got %#v", got)
+	}
+}
+
+func TestGetRecentCommits(t *testing.T) {
+	mgr, _, wsDir, wsID := setupWorkspaceGraphTest(t, "feature")
+	commitOnWorkspace(t, wsDir, "a.txt", "add a")
+	commitOnWorkspace(t, wsDir, "b.txt", "add b")
+
+	resp, err := mgr.GetRecentCommits(context.Background(), wsID, 2)
+	if err != nil {
+		t.Fatalf("GetRecentCommits failed: %v", err)
+	}
+	if resp.Branch != "feature" {
+		t.Errorf("expected branch feature, got %s", resp.Branch)
+	}
+	if len(resp.Commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(resp.Commits))
+	}
+	if resp.Commits[0].Subject != "add b" || resp.Commits[1].Subject != "add a" {
+		t.Errorf("expected newest first, got %q then %q", resp.Commits[0].Subject, resp.Commits[1].Subject)
+	}
+	if resp.Commits[0].Hash != getHash(t, wsDir, "HEAD") {
+		t.Errorf("expected first commit to be HEAD")
+	}
+
+	if _, err := mgr.GetRecentCommits(context.Background(), "missing", 5); err == nil {
+		t.Error("expected error for unknown workspace")
+	}
+}
@@ -0,0 +1,24 @@
+package workspace
+
+import "os"
+
+// Identity used for new local repos when sessions.git_author_name/email are unset.
+const (
+	defaultLocalRepoAuthorName  = "schmux"
+	defaultLocalRepoAuthorEmail = "schmux@localhost"
+)
+
+// gitIdentityEnv returns the environment for git commands that create commits on the
+// user's behalf. sessions.git_author_name/email override both author and committer;
+// when unset, git's own configuration applies. Rebases keep each commit's original
+// author, so only the committer changes there.
+func (m *Manager) gitIdentityEnv() []string {
+	env := os.Environ()
+	if name := m.config.GetGitAuthorName(); name != "" {
+		env = append(env, "GIT_AUTHOR_NAME="+name, "GIT_COMMITTER_NAME="+name)
+	}
+	if email := m.config.GetGitAuthorEmail(); email != "" {
+		env = append(env, "GIT_AUTHOR_EMAIL="+email, "GIT_COMMITTER_EMAIL="+email)
+	}
+	return env
+}
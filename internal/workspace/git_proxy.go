@@ -0,0 +1,41 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// gitCloneProxyArgs returns "git clone -c" options that apply sessions.git_http_proxy.
+// git clone writes these into the new repo's config before fetching, so the clone itself
+// and every later fetch from that repo (and its worktrees) go through the proxy.
+func (m *Manager) gitCloneProxyArgs() []string {
+	proxy := m.config.GetGitHTTPProxy()
+	if proxy == "" {
+		return nil
+	}
+	return []string{"-c", "http.proxy=" + proxy, "-c", "https.proxy=" + proxy}
+}
+
+// applyGitProxy sets http.proxy and https.proxy on an existing repo so repos cloned
+// before sessions.git_http_proxy was configured pick it up. No-op when unset.
+func (m *Manager) applyGitProxy(ctx context.Context, repoPath string) error {
+	proxy := m.config.GetGitHTTPProxy()
+	if proxy == "" {
+		return nil
+	}
+	for _, key := range []string{"http.proxy", "https.proxy"} {
+		getCmd := exec.CommandContext(ctx, "git", "config", "--local", "--get", key)
+		getCmd.Dir = repoPath
+		if output, err := getCmd.Output(); err == nil && strings.TrimSpace(string(output)) == proxy {
+			continue
+		}
+		setCmd := exec.CommandContext(ctx, "git", "config", "--local", key, proxy)
+		setCmd.Dir = repoPath
+		if output, err := setCmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git config %s failed: %w: %s", key, err, string(output))
+		}
+	}
+	return nil
+}
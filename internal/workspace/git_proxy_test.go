@@ -0,0 +1,46 @@
+package workspace
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestGitHTTPProxy(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	upstream := gitTestUpstream(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		Sessions:      &config.SessionsConfig{GitHTTPProxy: "http://proxy.example.com:3128"},
+	}
+	manager := New(cfg, state.New(statePath), statePath)
+	ctx := context.Background()
+
+	// New clones get the proxy in their repo config.
+	barePath := filepath.Join(t.TempDir(), "repo.git")
+	if err := manager.cloneBareRepo(ctx, upstream, barePath); err != nil {
+		t.Fatalf("cloneBareRepo() error: %v", err)
+	}
+	for _, key := range []string{"http.proxy", "https.proxy"} {
+		if got := strings.TrimSpace(gitOutput(t, barePath, "config", "--get", key)); got != "http://proxy.example.com:3128" {
+			t.Errorf("%s = %q, want proxy URL", key, got)
+		}
+	}
+
+	// Existing repos pick up a changed proxy.
+	cfg.Sessions.GitHTTPProxy = "http://other.example.com:8080"
+	if err := manager.applyGitProxy(ctx, barePath); err != nil {
+		t.Fatalf("applyGitProxy() error: %v", err)
+	}
+	if got := strings.TrimSpace(gitOutput(t, barePath, "config", "--get", "https.proxy")); got != "http://other.example.com:8080" {
+		t.Errorf("https.proxy = %q, want updated proxy URL", got)
+	}
+}
@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrCommitSigningNotConfigured is returned when sessions.git_sign_commits is on but
+// git has no way to sign commits.
+var ErrCommitSigningNotConfigured = errors.New("commit signing is not configured")
+
+// gitSignArgs returns the options that sign the commits schmux makes in dir (local
+// repo init, linear-sync WIP commits, squash and rebase) when sessions.git_sign_commits
+// is on. It checks signing is set up first, so a missing key fails before the
+// operation changes anything rather than halfway through a rebase.
+func (m *Manager) gitSignArgs(ctx context.Context, dir string) ([]string, error) {
+	if !m.config.GetGitSignCommits() {
+		return nil, nil
+	}
+	key := m.config.GetGitSigningKey()
+	if err := CheckCommitSigning(ctx, dir, key); err != nil {
+		return nil, err
+	}
+	if key == "" {
+		return []string{"--gpg-sign"}, nil
+	}
+	return []string{"--gpg-sign=" + key}, nil
+}
+
+// CheckCommitSigning reports whether git in dir can sign commits with key, or with
+// user.signingkey when key is empty: the signing program for gpg.format must be
+// installed, and SSH signing needs a key. dir "" checks the global git config.
+func CheckCommitSigning(ctx context.Context, dir, key string) error {
+	format := gitConfigValue(ctx, dir, "gpg.format")
+	if format == "" {
+		format = "openpgp"
+	}
+	if key == "" {
+		key = gitConfigValue(ctx, dir, "user.signingkey")
+	}
+	program := gitConfigValue(ctx, dir, "gpg."+format+".program")
+	switch format {
+	case "openpgp":
+		if program == "" {
+			program = gitConfigValue(ctx, dir, "gpg.program")
+		}
+		if program == "" {
+			program = "gpg"
+		}
+	case "x509":
+		if program == "" {
+			program = "gpgsm"
+		}
+	case "ssh":
+		if program == "" {
+			program = "ssh-keygen"
+		}
+		if key == "" && gitConfigValue(ctx, dir, "gpg.ssh.defaultKeyCommand") == "" {
+			return fmt.Errorf("%w: gpg.format is ssh but no signing key is set (sessions.git_signing_key or user.signingkey)", ErrCommitSigningNotConfigured)
+		}
+	default:
+		return fmt.Errorf("%w: unknown gpg.format %q", ErrCommitSigningNotConfigured, format)
+	}
+	if _, err := exec.LookPath(program); err != nil {
+		return fmt.Errorf("%w: %s signing program %q not found", ErrCommitSigningNotConfigured, format, program)
+	}
+	return nil
+}
+
+// gitConfigValue returns a git config value as seen from dir, or "" if unset.
+func gitConfigValue(ctx context.Context, dir, key string) string {
+	args := []string{"config", "--get", key}
+	if dir == "" {
+		args = []string{"config", "--global", "--get", key}
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
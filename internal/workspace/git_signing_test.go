@@ -0,0 +1,52 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestGitSignArgs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := gitTestWorkTree(t)
+	// "true" stands in for ssh-keygen so the check doesn't depend on the host.
+	runGit(t, repo, "config", "gpg.format", "ssh")
+	runGit(t, repo, "config", "gpg.ssh.program", "true")
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	cfg := &config.Config{WorkspacePath: t.TempDir(), Sessions: &config.SessionsConfig{}}
+	manager := New(cfg, state.New(statePath), statePath)
+	ctx := context.Background()
+
+	if args, err := manager.gitSignArgs(ctx, repo); err != nil || args != nil {
+		t.Fatalf("signing off: gitSignArgs() = %v, %v; want nil, nil", args, err)
+	}
+
+	cfg.Sessions.GitSignCommits = true
+	if _, err := manager.gitSignArgs(ctx, repo); !errors.Is(err, ErrCommitSigningNotConfigured) {
+		t.Fatalf("ssh without key: gitSignArgs() error = %v, want ErrCommitSigningNotConfigured", err)
+	}
+
+	runGit(t, repo, "config", "user.signingkey", "/keys/id_git")
+	if args, err := manager.gitSignArgs(ctx, repo); err != nil || !reflect.DeepEqual(args, []string{"--gpg-sign"}) {
+		t.Errorf("user.signingkey: gitSignArgs() = %v, %v; want [--gpg-sign]", args, err)
+	}
+
+	cfg.Sessions.GitSigningKey = "/keys/id_schmux"
+	if args, err := manager.gitSignArgs(ctx, repo); err != nil || !reflect.DeepEqual(args, []string{"--gpg-sign=/keys/id_schmux"}) {
+		t.Errorf("git_signing_key: gitSignArgs() = %v, %v; want [--gpg-sign=/keys/id_schmux]", args, err)
+	}
+
+	runGit(t, repo, "config", "gpg.ssh.program", "schmux-no-such-signer")
+	if _, err := manager.gitSignArgs(ctx, repo); !errors.Is(err, ErrCommitSigningNotConfigured) {
+		t.Errorf("missing program: gitSignArgs() error = %v, want ErrCommitSigningNotConfigured", err)
+	}
+}
@@ -0,0 +1,38 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// gitCloneConfigArgs returns the "git clone -c" options for a repo: the HTTP proxy
+// plus the repo's ssh_key_path, if any.
+func (m *Manager) gitCloneConfigArgs(repoURL string) []string {
+	args := m.gitCloneProxyArgs()
+	if sshCommand := m.config.GetRepoSSHCommand(repoURL); sshCommand != "" {
+		args = append(args, "-c", "core.sshCommand="+sshCommand)
+	}
+	return args
+}
+
+// applyGitSSHCommand sets core.sshCommand on an existing repo so repos cloned before
+// the repo's ssh_key_path was configured fetch with that key. No-op when unset.
+func (m *Manager) applyGitSSHCommand(ctx context.Context, repoPath, repoURL string) error {
+	sshCommand := m.config.GetRepoSSHCommand(repoURL)
+	if sshCommand == "" {
+		return nil
+	}
+	getCmd := exec.CommandContext(ctx, "git", "config", "--local", "--get", "core.sshCommand")
+	getCmd.Dir = repoPath
+	if output, err := getCmd.Output(); err == nil && strings.TrimSpace(string(output)) == sshCommand {
+		return nil
+	}
+	setCmd := exec.CommandContext(ctx, "git", "config", "--local", "core.sshCommand", sshCommand)
+	setCmd.Dir = repoPath
+	if output, err := setCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git config core.sshCommand failed: %w: %s", err, string(output))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package workspace
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestGitSSHCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	upstream := gitTestUpstream(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		Repos:         []config.Repo{{Name: "test", URL: upstream, SSHKeyPath: "/keys/id_deploy"}},
+	}
+	manager := New(cfg, state.New(statePath), statePath)
+	ctx := context.Background()
+
+	// New clones record the repo's key in core.sshCommand.
+	barePath := filepath.Join(t.TempDir(), "repo.git")
+	if err := manager.cloneBareRepo(ctx, upstream, barePath); err != nil {
+		t.Fatalf("cloneBareRepo() error: %v", err)
+	}
+	want := "ssh -i '/keys/id_deploy' -o IdentitiesOnly=yes"
+	if got := strings.TrimSpace(gitOutput(t, barePath, "config", "--get", "core.sshCommand")); got != want {
+		t.Errorf("core.sshCommand = %q, want %q", got, want)
+	}
+
+	// Existing repos pick up a changed key.
+	cfg.Repos[0].SSHKeyPath = "/keys/id_other"
+	if err := manager.applyGitSSHCommand(ctx, barePath, upstream); err != nil {
+		t.Fatalf("applyGitSSHCommand() error: %v", err)
+	}
+	if got := strings.TrimSpace(gitOutput(t, barePath, "config", "--get", "core.sshCommand")); !strings.Contains(got, "/keys/id_other") {
+		t.Errorf("core.sshCommand = %q, want updated key", got)
+	}
+}
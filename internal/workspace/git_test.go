@@ -2,6 +2,7 @@ package workspace
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -350,6 +351,44 @@ func TestGitPullRebase_WithBranchParameter(t *testing.T) {
 	t.Log("gitPullRebase() takes branch parameter - explicitly pulls from origin/<branch>")
 }
 
+func TestPullRebaseOrAbort_Conflict(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	remoteDir := gitTestWorkTree(t)
+	tmpDir := t.TempDir()
+	cloneDir := filepath.Join(tmpDir, "clone")
+	runGit(t, tmpDir, "clone", remoteDir, "clone")
+	runGit(t, cloneDir, "config", "user.email", "test@test.com")
+	runGit(t, cloneDir, "config", "user.name", "Test User")
+
+	// Diverge: both sides change README.md.
+	writeFile(t, remoteDir, "README.md", "remote change")
+	runGit(t, remoteDir, "commit", "-am", "remote")
+	writeFile(t, cloneDir, "README.md", "local change")
+	runGit(t, cloneDir, "commit", "-am", "local")
+	localHead := getHash(t, cloneDir, "HEAD")
+
+	statePath := filepath.Join(tmpDir, "state.json")
+	m := New(&config.Config{WorkspacePath: tmpDir}, state.New(statePath), statePath)
+
+	err := m.pullRebaseOrAbort(context.Background(), "ws-001", cloneDir, "main")
+	var conflictErr *PullConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("expected PullConflictError, got %v", err)
+	}
+	if len(conflictErr.ConflictedFiles) != 1 || conflictErr.ConflictedFiles[0] != "README.md" {
+		t.Errorf("ConflictedFiles = %v, want [README.md]", conflictErr.ConflictedFiles)
+	}
+	if rebaseInProgress(cloneDir) {
+		t.Error("expected rebase to be aborted")
+	}
+	if got := getHash(t, cloneDir, "HEAD"); got != localHead {
+		t.Errorf("HEAD = %s, want pre-pull %s", got, localHead)
+	}
+}
+
 func TestGitRemoteBranchExists(t *testing.T) {
 	if _, err := exec.LookPath("git"); err != nil {
 		t.Skip("git not available")
@@ -396,3 +435,50 @@ func TestGitRemoteBranchExists(t *testing.T) {
 		t.Error("gitRemoteBranchExists(missing-branch) expected false")
 	}
 }
+
+func TestCheckGitSafety_DisposeIgnoreGlobs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repoDir := gitTestWorkTree(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	st.AddWorkspace(state.Workspace{ID: "test-001", Repo: "test", Branch: "main", Path: repoDir})
+	cfg := &config.Config{WorkspacePath: t.TempDir()}
+	m := New(cfg, st, statePath)
+	ctx := context.Background()
+
+	// An edited tracked file and untracked files nested in a directory.
+	writeFile(t, repoDir, "README.md", "edited")
+	if err := os.MkdirAll(filepath.Join(repoDir, "tmp", "cache"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, repoDir, filepath.Join("tmp", "cache", "blob"), "x")
+	writeFile(t, repoDir, ".env", "SECRET=1")
+
+	status, err := m.checkGitSafety(ctx, "test-001")
+	if err != nil {
+		t.Fatalf("checkGitSafety failed: %v", err)
+	}
+	if status.Safe || status.ModifiedFiles != 1 || status.UntrackedFiles != 2 {
+		t.Fatalf("expected unsafe with 1 modified and 2 untracked, got %+v", status)
+	}
+
+	cfg.Sessions = &config.SessionsConfig{DisposeIgnoreGlobs: []string{".env", "tmp/**"}}
+	status, err = m.checkGitSafety(ctx, "test-001")
+	if err != nil {
+		t.Fatalf("checkGitSafety failed: %v", err)
+	}
+	if status.Safe || status.ModifiedFiles != 1 || status.UntrackedFiles != 0 {
+		t.Fatalf("expected only README.md to count, got %+v", status)
+	}
+
+	cfg.Sessions.DisposeIgnoreGlobs = append(cfg.Sessions.DisposeIgnoreGlobs, "README.md")
+	status, err = m.checkGitSafety(ctx, "test-001")
+	if err != nil {
+		t.Fatalf("checkGitSafety failed: %v", err)
+	}
+	if !status.Safe {
+		t.Fatalf("expected safe when all changes are ignored, got %+v", status)
+	}
+}
@@ -235,6 +235,10 @@ func (gw *GitWatcher) refreshWorkspace(workspaceID string) {
 		gw.onRefresh(workspaceID)
 		return
 	}
+	// Changes made during maintenance are picked up by the refresh when it ends
+	if gw.mgr.InMaintenance() {
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), gw.cfg.GitStatusTimeout())
 	defer cancel()
@@ -5,9 +5,123 @@ import (
 	"fmt"
 	"net/url"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
+// knownForgeHosts are hosts whose repo URLs follow a fixed owner/repo layout,
+// so web UI URLs (e.g. .../tree/main) can be mapped back to a clone URL.
+var knownForgeHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"bitbucket.org": true,
+}
+
+// NormalizeRepoURL validates a repo URL from config and returns its canonical form.
+// Known forge URLs (GitHub, GitLab, Bitbucket) are canonicalized to
+// https://host/owner/repo.git or git@host:owner/repo.git, including URLs copied
+// from the web UI. Other ssh://, git://, file://, http(s)://, scp-style, and
+// absolute-path URLs are accepted as-is. "local:" URLs are returned unchanged.
+func NormalizeRepoURL(raw string) (string, error) {
+	repoURL := strings.TrimSpace(raw)
+	if repoURL == "" {
+		return "", fmt.Errorf("URL is empty")
+	}
+	if strings.HasPrefix(repoURL, "local:") {
+		return repoURL, nil
+	}
+	if strings.ContainsAny(repoURL, " \t\n") {
+		return "", fmt.Errorf("URL %q contains whitespace", repoURL)
+	}
+
+	// Absolute paths to local repositories
+	if filepath.IsAbs(repoURL) {
+		return repoURL, nil
+	}
+
+	if !strings.Contains(repoURL, "://") {
+		// scp-style SSH: [user@]host:path
+		if at, colon := strings.Index(repoURL, "@"), strings.Index(repoURL, ":"); colon > 0 && at < colon {
+			host := repoURL[at+1 : colon]
+			path := repoURL[colon+1:]
+			if host == "" || path == "" {
+				return "", fmt.Errorf("URL %q is not a valid SSH repo URL", repoURL)
+			}
+			if !knownForgeHosts[host] {
+				return repoURL, nil
+			}
+			repoPath, err := forgeRepoPath(host, path)
+			if err != nil {
+				return "", fmt.Errorf("URL %q: %w", repoURL, err)
+			}
+			return fmt.Sprintf("%s:%s.git", repoURL[:colon], repoPath), nil
+		}
+		// Bare forge URL without a scheme (github.com/owner/repo)
+		if host, _, ok := strings.Cut(repoURL, "/"); ok && knownForgeHosts[host] {
+			repoURL = "https://" + repoURL
+		} else {
+			return "", fmt.Errorf("URL %q is not a recognized git URL (expected https://, ssh://, git@host:owner/repo, or an absolute path)", repoURL)
+		}
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("URL %q: %w", repoURL, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "ssh", "git", "file":
+		return strings.TrimSuffix(repoURL, "/"), nil
+	default:
+		return "", fmt.Errorf("URL %q has unsupported scheme %q", repoURL, u.Scheme)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URL %q is missing a host", repoURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if !knownForgeHosts[host] {
+		if strings.Trim(u.Path, "/") == "" {
+			return "", fmt.Errorf("URL %q is missing a repository path", repoURL)
+		}
+		return strings.TrimSuffix(repoURL, "/"), nil
+	}
+	repoPath, err := forgeRepoPath(host, u.Path)
+	if err != nil {
+		return "", fmt.Errorf("URL %q: %w", repoURL, err)
+	}
+	// Keep credentials or a custom port if the user supplied them.
+	canonical := url.URL{Scheme: "https", User: u.User, Host: u.Host, Path: "/" + repoPath + ".git"}
+	return canonical.String(), nil
+}
+
+// forgeRepoPath extracts "owner/repo" (or "group/subgroup/repo" for GitLab) from a
+// forge URL path, dropping any web UI suffix and the .git extension.
+func forgeRepoPath(host, path string) (string, error) {
+	path = strings.Trim(path, "/")
+	if host == "gitlab.com" {
+		// GitLab separates the project path from UI routes with "/-/" and allows subgroups.
+		if idx := strings.Index(path, "/-/"); idx >= 0 {
+			path = path[:idx]
+		}
+	} else {
+		parts := strings.SplitN(path, "/", 3)
+		if len(parts) > 2 {
+			path = parts[0] + "/" + parts[1]
+		}
+	}
+	path = strings.TrimSuffix(path, ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("expected %s/<owner>/<repo>", host)
+	}
+	for _, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("expected %s/<owner>/<repo>", host)
+		}
+	}
+	return path, nil
+}
+
 // BuildGitBranchURL constructs a web URL for viewing a git branch.
 // Returns nil if the repo URL or branch is empty, or if the URL cannot be parsed.
 // Supports GitHub, GitLab, Bitbucket, and a generic fallback pattern.
@@ -194,3 +194,50 @@ func TestRemoteBranchExists(t *testing.T) {
 		t.Error("RemoteBranchExists() returned true for non-existent branch")
 	}
 }
+
+func TestNormalizeRepoURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "GitHub HTTPS canonical", input: "https://github.com/user/repo.git", want: "https://github.com/user/repo.git"},
+		{name: "GitHub HTTPS without .git", input: "https://github.com/user/repo", want: "https://github.com/user/repo.git"},
+		{name: "GitHub web UI URL", input: "https://github.com/user/repo/tree/main/src", want: "https://github.com/user/repo.git"},
+		{name: "GitHub trailing slash and whitespace", input: "  https://github.com/user/repo/ ", want: "https://github.com/user/repo.git"},
+		{name: "GitHub without scheme", input: "github.com/user/repo", want: "https://github.com/user/repo.git"},
+		{name: "GitHub SSH", input: "git@github.com:user/repo", want: "git@github.com:user/repo.git"},
+		{name: "GitLab subgroup web UI URL", input: "https://gitlab.com/group/sub/repo/-/merge_requests/3", want: "https://gitlab.com/group/sub/repo.git"},
+		{name: "Bitbucket", input: "https://bitbucket.org/team/repo/src/main/", want: "https://bitbucket.org/team/repo.git"},
+		{name: "Self-hosted HTTPS unchanged", input: "https://git.example.com/team/repo", want: "https://git.example.com/team/repo"},
+		{name: "Self-hosted SSH unchanged", input: "git@git.example.com:team/repo.git", want: "git@git.example.com:team/repo.git"},
+		{name: "ssh scheme", input: "ssh://git@example.com:2222/repo.git", want: "ssh://git@example.com:2222/repo.git"},
+		{name: "Absolute path", input: "/srv/git/repo.git", want: "/srv/git/repo.git"},
+		{name: "local prefix untouched", input: "local:myproject", want: "local:myproject"},
+		{name: "Empty", input: "  ", wantErr: true},
+		{name: "GitHub owner only", input: "https://github.com/user", wantErr: true},
+		{name: "Unsupported scheme", input: "ftp://example.com/repo.git", wantErr: true},
+		{name: "Not a URL", input: "my repo", wantErr: true},
+		{name: "Relative path", input: "repos/myrepo", wantErr: true},
+		{name: "Missing path", input: "https://git.example.com/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeRepoURL(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeRepoURL(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeRepoURL(%q) error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeRepoURL(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// ErrInvalidImport is returned when a directory can't be imported as a workspace.
+var ErrInvalidImport = errors.New("invalid import")
+
+// Import registers an existing local checkout as a workspace without cloning it.
+// The repo is taken from the checkout's origin remote, which must be a configured
+// repo, and the branch from its HEAD. The directory stays where it is: it is never
+// reused for other spawns, moved by migration, or deleted on dispose.
+func (m *Manager) Import(ctx context.Context, path string, applyOverlay bool) (*state.Workspace, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalidImport)
+	}
+	if strings.HasPrefix(path, "~") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+		}
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrInvalidImport, absPath)
+	}
+
+	// Must be the top level of a work tree, not a subdirectory of one or a bare repo
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel")
+	cmd.Dir = absPath
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s is not a git repository", ErrInvalidImport, absPath)
+	}
+	topLevel, _ := filepath.EvalSymlinks(strings.TrimSpace(string(output)))
+	if resolved, _ := filepath.EvalSymlinks(absPath); topLevel != resolved {
+		return nil, fmt.Errorf("%w: %s is inside the repository at %s; import its root instead", ErrInvalidImport, absPath, topLevel)
+	}
+
+	repoURL, err := m.gitGetRemoteURL(absPath)
+	if err != nil || repoURL == "" {
+		return nil, fmt.Errorf("%w: %s has no origin remote", ErrInvalidImport, absPath)
+	}
+	repoConfig, found := m.findRepoByURL(repoURL)
+	if !found {
+		return nil, fmt.Errorf("%w: origin %s is not a configured repo; add it to repos first", ErrInvalidImport, repoURL)
+	}
+	branch, err := m.gitGetCurrentBranch(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	lock := m.repoLock(repoURL)
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, w := range m.state.GetWorkspaces() {
+		if !w.IsRemoteWorkspace() && filepath.Clean(w.Path) == absPath {
+			return nil, fmt.Errorf("%w: %s is already workspace %s", ErrInvalidImport, absPath, w.ID)
+		}
+	}
+
+	workspaces := m.getWorkspacesForRepo(repoURL)
+	if err := m.checkWorkspaceLimit(repoConfig.Name, workspaces); err != nil {
+		return nil, err
+	}
+	workspaceID := fmt.Sprintf("%s-"+workspaceNumberFormat, repoConfig.Name, findNextWorkspaceNumber(workspaces))
+
+	if applyOverlay {
+		if err := m.copyOverlayFiles(ctx, repoConfig.Name, absPath); err != nil {
+			fmt.Printf("[workspace] warning: failed to copy overlay files: %v\n", err)
+		}
+	}
+
+	w := state.Workspace{
+		ID:       workspaceID,
+		Repo:     repoURL,
+		Branch:   branch,
+		Path:     absPath,
+		Imported: true,
+	}
+	if err := m.state.AddWorkspace(w); err != nil {
+		return nil, fmt.Errorf("failed to add workspace to state: %w", err)
+	}
+	if err := m.state.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save state: %w", err)
+	}
+
+	if m.gitWatcher != nil {
+		m.gitWatcher.AddWorkspace(w.ID, w.Path)
+	}
+
+	fmt.Printf("[workspace] imported: id=%s path=%s branch=%s repo=%s\n", w.ID, w.Path, w.Branch, repoURL)
+	return &w, nil
+}
@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestImport(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repoURL := "https://example.com/test.git"
+	checkout := gitTestWorkTree(t)
+	runGit(t, checkout, "remote", "add", "origin", repoURL)
+	runGit(t, checkout, "checkout", "-b", "feature")
+	if err := os.Mkdir(filepath.Join(checkout, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{
+		WorkspacePath: t.TempDir(),
+		Repos:         []config.Repo{{Name: "test", URL: repoURL}},
+	}
+	m := New(cfg, st, statePath)
+	ctx := context.Background()
+
+	for name, path := range map[string]string{
+		"empty":        "",
+		"missing":      filepath.Join(checkout, "missing"),
+		"subdirectory": filepath.Join(checkout, "sub"),
+		"not a repo":   t.TempDir(),
+	} {
+		if _, err := m.Import(ctx, path, false); !errors.Is(err, ErrInvalidImport) {
+			t.Errorf("Import(%s) error = %v, want ErrInvalidImport", name, err)
+		}
+	}
+
+	ws, err := m.Import(ctx, checkout, false)
+	if err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+	if ws.ID != "test-001" || ws.Repo != repoURL || ws.Branch != "feature" || ws.Path != checkout || !ws.Imported {
+		t.Errorf("Import() = %+v", ws)
+	}
+	if _, err := m.Import(ctx, checkout, false); !errors.Is(err, ErrInvalidImport) {
+		t.Errorf("second Import() error = %v, want ErrInvalidImport", err)
+	}
+
+	// The checkout lives outside workspace_path, so a scan must not drop it
+	result, err := m.Scan()
+	if err != nil {
+		t.Fatalf("Scan() error: %v", err)
+	}
+	if len(result.Removed) != 0 {
+		t.Errorf("Scan() removed %+v", result.Removed)
+	}
+
+	if err := m.Dispose(ws.ID); err != nil {
+		t.Fatalf("Dispose() error: %v", err)
+	}
+	if _, found := st.GetWorkspace(ws.ID); found {
+		t.Error("workspace still in state after dispose")
+	}
+	if _, err := os.Stat(filepath.Join(checkout, "README.md")); err != nil {
+		t.Errorf("dispose touched the imported checkout: %v", err)
+	}
+}
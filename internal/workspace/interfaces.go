@@ -30,6 +30,42 @@ type RecentBranch struct {
 	Subject    string `json:"subject"`
 }
 
+// BranchExistence reports where a branch already exists, so a spawn can tell
+// whether it will check out an existing branch or create a new one.
+type BranchExistence struct {
+	ExistsRemote bool `json:"exists_remote"` // origin/<branch> is known from the last fetch
+	ExistsLocal  bool `json:"exists_local"`  // a workspace or the worktree base already has the branch
+}
+
+// Overlay refresh statuses reported per workspace by RefreshRepoOverlay.
+const (
+	OverlayRefreshStatusRefreshed = "refreshed"
+	OverlayRefreshStatusSkipped   = "skipped"
+	OverlayRefreshStatusFailed    = "failed"
+)
+
+// OverlayRefreshResult is the outcome of refreshing overlay files for one workspace.
+type OverlayRefreshResult struct {
+	WorkspaceID string `json:"workspace_id"`
+	Status      string `json:"status"`           // refreshed, skipped, or failed
+	Reason      string `json:"reason,omitempty"` // why the workspace was skipped or failed
+}
+
+// Base repo prune statuses reported per base repo by PruneBaseRepos.
+const (
+	BaseRepoPruneStatusPruned  = "pruned"
+	BaseRepoPruneStatusSkipped = "skipped"
+	BaseRepoPruneStatusFailed  = "failed"
+)
+
+// BaseRepoPruneResult is the outcome of pruning one unused base repo.
+type BaseRepoPruneResult struct {
+	RepoURL string `json:"repo_url"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`           // pruned, skipped, or failed
+	Reason  string `json:"reason,omitempty"` // why the base repo was skipped or failed
+}
+
 // LinearSyncResult represents the result of a linear sync operation (from or to main).
 type LinearSyncResult struct {
 	Success         bool   `json:"success"`
@@ -46,6 +82,9 @@ type ConflictResolution struct {
 	Confidence         string   `json:"confidence"`
 	Summary            string   `json:"summary"`
 	Files              []string `json:"files"`
+	// NeedsReview is set on a resolution below conflict_resolve.min_confidence that
+	// was left uncommitted for the user to review.
+	NeedsReview bool `json:"needs_review,omitempty"`
 }
 
 // LinearSyncResolveConflictResult contains the result of a conflict resolution rebase.
@@ -54,12 +93,15 @@ type LinearSyncResolveConflictResult struct {
 	Message     string               `json:"message"`
 	Hash        string               `json:"hash,omitempty"`
 	Resolutions []ConflictResolution `json:"resolutions"`
+	// NeedsReview is set when the rebase was left paused at a resolution that needs
+	// review. Success is false.
+	NeedsReview bool `json:"needs_review,omitempty"`
 }
 
 // ResolveConflictStep represents a progress step emitted during conflict resolution.
 type ResolveConflictStep struct {
 	Action             string   `json:"action"`
-	Status             string   `json:"status"` // "in_progress", "done", "failed"
+	Status             string   `json:"status"` // "in_progress", "done", "failed", "needs_review"
 	Message            string   `json:"message"`
 	Hash               string   `json:"hash,omitempty"`
 	LocalCommit        string   `json:"local_commit,omitempty"`
@@ -100,6 +142,12 @@ type WorkspaceManager interface {
 	// UpdateAllGitStatus refreshes git status for all workspaces.
 	UpdateAllGitStatus(ctx context.Context)
 
+	// Maintenance returns the current maintenance mode state.
+	Maintenance() Maintenance
+
+	// SetMaintenance turns maintenance mode, which pauses background git work, on or off.
+	SetMaintenance(enabled, blockSpawns bool) Maintenance
+
 	// EnsureWorkspaceDir ensures the workspace base directory exists.
 	EnsureWorkspaceDir() error
 
@@ -110,15 +158,34 @@ type WorkspaceManager interface {
 	// Returns what was added, updated, and removed.
 	Scan() (ScanResult, error)
 
+	// Import registers an existing local checkout as a workspace without cloning it,
+	// optionally applying the repo's overlay files.
+	Import(ctx context.Context, path string, applyOverlay bool) (*state.Workspace, error)
+
 	// RefreshOverlay reapplies overlay files to an existing workspace.
 	RefreshOverlay(ctx context.Context, workspaceID string) error
 
+	// RefreshRepoOverlay reapplies overlay files to every workspace of the named repo,
+	// skipping workspaces with active sessions.
+	RefreshRepoOverlay(ctx context.Context, repoName string) ([]OverlayRefreshResult, error)
+
+	// PruneBaseRepos removes base repos that no workspace uses and whose repo is
+	// no longer configured.
+	PruneBaseRepos(ctx context.Context) ([]BaseRepoPruneResult, error)
+
+	// MigrateWorkspaces moves idle local workspaces outside workspace_path into it.
+	MigrateWorkspaces(ctx context.Context) ([]MigrateResult, error)
+
 	// EnsureOverlayDirs ensures overlay directories exist for all configured repos.
 	EnsureOverlayDirs(repos []config.Repo) error
 
 	// GetWorkspaceConfig returns the cached workspace config for the given workspace ID.
 	GetWorkspaceConfig(workspaceID string) *contracts.RepoConfig
 
+	// LoadWorkspaceEnv returns the variables from the workspace's env file
+	// (sessions.workspace_env_file), or nil if the file doesn't exist.
+	LoadWorkspaceEnv(w *state.Workspace) (map[string]string, error)
+
 	// CreateLocalRepo creates a new workspace with a fresh local git repository.
 	CreateLocalRepo(ctx context.Context, repoName, branch string) (*state.Workspace, error)
 
@@ -152,6 +219,9 @@ type WorkspaceManager interface {
 	// GetRecentBranches returns recent branches from all bare clones, sorted by commit date.
 	GetRecentBranches(ctx context.Context, limit int) ([]RecentBranch, error)
 
+	// BranchExists reports whether a branch exists on origin and locally for a repo.
+	BranchExists(ctx context.Context, repoURL, branch string) (*BranchExistence, error)
+
 	// GetBranchCommitLog returns commit subjects for a branch relative to the default branch.
 	GetBranchCommitLog(ctx context.Context, repoURL, branch string, limit int) ([]string, error)
 
@@ -160,6 +230,19 @@ type WorkspaceManager interface {
 
 	// GetGitGraph returns the commit graph for a workspace showing local branch vs origin/main.
 	GetGitGraph(ctx context.Context, workspaceID string, maxCommits int, contextSize int) (*contracts.GitGraphResponse, error)
+
+	// GetRecentCommits returns the most recent commits on a workspace's checked-out branch.
+	GetRecentCommits(ctx context.Context, workspaceID string, limit int) (*contracts.WorkspaceCommitsResponse, error)
+
+	// ListStashes returns a workspace's git stashes, newest first.
+	ListStashes(ctx context.Context, workspaceID string) ([]Stash, error)
+
+	// ApplyStash applies a workspace's stash@{index}, dropping it afterwards when pop is true.
+	ApplyStash(ctx context.Context, workspaceID string, index int, pop bool) error
+
+	// Fork creates a new workspace on a new branch from another workspace's current HEAD,
+	// optionally carrying over its uncommitted changes.
+	Fork(ctx context.Context, sourceWorkspaceID, newBranch string, copyUncommitted bool) (*state.Workspace, error)
 }
 
 // Ensure *Manager implements WorkspaceManager at compile time.
@@ -1,11 +1,13 @@
 package workspace
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,11 +32,13 @@ func (m *Manager) LinearSyncFromDefault(ctx context.Context, workspaceID string)
 	workspacePath := w.Path
 	defaultRef := "origin/" + defaultBranch
 
-	// 1. git fetch origin
-	fetchCmd := exec.CommandContext(ctx, "git", "fetch", "origin")
-	fetchCmd.Dir = workspacePath
-	if output, err := fetchCmd.CombinedOutput(); err != nil {
-		return nil, fmt.Errorf("git fetch origin failed: %w: %s", err, string(output))
+	// 1. git fetch origin (offline: rebase onto the last fetched origin ref)
+	if !m.config.GetOffline() {
+		fetchCmd := exec.CommandContext(ctx, "git", "fetch", "origin")
+		fetchCmd.Dir = workspacePath
+		if output, err := fetchCmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git fetch origin failed: %w: %s", err, string(output))
+		}
 	}
 
 	// 2. Check if default branch is already an ancestor of HEAD (nothing to pull)
@@ -89,6 +93,11 @@ func (m *Manager) LinearSyncFromDefault(ctx context.Context, workspaceID string)
 		}, nil
 	}
 
+	signArgs, err := m.gitSignArgs(ctx, workspacePath)
+	if err != nil {
+		return nil, err
+	}
+
 	// 5. git add -A + git commit -m "WIP: <UUID>" to save local changes (including untracked files)
 	addCmd := exec.CommandContext(ctx, "git", "add", "-A")
 	addCmd.Dir = workspacePath
@@ -97,8 +106,9 @@ func (m *Manager) LinearSyncFromDefault(ctx context.Context, workspaceID string)
 	}
 
 	wipUUID := fmt.Sprintf("WIP: %d", time.Now().UnixNano())
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", wipUUID)
+	commitCmd := exec.CommandContext(ctx, "git", append(append([]string{"commit"}, signArgs...), "-m", wipUUID)...)
 	commitCmd.Dir = workspacePath
+	commitCmd.Env = m.gitIdentityEnv()
 	commitOutput, err := commitCmd.CombinedOutput()
 	didCommit := true
 	if err != nil {
@@ -115,8 +125,9 @@ func (m *Manager) LinearSyncFromDefault(ctx context.Context, workspaceID string)
 	// 6. For each commit hash: git rebase <hash>
 	successCount := 0
 	for i, hash := range commitHashes {
-		rebaseCmd := exec.CommandContext(ctx, "git", "rebase", hash)
+		rebaseCmd := exec.CommandContext(ctx, "git", append(append([]string{"rebase"}, signArgs...), hash)...)
 		rebaseCmd.Dir = workspacePath
+		rebaseCmd.Env = m.gitIdentityEnv()
 		if err := rebaseCmd.Run(); err != nil {
 			// Conflict occurred
 			// git rebase --abort
@@ -168,6 +179,9 @@ func (m *Manager) LinearSyncToDefault(ctx context.Context, workspaceID string) (
 	if !found {
 		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
 	}
+	if m.config.GetOffline() {
+		return nil, fmt.Errorf("cannot push to the default branch: %w", ErrOffline)
+	}
 
 	// Get the default branch
 	defaultBranch, err := m.GetDefaultBranch(ctx, w.Repo)
@@ -248,8 +262,24 @@ func (m *Manager) LinearSyncToDefault(ctx context.Context, workspaceID string) (
 	}
 	fmt.Printf("[workspace] linear-sync-to-default: workspace_id=%s current_branch=%s\n", workspaceID, currentBranch)
 
-	// 5. Push to default branch
-	if currentBranch == defaultBranch {
+	// 5. Squash into one commit when a sync commit template is configured. The
+	// commit is built off to the side and the branch only moves to it once the
+	// push has succeeded, so a failed push leaves the user's commits untouched.
+	squashCommit := ""
+	if tmpl := m.config.GetSyncCommitTemplate(); tmpl != "" {
+		fmt.Printf("[workspace] linear-sync-to-default: workspace_id=%s squashing %d commits\n", workspaceID, ahead)
+		signArgs, err := m.gitSignArgs(ctx, workspacePath)
+		if err != nil {
+			return nil, err
+		}
+		squashCommit, err = squashForSync(ctx, workspacePath, defaultRef, tmpl, currentBranch, defaultBranch, m.gitIdentityEnv(), signArgs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 6. Push to default branch
+	if currentBranch == defaultBranch && squashCommit == "" {
 		// On default branch: simple push
 		fmt.Printf("[workspace] linear-sync-to-default: workspace_id=%s pushing from %s\n", workspaceID, defaultBranch)
 		pushCmd := exec.CommandContext(ctx, "git", "push")
@@ -257,6 +287,17 @@ func (m *Manager) LinearSyncToDefault(ctx context.Context, workspaceID string) (
 		if output, err := pushCmd.CombinedOutput(); err != nil {
 			return nil, fmt.Errorf("git push failed: %w: %s", err, string(output))
 		}
+	} else if currentBranch == defaultBranch {
+		// On default branch with a squash commit: push it, then move the branch onto it
+		fmt.Printf("[workspace] linear-sync-to-default: workspace_id=%s pushing squash commit to %s\n", workspaceID, defaultBranch)
+		pushCmd := exec.CommandContext(ctx, "git", "push", "origin", squashCommit+":"+defaultBranch)
+		pushCmd.Dir = workspacePath
+		if output, err := pushCmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("git push origin %s:%s failed: %w: %s", squashCommit, defaultBranch, err, string(output))
+		}
+		if err := moveBranchToCommit(ctx, workspacePath, squashCommit); err != nil {
+			return nil, err
+		}
 	} else {
 		// On feature branch: set upstream to default branch, push to default branch, then sync local
 		fmt.Printf("[workspace] linear-sync-to-default: workspace_id=%s setting upstream to %s\n", workspaceID, defaultBranch)
@@ -266,11 +307,20 @@ func (m *Manager) LinearSyncToDefault(ctx context.Context, workspaceID string) (
 			return nil, fmt.Errorf("git branch --set-upstream-to=%s failed: %w: %s", defaultRef, err, string(output))
 		}
 
+		pushRef := "HEAD"
+		if squashCommit != "" {
+			pushRef = squashCommit
+		}
 		fmt.Printf("[workspace] linear-sync-to-default: workspace_id=%s pushing to %s\n", workspaceID, defaultBranch)
-		pushCmd := exec.CommandContext(ctx, "git", "push", "origin", "HEAD:"+defaultBranch)
+		pushCmd := exec.CommandContext(ctx, "git", "push", "origin", pushRef+":"+defaultBranch)
 		pushCmd.Dir = workspacePath
 		if output, err := pushCmd.CombinedOutput(); err != nil {
-			return nil, fmt.Errorf("git push origin HEAD:%s failed: %w: %s", defaultBranch, err, string(output))
+			return nil, fmt.Errorf("git push origin %s:%s failed: %w: %s", pushRef, defaultBranch, err, string(output))
+		}
+		if squashCommit != "" {
+			if err := moveBranchToCommit(ctx, workspacePath, squashCommit); err != nil {
+				return nil, err
+			}
 		}
 
 		// Sync local branch to match new default branch
@@ -291,6 +341,55 @@ func (m *Manager) LinearSyncToDefault(ctx context.Context, workspaceID string) (
 	}, nil
 }
 
+// squashForSync builds a single commit holding the tree of HEAD on top of
+// defaultRef, with a message rendered from sessions.sync_commit_template and
+// committed with env and signArgs. It returns the new commit's hash without
+// moving HEAD or the current branch.
+func squashForSync(ctx context.Context, workspacePath, defaultRef, tmpl, branch, defaultBranch string, env, signArgs []string) (string, error) {
+	logCmd := exec.CommandContext(ctx, "git", "log", "--reverse", "--format=%s", defaultRef+"..HEAD")
+	logCmd.Dir = workspacePath
+	output, err := logCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git log failed: %w", err)
+	}
+	subjects := strings.Split(strings.TrimSpace(string(output)), "\n")
+	message := renderSyncCommitMessage(tmpl, branch, defaultBranch, subjects)
+
+	args := append([]string{"commit-tree"}, signArgs...)
+	args = append(args, "-p", defaultRef, "-m", message, "HEAD^{tree}")
+	commitCmd := exec.CommandContext(ctx, "git", args...)
+	commitCmd.Dir = workspacePath
+	commitCmd.Env = env
+	var stderr bytes.Buffer
+	commitCmd.Stderr = &stderr
+	commitOutput, err := commitCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git commit-tree failed: %w: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(string(commitOutput)), nil
+}
+
+// moveBranchToCommit points the current branch at commit. The commit has the
+// same tree as HEAD, so the index and working tree are left as they are.
+func moveBranchToCommit(ctx context.Context, workspacePath, commit string) error {
+	resetCmd := exec.CommandContext(ctx, "git", "reset", "--soft", commit)
+	resetCmd.Dir = workspacePath
+	if output, err := resetCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git reset --soft %s failed: %w: %s", commit, err, string(output))
+	}
+	return nil
+}
+
+// renderSyncCommitMessage fills in the sessions.sync_commit_template placeholders.
+func renderSyncCommitMessage(tmpl, branch, defaultBranch string, subjects []string) string {
+	return strings.NewReplacer(
+		"{branch}", branch,
+		"{default_branch}", defaultBranch,
+		"{count}", strconv.Itoa(len(subjects)),
+		"{subjects}", strings.Join(subjects, "\n"),
+	).Replace(tmpl)
+}
+
 // LinearSyncFromMain performs an iterative rebase from origin/main into the current branch.
 // Deprecated: Use LinearSyncFromDefault instead.
 func (m *Manager) LinearSyncFromMain(ctx context.Context, workspaceID string) (*LinearSyncResult, error) {
@@ -306,6 +405,8 @@ func (m *Manager) LinearSyncToMain(ctx context.Context, workspaceID string) (*Li
 // LinearSyncResolveConflict rebases exactly one commit from the default branch, handling conflicts.
 // When a conflict occurs during replay of local commits, it pauses the rebase, runs a non-interactive
 // one-shot LLM call to resolve the conflicted files, then continues. Repeats for each conflicting commit.
+// A resolution below conflict_resolve.min_confidence aborts the rebase, or with
+// conflict_resolve.review_below_threshold leaves it paused with the resolved files unstaged.
 // The onStep callback (if non-nil) is called at each progress step for real-time reporting.
 func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID string, onStep ResolveConflictStepFunc) (*LinearSyncResolveConflictResult, error) {
 	emit := func(step ResolveConflictStep) {
@@ -333,6 +434,17 @@ func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID str
 	workspacePath := w.Path
 	defaultRef := "origin/" + defaultBranch
 
+	// A rebase left paused for review must be finished or aborted by hand first
+	if rebaseInProgress(workspacePath) {
+		return nil, fmt.Errorf("a rebase is already in progress in %s; finish it with git rebase --continue or --abort first", workspacePath)
+	}
+
+	// Refresh origin refs so the behind count is current. gitFetch fetches the
+	// worktree base for worktrees and the clone itself in full-clone mode.
+	if err := m.gitFetch(ctx, workspacePath); err != nil {
+		fmt.Printf("[workspace] linear-sync-resolve-conflict: warning: fetch failed: %v\n", err)
+	}
+
 	// 1. Get the oldest commit hash from HEAD..<default branch>
 	logCmd := exec.CommandContext(ctx, "git", "log", "--oneline", "--reverse", "HEAD.."+defaultRef)
 	logCmd.Dir = workspacePath
@@ -372,6 +484,11 @@ func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID str
 	})
 	fmt.Printf("[workspace] linear-sync-resolve-conflict: workspace_id=%s rebasing hash=%s\n", workspaceID, hash)
 
+	signArgs, err := m.gitSignArgs(ctx, workspacePath)
+	if err != nil {
+		return nil, err
+	}
+
 	// 2. Create WIP commit to preserve local changes (including untracked files)
 	addWipCmd := exec.CommandContext(ctx, "git", "add", "-A")
 	addWipCmd.Dir = workspacePath
@@ -380,8 +497,9 @@ func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID str
 	}
 
 	wipUUID := fmt.Sprintf("WIP: %d", time.Now().UnixNano())
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", wipUUID)
+	commitCmd := exec.CommandContext(ctx, "git", append(append([]string{"commit"}, signArgs...), "-m", wipUUID)...)
 	commitCmd.Dir = workspacePath
+	commitCmd.Env = m.gitIdentityEnv()
 	commitOutput, err := commitCmd.CombinedOutput()
 	didCommit := true
 	if err != nil {
@@ -431,8 +549,9 @@ func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID str
 
 	// 3. git rebase <hash>
 	emit(ResolveConflictStep{Action: "rebase_start", Status: "in_progress", Message: fmt.Sprintf("git rebase %s", hash)})
-	rebaseCmd := exec.CommandContext(ctx, "git", "rebase", hash)
+	rebaseCmd := exec.CommandContext(ctx, "git", append(append([]string{"rebase"}, signArgs...), hash)...)
 	rebaseCmd.Dir = workspacePath
+	rebaseCmd.Env = m.gitIdentityEnv()
 	rebaseOutput, rebaseErr := rebaseCmd.CombinedOutput()
 
 	var resolutions []ConflictResolution
@@ -478,7 +597,7 @@ func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID str
 				emit(ResolveConflictStep{Action: "rebase_continue", Status: "in_progress", Message: "No unmerged files, attempting git rebase --continue"})
 				autoContinueCmd := exec.CommandContext(ctx, "git", "rebase", "--continue")
 				autoContinueCmd.Dir = workspacePath
-				autoContinueCmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+				autoContinueCmd.Env = append(m.gitIdentityEnv(), "GIT_EDITOR=true")
 				autoContinueOutput, autoContinueErr := autoContinueCmd.CombinedOutput()
 				if autoContinueErr == nil {
 					if !rebaseInProgress(workspacePath) {
@@ -574,11 +693,38 @@ func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID str
 		resolutions = append(resolutions, resolution)
 		fmt.Printf("[workspace] linear-sync-resolve-conflict: oneshot result on %s: all_resolved=%t confidence=%s summary=%q\n", localCommitHash, oneshotResult.AllResolved, oneshotResult.Confidence, oneshotResult.Summary)
 
-		// Check decision logic: must be all_resolved=true AND confidence=high
-		if !oneshotResult.AllResolved || oneshotResult.Confidence != "high" {
+		// Check decision logic: must be all_resolved=true AND confidence >= min_confidence
+		minConfidence := m.config.GetConflictResolveMinConfidence()
+		if oneshotResult.AllResolved && !m.config.MeetsConflictResolveConfidence(oneshotResult.Confidence) && m.config.GetConflictResolveReviewBelowThreshold() {
+			resolutions[len(resolutions)-1].NeedsReview = true
+			emit(ResolveConflictStep{
+				Action:      "llm_call",
+				Status:      "done",
+				Message:     oneshotResult.Summary,
+				LocalCommit: localCommitHash,
+				Files:       unmergedFiles,
+				Confidence:  oneshotResult.Confidence,
+				Summary:     oneshotResult.Summary,
+			})
+			msg := fmt.Sprintf("Resolution of local commit %s has %s confidence, below %s; rebase left paused for review. Check the files, then git add them and run git rebase --continue, or run git rebase --abort.",
+				localCommitHash[:minLen(len(localCommitHash), 7)], oneshotResult.Confidence, minConfidence)
+			if didCommit {
+				msg += " Either way, then run git reset HEAD~1 to restore your uncommitted changes from the WIP commit."
+			}
+			emit(ResolveConflictStep{Action: "review", Status: "needs_review", Message: msg, LocalCommit: localCommitHash, Files: unmergedFiles})
+			fmt.Printf("[workspace] linear-sync-resolve-conflict: %s confidence on %s is below %s; leaving rebase paused for review\n", oneshotResult.Confidence, localCommitHash, minConfidence)
+			return &LinearSyncResolveConflictResult{
+				Success:     false,
+				Message:     msg,
+				Hash:        hash,
+				Resolutions: resolutions,
+				NeedsReview: true,
+			}, nil
+		}
+		if !oneshotResult.AllResolved || !m.config.MeetsConflictResolveConfidence(oneshotResult.Confidence) {
 			reason := "not all resolved"
 			if oneshotResult.AllResolved {
-				reason = fmt.Sprintf("%s confidence", oneshotResult.Confidence)
+				reason = fmt.Sprintf("%s confidence, below %s", oneshotResult.Confidence, minConfidence)
 			}
 			msg := fmt.Sprintf("Could not resolve conflict on local commit %s: %s", localCommitHash, reason)
 			emit(ResolveConflictStep{
@@ -717,7 +863,7 @@ func (m *Manager) LinearSyncResolveConflict(ctx context.Context, workspaceID str
 		emit(ResolveConflictStep{Action: "rebase_continue", Status: "in_progress", Message: "git rebase --continue"})
 		continueCmd := exec.CommandContext(ctx, "git", "rebase", "--continue")
 		continueCmd.Dir = workspacePath
-		continueCmd.Env = append(os.Environ(), "GIT_EDITOR=true")
+		continueCmd.Env = append(m.gitIdentityEnv(), "GIT_EDITOR=true")
 		continueOutput, continueErr := continueCmd.CombinedOutput()
 
 		if continueErr == nil {
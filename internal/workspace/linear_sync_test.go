@@ -0,0 +1,313 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+// gitTestUpstream creates a bare "origin" repo with an initial commit on main.
+// Returns the bare repo path, usable as a repo URL.
+func gitTestUpstream(t *testing.T) string {
+	t.Helper()
+	src := gitTestWorkTree(t)
+	bare := filepath.Join(t.TempDir(), "upstream.git")
+	runGit(t, src, "clone", "--bare", src, bare)
+	return bare
+}
+
+// gitTestPushCommit clones upstream, commits a file on main, and pushes it back.
+func gitTestPushCommit(t *testing.T, upstream, name, content string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "pusher")
+	runGit(t, t.TempDir(), "clone", upstream, dir)
+	writeFile(t, dir, name, content)
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "add "+name)
+	runGit(t, dir, "push", "origin", "main")
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// TestLinearSync_BothSCMModes runs sync-from-default and sync-to-default against
+// worktree and full-clone workspaces to make sure neither relies on the other's layout.
+func TestLinearSync_BothSCMModes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Commits created by linear sync need an identity; the test HOME has no gitconfig.
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	modes := []string{config.SourceCodeManagementGitWorktree, config.SourceCodeManagementGit}
+	for _, mode := range modes {
+		t.Run(mode, func(t *testing.T) {
+			upstream := gitTestUpstream(t)
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			st := state.New(statePath)
+			cfg := &config.Config{
+				WorkspacePath:        t.TempDir(),
+				WorktreeBasePath:     t.TempDir(),
+				SourceCodeManagement: mode,
+				Repos:                []config.Repo{{Name: "test", URL: upstream}},
+			}
+			manager := New(cfg, st, statePath)
+			ctx := context.Background()
+
+			ws, err := manager.GetOrCreate(ctx, upstream, "feature")
+			if err != nil {
+				t.Fatalf("GetOrCreate failed: %v", err)
+			}
+			if got := isWorktree(ws.Path); got != (mode == config.SourceCodeManagementGitWorktree) {
+				t.Fatalf("isWorktree(%s) = %v for mode %s", ws.Path, got, mode)
+			}
+
+			// Upstream moves ahead; sync it into the workspace while local edits are pending.
+			gitTestPushCommit(t, upstream, "upstream.txt", "from upstream")
+			writeFile(t, ws.Path, "local.txt", "uncommitted")
+
+			result, err := manager.LinearSyncFromDefault(ctx, ws.ID)
+			if err != nil {
+				t.Fatalf("LinearSyncFromDefault failed: %v", err)
+			}
+			if !result.Success || result.SuccessCount != 1 || result.Branch != "main" {
+				t.Fatalf("unexpected sync-from result: %+v", result)
+			}
+			if got := gitOutput(t, ws.Path, "status", "--porcelain"); !strings.Contains(got, "local.txt") {
+				t.Fatalf("expected local change to survive sync, status: %q", got)
+			}
+
+			// Resolve-conflict is a no-op when already caught up.
+			resolved, err := manager.LinearSyncResolveConflict(ctx, ws.ID, nil)
+			if err != nil {
+				t.Fatalf("LinearSyncResolveConflict failed: %v", err)
+			}
+			if !resolved.Success {
+				t.Fatalf("expected caught-up resolve to succeed: %+v", resolved)
+			}
+
+			// Commit locally and push straight to main.
+			runGit(t, ws.Path, "add", ".")
+			runGit(t, ws.Path, "commit", "-m", "local work")
+			result, err = manager.LinearSyncToDefault(ctx, ws.ID)
+			if err != nil {
+				t.Fatalf("LinearSyncToDefault failed: %v", err)
+			}
+			if !result.Success {
+				t.Fatalf("unexpected sync-to result: %+v", result)
+			}
+			if got, want := gitOutput(t, upstream, "rev-parse", "main"), gitOutput(t, ws.Path, "rev-parse", "HEAD"); got != want {
+				t.Fatalf("upstream main = %s, want workspace HEAD %s", got, want)
+			}
+		})
+	}
+}
+
+func TestLinearSyncToDefault_SyncCommitTemplate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	upstream := gitTestUpstream(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{
+		WorkspacePath:    t.TempDir(),
+		WorktreeBasePath: t.TempDir(),
+		Repos:            []config.Repo{{Name: "test", URL: upstream}},
+		Sessions:         &config.SessionsConfig{SyncCommitTemplate: "feat({branch}): {count} changes\n\n{subjects}"},
+	}
+	manager := New(cfg, st, statePath)
+	ctx := context.Background()
+
+	ws, err := manager.GetOrCreate(ctx, upstream, "feature")
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+	before := gitOutput(t, upstream, "rev-parse", "main")
+	for _, name := range []string{"a.txt", "b.txt"} {
+		writeFile(t, ws.Path, name, name)
+		runGit(t, ws.Path, "add", ".")
+		runGit(t, ws.Path, "commit", "-m", "add "+name)
+	}
+
+	result, err := manager.LinearSyncToDefault(ctx, ws.ID)
+	if err != nil {
+		t.Fatalf("LinearSyncToDefault failed: %v", err)
+	}
+	if !result.Success || result.SuccessCount != 2 {
+		t.Fatalf("unexpected sync-to result: %+v", result)
+	}
+	if got := gitOutput(t, upstream, "rev-parse", "main~1"); got != before {
+		t.Errorf("expected one squashed commit on main, main~1 = %s, want %s", got, before)
+	}
+	want := "feat(feature): 2 changes\n\nadd a.txt\nadd b.txt"
+	if got := gitOutput(t, upstream, "log", "-1", "--format=%B", "main"); got != want {
+		t.Errorf("commit message = %q, want %q", got, want)
+	}
+}
+
+func TestLinearSyncToDefault_GitAuthorIdentity(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	upstream := gitTestUpstream(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{
+		WorkspacePath:    t.TempDir(),
+		WorktreeBasePath: t.TempDir(),
+		Repos:            []config.Repo{{Name: "test", URL: upstream}},
+		Sessions: &config.SessionsConfig{
+			SyncCommitTemplate: "sync {branch}",
+			GitAuthorName:      "Jane Doe",
+			GitAuthorEmail:     "jane@example.com",
+		},
+	}
+	manager := New(cfg, st, statePath)
+	ctx := context.Background()
+
+	ws, err := manager.GetOrCreate(ctx, upstream, "feature")
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+	writeFile(t, ws.Path, "a.txt", "a")
+	runGit(t, ws.Path, "add", ".")
+	runGit(t, ws.Path, "commit", "-m", "add a.txt")
+
+	result, err := manager.LinearSyncToDefault(ctx, ws.ID)
+	if err != nil || !result.Success {
+		t.Fatalf("LinearSyncToDefault = %+v, %v", result, err)
+	}
+	want := "Jane Doe <jane@example.com> / Jane Doe <jane@example.com>"
+	if got := gitOutput(t, upstream, "log", "-1", "--format=%an <%ae> / %cn <%ce>", "main"); got != want {
+		t.Errorf("squash commit identity = %q, want %q", got, want)
+	}
+}
+
+func TestLinearSyncToDefault_SquashPushFailureKeepsBranch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	upstream := gitTestUpstream(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{
+		WorkspacePath:    t.TempDir(),
+		WorktreeBasePath: t.TempDir(),
+		Repos:            []config.Repo{{Name: "test", URL: upstream}},
+		Sessions:         &config.SessionsConfig{SyncCommitTemplate: "sync {branch}"},
+	}
+	manager := New(cfg, st, statePath)
+	ctx := context.Background()
+
+	ws, err := manager.GetOrCreate(ctx, upstream, "feature")
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		writeFile(t, ws.Path, name, name)
+		runGit(t, ws.Path, "add", ".")
+		runGit(t, ws.Path, "commit", "-m", "add "+name)
+	}
+	before := gitOutput(t, ws.Path, "rev-parse", "HEAD")
+	upstreamBefore := gitOutput(t, upstream, "rev-parse", "main")
+
+	// Make the remote reject every push.
+	hook := filepath.Join(upstream, "hooks", "pre-receive")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.LinearSyncToDefault(ctx, ws.ID); err == nil {
+		t.Fatal("expected LinearSyncToDefault to fail when the push is rejected")
+	}
+	if got := gitOutput(t, ws.Path, "rev-parse", "HEAD"); got != before {
+		t.Errorf("branch HEAD = %s after failed push, want unsquashed %s", got, before)
+	}
+	if got := gitOutput(t, upstream, "rev-parse", "main"); got != upstreamBefore {
+		t.Errorf("upstream main = %s, want unchanged %s", got, upstreamBefore)
+	}
+}
+
+func TestOfflineMode_UsesLocalRefs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Test User")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@test.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test User")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@test.com")
+
+	upstream := gitTestUpstream(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{
+		WorkspacePath:    t.TempDir(),
+		WorktreeBasePath: t.TempDir(),
+		Repos:            []config.Repo{{Name: "test", URL: upstream}},
+		Sessions:         &config.SessionsConfig{},
+	}
+	manager := New(cfg, st, statePath)
+	ctx := context.Background()
+
+	ws, err := manager.GetOrCreate(ctx, upstream, "feature")
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+	writeFile(t, ws.Path, "local.txt", "local")
+	runGit(t, ws.Path, "add", ".")
+	runGit(t, ws.Path, "commit", "-m", "add local.txt")
+
+	// Upstream moves on while we are offline.
+	gitTestPushCommit(t, upstream, "remote.txt", "remote")
+	cfg.Sessions.Offline = true
+
+	dirty, ahead, behind, _, _, _ := manager.gitStatus(ctx, ws.Path, upstream)
+	if dirty || ahead != 1 || behind != 0 {
+		t.Errorf("offline gitStatus = dirty=%v ahead=%d behind=%d, want clean, 1 ahead, 0 behind", dirty, ahead, behind)
+	}
+	if _, err := manager.LinearSyncFromDefault(ctx, ws.ID); err != nil {
+		t.Errorf("offline LinearSyncFromDefault failed: %v", err)
+	}
+	if _, err := manager.LinearSyncToDefault(ctx, ws.ID); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline LinearSyncToDefault error = %v, want ErrOffline", err)
+	}
+
+	cfg.Sessions.Offline = false
+	if _, _, behind, _, _, _ := manager.gitStatus(ctx, ws.Path, upstream); behind != 1 {
+		t.Errorf("online gitStatus behind = %d, want 1 after fetching", behind)
+	}
+}
@@ -0,0 +1,48 @@
+package workspace
+
+import (
+	"fmt"
+	"time"
+)
+
+// Maintenance is the state of maintenance mode. While it is on, the daemon's
+// background git work (status polling, origin fetches, the git watcher, and base
+// repo pruning) is paused so repos can be worked on by hand without racing it.
+// It lives in memory only; a daemon restart turns it off.
+type Maintenance struct {
+	Enabled bool
+	// BlockSpawns also rejects new spawns, which would create worktrees and fetch.
+	BlockSpawns bool
+	// Since is when maintenance mode was turned on.
+	Since time.Time
+}
+
+// Maintenance returns the current maintenance mode state.
+func (m *Manager) Maintenance() Maintenance {
+	m.maintenanceMu.RLock()
+	defer m.maintenanceMu.RUnlock()
+	return m.maintenance
+}
+
+// InMaintenance reports whether background git work is paused.
+func (m *Manager) InMaintenance() bool {
+	return m.Maintenance().Enabled
+}
+
+// SetMaintenance turns maintenance mode on or off and returns the new state.
+// blockSpawns is ignored when turning it off.
+func (m *Manager) SetMaintenance(enabled, blockSpawns bool) Maintenance {
+	m.maintenanceMu.Lock()
+	defer m.maintenanceMu.Unlock()
+
+	switch {
+	case !enabled:
+		m.maintenance = Maintenance{}
+	case m.maintenance.Enabled:
+		m.maintenance.BlockSpawns = blockSpawns
+	default:
+		m.maintenance = Maintenance{Enabled: true, BlockSpawns: blockSpawns, Since: time.Now()}
+	}
+	fmt.Printf("[workspace] maintenance mode: enabled=%v block_spawns=%v\n", m.maintenance.Enabled, m.maintenance.BlockSpawns)
+	return m.maintenance
+}
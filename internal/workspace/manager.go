@@ -39,6 +39,10 @@ type Manager struct {
 	defaultBranchCache   map[string]string // repoURL -> defaultBranch or "unknown"
 	defaultBranchCacheMu sync.RWMutex
 	workspaceLockedFn    func(workspaceID string) bool
+	gitGraphCache        map[string]gitGraphCacheEntry // workspace ID -> last computed graph
+	gitGraphCacheMu      sync.Mutex
+	maintenance          Maintenance
+	maintenanceMu        sync.RWMutex
 }
 
 // New creates a new workspace manager.
@@ -169,6 +173,10 @@ func (m *Manager) GetOrCreate(ctx context.Context, repoURL, branch string) (*sta
 			fmt.Printf("[workspace] directory missing, skipping: id=%s path=%s\n", w.ID, w.Path)
 			continue
 		}
+		// Imported checkouts hold the user's own work; never clean them for a spawn
+		if w.Imported {
+			continue
+		}
 		if w.Repo == repoURL && w.Branch == branch {
 			// Check if workspace has active sessions
 			if !m.hasActiveSessions(w.ID) {
@@ -184,7 +192,7 @@ func (m *Manager) GetOrCreate(ctx context.Context, repoURL, branch string) (*sta
 
 	// Try to find any unused workspace for this repo (different branch OK)
 	for _, w := range m.state.GetWorkspaces() {
-		if w.Repo == repoURL {
+		if w.Repo == repoURL && !w.Imported {
 			// Check if workspace has active sessions
 			if !m.hasActiveSessions(w.ID) {
 				fmt.Printf("[workspace] reusing for different branch: id=%s old=%s new=%s\n", w.ID, w.Branch, branch)
@@ -227,6 +235,9 @@ func (m *Manager) create(ctx context.Context, repoURL, branch string) (*state.Wo
 
 	// Find the next available workspace number
 	workspaces := m.getWorkspacesForRepo(repoURL)
+	if err := m.checkWorkspaceLimit(repoConfig.Name, workspaces); err != nil {
+		return nil, err
+	}
 	nextNum := findNextWorkspaceNumber(workspaces)
 
 	// Create workspace ID
@@ -242,6 +253,7 @@ func (m *Manager) create(ctx context.Context, repoURL, branch string) (*state.Wo
 	}
 
 	// Fetch latest before creating worktree
+	reportProgress(ctx, ProgressFetching, workspaceID)
 	if fetchErr := m.gitFetch(ctx, worktreeBasePath); fetchErr != nil {
 		fmt.Printf("[workspace] warning: fetch failed before worktree add: %v\n", fetchErr)
 	}
@@ -259,17 +271,19 @@ func (m *Manager) create(ctx context.Context, repoURL, branch string) (*state.Wo
 		createdUniqueBranch = wasCreated
 	}
 
-	// Clean up worktree if creation fails
+	// Clean up worktree if creation fails. Creation may have failed because ctx was
+	// cancelled (e.g. the spawn was aborted), so cleanup must not depend on it.
 	cleanupNeeded := true
 	defer func() {
 		if cleanupNeeded {
 			fmt.Printf("[workspace] cleaning up failed: %s\n", workspacePath)
+			cleanupCtx := context.WithoutCancel(ctx)
 			// Try worktree remove first, fall back to rm -rf
-			if err := m.removeWorktree(ctx, worktreeBasePath, workspacePath); err != nil {
+			if err := m.removeWorktree(cleanupCtx, worktreeBasePath, workspacePath); err != nil {
 				os.RemoveAll(workspacePath)
 			}
 			if createdUniqueBranch {
-				if err := m.deleteBranch(ctx, worktreeBasePath, branch); err != nil {
+				if err := m.deleteBranch(cleanupCtx, worktreeBasePath, branch); err != nil {
 					fmt.Printf("[workspace] warning: failed to delete branch %s: %v\n", branch, err)
 				}
 			}
@@ -277,6 +291,7 @@ func (m *Manager) create(ctx context.Context, repoURL, branch string) (*state.Wo
 	}()
 
 	// Check source code management setting
+	reportProgress(ctx, ProgressCheckingOut, branch)
 	if m.config.UseWorktrees() {
 		// Using worktrees - no fallback, branch conflicts are auto-resolved with suffixes
 		if err := m.addWorktree(ctx, worktreeBasePath, workspacePath, branch, repoURL); err != nil {
@@ -291,6 +306,7 @@ func (m *Manager) create(ctx context.Context, repoURL, branch string) (*state.Wo
 	}
 
 	// Copy overlay files if they exist
+	reportProgress(ctx, ProgressCopyingOverlays, workspaceID)
 	if err := m.copyOverlayFiles(ctx, repoConfig.Name, workspacePath); err != nil {
 		fmt.Printf("[workspace] warning: failed to copy overlay files: %v\n", err)
 		// Don't fail workspace creation if overlay copy fails
@@ -340,6 +356,9 @@ func (m *Manager) CreateLocalRepo(ctx context.Context, repoName, branch string)
 
 	// Find the next available workspace number for this "local repo"
 	workspaces := m.getWorkspacesForRepo(repoURL)
+	if err := m.checkWorkspaceLimit(repoName, workspaces); err != nil {
+		return nil, err
+	}
 	nextNum := findNextWorkspaceNumber(workspaces)
 
 	// Create workspace ID
@@ -410,9 +429,17 @@ func (m *Manager) prepare(ctx context.Context, workspaceID, branch string) error
 
 	fmt.Printf("[workspace] preparing: id=%s branch=%s\n", workspaceID, branch)
 
+	// A rebase left behind by an earlier failed pull would make every later git step fail
+	if aborted, err := m.abortRebase(ctx, w.Path); err != nil {
+		return err
+	} else if aborted {
+		fmt.Printf("[workspace] aborted stale rebase: id=%s\n", workspaceID)
+	}
+
 	hasOrigin := m.gitHasOriginRemote(ctx, w.Path)
 	if hasOrigin {
 		// Fetch latest
+		reportProgress(ctx, ProgressFetching, workspaceID)
 		if err := m.gitFetch(ctx, w.Path); err != nil {
 			return fmt.Errorf("git fetch failed: %w", err)
 		}
@@ -429,7 +456,14 @@ func (m *Manager) prepare(ctx context.Context, workspaceID, branch string) error
 		}
 	}
 
+	// With the ref pruned, the branch is checked out as it stands locally rather than
+	// reset to a stale origin/<branch>
+	if hasOrigin && !remoteBranchExists && branch == w.Branch && w.RemoteBranchHead != "" {
+		fmt.Printf("[workspace] origin/%s was deleted on the remote, keeping the local branch: id=%s\n", branch, workspaceID)
+	}
+
 	// Discard any local changes (must happen before pull)
+	reportProgress(ctx, ProgressCheckingOut, branch)
 	if err := m.gitCheckoutDot(ctx, w.Path); err != nil {
 		return fmt.Errorf("git checkout -- . failed: %w", err)
 	}
@@ -439,15 +473,26 @@ func (m *Manager) prepare(ctx context.Context, workspaceID, branch string) error
 		return fmt.Errorf("git clean failed: %w", err)
 	}
 
+	// A branch that doesn't exist yet starts from the requested base ref, if any
+	startPoint := ""
+	if baseRef := baseRefFromContext(ctx); baseRef != "" && !remoteBranchExists && !m.localBranchExists(ctx, w.Path, branch) {
+		if err := verifyBaseRef(ctx, w.Path, baseRef); err != nil {
+			return err
+		}
+		fmt.Printf("[workspace] starting branch %s from %s: id=%s\n", branch, baseRef, workspaceID)
+		startPoint = baseRef
+	}
+
 	// Checkout/reset branch after cleaning
-	if err := m.gitCheckoutBranch(ctx, w.Path, branch, remoteBranchExists); err != nil {
+	if err := m.gitCheckoutBranch(ctx, w.Path, branch, remoteBranchExists, startPoint); err != nil {
 		return fmt.Errorf("git checkout failed: %w", err)
 	}
 
 	// Pull with rebase (working dir is now clean)
 	if remoteBranchExists {
-		if err := m.gitPullRebase(ctx, w.Path, branch); err != nil {
-			return fmt.Errorf("git pull --rebase failed (conflicts?): %w", err)
+		reportProgress(ctx, ProgressPulling, "origin/"+branch)
+		if err := m.pullRebaseOrAbort(ctx, workspaceID, w.Path, branch); err != nil {
+			return err
 		}
 	} else {
 		fmt.Printf("[workspace] no origin/%s remote ref, skipping pull\n", branch)
@@ -501,6 +546,15 @@ func (m *Manager) findRepoByURL(repoURL string) (config.Repo, bool) {
 	return config.Repo{}, false
 }
 
+// checkWorkspaceLimit returns an error if the repo already has sessions.max_workspaces_per_repo
+// workspaces, so a runaway script can't fill the disk with worktrees.
+func (m *Manager) checkWorkspaceLimit(repoName string, workspaces []state.Workspace) error {
+	if limit := m.config.GetMaxWorkspacesPerRepo(); len(workspaces) >= limit {
+		return fmt.Errorf("repo %s already has %d workspaces, the limit set by sessions.max_workspaces_per_repo; dispose unused workspaces first", repoName, len(workspaces))
+	}
+	return nil
+}
+
 // findNextWorkspaceNumber finds the next available workspace number, filling gaps.
 // It starts from 1 and returns the first unused number.
 func findNextWorkspaceNumber(workspaces []state.Workspace) int {
@@ -560,6 +614,7 @@ func (m *Manager) UpdateGitStatus(ctx context.Context, workspaceID string) (*sta
 	}
 
 	// Update workspace in memory
+	previousBranch, wasGone := w.Branch, w.GitBranchGone
 	w.GitDirty = dirty
 	w.GitAhead = ahead
 	w.GitBehind = behind
@@ -567,18 +622,31 @@ func (m *Manager) UpdateGitStatus(ctx context.Context, workspaceID string) (*sta
 	w.GitLinesRemoved = linesRemoved
 	w.GitFilesChanged = filesChanged
 	w.Branch = actualBranch
+	headChanged := m.trackRemoteBranch(ctx, &w, previousBranch)
+	if w.GitBranchGone && !wasGone {
+		fmt.Printf("[workspace] origin/%s was deleted on the remote: id=%s\n", w.Branch, w.ID)
+	}
 
 	// Update the workspace in state (this updates the in-memory copy)
 	if err := m.state.UpdateWorkspace(w); err != nil {
 		return nil, fmt.Errorf("failed to update workspace in state: %w", err)
 	}
+	if headChanged {
+		if err := m.state.Save(); err != nil {
+			fmt.Printf("[workspace] failed to save state for %s: %v\n", w.ID, err)
+		}
+	}
 
 	return &w, nil
 }
 
 // UpdateAllGitStatus refreshes git status for all workspaces.
-// This is called periodically by the background goroutine.
+// This is called periodically by the background goroutine. It does nothing while
+// maintenance mode is on.
 func (m *Manager) UpdateAllGitStatus(ctx context.Context) {
+	if m.InMaintenance() {
+		return
+	}
 	workspaces := m.state.GetWorkspaces()
 
 	for _, w := range workspaces {
@@ -590,12 +658,15 @@ func (m *Manager) UpdateAllGitStatus(ctx context.Context) {
 		// Refresh workspace config for this workspace
 		m.RefreshWorkspaceConfig(w)
 
-		if _, err := m.UpdateGitStatus(ctx, w.ID); err != nil {
+		updated, err := m.UpdateGitStatus(ctx, w.ID)
+		if err != nil {
 			if errors.Is(err, ErrWorkspaceLocked) {
 				continue
 			}
 			fmt.Printf("[workspace] failed to update git status for %s: %v\n", w.ID, err)
+			continue
 		}
+		m.applyBranchGonePolicy(ctx, *updated)
 	}
 }
 
@@ -613,6 +684,7 @@ func (m *Manager) EnsureWorkspaceDir() error {
 }
 
 // Dispose deletes a workspace by removing its directory and removing it from state.
+// Imported workspaces are only removed from state; their directory is left alone.
 func (m *Manager) Dispose(workspaceID string) error {
 	w, found := m.state.GetWorkspace(workspaceID)
 	if !found {
@@ -644,6 +716,22 @@ func (m *Manager) Dispose(workspaceID string) error {
 		return fmt.Errorf("workspace has active sessions: %s", workspaceID)
 	}
 
+	// Imported checkouts belong to the user - unregister them but leave the directory
+	if w.Imported {
+		if m.gitWatcher != nil {
+			m.gitWatcher.RemoveWorkspace(workspaceID)
+		}
+		if err := m.state.RemoveWorkspace(workspaceID); err != nil {
+			return fmt.Errorf("failed to remove workspace from state: %w", err)
+		}
+		if err := m.state.Save(); err != nil {
+			return fmt.Errorf("failed to save state: %w", err)
+		}
+		m.invalidateGitGraph(workspaceID)
+		fmt.Printf("[workspace] disposed (imported, directory kept): id=%s\n", workspaceID)
+		return nil
+	}
+
 	ctx := context.Background()
 
 	// Check if workspace directory exists
@@ -662,6 +750,7 @@ func (m *Manager) Dispose(workspaceID string) error {
 		if !gitStatus.Safe {
 			return fmt.Errorf("workspace has unsaved changes: %s", gitStatus.Reason)
 		}
+		m.runPreDisposeCommand(ctx, w)
 	}
 
 	// Remove filesystem watches before directory removal
@@ -709,6 +798,8 @@ func (m *Manager) Dispose(workspaceID string) error {
 		return fmt.Errorf("failed to save state: %w", err)
 	}
 
+	m.invalidateGitGraph(workspaceID)
+
 	if err := difftool.CleanupWorkspaceTempDirs(workspaceID); err != nil {
 		fmt.Printf("[workspace] failed to cleanup diff temp dirs for %s: %v\n", workspaceID, err)
 	}
@@ -2,6 +2,7 @@ package workspace
 
 import (
 	"context"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -239,3 +240,136 @@ func TestGetOrCreate_BranchReuse_Failure(t *testing.T) {
 	// to validate that state is not corrupted when prepare() fails.
 	// The success test validates the fix (prepare() called before state update).
 }
+
+// TestBranchExists checks remote and local detection against a worktree base.
+func TestBranchExists(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir()) // no query repos, so the worktree base is used
+
+	repoDir := gitTestWorkTree(t)
+	gitTestBranch(t, repoDir, "feature-1")
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{
+		WorkspacePath:    t.TempDir(),
+		WorktreeBasePath: t.TempDir(),
+		Repos:            []config.Repo{{Name: "test", URL: repoDir}},
+	}
+	manager := New(cfg, st, statePath)
+	ctx := context.Background()
+
+	if _, err := manager.GetOrCreate(ctx, repoDir, "new-branch"); err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+
+	tests := []struct {
+		branch        string
+		remote, local bool
+	}{
+		{"feature-1", true, true}, // the bare clone copies origin's branches
+		{"new-branch", false, true},
+		{"missing", false, false},
+	}
+	for _, tt := range tests {
+		got, err := manager.BranchExists(ctx, repoDir, tt.branch)
+		if err != nil {
+			t.Fatalf("BranchExists(%q) error: %v", tt.branch, err)
+		}
+		if got.ExistsRemote != tt.remote || got.ExistsLocal != tt.local {
+			t.Errorf("BranchExists(%q) = %+v, want remote=%v local=%v", tt.branch, *got, tt.remote, tt.local)
+		}
+	}
+
+	if _, err := manager.BranchExists(ctx, "https://example.com/unknown.git", "main"); err == nil {
+		t.Error("expected error for unknown repo")
+	}
+}
+
+// TestGetOrCreate_BaseRef checks that a new branch starts from the context's base ref
+// in both worktree and full-clone mode.
+func TestGetOrCreate_BaseRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := gitTestWorkTree(t)
+	runGit(t, repoDir, "tag", "v1")
+	tagged := gitRevParse(t, repoDir, "v1")
+	writeFile(t, repoDir, "later.txt", "later")
+	runGit(t, repoDir, "add", ".")
+	runGit(t, repoDir, "commit", "-m", "later")
+
+	for _, scm := range []string{config.SourceCodeManagementGitWorktree, config.SourceCodeManagementGit} {
+		t.Run(scm, func(t *testing.T) {
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			st := state.New(statePath)
+			cfg := &config.Config{
+				WorkspacePath:        t.TempDir(),
+				WorktreeBasePath:     t.TempDir(),
+				SourceCodeManagement: scm,
+				Repos:                []config.Repo{{Name: "test", URL: repoDir}},
+			}
+			manager := New(cfg, st, statePath)
+			ctx := WithBaseRef(context.Background(), "v1")
+
+			ws, err := manager.GetOrCreate(ctx, repoDir, "hotfix")
+			if err != nil {
+				t.Fatalf("GetOrCreate failed: %v", err)
+			}
+			if got := gitRevParse(t, ws.Path, "HEAD"); got != tagged {
+				t.Errorf("HEAD = %s, want v1 at %s", got, tagged)
+			}
+
+			_ = st.AddSession(state.Session{ID: "sess-1", WorkspaceID: ws.ID, Target: "test", TmuxSession: "test", CreatedAt: time.Now()})
+			if _, err := manager.GetOrCreate(WithBaseRef(context.Background(), "v9"), repoDir, "hotfix-2"); err == nil || !strings.Contains(err.Error(), `base ref "v9" not found`) {
+				t.Errorf("missing base ref: got %v", err)
+			}
+		})
+	}
+}
+
+func TestGetOrCreate_CloneFilter(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := gitTestWorkTree(t)
+	runGit(t, repoDir, "config", "uploadpack.allowFilter", "true")
+	repoURL := "file://" + repoDir
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{
+		WorkspacePath:    t.TempDir(),
+		WorktreeBasePath: t.TempDir(),
+		Repos:            []config.Repo{{Name: "test", URL: repoURL, CloneFilter: "blob:none"}},
+	}
+	manager := New(cfg, st, statePath)
+
+	ws, err := manager.GetOrCreate(context.Background(), repoURL, "feature")
+	if err != nil {
+		t.Fatalf("GetOrCreate failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ws.Path, "README.md")); err != nil {
+		t.Errorf("checkout is missing README.md: %v", err)
+	}
+	cmd := exec.Command("git", "config", "remote.origin.partialclonefilter")
+	cmd.Dir = ws.Path
+	if out, err := cmd.Output(); err != nil || strings.TrimSpace(string(out)) != "blob:none" {
+		t.Errorf("remote.origin.partialclonefilter = %q, %v; want blob:none", out, err)
+	}
+}
+
+func gitRevParse(t *testing.T, dir, ref string) string {
+	t.Helper()
+	cmd := exec.Command("git", "rev-parse", ref)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git rev-parse %s: %v", ref, err)
+	}
+	return strings.TrimSpace(string(out))
+}
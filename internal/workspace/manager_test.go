@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -414,6 +415,32 @@ func TestGetOrCreate_LocalRepo(t *testing.T) {
 	}
 }
 
+func TestCreateLocalRepo_WorkspaceLimit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+	cfg := config.CreateDefault(filepath.Join(tmpDir, "config.json"))
+	cfg.WorkspacePath = tmpDir
+	cfg.Sessions = &config.SessionsConfig{MaxWorkspacesPerRepo: 1}
+	st := state.New(statePath)
+	m := New(cfg, st, statePath)
+
+	ctx := context.Background()
+	if _, err := m.CreateLocalRepo(ctx, "testproject", "main"); err != nil {
+		t.Fatalf("CreateLocalRepo() unexpected error: %v", err)
+	}
+	_, err := m.CreateLocalRepo(ctx, "testproject", "main")
+	if err == nil || !strings.Contains(err.Error(), "max_workspaces_per_repo") {
+		t.Fatalf("expected workspace limit error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "testproject-002")); !os.IsNotExist(err) {
+		t.Error("expected no directory for the refused workspace")
+	}
+}
+
 // mockStateStore wraps a state.Store and can simulate failures.
 type mockStateStore struct {
 	state    *state.State
@@ -436,6 +463,10 @@ func (m *mockStateStore) UpdateWorkspace(w state.Workspace) error {
 	return m.state.UpdateWorkspace(w)
 }
 
+func (m *mockStateStore) AddWorkspacePrompt(workspaceID string, entry state.PromptHistoryEntry, limit int) error {
+	return m.state.AddWorkspacePrompt(workspaceID, entry, limit)
+}
+
 func (m *mockStateStore) RemoveWorkspace(id string) error {
 	return m.state.RemoveWorkspace(id)
 }
@@ -452,6 +483,10 @@ func (m *mockStateStore) AddWorktreeBase(wb state.WorktreeBase) error {
 	return m.state.AddWorktreeBase(wb)
 }
 
+func (m *mockStateStore) RemoveWorktreeBase(repoURL string) error {
+	return m.state.RemoveWorktreeBase(repoURL)
+}
+
 func (m *mockStateStore) GetSessions() []state.Session {
 	return m.state.GetSessions()
 }
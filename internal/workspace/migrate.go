@@ -0,0 +1,251 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// renameDir moves a directory; tests replace it to simulate a cross-filesystem move.
+var renameDir = os.Rename
+
+// Migration statuses reported per workspace by MigrateWorkspaces.
+const (
+	MigrateStatusMoved   = "moved"
+	MigrateStatusSkipped = "skipped"
+	MigrateStatusFailed  = "failed"
+)
+
+// MigrateResult is the outcome of moving one workspace to the current workspace_path.
+type MigrateResult struct {
+	WorkspaceID string `json:"workspace_id"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Status      string `json:"status"`           // moved, skipped, or failed
+	Reason      string `json:"reason,omitempty"` // why the workspace was skipped or failed
+}
+
+// MigrateWorkspaces moves local workspaces that live outside workspace_path into it,
+// keeping their directory names, so a workspace_path change applies to existing
+// workspaces too. Workspaces with sessions, uncommitted changes, or a sync in progress
+// are skipped; migrate them later once they're idle. Worktrees have their gitdir links
+// repaired after the move.
+func (m *Manager) MigrateWorkspaces(ctx context.Context) ([]MigrateResult, error) {
+	basePath := m.config.GetWorkspacePath()
+	if basePath == "" {
+		return nil, fmt.Errorf("workspace_path is not set")
+	}
+	if err := m.EnsureWorkspaceDir(); err != nil {
+		return nil, err
+	}
+
+	workspaces := m.state.GetWorkspaces()
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].ID < workspaces[j].ID })
+
+	results := []MigrateResult{}
+	for _, w := range workspaces {
+		if w.IsRemoteWorkspace() || w.Imported || filepath.Dir(filepath.Clean(w.Path)) == filepath.Clean(basePath) {
+			continue
+		}
+		result := MigrateResult{WorkspaceID: w.ID, From: w.Path, To: filepath.Join(basePath, filepath.Base(w.Path))}
+		if reason := m.migrateBlocker(ctx, w.ID, w.Path, result.To); reason != "" {
+			result.Status = MigrateStatusSkipped
+			result.Reason = reason
+		} else if err := m.migrateWorkspace(ctx, w.ID, result.To); err != nil {
+			result.Status = MigrateStatusFailed
+			result.Reason = err.Error()
+		} else {
+			result.Status = MigrateStatusMoved
+		}
+		fmt.Printf("[workspace] migrate: id=%s status=%s from=%s to=%s %s\n", w.ID, result.Status, result.From, result.To, result.Reason)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// migrateBlocker returns why a workspace can't be moved right now, or "" if it can.
+func (m *Manager) migrateBlocker(ctx context.Context, workspaceID, from, to string) string {
+	if m.hasActiveSessions(workspaceID) {
+		return "workspace has active sessions"
+	}
+	if m.workspaceLockedFn != nil && m.workspaceLockedFn(workspaceID) {
+		return "workspace is locked by a sync operation"
+	}
+	if _, err := os.Stat(from); err != nil {
+		return fmt.Sprintf("workspace directory is missing: %v", err)
+	}
+	if _, err := os.Stat(to); err == nil {
+		return "destination already exists"
+	}
+	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
+	statusCmd.Dir = from
+	output, err := statusCmd.Output()
+	if err != nil {
+		return fmt.Sprintf("git status failed: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "" {
+		return "workspace has uncommitted changes"
+	}
+	return ""
+}
+
+// migrateWorkspace moves the workspace directory to newPath and updates state. The
+// directory is moved back if the worktree links can't be repaired or state can't be
+// saved. When newPath is on another filesystem, the directory is copied and verified
+// instead, and the original is removed only once state points at the copy.
+func (m *Manager) migrateWorkspace(ctx context.Context, workspaceID, newPath string) error {
+	w, found := m.state.GetWorkspace(workspaceID)
+	if !found {
+		return fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	lock := m.repoLock(w.Repo)
+	lock.Lock()
+	defer lock.Unlock()
+
+	oldPath := w.Path
+	if m.gitWatcher != nil {
+		m.gitWatcher.RemoveWorkspace(workspaceID)
+	}
+	// Whatever happens, watch the workspace wherever it ends up.
+	defer func() {
+		if m.gitWatcher != nil {
+			m.gitWatcher.AddWorkspace(w.ID, w.Path)
+		}
+	}()
+
+	copied := false
+	if err := renameDir(oldPath, newPath); err != nil {
+		if !errors.Is(err, syscall.EXDEV) {
+			return fmt.Errorf("failed to move workspace directory: %w", err)
+		}
+		if err := copyWorkspaceDir(oldPath, newPath); err != nil {
+			os.RemoveAll(newPath)
+			return fmt.Errorf("failed to copy workspace directory to another filesystem: %w", err)
+		}
+		copied = true
+	}
+	rollback := func(cause error) error {
+		if copied {
+			if err := os.RemoveAll(newPath); err != nil {
+				return fmt.Errorf("%w (and removing the copy failed: %v)", cause, err)
+			}
+		} else if err := renameDir(newPath, oldPath); err != nil {
+			return fmt.Errorf("%w (and moving it back failed: %v)", cause, err)
+		}
+		if isWorktree(oldPath) {
+			if err := repairWorktree(ctx, oldPath); err != nil {
+				return fmt.Errorf("%w (and repairing it after moving back failed: %v)", cause, err)
+			}
+		}
+		return cause
+	}
+
+	if isWorktree(newPath) {
+		if err := repairWorktree(ctx, newPath); err != nil {
+			return rollback(err)
+		}
+	}
+
+	w.Path = newPath
+	if err := m.state.UpdateWorkspace(w); err != nil {
+		w.Path = oldPath
+		return rollback(fmt.Errorf("failed to update workspace in state: %w", err))
+	}
+	if err := m.state.Save(); err != nil {
+		w.Path = oldPath
+		m.state.UpdateWorkspace(w)
+		return rollback(fmt.Errorf("failed to save state: %w", err))
+	}
+	if copied {
+		if err := os.RemoveAll(oldPath); err != nil {
+			fmt.Printf("[workspace] warning: migrated %s but failed to remove the old directory %s: %v\n", workspaceID, oldPath, err)
+		}
+	}
+	m.RefreshWorkspaceConfig(w)
+	m.invalidateGitGraph(workspaceID)
+	return nil
+}
+
+// copyWorkspaceDir copies a workspace directory tree to dst, which must not exist,
+// keeping file modes and symlinks, then checks that every entry arrived intact.
+func copyWorkspaceDir(src, dst string) error {
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.Mkdir(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			// Sockets and pipes can't be copied and don't belong in a workspace
+			return nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return verifyWorkspaceCopy(src, dst)
+}
+
+// verifyWorkspaceCopy checks that every directory, symlink, and regular file under
+// src exists under dst with the same type and size.
+func verifyWorkspaceCopy(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Type()&fs.ModeSymlink == 0 && !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		want, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		got, err := os.Lstat(filepath.Join(dst, rel))
+		if err != nil {
+			return fmt.Errorf("copy is missing %s: %w", rel, err)
+		}
+		if got.Mode().Type() != want.Mode().Type() || (want.Mode().IsRegular() && got.Size() != want.Size()) {
+			return fmt.Errorf("copy of %s doesn't match the original", rel)
+		}
+		return nil
+	})
+}
+
+// repairWorktree points the worktree base's record of a moved worktree at its new path.
+func repairWorktree(ctx context.Context, worktreePath string) error {
+	cmd := exec.CommandContext(ctx, "git", "worktree", "repair")
+	cmd.Dir = worktreePath
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree repair failed: %w: %s", err, string(output))
+	}
+	return nil
+}
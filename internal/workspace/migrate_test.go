@@ -0,0 +1,122 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
+)
+
+func TestMigrateWorkspaces(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	base := gitTestUpstream(t)
+	oldRoot, newRoot := t.TempDir(), t.TempDir()
+	for _, branch := range []string{"idle", "dirty", "busy"} {
+		runGit(t, base, "worktree", "add", "-b", branch, filepath.Join(oldRoot, "repo-"+branch), "main")
+	}
+	writeFile(t, filepath.Join(oldRoot, "repo-dirty"), "scratch.txt", "wip")
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{WorkspacePath: newRoot}
+	manager := New(cfg, st, statePath)
+	for _, ws := range []state.Workspace{
+		{ID: "repo-busy", Repo: base, Branch: "busy", Path: filepath.Join(oldRoot, "repo-busy")},
+		{ID: "repo-dirty", Repo: base, Branch: "dirty", Path: filepath.Join(oldRoot, "repo-dirty")},
+		{ID: "repo-idle", Repo: base, Branch: "idle", Path: filepath.Join(oldRoot, "repo-idle")},
+		{ID: "repo-remote", Repo: base, Branch: "main", Path: "/remote/repo", RemoteHostID: "host-1"},
+	} {
+		if err := st.AddWorkspace(ws); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.AddSession(state.Session{ID: "s1", WorkspaceID: "repo-busy", TmuxSession: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := manager.MigrateWorkspaces(context.Background())
+	if err != nil {
+		t.Fatalf("MigrateWorkspaces() error: %v", err)
+	}
+	want := map[string]string{
+		"repo-busy":  MigrateStatusSkipped,
+		"repo-dirty": MigrateStatusSkipped,
+		"repo-idle":  MigrateStatusMoved,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v, want statuses %v", results, want)
+	}
+	for _, result := range results {
+		if result.Status != want[result.WorkspaceID] {
+			t.Errorf("%s: status %q (%s), want %q", result.WorkspaceID, result.Status, result.Reason, want[result.WorkspaceID])
+		}
+	}
+
+	newPath := filepath.Join(newRoot, "repo-idle")
+	if w, _ := st.GetWorkspace("repo-idle"); w.Path != newPath {
+		t.Errorf("state path = %q, want %q", w.Path, newPath)
+	}
+	if _, err := os.Stat(filepath.Join(oldRoot, "repo-idle")); !os.IsNotExist(err) {
+		t.Errorf("old workspace directory still exists")
+	}
+	if got := strings.TrimSpace(gitOutput(t, newPath, "rev-parse", "--abbrev-ref", "HEAD")); got != "idle" {
+		t.Errorf("moved worktree HEAD = %q, want idle", got)
+	}
+	if list := gitOutput(t, base, "worktree", "list", "--porcelain"); !strings.Contains(list, "worktree "+newPath+"\n") {
+		t.Errorf("worktree base doesn't know the new path:\n%s", list)
+	}
+	if w, _ := st.GetWorkspace("repo-dirty"); w.Path != filepath.Join(oldRoot, "repo-dirty") {
+		t.Errorf("skipped workspace path changed to %q", w.Path)
+	}
+}
+
+func TestMigrateWorkspacesAcrossFilesystems(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	// Renames across filesystems fail with EXDEV, so the workspace must be copied
+	renameDir = func(oldPath, newPath string) error {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.EXDEV}
+	}
+	t.Cleanup(func() { renameDir = os.Rename })
+
+	base := gitTestUpstream(t)
+	oldRoot, newRoot := t.TempDir(), t.TempDir()
+	oldPath, newPath := filepath.Join(oldRoot, "repo-idle"), filepath.Join(newRoot, "repo-idle")
+	runGit(t, base, "worktree", "add", "-b", "idle", oldPath, "main")
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	manager := New(&config.Config{WorkspacePath: newRoot}, st, statePath)
+	if err := st.AddWorkspace(state.Workspace{ID: "repo-idle", Repo: base, Branch: "idle", Path: oldPath}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := manager.MigrateWorkspaces(context.Background())
+	if err != nil {
+		t.Fatalf("MigrateWorkspaces() error: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != MigrateStatusMoved {
+		t.Fatalf("results = %+v, want repo-idle moved", results)
+	}
+	if w, _ := st.GetWorkspace("repo-idle"); w.Path != newPath {
+		t.Errorf("state path = %q, want %q", w.Path, newPath)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("original directory still exists after the copy")
+	}
+	if status := gitOutput(t, newPath, "status", "--porcelain"); strings.TrimSpace(status) != "" {
+		t.Errorf("copied worktree is dirty:\n%s", status)
+	}
+	if list := gitOutput(t, base, "worktree", "list", "--porcelain"); !strings.Contains(list, "worktree "+newPath+"\n") {
+		t.Errorf("worktree base doesn't know the new path:\n%s", list)
+	}
+}
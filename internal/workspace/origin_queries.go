@@ -9,7 +9,6 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"time"
 )
 
 // EnsureOriginQueries ensures origin query repos exist for all configured repos.
@@ -64,9 +63,17 @@ func (m *Manager) ensureOriginQueryRepo(ctx context.Context, repoURL string) (st
 		if err := m.prepareOriginQueryRepo(ctx, queryRepoPath, repoName); err != nil {
 			return "", fmt.Errorf("failed to initialize origin query repo for %s: %w", repoName, err)
 		}
-	} else if m.originQueryRepoNeedsRepair(ctx, queryRepoPath) {
-		if err := m.prepareOriginQueryRepo(ctx, queryRepoPath, repoName); err != nil {
-			return "", fmt.Errorf("failed to repair origin query repo for %s: %w", repoName, err)
+	} else {
+		if err := m.applyGitProxy(ctx, queryRepoPath); err != nil {
+			fmt.Printf("[workspace] warning: failed to apply git proxy to query repo %s: %v\n", repoName, err)
+		}
+		if err := m.applyGitSSHCommand(ctx, queryRepoPath, repoURL); err != nil {
+			fmt.Printf("[workspace] warning: failed to apply ssh key to query repo %s: %v\n", repoName, err)
+		}
+		if m.originQueryRepoNeedsRepair(ctx, queryRepoPath) {
+			if err := m.prepareOriginQueryRepo(ctx, queryRepoPath, repoName); err != nil {
+				return "", fmt.Errorf("failed to repair origin query repo for %s: %w", repoName, err)
+			}
 		}
 	}
 
@@ -75,7 +82,8 @@ func (m *Manager) ensureOriginQueryRepo(ctx context.Context, repoURL string) (st
 
 // cloneOriginQueryRepo clones a repository as a bare clone for branch/commit querying.
 func (m *Manager) cloneOriginQueryRepo(ctx context.Context, url, path string) error {
-	args := []string{"clone", "--bare", url, path}
+	args := append(append([]string{"clone"}, m.gitCloneConfigArgs(url)...), m.gitCloneFilterArgs(url)...)
+	args = append(args, "--bare", url, path)
 	cmd := exec.CommandContext(ctx, "git", args...)
 
 	if output, err := cmd.CombinedOutput(); err != nil {
@@ -118,7 +126,10 @@ func (m *Manager) ensureOriginFetchRefspec(ctx context.Context, queryRepoPath st
 }
 
 func (m *Manager) fetchOriginQueryRepo(ctx context.Context, queryRepoPath, repoName string) error {
-	fetchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	if m.config.GetOffline() {
+		return nil
+	}
+	fetchCtx, cancel := context.WithTimeout(ctx, m.config.GitFetchTimeout())
 	defer cancel()
 	cmd := exec.CommandContext(fetchCtx, "git", "fetch", "--prune", "origin")
 	cmd.Dir = queryRepoPath
@@ -160,7 +171,7 @@ func (m *Manager) originQueryRepoNeedsRepair(ctx context.Context, queryRepoPath
 // FetchOriginQueries fetches updates for all origin query repos.
 func (m *Manager) FetchOriginQueries(ctx context.Context) {
 	queryRepoDir := m.config.GetQueryRepoPath()
-	if queryRepoDir == "" {
+	if queryRepoDir == "" || m.config.GetOffline() {
 		return
 	}
 
@@ -383,3 +394,42 @@ func (m *Manager) GetBranchCommitLog(ctx context.Context, repoURL, branch string
 
 	return messages, nil
 }
+
+// BranchExists reports whether branch exists on origin and whether schmux already has
+// it locally. It only reads refs from the query repo and worktree base, so it reflects
+// the last fetch and never touches the network.
+func (m *Manager) BranchExists(ctx context.Context, repoURL, branch string) (*BranchExistence, error) {
+	if _, found := m.findRepoByURL(repoURL); !found {
+		return nil, fmt.Errorf("repo URL not found in config: %s", repoURL)
+	}
+
+	result := &BranchExistence{}
+	if queryRepoDir := m.config.GetQueryRepoPath(); queryRepoDir != "" {
+		queryRepoPath := filepath.Join(queryRepoDir, extractRepoName(repoURL)+".git")
+		if _, err := os.Stat(queryRepoPath); err == nil {
+			exists, err := m.gitRemoteBranchExists(ctx, queryRepoPath, branch)
+			if err != nil {
+				return nil, err
+			}
+			result.ExistsRemote = exists
+		}
+	}
+
+	if wb, found := m.state.GetWorktreeBaseByURL(repoURL); found {
+		if !result.ExistsRemote {
+			result.ExistsRemote = RemoteBranchExists(ctx, wb.Path, branch)
+		}
+		cmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch)
+		cmd.Dir = wb.Path
+		result.ExistsLocal = cmd.Run() == nil
+	}
+
+	for _, w := range m.getWorkspacesForRepo(repoURL) {
+		if w.Branch == branch {
+			result.ExistsLocal = true
+			break
+		}
+	}
+
+	return result, nil
+}
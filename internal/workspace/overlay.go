@@ -2,12 +2,15 @@ package workspace
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/sergeknystautas/schmux/internal/config"
 )
@@ -22,6 +25,16 @@ func OverlayDir(repoName string) (string, error) {
 	return filepath.Join(homeDir, ".schmux", "overlays", repoName), nil
 }
 
+// GlobalOverlayDir returns the overlay directory applied to every repo's workspaces.
+// Returns ~/.schmux/global-overlay/.
+func GlobalOverlayDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".schmux", "global-overlay"), nil
+}
+
 // EnsureOverlayDir ensures the overlay directory exists for a given repo name.
 // Creates the directory if it doesn't exist.
 func EnsureOverlayDir(repoName string) error {
@@ -49,6 +62,11 @@ func EnsureOverlayDir(repoName string) error {
 // Only copies files that are covered by .gitignore in the destination workspace.
 // Preserves directory structure, file permissions, and symlinks.
 func CopyOverlay(ctx context.Context, srcDir, destDir string) error {
+	return copyOverlay(ctx, srcDir, destDir, nil)
+}
+
+// copyOverlay is CopyOverlay, leaving out the files whose relative path is in skip.
+func copyOverlay(ctx context.Context, srcDir, destDir string, skip map[string]bool) error {
 	// Walk the overlay directory
 	return filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -77,6 +95,10 @@ func CopyOverlay(ctx context.Context, srcDir, destDir string) error {
 			return nil
 		}
 
+		if skip[relPath] {
+			return nil
+		}
+
 		// For files, check if covered by .gitignore
 		ignored, err := isIgnoredByGit(ctx, destDir, relPath)
 		if err != nil {
@@ -164,23 +186,47 @@ func isIgnoredByGit(ctx context.Context, dir, filePath string) (bool, error) {
 	return false, fmt.Errorf("git check-ignore failed: %w", err)
 }
 
-// copyOverlayFiles copies overlay files from the overlay directory to the workspace.
-// If the overlay directory doesn't exist, this is a no-op.
+// copyOverlayFiles copies the global overlay and then the repo's overlay into the
+// workspace. A file in both comes from the repo's overlay. Overlay directories that
+// don't exist are skipped.
 func (m *Manager) copyOverlayFiles(ctx context.Context, repoName, workspacePath string) error {
+	globalDir, err := GlobalOverlayDir()
+	if err != nil {
+		return fmt.Errorf("failed to get global overlay directory: %w", err)
+	}
 	overlayDir, err := OverlayDir(repoName)
 	if err != nil {
 		return fmt.Errorf("failed to get overlay directory: %w", err)
 	}
 
-	// Check if overlay directory exists
-	if _, err := os.Stat(overlayDir); os.IsNotExist(err) {
+	_, globalErr := os.Stat(globalDir)
+	_, repoErr := os.Stat(overlayDir)
+	if os.IsNotExist(globalErr) && os.IsNotExist(repoErr) {
 		fmt.Printf("[workspace] no overlay directory for repo %s, skipping\n", repoName)
 		return nil
 	}
 
-	fmt.Printf("[workspace] copying overlay files: repo=%s to=%s\n", repoName, workspacePath)
-	if err := CopyOverlay(ctx, overlayDir, workspacePath); err != nil {
-		return fmt.Errorf("failed to copy overlay files: %w", err)
+	if !os.IsNotExist(globalErr) {
+		// Files the repo's overlay also has are left to it, so they aren't copied twice
+		repoFiles, err := ListOverlayFiles(repoName)
+		if err != nil {
+			return fmt.Errorf("failed to list overlay files: %w", err)
+		}
+		skip := make(map[string]bool, len(repoFiles))
+		for _, f := range repoFiles {
+			skip[f] = true
+		}
+		fmt.Printf("[workspace] copying global overlay files: to=%s\n", workspacePath)
+		if err := copyOverlay(ctx, globalDir, workspacePath, skip); err != nil {
+			return fmt.Errorf("failed to copy global overlay files: %w", err)
+		}
+	}
+
+	if !os.IsNotExist(repoErr) {
+		fmt.Printf("[workspace] copying overlay files: repo=%s to=%s\n", repoName, workspacePath)
+		if err := CopyOverlay(ctx, overlayDir, workspacePath); err != nil {
+			return fmt.Errorf("failed to copy overlay files: %w", err)
+		}
 	}
 
 	fmt.Printf("[workspace] overlay files copied successfully\n")
@@ -210,6 +256,43 @@ func (m *Manager) RefreshOverlay(ctx context.Context, workspaceID string) error
 	return nil
 }
 
+// RefreshRepoOverlay reapplies overlay files to every workspace of the named repo.
+// Workspaces with active sessions are skipped so files don't change under a running agent.
+// A failure on one workspace is reported in its result and does not stop the others.
+func (m *Manager) RefreshRepoOverlay(ctx context.Context, repoName string) ([]OverlayRefreshResult, error) {
+	repoConfig, found := m.config.FindRepo(repoName)
+	if !found {
+		return nil, fmt.Errorf("repo not found: %s", repoName)
+	}
+
+	workspaces := m.getWorkspacesForRepo(repoConfig.URL)
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].ID < workspaces[j].ID })
+
+	results := make([]OverlayRefreshResult, 0, len(workspaces))
+	for _, w := range workspaces {
+		result := OverlayRefreshResult{WorkspaceID: w.ID}
+		switch {
+		case w.IsRemoteWorkspace():
+			result.Status = OverlayRefreshStatusSkipped
+			result.Reason = "remote workspace"
+		case m.hasActiveSessions(w.ID):
+			result.Status = OverlayRefreshStatusSkipped
+			result.Reason = "workspace has active sessions"
+		default:
+			if err := m.copyOverlayFiles(ctx, repoConfig.Name, w.Path); err != nil {
+				result.Status = OverlayRefreshStatusFailed
+				result.Reason = err.Error()
+			} else {
+				result.Status = OverlayRefreshStatusRefreshed
+			}
+		}
+		results = append(results, result)
+	}
+
+	fmt.Printf("[workspace] refreshed repo overlay: repo=%s workspaces=%d\n", repoConfig.Name, len(results))
+	return results, nil
+}
+
 // EnsureOverlayDirs ensures overlay directories exist for all configured repos.
 func (m *Manager) EnsureOverlayDirs(repos []config.Repo) error {
 	for _, repo := range repos {
@@ -264,3 +347,98 @@ func ListOverlayFiles(repoName string) ([]string, error) {
 
 	return files, nil
 }
+
+// ErrInvalidOverlayPath is returned for overlay file names that are empty, absolute,
+// or reach outside the overlay directory.
+var ErrInvalidOverlayPath = errors.New("invalid overlay file path")
+
+// overlayRelPath converts a slash-separated overlay file name to a path relative to
+// the overlay root, rejecting names that could escape it.
+func overlayRelPath(name string) (string, error) {
+	relPath := filepath.FromSlash(name)
+	if name == "" || !filepath.IsLocal(relPath) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidOverlayPath, name)
+	}
+	return relPath, nil
+}
+
+// ReadOverlayFile returns the contents of a file in the repo's overlay directory.
+// name is slash-separated and relative to the overlay root. Symlinks that point
+// outside the overlay directory are not followed.
+func ReadOverlayFile(repoName, name string) ([]byte, error) {
+	relPath, err := overlayRelPath(name)
+	if err != nil {
+		return nil, err
+	}
+	overlayDir, err := OverlayDir(repoName)
+	if err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(overlayDir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	f, err := root.Open(relPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%w: %q is a directory", ErrInvalidOverlayPath, name)
+	}
+	return io.ReadAll(f)
+}
+
+// WriteOverlayFile creates or replaces a file in the repo's overlay directory,
+// creating the overlay directory and any parent directories as needed. New files
+// are private (0600) since overlays usually hold secrets; existing files keep their mode.
+func WriteOverlayFile(repoName, name string, content []byte) error {
+	relPath, err := overlayRelPath(name)
+	if err != nil {
+		return err
+	}
+	if err := EnsureOverlayDir(repoName); err != nil {
+		return err
+	}
+	overlayDir, err := OverlayDir(repoName)
+	if err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(overlayDir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	// os.Root has no MkdirAll, so create parents one component at a time.
+	dir := ""
+	for _, part := range strings.Split(filepath.Dir(relPath), string(filepath.Separator)) {
+		if part == "." {
+			break
+		}
+		dir = filepath.Join(dir, part)
+		if err := root.Mkdir(dir, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("failed to create overlay directory %s: %w", dir, err)
+		}
+	}
+
+	f, err := root.OpenFile(relPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("[workspace] wrote overlay file: repo=%s file=%s\n", repoName, name)
+	return nil
+}
@@ -2,10 +2,14 @@ package workspace
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/sergeknystautas/schmux/internal/config"
+	"github.com/sergeknystautas/schmux/internal/state"
 )
 
 func TestOverlayDir(t *testing.T) {
@@ -246,3 +250,168 @@ func runGitCommand(ctx context.Context, dir string, args ...string) error {
 	cmd.Dir = dir
 	return cmd.Run()
 }
+
+func TestRefreshRepoOverlay(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	overlayDir, err := OverlayDir("myrepo")
+	if err != nil {
+		t.Fatalf("OverlayDir() error: %v", err)
+	}
+	if err := os.MkdirAll(overlayDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, overlayDir, ".env", "SECRET=1")
+
+	newWorkspaceDir := func() string {
+		dir := t.TempDir()
+		runGit(t, dir, "init", "-q")
+		writeFile(t, dir, ".gitignore", ".env\n")
+		return dir
+	}
+	idleDir := newWorkspaceDir()
+	busyDir := newWorkspaceDir()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	st := state.New(statePath)
+	cfg := &config.Config{WorkspacePath: t.TempDir(), Repos: []config.Repo{{Name: "myrepo", URL: "git@example.com:me/myrepo.git"}}}
+	manager := New(cfg, st, statePath)
+	for _, ws := range []state.Workspace{
+		{ID: "myrepo-001", Repo: "git@example.com:me/myrepo.git", Branch: "main", Path: idleDir},
+		{ID: "myrepo-002", Repo: "git@example.com:me/myrepo.git", Branch: "feature", Path: busyDir},
+		{ID: "other-001", Repo: "git@example.com:me/other.git", Branch: "main", Path: t.TempDir()},
+	} {
+		if err := st.AddWorkspace(ws); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.AddSession(state.Session{ID: "s1", WorkspaceID: "myrepo-002", TmuxSession: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := manager.RefreshRepoOverlay(context.Background(), "myrepo")
+	if err != nil {
+		t.Fatalf("RefreshRepoOverlay() error: %v", err)
+	}
+	want := []OverlayRefreshResult{
+		{WorkspaceID: "myrepo-001", Status: OverlayRefreshStatusRefreshed},
+		{WorkspaceID: "myrepo-002", Status: OverlayRefreshStatusSkipped, Reason: "workspace has active sessions"},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("results[%d] = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(idleDir, ".env")); err != nil || string(data) != "SECRET=1" {
+		t.Errorf("overlay not copied to idle workspace: %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(busyDir, ".env")); !os.IsNotExist(err) {
+		t.Errorf("overlay copied to workspace with active sessions")
+	}
+
+	if _, err := manager.RefreshRepoOverlay(context.Background(), "missing"); err == nil {
+		t.Error("expected error for unknown repo")
+	}
+}
+
+func TestOverlayFileReadWrite(t *testing.T) {
+	repoName := "overlay-rw-repo"
+	overlayDir, err := OverlayDir(repoName)
+	if err != nil {
+		t.Fatalf("OverlayDir() error = %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(overlayDir) })
+
+	if err := WriteOverlayFile(repoName, "config/local.json", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("WriteOverlayFile() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(overlayDir, "config", "local.json"))
+	if err != nil {
+		t.Fatalf("expected file on disk: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	got, err := ReadOverlayFile(repoName, "config/local.json")
+	if err != nil || string(got) != `{"a":1}` {
+		t.Errorf("ReadOverlayFile() = %q, %v", got, err)
+	}
+
+	for _, name := range []string{"", "../outside", "/etc/passwd", "config/../../outside"} {
+		if err := WriteOverlayFile(repoName, name, []byte("x")); !errors.Is(err, ErrInvalidOverlayPath) {
+			t.Errorf("WriteOverlayFile(%q) error = %v, want ErrInvalidOverlayPath", name, err)
+		}
+	}
+
+	// A symlink pointing outside the overlay directory must not be followed.
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(overlayDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadOverlayFile(repoName, "link"); err == nil {
+		t.Error("ReadOverlayFile() followed a symlink out of the overlay directory")
+	}
+	if err := WriteOverlayFile(repoName, "link", []byte("overwrite")); err == nil {
+		t.Error("WriteOverlayFile() followed a symlink out of the overlay directory")
+	}
+}
+
+func TestCopyOverlayFilesLayersGlobalOverlay(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	globalDir, err := GlobalOverlayDir()
+	if err != nil {
+		t.Fatalf("GlobalOverlayDir() error: %v", err)
+	}
+	overlayDir, err := OverlayDir("myrepo")
+	if err != nil {
+		t.Fatalf("OverlayDir() error: %v", err)
+	}
+	for _, dir := range []string{globalDir, overlayDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, globalDir, ".editorconfig", "root = true")
+	writeFile(t, globalDir, ".env", "FROM=global")
+	writeFile(t, overlayDir, ".env", "FROM=repo")
+
+	wsDir := t.TempDir()
+	runGit(t, wsDir, "init", "-q")
+	writeFile(t, wsDir, ".gitignore", ".env\n.editorconfig\n")
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	manager := New(&config.Config{WorkspacePath: t.TempDir()}, state.New(statePath), statePath)
+	if err := manager.copyOverlayFiles(context.Background(), "myrepo", wsDir); err != nil {
+		t.Fatalf("copyOverlayFiles() error: %v", err)
+	}
+
+	for name, want := range map[string]string{".editorconfig": "root = true", ".env": "FROM=repo"} {
+		if data, err := os.ReadFile(filepath.Join(wsDir, name)); err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+
+	// Repos without an overlay of their own still get the global one
+	otherDir := t.TempDir()
+	runGit(t, otherDir, "init", "-q")
+	writeFile(t, otherDir, ".gitignore", ".env\n.editorconfig\n")
+	if err := manager.copyOverlayFiles(context.Background(), "other", otherDir); err != nil {
+		t.Fatalf("copyOverlayFiles() error: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(otherDir, ".env")); err != nil || string(data) != "FROM=global" {
+		t.Errorf(".env = %q, %v; want global overlay's", data, err)
+	}
+}
@@ -0,0 +1,79 @@
+package workspace
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// Progress stages reported while a workspace is created or prepared for a spawn.
+const (
+	ProgressCloning         = "cloning"
+	ProgressFetching        = "fetching"
+	ProgressCheckingOut     = "checking_out"
+	ProgressPulling         = "pulling"
+	ProgressCopyingOverlays = "copying_overlays"
+)
+
+// ProgressFunc receives the progress of a workspace being created or prepared.
+// stage is one of the Progress* constants; detail is free text, such as the
+// workspace ID or git's "Receiving objects:  45% (450/1000)".
+type ProgressFunc func(stage, detail string)
+
+type progressKey struct{}
+
+// WithProgress returns a context whose workspace operations report their progress to fn.
+// GetOrCreate and the steps under it run for minutes on a first clone of a large repo;
+// threading the reporter through the context keeps it out of every signature in between.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress reports a stage to the context's ProgressFunc, if it has one.
+func reportProgress(ctx context.Context, stage, detail string) {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok && fn != nil {
+		fn(stage, detail)
+	}
+}
+
+// runGitProgress runs a git clone or fetch and returns its combined output, like
+// CombinedOutput. When ctx carries a ProgressFunc, git is asked for --progress and
+// each progress line it prints is reported under stage as it arrives.
+func runGitProgress(ctx context.Context, cmd *exec.Cmd, stage string) ([]byte, error) {
+	fn, ok := ctx.Value(progressKey{}).(ProgressFunc)
+	if !ok || fn == nil || len(cmd.Args) < 2 {
+		return cmd.CombinedOutput()
+	}
+	// --progress goes right after the subcommand: git <subcommand> --progress ...
+	cmd.Args = slices.Insert(cmd.Args, 2, "--progress")
+	w := &progressWriter{report: func(line string) { fn(stage, line) }}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err := cmd.Run()
+	return w.output.Bytes(), err
+}
+
+// progressWriter collects a git command's output and reports each line of it. Git
+// redraws its progress meters with \r, so both \r and \n end a line.
+type progressWriter struct {
+	report func(line string)
+	output bytes.Buffer
+	line   []byte
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.output.Write(p)
+	for _, b := range p {
+		if b != '\r' && b != '\n' {
+			w.line = append(w.line, b)
+			continue
+		}
+		if line := strings.TrimSpace(string(w.line)); line != "" {
+			w.report(line)
+		}
+		w.line = w.line[:0]
+	}
+	return len(p), nil
+}
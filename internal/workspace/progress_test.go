@@ -0,0 +1,47 @@
+package workspace
+
+import (
+	"context"
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestProgressWriterSplitsLines(t *testing.T) {
+	var lines []string
+	w := &progressWriter{report: func(line string) { lines = append(lines, line) }}
+	w.Write([]byte("Cloning into 'repo'...\nReceiving objects:  10% (1/10)\rReceiving obj"))
+	w.Write([]byte("ects: 100% (10/10), done.\r\n  \n"))
+
+	want := []string{"Cloning into 'repo'...", "Receiving objects:  10% (1/10)", "Receiving objects: 100% (10/10), done."}
+	if !slices.Equal(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	if got := w.output.String(); got != "Cloning into 'repo'...\nReceiving objects:  10% (1/10)\rReceiving objects: 100% (10/10), done.\r\n  \n" {
+		t.Errorf("output = %q, want everything written", got)
+	}
+}
+
+func TestPrepareReportsProgress(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	mgr, _, wsDir, wsID := setupWorkspaceGraphTest(t, "feature")
+	commitOnWorkspace(t, wsDir, "feature.txt", "feature work")
+	runGit(t, wsDir, "push", "-u", "origin", "feature")
+
+	var stages []string
+	ctx := WithProgress(context.Background(), func(stage, detail string) {
+		if len(stages) == 0 || stages[len(stages)-1] != stage {
+			stages = append(stages, stage)
+		}
+	})
+	if err := mgr.prepare(ctx, wsID, "feature"); err != nil {
+		t.Fatalf("prepare() error: %v", err)
+	}
+
+	want := []string{ProgressFetching, ProgressCheckingOut, ProgressPulling}
+	if !slices.Equal(stages, want) {
+		t.Errorf("stages = %q, want %q", stages, want)
+	}
+}
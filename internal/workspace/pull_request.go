@@ -37,6 +37,9 @@ func (m *Manager) CheckoutPR(ctx context.Context, pr contracts.PullRequest) (*st
 
 // fetchPRRef fetches a GitHub PR ref into the bare clone.
 func (m *Manager) fetchPRRef(ctx context.Context, repoURL string, prNumber int, branchName string) error {
+	if m.config.GetOffline() {
+		return fmt.Errorf("cannot fetch PR #%d: %w", prNumber, ErrOffline)
+	}
 	lock := m.repoLock(repoURL)
 	lock.Lock()
 	defer lock.Unlock()
@@ -80,8 +80,9 @@ func (m *Manager) Scan() (ScanResult, error) {
 	// Step 2: Validate existing workspaces and check for updates
 	existingWorkspaces := m.state.GetWorkspaces()
 	for _, ws := range existingWorkspaces {
-		// Skip remote workspaces - they exist on remote hosts, not locally
-		if ws.IsRemoteWorkspace() {
+		// Skip remote workspaces - they exist on remote hosts, not locally - and
+		// imported ones, which live outside the workspace directory
+		if ws.IsRemoteWorkspace() || ws.Imported {
 			continue
 		}
 
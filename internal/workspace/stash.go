@@ -0,0 +1,116 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrStashConflict is returned when a stash can't be applied cleanly to a workspace.
+var ErrStashConflict = errors.New("stash conflicts with the workspace")
+
+// ErrStashNotFound is returned when a stash index doesn't exist in a workspace.
+var ErrStashNotFound = errors.New("stash not found")
+
+// Stash is one entry in a workspace's `git stash list`.
+type Stash struct {
+	Index     int    `json:"index"`
+	Ref       string `json:"ref"`       // e.g. "stash@{0}"
+	Message   string `json:"message"`   // e.g. "WIP on main: abc1234 subject" or "On main: message"
+	Timestamp string `json:"timestamp"` // when the stash was made, RFC 3339
+}
+
+// ListStashes returns a workspace's stashes, newest first.
+func (m *Manager) ListStashes(ctx context.Context, workspaceID string) ([]Stash, error) {
+	ws, ok := m.state.GetWorkspace(workspaceID)
+	if !ok {
+		return nil, fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+
+	// Message goes last so a "|" inside it does not shift the other fields
+	cmd := exec.CommandContext(ctx, "git", "stash", "list", "--format=%gd|%cI|%gs")
+	cmd.Dir = ws.Path
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git stash list failed: %w: %s", err, string(output))
+	}
+	return parseStashList(string(output)), nil
+}
+
+// parseStashList parses "%gd|%cI|%gs" git stash list output.
+func parseStashList(output string) []Stash {
+	stashes := []Stash{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(parts[0], "stash@{"), "}"))
+		if err != nil {
+			continue
+		}
+		stashes = append(stashes, Stash{
+			Index:     index,
+			Ref:       parts[0],
+			Timestamp: parts[1],
+			Message:   parts[2],
+		})
+	}
+	return stashes
+}
+
+// ApplyStash applies stash@{index} to a workspace, dropping it afterwards when pop is
+// true. The workspace must have no uncommitted changes to tracked files, so applying
+// can't mix the stash into unrelated work. If the stash still conflicts with HEAD, the
+// workspace is reset back to HEAD and the stash is kept, and ErrStashConflict is returned.
+func (m *Manager) ApplyStash(ctx context.Context, workspaceID string, index int, pop bool) error {
+	ws, ok := m.state.GetWorkspace(workspaceID)
+	if !ok {
+		return fmt.Errorf("workspace not found: %s", workspaceID)
+	}
+	if m.workspaceLockedFn != nil && m.workspaceLockedFn(workspaceID) {
+		return ErrWorkspaceLocked
+	}
+
+	stashes, err := m.ListStashes(ctx, workspaceID)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(stashes) {
+		return fmt.Errorf("%w: stash@{%d}", ErrStashNotFound, index)
+	}
+	ref := stashes[index].Ref
+
+	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "--untracked-files=no")
+	statusCmd.Dir = ws.Path
+	output, err := statusCmd.Output()
+	if err != nil {
+		return fmt.Errorf("git status failed: %w", err)
+	}
+	if strings.TrimSpace(string(output)) != "" {
+		return fmt.Errorf("%w: workspace has uncommitted changes; commit or stash them first", ErrStashConflict)
+	}
+
+	action := "apply"
+	if pop {
+		action = "pop"
+	}
+	cmd := exec.CommandContext(ctx, "git", "stash", action, ref)
+	cmd.Dir = ws.Path
+	if output, err := cmd.CombinedOutput(); err != nil {
+		// The tree was clean before, so resetting only undoes the half-applied stash.
+		// A conflicting pop keeps the stash, so nothing is lost.
+		resetCmd := exec.CommandContext(ctx, "git", "reset", "--merge")
+		resetCmd.Dir = ws.Path
+		if resetOutput, resetErr := resetCmd.CombinedOutput(); resetErr != nil {
+			return fmt.Errorf("%w: git stash %s failed: %s (and resetting the workspace failed: %s)", ErrStashConflict, action, strings.TrimSpace(string(output)), strings.TrimSpace(string(resetOutput)))
+		}
+		return fmt.Errorf("%w: git stash %s failed: %s", ErrStashConflict, action, strings.TrimSpace(string(output)))
+	}
+
+	m.invalidateGitGraph(workspaceID)
+	return nil
+}
@@ -0,0 +1,68 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStashListAndApply(t *testing.T) {
+	mgr, _, wsDir, wsID := setupWorkspaceGraphTest(t, "feature")
+	ctx := context.Background()
+
+	writeFile(t, wsDir, "README.md", "first change")
+	runGit(t, wsDir, "stash", "push", "-m", "first | with a pipe")
+	writeFile(t, wsDir, "README.md", "second change")
+	runGit(t, wsDir, "stash", "push", "-m", "second")
+
+	stashes, err := mgr.ListStashes(ctx, wsID)
+	if err != nil {
+		t.Fatalf("ListStashes() error: %v", err)
+	}
+	if len(stashes) != 2 {
+		t.Fatalf("ListStashes() = %+v, want 2 stashes", stashes)
+	}
+	if stashes[0].Index != 0 || stashes[0].Ref != "stash@{0}" || !strings.HasSuffix(stashes[0].Message, ": second") {
+		t.Errorf("newest stash = %+v", stashes[0])
+	}
+	if stashes[1].Index != 1 || !strings.HasSuffix(stashes[1].Message, ": first | with a pipe") {
+		t.Errorf("oldest stash = %+v", stashes[1])
+	}
+
+	if err := mgr.ApplyStash(ctx, wsID, 5, true); !errors.Is(err, ErrStashNotFound) {
+		t.Errorf("ApplyStash(missing) error = %v, want ErrStashNotFound", err)
+	}
+
+	// A dirty tree is refused before anything is applied.
+	writeFile(t, wsDir, "README.md", "local edit")
+	if err := mgr.ApplyStash(ctx, wsID, 1, true); !errors.Is(err, ErrStashConflict) {
+		t.Errorf("ApplyStash(dirty) error = %v, want ErrStashConflict", err)
+	}
+	runGit(t, wsDir, "checkout", "--", "README.md")
+
+	if err := mgr.ApplyStash(ctx, wsID, 1, true); err != nil {
+		t.Fatalf("ApplyStash(pop) error: %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(wsDir, "README.md")); string(got) != "first change" {
+		t.Errorf("README.md after pop = %q, want %q", got, "first change")
+	}
+	if stashes, _ := mgr.ListStashes(ctx, wsID); len(stashes) != 1 {
+		t.Errorf("after pop: %d stashes, want 1", len(stashes))
+	}
+
+	// The remaining stash conflicts with the committed first change: the workspace is
+	// reset and the stash is kept.
+	runGit(t, wsDir, "commit", "-am", "first change")
+	if err := mgr.ApplyStash(ctx, wsID, 0, true); !errors.Is(err, ErrStashConflict) {
+		t.Fatalf("ApplyStash(conflict) error = %v, want ErrStashConflict", err)
+	}
+	if status := gitOutput(t, wsDir, "status", "--porcelain"); strings.TrimSpace(status) != "" {
+		t.Errorf("workspace not clean after conflicting pop:\n%s", status)
+	}
+	if stashes, _ := mgr.ListStashes(ctx, wsID); len(stashes) != 1 {
+		t.Errorf("after conflicting pop: %d stashes, want 1", len(stashes))
+	}
+}
@@ -17,10 +17,12 @@ import (
 // servers). We add the refspec so that 'git fetch' creates remote tracking branches.
 func (m *Manager) cloneBareRepo(ctx context.Context, url, path string) error {
 	fmt.Printf("[workspace] cloning bare repository: url=%s path=%s\n", url, path)
-	args := []string{"clone", "--bare", url, path}
+	args := append(append([]string{"clone"}, m.gitCloneConfigArgs(url)...), m.gitCloneFilterArgs(url)...)
+	args = append(args, "--bare", url, path)
 	cmd := exec.CommandContext(ctx, "git", args...)
 
-	if output, err := cmd.CombinedOutput(); err != nil {
+	reportProgress(ctx, ProgressCloning, url)
+	if output, err := runGitProgress(ctx, cmd, ProgressCloning); err != nil {
 		return fmt.Errorf("git clone --bare failed: %w: %s", err, string(output))
 	}
 
@@ -53,6 +55,12 @@ func (m *Manager) ensureWorktreeBase(ctx context.Context, repoURL string) (strin
 		// Verify it still exists on disk (handles external deletion)
 		if _, err := os.Stat(wb.Path); err == nil {
 			fmt.Printf("[workspace] using existing worktree base: url=%s path=%s\n", repoURL, wb.Path)
+			if err := m.applyGitProxy(ctx, wb.Path); err != nil {
+				fmt.Printf("[workspace] warning: failed to apply git proxy: %v\n", err)
+			}
+			if err := m.applyGitSSHCommand(ctx, wb.Path, repoURL); err != nil {
+				fmt.Printf("[workspace] warning: failed to apply ssh key: %v\n", err)
+			}
 			return wb.Path, nil
 		}
 		fmt.Printf("[workspace] worktree base missing on disk, will recreate: url=%s\n", repoURL)
@@ -111,6 +119,12 @@ func (m *Manager) addWorktree(ctx context.Context, worktreeBasePath, workspacePa
 	} else if remoteBranchExists {
 		// Track existing remote branch (create local branch)
 		args = []string{"worktree", "add", "--track", "-b", branch, workspacePath, remoteBranch}
+	} else if baseRef := baseRefFromContext(ctx); baseRef != "" {
+		// Create new local branch from the requested base ref
+		if err := verifyBaseRef(ctx, worktreeBasePath, baseRef); err != nil {
+			return err
+		}
+		args = []string{"worktree", "add", "-b", branch, workspacePath, baseRef}
 	} else {
 		// Create new local branch from default branch (ensures we start from latest)
 		// Default branch is required to create a new branch from origin/<default>
@@ -125,7 +139,7 @@ func (m *Manager) addWorktree(ctx context.Context, worktreeBasePath, workspacePa
 	cmd.Dir = worktreeBasePath
 
 	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git worktree add failed: %w: %s", err, string(output))
+		return withPartialCloneHint(fmt.Errorf("git worktree add failed: %w: %s", err, string(output)), output)
 	}
 
 	fmt.Printf("[workspace] worktree added: path=%s\n", workspacePath)
@@ -289,13 +303,20 @@ func (m *Manager) initLocalRepo(ctx context.Context, path, branch string) error
 	}
 
 	// Configure user for initial commit (required for git commit)
-	configUserCmd := exec.CommandContext(ctx, "git", "config", "user.email", "schmux@localhost")
+	authorName, authorEmail := m.config.GetGitAuthorName(), m.config.GetGitAuthorEmail()
+	if authorName == "" {
+		authorName = defaultLocalRepoAuthorName
+	}
+	if authorEmail == "" {
+		authorEmail = defaultLocalRepoAuthorEmail
+	}
+	configUserCmd := exec.CommandContext(ctx, "git", "config", "user.email", authorEmail)
 	configUserCmd.Dir = path
 	if output, err := configUserCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git config user.email failed: %w: %s", err, string(output))
 	}
 
-	configNameCmd := exec.CommandContext(ctx, "git", "config", "user.name", "schmux")
+	configNameCmd := exec.CommandContext(ctx, "git", "config", "user.name", authorName)
 	configNameCmd.Dir = path
 	if output, err := configNameCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git config user.name failed: %w: %s", err, string(output))
@@ -309,7 +330,11 @@ func (m *Manager) initLocalRepo(ctx context.Context, path, branch string) error
 	}
 
 	// Create an empty commit for a valid git state
-	commitCmd := exec.CommandContext(ctx, "git", "commit", "--allow-empty", "-m", "Initial commit")
+	signArgs, err := m.gitSignArgs(ctx, path)
+	if err != nil {
+		return err
+	}
+	commitCmd := exec.CommandContext(ctx, "git", append(append([]string{"commit", "--allow-empty"}, signArgs...), "-m", "Initial commit")...)
 	commitCmd.Dir = path
 	if output, err := commitCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git commit failed: %w: %s", err, string(output))
@@ -323,10 +348,12 @@ func (m *Manager) initLocalRepo(ctx context.Context, path, branch string) error
 // Deprecated: Use ensureWorktreeBase + addWorktree for new workspaces.
 func (m *Manager) cloneRepo(ctx context.Context, url, path string) error {
 	fmt.Printf("[workspace] cloning repository: url=%s path=%s\n", url, path)
-	args := []string{"clone", url, path}
+	args := append(append([]string{"clone"}, m.gitCloneConfigArgs(url)...), m.gitCloneFilterArgs(url)...)
+	args = append(args, url, path)
 	cmd := exec.CommandContext(ctx, "git", args...)
 
-	if output, err := cmd.CombinedOutput(); err != nil {
+	reportProgress(ctx, ProgressCloning, url)
+	if output, err := runGitProgress(ctx, cmd, ProgressCloning); err != nil {
 		return fmt.Errorf("git clone failed: %w: %s", err, string(output))
 	}
 
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -26,8 +28,15 @@ func NewDaemonClient(baseURL string) *Client {
 	}
 }
 
-// GetDefaultURL returns the default daemon URL.
+// GetDefaultURL returns the daemon URL: SCHMUX_URL if set, else localhost on
+// SCHMUX_PORT, else http://localhost:7337.
 func GetDefaultURL() string {
+	if url := strings.TrimSpace(os.Getenv("SCHMUX_URL")); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	if port := strings.TrimSpace(os.Getenv("SCHMUX_PORT")); port != "" {
+		return "http://localhost:" + port
+	}
 	return "http://localhost:7337"
 }
 
@@ -136,6 +145,35 @@ func (c *Client) GetSessions() ([]WorkspaceWithSessions, error) {
 	return sessions, nil
 }
 
+// GetRecentBranches fetches the most recently committed branches across all repos.
+func (c *Client) GetRecentBranches(limit int) ([]RecentBranch, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/api/recent-branches?limit=%d", c.baseURL, limit), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("daemon returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var branches []RecentBranch
+	if err := json.NewDecoder(resp.Body).Decode(&branches); err != nil {
+		return nil, fmt.Errorf("failed to decode recent branches: %w", err)
+	}
+
+	return branches, nil
+}
+
 // Spawn spawns a new session.
 func (c *Client) Spawn(ctx context.Context, req SpawnRequest) ([]SpawnResult, error) {
 	body, err := json.Marshal(req)
@@ -154,6 +192,9 @@ func (c *Client) Spawn(ctx context.Context, req SpawnRequest) ([]SpawnResult, er
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	hr.Header.Set("Content-Type", "application/json")
+	if req.IdempotencyKey != "" {
+		hr.Header.Set("Idempotency-Key", req.IdempotencyKey)
+	}
 
 	resp, err := c.httpClient.Do(hr)
 	if err != nil {
@@ -290,6 +331,15 @@ type Repo struct {
 	URL  string `json:"url"`
 }
 
+// RecentBranch represents a branch with its latest commit.
+type RecentBranch struct {
+	RepoName   string `json:"repo_name"`
+	RepoURL    string `json:"repo_url"`
+	Branch     string `json:"branch"`
+	CommitDate string `json:"commit_date"`
+	Subject    string `json:"subject"`
+}
+
 // RunTarget represents a user-supplied run target.
 type RunTarget struct {
 	Name    string `json:"name"`
@@ -373,6 +423,10 @@ type SpawnRequest struct {
 	WorkspaceID     string         `json:"workspace_id,omitempty"`
 	Command         string         `json:"command,omitempty"`
 	QuickLaunchName string         `json:"quick_launch_name,omitempty"`
+	BaseRef         string         `json:"base_ref,omitempty"`
+	// IdempotencyKey is sent as the Idempotency-Key header, so a retried request
+	// returns the first one's results instead of spawning again.
+	IdempotencyKey string `json:"-"`
 }
 
 // SpawnResult represents the result of a spawn operation.
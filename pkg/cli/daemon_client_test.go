@@ -10,12 +10,27 @@ import (
 )
 
 func TestGetDefaultURL(t *testing.T) {
+	t.Setenv("SCHMUX_URL", "")
+	t.Setenv("SCHMUX_PORT", "")
 	url := GetDefaultURL()
 	if url != "http://localhost:7337" {
 		t.Errorf("got %q, want %q", url, "http://localhost:7337")
 	}
 }
 
+func TestGetDefaultURL_EnvOverrides(t *testing.T) {
+	t.Setenv("SCHMUX_URL", "")
+	t.Setenv("SCHMUX_PORT", "9000")
+	if got := GetDefaultURL(); got != "http://localhost:9000" {
+		t.Errorf("with SCHMUX_PORT: got %q, want %q", got, "http://localhost:9000")
+	}
+
+	t.Setenv("SCHMUX_URL", "http://10.0.0.5:8080/")
+	if got := GetDefaultURL(); got != "http://10.0.0.5:8080" {
+		t.Errorf("with SCHMUX_URL: got %q, want %q", got, "http://10.0.0.5:8080")
+	}
+}
+
 func TestNewDaemonClient(t *testing.T) {
 	baseURL := "http://example.com:8080"
 	client := NewDaemonClient(baseURL)
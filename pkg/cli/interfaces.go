@@ -18,6 +18,9 @@ type DaemonClient interface {
 	// GetSessions fetches all sessions grouped by workspace.
 	GetSessions() ([]WorkspaceWithSessions, error)
 
+	// GetRecentBranches fetches the most recently committed branches across all repos.
+	GetRecentBranches(limit int) ([]RecentBranch, error)
+
 	// Spawn spawns a new session.
 	Spawn(ctx context.Context, req SpawnRequest) ([]SpawnResult, error)
 